@@ -5,7 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // CLISessionAuth holds access information
@@ -72,3 +75,51 @@ func GetAccessTokenForCLISession(ctx context.Context, id string) (token string,
 
 	return
 }
+
+// OIDCToken wraps the short-lived access token an OIDC ID token exchanges for.
+type OIDCToken struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// ExchangeOIDCToken exchanges the ID token of a CI job, which its OIDC provider
+// issued, for a short-lived access token to the named organization. The
+// organization has to trust the provider and the claims of the ID token.
+func ExchangeOIDCToken(ctx context.Context, idToken, orgSlug string) (*OIDCToken, error) {
+	postData, _ := json.Marshal(map[string]interface{}{
+		"id_token": idToken,
+		"org_slug": orgSlug,
+	})
+
+	url := fmt.Sprintf("%s/api/v1/oidc/token", baseURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(postData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+
+		apiErr := ErrorFromResp(res)
+		if msg := strings.TrimSpace(string(body)); msg != "" {
+			apiErr.Message = fmt.Sprintf("%s: %s", res.Status, msg)
+		}
+
+		return nil, apiErr
+	}
+
+	var token OIDCToken
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
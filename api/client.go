@@ -12,6 +12,10 @@ import (
 	"strings"
 
 	"github.com/machinebox/graphql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var baseURL string
@@ -60,12 +64,35 @@ func (c *Client) Run(req *graphql.Request) (Query, error) {
 }
 
 // RunWithContext - Runs a GraphQL request within a Go context
-func (c *Client) RunWithContext(ctx context.Context, req *graphql.Request) (Query, error) {
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.accessToken))
+func (c *Client) RunWithContext(ctx context.Context, req *graphql.Request) (resp Query, err error) {
+	ctx, span := otel.Tracer("github.com/superfly/flyctl/api").Start(ctx, "api.graphql",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("graphql.operation", operationName(req.Query()))),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	req.Header.Set("Authorization", AuthorizationHeader(c.accessToken))
 	req.Header.Set("User-Agent", c.userAgent)
 
-	var resp Query
-	err := c.client.Run(ctx, req, &resp)
+	// mutations carry an idempotency key, the same across retries, so that
+	// they may be retried as safely as queries
+	if strings.HasPrefix(operationName(req.Query()), "mutation") {
+		if req.Header.Get(IdempotencyKeyHeader) == "" {
+			req.Header.Set(IdempotencyKeyHeader, newIdempotencyKey())
+		}
+	} else {
+		ctx = withIdempotent(ctx)
+	}
+
+	ctx = withOperation(ctx, operationLabel(req.Query()), req.Vars())
+
+	err = c.client.Run(ctx, req, &resp)
 	if err != nil && strings.HasPrefix(err.Error(), "graphql: ") {
 		return resp, errors.New(strings.TrimPrefix(err.Error(), "graphql: "))
 	}
@@ -79,6 +106,19 @@ func (c *Client) RunWithContext(ctx context.Context, req *graphql.Request) (Quer
 
 var compactPattern = regexp.MustCompile(`\s+`)
 
+var operationPattern = regexp.MustCompile(`^\s*(query|mutation|subscription)\s*([_A-Za-z][_0-9A-Za-z]*)?`)
+
+// operationName returns the type and name of the operation the given GraphQL
+// query denotes, e.g. "query GetApp".
+func operationName(q string) string {
+	m := operationPattern.FindStringSubmatch(q)
+	if m == nil {
+		return "query"
+	}
+
+	return strings.TrimSpace(m[1] + " " + m[2])
+}
+
 func compactQueryString(q string) string {
 	q = strings.TrimSpace(q)
 	return compactPattern.ReplaceAllString(q, " ")
@@ -3,9 +3,10 @@ module github.com/superfly/flyctl/api
 go 1.16
 
 require (
-	github.com/PuerkitoBio/rehttp v1.1.0
 	github.com/machinebox/graphql v0.2.2
 	github.com/matryer/is v1.4.0 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
+	go.opentelemetry.io/otel v1.0.0-RC1
+	go.opentelemetry.io/otel/trace v1.0.0-RC1
 	golang.org/x/net v0.0.0-20210510120150-4163338589ed
 )
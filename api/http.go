@@ -7,26 +7,19 @@ import (
 	"math"
 	"net/http"
 	"time"
-
-	"github.com/PuerkitoBio/rehttp"
 )
 
 func newHTTPClient(logger Logger) (*http.Client, error) {
-	retryTransport := rehttp.NewTransport(
-		http.DefaultTransport,
-		rehttp.RetryAll(
-			rehttp.RetryMaxRetries(3),
-			rehttp.RetryAny(
-				rehttp.RetryTemporaryErr(),
-				rehttp.RetryStatuses(502, 503),
-			),
-		),
-		rehttp.ExpJitterDelay(100*time.Millisecond, 1*time.Second),
-	)
-
-	transport := &LoggingTransport{
-		innerTransport: retryTransport,
-		logger:         logger,
+	// every attempt is logged, so that the retries stand out
+	transport := &retryTransport{
+		innerTransport: &LoggingTransport{
+			innerTransport: &tracingTransport{
+				innerTransport: http.DefaultTransport,
+			},
+			logger: logger,
+		},
+		policy: retryPolicy,
+		logger: logger,
 	}
 
 	httpClient := &http.Client{
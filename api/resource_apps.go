@@ -121,7 +121,9 @@ func (client *Client) GetAppCompact(ctx context.Context, appName string) (*AppCo
 				status
 				version
 				appUrl
+				platformVersion
 				organization {
+					id
 					slug
 				}
 				services {
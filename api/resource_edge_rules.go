@@ -0,0 +1,108 @@
+package api
+
+import "context"
+
+const edgeRuleFragment = `
+	fragment EdgeRuleFragment on EdgeRule {
+		id
+		kind
+		path
+		rps
+		burst
+		ips
+		countries
+		createdAt
+	}
+`
+
+// GetEdgeRules returns the edge rules of the app with the given name.
+func (client *Client) GetEdgeRules(ctx context.Context, appName string) ([]EdgeRule, error) {
+	query := `
+		query ($appName: String!) {
+			app(name: $appName) {
+				edgeRules {
+					...EdgeRuleFragment
+				}
+			}
+		}
+	` + edgeRuleFragment
+
+	req := client.NewRequest(query)
+	req.Var("appName", appName)
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.App.EdgeRules, nil
+}
+
+// AddEdgeRule adds an edge rule to an app.
+func (client *Client) AddEdgeRule(ctx context.Context, input AddEdgeRuleInput) (*EdgeRule, error) {
+	query := `
+		mutation ($input: AddEdgeRuleInput!) {
+			addEdgeRule(input: $input) {
+				edgeRule {
+					...EdgeRuleFragment
+				}
+			}
+		}
+	` + edgeRuleFragment
+
+	req := client.NewRequest(query)
+	req.Var("input", input)
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.AddEdgeRule.EdgeRule, nil
+}
+
+// DeleteEdgeRule deletes an edge rule of an app.
+func (client *Client) DeleteEdgeRule(ctx context.Context, input DeleteEdgeRuleInput) error {
+	query := `
+		mutation ($input: DeleteEdgeRuleInput!) {
+			deleteEdgeRule(input: $input) {
+				app {
+					id
+				}
+			}
+		}
+	`
+
+	req := client.NewRequest(query)
+	req.Var("input", input)
+
+	_, err := client.RunWithContext(ctx, req)
+
+	return err
+}
+
+// ReplaceEdgeRules replaces the edge rules of an app with the given ones, in
+// a single step.
+func (client *Client) ReplaceEdgeRules(ctx context.Context, input ReplaceEdgeRulesInput) ([]EdgeRule, error) {
+	query := `
+		mutation ($input: ReplaceEdgeRulesInput!) {
+			replaceEdgeRules(input: $input) {
+				app {
+					edgeRules {
+						...EdgeRuleFragment
+					}
+				}
+			}
+		}
+	` + edgeRuleFragment
+
+	req := client.NewRequest(query)
+	req.Var("input", input)
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.ReplaceEdgeRules.App.EdgeRules, nil
+}
@@ -35,7 +35,7 @@ func (c *Client) GetAppLogs(ctx context.Context, appName, token, region, instanc
 		return
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.accessToken))
+	req.Header.Set("Authorization", AuthorizationHeader(c.accessToken))
 
 	var result getLogsResponse
 
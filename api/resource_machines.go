@@ -215,6 +215,19 @@ func (client *Client) GetMachine(ctx context.Context, appID, machineID string) (
 					  maskSize  
 					}
 				}
+				events{
+					nodes{
+					  kind
+					  timestamp
+					  body
+					}
+				}
+				checks{
+					name
+					status
+					output
+					updatedAt
+				}
 			}
 		}
 	}
@@ -0,0 +1,73 @@
+package api
+
+import "context"
+
+const maintenanceFragment = `
+	fragment MaintenanceFragment on AppMaintenance {
+		enabled
+		message
+		statusCode
+		targetApp {
+			name
+		}
+		updatedAt
+	}
+`
+
+// GetAppMaintenance returns the maintenance mode of the edge routing of the
+// app with the given name.
+func (client *Client) GetAppMaintenance(ctx context.Context, appName string) (*MaintenanceMode, error) {
+	query := `
+		query ($appName: String!) {
+			app(name: $appName) {
+				maintenance {
+					...MaintenanceFragment
+				}
+			}
+		}
+	` + maintenanceFragment
+
+	req := client.NewRequest(query)
+	req.Var("appName", appName)
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.App.Maintenance == nil {
+		return &MaintenanceMode{}, nil
+	}
+
+	return data.App.Maintenance, nil
+}
+
+// SetAppMaintenance switches the edge routing of an app to and from its
+// maintenance response. Instances of the app are not affected.
+func (client *Client) SetAppMaintenance(ctx context.Context, input SetAppMaintenanceInput) (*MaintenanceMode, error) {
+	query := `
+		mutation ($input: SetAppMaintenanceInput!) {
+			setAppMaintenance(input: $input) {
+				app {
+					maintenance {
+						...MaintenanceFragment
+					}
+				}
+			}
+		}
+	` + maintenanceFragment
+
+	req := client.NewRequest(query)
+	req.Var("input", input)
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.SetAppMaintenance.App.Maintenance == nil {
+		return &MaintenanceMode{}, nil
+	}
+
+	return data.SetAppMaintenance.App.Maintenance, nil
+}
@@ -16,6 +16,19 @@ func (c *Client) GetAppStatus(ctx context.Context, appName string, showCompleted
 				organization {
 					slug
 				}
+				processGroups {
+					name
+					vmSize {
+						name
+						cpuCores
+						memoryGb
+						memoryMb
+					}
+				}
+				taskGroupCounts {
+					name
+					count
+				}
 				deploymentStatus {
 					id
 					status
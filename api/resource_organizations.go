@@ -128,6 +128,86 @@ func (client *Client) GetOrganizationBySlug(ctx context.Context, slug string) (*
 	return &data.OrganizationDetails, nil
 }
 
+// GetOrganizationAppImages returns the apps of the named organization along
+// with the image of their current release.
+func (client *Client) GetOrganizationAppImages(ctx context.Context, slug string) ([]App, error) {
+	query := `query($slug: String!) {
+		organizationdetails: organization(slug: $slug) {
+			apps(first: 400) {
+				nodes {
+					id
+					name
+					currentRelease {
+						version
+						imageRef
+					}
+				}
+			}
+		}
+	}
+	`
+
+	req := client.NewRequest(query)
+	req.Var("slug", slug)
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.OrganizationDetails.Apps.Nodes, nil
+}
+
+// GetOrganizationApps returns every app of the named organization, fetching
+// them a page at a time.
+func (client *Client) GetOrganizationApps(ctx context.Context, slug string) ([]App, error) {
+	query := `query($slug: String!, $after: String) {
+		organizationdetails: organization(slug: $slug) {
+			apps(first: 400, after: $after) {
+				nodes {
+					id
+					name
+					organization {
+						slug
+					}
+				}
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+			}
+		}
+	}
+	`
+
+	var (
+		apps  []App
+		after *string
+	)
+
+	for {
+		req := client.NewRequest(query)
+
+		req.Var("slug", slug)
+		req.Var("after", after)
+
+		data, err := client.RunWithContext(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		page := data.OrganizationDetails.Apps
+		apps = append(apps, page.Nodes...)
+
+		if !page.PageInfo.HasNextPage || page.PageInfo.EndCursor == "" {
+			return apps, nil
+		}
+
+		cursor := page.PageInfo.EndCursor
+		after = &cursor
+	}
+}
+
 func (c *Client) CreateOrganization(ctx context.Context, organizationname string) (*Organization, error) {
 	query := `
 		mutation($input: CreateOrganizationInput!) {
@@ -242,3 +322,268 @@ func (c *Client) DeleteOrganizationMembership(ctx context.Context, orgId, userId
 
 	return data.DeleteOrganizationMembership.Organization.Name, data.DeleteOrganizationMembership.User.Email, nil
 }
+
+// GetOrganizationReleaseRetentionPolicy returns the release retention policy of
+// the organization with the given slug, or nil in case it has none.
+func (client *Client) GetOrganizationReleaseRetentionPolicy(ctx context.Context, slug string) (*ReleaseRetentionPolicy, error) {
+	query := `query($slug: String!) {
+		organization(slug: $slug) {
+			releaseRetentionPolicy {
+				keep
+				maxAgeDays
+			}
+		}
+	}
+	`
+
+	req := client.NewRequest(query)
+	req.Var("slug", slug)
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Organization == nil {
+		return nil, nil
+	}
+
+	return data.Organization.ReleaseRetentionPolicy, nil
+}
+
+func (client *Client) UpdateOrganizationReleaseRetentionPolicy(ctx context.Context, input UpdateOrganizationReleaseRetentionPolicyInput) (*ReleaseRetentionPolicy, error) {
+	query := `mutation($input: UpdateOrganizationReleaseRetentionPolicyInput!) {
+		updateOrganizationReleaseRetentionPolicy(input: $input) {
+			organization {
+				releaseRetentionPolicy {
+					keep
+					maxAgeDays
+				}
+			}
+		}
+	}
+	`
+
+	req := client.NewRequest(query)
+	req.Var("input", input)
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.UpdateOrganizationReleaseRetentionPolicy.Organization.ReleaseRetentionPolicy, nil
+}
+
+// GetSelfHostedBuilders returns the organizations of the viewer which have a
+// self-hosted builder registered, along with their builders.
+func (client *Client) GetSelfHostedBuilders(ctx context.Context) ([]Organization, error) {
+	query := `query {
+		organizations {
+			nodes {
+				id
+				slug
+				selfHostedBuilder {
+					host
+					noFailover
+				}
+			}
+		}
+	}
+	`
+
+	data, err := client.RunWithContext(ctx, client.NewRequest(query))
+	if err != nil {
+		return nil, err
+	}
+
+	var orgs []Organization
+	for _, org := range data.Organizations.Nodes {
+		if org.SelfHostedBuilder != nil {
+			orgs = append(orgs, org)
+		}
+	}
+
+	return orgs, nil
+}
+
+// GetOrganizationSelfHostedBuilder returns the self-hosted builder of the
+// organization with the given slug, or nil in case it has none.
+func (client *Client) GetOrganizationSelfHostedBuilder(ctx context.Context, slug string) (*SelfHostedBuilder, error) {
+	query := `query($slug: String!) {
+		organization(slug: $slug) {
+			selfHostedBuilder {
+				host
+				noFailover
+			}
+		}
+	}
+	`
+
+	req := client.NewRequest(query)
+	req.Var("slug", slug)
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Organization == nil {
+		return nil, nil
+	}
+
+	return data.Organization.SelfHostedBuilder, nil
+}
+
+// GetOrganizationRemoteBuilderApp returns the app the Fly-managed remote
+// builder of the organization with the given slug runs in, or nil in case none
+// has been provisioned yet. Unlike EnsureRemoteBuilder it neither creates nor
+// wakes the builder.
+func (client *Client) GetOrganizationRemoteBuilderApp(ctx context.Context, slug string) (*App, error) {
+	query := `query($slug: String!) {
+		organization(slug: $slug) {
+			remoteBuilderApp {
+				id
+				name
+			}
+		}
+	}
+	`
+
+	req := client.NewRequest(query)
+	req.Var("slug", slug)
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Organization == nil {
+		return nil, nil
+	}
+
+	return data.Organization.RemoteBuilderApp, nil
+}
+
+// SetOrganizationSelfHostedBuilder registers the self-hosted builder of the
+// given input with its organization, replacing any other.
+func (client *Client) SetOrganizationSelfHostedBuilder(ctx context.Context, input SetOrganizationSelfHostedBuilderInput) (*SelfHostedBuilder, error) {
+	query := `mutation($input: SetOrganizationSelfHostedBuilderInput!) {
+		setOrganizationSelfHostedBuilder(input: $input) {
+			organization {
+				selfHostedBuilder {
+					host
+					noFailover
+				}
+			}
+		}
+	}
+	`
+
+	req := client.NewRequest(query)
+	req.Var("input", input)
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.SetOrganizationSelfHostedBuilder.Organization.SelfHostedBuilder, nil
+}
+
+// RemoveOrganizationSelfHostedBuilder removes the self-hosted builder of the
+// organization of the given input.
+func (client *Client) RemoveOrganizationSelfHostedBuilder(ctx context.Context, input RemoveOrganizationSelfHostedBuilderInput) error {
+	query := `mutation($input: RemoveOrganizationSelfHostedBuilderInput!) {
+		removeOrganizationSelfHostedBuilder(input: $input) {
+			organization {
+				id
+			}
+		}
+	}
+	`
+
+	req := client.NewRequest(query)
+	req.Var("input", input)
+
+	_, err := client.RunWithContext(ctx, req)
+
+	return err
+}
+
+// GetOrganizationSpend returns what the organization with the given slug has
+// spent in the current billing period so far, along with its spend alerts.
+func (client *Client) GetOrganizationSpend(ctx context.Context, slug string) (*OrganizationSpend, []SpendAlert, error) {
+	query := `query($slug: String!) {
+		organization(slug: $slug) {
+			currentSpend {
+				amountCents
+				periodStart
+				periodEnd
+			}
+			spendAlerts {
+				id
+				thresholdCents
+				handlerNames
+				triggeredAt
+			}
+		}
+	}
+	`
+
+	req := client.NewRequest(query)
+	req.Var("slug", slug)
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if data.Organization == nil {
+		return nil, nil, ErrNotFound
+	}
+
+	return data.Organization.CurrentSpend, data.Organization.SpendAlerts, nil
+}
+
+func (client *Client) CreateSpendAlert(ctx context.Context, input CreateSpendAlertInput) (*SpendAlert, error) {
+	query := `mutation($input: CreateSpendAlertInput!) {
+		createSpendAlert(input: $input) {
+			spendAlert {
+				id
+				thresholdCents
+				handlerNames
+				triggeredAt
+			}
+		}
+	}
+	`
+
+	req := client.NewRequest(query)
+	req.Var("input", input)
+
+	data, err := client.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.CreateSpendAlert.SpendAlert, nil
+}
+
+func (client *Client) DeleteSpendAlert(ctx context.Context, input DeleteSpendAlertInput) error {
+	query := `mutation($input: DeleteSpendAlertInput!) {
+		deleteSpendAlert(input: $input) {
+			organization {
+				id
+			}
+		}
+	}
+	`
+
+	req := client.NewRequest(query)
+	req.Var("input", input)
+
+	_, err := client.RunWithContext(ctx, req)
+
+	return err
+}
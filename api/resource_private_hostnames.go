@@ -0,0 +1,83 @@
+package api
+
+import "context"
+
+// GetPrivateHostnames returns the additional names the named app resolves by
+// on the private network.
+func (c *Client) GetPrivateHostnames(ctx context.Context, appName string) ([]PrivateHostname, error) {
+	query := `
+		query($appName: String!) {
+			app(name: $appName) {
+				privateHostnames {
+					nodes {
+						id
+						name
+						processGroup
+						createdAt
+					}
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("appName", appName)
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.App.PrivateHostnames.Nodes, nil
+}
+
+// AddPrivateHostname adds a name the app, or the process group, the given
+// input denotes resolves by on the private network.
+func (c *Client) AddPrivateHostname(ctx context.Context, input AddPrivateHostnameInput) (*PrivateHostname, error) {
+	query := `
+		mutation($input: AddPrivateHostnameInput!) {
+			addPrivateHostname(input: $input) {
+				privateHostname {
+					id
+					name
+					processGroup
+					createdAt
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", input)
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.AddPrivateHostname.PrivateHostname, nil
+}
+
+// RemovePrivateHostname removes a name an app resolves by on the private
+// network.
+func (c *Client) RemovePrivateHostname(ctx context.Context, input RemovePrivateHostnameInput) error {
+	query := `
+		mutation($input: RemovePrivateHostnameInput!) {
+			removePrivateHostname(input: $input) {
+				app {
+					id
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", input)
+
+	_, err := c.RunWithContext(ctx, req)
+
+	return err
+}
@@ -14,12 +14,15 @@ func (c *Client) GetAppReleases(ctx context.Context, appName string, limit int)
 						reason
 						status
 						stable
+						inProgress
 						user {
 							id
 							email
 							name
 						}
 						createdAt
+						updatedAt
+						imageRef
 					}
 				}
 			}
@@ -39,6 +42,67 @@ func (c *Client) GetAppReleases(ctx context.Context, appName string, limit int)
 	return data.App.Releases.Nodes, nil
 }
 
+// GetAllAppReleases returns every release of the named app, newest first,
+// fetching them a page at a time.
+func (c *Client) GetAllAppReleases(ctx context.Context, appName string) ([]Release, error) {
+	query := `
+		query ($appName: String!, $after: String) {
+			app(name: $appName) {
+				releases(first: 500, after: $after) {
+					nodes {
+						id
+						version
+						description
+						reason
+						status
+						stable
+						inProgress
+						user {
+							id
+							email
+							name
+						}
+						createdAt
+						updatedAt
+						imageRef
+					}
+					pageInfo {
+						hasNextPage
+						endCursor
+					}
+				}
+			}
+		}
+	`
+
+	var (
+		releases []Release
+		after    *string
+	)
+
+	for {
+		req := c.NewRequest(query)
+
+		req.Var("appName", appName)
+		req.Var("after", after)
+
+		data, err := c.RunWithContext(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		page := data.App.Releases
+		releases = append(releases, page.Nodes...)
+
+		if !page.PageInfo.HasNextPage || page.PageInfo.EndCursor == "" {
+			return releases, nil
+		}
+
+		cursor := page.PageInfo.EndCursor
+		after = &cursor
+	}
+}
+
 func (c *Client) GetAppRelease(ctx context.Context, appName string, id string) (*Release, error) {
 	query := `
 		query ($appName: String!, $releaseId: ID!) {
@@ -65,3 +129,70 @@ func (c *Client) GetAppRelease(ctx context.Context, appName string, id string) (
 
 	return data.App.Release, nil
 }
+
+// GetAppReleaseByVersion returns the release of the given version of the app,
+// along with the image it deployed and the config it deployed it with.
+func (c *Client) GetAppReleaseByVersion(ctx context.Context, appName string, version int) (*Release, error) {
+	query := `
+		query ($appName: String!, $version: Int!) {
+			app(name: $appName) {
+				release(version: $version) {
+					id
+					version
+					description
+					reason
+					status
+					stable
+					imageRef
+					config {
+						definition
+					}
+					user {
+						id
+						email
+						name
+					}
+					createdAt
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("appName", appName)
+	req.Var("version", version)
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.App.Release, nil
+}
+
+// DeleteReleases deletes the releases of the app with the given ID, returning
+// the number of releases it deleted.
+func (c *Client) DeleteReleases(ctx context.Context, appID string, releaseIDs []string) (int, error) {
+	query := `
+		mutation($input: DeleteReleasesInput!) {
+			deleteReleases(input: $input) {
+				deletedCount
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", map[string]interface{}{
+		"appId":      appID,
+		"releaseIds": releaseIDs,
+	})
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+
+	return data.DeleteReleases.DeletedCount, nil
+}
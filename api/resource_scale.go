@@ -114,6 +114,14 @@ func (c *Client) AppVMResources(ctx context.Context, appName string) (VMSize, []
 				processGroups {
 					name
 					maxPerRegion
+					vmSize {
+						name
+						cpuCores
+						memoryGb
+						memoryMb
+						priceMonth
+						priceSecond
+					}
 				}
 			}
 		}
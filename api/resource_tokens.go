@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"strings"
+)
+
+// ScopedTokenScheme is the authorization scheme of scoped tokens, which carry
+// it as their prefix and are sent as is rather than as bearer tokens.
+const ScopedTokenScheme = "FlyV1"
+
+// IsScopedToken reports whether token is a scoped token, such as the deploy
+// tokens CreateLimitedAccessToken mints.
+func IsScopedToken(token string) bool {
+	return strings.HasPrefix(token, ScopedTokenScheme+" ")
+}
+
+// AuthorizationHeader returns the value of the Authorization header which
+// authenticates requests with the given token.
+func AuthorizationHeader(token string) string {
+	if IsScopedToken(token) {
+		return token
+	}
+
+	return "Bearer " + token
+}
+
+// CreateLimitedAccessToken mints a token restricted to what the profile of
+// the given input allows.
+func (c *Client) CreateLimitedAccessToken(ctx context.Context, input CreateLimitedAccessTokenInput) (*LimitedAccessToken, error) {
+	query := `
+		mutation($input: CreateLimitedAccessTokenInput!) {
+			createLimitedAccessToken(input: $input) {
+				limitedAccessToken {
+					id
+					name
+					profile
+					tokenHeader
+					expiresAt
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", input)
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.CreateLimitedAccessToken.LimitedAccessToken, nil
+}
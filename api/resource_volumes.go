@@ -148,6 +148,7 @@ func (c *Client) GetVolumeSnapshots(ctx context.Context, volID string) ([]Snapsh
 						size
 						digest
 						createdAt
+						status
 					}
 				}
 			}
@@ -165,3 +166,91 @@ func (c *Client) GetVolumeSnapshots(ctx context.Context, volID string) ([]Snapsh
 
 	return data.Volume.Snapshots.Nodes, nil
 }
+
+func (c *Client) CreateVolumeSnapshot(ctx context.Context, volID string) error {
+	query := `
+		mutation($input: CreateVolumeSnapshotInput!) {
+			createVolumeSnapshot(input: $input) {
+				volume {
+					id
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", CreateVolumeSnapshotInput{VolumeID: volID})
+
+	_, err := c.RunWithContext(ctx, req)
+
+	return err
+}
+
+// GetVolumeSnapshotSchedule returns the volume with the given ID along with its
+// snapshot schedule and snapshots.
+func (c *Client) GetVolumeSnapshotSchedule(ctx context.Context, volID string) (*Volume, error) {
+	query := `
+	query($id: ID!) {
+		volume: node(id: $id) {
+			... on Volume {
+				id
+				name
+				snapshotSchedule {
+					frequency
+					retentionDays
+					nextSnapshotAt
+				}
+				snapshots {
+					nodes {
+						id
+						size
+						digest
+						createdAt
+						status
+						scheduled
+					}
+				}
+			}
+		}
+	}`
+
+	req := c.NewRequest(query)
+
+	req.Var("id", volID)
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &data.Volume, nil
+}
+
+func (c *Client) UpdateVolumeSnapshotSchedule(ctx context.Context, input UpdateVolumeSnapshotScheduleInput) (*VolumeSnapshotSchedule, error) {
+	query := `
+		mutation($input: UpdateVolumeSnapshotScheduleInput!) {
+			updateVolumeSnapshotSchedule(input: $input) {
+				volume {
+					id
+					snapshotSchedule {
+						frequency
+						retentionDays
+						nextSnapshotAt
+					}
+				}
+			}
+		}
+	`
+
+	req := c.NewRequest(query)
+
+	req.Var("input", input)
+
+	data, err := c.RunWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.UpdateVolumeSnapshotSchedule.Volume.SnapshotSchedule, nil
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	mrand "math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// IdempotencyKeyHeader is the header mutations carry their idempotency key in,
+// so that those which are retried after the platform applied them aren't
+// applied twice.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy denotes how requests which fail transiently, with a network
+// error or a 429, 502, 503 or 504 response, are retried.
+type RetryPolicy struct {
+	// MaxRetries is how many times requests are retried; 0 disables retries.
+	MaxRetries int
+
+	// BaseDelay is the delay before the first retry, which doubles with
+	// every retry after it. Delays are jittered.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between retries. Responses the Retry-After of
+	// which asks for longer aren't retried.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy rides out the platform restarting, or rate limiting,
+// for about half a minute.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 5,
+	BaseDelay:  250 * time.Millisecond,
+	MaxDelay:   15 * time.Second,
+}
+
+var retryPolicy = DefaultRetryPolicy
+
+// SetRetryPolicy - Sets the policy clients created afterwards retry requests by
+func SetRetryPolicy(p RetryPolicy) {
+	retryPolicy = p
+}
+
+// delay returns the jittered delay before the given retry, counting from 0.
+func (p RetryPolicy) delay(retry int) time.Duration {
+	d := p.MaxDelay
+	if retry < 32 {
+		if exp := p.BaseDelay << uint(retry); exp > 0 && exp < d {
+			d = exp
+		}
+	}
+
+	if half := int64(d / 2); half > 0 {
+		return time.Duration(half + mrand.Int63n(half+1))
+	}
+
+	return d
+}
+
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+var retryStatuses = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+var contextKeyIdempotent = &contextKey{"Idempotent"}
+
+// withIdempotent derives a Context which marks the requests made with it as
+// safe to retry, as GraphQL queries are even though they're POSTed.
+func withIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKeyIdempotent, true)
+}
+
+// isIdempotent reports whether req may be sent more than once.
+func isIdempotent(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false // the body may not be sent again
+	}
+
+	return idempotentMethods[req.Method] ||
+		req.Header.Get(IdempotencyKeyHeader) != "" ||
+		req.Context().Value(contextKeyIdempotent) != nil
+}
+
+// newIdempotencyKey returns a random idempotency key.
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// retryAfter returns the delay the Retry-After header of res asks for, if any.
+func retryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	v := res.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(v); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d, true
+		}
+
+		return 0, true
+	}
+
+	return 0, false
+}
+
+type retryTransport struct {
+	innerTransport http.RoundTripper
+	policy         RetryPolicy
+	logger         Logger
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.policy.MaxRetries <= 0 || !isIdempotent(req) {
+		return t.innerTransport.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+
+	for retry := 0; ; retry++ {
+		attempt := req
+		if retry > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			attempt = req.Clone(ctx)
+			attempt.Body = body
+		}
+
+		res, err := t.innerTransport.RoundTrip(attempt)
+		if retry == t.policy.MaxRetries || ctx.Err() != nil {
+			return res, err
+		}
+
+		var delay time.Duration
+		switch {
+		case err != nil:
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return res, err
+			}
+
+			delay = t.policy.delay(retry)
+			t.logger.Debugf("retrying %s %s in %s: %v\n", req.Method, req.URL, delay, err)
+		case retryStatuses[res.StatusCode]:
+			delay = t.policy.delay(retry)
+
+			if after, ok := retryAfter(res, time.Now()); ok {
+				if after > t.policy.MaxDelay {
+					return res, nil // waiting any longer is up to the caller
+				} else if after > delay {
+					delay = after
+				}
+			}
+
+			t.logger.Debugf("retrying %s %s in %s: %s\n", req.Method, req.URL, delay, res.Status)
+
+			// the connection may be reused only once the body is drained
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		default:
+			return res, nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestTracer wraps the logger requests to the API are traced through.
+type RequestTracer interface {
+	Tracef(format string, v ...interface{})
+}
+
+var (
+	requestTracerMu sync.RWMutex
+	requestTracer   RequestTracer
+)
+
+// SetRequestTracer - Sets the tracer every attempt of every request to the API,
+// and GraphQL operation, is traced through; nil disables tracing
+func SetRequestTracer(t RequestTracer) {
+	requestTracerMu.Lock()
+	defer requestTracerMu.Unlock()
+
+	requestTracer = t
+}
+
+func currentRequestTracer() RequestTracer {
+	requestTracerMu.RLock()
+	defer requestTracerMu.RUnlock()
+
+	return requestTracer
+}
+
+var contextKeyOperation = &contextKey{"Operation"}
+
+// operation wraps the GraphQL operation a request carries.
+type operation struct {
+	name string
+	vars map[string]interface{}
+}
+
+func withOperation(ctx context.Context, name string, vars map[string]interface{}) context.Context {
+	return context.WithValue(ctx, contextKeyOperation, &operation{name, vars})
+}
+
+var rootFieldPattern = regexp.MustCompile(`^[^{]*{\s*([_A-Za-z][_0-9A-Za-z]*)`)
+
+// operationLabel returns the name of the operation the given GraphQL query
+// denotes or, for anonymous ones, its type and root field, e.g.
+// "query { app }".
+func operationLabel(q string) string {
+	name := operationName(q)
+	if strings.Contains(name, " ") {
+		return name
+	}
+
+	if m := rootFieldPattern.FindStringSubmatch(q); m != nil {
+		return name + " { " + m[1] + " }"
+	}
+
+	return name
+}
+
+// tracingTransport traces the requests it sends: their GraphQL operation and
+// redacted variables or, for other requests, their method and URL, along with
+// the status and duration of the response.
+type tracingTransport struct {
+	innerTransport http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tracer := currentRequestTracer()
+	if tracer == nil {
+		return t.innerTransport.RoundTrip(req)
+	}
+
+	what := req.Method + " " + req.URL.String()
+	if op, ok := req.Context().Value(contextKeyOperation).(*operation); ok {
+		what = op.name + " " + redactedVars(op.vars)
+	}
+
+	start := time.Now()
+
+	resp, err := t.innerTransport.RoundTrip(req)
+
+	took := Duration(time.Since(start), 2)
+	if err != nil {
+		tracer.Tracef("%s failed after %s: %v", what, took, err)
+	} else {
+		tracer.Tracef("%s %d (%s)", what, resp.StatusCode, took)
+	}
+
+	return resp, err
+}
+
+// redacted replaces the values of variables which may be secret.
+const redacted = "[REDACTED]"
+
+var sensitiveVarPattern = regexp.MustCompile(`(?i)token|secret|password|passphrase|otp|credential|private|cert|^value$`)
+
+// redactedVars returns the JSON encoding of the given variables, the values of
+// those the names of which denote secrets replaced, e.g. those of setSecrets.
+func redactedVars(vars map[string]interface{}) string {
+	if len(vars) == 0 {
+		return "{}"
+	}
+
+	// inputs are usually structs; their JSON form has the names the API sees
+	data, err := json.Marshal(vars)
+	if err != nil {
+		return "{}"
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return "{}"
+	}
+
+	data, _ = json.Marshal(redact(v))
+
+	return string(data)
+}
+
+func redact(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if sensitiveVarPattern.MatchString(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redact(value)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redact(v[i])
+		}
+	}
+
+	return v
+}
@@ -134,6 +134,22 @@ type Query struct {
 		App App
 	}
 
+	SetAppMaintenance struct {
+		App App
+	}
+
+	AddEdgeRule struct {
+		EdgeRule EdgeRule
+	}
+
+	DeleteEdgeRule struct {
+		App App
+	}
+
+	ReplaceEdgeRules struct {
+		App App
+	}
+
 	RestartApp struct {
 		App App
 	}
@@ -158,8 +174,47 @@ type Query struct {
 	CreateOrganization CreateOrganizationPayload
 	DeleteOrganization DeleteOrganizationPayload
 
-	CreateVolume CreateVolumePayload
-	DeleteVolume DeleteVolumePayload
+	CreateVolume         CreateVolumePayload
+	DeleteVolume         DeleteVolumePayload
+	CreateVolumeSnapshot CreateVolumeSnapshotPayload
+
+	UpdateVolumeSnapshotSchedule UpdateVolumeSnapshotSchedulePayload
+
+	DeleteReleases struct {
+		DeletedCount int
+	}
+
+	UpdateOrganizationReleaseRetentionPolicy struct {
+		Organization Organization
+	}
+
+	SetOrganizationSelfHostedBuilder struct {
+		Organization Organization
+	}
+
+	RemoveOrganizationSelfHostedBuilder struct {
+		Organization Organization
+	}
+
+	CreateSpendAlert struct {
+		SpendAlert SpendAlert
+	}
+
+	DeleteSpendAlert struct {
+		Organization Organization
+	}
+
+	CreateLimitedAccessToken struct {
+		LimitedAccessToken LimitedAccessToken
+	}
+
+	AddPrivateHostname struct {
+		PrivateHostname PrivateHostname
+	}
+
+	RemovePrivateHostname struct {
+		App App
+	}
 
 	AddWireGuardPeer              CreatedWireGuardPeer
 	EstablishSSHKey               SSHCertificate
@@ -231,6 +286,45 @@ type DelegatedWireGuardToken struct {
 	Token string
 }
 
+// LimitedAccessToken wraps a token restricted to what its profile allows.
+type LimitedAccessToken struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Profile   string    `json:"profile"`
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// TokenHeader denotes the token, along with its authorization scheme.
+	TokenHeader string `json:"tokenHeader"`
+}
+
+// PrivateHostname denotes an additional name an app, or a process group of it,
+// resolves by on the private network, e.g. api.internal.
+type PrivateHostname struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	ProcessGroup string    `json:"processGroup,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type AddPrivateHostnameInput struct {
+	AppID        string `json:"appId"`
+	Name         string `json:"name"`
+	ProcessGroup string `json:"processGroup,omitempty"`
+}
+
+type RemovePrivateHostnameInput struct {
+	AppID string `json:"appId"`
+	Name  string `json:"name"`
+}
+
+type CreateLimitedAccessTokenInput struct {
+	Name           string                 `json:"name"`
+	OrganizationID string                 `json:"organizationId"`
+	Profile        string                 `json:"profile"`
+	ProfileParams  map[string]interface{} `json:"profileParams,omitempty"`
+	Expiry         string                 `json:"expiry,omitempty"`
+}
+
 type DelegatedWireGuardTokenHandle /* whatever */ struct {
 	Name string
 }
@@ -251,6 +345,9 @@ type MachineInit struct {
 	Entrypoint []string `json:"entrypoint"`
 	Cmd        []string `json:"cmd"`
 	Tty        bool     `json:"tty"`
+
+	SwapSizeMB   int `json:"swap_size_mb,omitempty"`
+	MaxOpenFiles int `json:"max_open_files,omitempty"`
 }
 
 func DefinitionPtr(in map[string]interface{}) *Definition {
@@ -280,7 +377,8 @@ type App struct {
 	Secrets        []Secret
 	CurrentRelease *Release
 	Releases       struct {
-		Nodes []Release
+		Nodes    []Release
+		PageInfo PageInfo
 	}
 	IPAddresses struct {
 		Nodes []IPAddress
@@ -305,6 +403,8 @@ type App struct {
 	Allocation       *AllocationStatus
 	DeploymentStatus *DeploymentStatus
 	Autoscaling      *AutoscalingConfig
+	Maintenance      *MaintenanceMode
+	EdgeRules        []EdgeRule
 	VMSize           VMSize
 	Regions          *[]Region
 	BackupRegions    *[]Region
@@ -328,6 +428,12 @@ type App struct {
 	LatestImageDetails          ImageVersion
 
 	Machine *Machine
+
+	// PrivateHostnames are the additional names the app, or process groups
+	// of it, resolve by on the private network.
+	PrivateHostnames struct {
+		Nodes []PrivateHostname
+	}
 }
 
 type TaskGroupCount struct {
@@ -340,6 +446,12 @@ type Snapshot struct {
 	Digest    string
 	Size      string
 	CreatedAt time.Time
+	// Status is the lifecycle status of the snapshot; only complete snapshots
+	// may be restored.
+	Status string
+	// Scheduled is set for the snapshots the schedule of the volume took, as
+	// opposed to those taken on demand.
+	Scheduled bool
 }
 
 type Volume struct {
@@ -360,6 +472,18 @@ type Volume struct {
 	Host               struct {
 		ID string
 	}
+	SnapshotSchedule *VolumeSnapshotSchedule
+}
+
+// VolumeSnapshotSchedule denotes how often a volume is snapshotted and for how
+// long those snapshots are kept.
+type VolumeSnapshotSchedule struct {
+	// Frequency is one of daily, weekly or off.
+	Frequency     string
+	RetentionDays int
+	// NextSnapshotAt is unset for volumes which aren't snapshotted on a
+	// schedule.
+	NextSnapshotAt *time.Time
 }
 
 type CreateVolumeInput struct {
@@ -370,6 +494,9 @@ type CreateVolumeInput struct {
 	Encrypted         bool    `json:"encrypted"`
 	SnapshotID        *string `json:"snapshotId,omitempty"`
 	RequireUniqueZone bool    `json:"requireUniqueZone"`
+	// SnapshotRetention, when set, denotes the number of days the daily
+	// snapshots of the volume are kept for.
+	SnapshotRetention *int `json:"snapshotRetention,omitempty"`
 }
 
 type CreateVolumePayload struct {
@@ -385,6 +512,24 @@ type DeleteVolumePayload struct {
 	App App
 }
 
+type CreateVolumeSnapshotInput struct {
+	VolumeID string `json:"volumeId"`
+}
+
+type CreateVolumeSnapshotPayload struct {
+	Volume Volume
+}
+
+type UpdateVolumeSnapshotScheduleInput struct {
+	VolumeID      string  `json:"volumeId"`
+	Frequency     *string `json:"frequency,omitempty"`
+	RetentionDays *int    `json:"retentionDays,omitempty"`
+}
+
+type UpdateVolumeSnapshotSchedulePayload struct {
+	Volume Volume
+}
+
 type AppCertsCompact struct {
 	Certificates struct {
 		Nodes []AppCertificateCompact
@@ -411,6 +556,9 @@ type AppCompact struct {
 		Nodes []IPAddress
 	}
 	Services []Service
+	// PlatformVersion denotes the platform the app runs on; nomad or
+	// machines.
+	PlatformVersion string
 }
 
 type AppStatus struct {
@@ -422,6 +570,8 @@ type AppStatus struct {
 	Version          int
 	AppURL           string
 	Organization     Organization
+	ProcessGroups    []ProcessGroup
+	TaskGroupCounts  []TaskGroupCount
 	DeploymentStatus *DeploymentStatus
 	Allocations      []*AllocationStatus
 }
@@ -450,6 +600,26 @@ type Organization struct {
 
 	WireGuardPeer *WireGuardPeer
 
+	// ReleaseRetentionPolicy is the default policy old releases of the apps
+	// of the organization are pruned by.
+	ReleaseRetentionPolicy *ReleaseRetentionPolicy
+
+	// SelfHostedBuilder is the builder the remote builds of the apps of the
+	// organization run on instead of the Fly-managed one, if any.
+	SelfHostedBuilder *SelfHostedBuilder
+
+	// RemoteBuilderApp is the app the Fly-managed remote builder of the
+	// organization runs in, if one has been provisioned yet.
+	RemoteBuilderApp *App
+
+	// CurrentSpend is what the organization has spent in the current billing
+	// period so far.
+	CurrentSpend *OrganizationSpend
+
+	// SpendAlerts are the monthly spend thresholds which notify the health
+	// check handlers of the organization once crossed.
+	SpendAlerts []SpendAlert
+
 	WireGuardPeers struct {
 		Nodes *[]*WireGuardPeer
 		Edges *[]*struct {
@@ -487,7 +657,8 @@ type OrganizationDetails struct {
 	Type              string
 	ViewerRole        string
 	Apps              struct {
-		Nodes []App
+		Nodes    []App
+		PageInfo PageInfo
 	}
 	Members struct {
 		Edges []OrganizationMembershipEdge
@@ -553,6 +724,72 @@ type SetSecretsInputSecret struct {
 	Value string `json:"value"`
 }
 
+// MaintenanceMode wraps the maintenance mode of the edge routing of an app.
+// While enabled, the edge serves either the static maintenance response or
+// the target app in place of the app.
+type MaintenanceMode struct {
+	Enabled    bool
+	Message    string
+	StatusCode int
+	TargetApp  *struct {
+		Name string
+	}
+	UpdatedAt *time.Time
+}
+
+type SetAppMaintenanceInput struct {
+	AppID       string  `json:"appId"`
+	Enabled     bool    `json:"enabled"`
+	Message     *string `json:"message,omitempty"`
+	StatusCode  *int    `json:"statusCode,omitempty"`
+	TargetAppID *string `json:"targetAppId,omitempty"`
+}
+
+// The kinds of edge rules.
+const (
+	EdgeRuleRateLimit      = "RATE_LIMIT"
+	EdgeRuleAllow          = "ALLOW"
+	EdgeRuleDeny           = "DENY"
+	EdgeRuleBlockCountries = "BLOCK_COUNTRIES"
+)
+
+// EdgeRule wraps a rule the edge applies to requests to an app before they
+// reach it. Rules apply to the requests whose path falls under Path.
+type EdgeRule struct {
+	ID        string
+	Kind      string
+	Path      string
+	RPS       int
+	Burst     int
+	IPs       []string
+	Countries []string
+	CreatedAt *time.Time
+}
+
+type EdgeRuleInput struct {
+	Kind      string   `json:"kind"`
+	Path      string   `json:"path"`
+	RPS       int      `json:"rps,omitempty"`
+	Burst     int      `json:"burst,omitempty"`
+	IPs       []string `json:"ips,omitempty"`
+	Countries []string `json:"countries,omitempty"`
+}
+
+type AddEdgeRuleInput struct {
+	AppID string `json:"appId"`
+	EdgeRuleInput
+}
+
+type DeleteEdgeRuleInput struct {
+	AppID  string `json:"appId"`
+	RuleID string `json:"ruleId"`
+}
+
+type ReplaceEdgeRulesInput struct {
+	AppID string          `json:"appId"`
+	Rules []EdgeRuleInput `json:"rules"`
+}
+
 type UnsetSecretsInput struct {
 	AppID string   `json:"appId"`
 	Keys  []string `json:"keys"`
@@ -599,6 +836,12 @@ type LogEntry struct {
 	}
 }
 
+// PageInfo denotes where a page of a connection ends.
+type PageInfo struct {
+	HasNextPage bool
+	EndCursor   string
+}
+
 type Release struct {
 	ID                 string
 	Version            int
@@ -611,6 +854,69 @@ type Release struct {
 	User               User
 	EvaluationID       string
 	CreatedAt          time.Time
+	// UpdatedAt is when the status of the release last changed, which, for
+	// releases no longer in progress, is when their deployment completed.
+	UpdatedAt time.Time
+	ImageRef  string
+	Config    *AppConfig
+}
+
+// ReleaseRetentionPolicy denotes which old releases are pruned: those beyond
+// the newest Keep, when set, which are older than MaxAgeDays, when set.
+type ReleaseRetentionPolicy struct {
+	Keep       int `json:"keep"`
+	MaxAgeDays int `json:"maxAgeDays"`
+}
+
+// OrganizationSpend denotes what an organization has spent in a billing
+// period, in cents.
+type OrganizationSpend struct {
+	AmountCents int       `json:"amountCents"`
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+}
+
+// SpendAlert denotes a monthly spend threshold, in cents, which notifies the
+// health check handlers it names once crossed.
+type SpendAlert struct {
+	ID             string     `json:"id"`
+	ThresholdCents int        `json:"thresholdCents"`
+	HandlerNames   []string   `json:"handlerNames"`
+	TriggeredAt    *time.Time `json:"triggeredAt"`
+}
+
+type CreateSpendAlertInput struct {
+	OrganizationID string   `json:"organizationId"`
+	ThresholdCents int      `json:"thresholdCents"`
+	HandlerNames   []string `json:"handlerNames"`
+}
+
+type DeleteSpendAlertInput struct {
+	OrganizationID string `json:"organizationId"`
+	SpendAlertID   string `json:"spendAlertId"`
+}
+
+// SelfHostedBuilder denotes a builder an organization runs itself and has
+// registered for its remote builds.
+type SelfHostedBuilder struct {
+	Host       string `json:"host"`
+	NoFailover bool   `json:"noFailover"`
+}
+
+type SetOrganizationSelfHostedBuilderInput struct {
+	OrganizationID string `json:"organizationId"`
+	Host           string `json:"host"`
+	NoFailover     bool   `json:"noFailover"`
+}
+
+type RemoveOrganizationSelfHostedBuilderInput struct {
+	OrganizationID string `json:"organizationId"`
+}
+
+type UpdateOrganizationReleaseRetentionPolicyInput struct {
+	OrganizationID string `json:"organizationId"`
+	Keep           int    `json:"keep"`
+	MaxAgeDays     int    `json:"maxAgeDays"`
 }
 
 type Build struct {
@@ -720,6 +1026,18 @@ type DeployImageInput struct {
 	Services   *[]Service  `json:"services"`
 	Definition *Definition `json:"definition"`
 	Strategy   *string     `json:"strategy"`
+
+	// Secrets holds the secrets to set as part of the release.
+	Secrets []SetSecretsInputSecret `json:"secrets,omitempty"`
+
+	// UnsetSecrets holds the names of the secrets to unset as part of the
+	// release.
+	UnsetSecrets []string `json:"unsetSecrets,omitempty"`
+
+	// ProcessGroups holds the names of the process groups the release
+	// updates; the rest keep running their current release. All groups are
+	// updated when empty.
+	ProcessGroups []string `json:"processGroups,omitempty"`
 }
 
 type Service struct {
@@ -1179,6 +1497,9 @@ type Machine struct {
 		Nodes []*MachineEvent
 	}
 
+	// Checks holds the states of the health checks of the machine.
+	Checks []*CheckState
+
 	CreatedAt time.Time
 }
 
@@ -1194,6 +1515,9 @@ type StopMachineInput struct {
 	ID              string `json:"id"`
 	Signal          string `json:"signal,omitempty"`
 	KillTimeoutSecs int    `json:"kill_timeout_secs,omitempty"`
+	// DrainTimeoutSecs is how long the proxy drains connections to the machine
+	// for before it is signaled.
+	DrainTimeoutSecs int `json:"drain_timeout_secs,omitempty"`
 }
 
 type StartMachineInput struct {
@@ -1236,6 +1560,8 @@ type MachineGuest struct {
 	CPUKind  string `json:"cpu_kind"`
 	CPUs     int    `json:"cpus"`
 	MemoryMB int    `json:"memory_mb"`
+
+	KernelArgs []string `json:"kernel_args,omitempty"`
 }
 
 const (
@@ -1264,6 +1590,9 @@ type MachineConfig struct {
 	Services []interface{}     `json:"services,omitempty"`
 	VMSize   string            `json:"size,omitempty"`
 	Guest    *MachineGuest     `json:"guest,omitempty"`
+	// Schedule is the cron expression denoting the times the machine is
+	// booted at, for machines which run recurring jobs.
+	Schedule string `json:"schedule,omitempty"`
 }
 
 type DeleteOrganizationMembershipPayload struct {
@@ -6,7 +6,10 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/BurntSushi/toml"
 	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/autoscale"
 	"github.com/superfly/flyctl/internal/client"
 
 	"github.com/superfly/flyctl/api"
@@ -40,6 +43,11 @@ func newAutoscaleCommand(client *client.Client) *Command {
 	showCmdStrings := docstrings.Get("autoscale.show")
 	BuildCommand(cmd, runAutoscalingShow, showCmdStrings.Usage, showCmdStrings.Short, showCmdStrings.Long, client, requireSession, requireAppName)
 
+	exportCmdStrings := docstrings.Get("autoscale.export")
+	exportCmd := BuildCommandKS(cmd, runAutoscalingExport, exportCmdStrings, client, requireSession, requireAppName)
+	exportCmd.AddBoolFlag(BoolFlagOpts{Name: "print", Description: "Print the autoscale section instead of writing it to the app config file"})
+	exportCmd.Args = cobra.NoArgs
+
 	return cmd
 }
 
@@ -157,6 +165,30 @@ func runAutoscalingShow(cmdCtx *cmdctx.CmdContext) error {
 	return nil
 }
 
+func runAutoscalingExport(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+
+	cfg, err := cmdCtx.Client.API().AppAutoscalingConfig(ctx, cmdCtx.AppName)
+	if err != nil {
+		return err
+	}
+
+	section := autoscale.FromAPI(cfg).Section()
+
+	if cmdCtx.OutputJSON() {
+		cmdCtx.WriteJSON(map[string]interface{}{autoscale.Section: section})
+		return nil
+	}
+
+	if cmdCtx.Config.GetBool("print") || cmdCtx.AppConfig == nil || !helpers.FileExists(cmdCtx.ConfigFile) {
+		return toml.NewEncoder(cmdCtx.Out).Encode(map[string]interface{}{autoscale.Section: section})
+	}
+
+	cmdCtx.AppConfig.Definition[autoscale.Section] = section
+
+	return writeAppConfig(cmdCtx.ConfigFile, cmdCtx.AppConfig)
+}
+
 func printScaleConfig(cmdCtx *cmdctx.CmdContext, cfg *api.AutoscalingConfig) {
 
 	asJSON := cmdCtx.OutputJSON()
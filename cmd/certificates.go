@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/cmdctx"
 	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/dnsprovider"
 
 	"github.com/superfly/flyctl/docstrings"
 
@@ -29,6 +31,11 @@ func newCertificatesCommand(client *client.Client) *Command {
 	createCmd := BuildCommandKS(cmd, runCertAdd, certsCreateStrings, client, requireSession, requireAppName)
 	createCmd.Aliases = []string{"create"}
 	createCmd.Command.Args = cobra.ExactArgs(1)
+	createCmd.AddStringFlag(StringFlagOpts{Name: "dns-provider", Description: fmt.Sprintf("Create the DNS records the certificate requires via the API of the given provider (%s)", strings.Join(dnsprovider.Names, ", "))})
+	createCmd.AddIntFlag(IntFlagOpts{Name: "wait-timeout", Description: "Minutes to wait for the certificate to be issued with --dns-provider. 0 disables waiting.", Default: 10})
+	createCmd.Command.Example = `flyctl certs add www.example.com -a $APP
+flyctl certs add "*.example.com" -a $APP
+CLOUDFLARE_API_TOKEN=... flyctl certs add example.com --dns-provider cloudflare -a $APP`
 
 	certsDeleteStrings := docstrings.Get("certs.remove")
 	deleteCmd := BuildCommandKS(cmd, runCertDelete, certsDeleteStrings, client, requireSession, requireAppName)
@@ -42,7 +49,16 @@ func newCertificatesCommand(client *client.Client) *Command {
 
 	certsCheckStrings := docstrings.Get("certs.check")
 	check := BuildCommandKS(cmd, runCertCheck, certsCheckStrings, client, requireSession, requireAppName)
-	check.Command.Args = cobra.ExactArgs(1)
+	check.Command.Args = cobra.MaximumNArgs(1)
+	check.AddBoolFlag(BoolFlagOpts{Name: "all", Description: "Check all certificates of the app and summarize the ones which aren't issued"})
+	check.AddIntFlag(IntFlagOpts{Name: "concurrency", Description: "Number of certificates to check at once with --all", Default: certsDefaultConcurrency})
+
+	certsImportStrings := docstrings.Get("certs.import")
+	importCmd := BuildCommandKS(cmd, runCertsImport, certsImportStrings, client, requireSession, requireAppName)
+	importCmd.Command.Args = cobra.NoArgs
+	importCmd.AddStringFlag(StringFlagOpts{Name: "from-file", Description: "File listing the hostnames to add certificates for, one per line, or - for stdin"})
+	importCmd.AddIntFlag(IntFlagOpts{Name: "concurrency", Description: "Number of certificates to add at once", Default: certsDefaultConcurrency})
+	importCmd.Command.Example = `flyctl certs import --from-file domains.txt -a $APP`
 
 	return cmd
 }
@@ -82,6 +98,15 @@ func runCertShow(commandContext *cmdctx.CmdContext) error {
 func runCertCheck(commandContext *cmdctx.CmdContext) error {
 	ctx := commandContext.Command.Context()
 
+	switch all := commandContext.Config.GetBool("all"); {
+	case all && len(commandContext.Args) > 0:
+		return errors.New("--all is mutually exclusive with a hostname")
+	case all:
+		return runCertsCheckAll(commandContext)
+	case len(commandContext.Args) == 0:
+		return errors.New("requires a hostname, or --all")
+	}
+
 	hostname := commandContext.Args[0]
 
 	cert, hostcheck, err := commandContext.Client.API().CheckAppCertificate(ctx, commandContext.AppName, hostname)
@@ -107,11 +132,24 @@ func runCertAdd(commandContext *cmdctx.CmdContext) error {
 
 	hostname := commandContext.Args[0]
 
+	// providers are set up first so they fail before the certificate is added
+	var provider dnsprovider.Provider
+	if name := commandContext.Config.GetString("dns-provider"); name != "" {
+		var err error
+		if provider, err = dnsprovider.New(name); err != nil {
+			return err
+		}
+	}
+
 	cert, hostcheck, err := commandContext.Client.API().AddCertificate(ctx, commandContext.AppName, hostname)
 	if err != nil {
 		return err
 	}
 
+	if provider != nil {
+		return configureCertDNS(commandContext, provider, cert)
+	}
+
 	return reportNextStepCert(commandContext, hostname, cert, hostcheck)
 }
 
@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jpillora/backoff"
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/helpers"
+)
+
+// certsDefaultConcurrency is the number of certificates bulk operations
+// process at once by default.
+const certsDefaultConcurrency = 5
+
+// certsRateLimitAttempts is the number of times bulk operations attempt an
+// operation the API rate limits.
+const certsRateLimitAttempts = 6
+
+// The outcomes of bulk certificate operations.
+const (
+	certAdded         = "added"
+	certExists        = "exists"
+	certIssued        = "issued"
+	certPending       = "pending"
+	certMisconfigured = "misconfigured"
+	certFailed        = "failed"
+)
+
+type certResult struct {
+	Hostname string `json:"hostname"`
+	Status   string `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+type certsReport struct {
+	Results []certResult   `json:"results"`
+	Counts  map[string]int `json:"counts"`
+}
+
+func runCertsImport(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+
+	path := cmdCtx.Config.GetString("from-file")
+	if path == "" {
+		return errors.New("--from-file is required")
+	}
+
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		in = f
+	} else if !helpers.HasPipedStdin() {
+		return errors.New("--from-file - requires hostnames via stdin")
+	}
+
+	hostnames, err := readHostnames(in)
+	if err != nil {
+		return fmt.Errorf("failed reading hostnames from %s: %w", path, err)
+	} else if len(hostnames) == 0 {
+		return fmt.Errorf("%s lists no hostnames", path)
+	}
+
+	existing, err := cmdCtx.Client.API().GetAppCertificates(ctx, cmdCtx.AppName)
+	if err != nil {
+		return err
+	}
+
+	exists := make(map[string]bool, len(existing))
+	for _, cert := range existing {
+		exists[strings.ToLower(cert.Hostname)] = true
+	}
+
+	report := runBulkCertOp(cmdCtx, hostnames, func(ctx context.Context, hostname string) certResult {
+		if exists[hostname] {
+			return certResult{Hostname: hostname, Status: certExists}
+		}
+
+		var cert *api.AppCertificate
+		err := retryRateLimited(ctx, func() (err error) {
+			cert, _, err = cmdCtx.Client.API().AddCertificate(ctx, cmdCtx.AppName, hostname)
+			return
+		})
+		if err != nil {
+			return certResult{Hostname: hostname, Status: certFailed, Detail: err.Error()}
+		}
+
+		return certResult{Hostname: hostname, Status: certAdded, Detail: cert.ClientStatus}
+	})
+
+	return printCertsReport(cmdCtx, report, certFailed)
+}
+
+func runCertsCheckAll(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+
+	certs, err := cmdCtx.Client.API().GetAppCertificates(ctx, cmdCtx.AppName)
+	if err != nil {
+		return err
+	} else if len(certs) == 0 {
+		return fmt.Errorf("app %s has no certificates", cmdCtx.AppName)
+	}
+
+	hostnames := make([]string, 0, len(certs))
+	for _, cert := range certs {
+		hostnames = append(hostnames, cert.Hostname)
+	}
+
+	report := runBulkCertOp(cmdCtx, hostnames, func(ctx context.Context, hostname string) certResult {
+		var (
+			cert      *api.AppCertificate
+			hostcheck *api.HostnameCheck
+		)
+		err := retryRateLimited(ctx, func() (err error) {
+			cert, hostcheck, err = cmdCtx.Client.API().CheckAppCertificate(ctx, cmdCtx.AppName, hostname)
+			return
+		})
+		if err != nil {
+			return certResult{Hostname: hostname, Status: certFailed, Detail: err.Error()}
+		}
+
+		return checkedCertResult(hostname, cert, hostcheck)
+	})
+
+	return printCertsReport(cmdCtx, report, certMisconfigured, certFailed)
+}
+
+// checkedCertResult returns the result of checking the certificate of the
+// given hostname.
+func checkedCertResult(hostname string, cert *api.AppCertificate, hostcheck *api.HostnameCheck) certResult {
+	switch {
+	case cert.ClientStatus == "Ready":
+		return certResult{Hostname: hostname, Status: certIssued}
+	case cert.Configured:
+		return certResult{Hostname: hostname, Status: certPending, Detail: cert.ClientStatus}
+	}
+
+	detail := "DNS isn't configured"
+	if hostcheck != nil && len(hostcheck.ResolvedAddresses) > 0 {
+		detail = fmt.Sprintf("resolves to %s", strings.Join(hostcheck.ResolvedAddresses, ", "))
+	}
+
+	return certResult{Hostname: hostname, Status: certMisconfigured, Detail: detail}
+}
+
+// readHostnames reads the hostnames r lists, one per line. Blank lines and
+// lines starting with # are skipped, as are duplicates.
+func readHostnames(r io.Reader) ([]string, error) {
+	var (
+		hostnames []string
+		seen      = map[string]bool{}
+		scanner   = bufio.NewScanner(r)
+		lineNo    int
+	)
+
+	for scanner.Scan() {
+		lineNo++
+
+		hostname := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if hostname == "" || strings.HasPrefix(hostname, "#") {
+			continue
+		}
+
+		if strings.ContainsAny(hostname, " \t/:") || !strings.Contains(hostname, ".") {
+			return nil, fmt.Errorf("line %d: %q is not a hostname", lineNo, hostname)
+		}
+
+		if !seen[hostname] {
+			seen[hostname] = true
+			hostnames = append(hostnames, hostname)
+		}
+	}
+
+	return hostnames, scanner.Err()
+}
+
+// runBulkCertOp runs op for each of the given hostnames, as many at once as
+// --concurrency allows, reporting progress as results come in.
+func runBulkCertOp(cmdCtx *cmdctx.CmdContext, hostnames []string, op func(context.Context, string) certResult) *certsReport {
+	ctx := cmdCtx.Command.Context()
+
+	concurrency := cmdCtx.Config.GetInt("concurrency")
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		work    = make(chan string)
+		results = make([]certResult, 0, len(hostnames))
+	)
+
+	for i := 0; i < concurrency && i < len(hostnames); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for hostname := range work {
+				result := op(ctx, hostname)
+
+				mu.Lock()
+				results = append(results, result)
+				if !cmdCtx.OutputJSON() {
+					fmt.Fprintf(cmdCtx.IO.ErrOut, "[%d/%d] %s: %s\n", len(results), len(hostnames), hostname, result.Status)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, hostname := range hostnames {
+		if ctx.Err() != nil {
+			break
+		}
+		work <- hostname
+	}
+	close(work)
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Hostname < results[j].Hostname
+	})
+
+	report := &certsReport{
+		Results: results,
+		Counts:  map[string]int{},
+	}
+	for _, result := range results {
+		report.Counts[result.Status]++
+	}
+
+	return report
+}
+
+// printCertsReport prints the summary of report, listing the results which
+// have any of the given failure statuses, and fails in case there are any.
+func printCertsReport(cmdCtx *cmdctx.CmdContext, report *certsReport, failures ...string) error {
+	var failed []certResult
+	for _, result := range report.Results {
+		for _, status := range failures {
+			if result.Status == status {
+				failed = append(failed, result)
+			}
+		}
+	}
+
+	if cmdCtx.OutputJSON() {
+		cmdCtx.WriteJSON(report)
+	} else {
+		statuses := make([]string, 0, len(report.Counts))
+		for status := range report.Counts {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+
+		summary := make([]string, 0, len(statuses))
+		for _, status := range statuses {
+			summary = append(summary, fmt.Sprintf("%d %s", report.Counts[status], status))
+		}
+
+		fmt.Fprintf(cmdCtx.Out, "\n%d certificates: %s\n", len(report.Results), strings.Join(summary, ", "))
+
+		if len(failed) > 0 {
+			fmt.Fprintf(cmdCtx.Out, "\nFailures\n")
+			for _, result := range failed {
+				fmt.Fprintf(cmdCtx.Out, "  %s: %s: %s\n", result.Hostname, result.Status, result.Detail)
+			}
+			fmt.Fprintf(cmdCtx.Out, "\nRun 'flyctl certs check <hostname>' for the DNS records a hostname needs.\n")
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d certificates failed", len(failed), len(report.Results))
+	}
+
+	return nil
+}
+
+// retryRateLimited runs fn, retrying with backoff for as long as the API rate
+// limits it.
+func retryRateLimited(ctx context.Context, fn func() error) (err error) {
+	b := &backoff.Backoff{
+		Min:    time.Second,
+		Max:    30 * time.Second,
+		Factor: 2,
+		Jitter: true,
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil || !isRateLimited(err) || attempt == certsRateLimitAttempts {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(b.Duration()):
+		}
+	}
+}
+
+func isRateLimited(err error) bool {
+	msg := strings.ToLower(err.Error())
+
+	return strings.Contains(msg, "rate limit") ||
+		strings.Contains(msg, "too many requests") ||
+		strings.Contains(msg, "429")
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/internal/dnsprovider"
+)
+
+// certsIssuancePollInterval is how often certs add checks whether the
+// certificates of hostnames it created the records of have been issued.
+const certsIssuancePollInterval = 10 * time.Second
+
+// configureCertDNS creates the records the certificate of hostname requires
+// via provider, then waits for the certificate to be issued.
+func configureCertDNS(cmdCtx *cmdctx.CmdContext, provider dnsprovider.Provider, cert *api.AppCertificate) error {
+	ctx := cmdCtx.Command.Context()
+
+	ips, err := cmdCtx.Client.API().GetIPAddresses(ctx, cmdCtx.AppName)
+	if err != nil {
+		return err
+	}
+
+	records, err := certDNSRecords(cmdCtx.AppName, cert, ips)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		created, err := provider.Ensure(ctx, record)
+		if err != nil {
+			return err
+		}
+
+		if !cmdCtx.OutputJSON() {
+			state := "Created"
+			if !created {
+				state = "Found"
+			}
+			fmt.Fprintf(cmdCtx.IO.ErrOut, "%s DNS record %s\n", state, record)
+		}
+	}
+
+	minutes := cmdCtx.Config.GetInt("wait-timeout")
+	if minutes <= 0 {
+		if cmdCtx.OutputJSON() {
+			cmdCtx.WriteJSON(cert)
+		} else {
+			fmt.Fprintf(cmdCtx.Out, "Check whether the certificate for %s has been issued with 'flyctl certs check %s'\n", cert.Hostname, cert.Hostname)
+		}
+
+		return nil
+	}
+
+	if cert, err = waitForCertIssuance(cmdCtx, cert.Hostname, time.Duration(minutes)*time.Minute); err != nil {
+		return err
+	}
+
+	if cmdCtx.OutputJSON() {
+		cmdCtx.WriteJSON(cert)
+	} else {
+		fmt.Fprintf(cmdCtx.Out, "The certificate for %s has been issued\n", cert.Hostname)
+	}
+
+	return nil
+}
+
+// certDNSRecords returns the records the certificate of the named app
+// requires: A and AAAA records for apex and wildcard hostnames, a CNAME
+// record for others, along with the CNAME record which validates the
+// ownership of the hostname.
+func certDNSRecords(appName string, cert *api.AppCertificate, ips []api.IPAddress) ([]dnsprovider.Record, error) {
+	var records []dnsprovider.Record
+
+	if cert.IsApex || cert.IsWildcard {
+		for _, ip := range ips {
+			switch ip.Type {
+			case "v4":
+				records = append(records, dnsprovider.Record{Type: "A", Name: cert.Hostname, Content: ip.Address})
+			case "v6":
+				records = append(records, dnsprovider.Record{Type: "AAAA", Name: cert.Hostname, Content: ip.Address})
+			}
+		}
+
+		if len(records) == 0 {
+			return nil, fmt.Errorf("app %s has no public IP addresses to point %s at; allocate them with 'flyctl ips allocate-v4' and 'flyctl ips allocate-v6'", appName, cert.Hostname)
+		}
+	} else {
+		records = append(records, dnsprovider.Record{Type: "CNAME", Name: cert.Hostname, Content: appName + ".fly.dev"})
+	}
+
+	if cert.DNSValidationHostname != "" && cert.DNSValidationTarget != "" {
+		records = append(records, dnsprovider.Record{
+			Type:    "CNAME",
+			Name:    strings.TrimSuffix(cert.DNSValidationHostname, "."),
+			Content: strings.TrimSuffix(cert.DNSValidationTarget, "."),
+		})
+	}
+
+	return records, nil
+}
+
+// waitForCertIssuance polls the certificate of hostname until it's issued, or
+// fails once timeout elapses.
+func waitForCertIssuance(cmdCtx *cmdctx.CmdContext, hostname string, timeout time.Duration) (*api.AppCertificate, error) {
+	ctx, cancel := context.WithTimeout(cmdCtx.Command.Context(), timeout)
+	defer cancel()
+
+	var status string
+	for {
+		cert, _, err := cmdCtx.Client.API().CheckAppCertificate(ctx, cmdCtx.AppName, hostname)
+		switch {
+		case ctx.Err() != nil:
+			// reported below
+		case err != nil:
+			return nil, err
+		case cert.ClientStatus == "Ready":
+			return cert, nil
+		case cert.ClientStatus != status && !cmdCtx.OutputJSON():
+			status = cert.ClientStatus
+			fmt.Fprintf(cmdCtx.IO.ErrOut, "Waiting for the certificate for %s to be issued; status is %s\n", hostname, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			if cmdCtx.Command.Context().Err() != nil {
+				return nil, cmdCtx.Command.Context().Err()
+			}
+
+			return nil, fmt.Errorf("the certificate for %s wasn't issued within %s; DNS changes may take a while to propagate, check on it with 'flyctl certs check %s'", hostname, timeout, hostname)
+		case <-time.After(certsIssuancePollInterval):
+		}
+	}
+}
@@ -1,9 +1,16 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"strconv"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/morikuni/aec"
 	"github.com/spf13/cobra"
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/cmd/presenters"
@@ -11,6 +18,8 @@ import (
 	"github.com/superfly/flyctl/docstrings"
 	"github.com/superfly/flyctl/helpers"
 	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/healthcheck"
+	"github.com/superfly/flyctl/terminal"
 )
 
 func newChecksCommand(client *client.Client) *Command {
@@ -36,6 +45,28 @@ func newChecksCommand(client *client.Client) *Command {
 	checksListStrings := docstrings.Get("checks.list")
 	listChecksCmd := BuildCommandKS(cmd, runAppCheckList, checksListStrings, client, requireSession, requireAppName)
 	listChecksCmd.AddStringFlag(StringFlagOpts{Name: "check-name", Description: "Filter checks by name"})
+	listChecksCmd.AddBoolFlag(BoolFlagOpts{Name: "watch", Description: "Refresh the checks every --rate seconds, showing their transitions as they happen"})
+	listChecksCmd.AddIntFlag(IntFlagOpts{Name: "rate", Description: "Refresh rate for --watch, in seconds", Default: 5})
+
+	checksHistoryStrings := docstrings.Get("checks.history")
+	historyChecksCmd := BuildCommandKS(cmd, runAppCheckHistory, checksHistoryStrings, client, requireSession, requireAppName)
+	historyChecksCmd.Args = cobra.ExactArgs(1)
+	historyChecksCmd.AddIntFlag(IntFlagOpts{Name: "limit", Description: "Number of most recent transitions to show", Default: 50})
+
+	checksReportStrings := docstrings.Get("checks.report")
+	reportChecksCmd := BuildCommandKS(cmd, runAppCheckReport, checksReportStrings, client, requireSession, requireAppName)
+	reportChecksCmd.Args = cobra.NoArgs
+	reportChecksCmd.AddStringFlag(StringFlagOpts{Name: "last", Description: "Window to report on, e.g. 24h or 7d", Default: "7d"})
+	reportChecksCmd.AddStringFlag(StringFlagOpts{Name: "check-name", Description: "Report on the named check only"})
+
+	checksUpdateStrings := docstrings.Get("checks.update")
+	updateChecksCmd := BuildCommandKS(cmd, runAppCheckUpdate, checksUpdateStrings, client, requireSession, requireAppName)
+	updateChecksCmd.Args = cobra.MaximumNArgs(1)
+	updateChecksCmd.AddStringFlag(StringFlagOpts{Name: "interval", Description: "Time between runs of the checks, e.g. 15s"})
+	updateChecksCmd.AddStringFlag(StringFlagOpts{Name: "timeout", Description: "Time the checks may take before they fail, e.g. 5s"})
+	updateChecksCmd.AddStringFlag(StringFlagOpts{Name: "grace-period", Description: "Time after instances start during which failing checks are ignored, e.g. 30s"})
+	updateChecksCmd.AddStringFlag(StringFlagOpts{Name: "strategy", Description: "The strategy for rolling out the updated checks, for apps whose checks are updated via a release"})
+	updateChecksCmd.AddBoolFlag(BoolFlagOpts{Name: "detach", Description: "Return once the release is created instead of monitoring it"})
 
 	return cmd
 }
@@ -242,29 +273,259 @@ func runAppCheckList(cmdCtx *cmdctx.CmdContext) error {
 		nameFilter = api.StringPointer(val)
 	}
 
+	if !cmdCtx.Config.GetBool("watch") {
+		checks, _, err := recordAppChecks(ctx, cmdCtx, nameFilter)
+		if err != nil {
+			return err
+		}
+
+		if cmdCtx.OutputJSON() {
+			cmdCtx.WriteJSON(checks)
+			return nil
+		}
+
+		renderAppChecks(cmdCtx.Out, cmdCtx.AppName, checks)
+
+		return nil
+	}
+
+	rate := cmdCtx.Config.GetInt("rate")
+	if rate < 1 || rate > 3600 {
+		return errors.New("--rate must be in the [1, 3600] range")
+	}
+
+	var (
+		interactive = cmdCtx.IO.IsInteractive() && !cmdCtx.OutputJSON()
+		recent      []healthcheck.Transition
+	)
+
+	ticker := time.NewTicker(time.Duration(rate) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		checks, transitions, err := recordAppChecks(ctx, cmdCtx, nameFilter)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case cmdCtx.OutputJSON():
+			// a line per transition, so that watchers may stream them
+			enc := json.NewEncoder(cmdCtx.Out)
+			for _, t := range transitions {
+				if err := enc.Encode(t); err != nil {
+					return err
+				}
+			}
+		case interactive:
+			if recent = append(recent, transitions...); len(recent) > checkWatchTransitions {
+				recent = recent[len(recent)-checkWatchTransitions:]
+			}
+
+			fmt.Fprint(cmdCtx.Out, aec.EraseDisplay(aec.EraseModes.All).With(aec.Position(1, 1)))
+			renderAppChecks(cmdCtx.Out, cmdCtx.AppName, checks)
+			fmt.Fprintf(cmdCtx.Out, "\nRecent Transitions (refreshing every %ds)\n", rate)
+			renderCheckTransitions(cmdCtx.Out, recent, false)
+		default:
+			renderCheckTransitions(cmdCtx.Out, transitions, false)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkWatchTransitions is the number of recent transitions checks list
+// --watch shows.
+const checkWatchTransitions = 10
+
+// recordAppChecks fetches the checks of the app and records their states in
+// its local check history, returning the transitions they denote. Failing to
+// record them is only warned of.
+func recordAppChecks(ctx context.Context, cmdCtx *cmdctx.CmdContext, nameFilter *string) ([]api.CheckState, []healthcheck.Transition, error) {
 	checks, err := cmdCtx.Client.API().GetAppHealthChecks(ctx, cmdCtx.AppName, nameFilter, nil, api.BoolPointer(true))
 	if err != nil {
+		return nil, nil, err
+	}
+
+	transitions, err := healthcheck.Record(ctx, cmdCtx.AppName, checks)
+	if err != nil {
+		terminal.Warnf("failed recording check history: %v\n", err)
+	}
+
+	return checks, transitions, nil
+}
+
+func renderAppChecks(w io.Writer, appName string, checks []api.CheckState) {
+	fmt.Fprintf(w, "Health Checks for %s\n", appName)
+
+	table := helpers.MakeSimpleTable(w, []string{"Name", "Status", "Allocation", "Region", "Type", "Last Updated", "Output"})
+
+	for _, check := range checks {
+		var allocID, region string
+		if check.Allocation != nil {
+			allocID, region = check.Allocation.IDShort, check.Allocation.Region
+		}
+
+		table.Append([]string{check.Name, check.Status, allocID, region, check.Type, presenters.FormatRelativeTime(check.UpdatedAt), check.Output})
+	}
+
+	table.Render()
+}
+
+func renderCheckTransitions(w io.Writer, transitions []healthcheck.Transition, output bool) {
+	if len(transitions) == 0 {
+		return
+	}
+
+	cols := []string{"Time", "Check", "Allocation", "Region", "Transition"}
+	if output {
+		cols = append(cols, "Output")
+	}
+
+	table := helpers.MakeSimpleTable(w, cols)
+
+	for _, t := range transitions {
+		transition := t.From + " -> " + t.To
+		if t.IsFirstSeen() {
+			transition = t.To + " (first seen)"
+		}
+
+		row := []string{t.At.Local().Format(time.RFC3339), t.Check, t.Allocation, t.Region, transition}
+		if output {
+			row = append(row, truncateCheckOutput(t.Output))
+		}
+
+		table.Append(row)
+	}
+
+	table.Render()
+}
+
+func runAppCheckHistory(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+	name := cmdCtx.Args[0]
+
+	limit := cmdCtx.Config.GetInt("limit")
+	if limit < 1 {
+		return errors.New("--limit must be positive")
+	}
+
+	// record the current states first, so that the history is up to date
+	if _, _, err := recordAppChecks(ctx, cmdCtx, api.StringPointer(name)); err != nil {
 		return err
 	}
 
+	history, err := healthcheck.Load(ctx, cmdCtx.AppName)
 	if err != nil {
+		return fmt.Errorf("failed loading check history: %w", err)
+	}
+
+	transitions := history.Since(name, time.Time{})
+	if len(transitions) > limit {
+		transitions = transitions[len(transitions)-limit:]
+	}
+
+	if cmdCtx.OutputJSON() {
+		cmdCtx.WriteJSON(transitions)
+		return nil
+	}
+
+	if len(transitions) == 0 {
+		fmt.Fprintf(cmdCtx.Out, "No history recorded for check %s of %s\n", name, cmdCtx.AppName)
+		return nil
+	}
+
+	fmt.Fprintf(cmdCtx.Out, "History of check %s of %s, as observed by flyctl\n", name, cmdCtx.AppName)
+	renderCheckTransitions(cmdCtx.Out, transitions, true)
+
+	return nil
+}
+
+// checkReportWidth is the width of the timelines of checks report.
+const checkReportWidth = 28
+
+func runAppCheckReport(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+
+	last, err := helpers.ParseDuration(cmdCtx.Config.GetString("last"))
+	if err != nil || last <= 0 {
+		return errors.New("--last must be a positive duration, e.g. 24h or 7d")
+	}
+
+	var nameFilter *string
+	name := cmdCtx.Config.GetString("check-name")
+	if name != "" {
+		nameFilter = api.StringPointer(name)
+	}
+
+	// record the current states first, so that the report is up to date
+	if _, _, err := recordAppChecks(ctx, cmdCtx, nameFilter); err != nil {
 		return err
 	}
 
+	history, err := healthcheck.Load(ctx, cmdCtx.AppName)
+	if err != nil {
+		return fmt.Errorf("failed loading check history: %w", err)
+	}
+
+	now := time.Now().UTC()
+	report := history.Report(name, now.Add(-last), now, checkReportWidth)
+
 	if cmdCtx.OutputJSON() {
-		cmdCtx.WriteJSON(checks)
+		cmdCtx.WriteJSON(report)
 		return nil
 	}
 
-	fmt.Fprintf(cmdCtx.Out, "Health Checks for %s\n", cmdCtx.AppName)
+	if len(report.Checks) == 0 {
+		fmt.Fprintf(cmdCtx.Out, "No check history recorded for %s\n", cmdCtx.AppName)
+		return nil
+	}
 
-	table := helpers.MakeSimpleTable(cmdCtx.Out, []string{"Name", "Status", "Allocation", "Region", "Type", "Last Updated", "Output"})
+	fmt.Fprintf(cmdCtx.Out, "Stability score of %s over the last %s: %.1f/100 (%d checks, %d flapping)\n\n",
+		cmdCtx.AppName, cmdCtx.Config.GetString("last"), report.Score, len(report.Checks), len(report.Flapping()))
 
-	for _, check := range checks {
-		table.Append([]string{check.Name, check.Status, check.Allocation.IDShort, check.Allocation.Region, check.Type, presenters.FormatRelativeTime(check.UpdatedAt), check.Output})
+	table := helpers.MakeSimpleTable(cmdCtx.Out, []string{"Check", "Allocation", "Region", "Status", "Changes", "Per Day", "Passing", "Timeline", ""})
+
+	for _, c := range report.Checks {
+		flapping := ""
+		if c.Flapping {
+			flapping = "flapping"
+		}
+
+		table.Append([]string{
+			c.Check,
+			c.Allocation,
+			c.Region,
+			c.Status,
+			strconv.Itoa(c.Changes),
+			strconv.FormatFloat(c.ChangesPerDay, 'f', 1, 64),
+			strconv.FormatFloat(100*c.Passing, 'f', 1, 64) + "%",
+			"|" + c.Timeline + "|",
+			flapping,
+		})
 	}
 
 	table.Render()
 
+	fmt.Fprintf(cmdCtx.Out, "\nTimelines span the window, oldest first: ▁ passing, ▄ warning, █ critical.\n")
+
+	if len(report.Flapping()) > 0 {
+		fmt.Fprintf(cmdCtx.Out, "Checks are flapping when they change status %d or more times over the window; consider raising their\ngrace_period, interval or timeout, or looking into what the flapping instances have in common.\n", healthcheck.FlapThreshold)
+	}
+
 	return nil
 }
+
+func truncateCheckOutput(output string) string {
+	const max = 60
+
+	if r := []rune(output); len(r) > max {
+		return string(r[:max-3]) + "..."
+	}
+
+	return output
+}
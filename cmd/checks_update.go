@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/internal/healthcheck"
+)
+
+func runAppCheckUpdate(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+
+	tuning, err := checkTuning(cmdCtx)
+	if err != nil {
+		return err
+	}
+
+	var name string
+	if len(cmdCtx.Args) > 0 {
+		name = cmdCtx.Args[0]
+	}
+
+	app, err := cmdCtx.Client.API().GetAppCompact(ctx, cmdCtx.AppName)
+	if err != nil {
+		return err
+	}
+
+	if app.PlatformVersion == "machines" {
+		return updateMachineChecks(cmdCtx, name, tuning)
+	}
+
+	return updateReleaseChecks(cmdCtx, name, tuning)
+}
+
+// checkTuning returns the tuning the interval, timeout and grace period flags
+// denote.
+func checkTuning(cmdCtx *cmdctx.CmdContext) (t healthcheck.Tuning, err error) {
+	for flag, d := range map[string]*time.Duration{
+		"interval":     &t.Interval,
+		"timeout":      &t.Timeout,
+		"grace-period": &t.GracePeriod,
+	} {
+		s := cmdCtx.Config.GetString(flag)
+		if s == "" {
+			continue
+		}
+
+		if *d, err = time.ParseDuration(s); err != nil || *d <= 0 {
+			return t, fmt.Errorf("invalid --%s %q; use a positive duration, e.g. 15s", flag, s)
+		}
+	}
+
+	if t.IsZero() {
+		err = errors.New("specify at least one of --interval, --timeout and --grace-period")
+	}
+
+	return
+}
+
+// updateReleaseChecks creates a release of the image the app runs with its
+// current config, only with the checks tuned.
+func updateReleaseChecks(cmdCtx *cmdctx.CmdContext, name string, tuning healthcheck.Tuning) error {
+	ctx := cmdCtx.Command.Context()
+	client := cmdCtx.Client.API()
+
+	cfg, err := client.GetConfig(ctx, cmdCtx.AppName)
+	if err != nil {
+		return err
+	}
+
+	tuned := healthcheck.TuneDefinition(cfg.Definition, name, tuning)
+	if err := checkTuned(cmdCtx, name, tuned); err != nil {
+		return err
+	}
+
+	// the newest release may have failed or still be rolling out, so the
+	// image of the newest stable one is the one the app runs
+	releases, err := client.GetAllAppReleases(ctx, cmdCtx.AppName)
+	if err != nil {
+		return err
+	}
+
+	var image string
+	for _, release := range releases {
+		if release.Stable && release.ImageRef != "" {
+			image = release.ImageRef
+
+			break
+		}
+	}
+
+	if image == "" {
+		return fmt.Errorf("app %s has no stable release to update the checks of; deploy it first", cmdCtx.AppName)
+	}
+
+	input := api.DeployImageInput{
+		AppID:      cmdCtx.AppName,
+		Image:      image,
+		Definition: api.DefinitionPtr(cfg.Definition),
+	}
+	if val := cmdCtx.Config.GetString("strategy"); val != "" {
+		input.Strategy = api.StringPointer(strings.ToUpper(val))
+	}
+
+	release, releaseCommand, err := client.DeployImage(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmdCtx.Out, "Release v%d created with the checks updated: %s\n", release.Version, strings.Join(tuned, ", "))
+	printCheckDrift(cmdCtx)
+
+	if cmdCtx.Config.GetBool("detach") {
+		return nil
+	}
+
+	if releaseCommand != nil {
+		if err := watchReleaseCommand(ctx, cmdCtx, client, releaseCommand.ID); err != nil {
+			return err
+		}
+
+		if release, err = client.GetAppRelease(ctx, cmdCtx.AppName, release.ID); err != nil {
+			return err
+		}
+	}
+
+	if release.DeploymentStrategy == "IMMEDIATE" {
+		return nil
+	}
+
+	return watchDeployment(ctx, cmdCtx, release.EvaluationID)
+}
+
+// updateMachineChecks tunes the checks of the services of each machine of the
+// app, leaving the rest of their configs as they are.
+func updateMachineChecks(cmdCtx *cmdctx.CmdContext, name string, tuning healthcheck.Tuning) error {
+	ctx := cmdCtx.Command.Context()
+	client := cmdCtx.Client.API()
+
+	all, err := client.ListMachines(ctx, cmdCtx.AppName, "")
+	if err != nil {
+		return err
+	}
+
+	var updated int
+	for _, m := range all {
+		if m.State == "destroyed" || m.State == "destroying" {
+			continue
+		}
+
+		tuned := healthcheck.TuneMachineServices(m.Config.Services, name, tuning)
+		if len(tuned) == 0 {
+			continue
+		}
+
+		input := api.LaunchMachineInput{
+			AppID:  cmdCtx.AppName,
+			ID:     m.ID,
+			Name:   m.Name,
+			Region: m.Region,
+			Config: &m.Config,
+		}
+
+		if _, _, err := client.LaunchMachine(ctx, input); err != nil {
+			return fmt.Errorf("failed updating machine %s (%d machines updated): %w", m.ID, updated, err)
+		}
+		updated++
+
+		fmt.Fprintf(cmdCtx.Out, "Updated the checks of machine %s: %s\n", m.ID, strings.Join(tuned, ", "))
+	}
+
+	if updated == 0 {
+		return checkTuned(cmdCtx, name, nil)
+	}
+	printCheckDrift(cmdCtx)
+
+	return nil
+}
+
+func checkTuned(cmdCtx *cmdctx.CmdContext, name string, tuned []string) error {
+	switch {
+	case len(tuned) > 0:
+		return nil
+	case name != "":
+		return fmt.Errorf("app %s has no check named %s; see 'flyctl checks list'", cmdCtx.AppName, name)
+	default:
+		return fmt.Errorf("app %s has no checks", cmdCtx.AppName)
+	}
+}
+
+// printCheckDrift reminds that the app config still has the previous
+// parameters, which the next deploy restores.
+func printCheckDrift(cmdCtx *cmdctx.CmdContext) {
+	fmt.Fprintln(cmdCtx.IO.ErrOut, "Update the checks in fly.toml as well, or the next deploy restores their previous settings")
+}
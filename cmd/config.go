@@ -6,11 +6,17 @@ import (
 
 	"github.com/superfly/flyctl/cmd/presenters"
 	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/internal/autoscale"
 	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/cmdutil"
+	"github.com/superfly/flyctl/internal/configcrypt"
+	"github.com/superfly/flyctl/internal/processgroup"
+	"github.com/superfly/flyctl/pkg/machines"
 
 	"github.com/superfly/flyctl/docstrings"
 
 	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/flyctl"
 	"github.com/superfly/flyctl/helpers"
@@ -34,6 +40,16 @@ func newConfigCommand(client *client.Client) *Command {
 	configEnvStrings := docstrings.Get("config.env")
 	BuildCommandKS(cmd, runEnvConfig, configEnvStrings, client, requireSession, requireAppName)
 
+	configDiffStrings := docstrings.Get("config.diff")
+	BuildCommandKS(cmd, runDiffConfig, configDiffStrings, client, requireSession, requireAppName)
+
+	configLockStrings := docstrings.Get("config.lock")
+	BuildCommandKS(cmd, runLockConfig, configLockStrings, client, optionalAppName)
+
+	configUnlockStrings := docstrings.Get("config.unlock")
+	unlockCmd := BuildCommandKS(cmd, runUnlockConfig, configUnlockStrings, client, optionalAppName)
+	unlockCmd.Args = cobra.NoArgs
+
 	return cmd
 }
 
@@ -93,7 +109,26 @@ func runValidateConfig(commandContext *cmdctx.CmdContext) error {
 
 	commandContext.Status("config", cmdctx.STITLE, "Validating", commandContext.ConfigFile)
 
-	serverCfg, err := commandContext.Client.API().ParseConfig(ctx, commandContext.AppName, commandContext.AppConfig.Definition)
+	definition := commandContext.AppConfig.Definition
+	if problems := append(cmdutil.ValidateKillSettings(definition), machines.ValidateTunables(definition)...); len(problems) > 0 {
+		printAppConfigErrors(api.AppConfig{Errors: problems})
+
+		return errors.New("App configuration is not valid")
+	}
+
+	if _, err := autoscale.FromDefinition(definition); err != nil {
+		printAppConfigErrors(api.AppConfig{Errors: []string{err.Error()}})
+
+		return errors.New("App configuration is not valid")
+	}
+
+	if _, err := processgroup.FromDefinition(definition); err != nil {
+		printAppConfigErrors(api.AppConfig{Errors: []string{err.Error()}})
+
+		return errors.New("App configuration is not valid")
+	}
+
+	serverCfg, err := commandContext.Client.API().ParseConfig(ctx, commandContext.AppName, remoteDefinition(definition))
 	if err != nil {
 		return err
 	}
@@ -151,6 +186,99 @@ func runEnvConfig(cmdCtx *cmdctx.CmdContext) error {
 	return nil
 }
 
+func runDiffConfig(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+
+	if cmdCtx.AppConfig == nil {
+		return errors.New("App config file not found")
+	}
+
+	localCfg, err := cmdCtx.Client.API().ParseConfig(ctx, cmdCtx.AppName, remoteDefinition(cmdCtx.AppConfig.Definition))
+	if err != nil {
+		return err
+	}
+
+	if !localCfg.Valid {
+		printAppConfigErrors(*localCfg)
+
+		return errors.New("App configuration is not valid")
+	}
+
+	deployedCfg, err := cmdCtx.Client.API().GetConfig(ctx, cmdCtx.AppName)
+	if err != nil {
+		return err
+	}
+
+	diff, err := cmdutil.DiffDefinitions("deployed", deployedCfg.Definition, helpers.PathRelativeToCWD(cmdCtx.ConfigFile), localCfg.Definition)
+	if err != nil {
+		return err
+	}
+
+	if cmdCtx.OutputJSON() {
+		cmdCtx.WriteJSON(map[string]string{"diff": diff})
+
+		return nil
+	}
+
+	if diff == "" {
+		fmt.Fprintln(cmdCtx.Out, cmdCtx.IO.ColorScheme().SuccessIcon(), "Configuration matches the deployed one")
+
+		return nil
+	}
+
+	fmt.Fprint(cmdCtx.Out, cmdutil.ColorizeDiff(diff, cmdCtx.IO.ColorScheme()))
+
+	return nil
+}
+
+func runLockConfig(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+
+	if !helpers.FileExists(cmdCtx.ConfigFile) {
+		return errors.New("App config file not found")
+	}
+
+	n, err := configcrypt.Lock(ctx, cmdCtx.AppConfig.Definition, cmdCtx.Args...)
+	if err != nil {
+		return err
+	}
+
+	if err := cmdCtx.AppConfig.WriteToFile(cmdCtx.ConfigFile); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmdCtx.Out, "Encrypted %d values of %s\n", n, helpers.PathRelativeToCWD(cmdCtx.ConfigFile))
+
+	return nil
+}
+
+func runUnlockConfig(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+
+	if !helpers.FileExists(cmdCtx.ConfigFile) {
+		return errors.New("App config file not found")
+	}
+
+	n, err := configcrypt.Unlock(ctx, cmdCtx.AppConfig.Definition)
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		fmt.Fprintf(cmdCtx.Out, "%s has no encrypted values\n", helpers.PathRelativeToCWD(cmdCtx.ConfigFile))
+
+		return nil
+	}
+
+	if err := cmdCtx.AppConfig.WriteToFile(cmdCtx.ConfigFile); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmdCtx.Out, "Decrypted %d values of %s; run 'fly config lock' to encrypt them again\n", n, helpers.PathRelativeToCWD(cmdCtx.ConfigFile))
+
+	return nil
+}
+
 func printAppConfigErrors(cfg api.AppConfig) {
 	fmt.Println()
 	for _, error := range cfg.Errors {
@@ -159,6 +287,23 @@ func printAppConfigErrors(cfg api.AppConfig) {
 	fmt.Println()
 }
 
+// remoteDefinition returns def without the sections and settings which stay
+// local.
+func remoteDefinition(def map[string]interface{}) map[string]interface{} {
+	remote := make(map[string]interface{}, len(def))
+	for k, v := range def {
+		if k != autoscale.Section {
+			remote[k] = v
+		}
+	}
+
+	// process groups the platform can't make sense of are left for it to
+	// report, along with the rest of the problems of the definition
+	_, _ = processgroup.Normalize(remote)
+
+	return remote
+}
+
 func writeAppConfig(path string, appConfig *flyctl.AppConfig) error {
 
 	if err := appConfig.WriteToFile(path); err != nil {
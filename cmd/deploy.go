@@ -23,6 +23,7 @@ import (
 	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/internal/spinner"
 	"github.com/superfly/flyctl/pkg/logs"
+	"github.com/superfly/flyctl/pkg/machines"
 	"github.com/superfly/flyctl/terminal"
 	"golang.org/x/sync/errgroup"
 )
@@ -51,7 +52,15 @@ func runDeploy(cmdCtx *cmdctx.CmdContext) error {
 		cmdCtx.AppConfig.SetEnvVariables(parsedEnv)
 	}
 
-	parsedCfg, err := cmdCtx.Client.API().ParseConfig(ctx, cmdCtx.AppName, cmdCtx.AppConfig.Definition)
+	definition := cmdCtx.AppConfig.Definition
+	if problems := append(cmdutil.ValidateKillSettings(definition), machines.ValidateTunables(definition)...); len(problems) > 0 {
+		for _, problem := range problems {
+			cmdCtx.Status("deploy", cmdctx.SERROR, "   ", aurora.Red("✘").String(), problem)
+		}
+		return errors.New("app configuration is not valid")
+	}
+
+	parsedCfg, err := cmdCtx.Client.API().ParseConfig(ctx, cmdCtx.AppName, remoteDefinition(cmdCtx.AppConfig.Definition))
 	if err != nil {
 		if parsedCfg == nil {
 			// No error data has been returned
@@ -0,0 +1,444 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	hero "github.com/heroku/heroku-go/v5"
+	"github.com/logrusorgru/aurora"
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/docstrings"
+	"github.com/superfly/flyctl/flyctl"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+func newImportCommand(client *client.Client) *Command {
+	importStrings := docstrings.Get("import")
+	cmd := BuildCommandKS(nil, nil, importStrings, client, requireSession)
+
+	herokuStrings := docstrings.Get("import.heroku")
+	heroku := BuildCommandKS(cmd, runImportHeroku, herokuStrings, client, requireSession)
+	heroku.Args = cobra.ExactArgs(1)
+
+	heroku.AddStringFlag(StringFlagOpts{
+		Name:        "heroku-token",
+		Description: "Heroku API token",
+		EnvName:     "HEROKU_TOKEN",
+	})
+	heroku.AddStringFlag(StringFlagOpts{
+		Name:        "org",
+		Description: "The organization that will own the app",
+	})
+	heroku.AddStringFlag(StringFlagOpts{
+		Name:        "name",
+		Description: "The name of the new app. Defaults to the name of the Heroku app",
+	})
+	heroku.AddStringFlag(StringFlagOpts{
+		Name:        "region",
+		Description: "The region to launch the new app in",
+	})
+	heroku.AddBoolFlag(BoolFlagOpts{
+		Name:        "set-secrets",
+		Description: "Set the config vars the plan marks as secrets on the new app",
+	})
+	heroku.AddBoolFlag(BoolFlagOpts{
+		Name:        "deploy",
+		Description: "Deploy the new app once it's been created. Implies --set-secrets",
+	})
+
+	return cmd
+}
+
+// herokuImport wraps the migration plan of a Heroku app.
+type herokuImport struct {
+	Env       map[string]string
+	Secrets   map[string]string
+	Addons    []herokuAddon
+	Processes []herokuProcess
+}
+
+type herokuAddon struct {
+	Name       string
+	Service    string
+	Plan       string
+	ConfigVars []string
+}
+
+type herokuProcess struct {
+	Name     string
+	Command  string
+	Quantity int
+	Size     string
+}
+
+// herokuAddonReplacements maps the Heroku add-on services we know of to
+// suggestions for their replacements on Fly.
+var herokuAddonReplacements = map[string]string{
+	"heroku-postgresql": "create a Postgres cluster via postgres create and attach it via postgres attach",
+	"heroku-redis":      "deploy a Redis app on Fly and point the app to it",
+	"papertrail":        "ship the app's logs via a log shipper app",
+	"scheduler":         "run the scheduled commands via a cron process",
+}
+
+// secretVarMarkers denotes the words of config var names which mark them as
+// secrets. Names are split into words on underscores and dashes, so that the
+// likes of KEYBOARD_LAYOUT don't match.
+var secretVarMarkers = map[string]struct{}{
+	"SECRET": {}, "KEY": {}, "APIKEY": {}, "TOKEN": {}, "PASSWORD": {}, "PASS": {}, "PASSWD": {},
+	"PRIVATE": {}, "CREDENTIAL": {}, "CREDENTIALS": {}, "AUTH": {}, "DSN": {},
+}
+
+// secretVarSuffixes denotes the suffixes of the names of config vars which hold
+// connection strings, which commonly embed credentials. Other URLs, such as
+// SITE_URL, are plain config vars.
+var secretVarSuffixes = []string{
+	"DATABASE_URL", "REDIS_URL", "MONGODB_URI", "MONGO_URL", "AMQP_URL",
+}
+
+func isSecretVar(name string) bool {
+	name = strings.ToUpper(name)
+
+	for _, suffix := range secretVarSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_' || r == '-'
+	})
+	for _, word := range words {
+		if _, ok := secretVarMarkers[word]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func runImportHeroku(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+	fly := cmdCtx.Client.API()
+
+	herokuToken := cmdCtx.Config.GetString("heroku-token")
+	if herokuToken == "" {
+		return fmt.Errorf("heroku-token is required")
+	}
+	hero.DefaultTransport.BearerToken = herokuToken
+	heroku := hero.NewService(hero.DefaultClient)
+
+	herokuAppName := cmdCtx.Args[0]
+
+	hkApp, err := heroku.AppInfo(ctx, herokuAppName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving heroku app %s: %w", herokuAppName, err)
+	}
+	fmt.Printf("Using heroku app: %s\n", hkApp.Name)
+
+	split := func(vars map[string]string) (env, secrets map[string]string, err error) {
+		return splitSecretVars(cmdCtx, "config vars of "+hkApp.Name, vars)
+	}
+
+	plan, slug, err := planHerokuImport(ctx, heroku, hkApp, split)
+	if err != nil {
+		return err
+	}
+
+	regionCode := cmdCtx.Config.GetString("region")
+	if regionCode != "" {
+		region, err := selectRegion(ctx, fly, regionCode)
+		if err != nil {
+			return err
+		}
+		regionCode = region.Code
+	} else if hkApp.Region.Name == "us" {
+		// Heroku regions are in Virginia (US) and Ireland (EU)
+		regionCode = "iad"
+	} else {
+		regionCode = "lhr"
+	}
+	fmt.Printf("Selected fly region: %s\n", regionCode)
+
+	appName := cmdCtx.Config.GetString("name")
+	if appName == "" {
+		if appName, err = inputAppName(hkApp.Name, false); err != nil {
+			return err
+		}
+	}
+
+	org, err := selectOrganization(ctx, fly, cmdCtx.Config.GetString("org"), nil)
+	if err != nil {
+		return err
+	}
+
+	app, err := fly.CreateApp(ctx, api.CreateAppInput{
+		Name:            appName,
+		Runtime:         "FIRECRACKER",
+		OrganizationID:  org.ID,
+		PreferredRegion: api.StringPointer(regionCode),
+	})
+	switch isTakenError(err) {
+	case nil:
+		fmt.Printf("New app created: %s\n", app.Name)
+	case errAppNameTaken:
+		return fmt.Errorf("app %s already exists; please pick another name via --name", appName)
+	default:
+		return err
+	}
+
+	appConfig := flyctl.NewAppConfig()
+	appConfig.AppName = app.Name
+	appConfig.Definition = app.Config.Definition
+	if len(plan.Env) > 0 {
+		appConfig.SetEnvVariables(plan.Env)
+	}
+
+	var procfile strings.Builder
+	for _, process := range plan.Processes {
+		if process.Quantity == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&procfile, "%s: %s\n", process.Name, process.Command)
+
+		// 'app' is the default process in our config
+		name := process.Name
+		if name == "web" {
+			name = "app"
+		}
+		appConfig.SetProcess(name, process.Command)
+	}
+	if command, ok := slug.ProcessTypes["release"]; ok {
+		appConfig.SetReleaseCommand(command)
+	}
+
+	if err := os.MkdirAll(app.Name, 0750); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(app.Name, "Procfile"), []byte(procfile.String()), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Procfile created: %s/Procfile\n", app.Name)
+
+	if err := createDockerfile(app.Name, slug.Stack.Name, slug.Blob.URL); err != nil {
+		return err
+	}
+	fmt.Printf("Dockerfile created: %s/Dockerfile\n", app.Name)
+
+	if err := writeAppConfig(filepath.Join(app.Name, "fly.toml"), appConfig); err != nil {
+		return err
+	}
+
+	deploy := cmdCtx.Config.GetBool("deploy")
+	setSecrets := deploy || cmdCtx.Config.GetBool("set-secrets")
+
+	if setSecrets && len(plan.Secrets) > 0 {
+		if _, err := fly.SetSecrets(ctx, app.Name, plan.Secrets); err != nil {
+			return fmt.Errorf("failed setting secrets of %s: %w", app.Name, err)
+		}
+		cmdCtx.Statusf("secrets", cmdctx.SINFO, "Secrets are staged for the first deployment\n")
+	}
+
+	printHerokuImportPlan(app.Name, plan, setSecrets)
+
+	if !deploy {
+		return nil
+	}
+
+	cmdCtx.AppName = app.Name
+	cmdCtx.AppConfig = appConfig
+	cmdCtx.WorkingDir = filepath.Join(cmdCtx.WorkingDir, app.Name)
+	cmdCtx.ConfigFile = filepath.Join(cmdCtx.WorkingDir, "fly.toml")
+
+	if err := runDeploy(cmdCtx); err != nil {
+		return err
+	}
+	fmt.Printf("App deployed: %s\n", app.Name)
+
+	return nil
+}
+
+// planHerokuImport builds the migration plan of the given Heroku app off of its
+// config vars, latest slug, formation and add-ons. split splits the config vars
+// into plain ones and secrets.
+func planHerokuImport(ctx context.Context, heroku *hero.Service, hkApp *hero.App,
+	split func(map[string]string) (env, secrets map[string]string, err error)) (*herokuImport, *hero.Slug, error) {
+	vars, err := heroku.ConfigVarInfoForApp(ctx, hkApp.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed retrieving config vars of %s: %w", hkApp.Name, err)
+	}
+
+	releases, err := heroku.ReleaseList(ctx, hkApp.ID, &hero.ListRange{Field: "version", Descending: true, Max: 1})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed retrieving releases of %s: %w", hkApp.Name, err)
+	}
+	if len(releases) == 0 || releases[0].Slug == nil {
+		return nil, nil, fmt.Errorf("heroku app %s has no releases to import", hkApp.Name)
+	}
+
+	slug, err := heroku.SlugInfo(ctx, hkApp.ID, releases[0].Slug.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed retrieving slug of %s: %w", hkApp.Name, err)
+	}
+
+	formation, err := heroku.FormationList(ctx, hkApp.ID, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed retrieving formation of %s: %w", hkApp.Name, err)
+	}
+
+	addons, err := heroku.AddOnListByApp(ctx, hkApp.ID, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed retrieving add-ons of %s: %w", hkApp.Name, err)
+	}
+
+	plan := &herokuImport{}
+
+	// config vars add-ons provide point to heroku resources; leave them out
+	addonVars := map[string]struct{}{}
+	for _, addon := range addons {
+		plan.Addons = append(plan.Addons, herokuAddon{
+			Name:       addon.Name,
+			Service:    addon.AddonService.Name,
+			Plan:       addon.Plan.Name,
+			ConfigVars: addon.ConfigVars,
+		})
+
+		for _, name := range addon.ConfigVars {
+			addonVars[name] = struct{}{}
+		}
+	}
+
+	imported := map[string]string{}
+	for name, value := range vars {
+		if _, ok := addonVars[name]; ok || value == nil {
+			continue
+		}
+
+		imported[name] = *value
+	}
+
+	if plan.Env, plan.Secrets, err = split(imported); err != nil {
+		return nil, nil, err
+	}
+
+	quantities := map[string]hero.Formation{}
+	for _, f := range formation {
+		quantities[f.Type] = f
+	}
+
+	for name, command := range slug.ProcessTypes {
+		switch name {
+		case "release", "console", "rake":
+			continue
+		}
+
+		process := herokuProcess{
+			Name:    name,
+			Command: command,
+		}
+		if f, ok := quantities[name]; ok {
+			process.Quantity = f.Quantity
+			process.Size = f.Size
+		}
+
+		plan.Processes = append(plan.Processes, process)
+	}
+
+	sort.Slice(plan.Processes, func(i, j int) bool {
+		return plan.Processes[i].Name < plan.Processes[j].Name
+	})
+
+	return plan, slug, nil
+}
+
+func printHerokuImportPlan(appName string, plan *herokuImport, secretsSet bool) {
+	fmt.Println()
+	fmt.Println(aurora.Bold("Migration plan"))
+
+	if len(plan.Secrets) > 0 {
+		keys := sortedKeys(plan.Secrets)
+
+		if secretsSet {
+			fmt.Printf("\nThe following config vars were set as secrets:\n")
+		} else {
+			fmt.Printf("\nThe following config vars look sensitive and should be set as secrets:\n")
+		}
+		for _, key := range keys {
+			fmt.Printf("  %s\n", key)
+		}
+
+		if !secretsSet {
+			fmt.Printf("\n  flyctl secrets set -a %s %s\n", appName, strings.Join(placeholders(keys), " "))
+		}
+	}
+
+	if len(plan.Env) > 0 {
+		fmt.Printf("\nThe following config vars were added to the [env] section of fly.toml:\n")
+		for _, key := range sortedKeys(plan.Env) {
+			fmt.Printf("  %s\n", key)
+		}
+	}
+
+	var counts []string
+	for _, process := range plan.Processes {
+		name := process.Name
+		if name == "web" {
+			name = "app"
+		}
+
+		if process.Quantity == 0 {
+			fmt.Printf("\nProcess %s is scaled to zero on Heroku and was left out.\n", process.Name)
+
+			continue
+		}
+
+		counts = append(counts, fmt.Sprintf("%s=%d", name, process.Quantity))
+	}
+	if len(counts) > 0 {
+		fmt.Printf("\nTo match the Heroku formation, scale the app once deployed:\n\n  flyctl scale count -a %s %s\n", appName, strings.Join(counts, " "))
+	}
+
+	if len(plan.Addons) > 0 {
+		fmt.Printf("\nThe following add-ons have to be replaced; the config vars they provide were not imported:\n")
+		for _, addon := range plan.Addons {
+			suggestion, ok := herokuAddonReplacements[addon.Service]
+			if !ok {
+				suggestion = "no direct replacement is known"
+			}
+
+			fmt.Printf("  %s (%s, %s): %s\n", addon.Name, addon.Service, addon.Plan, suggestion)
+			if len(addon.ConfigVars) > 0 {
+				fmt.Printf("    provides %s\n", strings.Join(addon.ConfigVars, ", "))
+			}
+		}
+	}
+
+	fmt.Println()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func placeholders(keys []string) []string {
+	ret := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ret = append(ret, key+"=<value>")
+	}
+
+	return ret
+}
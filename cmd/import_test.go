@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSecretVar(t *testing.T) {
+	cases := map[string]bool{
+		"API_KEY":          true,
+		"SECRET_KEY_BASE":  true,
+		"github_token":     true,
+		"SENTRY_DSN":       true,
+		"DATABASE_URL":     true,
+		"HEROKU_REDIS_URL": true,
+		"SITE_URL":         false,
+		"KEYBOARD_LAYOUT":  false,
+		"MONKEY_MODE":      false,
+		"PASSENGER_PORT":   false,
+		"RAILS_ENV":        false,
+	}
+
+	for name, exp := range cases {
+		assert.Equal(t, exp, isSecretVar(name), name)
+	}
+}
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
+	"time"
 
 	"github.com/superfly/flyctl/cmdctx"
 	"github.com/superfly/flyctl/helpers"
@@ -33,6 +36,7 @@ func newIPAddressesCommand(client *client.Client) *Command {
 		Name:        "region",
 		Description: "The region where the address should be allocated",
 	})
+	addIPLifecycleFlags(allocateV4Command, "allocated")
 
 	ipsAllocateV6Strings := docstrings.Get("ips.allocate-v6")
 	allocateV6Command := BuildCommandKS(cmd, runAllocateIPAddressV6, ipsAllocateV6Strings, client, requireSession, requireAppName)
@@ -41,10 +45,12 @@ func newIPAddressesCommand(client *client.Client) *Command {
 		Name:        "region",
 		Description: "The region where the address should be allocated.",
 	})
+	addIPLifecycleFlags(allocateV6Command, "allocated")
 
 	ipsReleaseStrings := docstrings.Get("ips.release")
 	release := BuildCommandKS(cmd, runReleaseIPAddress, ipsReleaseStrings, client, requireSession, requireAppName)
 	release.Args = cobra.ExactArgs(1)
+	addIPLifecycleFlags(release, "released")
 
 	return cmd
 }
@@ -76,11 +82,30 @@ func runAllocateIPAddress(cmdCtx *cmdctx.CmdContext, addrType string) error {
 	appName := cmdCtx.AppName
 	regionCode := cmdCtx.Config.GetString("region")
 
+	// dedicated IPv4 addresses are billed, IPv6 ones aren't
+	if addrType == "v4" {
+		msg := fmt.Sprintf("Dedicated IPv4 addresses cost %s. Allocate one for %s?", dedicatedIPv4Price, appName)
+		if regionCode != "" {
+			msg = fmt.Sprintf("Dedicated IPv4 addresses cost %s. Allocate one for %s in %s?", dedicatedIPv4Price, appName, regionCode)
+		}
+
+		if !confirmIPChange(cmdCtx, msg) {
+			return nil
+		}
+		if !cmdCtx.IO.IsInteractive() && !cmdCtx.OutputJSON() {
+			fmt.Fprintf(cmdCtx.IO.ErrOut, "Dedicated IPv4 addresses cost %s\n", dedicatedIPv4Price)
+		}
+	}
+
 	ipAddress, err := cmdCtx.Client.API().AllocateIPAddress(ctx, appName, addrType, regionCode)
 	if err != nil {
 		return err
 	}
 
+	if err := waitForIPPropagation(cmdCtx, ipAddress.Address, true); err != nil {
+		return err
+	}
+
 	return cmdCtx.Frender(cmdctx.PresenterOption{
 		Presentable: &presenters.IPAddresses{IPAddresses: []api.IPAddress{*ipAddress}},
 	})
@@ -101,15 +126,119 @@ func runReleaseIPAddress(cmdCtx *cmdctx.CmdContext) error {
 		return err
 	}
 
+	msg := fmt.Sprintf("Release %s from %s? Released addresses can't be reclaimed, so allow-lists which name it must be updated.", ipAddress.Address, appName)
+	if !confirmIPChange(cmdCtx, msg) {
+		return nil
+	}
+
 	if err := cmdCtx.Client.API().ReleaseIPAddress(ctx, ipAddress.ID); err != nil {
 		return err
 	}
 
+	if err := waitForIPPropagation(cmdCtx, ipAddress.Address, false); err != nil {
+		return err
+	}
+
+	if cmdCtx.OutputJSON() {
+		cmdCtx.WriteJSON(ipAddress)
+
+		return nil
+	}
+
 	fmt.Printf("Released %s from %s\n", ipAddress.Address, appName)
 
 	return nil
 }
 
+// dedicatedIPv4Price is the monthly price of dedicated IPv4 addresses.
+const dedicatedIPv4Price = "$2/mo"
+
+// ipPropagationPollInterval is how often --wait checks whether changes to
+// the addresses of apps have propagated.
+const ipPropagationPollInterval = 5 * time.Second
+
+func addIPLifecycleFlags(cmd *Command, change string) {
+	cmd.AddBoolFlag(BoolFlagOpts{
+		Name:        "yes",
+		Shorthand:   "y",
+		Description: "Accept all confirmations",
+	})
+
+	cmd.AddBoolFlag(BoolFlagOpts{
+		Name:        "wait",
+		Description: fmt.Sprintf("Wait for the DNS of the app to reflect that the address was %s", change),
+	})
+
+	cmd.AddIntFlag(IntFlagOpts{
+		Name:        "wait-timeout",
+		Default:     300,
+		Description: "Seconds to wait for with --wait",
+	})
+}
+
+// confirmIPChange asks for confirmation of the change msg describes, unless
+// --yes is given or the session isn't interactive.
+func confirmIPChange(cmdCtx *cmdctx.CmdContext, msg string) bool {
+	if cmdCtx.Config.GetBool("yes") || !cmdCtx.IO.IsInteractive() {
+		return true
+	}
+
+	return confirm(msg)
+}
+
+// waitForIPPropagation waits, when --wait is given, for the <app>.fly.dev
+// hostname of the app to resolve to address, or to no longer resolve to it in
+// case present is false.
+func waitForIPPropagation(cmdCtx *cmdctx.CmdContext, address string, present bool) error {
+	if !cmdCtx.Config.GetBool("wait") {
+		return nil
+	}
+
+	timeout := time.Duration(cmdCtx.Config.GetInt("wait-timeout")) * time.Second
+	if timeout <= 0 {
+		return errors.New("--wait-timeout must be positive")
+	}
+
+	ctx, cancel := context.WithTimeout(cmdCtx.Command.Context(), timeout)
+	defer cancel()
+
+	var (
+		ip       = net.ParseIP(address)
+		hostname = cmdCtx.AppName + ".fly.dev"
+	)
+
+	if !cmdCtx.OutputJSON() {
+		fmt.Fprintf(cmdCtx.IO.ErrOut, "Waiting for %s to propagate to %s\n", address, hostname)
+	}
+
+	for {
+		// hostnames of apps left without addresses aren't found
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, hostname)
+
+		var dnsErr *net.DNSError
+		if err == nil || (errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+			var resolves bool
+			for _, addr := range addrs {
+				resolves = resolves || addr.IP.Equal(ip)
+			}
+
+			if resolves == present {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if err := cmdCtx.Command.Context().Err(); err != nil {
+				return err
+			}
+
+			return fmt.Errorf("%s didn't propagate to %s within %s", address, hostname, timeout)
+		case <-time.After(ipPropagationPollInterval):
+		}
+	}
+}
+
 func runPrivateIPAddressesList(cmdCtx *cmdctx.CmdContext) error {
 	ctx := cmdCtx.Command.Context()
 
@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -78,6 +79,15 @@ func newLaunchCommand(client *client.Client) *Command {
 		Description: "Perform builds remotely without using the local docker daemon",
 		Default:     true,
 	})
+	launchCmd.AddStringFlag(StringFlagOpts{
+		Name:        "import-env",
+		Description: "Path to a .env file to import into the [env] section of fly.toml, leaving out secrets",
+	})
+	launchCmd.AddBoolFlag(BoolFlagOpts{
+		Name:        "import-secrets",
+		Description: "Set the secrets of the --import-env file on the new app",
+		Default:     false,
+	})
 
 	return launchCmd
 }
@@ -92,6 +102,17 @@ func runLaunch(cmdCtx *cmdctx.CmdContext) error {
 	}
 	cmdCtx.WorkingDir = dir
 
+	// the .env file is read upfront so that it can't fail once the app exists
+	var imported *importedEnv
+	if path := cmdCtx.Config.GetString("import-env"); path != "" {
+		var err error
+		if imported, err = readImportedEnv(cmdCtx, path); err != nil {
+			return err
+		}
+	} else if cmdCtx.Config.GetBool("import-secrets") {
+		return errors.New("--import-secrets requires --import-env")
+	}
+
 	orgSlug := cmdCtx.Config.GetString("org")
 
 	// start a remote builder for the personal org if necessary
@@ -305,8 +326,26 @@ func runLaunch(cmdCtx *cmdctx.CmdContext) error {
 		}
 	}
 
+	// imported variables take precedence over the defaults of the scanner
+	if imported != nil {
+		for name, value := range imported.env {
+			appConfig.SetEnvVariable(name, value)
+		}
+	}
+
 	fmt.Printf("Created app %s in organization %s\n", app.Name, org.Slug)
 
+	if imported != nil {
+		importSecrets := cmdCtx.Config.GetBool("import-secrets") && len(imported.secrets) > 0
+		if importSecrets {
+			if _, err := cmdCtx.Client.API().SetSecrets(ctx, app.Name, imported.secrets); err != nil {
+				return fmt.Errorf("failed setting the secrets of %s: %w", imported.path, err)
+			}
+		}
+
+		printImportedEnv(app.Name, imported, importSecrets)
+	}
+
 	// If secrets are requested by the launch scanner, ask the user to input them
 	if srcInfo != nil && len(srcInfo.Secrets) > 0 {
 		secrets := make(map[string]string)
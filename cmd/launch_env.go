@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/internal/cmdutil"
+)
+
+// importedEnv wraps the variables of a .env file launch imports, split into
+// the ones which go into the [env] section of fly.toml and the secrets.
+type importedEnv struct {
+	path    string
+	env     map[string]string
+	secrets map[string]string
+}
+
+// readImportedEnv reads the .env file at path and splits its variables into
+// plain ones and secrets. Names which look sensitive are deemed secrets; when
+// running interactively, the user confirms the split.
+func readImportedEnv(cmdCtx *cmdctx.CmdContext, path string) (*importedEnv, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	vars, err := cmdutil.ParseDotenv(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing %s: %w", path, err)
+	}
+
+	env, secrets, err := splitSecretVars(cmdCtx, "variables of "+path, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	return &importedEnv{
+		path:    path,
+		env:     env,
+		secrets: secrets,
+	}, nil
+}
+
+// splitSecretVars splits vars into plain ones and secrets. Names which look
+// sensitive are deemed secrets; when running interactively, the user confirms
+// the split. what describes vars in the prompt, e.g. "variables of .env".
+func splitSecretVars(cmdCtx *cmdctx.CmdContext, what string, vars map[string]string) (env, secrets map[string]string, err error) {
+	names := sortedKeys(vars)
+
+	var secret []string
+	for _, name := range names {
+		if isSecretVar(name) {
+			secret = append(secret, name)
+		}
+	}
+
+	if cmdCtx.IO.IsInteractive() && len(names) > 0 {
+		prompt := &survey.MultiSelect{
+			Message: fmt.Sprintf("Which %s are secrets?", what),
+			Help:    "Secrets are set on the app rather than written to fly.toml. Variables which look sensitive are preselected.",
+			Options: names,
+			Default: secret,
+		}
+
+		secret = nil
+		if err = survey.AskOne(prompt, &secret); err != nil {
+			return
+		}
+	}
+
+	isSecret := make(map[string]bool, len(secret))
+	for _, name := range secret {
+		isSecret[name] = true
+	}
+
+	env, secrets = map[string]string{}, map[string]string{}
+	for name, value := range vars {
+		if isSecret[name] {
+			secrets[name] = value
+		} else {
+			env[name] = value
+		}
+	}
+
+	return
+}
+
+// printImportedEnv tells what became of the variables of imported.
+func printImportedEnv(appName string, imported *importedEnv, secretsSet bool) {
+	if len(imported.env) > 0 {
+		fmt.Printf("Added %d variables of %s to the [env] section of fly.toml: %s\n",
+			len(imported.env), imported.path, strings.Join(sortedKeys(imported.env), ", "))
+	}
+
+	if len(imported.secrets) == 0 {
+		return
+	}
+
+	keys := sortedKeys(imported.secrets)
+	if secretsSet {
+		fmt.Printf("Set secrets of %s on %s: %s\n", imported.path, appName, strings.Join(keys, ", "))
+
+		return
+	}
+
+	fmt.Printf("Left out variables of %s which are secrets: %s\n", imported.path, strings.Join(keys, ", "))
+	fmt.Printf("Set them with --import-secrets, or with:\n\n  flyctl secrets set -a %s %s\n\n", appName, strings.Join(placeholders(keys), " "))
+}
@@ -3,18 +3,17 @@ package cmd
 import (
 	"fmt"
 	"io"
-	"os"
 	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	surveyterminal "github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/dustin/go-humanize"
 	"github.com/google/shlex"
 	"github.com/logrusorgru/aurora"
-	"github.com/olekukonko/tablewriter"
 	"github.com/pkg/errors"
 	"github.com/segmentio/textio"
 	"github.com/spf13/cobra"
@@ -22,11 +21,13 @@ import (
 	"github.com/superfly/flyctl/cmd/presenters"
 	"github.com/superfly/flyctl/cmdctx"
 	"github.com/superfly/flyctl/docstrings"
+	"github.com/superfly/flyctl/flyctl"
 	"github.com/superfly/flyctl/helpers"
 	"github.com/superfly/flyctl/internal/build/imgsrc"
 	"github.com/superfly/flyctl/internal/client"
 	"github.com/superfly/flyctl/internal/cmdutil"
 	"github.com/superfly/flyctl/pkg/logs"
+	"github.com/superfly/flyctl/pkg/machines"
 	"github.com/superfly/flyctl/terminal"
 )
 
@@ -40,13 +41,19 @@ func newMachineCommand(client *client.Client) *Command {
 	}, client)
 
 	newMachineRunCommand(cmd, client)
+	newMachineCreateCommand(cmd, client)
 	newMachineListCommand(cmd, client)
 	newMachineStopCommand(cmd, client)
 	newMachineStartCommand(cmd, client)
+	newMachineRestartCommand(cmd, client)
 	newMachineKillCommand(cmd, client)
 	newMachineRemoveCommand(cmd, client)
 	newMachineCloneCommand(cmd, client)
 	newMachineStatusCommand(cmd, client)
+	newMachineUpdateCommand(cmd, client)
+	newMachineExecCommand(cmd, client)
+	newMachineLogsCommand(cmd, client)
+	newMachineImagesCommand(cmd, client)
 
 	return cmd
 }
@@ -71,11 +78,8 @@ func newMachineListCommand(parent *Command, client *client.Client) {
 		Description: "List machines in a specific state",
 	})
 
-	cmd.AddBoolFlag(BoolFlagOpts{
-		Name:        "quiet",
-		Shorthand:   "q",
-		Description: "Only list machine ids",
-	})
+	addMachineSelectorFlag(cmd)
+	addMachineLabelColumnFlags(cmd)
 }
 
 func runMachineList(cmdCtx *cmdctx.CmdContext) error {
@@ -85,94 +89,106 @@ func runMachineList(cmdCtx *cmdctx.CmdContext) error {
 	if cmdCtx.Config.GetBool("all") {
 		state = ""
 	}
+	selector, err := machineSelector(cmdCtx)
+	if err != nil {
+		return err
+	}
+
 	machines, err := cmdCtx.Client.API().ListMachines(ctx, cmdCtx.AppName, state)
 	if err != nil {
 		return errors.Wrap(err, "could not get list of machines")
 	}
 
-	if cmdCtx.Config.GetBool("quiet") {
-		for _, machine := range machines {
-			fmt.Println(machine.ID)
-		}
-		return nil
+	if selector != nil {
+		machines = selector.Select(machines)
 	}
 
-	data := [][]string{}
-
-	for _, machine := range machines {
-
-		var ipv6 string
-
-		for _, ip := range machine.IPs.Nodes {
-			if ip.Family == "v6" && ip.Kind == "privatenet" {
-				ipv6 = ip.IP
-			}
-		}
-
-		row := []string{
-			machine.ID,
-			machine.Config.Image,
-			machine.CreatedAt.String(),
-			machine.State,
-			machine.Region,
-			machine.Name,
-			ipv6,
-		}
-		if cmdCtx.AppName == "" {
-			row = append(row, machine.App.Name)
+	// the global --quiet lists only the machine ids
+	if cmdCtx.GlobalConfig.GetBool(flyctl.ConfigQuietOutput) {
+		for _, machine := range machines {
+			fmt.Fprintln(cmdCtx.Out, machine.ID)
 		}
-		data = append(data, row)
+		return nil
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	headers := []string{"ID", "Image", "Created", "State", "Region", "Name", "IP Address"}
-	if cmdCtx.AppName == "" {
-		headers = append(headers, "App")
-	}
-	table.SetHeader(headers)
-	table.SetAutoWrapText(false)
-	table.SetAutoFormatHeaders(true)
-	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
-	table.SetAlignment(tablewriter.ALIGN_LEFT)
-	table.SetCenterSeparator("")
-	table.SetColumnSeparator("")
-	table.SetRowSeparator("")
-	table.SetHeaderLine(false)
-	table.SetBorder(false)
-	table.SetTablePadding("\t") // pad with tabs
-	table.SetNoWhiteSpace(true)
-	table.AppendBulk(data) // Add Bulk Data
-	table.Render()
-
-	return nil
+	return renderMachines(cmdCtx, machines)
 }
 
 func newMachineStopCommand(parent *Command, client *client.Client) {
 	cmd := BuildCommandKS(parent, runMachineStop, docstrings.Get("machine.stop"), client, requireSession, optionalAppName)
 
+	addMachineStopFlags(cmd)
+	addMachineWaitFlags(cmd, "stopped")
+
+	cmd.Args = cobra.MinimumNArgs(1)
+}
+
+func addMachineStopFlags(cmd *Command) {
 	cmd.AddStringFlag(StringFlagOpts{
 		Name:        "signal",
 		Shorthand:   "s",
-		Description: "Signal to stop the machine with (default: SIGINT)",
+		Description: "Signal to stop the machine with (default: the app's kill_signal or SIGINT)",
 	})
 
 	cmd.AddIntFlag(IntFlagOpts{
 		Name:        "time",
-		Description: "Seconds to wait before killing the machine",
+		Description: "Seconds to wait before killing the machine (default: the app's kill_timeout)",
 	})
 
-	cmd.Args = cobra.MinimumNArgs(1)
+	cmd.AddIntFlag(IntFlagOpts{
+		Name:        "drain-timeout",
+		Description: "Seconds to drain connections to the machine through the proxy for before signaling it",
+	})
+}
+
+// machineStopInput returns the input to stop the given machine with, as
+// configured by the stop flags with the kill_signal and kill_timeout of the
+// app config as fallbacks.
+func machineStopInput(cmdCtx *cmdctx.CmdContext, id string) (input api.StopMachineInput, err error) {
+	input = api.StopMachineInput{
+		AppID:            cmdCtx.AppName,
+		ID:               id,
+		Signal:           cmdCtx.Config.GetString("signal"),
+		KillTimeoutSecs:  cmdCtx.Config.GetInt("time"),
+		DrainTimeoutSecs: cmdCtx.Config.GetInt("drain-timeout"),
+	}
+
+	if input.KillTimeoutSecs < 0 || input.DrainTimeoutSecs < 0 {
+		return input, errors.New("--time and --drain-timeout must not be negative")
+	}
+
+	var definition map[string]interface{}
+	if cmdCtx.AppConfig != nil {
+		definition = cmdCtx.AppConfig.Definition
+	}
+
+	if input.Signal == "" {
+		input.Signal, _ = definition["kill_signal"].(string)
+	}
+	if input.Signal != "" {
+		if input.Signal, err = cmdutil.NormalizeSignal(input.Signal); err != nil {
+			return
+		}
+	}
+
+	if v, ok := definition["kill_timeout"]; ok && input.KillTimeoutSecs == 0 {
+		if input.KillTimeoutSecs, err = cmdutil.KillTimeout(v); err != nil {
+			err = errors.Wrap(err, "invalid kill_timeout")
+
+			return
+		}
+	}
+
+	return
 }
 
 func runMachineStop(cmdCtx *cmdctx.CmdContext) error {
 	ctx := cmdCtx.Command.Context()
 
 	for _, arg := range cmdCtx.Args {
-		input := api.StopMachineInput{
-			AppID:           cmdCtx.AppName,
-			ID:              arg,
-			Signal:          cmdCtx.Config.GetString("signal"),
-			KillTimeoutSecs: cmdCtx.Config.GetInt("time"),
+		input, err := machineStopInput(cmdCtx, arg)
+		if err != nil {
+			return err
 		}
 
 		machine, err := cmdCtx.Client.API().StopMachine(ctx, input)
@@ -180,15 +196,66 @@ func runMachineStop(cmdCtx *cmdctx.CmdContext) error {
 			return errors.Wrap(err, "could not stop machine")
 		}
 
+		if machine, err = waitForMachine(cmdCtx, machine, "stopped"); err != nil {
+			return err
+		}
+
+		printMachine(cmdCtx, machine)
+	}
+
+	return nil
+}
+
+func newMachineRestartCommand(parent *Command, client *client.Client) {
+	cmd := BuildCommandKS(parent, runMachineRestart, docstrings.Get("machine.restart"), client, requireSession, optionalAppName)
+
+	addMachineStopFlags(cmd)
+
+	cmd.Args = cobra.MinimumNArgs(1)
+}
+
+func runMachineRestart(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+	client := cmdCtx.Client.API()
+
+	for _, arg := range cmdCtx.Args {
+		input, err := machineStopInput(cmdCtx, arg)
+		if err != nil {
+			return err
+		}
+
+		if _, err := client.StopMachine(ctx, input); err != nil {
+			return errors.Wrap(err, "could not stop machine")
+		}
+
+		// give the machine the drain period and the grace period to stop, plus
+		// some slack for the platform to act on them
+		timeout := time.Duration(input.DrainTimeoutSecs+input.KillTimeoutSecs)*time.Second + machineStopSlack
+		if err := machines.WaitForState(ctx, client, cmdCtx.AppName, arg, "stopped", timeout); err != nil {
+			return err
+		}
+
+		machine, err := client.StartMachine(ctx, api.StartMachineInput{
+			AppID: cmdCtx.AppName,
+			ID:    arg,
+		})
+		if err != nil {
+			return errors.Wrap(err, "could not start machine")
+		}
+
 		fmt.Println(machine.ID)
 	}
 
 	return nil
 }
 
+const machineStopSlack = 30 * time.Second
+
 func newMachineStartCommand(parent *Command, client *client.Client) {
 	cmd := BuildCommandKS(parent, runMachineStart, docstrings.Get("machine.start"), client, requireSession, optionalAppName)
 
+	addMachineWaitFlags(cmd, "started")
+
 	cmd.Args = cobra.ExactArgs(1)
 }
 
@@ -202,10 +269,14 @@ func runMachineStart(cmdCtx *cmdctx.CmdContext) error {
 
 	machine, err := cmdCtx.Client.API().StartMachine(ctx, input)
 	if err != nil {
-		return errors.Wrap(err, "could not stop machine")
+		return errors.Wrap(err, "could not start machine")
 	}
 
-	fmt.Println(machine.ID)
+	if machine, err = waitForMachine(cmdCtx, machine, "started"); err != nil {
+		return err
+	}
+
+	printMachine(cmdCtx, machine)
 
 	return nil
 }
@@ -367,7 +438,7 @@ func newMachineRemoveCommand(parent *Command, client *client.Client) {
 		Use:     keystrings.Usage,
 		Short:   keystrings.Short,
 		Long:    keystrings.Long,
-		Aliases: []string{"rm"},
+		Aliases: []string{"rm", "destroy"},
 	}, client, requireSession, optionalAppName)
 
 	cmd.AddBoolFlag(BoolFlagOpts{
@@ -376,25 +447,51 @@ func newMachineRemoveCommand(parent *Command, client *client.Client) {
 		Description: "force kill machine if it's running",
 	})
 
-	cmd.Args = cobra.MinimumNArgs(1)
+	cmd.AddBoolFlag(BoolFlagOpts{
+		Name:        "yes",
+		Shorthand:   "y",
+		Description: "Accept all confirmations",
+	})
+
+	addMachineSelectorFlag(cmd)
 }
 
 func runMachineRemove(cmdCtx *cmdctx.CmdContext) error {
 	ctx := cmdCtx.Command.Context()
 
-	for _, arg := range cmdCtx.Args {
+	ids, err := machineTargets(cmdCtx)
+	if err != nil {
+		return err
+	}
+
+	// selectors may match more machines than anticipated
+	if cmdCtx.Config.GetString("selector") != "" && !cmdCtx.Config.GetBool("yes") {
+		if !cmdCtx.IO.IsInteractive() {
+			return errors.New("--yes is required to remove machines by --selector when not running interactively")
+		}
+
+		confirm := false
+		prompt := &survey.Confirm{
+			Message: fmt.Sprintf("Remove %d machines of %s (%s)?", len(ids), cmdCtx.AppName, strings.Join(ids, ", ")),
+		}
+		if err := survey.AskOne(prompt, &confirm); err != nil || !confirm {
+			return err
+		}
+	}
+
+	for _, id := range ids {
 		input := api.RemoveMachineInput{
 			AppID: cmdCtx.AppName,
-			ID:    arg,
+			ID:    id,
 			Kill:  cmdCtx.Config.GetBool("force"),
 		}
 
 		machine, err := cmdCtx.Client.API().RemoveMachine(ctx, input)
 		if err != nil {
-			return errors.Wrap(err, "could not stop machine")
+			return errors.Wrap(err, "could not remove machine")
 		}
 
-		fmt.Println(machine.ID)
+		printMachine(cmdCtx, machine)
 	}
 
 	return nil
@@ -460,7 +557,6 @@ func newMachineRunCommand(parent *Command, client *client.Client) {
 
 	cmd.AddStringSliceFlag(StringSliceFlagOpts{
 		Name:        "volume",
-		Shorthand:   "v",
 		Description: "Volumes to mount in the form of <volume_id_or_name>:/path/inside/machine[:<options>]",
 	})
 
@@ -469,12 +565,19 @@ func newMachineRunCommand(parent *Command, client *client.Client) {
 		Description: "ENTRYPOINT replacement",
 	})
 
+	cmd.AddStringFlag(StringFlagOpts{
+		Name:        "schedule",
+		Description: `Cron expression, in UTC, of the times to boot the machine at to run a recurring job, e.g. "0 3 * * *" or @daily`,
+	})
+
 	cmd.AddBoolFlag(BoolFlagOpts{
 		Name:        "detach",
 		Shorthand:   "d",
 		Description: "Detach from the machine's logs",
 	})
 
+	addMachineTunableFlags(cmd)
+
 	cmd.AddBoolFlag(BoolFlagOpts{
 		Name: "build-only",
 	})
@@ -507,9 +610,139 @@ func newMachineRunCommand(parent *Command, client *client.Client) {
 		Description: "Do not use the cache when building the image",
 	})
 
+	addMachineLabelFlags(cmd, false)
+
 	cmd.Command.Args = cobra.MinimumNArgs(1)
 }
 
+func newMachineUpdateCommand(parent *Command, client *client.Client) {
+	cmd := BuildCommandKS(parent, runMachineUpdate, docstrings.Get("machine.update"), client, requireSession, requireAppName)
+
+	cmd.AddStringFlag(StringFlagOpts{
+		Name:        "machine-config",
+		Description: "Path to a JSON file holding the config to replace the machine's with, or - to read it from stdin",
+	})
+
+	addMachineTunableFlags(cmd)
+	addMachineLabelFlags(cmd, true)
+	addMachineSelectorFlag(cmd)
+	addMachineWaitFlags(cmd, "started")
+
+	cmd.Args = cobra.MaximumNArgs(1)
+}
+
+func runMachineUpdate(cmdCtx *cmdctx.CmdContext) error {
+	ids, err := machineTargets(cmdCtx)
+	if err != nil {
+		return err
+	}
+
+	machineConf, err := readMachineConfig(cmdCtx)
+	if err != nil {
+		return err
+	}
+
+	tunables, err := machineTunables(cmdCtx)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := updateMachine(cmdCtx, id, machineConf, tunables); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateMachine replaces the config of the machine id names with machineConf,
+// unless it's nil, then applies tunables and the label flags.
+func updateMachine(cmdCtx *cmdctx.CmdContext, id string, machineConf *api.MachineConfig, tunables machines.Tunables) error {
+	ctx := cmdCtx.Command.Context()
+	client := cmdCtx.Client.API()
+
+	machine, err := client.GetMachine(ctx, cmdCtx.AppName, id)
+	if err != nil {
+		return errors.Wrap(err, "could not get machine")
+	}
+
+	if machineConf != nil {
+		machine.Config = *machineConf
+	}
+
+	tunables.Apply(&machine.Config)
+	if err := applyMachineLabels(cmdCtx, &machine.Config); err != nil {
+		return err
+	}
+
+	input := api.LaunchMachineInput{
+		AppID:  cmdCtx.AppName,
+		ID:     machine.ID,
+		Name:   machine.Name,
+		Region: machine.Region,
+		Config: &machine.Config,
+	}
+
+	if machine, _, err = client.LaunchMachine(ctx, input); err != nil {
+		return errors.Wrap(err, "could not update machine")
+	}
+
+	if machine, err = waitForMachine(cmdCtx, machine, "started"); err != nil {
+		return err
+	}
+
+	printMachine(cmdCtx, machine)
+
+	return nil
+}
+
+func addMachineTunableFlags(cmd *Command) {
+	cmd.AddIntFlag(IntFlagOpts{
+		Name:        "swap-size-mb",
+		Description: "Swap space (in megabytes) to attribute to the machine (default: the app's swap_size_mb)",
+	})
+
+	cmd.AddStringSliceFlag(StringSliceFlagOpts{
+		Name:        "kernel-arg",
+		Description: "Kernel command line parameter to boot the machine with, e.g. transparent_hugepage=never. Can be specified multiple times.",
+	})
+
+	cmd.AddIntFlag(IntFlagOpts{
+		Name:        "max-open-files",
+		Description: "Open file descriptor limit of the machine (default: the app's guest.max_open_files)",
+	})
+}
+
+// machineTunables returns the guest tunables of the app config, overridden by
+// any given tunable flags.
+func machineTunables(cmdCtx *cmdctx.CmdContext) (tunables machines.Tunables, err error) {
+	if cmdCtx.AppConfig != nil {
+		var problems []string
+		if tunables, problems = machines.TunablesFromDefinition(cmdCtx.AppConfig.Definition); len(problems) > 0 {
+			err = fmt.Errorf("invalid guest tunables in %s: %s", cmdCtx.ConfigFile, strings.Join(problems, "; "))
+
+			return
+		}
+	}
+
+	if v := cmdCtx.Config.GetInt("swap-size-mb"); v != 0 {
+		tunables.SwapSizeMB = v
+	}
+	if v := cmdCtx.Config.GetStringSlice("kernel-arg"); len(v) > 0 {
+		tunables.KernelArgs = v
+	}
+	if v := cmdCtx.Config.GetInt("max-open-files"); v != 0 {
+		tunables.MaxOpenFiles = v
+	}
+
+	if problems := tunables.Validate(); len(problems) > 0 {
+		err = fmt.Errorf("invalid guest tunables: %s", strings.Join(problems, "; "))
+	}
+
+	return
+}
+
 func newMachineStatusCommand(parent *Command, client *client.Client) {
 	cmd := BuildCommandKS(parent, getMachineStatus, docstrings.Get("machine.status"), client, requireSession, optionalAppName)
 	cmd.Args = cobra.ExactArgs(1)
@@ -606,6 +839,13 @@ func runMachineRun(cmdCtx *cmdctx.CmdContext) error {
 		cmdCtx.MachineConfig = &api.MachineConfig{}
 	}
 
+	var schedule *machines.Schedule
+	if expr := cmdCtx.Config.GetString("schedule"); expr != "" {
+		if schedule, err = machines.ParseSchedule(expr); err != nil {
+			return err
+		}
+	}
+
 	if extraEnv := cmdCtx.Config.GetStringSlice("env"); len(extraEnv) > 0 {
 		parsedEnv, err := cmdutil.ParseKVStringsToMap(cmdCtx.Config.GetStringSlice("env"))
 		if err != nil {
@@ -614,6 +854,10 @@ func runMachineRun(cmdCtx *cmdctx.CmdContext) error {
 		cmdCtx.MachineConfig.Env = parsedEnv
 	}
 
+	if err := applyMachineLabels(cmdCtx, cmdCtx.MachineConfig); err != nil {
+		return err
+	}
+
 	machineConf := cmdCtx.MachineConfig
 
 	var img *imgsrc.DeploymentImage
@@ -717,6 +961,12 @@ func runMachineRun(cmdCtx *cmdctx.CmdContext) error {
 
 	machineConf.Guest = guest
 
+	tunables, err := machineTunables(cmdCtx)
+	if err != nil {
+		return err
+	}
+	tunables.Apply(machineConf)
+
 	if entrypoint := cmdCtx.Config.GetString("entrypoint"); entrypoint != "" {
 		splitted, err := shlex.Split(entrypoint)
 		if err != nil {
@@ -800,6 +1050,15 @@ func runMachineRun(cmdCtx *cmdctx.CmdContext) error {
 
 	machineConf.Mounts = mounts
 
+	if schedule != nil {
+		machineConf.Schedule = schedule.String()
+
+		// jobs exit once done, and should not be restarted until their next run
+		if machineConf.Restart.Policy == "" {
+			machineConf.Restart.Policy = api.MachineRestartPolicyNo
+		}
+	}
+
 	input := api.LaunchMachineInput{
 		AppID:  cmdCtx.AppName,
 		ID:     cmdCtx.Config.GetString("id"),
@@ -822,35 +1081,13 @@ func runMachineRun(cmdCtx *cmdctx.CmdContext) error {
 		return nil
 	}
 
-	apiClient := cmdCtx.Client.API()
-
-	opts := &logs.LogOptions{
-		AppName: app.Name,
-		VMID:    machine.ID,
-	}
-
-	stream, err := logs.NewNatsStream(ctx, apiClient, opts)
+	if schedule != nil {
+		fmt.Fprintf(cmdCtx.Out, "Machine %s runs on the schedule %s; the next run is at %s\n",
+			machine.ID, schedule, schedule.Next(time.Now()).Format(time.RFC3339))
+		fmt.Fprintf(cmdCtx.Out, "See the status of its runs with 'flyctl cron list -a %s'\n", app.Name)
 
-	if err != nil {
-		terminal.Debugf("could not connect to wireguard tunnel, err: %v\n", err)
-		terminal.Debug("Falling back to log polling...")
-
-		stream, err = logs.NewPollingStream(ctx, apiClient, opts)
-		if err != nil {
-			return err
-		}
+		return nil
 	}
 
-	presenter := presenters.LogPresenter{}
-
-	entries := stream.Stream(ctx, opts)
-
-	for {
-		select {
-		case <-ctx.Done():
-			return stream.Err()
-		case entry := <-entries:
-			presenter.FPrint(cmdCtx.Out, cmdCtx.OutputJSON(), entry)
-		}
-	}
+	return streamMachineLogs(cmdCtx, app.Name, machine.ID)
 }
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/docstrings"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/cmdutil"
+	"github.com/superfly/flyctl/pkg/machines"
+)
+
+const defaultMachineWaitTimeout = 60
+
+func newMachineCreateCommand(parent *Command, client *client.Client) {
+	cmd := BuildCommandKS(parent, runMachineCreate, docstrings.Get("machine.create"), client, requireSession, requireAppName)
+
+	cmd.AddStringFlag(StringFlagOpts{
+		Name:        "machine-config",
+		Description: "Path to a JSON file holding the config of the machine, or - to read it from stdin",
+	})
+
+	cmd.AddStringFlag(StringFlagOpts{
+		Name:        "id",
+		Description: "Machine ID, if previously known",
+	})
+
+	cmd.AddStringFlag(StringFlagOpts{
+		Name:        "name",
+		Shorthand:   "n",
+		Description: "Machine name, will be generated if missing",
+	})
+
+	cmd.AddStringFlag(StringFlagOpts{
+		Name:        "region",
+		Shorthand:   "r",
+		Description: "Region to create the machine in (see `flyctl platform regions`)",
+	})
+
+	cmd.AddStringSliceFlag(StringSliceFlagOpts{
+		Name:        "env",
+		Shorthand:   "e",
+		Description: "Set of environment variables in the form of NAME=VALUE pairs. Can be specified multiple times.",
+	})
+
+	addMachineLabelFlags(cmd, false)
+	addMachineWaitFlags(cmd, "started")
+
+	cmd.Args = cobra.MaximumNArgs(1)
+}
+
+func runMachineCreate(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+	client := cmdCtx.Client.API()
+
+	machineConf, err := readMachineConfig(cmdCtx)
+	if err != nil {
+		return err
+	}
+	if machineConf == nil {
+		machineConf = &api.MachineConfig{}
+	}
+
+	if len(cmdCtx.Args) > 0 {
+		machineConf.Image = cmdCtx.Args[0]
+	}
+	if machineConf.Image == "" {
+		return errors.New("an image is required, either as an argument or in the machine config")
+	}
+
+	if extraEnv := cmdCtx.Config.GetStringSlice("env"); len(extraEnv) > 0 {
+		parsedEnv, err := cmdutil.ParseKVStringsToMap(extraEnv)
+		if err != nil {
+			return errors.Wrap(err, "invalid env")
+		}
+		if machineConf.Env == nil {
+			machineConf.Env = map[string]string{}
+		}
+		for k, v := range parsedEnv {
+			machineConf.Env[k] = v
+		}
+	}
+
+	if err := applyMachineLabels(cmdCtx, machineConf); err != nil {
+		return err
+	}
+
+	input := api.LaunchMachineInput{
+		AppID:  cmdCtx.AppName,
+		ID:     cmdCtx.Config.GetString("id"),
+		Name:   cmdCtx.Config.GetString("name"),
+		Region: cmdCtx.Config.GetString("region"),
+		Config: machineConf,
+	}
+
+	machine, _, err := client.LaunchMachine(ctx, input)
+	if err != nil {
+		return errors.Wrap(err, "could not create machine")
+	}
+
+	if machine, err = waitForMachine(cmdCtx, machine, "started"); err != nil {
+		return err
+	}
+
+	return renderMachines(cmdCtx, []*api.Machine{machine})
+}
+
+// readMachineConfig reads the machine config the --machine-config flag points
+// to. It returns nil when the flag is not set.
+func readMachineConfig(cmdCtx *cmdctx.CmdContext) (*api.MachineConfig, error) {
+	path := cmdCtx.Config.GetString("machine-config")
+	if path == "" {
+		return nil, nil
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(cmdCtx.IO.In)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read machine config")
+	}
+
+	var machineConf api.MachineConfig
+	if err := json.Unmarshal(data, &machineConf); err != nil {
+		return nil, errors.Wrapf(err, "invalid machine config %s", path)
+	}
+
+	return &machineConf, nil
+}
+
+func addMachineWaitFlags(cmd *Command, state string) {
+	cmd.AddBoolFlag(BoolFlagOpts{
+		Name:        "wait",
+		Description: fmt.Sprintf("Wait for the machine to be %s", state),
+	})
+
+	cmd.AddIntFlag(IntFlagOpts{
+		Name:        "wait-timeout",
+		Default:     defaultMachineWaitTimeout,
+		Description: "Seconds to wait for the machine to reach its state when --wait is given",
+	})
+}
+
+// waitForMachine waits for the given machine to reach the given state when
+// --wait is given, and returns the machine as of then.
+func waitForMachine(cmdCtx *cmdctx.CmdContext, machine *api.Machine, state string) (*api.Machine, error) {
+	if !cmdCtx.Config.GetBool("wait") {
+		return machine, nil
+	}
+
+	timeout := cmdCtx.Config.GetInt("wait-timeout")
+	if timeout <= 0 {
+		return nil, errors.New("--wait-timeout must be positive")
+	}
+
+	ctx := cmdCtx.Command.Context()
+	client := cmdCtx.Client.API()
+
+	if err := machines.WaitForState(ctx, client, cmdCtx.AppName, machine.ID, state, time.Duration(timeout)*time.Second); err != nil {
+		return nil, err
+	}
+
+	return client.GetMachine(ctx, cmdCtx.AppName, machine.ID)
+}
+
+// printMachine prints the ID of the given machine, or the machine as JSON when
+// JSON output was requested.
+func printMachine(cmdCtx *cmdctx.CmdContext, machine *api.Machine) {
+	if cmdCtx.OutputJSON() {
+		cmdCtx.WriteJSON(machine)
+
+		return
+	}
+
+	fmt.Fprintln(cmdCtx.Out, machine.ID)
+}
+
+// renderMachines prints the given machines as a table, or as JSON when JSON
+// output was requested.
+func renderMachines(cmdCtx *cmdctx.CmdContext, machines []*api.Machine) error {
+	if cmdCtx.OutputJSON() {
+		cmdCtx.WriteJSON(machines)
+
+		return nil
+	}
+
+	data := [][]string{}
+
+	for _, machine := range machines {
+		row := []string{
+			machine.ID,
+			machine.Config.Image,
+			machine.CreatedAt.String(),
+			machine.State,
+			machine.Region,
+			machine.Name,
+			machinePrivateIP(machine),
+		}
+		row = append(row, machineLabelCells(cmdCtx, machine)...)
+		if cmdCtx.AppName == "" {
+			var appName string
+			if machine.App != nil {
+				appName = machine.App.Name
+			}
+			row = append(row, appName)
+		}
+		data = append(data, row)
+	}
+
+	table := tablewriter.NewWriter(cmdCtx.Out)
+	headers := []string{"ID", "Image", "Created", "State", "Region", "Name", "IP Address"}
+	headers = append(headers, machineLabelHeaders(cmdCtx)...)
+	if cmdCtx.AppName == "" {
+		headers = append(headers, "App")
+	}
+	table.SetHeader(headers)
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetBorder(false)
+	table.SetTablePadding("\t") // pad with tabs
+	table.SetNoWhiteSpace(true)
+	table.AppendBulk(data) // Add Bulk Data
+	table.Render()
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/cmd/presenters"
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/docstrings"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/pkg/agent"
+	"github.com/superfly/flyctl/pkg/logs"
+	"github.com/superfly/flyctl/terminal"
+)
+
+func newMachineExecCommand(parent *Command, client *client.Client) {
+	cmd := BuildCommandKS(parent, runMachineExec, docstrings.Get("machine.exec"), client, requireSession, requireAppName)
+
+	cmd.AddIntFlag(IntFlagOpts{
+		Name:        "timeout",
+		Description: "Seconds to let the command run for before it's terminated. 0 lets it run indefinitely.",
+	})
+
+	cmd.Args = cobra.MinimumNArgs(2)
+}
+
+func runMachineExec(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+	client := cmdCtx.Client.API()
+
+	timeout := cmdCtx.Config.GetInt("timeout")
+	if timeout < 0 {
+		return errors.New("--timeout must not be negative")
+	}
+
+	machine, err := client.GetMachine(ctx, cmdCtx.AppName, cmdCtx.Args[0])
+	if err != nil {
+		return errors.Wrap(err, "could not get machine")
+	}
+
+	if machine.State != "started" {
+		return fmt.Errorf("machine %s is %s; start it with 'flyctl machine start %s'", machine.ID, machine.State, machine.ID)
+	}
+
+	addr := machinePrivateIP(machine)
+	if addr == "" {
+		return fmt.Errorf("machine %s has no private IP address", machine.ID)
+	}
+
+	app, err := client.GetApp(ctx, cmdCtx.AppName)
+	if err != nil {
+		return fmt.Errorf("get app: %w", err)
+	}
+
+	agentclient, err := agent.Establish(ctx, client)
+	if err != nil {
+		return errors.Wrap(err, "can't establish agent")
+	}
+
+	dialer, err := agentclient.Dialer(ctx, app.Organization.Slug)
+	if err != nil {
+		return fmt.Errorf("ssh: can't build tunnel for %s: %s\n", app.Organization.Slug, err)
+	}
+
+	cmdCtx.IO.StartProgressIndicatorMsg("Connecting to tunnel")
+	if err := agentclient.WaitForTunnel(ctx, app.Organization.Slug); err != nil {
+		return errors.Wrapf(err, "tunnel unavailable")
+	}
+	cmdCtx.IO.StopProgressIndicator()
+
+	params := &SSHParams{
+		Ctx:            cmdCtx,
+		Org:            &app.Organization,
+		Dialer:         dialer,
+		App:            app.Name,
+		DisableSpinner: true,
+	}
+
+	sshClient, err := sshDial(params, fmt.Sprintf("[%s]", addr))
+	if err != nil {
+		return err
+	}
+	defer sshClient.Close()
+
+	runCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+	}
+
+	err = sshClient.Run(runCtx, strings.Join(cmdCtx.Args[1:], " "), os.Stdout, os.Stderr)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("command terminated after running for %ds: %w", timeout, err)
+	}
+
+	return exitStatusError(err)
+}
+
+// machinePrivateIP returns the private IPv6 address of the given machine.
+func machinePrivateIP(machine *api.Machine) string {
+	for _, ip := range machine.IPs.Nodes {
+		if ip.Family == "v6" && ip.Kind == "privatenet" {
+			return ip.IP
+		}
+	}
+
+	return ""
+}
+
+func newMachineLogsCommand(parent *Command, client *client.Client) {
+	cmd := BuildCommandKS(parent, runMachineLogs, docstrings.Get("machine.logs"), client, requireSession, requireAppName)
+
+	cmd.Args = cobra.ExactArgs(1)
+}
+
+func runMachineLogs(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+
+	machine, err := cmdCtx.Client.API().GetMachine(ctx, cmdCtx.AppName, cmdCtx.Args[0])
+	if err != nil {
+		return errors.Wrap(err, "could not get machine")
+	}
+
+	return streamMachineLogs(cmdCtx, cmdCtx.AppName, machine.ID)
+}
+
+// streamMachineLogs prints the logs of the given machine until the command's
+// context is done.
+func streamMachineLogs(cmdCtx *cmdctx.CmdContext, appName, id string) error {
+	ctx := cmdCtx.Command.Context()
+	apiClient := cmdCtx.Client.API()
+
+	opts := &logs.LogOptions{
+		AppName: appName,
+		VMID:    id,
+	}
+
+	stream, err := logs.NewNatsStream(ctx, apiClient, opts)
+	if err != nil {
+		terminal.Debugf("could not connect to wireguard tunnel, err: %v\n", err)
+		terminal.Debug("Falling back to log polling...")
+
+		stream, err = logs.NewPollingStream(ctx, apiClient, opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	presenter := presenters.LogPresenter{}
+
+	entries := stream.Stream(ctx, opts)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return stream.Err()
+		case entry, ok := <-entries:
+			if !ok {
+				return stream.Err()
+			}
+
+			presenter.FPrint(cmdCtx.Out, cmdCtx.OutputJSON(), entry)
+		}
+	}
+}
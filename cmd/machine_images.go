@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/docstrings"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/pkg/machines"
+	"github.com/superfly/flyctl/terminal"
+)
+
+func newMachineImagesCommand(parent *Command, client *client.Client) {
+	cmd := BuildCommandKS(parent, nil, docstrings.Get("machine.images"), client)
+
+	list := BuildCommandKS(cmd, runMachineImagesList, docstrings.Get("machine.images.list"), client, requireSession)
+	list.Aliases = []string{"ls"}
+	list.Args = cobra.NoArgs
+}
+
+func runMachineImagesList(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+
+	images, err := machines.LatestBaseImages(ctx, cmdCtx.Client.API())
+	if err != nil {
+		if len(images) == 0 {
+			return errors.Wrap(err, "could not get base images")
+		}
+
+		terminal.Warnf("could not determine the latest version of every base image: %v\n", err)
+	}
+
+	if cmdCtx.OutputJSON() {
+		cmdCtx.WriteJSON(images)
+
+		return nil
+	}
+
+	data := make([][]string, 0, len(images))
+	for _, image := range images {
+		latest := image.Latest
+		if latest == "" {
+			latest = "unknown"
+		}
+
+		data = append(data, []string{image.Name, image.Repository, latest, image.Description, image.Changelog})
+	}
+
+	table := tablewriter.NewWriter(cmdCtx.Out)
+	table.SetHeader([]string{"Name", "Repository", "Latest", "Description", "Changelog"})
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetBorder(false)
+	table.SetTablePadding("\t")
+	table.SetNoWhiteSpace(true)
+	table.AppendBulk(data)
+	table.Render()
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/pkg/machines"
+)
+
+func addMachineLabelFlags(cmd *Command, removable bool) {
+	cmd.AddStringSliceFlag(StringSliceFlagOpts{
+		Name:        "label",
+		Description: "Label to set on the machine in the form of KEY=VALUE. Can be specified multiple times.",
+	})
+
+	if removable {
+		cmd.AddStringSliceFlag(StringSliceFlagOpts{
+			Name:        "remove-label",
+			Description: "Key of a label to remove from the machine. Can be specified multiple times.",
+		})
+	}
+}
+
+// applyMachineLabels applies the labels the label flags denote to conf.
+func applyMachineLabels(cmdCtx *cmdctx.CmdContext, conf *api.MachineConfig) error {
+	labels, err := machines.ParseLabels(cmdCtx.Config.GetStringSlice("label"))
+	if err != nil {
+		return err
+	}
+
+	return machines.SetLabels(conf, labels, cmdCtx.Config.GetStringSlice("remove-label"))
+}
+
+func addMachineSelectorFlag(cmd *Command) {
+	cmd.AddStringFlag(StringFlagOpts{
+		Name:        "selector",
+		Shorthand:   "l",
+		Description: "Only operate on the machines whose labels match, e.g. env=staging,tier!=web",
+	})
+}
+
+// machineSelector returns the selector the selector flag denotes, or nil in
+// case it isn't set.
+func machineSelector(cmdCtx *cmdctx.CmdContext) (*machines.Selector, error) {
+	expr := cmdCtx.Config.GetString("selector")
+	if expr == "" {
+		return nil, nil
+	}
+
+	return machines.ParseSelector(expr)
+}
+
+// selectedMachines returns the machines of the app, in any state, which
+// selector matches. Selectors which match none are errors.
+func selectedMachines(cmdCtx *cmdctx.CmdContext, selector *machines.Selector) ([]*api.Machine, error) {
+	all, err := cmdCtx.Client.API().ListMachines(cmdCtx.Command.Context(), cmdCtx.AppName, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get list of machines")
+	}
+
+	selected := selector.Select(all)
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no machines of %s match selector %s", cmdCtx.AppName, selector)
+	}
+
+	return selected, nil
+}
+
+// machineTargets returns the IDs of the machines commands which take either
+// machine IDs or the selector flag operate on.
+func machineTargets(cmdCtx *cmdctx.CmdContext) ([]string, error) {
+	selector, err := machineSelector(cmdCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case selector != nil && len(cmdCtx.Args) > 0:
+		return nil, errors.New("--selector is mutually exclusive with machine IDs")
+	case selector == nil && len(cmdCtx.Args) == 0:
+		return nil, errors.New("requires a machine ID, or --selector")
+	case selector == nil:
+		return cmdCtx.Args, nil
+	}
+
+	selected, err := selectedMachines(cmdCtx, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(selected))
+	for _, m := range selected {
+		ids = append(ids, m.ID)
+	}
+
+	return ids, nil
+}
+
+func addMachineLabelColumnFlags(cmd *Command) {
+	cmd.AddBoolFlag(BoolFlagOpts{
+		Name:        "show-labels",
+		Description: "Show the labels of machines",
+	})
+
+	cmd.AddStringSliceFlag(StringSliceFlagOpts{
+		Name:        "label-columns",
+		Shorthand:   "L",
+		Description: "Keys of labels to show the values of in columns of their own, e.g. env,tier",
+	})
+}
+
+// machineLabelHeaders returns the headers of the label columns the label
+// column flags add to machine tables.
+func machineLabelHeaders(cmdCtx *cmdctx.CmdContext) (headers []string) {
+	headers = append(headers, cmdCtx.Config.GetStringSlice("label-columns")...)
+	if cmdCtx.Config.GetBool("show-labels") {
+		headers = append(headers, "Labels")
+	}
+
+	return
+}
+
+// machineLabelCells returns the cells of the label columns of the row of m.
+func machineLabelCells(cmdCtx *cmdctx.CmdContext, m *api.Machine) (cells []string) {
+	labels := machines.Labels(m)
+
+	for _, key := range cmdCtx.Config.GetStringSlice("label-columns") {
+		cells = append(cells, labels[key])
+	}
+	if cmdCtx.Config.GetBool("show-labels") {
+		cells = append(cells, machines.FormatLabels(labels))
+	}
+
+	return
+}
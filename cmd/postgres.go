@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/exec"
 	"strconv"
 	"strings"
 	"time"
@@ -21,6 +22,7 @@ import (
 	"github.com/superfly/flyctl/helpers"
 	"github.com/superfly/flyctl/internal/client"
 	"github.com/superfly/flyctl/pkg/agent"
+	"github.com/superfly/flyctl/terminal"
 )
 
 type PostgresConfiguration struct {
@@ -114,6 +116,8 @@ func newPostgresCommand(client *client.Client) *Command {
 	connectCmd.AddStringFlag(StringFlagOpts{Name: "database", Description: "The postgres database to connect to"})
 	connectCmd.AddStringFlag(StringFlagOpts{Name: "user", Description: "The postgres user to connect with"})
 	connectCmd.AddStringFlag(StringFlagOpts{Name: "password", Description: "The postgres user password"})
+	connectCmd.AddBoolFlag(BoolFlagOpts{Name: "url", Description: "Print a DATABASE_URL for a local port proxied to the cluster instead of launching psql"})
+	connectCmd.AddIntFlag(IntFlagOpts{Name: "local-port", Description: "The local port to proxy the cluster on, defaults to a free one"})
 
 	attachStrngs := docstrings.Get("postgres.attach")
 	attachCmd := BuildCommandKS(cmd, runAttachPostgresCluster, attachStrngs, client, requireSession, requireAppName)
@@ -140,6 +144,8 @@ func newPostgresCommand(client *client.Client) *Command {
 	usersListCmd := BuildCommandKS(usersCmd, runListPostgresUsers, usersListStrings, client, requireSession, requireAppNameAsArg)
 	usersListCmd.Args = cobra.ExactArgs(1)
 
+	newPostgresBackupCommand(cmd, client)
+
 	return cmd
 }
 
@@ -581,18 +587,53 @@ func runDetachPostgresCluster(cmdCtx *cmdctx.CmdContext) error {
 }
 
 func runPostgresConnect(cmdCtx *cmdctx.CmdContext) error {
-	// Minimum image version requirements
-	MinPostgresStandaloneVersion := "0.0.4"
-	MinPostgresHaVersion := "0.0.9"
+	app, dialer, err := postgresTunnel(cmdCtx, cmdCtx.AppName)
+	if err != nil {
+		return err
+	}
 
-	ctx := cmdCtx.Command.Context()
-	client := cmdCtx.Client.API()
+	database := cmdCtx.Config.GetString("database")
+	if database == "" {
+		database = "postgres"
+	}
 
-	app, err := client.GetApp(ctx, cmdCtx.AppName)
-	if err != nil {
-		return fmt.Errorf("get app: %w", err)
+	user := cmdCtx.Config.GetString("user")
+	if user == "" {
+		user = "postgres"
+	}
+
+	password := cmdCtx.Config.GetString("password")
+
+	printURL := cmdCtx.Config.GetBool("url")
+
+	psql, err := exec.LookPath("psql")
+	if err != nil && !printURL {
+		terminal.Debugf("psql isn't installed (%v); connecting over ssh\n", err)
+
+		return runPostgresConnectSSH(cmdCtx, app, dialer, database, user, password)
+	}
+
+	if password == "" {
+		if password, err = postgresPassword(cmdCtx, app, dialer, user); err != nil {
+			return err
+		}
 	}
 
+	return proxyPostgres(cmdCtx, app, dialer, &postgresConnection{
+		database: database,
+		user:     user,
+		password: password,
+		port:     cmdCtx.Config.GetInt("local-port"),
+	}, psql, printURL)
+}
+
+// runPostgresConnectSSH runs the postgres console on the cluster itself, for
+// hosts without psql.
+func runPostgresConnectSSH(cmdCtx *cmdctx.CmdContext, app *api.App, dialer agent.Dialer, database, user, password string) error {
+	// Minimum image version requirements
+	MinPostgresStandaloneVersion := "0.0.4"
+	MinPostgresHaVersion := "0.0.9"
+
 	// Validate image version to ensure it's compatible with this feature.
 	if app.ImageDetails.Version == "" {
 		return fmt.Errorf("PG Connect is not compatible with this image.")
@@ -632,36 +673,14 @@ func runPostgresConnect(cmdCtx *cmdctx.CmdContext) error {
 			imageVersion, requiredVersion.String())
 	}
 
-	agentclient, err := agent.Establish(ctx, cmdCtx.Client.API())
-	if err != nil {
-		return errors.Wrap(err, "can't establish agent")
-	}
-
-	dialer, err := agentclient.Dialer(ctx, app.Organization.Slug)
-	if err != nil {
-		return fmt.Errorf("ssh: can't build tunnel for %s: %s\n", app.Organization.Slug, err)
-	}
-
-	database := cmdCtx.Config.GetString("database")
-	if database == "" {
-		database = "postgres"
-	}
-
-	user := cmdCtx.Config.GetString("user")
-	if user == "" {
-		user = "postgres"
-	}
-
-	password := cmdCtx.Config.GetString("password")
-
-	addr := fmt.Sprintf("%s.internal", cmdCtx.AppName)
+	addr := fmt.Sprintf("%s.internal", app.Name)
 	cmd := fmt.Sprintf("connect %s %s %s", database, user, password)
 
 	return sshConnect(&SSHParams{
 		Ctx:    cmdCtx,
 		Org:    &app.Organization,
 		Dialer: dialer,
-		App:    cmdCtx.AppName,
+		App:    app.Name,
 		Cmd:    cmd,
 		Stdin:  os.Stdin,
 		Stdout: os.Stdout,
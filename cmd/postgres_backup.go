@@ -0,0 +1,462 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/docstrings"
+	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/pkg/agent"
+	"github.com/superfly/flyctl/terminal"
+)
+
+// postgresDataDir is the data directory of the postgres images.
+const postgresDataDir = "/data/postgres"
+
+// walgEnvPrefixes are the prefixes of the environment variables WAL-G is
+// configured with: where backups are stored and the credentials to that
+// storage.
+var walgEnvPrefixes = []string{"WALG_", "WALE_", "AWS_", "GOOGLE_", "AZURE_", "SWIFT_"}
+
+// PostgresBackup describes a base backup, as listed by wal-g backup-list.
+type PostgresBackup struct {
+	Name             string    `json:"backup_name"`
+	WalFileName      string    `json:"wal_file_name"`
+	StartTime        time.Time `json:"start_time"`
+	FinishTime       time.Time `json:"finish_time"`
+	CompressedSize   int64     `json:"compressed_size"`
+	UncompressedSize int64     `json:"uncompressed_size"`
+}
+
+func newPostgresBackupCommand(parent *Command, client *client.Client) {
+	backupStrings := docstrings.Get("postgres.backup")
+	backupCmd := BuildCommandKS(parent, nil, backupStrings, client, requireSession)
+
+	createStrings := docstrings.Get("postgres.backup.create")
+	createCmd := BuildCommandKS(backupCmd, runCreatePostgresBackup, createStrings, client, requireSession, requireAppNameAsArg)
+	createCmd.Args = cobra.ExactArgs(1)
+
+	listStrings := docstrings.Get("postgres.backup.list")
+	listCmd := BuildCommandKS(backupCmd, runListPostgresBackups, listStrings, client, requireSession, requireAppNameAsArg)
+	listCmd.Args = cobra.ExactArgs(1)
+
+	restoreStrings := docstrings.Get("postgres.backup.restore")
+	restoreCmd := BuildCommandKS(backupCmd, runRestorePostgresBackup, restoreStrings, client, requireSession, requireAppNameAsArg)
+	restoreCmd.Args = cobra.ExactArgs(1)
+	restoreCmd.AddStringFlag(StringFlagOpts{Name: "name", Description: "the name of the new cluster to restore into"})
+	restoreCmd.AddStringFlag(StringFlagOpts{Name: "backup", Description: "the base backup to restore, defaults to the latest one"})
+	restoreCmd.AddStringFlag(StringFlagOpts{Name: "time", Description: "restore to this point in time (RFC 3339, e.g. 2021-06-01T15:04:05Z) by replaying WAL after the base backup"})
+	restoreCmd.AddStringFlag(StringFlagOpts{Name: "region", Description: "the region to launch the new cluster in"})
+	restoreCmd.AddStringFlag(StringFlagOpts{Name: "vm-size", Description: "the size of the VM", Default: "shared-cpu-1x"})
+	restoreCmd.AddIntFlag(IntFlagOpts{Name: "volume-size", Description: "the size in GB for the volume", Default: 10})
+	restoreCmd.AddBoolFlag(BoolFlagOpts{Name: "yes", Shorthand: "y", Description: "Accept all confirmations"})
+}
+
+func runCreatePostgresBackup(cmdCtx *cmdctx.CmdContext) error {
+	app, dialer, err := postgresTunnel(cmdCtx, cmdCtx.AppName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmdCtx.Out, "Creating base backup of %s\n", app.Name)
+
+	if err := runPostgresScript(cmdCtx, app, dialer, shellCommand(walgCommand("backup-push", postgresDataDir))); err != nil {
+		return fmt.Errorf("failed creating backup: %w", err)
+	}
+
+	fmt.Fprintln(cmdCtx.Out, "Backup created")
+
+	return nil
+}
+
+func runListPostgresBackups(cmdCtx *cmdctx.CmdContext) error {
+	app, dialer, err := postgresTunnel(cmdCtx, cmdCtx.AppName)
+	if err != nil {
+		return err
+	}
+
+	backups, err := listPostgresBackups(cmdCtx, app, dialer)
+	if err != nil {
+		return err
+	}
+
+	if cmdCtx.OutputJSON() {
+		cmdCtx.WriteJSON(backups)
+		return nil
+	}
+
+	table := helpers.MakeSimpleTable(cmdCtx.Out, []string{"Name", "Started", "Finished", "Size", "WAL File"})
+
+	for _, backup := range backups {
+		table.Append([]string{
+			backup.Name,
+			backup.StartTime.UTC().Format(time.RFC3339),
+			backup.FinishTime.UTC().Format(time.RFC3339),
+			humanize.Bytes(uint64(backup.CompressedSize)),
+			backup.WalFileName,
+		})
+	}
+
+	table.Render()
+
+	return nil
+}
+
+func runRestorePostgresBackup(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+	client := cmdCtx.Client.API()
+
+	var target *time.Time
+	if t := cmdCtx.Config.GetString("time"); t != "" {
+		parsed, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			return fmt.Errorf("invalid --time %q; use RFC 3339, e.g. 2021-06-01T15:04:05Z", t)
+		}
+		target = &parsed
+	}
+
+	source, dialer, err := postgresTunnel(cmdCtx, cmdCtx.AppName)
+	if err != nil {
+		return err
+	}
+
+	walgEnv, err := postgresWalgEnv(cmdCtx, source, dialer)
+	if err != nil {
+		return err
+	}
+
+	backups, err := listPostgresBackups(cmdCtx, source, dialer)
+	if err != nil {
+		return err
+	}
+
+	backup, err := selectPostgresBackup(backups, cmdCtx.Config.GetString("backup"), target)
+	if err != nil {
+		return err
+	}
+
+	name := cmdCtx.Config.GetString("name")
+	if name == "" {
+		if name, err = inputAppName("", false); err != nil {
+			return err
+		}
+	}
+
+	region, err := selectRegion(ctx, client, cmdCtx.Config.GetString("region"))
+	if err != nil {
+		return err
+	}
+
+	vmSize, err := selectVMSize(ctx, client, cmdCtx.Config.GetString("vm-size"))
+	if err != nil {
+		return err
+	}
+
+	restoring := fmt.Sprintf("backup %s", backup.Name)
+	if target != nil {
+		restoring += fmt.Sprintf(" replayed up to %s", target.UTC().Format(time.RFC3339))
+	}
+
+	if !cmdCtx.Config.GetBool("yes") {
+		if !confirm(fmt.Sprintf("Restore %s of %s into a new cluster %s?", restoring, source.Name, name)) {
+			return nil
+		}
+	}
+
+	fmt.Fprintf(cmdCtx.Out, "Creating postgres cluster %s in organization %s\n", name, source.Organization.Slug)
+
+	// Restores start off a single node; replicas are better added once the
+	// restored data is in place than made to follow along while it's replaced.
+	payload, err := runApiCreatePostgresCluster(cmdCtx, source.Organization.Slug, &api.CreatePostgresClusterInput{
+		OrganizationID: source.Organization.ID,
+		Name:           name,
+		Region:         api.StringPointer(region.Code),
+		ImageRef:       api.StringPointer("flyio/postgres"),
+		VMSize:         api.StringPointer(vmSize.Name),
+		VolumeSizeGB:   api.IntPointer(cmdCtx.Config.GetInt("volume-size")),
+		Count:          api.IntPointer(1),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmdCtx.Out, "Configuring WAL-G on %s\n", payload.App.Name)
+
+	// The restored cluster mustn't archive into the storage of the source,
+	// where its WAL would mix with the source's; it reads from there only
+	// while restoring.
+	restoredEnv, sourcePrefixes := restoredWalgEnv(walgEnv, payload.App.Name)
+
+	if _, err := client.SetSecrets(ctx, payload.App.Name, restoredEnv); err != nil {
+		return fmt.Errorf("failed configuring WAL-G: %w", err)
+	}
+
+	cmdCtx.AppName = payload.App.Name
+	if err := watchDeployment(ctx, cmdCtx, ""); err != nil && !isCancelledError(err) {
+		return err
+	}
+
+	restored, restoredDialer, err := postgresTunnel(cmdCtx, payload.App.Name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmdCtx.Out, "Restoring %s into %s\n", restoring, restored.Name)
+
+	if err := runPostgresScript(cmdCtx, restored, restoredDialer, postgresRestoreScript(backup.Name, target, sourcePrefixes)); err != nil {
+		return fmt.Errorf("failed restoring backup: %w", err)
+	}
+
+	if _, err := client.RestartApp(ctx, restored.Name); err != nil {
+		return fmt.Errorf("failed restarting %s: %w", restored.Name, err)
+	}
+
+	fmt.Fprintf(cmdCtx.Out, "Restored %s of %s into %s; postgres replays WAL while it restarts.\n", restoring, source.Name, restored.Name)
+	fmt.Fprintf(cmdCtx.Out, "The data it replaced is kept in %s.pre-restore until you remove it.\n", postgresDataDir)
+
+	return nil
+}
+
+// postgresTunnel returns the named postgres app, once the tunnel to its
+// organization is up and the app answers on it.
+func postgresTunnel(cmdCtx *cmdctx.CmdContext, appName string) (*api.App, agent.Dialer, error) {
+	ctx := cmdCtx.Command.Context()
+
+	app, err := cmdCtx.Client.API().GetApp(ctx, appName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get app: %w", err)
+	}
+
+	agentclient, dialer, err := connectTunnel(cmdCtx, app)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cmdCtx.IO.StartProgressIndicatorMsg(fmt.Sprintf("Looking up %s.internal", app.Name))
+	defer cmdCtx.IO.StopProgressIndicator()
+
+	if err := agentclient.WaitForDNS(ctx, dialer, app.Organization.Slug, fmt.Sprintf("%s.internal", app.Name)); err != nil {
+		return nil, nil, fmt.Errorf("%s is unreachable over the tunnel: %w", app.Name, err)
+	}
+
+	return app, dialer, nil
+}
+
+func listPostgresBackups(cmdCtx *cmdctx.CmdContext, app *api.App, dialer agent.Dialer) ([]PostgresBackup, error) {
+	out, err := runSSHCommand(cmdCtx, app, dialer, shellCommand(walgCommand("backup-list", "--json", "--detail")+" 2>/dev/null"))
+	if err != nil {
+		return nil, fmt.Errorf("failed listing backups: %w", err)
+	}
+
+	var backups []PostgresBackup
+	if trimmed := strings.TrimSpace(string(out)); trimmed != "" {
+		if err := json.Unmarshal([]byte(trimmed), &backups); err != nil {
+			return nil, fmt.Errorf("failed decoding backups (is WAL-G configured on %s?): %w", app.Name, err)
+		}
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].FinishTime.Before(backups[j].FinishTime)
+	})
+
+	return backups, nil
+}
+
+// selectPostgresBackup returns the named backup or, in case name is empty,
+// the latest backup which finished by target, so that WAL may be replayed
+// from it up to target.
+func selectPostgresBackup(backups []PostgresBackup, name string, target *time.Time) (*PostgresBackup, error) {
+	if len(backups) == 0 {
+		return nil, fmt.Errorf("no backups; create one with flyctl postgres backup create")
+	}
+
+	if name != "" {
+		for i := range backups {
+			if backups[i].Name != name {
+				continue
+			}
+
+			if target != nil && backups[i].FinishTime.After(*target) {
+				return nil, fmt.Errorf("backup %s finished after %s; pick an earlier backup or a later time", name, target.UTC().Format(time.RFC3339))
+			}
+
+			return &backups[i], nil
+		}
+
+		return nil, fmt.Errorf("no backup %s", name)
+	}
+
+	for i := len(backups) - 1; i >= 0; i-- {
+		if target == nil || !backups[i].FinishTime.After(*target) {
+			return &backups[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("no backup finished by %s", target.UTC().Format(time.RFC3339))
+}
+
+// postgresWalgEnv returns the WAL-G configuration of app, so that clusters
+// restored from its backups may read them.
+func postgresWalgEnv(cmdCtx *cmdctx.CmdContext, app *api.App, dialer agent.Dialer) (map[string]string, error) {
+	all, err := postgresEnv(cmdCtx, app, dialer)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading WAL-G configuration: %w", err)
+	}
+
+	env := map[string]string{}
+	for key, value := range all {
+		for _, prefix := range walgEnvPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				env[key] = value
+			}
+		}
+	}
+
+	_, prefixes := restoredWalgEnv(env, "")
+	if len(prefixes) == 0 {
+		return nil, fmt.Errorf("WAL-G isn't configured on %s; set its WALG_ secrets first", app.Name)
+	}
+
+	return env, nil
+}
+
+// restoredWalgEnv returns the WAL-G configuration of the cluster named name
+// restored from backups WAL-G stores as env configures, along with the
+// storage prefixes of env. The restored cluster stores its backups next to
+// the ones it's restored from, under prefixes suffixed with its name.
+func restoredWalgEnv(env map[string]string, name string) (restored, prefixes map[string]string) {
+	restored = map[string]string{}
+	prefixes = map[string]string{}
+
+	for key, value := range env {
+		restored[key] = value
+
+		if (strings.HasPrefix(key, "WALG_") || strings.HasPrefix(key, "WALE_")) && strings.HasSuffix(key, "_PREFIX") {
+			prefixes[key] = value
+			restored[key] = strings.TrimRight(value, "/") + "-" + name
+		}
+	}
+
+	return restored, prefixes
+}
+
+// postgresEnv returns the environment of app, secrets included.
+func postgresEnv(cmdCtx *cmdctx.CmdContext, app *api.App, dialer agent.Dialer) (map[string]string, error) {
+	out, err := runSSHCommand(cmdCtx, app, dialer, "env")
+	if err != nil {
+		return nil, err
+	}
+
+	env := map[string]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		if i := strings.Index(line, "="); i > 0 {
+			env[line[:i]] = strings.TrimRight(line[i+1:], "\r")
+		}
+	}
+
+	return env, nil
+}
+
+// postgresRestoreScript returns the script which fetches the named backup in
+// place of the data directory and sets postgres up to recover from it,
+// replaying WAL up to target or to its end in case target is nil. Backups and
+// WAL are read from the given storage prefixes.
+func postgresRestoreScript(backup string, target *time.Time, prefixes map[string]string) string {
+	restore := postgresDataDir + ".restore"
+	restoreCommand := walgEnvAssignments(prefixes) + "wal-g wal-fetch %f %p"
+
+	lines := []string{
+		"set -e",
+		"rm -rf " + restore,
+		walgCommandWithEnv(prefixes, "backup-fetch", restore, backup),
+		fmt.Sprintf("echo %s >> %s/postgresql.auto.conf", shellQuote("restore_command = '"+strings.ReplaceAll(restoreCommand, "'", "''")+"'"), restore),
+	}
+
+	if target != nil {
+		lines = append(lines,
+			fmt.Sprintf("echo %s >> %s/postgresql.auto.conf", shellQuote(fmt.Sprintf("recovery_target_time = '%s'", target.UTC().Format(time.RFC3339))), restore),
+			fmt.Sprintf("echo %s >> %s/postgresql.auto.conf", shellQuote("recovery_target_action = 'promote'"), restore),
+		)
+	}
+
+	lines = append(lines,
+		fmt.Sprintf("touch %s/recovery.signal", restore),
+		fmt.Sprintf("chown -R postgres:postgres %s", restore),
+		fmt.Sprintf("su postgres -c %s || true", shellQuote("pg_ctl -D "+postgresDataDir+" stop -m fast")),
+		fmt.Sprintf("rm -rf %[1]s.pre-restore && mv %[1]s %[1]s.pre-restore && mv %[2]s %[1]s", postgresDataDir, restore),
+	)
+
+	return shellCommand(strings.Join(lines, "\n"))
+}
+
+// runPostgresScript runs script on app, streaming its output.
+func runPostgresScript(cmdCtx *cmdctx.CmdContext, app *api.App, dialer agent.Dialer, script string) error {
+	sshClient, err := sshDial(&SSHParams{
+		Ctx:            cmdCtx,
+		Org:            &app.Organization,
+		Dialer:         dialer,
+		App:            app.Name,
+		DisableSpinner: true,
+	}, fmt.Sprintf("%s.internal", app.Name))
+	if err != nil {
+		return err
+	}
+	defer sshClient.Close()
+
+	terminal.Debugf("Running on %s: %s\n", app.Name, script)
+
+	return exitStatusError(sshClient.Run(cmdCtx.Command.Context(), script, cmdCtx.Out, os.Stderr))
+}
+
+// walgCommand returns the shell command running wal-g with args as the
+// postgres user, which owns the data directory.
+func walgCommand(args ...string) string {
+	return walgCommandWithEnv(nil, args...)
+}
+
+// walgCommandWithEnv is like walgCommand, but runs wal-g with env on top of
+// its configuration.
+func walgCommandWithEnv(env map[string]string, args ...string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+
+	return "su postgres -c " + shellQuote(walgEnvAssignments(env)+"wal-g "+strings.Join(quoted, " "))
+}
+
+// walgEnvAssignments returns env as the variable assignments prefixing a
+// shell command.
+func walgEnvAssignments(env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s=%s ", key, shellQuote(env[key]))
+	}
+
+	return b.String()
+}
+
+// shellCommand wraps script in a shell, since commands run over SSH don't
+// run in one.
+func shellCommand(script string) string {
+	return "sh -c " + shellQuote(script)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
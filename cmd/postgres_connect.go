@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/pkg/agent"
+	"github.com/superfly/flyctl/pkg/proxy"
+	"github.com/superfly/flyctl/terminal"
+)
+
+// postgresProxyPort is the port postgres clusters route to their leader on.
+const postgresProxyPort = 5432
+
+// postgresPasswordEnv maps the users the postgres images create to the
+// secrets which hold their passwords.
+var postgresPasswordEnv = map[string]string{
+	"postgres":   "OPERATOR_PASSWORD",
+	"flypgadmin": "SU_PASSWORD",
+}
+
+type postgresConnection struct {
+	database string
+	user     string
+	password string
+	port     int
+}
+
+// URL returns the URL of the connection through its local port.
+func (c *postgresConnection) URL() string {
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.User(c.user),
+		Host:   net.JoinHostPort("localhost", strconv.Itoa(c.port)),
+		Path:   "/" + c.database,
+	}
+	if c.password != "" {
+		u.User = url.UserPassword(c.user, c.password)
+	}
+
+	return u.String()
+}
+
+// postgresPassword returns the password of user, read off the cluster for the
+// users it creates and prompted for otherwise.
+func postgresPassword(cmdCtx *cmdctx.CmdContext, app *api.App, dialer agent.Dialer, user string) (string, error) {
+	if key, ok := postgresPasswordEnv[user]; ok {
+		env, err := postgresEnv(cmdCtx, app, dialer)
+		if err != nil {
+			terminal.Debugf("Failed reading the password of %s off %s: %v\n", user, app.Name, err)
+		} else if password := env[key]; password != "" {
+			return password, nil
+		}
+	}
+
+	var password string
+	prompt := &survey.Password{
+		Message: fmt.Sprintf("Password for %s:", user),
+	}
+
+	if err := survey.AskOne(prompt, &password); err != nil {
+		return "", err
+	}
+
+	return password, nil
+}
+
+// proxyPostgres proxies a local port to the cluster and either prints the URL
+// of conn through it, until interrupted, or runs psql against it.
+func proxyPostgres(cmdCtx *cmdctx.CmdContext, app *api.App, dialer agent.Dialer, conn *postgresConnection, psql string, printURL bool) error {
+	listener, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: conn.port})
+	if err != nil {
+		return fmt.Errorf("failed binding local port: %w", err)
+	}
+	conn.port = listener.Addr().(*net.TCPAddr).Port
+
+	srv := &proxy.Server{
+		LocalAddr: strconv.Itoa(conn.port),
+		Addr:      fmt.Sprintf("%s.internal:%d", app.Name, postgresProxyPort),
+		Listener:  listener,
+		Dial:      dialer.DialContext,
+	}
+
+	if printURL {
+		fmt.Fprintf(cmdCtx.Out, "Proxying local port %d to %s\n", conn.port, srv.Addr)
+		fmt.Fprintf(cmdCtx.Out, "DATABASE_URL=%s\n", conn.URL())
+		fmt.Fprintln(cmdCtx.Out, "Press Ctrl-C to stop proxying")
+
+		return srv.ProxyServer(cmdCtx.Command.Context())
+	}
+
+	// psql cancels queries on Ctrl-C and keeps running, so the proxy may not
+	// stop along with the command until it exits.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		if err := srv.ProxyServer(ctx); err != nil {
+			terminal.Debugf("Proxy stopped: %v\n", err)
+		}
+	}()
+
+	password := conn.password
+	conn.password = ""
+
+	cmd := exec.Command(psql, conn.URL())
+	cmd.Env = append(os.Environ(), "PGPASSWORD="+password)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return &remoteExitError{status: exitErr.ExitCode()}
+		}
+
+		return fmt.Errorf("failed running psql: %w", err)
+	}
+
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 
 	"github.com/olekukonko/tablewriter"
+	"github.com/superfly/flyctl/internal/outputtemplate"
 )
 
 // Presentable - Records (and field names) which may be presented by a Presenter
@@ -29,10 +30,17 @@ type Options struct {
 	HideHeader bool
 	Title      string
 	AsJSON     bool
+	// Format is the Go template of the --format flag, which the API struct
+	// of the item is rendered through in place of JSON.
+	Format string
 }
 
 // Render - Renders a presenter as a field list or table
 func (p *Presenter) Render() error {
+	if p.Opts.Format != "" {
+		return p.renderTemplate()
+	}
+
 	if p.Opts.AsJSON {
 		return p.renderJSON()
 	}
@@ -125,3 +133,13 @@ func (p *Presenter) renderJSON() error {
 		return err
 	}
 }
+
+func (p *Presenter) renderTemplate() error {
+	var data = p.Item.APIStruct()
+
+	if data == nil {
+		return fmt.Errorf("--format is not available for this output")
+	}
+
+	return outputtemplate.Execute(p.Out, p.Opts.Format, data)
+}
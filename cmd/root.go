@@ -33,14 +33,26 @@ func NewRootCmd(client *client.Client) *cobra.Command {
 	err := viper.BindPFlag(flyctl.ConfigAPIToken, rootCmd.PersistentFlags().Lookup("access-token"))
 	checkErr(err)
 
-	rootCmd.PersistentFlags().Bool("verbose", false, "verbose output")
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
 	err = viper.BindPFlag(flyctl.ConfigVerboseOutput, rootCmd.PersistentFlags().Lookup("verbose"))
 	checkErr(err)
 
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "only output results, outcomes and errors")
+	err = viper.BindPFlag(flyctl.ConfigQuietOutput, rootCmd.PersistentFlags().Lookup("quiet"))
+	checkErr(err)
+
+	rootCmd.PersistentFlags().Bool("debug", false, "verbose output including debugging information")
+	err = viper.BindPFlag(flyctl.ConfigDebugOutput, rootCmd.PersistentFlags().Lookup("debug"))
+	checkErr(err)
+
 	rootCmd.PersistentFlags().BoolP("json", "j", false, "json output")
 	err = viper.BindPFlag(flyctl.ConfigJSONOutput, rootCmd.PersistentFlags().Lookup("json"))
 	checkErr(err)
 
+	rootCmd.PersistentFlags().String("format", "", "render structured output through the given Go template, e.g. '{{.Version}}'")
+	err = viper.BindPFlag(flyctl.ConfigOutputFormat, rootCmd.PersistentFlags().Lookup("format"))
+	checkErr(err)
+
 	rootCmd.PersistentFlags().String("builtinsfile", "", "Load builtins from named file")
 	err = viper.BindPFlag(flyctl.ConfigBuiltinsfile, rootCmd.PersistentFlags().Lookup("builtinsfile"))
 	checkErr(err)
@@ -70,6 +82,7 @@ func NewRootCmd(client *client.Client) *cobra.Command {
 		newLaunchCommand(client),
 		newMachineCommand(client),
 		newTurbokuCommand(client),
+		newImportCommand(client),
 	)
 
 	return rootCmd.Command
@@ -43,6 +43,15 @@ func newScaleCommand(client *client.Client) *Command {
 		Default:     "",
 	})
 
+	cpuCmdStrings := docstrings.Get("scale.cpu")
+	cpuCmd := BuildCommandKS(cmd, runScaleCPU, cpuCmdStrings, client, requireSession, requireAppName)
+	cpuCmd.Args = cobra.ExactArgs(1)
+	cpuCmd.AddStringFlag(StringFlagOpts{
+		Name:        "group",
+		Description: "The process group to apply the CPU count to",
+		Default:     "",
+	})
+
 	countCmdStrings := docstrings.Get("scale.count")
 	countCmd := BuildCommand(cmd, runScaleCount, countCmdStrings.Usage, countCmdStrings.Short, countCmdStrings.Long, client, requireSession, requireAppName)
 	countCmd.Args = cobra.MinimumNArgs(1)
@@ -111,6 +120,21 @@ func runScaleCount(cmdCtx *cmdctx.CmdContext) error {
 		}
 	}
 
+	// counts may only be set for the groups the app runs, and a bare count is
+	// ambiguous once it runs several
+	_, _, processGroups, err := cmdCtx.Client.API().AppVMResources(ctx, cmdCtx.AppName)
+	if err != nil {
+		return err
+	}
+
+	if count, ok := groups["app"]; ok && len(groups) == 1 && len(processGroups) == 1 {
+		groups = map[string]int{processGroups[0].Name: count}
+	}
+
+	if err := validateCountGroups(groups, processGroups); err != nil {
+		return err
+	}
+
 	// THIS IS AN OPTION TYPE CAN YOU TELL?
 	maxPerRegionRaw := cmdCtx.Config.GetInt("max-per-region")
 	maxPerRegion := &maxPerRegionRaw
@@ -138,6 +162,34 @@ func runScaleCount(cmdCtx *cmdctx.CmdContext) error {
 	return nil
 }
 
+// validateCountGroups checks that counts only names groups of the app.
+func validateCountGroups(counts map[string]int, groups []api.ProcessGroup) error {
+	if len(groups) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(groups))
+	known := make(map[string]bool, len(groups))
+	for _, pg := range groups {
+		names = append(names, pg.Name)
+		known[pg.Name] = true
+	}
+
+	for group := range counts {
+		if known[group] {
+			continue
+		}
+
+		if group == "app" {
+			return fmt.Errorf("the app runs the process groups %s; specify counts per group, e.g. %s=2", strings.Join(names, ", "), names[0])
+		}
+
+		return fmt.Errorf("process group %q not found; the app has %s", group, strings.Join(names, ", "))
+	}
+
+	return nil
+}
+
 func runScaleShow(cmdCtx *cmdctx.CmdContext) error {
 	ctx := cmdCtx.Command.Context()
 
@@ -149,7 +201,7 @@ func runScaleShow(cmdCtx *cmdctx.CmdContext) error {
 	countMsg := countMessage(tgCounts)
 	maxPerRegionMsg := maxPerRegionMessage(processGroups)
 
-	printVMResources(cmdCtx, size, countMsg, maxPerRegionMsg)
+	printVMResources(cmdCtx, size, countMsg, maxPerRegionMsg, processGroups, tgCounts)
 
 	return nil
 }
@@ -200,16 +252,18 @@ func maxPerRegionMessage(groups []api.ProcessGroup) string {
 	return msg
 }
 
-func printVMResources(commandContext *cmdctx.CmdContext, vmSize api.VMSize, count string, maxPerRegion string) {
+func printVMResources(commandContext *cmdctx.CmdContext, vmSize api.VMSize, count string, maxPerRegion string, groups []api.ProcessGroup, counts []api.TaskGroupCount) {
 	if commandContext.OutputJSON() {
 		out := struct {
 			api.VMSize
-			Count        string
-			MaxPerRegion string
+			Count         string
+			MaxPerRegion  string
+			ProcessGroups []api.ProcessGroup
 		}{
-			VMSize:       vmSize,
-			Count:        count,
-			MaxPerRegion: maxPerRegion,
+			VMSize:        vmSize,
+			Count:         count,
+			MaxPerRegion:  maxPerRegion,
+			ProcessGroups: groups,
 		}
 
 		prettyJSON, _ := json.MarshalIndent(out, "", "    ")
@@ -223,6 +277,26 @@ func printVMResources(commandContext *cmdctx.CmdContext, vmSize api.VMSize, coun
 	fmt.Fprintf(commandContext.Out, "%15s: %s\n", "VM Memory", formatMemory(vmSize))
 	fmt.Fprintf(commandContext.Out, "%15s: %s\n", "Count", count)
 	fmt.Fprintf(commandContext.Out, "%15s: %s\n", "Max Per Region", maxPerRegion)
+
+	// a lone group is sized like the app; only list the sizes of several
+	if len(groups) < 2 {
+		return
+	}
+
+	groupCounts := make(map[string]int, len(counts))
+	for _, tg := range counts {
+		groupCounts[tg.Name] = tg.Count
+	}
+
+	fmt.Fprintf(commandContext.Out, "\nProcess Groups\n")
+	for _, pg := range groups {
+		size := vmSize
+		if pg.VMSize != nil {
+			size = *pg.VMSize
+		}
+
+		fmt.Fprintf(commandContext.Out, "%15s: %s, %s CPU, %s, count %d\n", pg.Name, size.Name, formatCores(size), formatMemory(size), groupCounts[pg.Name])
+	}
 }
 
 func runScaleMemory(cmdCtx *cmdctx.CmdContext) error {
@@ -233,26 +307,146 @@ func runScaleMemory(cmdCtx *cmdctx.CmdContext) error {
 		return err
 	}
 
+	group := cmdCtx.Config.GetString("group")
+
 	// API doesn't allow memory setting on own yet, so get get the current size for the mutation
-	currentsize, _, _, err := cmdCtx.Client.API().AppVMResources(ctx, cmdCtx.AppName)
+	appSize, _, processGroups, err := cmdCtx.Client.API().AppVMResources(ctx, cmdCtx.AppName)
 	if err != nil {
 		return err
 	}
 
-	group := cmdCtx.Config.GetString("group")
+	currentsize, err := groupVMSize(appSize, processGroups, group)
+	if err != nil {
+		return err
+	}
 
 	size, err := cmdCtx.Client.API().SetAppVMSize(ctx, cmdCtx.AppName, group, currentsize.Name, memoryMB)
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("Scaled VM Memory size to", formatMemory(size))
+	if group == "" {
+		fmt.Println("Scaled VM Memory size to", formatMemory(size))
+	} else {
+		fmt.Printf("Scaled VM Memory size for \"%s\" to %s\n", group, formatMemory(size))
+	}
 	fmt.Printf("%15s: %s\n", "CPU Cores", formatCores(size))
 	fmt.Printf("%15s: %s\n", "Memory", formatMemory(size))
 
 	return nil
 }
 
+func runScaleCPU(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+
+	cores, err := strconv.ParseFloat(cmdCtx.Args[0], 32)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid number of CPU cores", cmdCtx.Args[0])
+	}
+
+	group := cmdCtx.Config.GetString("group")
+
+	appSize, _, processGroups, err := cmdCtx.Client.API().AppVMResources(ctx, cmdCtx.AppName)
+	if err != nil {
+		return err
+	}
+
+	currentsize, err := groupVMSize(appSize, processGroups, group)
+	if err != nil {
+		return err
+	}
+
+	sizes, err := cmdCtx.Client.API().PlatformVMSizes(ctx)
+	if err != nil {
+		return err
+	}
+
+	target, err := vmSizeWithCores(sizes, currentsize, float32(cores))
+	if err != nil {
+		return err
+	}
+
+	// keep the current memory, unless the new size comes with more
+	var memoryMB int64
+	if currentsize.MemoryMB > target.MemoryMB {
+		memoryMB = int64(currentsize.MemoryMB)
+	}
+
+	size, err := cmdCtx.Client.API().SetAppVMSize(ctx, cmdCtx.AppName, group, target.Name, memoryMB)
+	if err != nil {
+		return err
+	}
+
+	if group == "" {
+		fmt.Println("Scaled VM Type to", size.Name)
+	} else {
+		fmt.Printf("Scaled VM Type for \"%s\" to %s\n", group, size.Name)
+	}
+	fmt.Printf("%15s: %s\n", "CPU Cores", formatCores(size))
+	fmt.Printf("%15s: %s\n", "Memory", formatMemory(size))
+
+	return nil
+}
+
+// groupVMSize returns the VM size of the given process group, falling back to
+// the size of the app for groups which haven't been sized on their own.
+func groupVMSize(appSize api.VMSize, groups []api.ProcessGroup, group string) (api.VMSize, error) {
+	if group == "" {
+		return appSize, nil
+	}
+
+	for _, pg := range groups {
+		if pg.Name != group {
+			continue
+		}
+
+		if pg.VMSize != nil {
+			return *pg.VMSize, nil
+		}
+
+		return appSize, nil
+	}
+
+	names := make([]string, 0, len(groups))
+	for _, pg := range groups {
+		names = append(names, pg.Name)
+	}
+
+	return api.VMSize{}, fmt.Errorf("process group %q not found; the app has %s", group, strings.Join(names, ", "))
+}
+
+// vmSizeWithCores returns the VM size of the class of current (shared-cpu,
+// dedicated-cpu, ...) which has the given number of cores.
+func vmSizeWithCores(sizes []api.VMSize, current api.VMSize, cores float32) (api.VMSize, error) {
+	class := vmSizeClass(current.Name)
+
+	var available []string
+	for _, size := range sizes {
+		if vmSizeClass(size.Name) != class {
+			continue
+		}
+
+		if size.CPUCores == cores {
+			return size, nil
+		}
+
+		available = append(available, formatCores(size))
+	}
+
+	return api.VMSize{}, fmt.Errorf("no %s VM size has %s CPU cores; available are %s",
+		class, strconv.FormatFloat(float64(cores), 'f', -1, 32), strings.Join(available, ", "))
+}
+
+// vmSizeClass returns the class of the named VM size, i.e. its name without
+// its multiplier suffix.
+func vmSizeClass(name string) string {
+	if i := strings.LastIndex(name, "-"); i > 0 {
+		return name[:i]
+	}
+
+	return name
+}
+
 // TODO: Move these funcs (also in presenters.VMSizes into presentation package)
 func formatCores(size api.VMSize) string {
 	if size.CPUCores < 1.0 {
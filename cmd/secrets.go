@@ -3,13 +3,14 @@ package cmd
 import (
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"os"
-	"strings"
 
 	"github.com/superfly/flyctl/cmdctx"
 	"github.com/superfly/flyctl/internal/client"
 	"github.com/superfly/flyctl/internal/cmdutil"
+	stagedsecrets "github.com/superfly/flyctl/internal/secrets"
+	secretsprovider "github.com/superfly/flyctl/internal/secrets/provider"
 
 	"github.com/superfly/flyctl/docstrings"
 
@@ -39,13 +40,46 @@ func newSecretsCommand(client *client.Client) *Command {
 		Name:        "detach",
 		Description: "Return immediately instead of monitoring deployment progress",
 	})
+	set.AddBoolFlag(BoolFlagOpts{
+		Name:        "stage",
+		Description: "Stage the secrets locally and apply them with the next deploy instead of restarting the app now",
+	})
 
 	secretsImportStrings := docstrings.Get("secrets.import")
 	importCmd := BuildCommandKS(cmd, runImportSecrets, secretsImportStrings, client, requireSession, requireAppName)
+	importCmd.Command.Example = `flyctl secrets import .env
+	cat .env | flyctl secrets import
+	flyctl secrets import --stage .env.production
+	`
+	importCmd.Command.Args = cobra.MaximumNArgs(1)
 	importCmd.AddBoolFlag(BoolFlagOpts{
 		Name:        "detach",
 		Description: "Return immediately instead of monitoring deployment progress",
 	})
+	importCmd.AddBoolFlag(BoolFlagOpts{
+		Name:        "stage",
+		Description: "Stage the secrets locally and apply them with the next deploy instead of restarting the app now",
+	})
+
+	secretsSyncStrings := docstrings.Get("secrets.sync")
+	sync := BuildCommandKS(cmd, runSyncSecrets, secretsSyncStrings, client, requireSession, requireAppName)
+	sync.Command.Example = `flyctl secrets sync --from vault://secret/myapp
+	flyctl secrets sync --from aws://prod/myapp?region=us-east-1
+	flyctl secrets sync --from gcp://my-project/db-password?key=DATABASE_PASSWORD --stage
+	`
+	sync.Command.Args = cobra.NoArgs
+	sync.AddStringArrayFlag(StringArrayFlagOpts{
+		Name:        "from",
+		Description: "Secrets manager source to sync secrets from, as vault://<mount>/<path>, aws://<secret> or gcp://<project>/<secret>. Can be specified multiple times.",
+	})
+	sync.AddBoolFlag(BoolFlagOpts{
+		Name:        "detach",
+		Description: "Return immediately instead of monitoring deployment progress",
+	})
+	sync.AddBoolFlag(BoolFlagOpts{
+		Name:        "stage",
+		Description: "Stage the secrets locally and apply them with the next deploy instead of restarting the app now",
+	})
 
 	secretsUnsetStrings := docstrings.Get("secrets.unset")
 	unset := BuildCommandKS(cmd, runSecretsUnset, secretsUnsetStrings, client, requireSession, requireAppName)
@@ -55,6 +89,17 @@ func newSecretsCommand(client *client.Client) *Command {
 		Name:        "detach",
 		Description: "Return immediately instead of monitoring deployment progress",
 	})
+	unset.AddBoolFlag(BoolFlagOpts{
+		Name:        "stage",
+		Description: "Stage the removal locally and apply it with the next deploy instead of restarting the app now",
+	})
+
+	secretsStagedStrings := docstrings.Get("secrets.staged")
+	staged := BuildCommandKS(cmd, runStagedSecrets, secretsStagedStrings, client, requireAppName)
+	staged.AddBoolFlag(BoolFlagOpts{
+		Name:        "discard",
+		Description: "Discard the staged secrets instead of listing them",
+	})
 
 	return cmd
 }
@@ -100,6 +145,10 @@ func runSetSecrets(cc *cmdctx.CmdContext) error {
 		return errors.New("requires at least one SECRET=VALUE pair")
 	}
 
+	if cc.Config.GetBool("stage") {
+		return stageSecrets(cc, secrets)
+	}
+
 	release, err := cc.Client.API().SetSecrets(ctx, cc.AppName, secrets)
 	if err != nil {
 		return err
@@ -118,68 +167,85 @@ func runSetSecrets(cc *cmdctx.CmdContext) error {
 func runImportSecrets(cc *cmdctx.CmdContext) error {
 	ctx := cc.Command.Context()
 
-	app, err := cc.Client.API().GetApp(ctx, cc.AppName)
+	var (
+		in   io.Reader = os.Stdin
+		name           = "stdin"
+	)
+	if len(cc.Args) > 0 && cc.Args[0] != "-" {
+		f, err := os.Open(cc.Args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		in, name = f, cc.Args[0]
+	} else if !helpers.HasPipedStdin() {
+		return errors.New("requires a file to import or NAME=VALUE pairs via stdin")
+	}
+
+	secrets, err := cmdutil.ParseDotenv(in)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed parsing secrets from %s: %w", name, err)
 	}
 
-	secrets := make(map[string]string)
+	if len(secrets) < 1 {
+		return errors.New("requires at least one SECRET=VALUE pair")
+	}
 
-	secretsString, err := ioutil.ReadAll(os.Stdin)
+	if cc.Config.GetBool("stage") {
+		return stageSecrets(cc, secrets)
+	}
 
+	app, err := cc.Client.API().GetApp(ctx, cc.AppName)
 	if err != nil {
 		return err
 	}
 
-	secretsArray := strings.Split(string(secretsString), "\n")
-
-	parsestate := 0
-	parsedkey := ""
-	var parsebuffer strings.Builder
-
-	for _, line := range secretsArray {
-		switch parsestate {
-		case 0:
-			if line != "" {
-				parts := strings.SplitN(line, "=", 2)
-				if strings.HasPrefix(parts[1], "\"\"\"") {
-					// Switch to multiline
-					parsestate = 1
-					parsedkey = parts[0]
-					parsebuffer.WriteString(strings.TrimPrefix(parts[1], "\"\"\""))
-					parsebuffer.WriteString("\n")
-				} else {
-					if len(parts) != 2 {
-						return fmt.Errorf("Secrets must be provided as NAME=VALUE pairs (%s is invalid)", line)
-					}
-					key := parts[0]
-					value := parts[1]
-					secrets[key] = value
-				}
-			}
-		case 1:
-			if strings.HasSuffix(line, "\"\"\"") {
-				// End of multiline
-				parsebuffer.WriteString(strings.TrimSuffix(line, "\"\"\""))
-				secrets[parsedkey] = parsebuffer.String()
-				parsebuffer.Reset()
-				parsestate = 0
-				parsedkey = ""
-			} else {
-				if line != "" {
-					parsebuffer.WriteString(line)
-				}
-				parsebuffer.WriteString("\n")
-			}
+	// all secrets are set via a single call and thus a single release
+	release, err := cc.Client.API().SetSecrets(ctx, cc.AppName, secrets)
+	if err != nil {
+		return err
+	}
 
-		}
+	if !app.Deployed {
+		cc.Statusf("secrets", cmdctx.SINFO, "Secrets are staged for the first deployment\n")
+		return nil
+	}
+
+	cc.Statusf("secrets", cmdctx.SINFO, "%d secrets set; release v%d created\n", len(secrets), release.Version)
+
+	if cc.Config.GetBool("detach") {
+		return nil
+	}
+
+	return watchDeployment(ctx, cc, release.EvaluationID)
+}
+
+func runSyncSecrets(cc *cmdctx.CmdContext) error {
+	ctx := cc.Command.Context()
 
+	sources := cc.Config.GetStringSlice("from")
+	if len(sources) == 0 {
+		return errors.New("requires at least one --from source")
+	}
+
+	secrets, err := secretsprovider.FetchAll(ctx, sources)
+	if err != nil {
+		return err
 	}
 
 	if len(secrets) < 1 {
-		return errors.New("requires at least one SECRET=VALUE pair")
+		return errors.New("the given sources define no secrets")
+	}
+
+	if cc.Config.GetBool("stage") {
+		return stageSecrets(cc, secrets)
+	}
+
+	app, err := cc.Client.API().GetApp(ctx, cc.AppName)
+	if err != nil {
+		return err
 	}
-	fmt.Println(secrets)
 
 	release, err := cc.Client.API().SetSecrets(ctx, cc.AppName, secrets)
 	if err != nil {
@@ -191,7 +257,11 @@ func runImportSecrets(cc *cmdctx.CmdContext) error {
 		return nil
 	}
 
-	cc.Statusf("secrets", cmdctx.SINFO, "Release v%d created\n", release.Version)
+	cc.Statusf("secrets", cmdctx.SINFO, "%d secrets synced; release v%d created\n", len(secrets), release.Version)
+
+	if cc.Config.GetBool("detach") {
+		return nil
+	}
 
 	return watchDeployment(ctx, cc, release.EvaluationID)
 }
@@ -208,6 +278,16 @@ func runSecretsUnset(cc *cmdctx.CmdContext) error {
 		return errors.New("Requires at least one secret name")
 	}
 
+	if cc.Config.GetBool("stage") {
+		if err := stagedsecrets.StageUnset(cc.AppName, cc.Args); err != nil {
+			return fmt.Errorf("failed staging secrets: %w", err)
+		}
+
+		cc.Statusf("secrets", cmdctx.SINFO, "Removal of %d secrets staged for the next deployment of %s\n", len(cc.Args), cc.AppName)
+
+		return nil
+	}
+
 	release, err := cc.Client.API().UnsetSecrets(ctx, cc.AppName, cc.Args)
 	if err != nil {
 		return err
@@ -222,3 +302,54 @@ func runSecretsUnset(cc *cmdctx.CmdContext) error {
 
 	return watchDeployment(ctx, cc, release.EvaluationID)
 }
+
+func stageSecrets(cc *cmdctx.CmdContext, secrets map[string]string) error {
+	if err := stagedsecrets.Stage(cc.AppName, secrets); err != nil {
+		return fmt.Errorf("failed staging secrets: %w", err)
+	}
+
+	cc.Statusf("secrets", cmdctx.SINFO, "%d secrets staged for the next deployment of %s\n", len(secrets), cc.AppName)
+
+	return nil
+}
+
+func runStagedSecrets(cc *cmdctx.CmdContext) error {
+	if cc.Config.GetBool("discard") {
+		if err := stagedsecrets.ClearStaged(cc.AppName); err != nil {
+			return fmt.Errorf("failed discarding staged secrets: %w", err)
+		}
+
+		cc.Statusf("secrets", cmdctx.SINFO, "Discarded the staged secrets of %s\n", cc.AppName)
+
+		return nil
+	}
+
+	staged, err := stagedsecrets.LoadStaged(cc.AppName)
+	if err != nil {
+		return fmt.Errorf("failed loading staged secrets: %w", err)
+	}
+
+	if cc.OutputJSON() {
+		cc.WriteJSON(map[string][]string{
+			"set":   staged.Names(),
+			"unset": staged.Unset,
+		})
+
+		return nil
+	}
+
+	if staged.IsEmpty() {
+		fmt.Fprintf(cc.Out, "No secrets are staged for %s\n", cc.AppName)
+
+		return nil
+	}
+
+	for _, name := range staged.Names() {
+		fmt.Fprintf(cc.Out, "set   %s\n", name)
+	}
+	for _, name := range staged.Unset {
+		fmt.Fprintf(cc.Out, "unset %s\n", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/internal/client"
+)
+
+func TestSecretsSyncFromKeepsCommas(t *testing.T) {
+	root := NewRootCmd(client.New())
+
+	cmd, _, err := root.Find([]string{"secrets", "sync"})
+	require.NoError(t, err)
+
+	sources := []string{"gcp://proj/creds?key=A,B", "vault://secret/app"}
+	require.NoError(t, cmd.ParseFlags([]string{"--from", sources[0], "--from", sources[1]}))
+
+	// runSyncSecrets reads the sources through viper
+	assert.Equal(t, sources, viper.GetStringSlice(namespace(cmd)+".from"))
+}
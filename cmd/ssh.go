@@ -42,6 +42,11 @@ func newSSHCommand(client *client.Client) *Command {
 		requireSession,
 		requireAppName)
 	console.Args = cobra.MaximumNArgs(1)
+	console.Example = `flyctl ssh console -a $APP
+flyctl ssh console --select -a $APP
+flyctl ssh console -C "ls -la /data" -a $APP
+flyctl ssh console -N -L 5432:localhost:5432 -a $APP
+flyctl ssh console -R 8080:localhost:3000 -a $APP`
 
 	console.AddStringFlag(StringFlagOpts{
 		Name:        "command",
@@ -70,6 +75,105 @@ func newSSHCommand(client *client.Client) *Command {
 		Description: "Region to create WireGuard connection in",
 	})
 
+	console.AddStringSliceFlag(StringSliceFlagOpts{
+		Name:        "local-forward",
+		Shorthand:   "L",
+		Description: "Forward a local port to the instance, as [bind_address:]port:host:hostport",
+	})
+
+	console.AddStringSliceFlag(StringSliceFlagOpts{
+		Name:        "remote-forward",
+		Shorthand:   "R",
+		Description: "Forward a port of the instance to the local machine, as [bind_address:]port:host:hostport",
+	})
+
+	console.AddBoolFlag(BoolFlagOpts{
+		Name:        "no-shell",
+		Shorthand:   "N",
+		Default:     false,
+		Description: "Forward ports without starting a shell",
+	})
+
+	sftpCmd := BuildCommandKS(cmd,
+		runSSHSFTP,
+		docstrings.Get("ssh.sftp"),
+		client,
+		requireSession,
+		requireAppName)
+	sftpCmd.Args = cobra.MaximumNArgs(1)
+
+	sftpCmd.AddBoolFlag(BoolFlagOpts{
+		Name:        "select",
+		Shorthand:   "s",
+		Default:     false,
+		Description: "select available instances",
+	})
+
+	cpCmd := BuildCommandKS(cmd,
+		runSSHCopy,
+		docstrings.Get("ssh.cp"),
+		client,
+		requireSession,
+		requireAppName)
+	cpCmd.Args = cobra.ExactArgs(2)
+	cpCmd.Example = `flyctl ssh cp ./seed.sql :/data/seed.sql -a $APP
+flyctl ssh cp -r :/data/uploads ./uploads -a $APP`
+
+	cpCmd.AddBoolFlag(BoolFlagOpts{
+		Name:        "select",
+		Shorthand:   "s",
+		Default:     false,
+		Description: "select available instances",
+	})
+
+	cpCmd.AddBoolFlag(BoolFlagOpts{
+		Name:        "recursive",
+		Shorthand:   "r",
+		Default:     false,
+		Description: "copy directories recursively",
+	})
+
+	execCmd := BuildCommandKS(cmd,
+		runSSHExec,
+		docstrings.Get("ssh.exec"),
+		client,
+		requireSession,
+		requireAppName)
+	execCmd.Args = cobra.MinimumNArgs(1)
+	execCmd.Example = `flyctl ssh exec "df -h /data" -a $APP
+flyctl ssh exec --all "rm -rf /tmp/cache/*" -a $APP
+flyctl ssh exec --all --region ord --process-group worker "uptime" -a $APP`
+
+	execCmd.AddBoolFlag(BoolFlagOpts{
+		Name:        "all",
+		Default:     false,
+		Description: "run the command on every running instance",
+	})
+
+	execCmd.AddBoolFlag(BoolFlagOpts{
+		Name:        "select",
+		Shorthand:   "s",
+		Default:     false,
+		Description: "select available instances",
+	})
+
+	execCmd.AddStringSliceFlag(StringSliceFlagOpts{
+		Name:        "region",
+		Shorthand:   "r",
+		Description: "with --all, only run on instances in these regions",
+	})
+
+	execCmd.AddStringFlag(StringFlagOpts{
+		Name:        "process-group",
+		Description: "with --all, only run on instances of this process group",
+	})
+
+	execCmd.AddIntFlag(IntFlagOpts{
+		Name:        "concurrency",
+		Default:     10,
+		Description: "with --all, the number of instances to run on at once",
+	})
+
 	issue := child(cmd, runSSHIssue, "ssh.issue")
 	issue.Args = cobra.MaximumNArgs(3)
 
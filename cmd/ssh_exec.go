@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/terminal"
+)
+
+func runSSHExec(cc *cmdctx.CmdContext) error {
+	client := cc.Client.API()
+	ctx := cc.Command.Context()
+	command := strings.Join(cc.Args, " ")
+
+	all := cc.Config.GetBool("all")
+	regions := cc.Config.GetStringSlice("region")
+	group := cc.Config.GetString("process-group")
+
+	if !all && (len(regions) > 0 || group != "") {
+		return errors.New("--region and --process-group require --all")
+	}
+
+	if all && cc.Config.GetBool("select") {
+		return errors.New("--all and --select are mutually exclusive")
+	}
+
+	terminal.Debugf("Retrieving app info for %s\n", cc.AppName)
+
+	app, err := client.GetApp(ctx, cc.AppName)
+	if err != nil {
+		return fmt.Errorf("get app: %w", err)
+	}
+
+	agentclient, dialer, err := connectTunnel(cc, app)
+	if err != nil {
+		if all {
+			return err
+		}
+
+		return sshFallback(cc, app, err, "", command)
+	}
+
+	params := &SSHParams{
+		Ctx:            cc,
+		Org:            &app.Organization,
+		Dialer:         dialer,
+		App:            app.Name,
+		DisableSpinner: all,
+	}
+
+	if !all {
+		addr, err := sshAddress(cc, app, agentclient, dialer, "")
+		if err != nil {
+			return err
+		}
+
+		sshClient, err := sshDial(params, addr)
+		if err != nil {
+			return err
+		}
+		defer sshClient.Close()
+
+		return exitStatusError(sshClient.Run(ctx, command, os.Stdout, os.Stderr))
+	}
+
+	status, err := client.GetAppStatus(ctx, app.Name, false)
+	if err != nil {
+		return fmt.Errorf("get app status: %w", err)
+	}
+
+	allocs := execTargets(status.Allocations, regions, group)
+	if len(allocs) == 0 {
+		return errors.New("no running instances match")
+	}
+
+	creds, err := issueSSHCredentials(params)
+	if err != nil {
+		return err
+	}
+
+	concurrency := cc.Config.GetInt("concurrency")
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, concurrency)
+		failures = make([]error, len(allocs))
+	)
+
+	for i, alloc := range allocs {
+		i, alloc := i, alloc
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			prefix := fmt.Sprintf("[%s %s] ", alloc.IDShort, alloc.Region)
+			stdout := &linePrefixer{mu: &mu, w: os.Stdout, prefix: prefix}
+			stderr := &linePrefixer{mu: &mu, w: os.Stderr, prefix: prefix}
+
+			failures[i] = execOn(params, alloc, creds, command, stdout, stderr)
+
+			stdout.Flush()
+			stderr.Flush()
+
+			if failures[i] != nil {
+				mu.Lock()
+				fmt.Fprintf(os.Stderr, "%s%v\n", prefix, failures[i])
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	var failed int
+	for _, err := range failures {
+		if err != nil {
+			failed++
+		}
+	}
+
+	fmt.Fprintf(cc.IO.ErrOut, "Ran on %d instances: %d succeeded, %d failed\n", len(allocs), len(allocs)-failed, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("command failed on %d of %d instances", failed, len(allocs))
+	}
+
+	return nil
+}
+
+// execTargets returns the running allocations in regions and group, when
+// given, ordered by region.
+func execTargets(allocs []*api.AllocationStatus, regions []string, group string) (targets []*api.AllocationStatus) {
+	inRegion := func(region string) bool {
+		if len(regions) == 0 {
+			return true
+		}
+
+		for _, r := range regions {
+			if r == region {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, alloc := range allocs {
+		if alloc.Status != "running" || alloc.PrivateIP == "" {
+			continue
+		}
+
+		if !inRegion(alloc.Region) || (group != "" && alloc.TaskName != group) {
+			continue
+		}
+
+		targets = append(targets, alloc)
+	}
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].Region < targets[j].Region
+	})
+
+	return
+}
+
+func execOn(p *SSHParams, alloc *api.AllocationStatus, creds *sshCredentials, command string, stdout, stderr io.Writer) error {
+	sshClient, err := sshDialWith(p, fmt.Sprintf("[%s]", alloc.PrivateIP), creds)
+	if err != nil {
+		return err
+	}
+	defer sshClient.Close()
+
+	return exitStatusError(sshClient.Run(p.Ctx.Command.Context(), command, stdout, stderr))
+}
+
+// exitStatusError describes the exit status of commands which have failed
+// to run successfully. flyctl exits with the status of such commands.
+func exitStatusError(err error) error {
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return &remoteExitError{status: exitErr.ExitStatus()}
+	}
+
+	return err
+}
+
+// remoteExitError is the error of remote commands which exited with a non-zero
+// status.
+type remoteExitError struct {
+	status int
+}
+
+func (e *remoteExitError) Error() string {
+	return fmt.Sprintf("command exited with status %d", e.status)
+}
+
+func (e *remoteExitError) ExitCode() int {
+	return e.status
+}
+
+// linePrefixer prefixes each line written to w. Lines are written whole, so
+// that the output of several linePrefixers sharing mu doesn't interleave.
+type linePrefixer struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *linePrefixer) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		p.writeLine(p.buf[:i+1])
+		p.buf = p.buf[i+1:]
+	}
+
+	return len(b), nil
+}
+
+// Flush writes out the last line, in case it isn't terminated.
+func (p *linePrefixer) Flush() {
+	if len(p.buf) > 0 {
+		p.writeLine(append(p.buf, '\n'))
+		p.buf = nil
+	}
+}
+
+func (p *linePrefixer) writeLine(line []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, _ = io.WriteString(p.w, p.prefix)
+	_, _ = p.w.Write(line)
+}
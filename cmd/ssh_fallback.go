@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/pkg/errors"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/flyctl"
+	"github.com/superfly/flyctl/pkg/agent"
+	"github.com/superfly/flyctl/pkg/machines"
+	"github.com/superfly/flyctl/terminal"
+)
+
+// connectTunnel establishes the WireGuard tunnel to the organization of app.
+func connectTunnel(cc *cmdctx.CmdContext, app *api.App) (*agent.Client, agent.Dialer, error) {
+	ctx := cc.Command.Context()
+
+	agentclient, err := agent.Establish(ctx, cc.Client.API())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "can't establish agent")
+	}
+
+	dialer, err := agentclient.Dialer(ctx, app.Organization.Slug)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ssh: can't build tunnel for %s: %s", app.Organization.Slug, err)
+	}
+
+	cc.IO.StartProgressIndicatorMsg("Connecting to tunnel")
+	defer cc.IO.StopProgressIndicator()
+
+	if err := agentclient.WaitForTunnel(ctx, app.Organization.Slug); err != nil {
+		return nil, nil, errors.Wrapf(err, "tunnel unavailable")
+	}
+
+	return agentclient, dialer, nil
+}
+
+// sshFallback runs command, or a console in case command is empty, on a
+// machine of app over HTTPS rather than SSH, for networks through which the
+// tunnel fails to establish with the given cause. host, when given, selects
+// the machine by its ID, region or address.
+//
+// The fallback has no terminal, so it's only good for running commands; it
+// returns cause for apps which don't run on machines.
+func sshFallback(cc *cmdctx.CmdContext, app *api.App, cause error, host, command string) error {
+	ctx := cc.Command.Context()
+
+	if os.Getenv("FLY_SSH_NO_HTTPS_FALLBACK") != "" {
+		return cause
+	}
+
+	all, err := cc.Client.API().ListMachines(ctx, app.Name, "started")
+	if err != nil {
+		terminal.Debugf("Failed listing machines for the HTTPS fallback: %v\n", err)
+
+		return cause
+	}
+
+	machine, err := fallbackMachine(all, host, cc.Config.GetBool("select"))
+	if err != nil {
+		return err
+	} else if machine == nil {
+		return cause
+	}
+
+	terminal.Warnf("WireGuard tunnel unavailable (%v); running commands on machine %s over HTTPS instead.\n"+
+		"There's no terminal, so interactive programs, port forwarding and file transfers don't work.\n", cause, machine.ID)
+
+	execClient := machines.NewExecClient(flyctl.GetAPIToken())
+
+	if command == "" {
+		return execClient.Console(ctx, app.Name, machine.ID, os.Stdin, os.Stdout, os.Stderr)
+	}
+
+	res, err := execClient.Exec(ctx, app.Name, machine.ID, []string{"sh", "-c", command}, machines.DefaultExecTimeout)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(os.Stdout, res.Stdout)
+	fmt.Fprint(os.Stderr, res.Stderr)
+
+	if res.ExitCode != 0 {
+		return &remoteExitError{status: res.ExitCode}
+	}
+
+	return nil
+}
+
+// fallbackMachine returns the started machine host denotes, by its ID, region
+// or address, the one the user selects when sel is set or the first one. It
+// returns nil in case there's none.
+func fallbackMachine(all []*api.Machine, host string, sel bool) (*api.Machine, error) {
+	var started []*api.Machine
+	for _, m := range all {
+		if m.State == "started" {
+			started = append(started, m)
+		}
+	}
+
+	if len(started) == 0 {
+		return nil, nil
+	}
+
+	host = strings.Trim(host, "[]")
+
+	switch {
+	case host != "":
+		for _, m := range started {
+			if m.ID == host || m.Region == host || strings.HasPrefix(host, m.Region+".") {
+				return m, nil
+			}
+
+			for _, ip := range m.IPs.Nodes {
+				if ip.IP == host {
+					return m, nil
+				}
+			}
+		}
+
+		return nil, fmt.Errorf("no started machine matches %s", host)
+	case sel:
+		labels := make([]string, len(started))
+		for i, m := range started {
+			labels[i] = fmt.Sprintf("%s (%s)", m.ID, m.Region)
+		}
+
+		selected := 0
+		prompt := &survey.Select{
+			Message:  "Select machine:",
+			Options:  labels,
+			PageSize: 15,
+		}
+
+		if err := survey.AskOne(prompt, &selected); err != nil {
+			return nil, fmt.Errorf("selecting machine: %w", err)
+		}
+
+		return started[selected], nil
+	default:
+		return started[0], nil
+	}
+}
@@ -0,0 +1,488 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/shlex"
+	"github.com/pkg/errors"
+	"github.com/pkg/sftp"
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/helpers"
+	"github.com/superfly/flyctl/pkg/agent"
+	"github.com/superfly/flyctl/terminal"
+)
+
+// openSFTP connects to an instance of the current app, as selected by
+// sshAddress, and starts an sftp session on it. The returned func closes the
+// session and the connection.
+func openSFTP(cc *cmdctx.CmdContext, host string) (*sftp.Client, func(), error) {
+	client := cc.Client.API()
+	ctx := cc.Command.Context()
+
+	terminal.Debugf("Retrieving app info for %s\n", cc.AppName)
+
+	app, err := client.GetApp(ctx, cc.AppName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get app: %w", err)
+	}
+
+	agentclient, err := agent.Establish(ctx, client)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "can't establish agent")
+	}
+
+	dialer, err := agentclient.Dialer(ctx, app.Organization.Slug)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ssh: can't build tunnel for %s: %s\n", app.Organization.Slug, err)
+	}
+
+	cc.IO.StartProgressIndicatorMsg("Connecting to tunnel")
+	if err := agentclient.WaitForTunnel(ctx, app.Organization.Slug); err != nil {
+		return nil, nil, errors.Wrapf(err, "tunnel unavailable")
+	}
+	cc.IO.StopProgressIndicator()
+
+	addr, err := sshAddress(cc, app, agentclient, dialer, host)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sshClient, err := sshDial(&SSHParams{
+		Ctx:    cc,
+		Org:    &app.Organization,
+		Dialer: dialer,
+		App:    cc.AppName,
+	}, addr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sftpClient, err := sshClient.SFTP(ctx)
+	if err != nil {
+		sshClient.Close()
+
+		return nil, nil, err
+	}
+
+	return sftpClient, func() {
+		sftpClient.Close()
+		sshClient.Close()
+	}, nil
+}
+
+func runSSHCopy(cc *cmdctx.CmdContext) error {
+	src, dst := cc.Args[0], cc.Args[1]
+	recursive := cc.Config.GetBool("recursive")
+
+	remoteSrc, srcIsRemote := remotePath(src)
+	remoteDst, dstIsRemote := remotePath(dst)
+
+	if srcIsRemote == dstIsRemote {
+		return errors.New("exactly one of the paths must be remote; prefix remote paths with a colon, like :/data/file")
+	}
+
+	c, closeFn, err := openSFTP(cc, "")
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	if srcIsRemote {
+		return sftpDownload(c, remoteSrc, dst, recursive, cc.Out)
+	}
+
+	return sftpUpload(c, src, remoteDst, recursive, cc.Out)
+}
+
+// remotePath reports whether the given path of ssh cp refers to the remote
+// instance, which colon prefixes denote, and strips the prefix.
+func remotePath(p string) (string, bool) {
+	if !strings.HasPrefix(p, ":") {
+		return p, false
+	}
+
+	if p = p[1:]; p == "" {
+		p = "."
+	}
+
+	return p, true
+}
+
+// sftpUpload copies the local src to the remote dst. Like cp, it copies into
+// dst in case dst is an existing directory.
+func sftpUpload(c *sftp.Client, src, dst string, recursive bool, out io.Writer) error {
+	fi, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if fi.IsDir() && !recursive {
+		return fmt.Errorf("%s is a directory; use --recursive to copy directories", src)
+	}
+
+	if rfi, err := c.Stat(dst); err == nil && rfi.IsDir() {
+		dst = path.Join(dst, filepath.Base(src))
+	}
+
+	return uploadTree(c, src, dst, fi, out)
+}
+
+func uploadTree(c *sftp.Client, src, dst string, fi os.FileInfo, out io.Writer) error {
+	switch {
+	case fi.Mode().IsRegular():
+		return uploadFile(c, src, dst, fi, out)
+	case !fi.IsDir():
+		fmt.Fprintf(out, "skipping %s: not a regular file or directory\n", src)
+
+		return nil
+	}
+
+	if _, err := c.Stat(dst); errors.Is(err, os.ErrNotExist) {
+		if err := c.Mkdir(dst); err != nil {
+			return fmt.Errorf("failed creating %s: %w", dst, err)
+		}
+
+		if err := c.Chmod(dst, fi.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed creating %s: %w", dst, err)
+		}
+	} else if err != nil {
+		return err
+	}
+
+	entries, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := uploadTree(c, filepath.Join(src, entry.Name()), path.Join(dst, entry.Name()), entry, out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func uploadFile(c *sftp.Client, src, dst string, fi os.FileInfo, out io.Writer) error {
+	local, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer local.Close()
+
+	remote, err := c.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY)
+	if err != nil {
+		return fmt.Errorf("failed creating %s: %w", dst, err)
+	}
+
+	if err := remote.Chmod(fi.Mode().Perm()); err != nil {
+		remote.Close()
+
+		return fmt.Errorf("failed creating %s: %w", dst, err)
+	}
+
+	n, err := io.Copy(remote, local)
+	if cerr := remote.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed uploading %s: %w", src, err)
+	}
+
+	fmt.Fprintf(out, "%s -> %s (%d bytes)\n", src, dst, n)
+
+	return nil
+}
+
+// sftpDownload copies the remote src to the local dst. Like cp, it copies
+// into dst in case dst is an existing directory.
+func sftpDownload(c *sftp.Client, src, dst string, recursive bool, out io.Writer) error {
+	fi, err := c.Stat(src)
+	if err != nil {
+		return fmt.Errorf("failed reading %s: %w", src, err)
+	}
+
+	if fi.IsDir() && !recursive {
+		return fmt.Errorf("%s is a directory; use --recursive to copy directories", src)
+	}
+
+	if lfi, err := os.Stat(dst); err == nil && lfi.IsDir() {
+		dst = filepath.Join(dst, path.Base(src))
+	}
+
+	return downloadTree(c, src, dst, fi, out)
+}
+
+func downloadTree(c *sftp.Client, src, dst string, fi os.FileInfo, out io.Writer) error {
+	switch {
+	case fi.Mode().IsRegular():
+		return downloadFile(c, src, dst, fi, out)
+	case !fi.IsDir():
+		fmt.Fprintf(out, "skipping %s: not a regular file or directory\n", src)
+
+		return nil
+	}
+
+	if err := os.MkdirAll(dst, fi.Mode().Perm()|0700); err != nil {
+		return err
+	}
+
+	entries, err := c.ReadDir(src)
+	if err != nil {
+		return fmt.Errorf("failed listing %s: %w", src, err)
+	}
+
+	for _, entry := range entries {
+		local, err := localEntryPath(dst, entry.Name())
+		if err != nil {
+			return fmt.Errorf("failed listing %s: %w", src, err)
+		}
+
+		if err := downloadTree(c, path.Join(src, entry.Name()), local, entry, out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// localEntryPath returns the local path the remote directory entry of the
+// given name downloads to. Since the name comes from the server, names which
+// could point outside of dir are rejected.
+func localEntryPath(dir, name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/"+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid directory entry %q", name)
+	}
+
+	p := filepath.Join(dir, name)
+	if rel, err := filepath.Rel(dir, p); err != nil || rel != name {
+		return "", fmt.Errorf("invalid directory entry %q", name)
+	}
+
+	return p, nil
+}
+
+func downloadFile(c *sftp.Client, src, dst string, fi os.FileInfo, out io.Writer) error {
+	remote, err := c.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed opening %s: %w", src, err)
+	}
+	defer remote.Close()
+
+	local, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(local, remote)
+	if cerr := local.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed downloading %s: %w", src, err)
+	}
+
+	fmt.Fprintf(out, "%s -> %s (%d bytes)\n", src, dst, n)
+
+	return nil
+}
+
+func runSSHSFTP(cc *cmdctx.CmdContext) error {
+	var host string
+	if len(cc.Args) != 0 {
+		host = cc.Args[0]
+	}
+
+	c, closeFn, err := openSFTP(cc, host)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	wd, err := c.RealPath(".")
+	if err != nil {
+		return err
+	}
+
+	sh := &sftpShell{c: c, wd: wd, out: cc.Out}
+
+	return sh.run(os.Stdin, helpers.IsTerminal())
+}
+
+// sftpShell implements the interactive shell of ssh sftp.
+type sftpShell struct {
+	c   *sftp.Client
+	wd  string
+	out io.Writer
+}
+
+const sftpShellHelp = `Commands:
+  ls [path]                    list a remote directory
+  cd <path>                    change the remote directory
+  pwd                          print the remote directory
+  lcd <path>                   change the local directory
+  lpwd                         print the local directory
+  get [-r] <remote> [local]    download a file, or a directory with -r
+  put [-r] <local> [remote]    upload a file, or a directory with -r
+  mkdir <path>                 create a remote directory
+  rm <path>                    remove a remote file
+  rmdir <path>                 remove an empty remote directory
+  chmod <mode> <path>          change the permissions of a remote file
+  exit                         end the session
+
+Quote paths which contain spaces, e.g. get "my file".
+`
+
+func (sh *sftpShell) run(in io.Reader, interactive bool) error {
+	scanner := bufio.NewScanner(in)
+
+	for {
+		if interactive {
+			fmt.Fprint(sh.out, "sftp> ")
+		}
+
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+
+		// paths may be quoted, so that they may contain spaces
+		args, err := shlex.Split(scanner.Text())
+		if err != nil {
+			fmt.Fprintf(sh.out, "invalid command: %v\n", err)
+
+			continue
+		}
+
+		if len(args) == 0 {
+			continue
+		}
+
+		if args[0] == "exit" || args[0] == "quit" {
+			return nil
+		}
+
+		if err := sh.exec(args[0], args[1:]); err != nil {
+			fmt.Fprintf(sh.out, "%s: %v\n", args[0], err)
+		}
+	}
+}
+
+// resolve returns the remote path p relative to the working directory.
+func (sh *sftpShell) resolve(p string) string {
+	if path.IsAbs(p) {
+		return path.Clean(p)
+	}
+
+	return path.Join(sh.wd, p)
+}
+
+func (sh *sftpShell) exec(cmd string, args []string) (err error) {
+	recursive := len(args) > 0 && args[0] == "-r"
+	if recursive && (cmd == "get" || cmd == "put") {
+		args = args[1:]
+	}
+
+	arg := func(i int) string {
+		if i < len(args) {
+			return args[i]
+		}
+
+		return ""
+	}
+
+	switch cmd {
+	case "help", "?":
+		fmt.Fprint(sh.out, sftpShellHelp)
+	case "pwd":
+		fmt.Fprintln(sh.out, sh.wd)
+	case "lpwd":
+		var wd string
+		if wd, err = os.Getwd(); err == nil {
+			fmt.Fprintln(sh.out, wd)
+		}
+	case "cd":
+		dir := sh.resolve(arg(0))
+
+		var fi os.FileInfo
+		if fi, err = sh.c.Stat(dir); err == nil {
+			if !fi.IsDir() {
+				return fmt.Errorf("%s is not a directory", dir)
+			}
+
+			sh.wd = dir
+		}
+	case "lcd":
+		err = os.Chdir(arg(0))
+	case "ls":
+		err = sh.ls(sh.resolve(arg(0)))
+	case "get":
+		if arg(0) == "" {
+			return errors.New("usage: get [-r] <remote> [local]")
+		}
+
+		local := arg(1)
+		if local == "" {
+			local = "."
+		}
+
+		err = sftpDownload(sh.c, sh.resolve(arg(0)), local, recursive, sh.out)
+	case "put":
+		if arg(0) == "" {
+			return errors.New("usage: put [-r] <local> [remote]")
+		}
+
+		err = sftpUpload(sh.c, arg(0), sh.resolve(arg(1)), recursive, sh.out)
+	case "mkdir":
+		err = sh.c.Mkdir(sh.resolve(arg(0)))
+	case "rm":
+		err = sh.c.Remove(sh.resolve(arg(0)))
+	case "rmdir":
+		err = sh.c.RemoveDirectory(sh.resolve(arg(0)))
+	case "chmod":
+		var mode uint64
+		if mode, err = strconv.ParseUint(arg(0), 8, 32); err != nil {
+			return fmt.Errorf("invalid mode %q", arg(0))
+		}
+
+		err = sh.c.Chmod(sh.resolve(arg(1)), os.FileMode(mode))
+	default:
+		err = fmt.Errorf("unknown command; type help for a list of commands")
+	}
+
+	return
+}
+
+func (sh *sftpShell) ls(dir string) error {
+	fi, err := sh.c.Stat(dir)
+	if err != nil {
+		return err
+	}
+
+	entries := []os.FileInfo{fi}
+	if fi.IsDir() {
+		if entries, err = sh.c.ReadDir(dir); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+
+		fmt.Fprintf(sh.out, "%s %10d %s %s\n", entry.Mode(), entry.Size(), entry.ModTime().Format("Jan _2 15:04"), name)
+	}
+
+	return nil
+}
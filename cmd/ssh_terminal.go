@@ -84,31 +84,164 @@ func runSSHConsole(cc *cmdctx.CmdContext) error {
 		)
 	}
 
-	agentclient, err := agent.Establish(ctx, client)
+	local, remote, err := sshForwards(cc)
+	if err != nil {
+		return err
+	}
+
+	var host string
+	if len(cc.Args) != 0 {
+		host = cc.Args[0]
+	}
+
+	agentclient, dialer, err := connectTunnel(cc, app)
 	if err != nil {
 		captureError(err)
-		return errors.Wrap(err, "can't establish agent")
+
+		if len(local) > 0 || len(remote) > 0 {
+			return err // forwarding requires the tunnel
+		}
+
+		return sshFallback(cc, app, err, host, cc.Config.GetString("command"))
 	}
 
-	dialer, err := agentclient.Dialer(ctx, app.Organization.Slug)
+	addr, err := sshAddress(cc, app, agentclient, dialer, host)
 	if err != nil {
 		captureError(err)
-		return fmt.Errorf("ssh: can't build tunnel for %s: %s\n", app.Organization.Slug, err)
+		return err
+	}
+
+	params := &SSHParams{
+		Ctx:    cc,
+		Org:    &app.Organization,
+		Dialer: dialer,
+		App:    cc.AppName,
+		Cmd:    cc.Config.GetString("command"),
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+
+	if len(local) > 0 || len(remote) > 0 {
+		err = sshForward(params, addr, local, remote, cc.Config.GetBool("no-shell"))
+	} else {
+		err = sshConnect(params, addr)
 	}
 
-	cc.IO.StartProgressIndicatorMsg("Connecting to tunnel")
-	if err := agentclient.WaitForTunnel(ctx, app.Organization.Slug); err != nil {
+	if err != nil {
 		captureError(err)
-		return errors.Wrapf(err, "tunnel unavailable")
 	}
-	cc.IO.StopProgressIndicator()
 
-	var addr string
+	return err
+}
+
+// sshForwards parses the forwards of the -L and -R flags.
+func sshForwards(cc *cmdctx.CmdContext) (local, remote []ssh.Forward, err error) {
+	parse := func(specs []string) (forwards []ssh.Forward, err error) {
+		for _, spec := range specs {
+			f, err := ssh.ParseForward(spec)
+			if err != nil {
+				return nil, err
+			}
+
+			forwards = append(forwards, f)
+		}
+
+		return
+	}
+
+	if local, err = parse(cc.Config.GetStringSlice("local-forward")); err != nil {
+		return
+	}
+
+	if remote, err = parse(cc.Config.GetStringSlice("remote-forward")); err != nil {
+		return
+	}
+
+	if cc.Config.GetBool("no-shell") {
+		switch {
+		case len(local) == 0 && len(remote) == 0:
+			err = errors.New("--no-shell requires at least one of --local-forward or --remote-forward")
+		case cc.Config.GetString("command") != "":
+			err = errors.New("--no-shell and --command are mutually exclusive")
+		}
+	}
+
+	return
+}
+
+// sshForward connects to addr and sets up the forwards. With noShell it
+// holds the connection open until interrupted; otherwise it attaches to a
+// shell, like sshConnect does, and tears the forwards down once it exits.
+func sshForward(p *SSHParams, addr string, local, remote []ssh.Forward, noShell bool) error {
+	sshClient, err := sshDial(p, addr)
+	if err != nil {
+		return err
+	}
+	defer sshClient.Close()
+
+	ctx, cancel := context.WithCancel(p.Ctx.Command.Context())
+	defer cancel()
+
+	errc := make(chan error, len(local)+len(remote))
+
+	for _, f := range local {
+		f := f
+		go func() {
+			if err := sshClient.ForwardLocal(ctx, f); err != nil {
+				errc <- fmt.Errorf("forward %s: %w", f, err)
+			}
+		}()
+
+		fmt.Fprintf(p.Ctx.IO.ErrOut, "Forwarding local %s to remote %s\n", f.Listen, f.Connect)
+	}
+
+	for _, f := range remote {
+		f := f
+		go func() {
+			if err := sshClient.ForwardRemote(ctx, f); err != nil {
+				errc <- fmt.Errorf("forward %s: %w", f, err)
+			}
+		}()
+
+		fmt.Fprintf(p.Ctx.IO.ErrOut, "Forwarding remote %s to local %s\n", f.Listen, f.Connect)
+	}
+
+	if noShell {
+		fmt.Fprintln(p.Ctx.IO.ErrOut, "Press Ctrl-C to stop forwarding")
+
+		select {
+		case err := <-errc:
+			return err
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	term := &ssh.Terminal{
+		Stdin:  p.Stdin,
+		Stdout: p.Stdout,
+		Stderr: p.Stderr,
+		Mode:   "xterm",
+	}
+
+	if err := sshClient.Shell(ctx, term, p.Cmd); err != nil {
+		return errors.Wrap(err, "ssh shell")
+	}
+
+	return nil
+}
+
+// sshAddress returns the address of the instance of app to connect to: the
+// one the user selects with --select, the given host or, by default, the
+// nearest one. It waits for host names to resolve.
+func sshAddress(cc *cmdctx.CmdContext, app *api.App, agentclient *agent.Client, dialer agent.Dialer, host string) (addr string, err error) {
+	ctx := cc.Command.Context()
 
 	if cc.Config.GetBool("select") {
-		instances, err := agentclient.Instances(ctx, &app.Organization, cc.AppName)
+		instances, err := agentclient.Instances(ctx, &app.Organization, app.Name)
 		if err != nil {
-			return fmt.Errorf("look up %s: %w", cc.AppName, err)
+			return "", fmt.Errorf("look up %s: %w", app.Name, err)
 		}
 
 		selected := 0
@@ -119,40 +252,24 @@ func runSSHConsole(cc *cmdctx.CmdContext) error {
 		}
 
 		if err := survey.AskOne(prompt, &selected); err != nil {
-			return fmt.Errorf("selecting instance: %w", err)
+			return "", fmt.Errorf("selecting instance: %w", err)
 		}
 
 		addr = fmt.Sprintf("[%s]", instances.Addresses[selected])
-	} else if len(cc.Args) != 0 {
-		addr = cc.Args[0]
+	} else if host != "" {
+		addr = host
 	} else {
-		addr = fmt.Sprintf("top1.nearest.of.%s.internal", cc.AppName)
+		addr = fmt.Sprintf("top1.nearest.of.%s.internal", app.Name)
 	}
 
 	// wait for the addr to be resolved in dns unless it's an ip address
 	if !ip.IsV6(addr) {
 		if err := agentclient.WaitForDNS(ctx, dialer, app.Organization.Slug, addr); err != nil {
-			captureError(err)
-			return errors.Wrapf(err, "host unavailable")
+			return "", errors.Wrapf(err, "host unavailable")
 		}
 	}
 
-	err = sshConnect(&SSHParams{
-		Ctx:    cc,
-		Org:    &app.Organization,
-		Dialer: dialer,
-		App:    cc.AppName,
-		Cmd:    cc.Config.GetString("command"),
-		Stdin:  os.Stdin,
-		Stdout: os.Stdout,
-		Stderr: os.Stderr,
-	}, addr)
-
-	if err != nil {
-		captureError(err)
-	}
-
-	return err
+	return addr, nil
 }
 
 func spin(in, out string) context.CancelFunc {
@@ -190,20 +307,67 @@ type SSHParams struct {
 }
 
 func sshConnect(p *SSHParams, addr string) error {
-	terminal.Debugf("Fetching certificate for %s\n", addr)
+	sshClient, err := sshDial(p, addr)
+	if err != nil {
+		return err
+	}
+	defer sshClient.Close()
+
+	term := &ssh.Terminal{
+		Stdin:  p.Stdin,
+		Stdout: p.Stdout,
+		Stderr: p.Stderr,
+		Mode:   "xterm",
+	}
+
+	if err := sshClient.Shell(p.Ctx.Command.Context(), term, p.Cmd); err != nil {
+		return errors.Wrap(err, "ssh shell")
+	}
+
+	return nil
+}
+
+// sshDial issues a single-use certificate and connects to the SSH server at
+// addr with it.
+func sshDial(p *SSHParams, addr string) (*ssh.Client, error) {
+	creds, err := issueSSHCredentials(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return sshDialWith(p, addr, creds)
+}
+
+// sshCredentials are a single-use certificate along with its private key.
+type sshCredentials struct {
+	certificate string
+	privateKey  string
+}
+
+func issueSSHCredentials(p *SSHParams) (*sshCredentials, error) {
+	terminal.Debugf("Fetching certificate for %s\n", p.App)
 
 	cert, err := singleUseSSHCertificate(p.Ctx, p.Org)
 	if err != nil {
-		return fmt.Errorf("create ssh certificate: %w (if you haven't created a key for your org yet, try `flyctl ssh establish`)", err)
+		return nil, fmt.Errorf("create ssh certificate: %w (if you haven't created a key for your org yet, try `flyctl ssh establish`)", err)
 	}
 
 	pk, err := parsePrivateKey(cert.Key)
 	if err != nil {
-		return errors.Wrap(err, "parse ssh certificate")
+		return nil, errors.Wrap(err, "parse ssh certificate")
 	}
 
 	pemkey := MarshalED25519PrivateKey(pk, "single-use certificate")
 
+	return &sshCredentials{
+		certificate: cert.Certificate,
+		privateKey:  string(pemkey),
+	}, nil
+}
+
+// sshDialWith connects to the SSH server at addr with creds, which may be
+// used for several connections.
+func sshDialWith(p *SSHParams, addr string, creds *sshCredentials) (*ssh.Client, error) {
 	terminal.Debugf("Keys for %s configured; connecting...\n", addr)
 
 	sshClient := &ssh.Client{
@@ -212,8 +376,8 @@ func sshConnect(p *SSHParams, addr string) error {
 
 		Dial: p.Dialer.DialContext,
 
-		Certificate: cert.Certificate,
-		PrivateKey:  string(pemkey),
+		Certificate: creds.certificate,
+		PrivateKey:  creds.privateKey,
 	}
 
 	var endSpin context.CancelFunc
@@ -223,11 +387,9 @@ func sshConnect(p *SSHParams, addr string) error {
 		defer endSpin()
 	}
 
-	ctx := p.Ctx.Command.Context()
-	if err := sshClient.Connect(ctx); err != nil {
-		return errors.Wrap(err, "error connecting to SSH server")
+	if err := sshClient.Connect(p.Ctx.Command.Context()); err != nil {
+		return nil, errors.Wrap(err, "error connecting to SSH server")
 	}
-	defer sshClient.Close()
 
 	terminal.Debugf("Connection completed.\n", addr)
 
@@ -235,16 +397,5 @@ func sshConnect(p *SSHParams, addr string) error {
 		endSpin()
 	}
 
-	term := &ssh.Terminal{
-		Stdin:  p.Stdin,
-		Stdout: p.Stdout,
-		Stderr: p.Stderr,
-		Mode:   "xterm",
-	}
-
-	if err := sshClient.Shell(ctx, term, p.Cmd); err != nil {
-		return errors.Wrap(err, "ssh shell")
-	}
-
-	return nil
+	return sshClient, nil
 }
@@ -5,11 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"os"
 	"strings"
-	"text/template"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/olekukonko/tablewriter"
@@ -34,6 +32,23 @@ func newWireGuardCommand(client *client.Client) *Command {
 	child(cmd, runWireGuardRemove, "wireguard.remove").Args = cobra.MaximumNArgs(2)
 	child(cmd, runWireGuardStat, "wireguard.status").Args = cobra.MaximumNArgs(2)
 
+	export := child(cmd, runWireGuardExport, "wireguard.export")
+	export.AddStringFlag(StringFlagOpts{
+		Name:        "config",
+		Description: "wg-quick configuration of the peer to convert, as written by wireguard create",
+	})
+
+	rotate := child(cmd, runWireGuardRotate, "wireguard.rotate")
+
+	for _, c := range []*Command{export, rotate} {
+		c.Args = cobra.MaximumNArgs(3)
+		c.AddStringFlag(StringFlagOpts{
+			Name:        "format",
+			Default:     wgFormatQuick,
+			Description: "Format of the configuration: wg-quick, mobileconfig or qr",
+		})
+	}
+
 	tokens := child(cmd, nil, "wireguard.token")
 
 	child(tokens, runWireGuardTokenList, "wireguard.token.list").Args = cobra.MaximumNArgs(1)
@@ -121,46 +136,7 @@ func runWireGuardList(cmdCtx *cmdctx.CmdContext) error {
 }
 
 func generateWgConf(peer *api.CreatedWireGuardPeer, privkey string, w io.Writer) {
-	templateStr := `
-[Interface]
-PrivateKey = {{.Meta.Privkey}}
-Address = {{.Peer.Peerip}}/120
-DNS = {{.Meta.DNS}}
-
-[Peer]
-PublicKey = {{.Peer.Pubkey}}
-AllowedIPs = {{.Meta.AllowedIPs}}
-Endpoint = {{.Peer.Endpointip}}:51820
-PersistentKeepalive = 15
-
-`
-	data := struct {
-		Peer *api.CreatedWireGuardPeer
-		Meta struct {
-			Privkey    string
-			AllowedIPs string
-			DNS        string
-		}
-	}{
-		Peer: peer,
-	}
-
-	addr := net.ParseIP(peer.Peerip).To16()
-	for i := 6; i < 16; i++ {
-		addr[i] = 0
-	}
-
-	// BUG(tqbf): can't stay this way
-	data.Meta.AllowedIPs = fmt.Sprintf("%s/48", addr)
-
-	addr[15] = 3
-
-	data.Meta.DNS = fmt.Sprintf("%s", addr)
-	data.Meta.Privkey = privkey
-
-	tmpl := template.Must(template.New("name").Parse(templateStr))
-
-	tmpl.Execute(w, &data)
+	_ = newWgConfig(peer, privkey).writeWgQuick(w)
 }
 
 func resolveOutputWriter(ctx *cmdctx.CmdContext, idx int, prompt string) (w io.WriteCloser, mustClose bool, err error) {
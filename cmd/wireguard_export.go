@@ -0,0 +1,419 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"text/template"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/cmdctx"
+	"github.com/superfly/flyctl/internal/wireguard"
+	"github.com/superfly/flyctl/pkg/wg"
+)
+
+// the formats WireGuard configurations may be written in
+const (
+	wgFormatQuick        = "wg-quick"
+	wgFormatMobileConfig = "mobileconfig"
+	wgFormatQR           = "qr"
+)
+
+// wgConfig is the configuration of a WireGuard client of a peer.
+type wgConfig struct {
+	PrivateKey string
+	Address    string
+	DNS        string
+	PublicKey  string
+	AllowedIPs string
+	Endpoint   string
+}
+
+func newWgConfig(peer *api.CreatedWireGuardPeer, privkey string) *wgConfig {
+	addr := net.ParseIP(peer.Peerip).To16()
+	for i := 6; i < 16; i++ {
+		addr[i] = 0
+	}
+
+	// BUG(tqbf): can't stay this way
+	allowedIPs := fmt.Sprintf("%s/48", addr)
+
+	addr[15] = 3
+
+	return &wgConfig{
+		PrivateKey: privkey,
+		Address:    peer.Peerip + "/120",
+		DNS:        addr.String(),
+		PublicKey:  peer.Pubkey,
+		AllowedIPs: allowedIPs,
+		Endpoint:   peer.Endpointip + ":51820",
+	}
+}
+
+var wgQuickTemplate = template.Must(template.New("wg-quick").Parse(`
+[Interface]
+PrivateKey = {{.PrivateKey}}
+Address = {{.Address}}
+DNS = {{.DNS}}
+
+[Peer]
+PublicKey = {{.PublicKey}}
+AllowedIPs = {{.AllowedIPs}}
+Endpoint = {{.Endpoint}}
+PersistentKeepalive = 15
+
+`))
+
+func (c *wgConfig) writeWgQuick(w io.Writer) error {
+	return wgQuickTemplate.Execute(w, c)
+}
+
+// parseWgConfig parses the wg-quick configuration r reads, as written by
+// wireguard create.
+func parseWgConfig(r io.Reader) (*wgConfig, error) {
+	var c wgConfig
+
+	fields := map[string]*string{
+		"privatekey": &c.PrivateKey,
+		"address":    &c.Address,
+		"dns":        &c.DNS,
+		"publickey":  &c.PublicKey,
+		"allowedips": &c.AllowedIPs,
+		"endpoint":   &c.Endpoint,
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") {
+			continue
+		}
+
+		i := strings.Index(line, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid line in WireGuard configuration: %q", line)
+		}
+
+		if dst, ok := fields[strings.ToLower(strings.TrimSpace(line[:i]))]; ok {
+			*dst = strings.TrimSpace(line[i+1:])
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if c.PrivateKey == "" || c.PublicKey == "" || c.Endpoint == "" {
+		return nil, fmt.Errorf("WireGuard configuration lacks a private key, public key or endpoint")
+	}
+
+	return &c, nil
+}
+
+var mobileConfigTemplate = template.Must(template.New("mobileconfig").Funcs(template.FuncMap{
+	"xml": func(s string) (string, error) {
+		var b bytes.Buffer
+		err := xml.EscapeText(&b, []byte(s))
+
+		return b.String(), err
+	},
+}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>PayloadDisplayName</key>
+	<string>{{xml .Name}}</string>
+	<key>PayloadType</key>
+	<string>Configuration</string>
+	<key>PayloadVersion</key>
+	<integer>1</integer>
+	<key>PayloadIdentifier</key>
+	<string>io.fly.wireguard.{{xml .Name}}</string>
+	<key>PayloadUUID</key>
+	<string>{{.ProfileUUID}}</string>
+	<key>PayloadContent</key>
+	<array>
+		<dict>
+			<key>PayloadDisplayName</key>
+			<string>VPN</string>
+			<key>PayloadType</key>
+			<string>com.apple.vpn.managed</string>
+			<key>PayloadVersion</key>
+			<integer>1</integer>
+			<key>PayloadIdentifier</key>
+			<string>io.fly.wireguard.{{xml .Name}}.vpn</string>
+			<key>PayloadUUID</key>
+			<string>{{.VPNUUID}}</string>
+			<key>UserDefinedName</key>
+			<string>{{xml .Name}}</string>
+			<key>VPNType</key>
+			<string>VPN</string>
+			<key>VPNSubType</key>
+			<string>com.wireguard.ios</string>
+			<key>VendorConfig</key>
+			<dict>
+				<key>WgQuickConfig</key>
+				<string>{{xml .WgQuick}}</string>
+			</dict>
+			<key>VPN</key>
+			<dict>
+				<key>RemoteAddress</key>
+				<string>{{xml .Endpoint}}</string>
+				<key>AuthenticationMethod</key>
+				<string>Password</string>
+			</dict>
+		</dict>
+	</array>
+</dict>
+</plist>
+`))
+
+// writeMobileConfig writes c as an Apple configuration profile, which the
+// WireGuard apps of iOS and macOS import.
+func (c *wgConfig) writeMobileConfig(w io.Writer, name string) error {
+	var wgQuick bytes.Buffer
+	if err := c.writeWgQuick(&wgQuick); err != nil {
+		return err
+	}
+
+	return mobileConfigTemplate.Execute(w, map[string]string{
+		"Name":        name,
+		"ProfileUUID": newUUID(),
+		"VPNUUID":     newUUID(),
+		"WgQuick":     strings.TrimSpace(wgQuick.String()),
+		"Endpoint":    c.Endpoint,
+	})
+}
+
+// writeQR writes c as a QR code the WireGuard mobile apps scan, as rendered
+// by qrencode.
+func (c *wgConfig) writeQR(w io.Writer) error {
+	path, err := exec.LookPath("qrencode")
+	if err != nil {
+		return fmt.Errorf("rendering QR codes requires qrencode; install it or use --format %s", wgFormatQuick)
+	}
+
+	var wgQuick bytes.Buffer
+	if err := c.writeWgQuick(&wgQuick); err != nil {
+		return err
+	}
+
+	cmd := exec.Command(path, "-t", "ansiutf8")
+	cmd.Stdin = &wgQuick
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+func (c *wgConfig) write(w io.Writer, format, name string) error {
+	switch format {
+	case wgFormatQuick:
+		return c.writeWgQuick(w)
+	case wgFormatMobileConfig:
+		return c.writeMobileConfig(w, name)
+	case wgFormatQR:
+		return c.writeQR(w)
+	default:
+		return fmt.Errorf("unsupported format %q; use %s, %s or %s", format, wgFormatQuick, wgFormatMobileConfig, wgFormatQR)
+	}
+}
+
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("reading from random: %s", err))
+	}
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%X-%X-%X-%X-%X", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// peerByArg returns the name of the peer of org the second argument names,
+// prompting for it in its absence.
+func peerByArg(cmdCtx *cmdctx.CmdContext, org *api.Organization) (string, error) {
+	if len(cmdCtx.Args) >= 2 {
+		return cmdCtx.Args[1], nil
+	}
+
+	return selectWireGuardPeer(cmdCtx.Command.Context(), cmdCtx.Client.API(), org.Slug)
+}
+
+// wgOutput is where the configuration of a peer is written to.
+type wgOutput struct {
+	format  string
+	w       io.Writer
+	file    *os.File
+	written bool
+}
+
+// openWgOutput opens the output of the --format flag: stdout for QR codes,
+// and otherwise the file the nth argument names, prompting for it in its
+// absence.
+func openWgOutput(cmdCtx *cmdctx.CmdContext, nth int) (*wgOutput, error) {
+	o := &wgOutput{format: cmdCtx.Config.GetString("format"), w: cmdCtx.Out}
+
+	if o.format == wgFormatQR {
+		if _, err := exec.LookPath("qrencode"); err != nil {
+			return nil, fmt.Errorf("rendering QR codes requires qrencode; install it or use --format %s", wgFormatQuick)
+		}
+
+		return o, nil
+	}
+
+	w, shouldClose, err := resolveOutputWriter(cmdCtx, nth, "Filename to store WireGuard configuration in, or 'stdout': ")
+	if err != nil {
+		return nil, err
+	}
+
+	o.w = w
+	if shouldClose {
+		o.file = w.(*os.File)
+	}
+
+	return o, nil
+}
+
+// write writes c to the output and closes it.
+func (o *wgOutput) write(cmdCtx *cmdctx.CmdContext, c *wgConfig, name string) error {
+	if o.format == wgFormatQR {
+		err := c.writeQR(o.w)
+		o.written = err == nil
+
+		return err
+	}
+
+	err := c.write(o.w, o.format, name)
+	if o.file == nil {
+		return err
+	}
+
+	if cerr := o.file.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		return err
+	}
+	o.written = true
+
+	fmt.Fprintf(cmdCtx.Out, "Wrote WireGuard configuration to %s; load in your WireGuard client\n", o.file.Name())
+
+	return nil
+}
+
+// discard closes the output and removes the file it created, if any, unless
+// the configuration was written to it.
+func (o *wgOutput) discard() {
+	if o.file != nil && !o.written {
+		o.file.Close()
+		os.Remove(o.file.Name())
+	}
+}
+
+// writeWgConfig writes c in the format of the --format flag to the file the
+// nth argument names, prompting for it in its absence. QR codes go to stdout.
+func writeWgConfig(cmdCtx *cmdctx.CmdContext, c *wgConfig, name string, nth int) error {
+	o, err := openWgOutput(cmdCtx, nth)
+	if err != nil {
+		return err
+	}
+
+	if err := o.write(cmdCtx, c, name); err != nil {
+		o.discard()
+
+		return err
+	}
+
+	return nil
+}
+
+func runWireGuardExport(cmdCtx *cmdctx.CmdContext) error {
+	org, err := orgByArg(cmdCtx)
+	if err != nil {
+		return err
+	}
+
+	name, err := peerByArg(cmdCtx, org)
+	if err != nil {
+		return err
+	}
+
+	var c *wgConfig
+
+	if path := cmdCtx.Config.GetString("config"); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if c, err = parseWgConfig(f); err != nil {
+			return fmt.Errorf("failed parsing %s: %w", path, err)
+		}
+	} else {
+		state, err := wireguard.LocalState(org.Slug, name)
+		if err != nil {
+			return err
+		}
+
+		if state == nil {
+			return fmt.Errorf("the private key of peer %s isn't stored locally; pass the configuration wireguard create wrote via --config, or issue new keys with 'flyctl wireguard rotate %s %s'", name, org.Slug, name)
+		}
+
+		c = newWgConfig(&state.Peer, state.LocalPrivate)
+	}
+
+	return writeWgConfig(cmdCtx, c, name, 2)
+}
+
+func runWireGuardRotate(cmdCtx *cmdctx.CmdContext) error {
+	ctx := cmdCtx.Command.Context()
+
+	org, err := orgByArg(cmdCtx)
+	if err != nil {
+		return err
+	}
+
+	name, err := peerByArg(cmdCtx, org)
+	if err != nil {
+		return err
+	}
+
+	// The output is resolved before rotating, so that the new configuration
+	// has somewhere to go before the old peer is removed.
+	o, err := openWgOutput(cmdCtx, 2)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmdCtx.Out, "Rotating the keys of WireGuard peer \"%s\" of organization %s\n", name, org.Slug)
+
+	state, err := wireguard.Rotate(ctx, cmdCtx.Client.API(), org, name, func(state *wg.WireGuardState) error {
+		fmt.Fprint(cmdCtx.Out, `
+!!!! WARNING: Output includes private key. Private keys cannot be recovered !!!!
+!!!! after rotating the peer; clients using the previous configuration      !!!!
+!!!! can no longer connect.                                                 !!!!
+`)
+
+		return o.write(cmdCtx, newWgConfig(&state.Peer, state.LocalPrivate), state.Name)
+	})
+	if err != nil {
+		o.discard()
+
+		return err
+	}
+
+	fmt.Fprintf(cmdCtx.Out, "Replaced peer %s with %s\n", name, state.Name)
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/flyctl"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+func TestWireGuardFormatFlag(t *testing.T) {
+	root := NewRootCmd(client.New())
+
+	for _, name := range []string{"export", "rotate"} {
+		cmd, _, err := root.Find([]string{"wireguard", name})
+		require.NoError(t, err, name)
+
+		require.NoError(t, cmd.ParseFlags([]string{"--format", wgFormatMobileConfig}), name)
+
+		assert.Equal(t, wgFormatMobileConfig, viper.GetString(namespace(cmd)+".format"), name)
+		assert.Empty(t, viper.GetString(flyctl.ConfigOutputFormat), "the wireguard --format must shadow the global one")
+	}
+}
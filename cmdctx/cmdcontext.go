@@ -16,6 +16,7 @@ import (
 	"github.com/superfly/flyctl/cmd/presenters"
 	"github.com/superfly/flyctl/flyctl"
 	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/outputtemplate"
 	"github.com/superfly/flyctl/pkg/iostreams"
 )
 
@@ -75,9 +76,26 @@ func NewCmdContext(flyctlClient *client.Client, ns string, cmd *cobra.Command, a
 	}
 	ctx.WorkingDir = cwd
 
+	ctx.IO.SetVerbosity(verbosity(ctx.GlobalConfig))
+
 	return ctx, nil
 }
 
+// verbosity returns the output verbosity the global flags denote. Asking for
+// more output takes precedence over asking for less.
+func verbosity(cfg flyctl.Config) iostreams.Verbosity {
+	switch {
+	case cfg.GetBool(flyctl.ConfigDebugOutput):
+		return iostreams.VerbosityDebug
+	case cfg.GetBool(flyctl.ConfigVerboseOutput):
+		return iostreams.VerbosityVerbose
+	case cfg.GetBool(flyctl.ConfigQuietOutput):
+		return iostreams.VerbosityQuiet
+	default:
+		return iostreams.VerbosityNormal
+	}
+}
+
 // Render - Render a presentable structure via the context
 func (commandContext *CmdContext) Render(presentable presenters.Presentable) error {
 	presenter := &presenters.Presenter{
@@ -85,6 +103,7 @@ func (commandContext *CmdContext) Render(presentable presenters.Presentable) err
 		Out:  os.Stdout,
 		Opts: presenters.Options{
 			AsJSON: commandContext.OutputJSON(),
+			Format: commandContext.outputFormat(),
 		},
 	}
 
@@ -101,6 +120,7 @@ func (commandContext *CmdContext) render(out io.Writer, views ...PresenterOption
 				HideHeader: v.HideHeader,
 				Title:      v.Title,
 				AsJSON:     v.AsJSON,
+				Format:     commandContext.outputFormat(),
 			},
 		}
 
@@ -168,6 +188,11 @@ func (commandContext *CmdContext) Status(source string, status string, args ...i
 		}
 	}
 
+	if commandContext.outputFormat() != "" {
+		// statuses would end up in the output of the template
+		return
+	}
+
 	if outputJSON {
 		outstruct := JSON{TS: time.Now().Format(time.RFC3339),
 			Source:  source,
@@ -207,6 +232,11 @@ func (commandContext *CmdContext) Statusf(source string, status string, format s
 
 	message := fmt.Sprintf(format, args...)
 
+	if commandContext.outputFormat() != "" {
+		// statuses would end up in the output of the template
+		return
+	}
+
 	if outputJSON {
 		outbuf, _ := json.Marshal(JSON{TS: time.Now().Format(time.RFC3339),
 			Source:  source,
@@ -220,10 +250,25 @@ func (commandContext *CmdContext) Statusf(source string, status string, format s
 }
 
 func (commandContext *CmdContext) WriteJSON(myData interface{}) {
+	if format := commandContext.outputFormat(); format != "" {
+		if err := outputtemplate.Execute(commandContext.IO.Out, format, myData); err != nil {
+			fmt.Fprintln(commandContext.IO.ErrOut, err)
+		}
+
+		return
+	}
+
 	outBuf, _ := json.MarshalIndent(myData, "", "    ")
 	fmt.Fprintln(commandContext.IO.Out, string(outBuf))
 }
 
+// OutputJSON reports whether the user wants structured output, as JSON or
+// through the template of the --format flag.
 func (commandContext *CmdContext) OutputJSON() bool {
-	return commandContext.GlobalConfig.GetBool(flyctl.ConfigJSONOutput)
+	return commandContext.GlobalConfig.GetBool(flyctl.ConfigJSONOutput) || commandContext.outputFormat() != ""
+}
+
+// outputFormat returns the Go template of the --format flag, if any.
+func (commandContext *CmdContext) outputFormat() string {
+	return commandContext.GlobalConfig.GetString(flyctl.ConfigOutputFormat)
 }
@@ -117,7 +117,8 @@ authenticated and in use.`,
 		}
 	case "autoscale":
 		return KeyStrings{"autoscale", "Autoscaling app resources",
-			`Autoscaling application resources`,
+			`Autoscaling application resources. Autoscaling may also be declared in the
+[autoscale] section of the app config; see flyctl autoscale export.`,
 		}
 	case "autoscale.balanced":
 		return KeyStrings{"balanced", "Configure a traffic balanced app with params (min=int max=int)",
@@ -130,6 +131,21 @@ max=int - maximum number of instances to be allocated from region pool.`,
 		return KeyStrings{"disable", "Disable autoscaling",
 			`Disable autoscaling to manually controlling app resources`,
 		}
+	case "autoscale.export":
+		return KeyStrings{"export", "Export autoscaling configuration to the app config",
+			`Export the current autoscaling configuration as the [autoscale] section
+of the app config file, which fly deploy applies along with the release:
+
+[autoscale]
+  strategy = "balanced"    # or "standard", or "disabled"
+  min_count = 2
+  max_count = 10
+
+  [autoscale.regions.ord]  # per-region limits
+    min_count = 1
+
+The section is written to the app config file, or printed when there's none.`,
+		}
 	case "autoscale.set":
 		return KeyStrings{"set", "Set current models autoscaling parameters",
 			`Allows the setting of the current models autoscaling parameters:
@@ -190,12 +206,35 @@ certificates issued for the hostname/domain by Let's Encrypt.`,
 	case "certs.add":
 		return KeyStrings{"add <hostname>", "Add a certificate for an app.",
 			`Add a certificate for an application. Takes a hostname
-as a parameter for the certificate.`,
+as a parameter for the certificate.
+
+With --dns-provider, the DNS records the certificate requires are created via
+the API of the provider, after which certs add waits up to --wait-timeout
+minutes for the certificate to be issued. Records which exist already are left
+as is; conflicting ones fail the command rather than being replaced.
+
+Supported providers and the environment variables their tokens are read from:
+
+  cloudflare  CLOUDFLARE_API_TOKEN, with the Zone:Read and DNS:Edit permissions`,
 		}
 	case "certs.check":
-		return KeyStrings{"check <hostname>", "Checks DNS configuration",
+		return KeyStrings{"check [<hostname>]", "Checks DNS configuration",
 			`Checks the DNS configuration for the specified hostname.
-Displays results in the same format as the SHOW command.`,
+Displays results in the same format as the SHOW command.
+
+With --all, checks every certificate of the application, --concurrency at a
+time, and summarizes how many are issued, pending or misconfigured, listing
+the misconfigured ones and the ones which failed to be checked.`,
+		}
+	case "certs.import":
+		return KeyStrings{"import", "Add certificates for a list of hostnames",
+			`Adds certificates for the hostnames listed in a file, one per line, as
+--from-file denotes. Blank lines and lines starting with # are skipped, as are
+hostnames which already have a certificate.
+
+Certificates are added --concurrency at a time. Requests the API rate limits
+are retried with backoff. Once done, a summary lists the hostnames which
+failed; run 'flyctl certs check --all' to follow their validation.`,
 		}
 	case "certs.list":
 		return KeyStrings{"list", "List certificates for an app.",
@@ -231,14 +270,65 @@ Takes hostname as a parameter to locate the certificate.`,
 		return KeyStrings{"list", "List health check handlers",
 			`List health check handlers`,
 		}
+	case "checks.history":
+		return KeyStrings{"history <check>", "Show the history of an app health check",
+			`Show the recent transitions of the named health check of each
+allocation of the app, oldest first, to diagnose flapping checks.
+
+Transitions are recorded by flyctl each time it lists the app's checks, so the
+history covers what checks list, checks list --watch and checks history
+themselves observed; keep checks list --watch running to capture every one.`,
+		}
 	case "checks.list":
 		return KeyStrings{"list", "List app health checks",
-			`List app health checks`,
+			`List app health checks.
+
+With --watch, the checks are refreshed every --rate seconds and their
+transitions shown as they happen; with --json, each transition is written as
+a JSON object of its own line. Transitions are recorded locally for
+checks history.`,
+		}
+	case "checks.report":
+		return KeyStrings{"report", "Report on the stability of app health checks",
+			`Report on the stability of the app's health checks over the --last window,
+e.g. 24h or 7d, to guide tuning them.
+
+Each check of each allocation is listed with its number of status changes,
+the share of the time it spent passing and a timeline of its status, the
+least stable first; checks which change status often are flagged as flapping.
+The stability score is the share of the time the checks spent passing, less
+a few points per flapping check.
+
+The report is based on the history checks history shows, which flyctl
+records as it lists the app's checks.`,
+		}
+	case "checks.update":
+		return KeyStrings{"update [check]", "Update the parameters of app health checks",
+			`Update the interval, timeout and grace period of the app's health checks
+without building or deploying a new image. The named check is updated, or all
+of them when no name is given. Checks of the [checks] section are named by
+their key; checks of services by their name setting, if any, or by their
+position, e.g. services[0].tcp_checks[0].
+
+For apps running on machines, the config of each machine is updated in place.
+For others, a release of the image of the newest stable release is created
+with the current config, only with the checks updated. It's rolled out like
+any other release: the release command of the app, if any, runs again before
+it's deployed.
+
+The app's fly.toml isn't changed, so update it as well lest the next deploy
+restore the previous settings.`,
 		}
 	case "config":
 		return KeyStrings{"config", "Manage an app's configuration",
 			`The CONFIG commands allow you to work with an application's configuration.`,
 		}
+	case "config.diff":
+		return KeyStrings{"diff", "Diff an app's config file against the deployed configuration",
+			`Display the differences between an application's local config file and
+the configuration currently deployed on the Fly platform. Both are normalized
+by the platform before being compared, so that only meaningful changes are shown.`,
+		}
 	case "config.display":
 		return KeyStrings{"display", "Display an app's configuration",
 			`Display an application's configuration. The configuration is presented
@@ -249,10 +339,38 @@ in JSON format. The configuration data is retrieved from the Fly service.`,
 			`Display an app's runtime environment variables. It displays a section for
 secrets and another for config file defined environment variables.`,
 		}
+	case "config.lock":
+		return KeyStrings{"lock [FIELD]...", "Encrypt fields of an app's config file",
+			`Encrypt the values of fields of an app's config file in place, so that
+settings which are sensitive but not secret may be committed along with it.
+Fields are given by their dotted paths, such as env.API_HOST, and are added
+to the fields of the [encryption] section, all of which are encrypted.
+
+Values are encrypted with a data key which is itself encrypted for each of
+the age public keys and AWS KMS key ARNs the [encryption] section names:
+
+  [encryption]
+    age = ["age1..."]
+    kms = ["arn:aws:kms:us-east-1:111122223333:key/..."]
+
+Encrypting requires the age or aws CLI. Once the data key exists, adding
+fields or recipients requires decrypting it, as 'fly config unlock' does.
+Deployments decrypt encrypted values transparently.`,
+		}
 	case "config.save":
 		return KeyStrings{"save", "Save an app's config file",
 			`Save an application's configuration locally. The configuration data is
-retrieved from the Fly service and saved in TOML format.`,
+retrieved from the Fly service and saved in the format of the existing
+config file (fly.toml, fly.yaml or fly.json), defaulting to TOML.`,
+		}
+	case "config.unlock":
+		return KeyStrings{"unlock", "Decrypt the encrypted fields of an app's config file",
+			`Decrypt the encrypted values of an app's config file in place, so that they
+may be edited, after which 'fly config lock' encrypts them again.
+
+The data key is decrypted with the age identity file FLY_AGE_KEY_FILE or
+SOPS_AGE_KEY_FILE denote, defaulting to the one sops uses, or through the aws
+CLI and the credentials it's configured with for KMS keys.`,
 		}
 	case "config.validate":
 		return KeyStrings{"validate", "Validate an app's config file",
@@ -357,7 +475,21 @@ one command.
 * View a deployed web application with the open command
 * Check the status of an application with the status command
 
-To read more, use the docs command to view Fly's help on the web.`,
+To read more, use the docs command to view Fly's help on the web.
+
+Commands exit with one of the following codes:
+
+  0    success
+  1    generic failure
+  2    invalid arguments, flags or input
+  3    not authenticated or not authorized
+  4    resource not found
+  5    timed out
+  6    deployment failed
+  127  cancelled
+
+With --error-json, or FLY_ERROR_JSON set, errors are printed on stderr as
+JSON objects carrying their message, kind and exit code.`,
 		}
 	case "history":
 		return KeyStrings{"history", "List an app's change history",
@@ -377,6 +509,23 @@ events and their results.`,
 			`This will update the application's image to the latest available version.
 The update will perform a rolling restart against each VM, which may result in a brief service disruption.`,
 		}
+	case "import":
+		return KeyStrings{"import", "Import apps from other platforms",
+			`Import apps from other platforms`,
+		}
+	case "import.heroku":
+		return KeyStrings{"heroku <heroku-app>", "Import a Heroku app",
+			`Creates a Fly app off of a Heroku app. The app's config vars, Procfile,
+formation and add-ons are read in order to generate a fly.toml, a Procfile and
+a Dockerfile in a directory named after the new app.
+
+Config vars which look sensitive, such as API_KEY or DATABASE_URL, are planned
+as secrets, which, when running interactively, you confirm or adjust; secrets
+are only set on the new app when --set-secrets or --deploy is passed. Config
+vars add-ons provide are left out since they point to Heroku resources, and a
+replacement is suggested for each add-on. The Heroku formation is printed as
+the scale count command to run once the app is deployed.`,
+		}
 	case "info":
 		return KeyStrings{"info", "Show detailed app information",
 			`Shows information about the application on the Fly platform
@@ -395,11 +544,20 @@ and IPv6 addresses.`,
 		}
 	case "ips.allocate-v4":
 		return KeyStrings{"allocate-v4", "Allocate an IPv4 address",
-			`Allocates an IPv4 address to the application.`,
+			`Allocates a dedicated IPv4 address to the application. Dedicated IPv4
+addresses are billed monthly, so interactive sessions confirm the allocation
+unless --yes is given.
+
+With --region, the address is anchored to the given region: it's announced
+from there only, so traffic to it always enters the Fly network there.
+
+With --wait, the command returns once the <app>.fly.dev hostname of the app
+resolves to the new address, which is when allow-lists may be updated.`,
 		}
 	case "ips.allocate-v6":
 		return KeyStrings{"allocate-v6", "Allocate an IPv6 address",
-			`Allocates an IPv6 address to the application.`,
+			`Allocates an IPv6 address to the application. --region and --wait work
+as they do for allocate-v4.`,
 		}
 	case "ips.list":
 		return KeyStrings{"list", "List allocated IP addresses",
@@ -412,11 +570,20 @@ Fly network`,
 		}
 	case "ips.release":
 		return KeyStrings{"release [ADDRESS]", "Release an IP address",
-			`Releases an IP address from the application.`,
+			`Releases an IP address from the application. Released addresses can't be
+reclaimed, so interactive sessions confirm the release unless --yes is given.
+With --wait, the command returns once the <app>.fly.dev hostname of the app no
+longer resolves to the address.`,
 		}
 	case "launch":
 		return KeyStrings{"launch", "Launch a new app",
-			`Create and configure a new app from source code or an image reference.`,
+			`Create and configure a new app from source code or an image reference.
+
+Use --import-env to migrate the variables of an existing .env file. Variables
+whose names look sensitive, such as API_KEY or DATABASE_URL, are taken for
+secrets, which interactive sessions confirm. The rest go into the [env]
+section of fly.toml; secrets are set on the new app with --import-secrets and
+left out otherwise.`,
 		}
 	case "list":
 		return KeyStrings{"list", "Lists your Fly resources",
@@ -461,25 +628,84 @@ to all instances running in a specific region using the --region/-r flag.`,
 		return KeyStrings{"clone", "Clones a Fly Machine",
 			`Clones a Fly Machine`,
 		}
+	case "machine.create":
+		return KeyStrings{"create [image]", "Create a Fly machine",
+			`Create a Fly machine from an image. The rest of the machine's config may be
+given as JSON with --machine-config, in the same shape the machines API takes:
+
+  {
+    "image": "nginx:latest",
+    "env": {"PORT": "8080"},
+    "guest": {"cpu_kind": "shared", "cpus": 1, "memory_mb": 256}
+  }
+
+The image argument overrides the image of the config. Labels given with
+--label are kept in the metadata of the config, and select the machine with
+the --selector flag of machine list, update and remove. Pass --wait to return
+only once the machine is started.`,
+		}
+	case "machine.exec":
+		return KeyStrings{"exec <id> <command>", "Run a command in a Fly machine",
+			`Run a one-off command in a started Fly machine over SSH, through the
+WireGuard tunnel of the app's organization. flyctl exits with the exit status
+of the command. With --timeout, the command is terminated once it has run for
+the given number of seconds.`,
+		}
+	case "machine.images":
+		return KeyStrings{"images <command>", "Manage Fly-provided base images",
+			`Commands that list the base images Fly provides for running infrastructure
+apps, such as Postgres and Redis.`,
+		}
+	case "machine.images.list":
+		return KeyStrings{"list", "List Fly-provided base images",
+			`List the base images Fly provides, along with the reference of their latest
+version and where their changelogs are found. 'flyctl status' tells when an app
+runs an outdated version of one of them, which 'flyctl image update' updates.`,
+		}
 	case "machine.kill":
 		return KeyStrings{"kill <id>", "Kill (SIGKILL) a Fly machine",
 			`Kill (SIGKILL) a Fly machine`,
 		}
 	case "machine.list":
 		return KeyStrings{"list", "List Fly machines",
-			`List Fly machines`,
+			`List Fly machines. --selector lists only the machines whose labels match
+all of its comma separated requirements: key=value, key!=value, key (the label
+is set) and !key (the label isn't set). Labels are shown with --show-labels,
+or in columns of their own with --label-columns. With --quiet, only the ids of
+the machines are listed.`,
+		}
+	case "machine.logs":
+		return KeyStrings{"logs <id>", "Stream the logs of a Fly machine",
+			`Stream the logs of a Fly machine until interrupted.`,
 		}
 	case "machine.remove":
-		return KeyStrings{"remove <id>", "Remove a Fly machine",
-			`Remove a Fly machine`,
+		return KeyStrings{"remove [id...]", "Remove a Fly machine",
+			`Remove (destroy) Fly machines, either the given ones or the ones whose
+labels match --selector, as with machine list. Removing machines by selector
+asks for confirmation unless --yes is given.`,
+		}
+	case "machine.restart":
+		return KeyStrings{"restart <id>", "Restart a Fly machine",
+			`Restart a Fly machine by stopping and starting it again. Stopping honors
+the same signal, grace period and connection drain settings as machine stop.`,
 		}
 	case "machine.run":
 		return KeyStrings{"run <image> [command]", "Launch a Fly machine",
-			`Launch Fly machine with the provided image and command`,
+			`Launch Fly machine with the provided image and command.
+
+With --schedule, the machine runs a recurring job instead: it's booted at the
+times the given cron expression denotes, in UTC, and should exit once its work
+is done. Unless the restart policy says otherwise, exited jobs aren't restarted
+until their next run. List scheduled machines and the outcome of their last
+runs with 'flyctl cron list'.
+
+Volumes are mounted via --volume, which has no shorthand since -v is the
+global --verbose.`,
 		}
 	case "machine.start":
 		return KeyStrings{"start <id>", "Start a Fly machine",
-			`Start a Fly machine`,
+			`Start a Fly machine. Pass --wait to return only once the machine is
+started.`,
 		}
 	case "machine.status":
 		return KeyStrings{"status <id>", "Show current status of a running machine",
@@ -487,7 +713,35 @@ to all instances running in a specific region using the --region/-r flag.`,
 		}
 	case "machine.stop":
 		return KeyStrings{"stop <id>", "Stop a Fly machine",
-			`Stop a Fly machine`,
+			`Stop a Fly machine. The machine is sent the app's kill_signal, or SIGINT,
+and killed once the app's kill_timeout elapses; --signal and --time override
+them. Pass --drain-timeout to have the proxy stop routing new connections to
+the machine and let open ones finish before it is signaled. Pass --wait to
+return only once the machine is stopped.`,
+		}
+	case "machine.update":
+		return KeyStrings{"update [id]", "Update the config of a Fly machine",
+			`Update the guest tunables of a Fly machine. The swap_size_mb setting and the
+kernel_args and max_open_files settings of the [guest] section of the app's
+config are applied, overridden by any given flags:
+
+  swap_size_mb = 512
+
+  [guest]
+    kernel_args = ["transparent_hugepage=never"]
+    max_open_files = 65536
+
+Kernel args are limited to parameters like transparent_hugepage=, hugepages=,
+numa_balancing= and sysctl.*; parameters which control how the machine boots
+are reserved for the platform.
+
+The whole config of the machine may be replaced with the JSON config given with
+--machine-config, as taken by machine create; tunables are applied on top of
+it. Labels are set with --label and removed with --remove-label.
+
+Rather than a single machine, --selector updates all the machines whose labels
+match it, as with machine list. Pass --wait to return only once the updated
+machines are started.`,
 		}
 	case "monitor":
 		return KeyStrings{"monitor", "Monitor deployments",
@@ -569,9 +823,44 @@ about the Fly platform.`,
 		return KeyStrings{"attach", "Attach a postgres cluster to an app",
 			`Attach a postgres cluster to an app`,
 		}
+	case "postgres.backup":
+		return KeyStrings{"backup", "Manage backups of a postgres cluster",
+			`Manage WAL-G base backups of a postgres cluster. WAL-G must be configured
+on the cluster through its WALG_ secrets, which name where backups and WAL are
+stored and the credentials to that storage.`,
+		}
+	case "postgres.backup.create":
+		return KeyStrings{"create <postgres-cluster-name>", "Create a base backup of a postgres cluster",
+			`Create a base backup of a postgres cluster with WAL-G`,
+		}
+	case "postgres.backup.list":
+		return KeyStrings{"list <postgres-cluster-name>", "List the base backups of a postgres cluster",
+			`List the base backups of a postgres cluster`,
+		}
+	case "postgres.backup.restore":
+		return KeyStrings{"restore <postgres-cluster-name>", "Restore a backup into a new postgres cluster",
+			`Restore a base backup of a postgres cluster into a new, single node
+cluster. The new cluster reads backups with the WAL-G configuration of the
+source cluster, and stores its own next to them, under storage prefixes
+suffixed with its name, e.g. s3://bucket/source-newcluster for
+s3://bucket/source.
+
+--time restores to a point in time: the latest backup which finished by then
+is restored, unless --backup names another, and WAL is replayed from it up to
+that time.
+
+The source cluster is left untouched.`,
+		}
 	case "postgres.connect":
 		return KeyStrings{"connect", "Connect to the Postgres console",
-			`Connect to the Postgres console`,
+			`Connect to the Postgres console. The cluster is proxied to a local port
+through the WireGuard tunnel and psql is launched against it, with the
+password of the user read off the cluster for the users it creates and
+prompted for otherwise. Hosts without psql get the console of the cluster
+itself, over ssh.
+
+--url prints a DATABASE_URL for the local port instead, for other clients to
+connect with while the command runs.`,
 		}
 	case "postgres.create":
 		return KeyStrings{"create", "Create a postgres cluster",
@@ -658,18 +947,38 @@ the number of configured instances.`,
 			`Scale application resources`,
 		}
 	case "scale.count":
-		return KeyStrings{"count <count>", "Change an app's VM count to the given value",
+		return KeyStrings{"count <count> | <group>=<count>...", "Change an app's VM count to the given value",
 			`Change an app's VM count to the given value.
 
+Apps which run several process groups, declared in the [processes] section
+of fly.toml, are scaled per group, e.g. 'fly scale count web=2 worker=1'.
+
 For pricing, see https://fly.io/docs/about/pricing/`,
 		}
+	case "scale.cpu":
+		return KeyStrings{"cpu <cores>", "Set VM CPU cores",
+			`Set the number of CPU cores of the VMs, switching to the VM size of the
+same class (shared or dedicated) with that many cores. Memory is kept unless
+the new size requires more.
+
+Use --group to size the VMs of a single process group, like workers, rather
+than the whole app.
+
+e.g. flyctl scale cpu 2 --group worker`,
+		}
 	case "scale.memory":
 		return KeyStrings{"memory <memoryMB>", "Set VM memory",
-			`Set VM memory to a number of megabytes`,
+			`Set VM memory to a number of megabytes
+
+Use --group to size the VMs of a single process group, like workers, rather
+than the whole app. The group keeps its current VM size.
+
+e.g. flyctl scale memory 2048 --group worker`,
 		}
 	case "scale.show":
 		return KeyStrings{"show", "Show current resources",
-			`Show current VM size and counts`,
+			`Show current VM size and counts, including the VM sizes of the
+process groups of the app`,
 		}
 	case "scale.vm":
 		return KeyStrings{"vm [SIZENAME] [flags]", "Change an app's VM to a named size (eg. shared-cpu-1x, dedicated-cpu-1x, dedicated-cpu-2x...)",
@@ -698,9 +1007,17 @@ case sensitive and stored as-is, so ensure names are appropriate for
 the application and vm environment.`,
 		}
 	case "secrets.import":
-		return KeyStrings{"import [flags]", "Read secrets in name=value from stdin",
-			`Set one or more encrypted secrets for an application. Values
-are read from stdin as name=value`,
+		return KeyStrings{"import [flags] [FILE]", "Read secrets in name=value format from a file or stdin",
+			`Set one or more encrypted secrets for an application. Secrets
+are read in dotenv format, as NAME=VALUE lines, from the given file or stdin.
+
+Blank lines and lines starting with # are skipped. Values may be quoted and
+values wrapped in triple double quotes may span multiple lines. All secrets are
+validated before any is set, and they're set at once, resulting in a single
+restart of the app.
+
+With --stage the secrets are recorded locally instead and applied with the next
+deploy of the app, in the same release.`,
 		}
 	case "secrets.list":
 		return KeyStrings{"list", "Lists the secrets available to the app",
@@ -716,7 +1033,31 @@ Secrets are provided to application at runtime as ENV variables. Names are
 case sensitive and stored as-is, so ensure names are appropriate for
 the application and vm environment.
 
-Any value that equals "-" will be assigned from STDIN instead of args.`,
+Any value that equals "-" will be assigned from STDIN instead of args.
+
+With --stage the secrets are recorded locally instead and applied with the next
+deploy of the app, in the same release.`,
+		}
+	case "secrets.staged":
+		return KeyStrings{"staged [flags]", "List or discard the staged secrets of an app",
+			`List the secrets staged via secrets set --stage, secrets unset --stage
+or secrets import --stage. Staged secrets are recorded locally and applied by
+the next deploy of the application, in the same release as the new code. Values
+are never shown.`,
+		}
+	case "secrets.sync":
+		return KeyStrings{"sync --from <source>", "Set secrets from an external secrets manager",
+			`Set secrets from an external secrets manager, so that their values never
+pass through the shell or its history. Supported sources are:
+
+  vault://<mount>/<path>     HashiCorp Vault key/value secrets; authenticated
+                             via VAULT_ADDR and VAULT_TOKEN
+  aws://<name-or-arn>        AWS Secrets Manager, via the aws CLI
+  gcp://<project>/<secret>   GCP Secret Manager, via the gcloud CLI
+
+Secrets manager values must be JSON objects, each field of which becomes a
+secret, unless ?key=NAME is given to set the whole value as the secret NAME.
+Secrets may also be pulled at deploy time via deploy --secrets-from.`,
 		}
 	case "secrets.unset":
 		return KeyStrings{"unset [flags] NAME NAME ...", "Remove encrypted secrets from an app",
@@ -729,7 +1070,26 @@ secret removes its availability to the application.`,
 		}
 	case "ssh.console":
 		return KeyStrings{"console [<host>]", "Connect to a running instance of the current app.",
-			`Connect to a running instance of the current app; with -select, choose instance from list.`,
+			`Connect to a running instance of the current app; with -select, choose instance from list.
+
+Forward ports along the connection with -L and -R, which take ssh's
+[bind_address:]port:host:hostport form. -L reaches services the instance binds
+to localhost, -R exposes local services to the instance. With -N, only the
+forwards are set up and no shell starts.
+
+When no WireGuard tunnel can be established, e.g. from networks which block
+it, commands of apps running on machines run over HTTPS instead: one at a
+time, without a terminal, port forwarding or file transfers. Set
+FLY_SSH_NO_HTTPS_FALLBACK to fail instead.`,
+		}
+	case "ssh.cp":
+		return KeyStrings{"cp <src> <dst>", "Copy files to and from an instance",
+			`Copy files between the local machine and a running instance of the
+current app over SFTP; with -select, choose instance from list. Remote paths
+are prefixed with a colon. With -recursive, copy directories.
+
+e.g. flyctl ssh cp ./seed.sql :/data/seed.sql
+     flyctl ssh cp -r :/data/uploads ./backup`,
 		}
 	case "ssh.establish":
 		return KeyStrings{"establish [<org>] [<override>]", "Create a root SSH certificate for your organization",
@@ -737,6 +1097,20 @@ secret removes its availability to the application.`,
 is provided, will re-key an organization; all previously issued creds will be
 invalidated.`,
 		}
+	case "ssh.exec":
+		return KeyStrings{"exec <command>", "Run a command on one or all instances of the current app.",
+			`Run a command on a running instance of the current app, without a
+terminal; with -select, choose instance from list. flyctl exits with the exit
+status of the command.
+
+With --all, the command runs on every running instance in parallel, optionally
+narrowed down to --region and --process-group. Each line of output is prefixed
+with the instance and region it came from; the command fails when it fails on
+any of the instances.
+
+Without --all, the command runs over HTTPS when no WireGuard tunnel can be
+established, like ssh console does.`,
+		}
 	case "ssh.issue":
 		return KeyStrings{"issue [org] [email] [path]", "Issue a new SSH credential.",
 			`Issue a new SSH credential. With -agent, populate credential
@@ -747,6 +1121,12 @@ validity.`,
 		return KeyStrings{"log", "Log of all issued certs",
 			`log of all issued certs`,
 		}
+	case "ssh.sftp":
+		return KeyStrings{"sftp [<host>]", "Transfer files to and from an instance interactively",
+			`Start an interactive SFTP session on a running instance of the current
+app; with -select, choose instance from list. Type help in the session for a
+list of commands.`,
+		}
 	case "ssh.shell":
 		return KeyStrings{"shell [org] [address]", "Connect directly to an instance.",
 			`Connect directly to an instance. With -region, set the
@@ -840,6 +1220,20 @@ number to operate. This can be found through the volumes list command`,
 		return KeyStrings{"create [org] [region] [name]", "Add a WireGuard peer connection",
 			`Add a WireGuard peer connection to an organization`,
 		}
+	case "wireguard.export":
+		return KeyStrings{"export [org] [name] [file]", "Export the configuration of a WireGuard peer",
+			`Export the configuration of a WireGuard peer for third-party WireGuard
+clients, as a wg-quick configuration, an Apple configuration profile
+(mobileconfig) for the iOS and macOS apps, or a QR code for the mobile apps
+(which requires qrencode), as --format wg-quick|mobileconfig|qr selects.
+Defaults to wg-quick. Here --format names the type of the configuration
+rather than an output template.
+
+Private keys aren't stored by Fly; peers other than the ones flyctl created
+for itself are exported from the configuration wireguard create wrote, via
+--config. Use wireguard rotate to issue new keys for peers the configuration
+of which is lost.`,
+		}
 	case "wireguard.list":
 		return KeyStrings{"list [<org>]", "List all WireGuard peer connections",
 			`List all WireGuard peer connections`,
@@ -848,6 +1242,14 @@ number to operate. This can be found through the volumes list command`,
 		return KeyStrings{"remove [org] [name]", "Remove a WireGuard peer connection",
 			`Remove a WireGuard peer connection from an organization`,
 		}
+	case "wireguard.rotate":
+		return KeyStrings{"rotate [org] [name] [file]", "Rotate the keys of a WireGuard peer connection",
+			`Rotate the keys of a WireGuard peer connection by replacing the peer with a
+new one in the same region, named after it with a -r1, -r2, ... suffix, and
+write the new configuration in the given --format. The old peer is removed
+once the new configuration is written; clients using the previous
+configuration can no longer connect.`,
+		}
 	case "wireguard.status":
 		return KeyStrings{"status [org] [name]", "Get status a WireGuard peer connection",
 			`Get status for a WireGuard peer connection`,
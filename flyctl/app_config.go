@@ -20,6 +20,8 @@ type ConfigFormat string
 
 const (
 	TOMLFormat        ConfigFormat = ".toml"
+	YAMLFormat        ConfigFormat = ".yaml"
+	JSONFormat        ConfigFormat = ".json"
 	UnsupportedFormat              = ""
 )
 
@@ -68,6 +70,10 @@ func LoadAppConfig(configFile string) (*AppConfig, error) {
 	switch ConfigFormatFromPath(fullConfigFilePath) {
 	case TOMLFormat:
 		err = appConfig.unmarshalTOML(file)
+	case YAMLFormat:
+		err = appConfig.unmarshalYAML(file)
+	case JSONFormat:
+		err = appConfig.unmarshalJSON(file)
 	default:
 		return nil, errors.New("Unsupported config file format")
 	}
@@ -112,6 +118,10 @@ func (ac *AppConfig) WriteTo(w io.Writer, format ConfigFormat) error {
 	switch format {
 	case TOMLFormat:
 		return ac.marshalTOML(w)
+	case YAMLFormat:
+		return ac.marshalYAML(w)
+	case JSONFormat:
+		return ac.marshalJSON(w)
 	}
 
 	return fmt.Errorf("Unsupported format: %s", format)
@@ -195,6 +205,33 @@ func (ac *AppConfig) unmarshalNativeMap(data map[string]interface{}) error {
 	return nil
 }
 
+// toMap returns the native map representation of b.
+func (b *Build) toMap() map[string]interface{} {
+	buildData := map[string]interface{}{}
+	if b.Builder != "" {
+		buildData["builder"] = b.Builder
+	}
+	if len(b.Buildpacks) > 0 {
+		buildData["buildpacks"] = b.Buildpacks
+	}
+	if len(b.Args) > 0 {
+		buildData["args"] = b.Args
+	}
+	if b.Builtin != "" {
+		buildData["builtin"] = b.Builtin
+		if len(b.Settings) > 0 {
+			buildData["settings"] = b.Settings
+		}
+	}
+	if b.Image != "" {
+		buildData["image"] = b.Image
+	}
+	if b.Dockerfile != "" {
+		buildData["dockerfile"] = b.Dockerfile
+	}
+	return buildData
+}
+
 func (ac AppConfig) marshalTOML(w io.Writer) error {
 	encoder := toml.NewEncoder(w)
 
@@ -211,29 +248,7 @@ func (ac AppConfig) marshalTOML(w io.Writer) error {
 	rawData = ac.Definition
 
 	if ac.Build != nil {
-		buildData := map[string]interface{}{}
-		if ac.Build.Builder != "" {
-			buildData["builder"] = ac.Build.Builder
-		}
-		if len(ac.Build.Buildpacks) > 0 {
-			buildData["buildpacks"] = ac.Build.Buildpacks
-		}
-		if len(ac.Build.Args) > 0 {
-			buildData["args"] = ac.Build.Args
-		}
-		if ac.Build.Builtin != "" {
-			buildData["builtin"] = ac.Build.Builtin
-			if len(ac.Build.Settings) > 0 {
-				buildData["settings"] = ac.Build.Settings
-			}
-		}
-		if ac.Build.Image != "" {
-			buildData["image"] = ac.Build.Image
-		}
-		if ac.Build.Dockerfile != "" {
-			buildData["dockerfile"] = ac.Build.Dockerfile
-		}
-		rawData["build"] = buildData
+		rawData["build"] = ac.Build.toMap()
 	}
 
 	if len(ac.Definition) > 0 {
@@ -408,16 +423,19 @@ func (ac *AppConfig) SetEnvVariable(name, value string) {
 }
 
 func (ac *AppConfig) SetProcess(name, value string) {
-	var processes map[string]string
-
-	if rawProcesses, ok := ac.Definition["processes"]; ok {
-		if castProcesses, ok := rawProcesses.(map[string]string); ok {
-			processes = castProcesses
+	var processes map[string]interface{}
+
+	// keep the groups the config already declares, however they were decoded
+	switch rawProcesses := ac.Definition["processes"].(type) {
+	case map[string]interface{}:
+		processes = rawProcesses
+	case map[string]string:
+		processes = make(map[string]interface{}, len(rawProcesses))
+		for k, v := range rawProcesses {
+			processes[k] = v
 		}
-	}
-
-	if processes == nil {
-		processes = map[string]string{}
+	default:
+		processes = map[string]interface{}{}
 	}
 
 	processes[name] = value
@@ -435,6 +453,15 @@ func (ac *AppConfig) SetVolumes(volumes []sourcecode.Volume) {
 
 const defaultConfigFileName = "fly.toml"
 
+// configFileNames denotes the names an app config file may have in order of
+// preference.
+var configFileNames = []string{
+	defaultConfigFileName,
+	"fly.yaml",
+	"fly.yml",
+	"fly.json",
+}
+
 func ResolveConfigFileFromPath(p string) (string, error) {
 	p, err := filepath.Abs(p)
 	if err != nil {
@@ -446,6 +473,10 @@ func ResolveConfigFileFromPath(p string) (string, error) {
 
 	if err != nil {
 		if os.IsNotExist(err) {
+			// fall back to the alternative formats of a missing default file
+			if filepath.Base(p) == defaultConfigFileName {
+				return configFileInDir(filepath.Dir(p)), nil
+			}
 			return p, nil
 		}
 		return "", err
@@ -453,16 +484,32 @@ func ResolveConfigFileFromPath(p string) (string, error) {
 
 	// Ok, something exists. Is it a file - yes? return the path
 	if pd.IsDir() {
-		return path.Join(p, defaultConfigFileName), nil
+		return configFileInDir(p), nil
 	}
 
 	return p, nil
 }
 
+// configFileInDir returns the path of the first app config file found in dir,
+// or the path of the default one in case none exists.
+func configFileInDir(dir string) string {
+	for _, name := range configFileNames {
+		if p := path.Join(dir, name); helpers.FileExists(p) {
+			return p
+		}
+	}
+
+	return path.Join(dir, defaultConfigFileName)
+}
+
 func ConfigFormatFromPath(p string) ConfigFormat {
 	switch path.Ext(p) {
 	case ".toml":
 		return TOMLFormat
+	case ".yaml", ".yml":
+		return YAMLFormat
+	case ".json":
+		return JSONFormat
 	}
 	return UnsupportedFormat
 }
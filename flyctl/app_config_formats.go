@@ -0,0 +1,46 @@
+package flyctl
+
+import (
+	"io"
+
+	"github.com/superfly/flyctl/internal/appconfig"
+)
+
+func (ac *AppConfig) unmarshalYAML(r io.Reader) error {
+	data, err := appconfig.DecodeYAML(r)
+	if err != nil {
+		return err
+	}
+
+	return ac.unmarshalNativeMap(data)
+}
+
+func (ac *AppConfig) unmarshalJSON(r io.Reader) error {
+	data, err := appconfig.DecodeJSON(r)
+	if err != nil {
+		return err
+	}
+
+	return ac.unmarshalNativeMap(data)
+}
+
+// fullDefinition returns the definition of ac, build section included.
+func (ac AppConfig) fullDefinition() map[string]interface{} {
+	data := make(map[string]interface{}, len(ac.Definition)+1)
+	for k, v := range ac.Definition {
+		data[k] = v
+	}
+	if ac.Build != nil {
+		data["build"] = ac.Build.toMap()
+	}
+
+	return data
+}
+
+func (ac AppConfig) marshalYAML(w io.Writer) error {
+	return appconfig.EncodeYAML(w, ac.AppName, ac.fullDefinition())
+}
+
+func (ac AppConfig) marshalJSON(w io.Writer) error {
+	return appconfig.EncodeJSON(w, ac.AppName, ac.fullDefinition())
+}
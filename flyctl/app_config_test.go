@@ -1,6 +1,7 @@
 package flyctl
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/BurntSushi/toml"
@@ -54,3 +55,16 @@ func TestLoadTOMLAppConfigWithServices(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, p.Definition, rawData)
 }
+
+func TestEnvironmentsRoundtrip(t *testing.T) {
+	src, err := LoadAppConfig("./testdata/environments.toml")
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "fly.toml")
+	assert.NoError(t, src.WriteToFile(path))
+
+	dst, err := LoadAppConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, src.Definition["environments"], dst.Definition["environments"])
+	assert.Contains(t, dst.Definition, "environments")
+}
@@ -9,7 +9,10 @@ const (
 	ConfigAPIBaseURL      = "api_base_url"
 	ConfigAppName         = "app"
 	ConfigVerboseOutput   = "verbose"
+	ConfigQuietOutput     = "quiet"
+	ConfigDebugOutput     = "debug"
 	ConfigJSONOutput      = "json"
+	ConfigOutputFormat    = "format"
 	ConfigBuiltinsfile    = "builtins_file"
 	ConfigGQLErrorLogging = "gqlerrorlogging"
 	ConfigInstaller       = "installer"
@@ -1,7 +1,10 @@
 package helpers
 
 import (
+	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -22,3 +25,29 @@ func Duration(d time.Duration, dicimal int) time.Duration {
 
 	return d
 }
+
+// ParseDuration parses s like time.ParseDuration does, additionally accepting
+// a leading number of days, as in 7d or 1d12h.
+func ParseDuration(s string) (time.Duration, error) {
+	i := strings.Index(s, "d")
+	if i < 0 {
+		return time.ParseDuration(s)
+	}
+
+	days, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("time: invalid duration %q", s)
+	}
+
+	d := time.Duration(days * float64(24*time.Hour))
+
+	if rest := s[i+1:]; rest != "" {
+		r, err := time.ParseDuration(rest)
+		if err != nil || r < 0 {
+			return 0, fmt.Errorf("time: invalid duration %q", s)
+		}
+		d += r
+	}
+
+	return d, nil
+}
@@ -0,0 +1,151 @@
+// Package appconfig implements the YAML and JSON formats of app config files
+// for both the flyctl package and the app package of the CLI, so that the two
+// read and write them alike. Definitions are handled in their native map
+// representation, numbers as int64 or float64 the way decoding TOML yields
+// them.
+package appconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeYAML decodes the YAML app config r holds. Empty documents decode to
+// an empty definition.
+func DecodeYAML(r io.Reader) (map[string]interface{}, error) {
+	var data map[string]interface{}
+
+	if err := yaml.NewDecoder(r).Decode(&data); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return normalized(data), nil
+}
+
+// DecodeJSON decodes the JSON app config r holds.
+func DecodeJSON(r io.Reader) (map[string]interface{}, error) {
+	var data map[string]interface{}
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	return normalized(data), nil
+}
+
+func normalized(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return map[string]interface{}{}
+	}
+
+	return NormalizeNumbers(data).(map[string]interface{})
+}
+
+// NormalizeNumbers converts the numbers v contains to either int64 or
+// float64, which is what decoding TOML yields. Maps and slices are converted in
+// place.
+func NormalizeNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = NormalizeNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = NormalizeNumbers(e)
+		}
+	case int:
+		return int64(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+	}
+
+	return v
+}
+
+// Native returns a copy of def with any typed values it holds, such as
+// structs, converted to their generic counterparts.
+func Native(def map[string]interface{}) (map[string]interface{}, error) {
+	// roundtrip through the json encoder in order to convert structs to maps
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(def); err != nil {
+		return nil, err
+	}
+
+	var native map[string]interface{}
+
+	dec := json.NewDecoder(&buf)
+	dec.UseNumber()
+	if err := dec.Decode(&native); err != nil {
+		return nil, err
+	}
+
+	return normalized(native), nil
+}
+
+// EncodeYAML writes the app config of the named app def defines to w as
+// YAML, the app name first. Nothing is written in case encoding fails.
+func EncodeYAML(w io.Writer, appName string, def map[string]interface{}) error {
+	data, err := Native(def)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# fly.yaml file generated for %s on %s\n\n", appName, time.Now().Format(time.RFC3339))
+
+	// encode the name separately so that it comes first
+	if err := encodeYAML(&b, map[string]interface{}{"app": appName}); err != nil {
+		return err
+	}
+
+	if len(data) > 0 {
+		b.WriteString("\n")
+
+		if err := encodeYAML(&b, data); err != nil {
+			return err
+		}
+	}
+
+	_, err = b.WriteTo(w)
+
+	return err
+}
+
+func encodeYAML(w io.Writer, v interface{}) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// EncodeJSON writes the app config of the named app def defines to w as
+// indented JSON.
+func EncodeJSON(w io.Writer, appName string, def map[string]interface{}) error {
+	data, err := Native(def)
+	if err != nil {
+		return err
+	}
+	data["app"] = appName
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(data)
+}
@@ -0,0 +1,62 @@
+package appconfig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeNormalizesNumbers(t *testing.T) {
+	expected := map[string]interface{}{
+		"kill_timeout": int64(5),
+		"services": []interface{}{
+			map[string]interface{}{"internal_port": int64(8080), "ratio": 0.5},
+		},
+	}
+
+	data, err := DecodeJSON(strings.NewReader(`{"kill_timeout": 5, "services": [{"internal_port": 8080, "ratio": 0.5}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, expected, data)
+
+	data, err = DecodeYAML(strings.NewReader("kill_timeout: 5\nservices:\n  - internal_port: 8080\n    ratio: 0.5\n"))
+	require.NoError(t, err)
+	assert.Equal(t, expected, data)
+
+	data, err = DecodeYAML(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Empty(t, data)
+}
+
+func TestEncodeRoundtrips(t *testing.T) {
+	type port struct {
+		Port int `json:"port"`
+	}
+
+	def := map[string]interface{}{
+		"env":   map[string]interface{}{"PORT": "8080"},
+		"ports": []port{{Port: 80}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeYAML(&buf, "web", def))
+	assert.Contains(t, buf.String(), "\napp: web\n\nenv:\n")
+
+	data, err := DecodeYAML(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"app":   "web",
+		"env":   map[string]interface{}{"PORT": "8080"},
+		"ports": []interface{}{map[string]interface{}{"port": int64(80)}},
+	}, data)
+
+	buf.Reset()
+	require.NoError(t, EncodeJSON(&buf, "web", def))
+
+	data, err = DecodeJSON(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, "web", data["app"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"port": int64(80)}}, data["ports"])
+}
@@ -0,0 +1,332 @@
+// Package autoscale implements the autoscale section of app configs, which
+// declares the autoscaling settings deployments apply along with the rest of
+// the config:
+//
+//	[autoscale]
+//	  strategy = "balanced"
+//	  min_count = 2
+//	  max_count = 10
+//
+//	  [autoscale.regions.ord]
+//	    min_count = 1
+//	    weight = 2
+//
+// The section stays local; it's applied through the autoscaling API rather
+// than as part of the definition.
+package autoscale
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// Section is the name of the section of app configs the autoscaling settings
+// are found in.
+const Section = "autoscale"
+
+// The strategies apps may be autoscaled with.
+const (
+	// StrategyBalanced balances instances across regions.
+	StrategyBalanced = "balanced"
+	// StrategyStandard places instances in the regions closest to demand.
+	StrategyStandard = "standard"
+	// StrategyDisabled disables autoscaling.
+	StrategyDisabled = "disabled"
+)
+
+// Config wraps the contents of the autoscale section.
+type Config struct {
+	Strategy string
+	MinCount int
+	MaxCount int
+
+	// Regions maps region codes to the limits of the region.
+	Regions map[string]Region
+}
+
+// Region wraps the limits of a region.
+type Region struct {
+	MinCount int
+	Weight   int
+}
+
+// FromDefinition returns the autoscaling settings of the given definition, or
+// nil in case it has no autoscale section.
+func FromDefinition(def map[string]interface{}) (*Config, error) {
+	raw, ok := def[Section]
+	if !ok {
+		return nil, nil
+	}
+
+	section, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a table", Section)
+	}
+
+	cfg := &Config{
+		Strategy: StrategyStandard,
+	}
+
+	for k, v := range section {
+		var err error
+
+		switch k {
+		case "strategy":
+			cfg.Strategy = strings.ToLower(fmt.Sprint(v))
+		case "min_count":
+			cfg.MinCount, err = toInt(v)
+		case "max_count":
+			cfg.MaxCount, err = toInt(v)
+		case "regions":
+			cfg.Regions, err = regions(v)
+		default:
+			err = fmt.Errorf("unknown setting")
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", Section, k, err)
+		}
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Take returns the autoscaling settings of def, as FromDefinition does, and
+// removes them from it.
+func Take(def map[string]interface{}) (*Config, error) {
+	cfg, err := FromDefinition(def)
+	if err == nil {
+		delete(def, Section)
+	}
+
+	return cfg, err
+}
+
+func regions(v interface{}) (map[string]Region, error) {
+	raw, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("must be a table of regions")
+	}
+
+	regions := make(map[string]Region, len(raw))
+	for code, v := range raw {
+		settings, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s must be a table", code)
+		}
+
+		var (
+			region Region
+			err    error
+		)
+
+		for k, v := range settings {
+			switch k {
+			case "min_count":
+				region.MinCount, err = toInt(v)
+			case "weight":
+				region.Weight, err = toInt(v)
+			default:
+				err = fmt.Errorf("unknown setting")
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", code, k, err)
+			}
+		}
+
+		regions[code] = region
+	}
+
+	return regions, nil
+}
+
+func toInt(v interface{}) (int, error) {
+	var n int
+
+	switch v := v.(type) {
+	case int:
+		n = v
+	case int64:
+		n = int(v)
+	case float64:
+		if v != float64(int(v)) {
+			return 0, fmt.Errorf("must be a whole number")
+		}
+		n = int(v)
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			return 0, fmt.Errorf("must be a whole number")
+		}
+		n = int(i)
+	default:
+		return 0, fmt.Errorf("must be a number")
+	}
+
+	if n < 0 {
+		return 0, fmt.Errorf("may not be negative")
+	}
+
+	return n, nil
+}
+
+func (cfg *Config) validate() error {
+	switch cfg.Strategy {
+	case StrategyBalanced, StrategyStandard, StrategyDisabled:
+	default:
+		return fmt.Errorf("%s.strategy must be one of %s, %s or %s", Section, StrategyBalanced, StrategyStandard, StrategyDisabled)
+	}
+
+	if cfg.Strategy == StrategyDisabled {
+		return nil
+	}
+
+	if cfg.MaxCount == 0 {
+		return fmt.Errorf("%s.max_count must be set", Section)
+	} else if cfg.MinCount > cfg.MaxCount {
+		return fmt.Errorf("%s.min_count (%d) exceeds %s.max_count (%d)", Section, cfg.MinCount, Section, cfg.MaxCount)
+	}
+
+	var regional int
+	for _, region := range cfg.Regions {
+		regional += region.MinCount
+	}
+
+	if regional > cfg.MaxCount {
+		return fmt.Errorf("the min_count of the regions of %s add up to %d, which exceeds %s.max_count (%d)", Section, regional, Section, cfg.MaxCount)
+	}
+
+	return nil
+}
+
+// Input returns the input which applies cfg to the app with the given ID.
+// Regions the autoscaling settings of the app have but cfg doesn't are reset.
+func (cfg *Config) Input(appID string) api.UpdateAutoscaleConfigInput {
+	if cfg.Strategy == StrategyDisabled {
+		return api.UpdateAutoscaleConfigInput{
+			AppID:   appID,
+			Enabled: api.BoolPointer(false),
+		}
+	}
+
+	input := api.UpdateAutoscaleConfigInput{
+		AppID:          appID,
+		Enabled:        api.BoolPointer(true),
+		MinCount:       api.IntPointer(cfg.MinCount),
+		MaxCount:       api.IntPointer(cfg.MaxCount),
+		BalanceRegions: api.BoolPointer(cfg.Strategy == StrategyBalanced),
+		ResetRegions:   api.BoolPointer(true),
+	}
+
+	for _, code := range cfg.regionCodes() {
+		region := cfg.Regions[code]
+
+		in := api.AutoscaleRegionConfigInput{
+			Code:     code,
+			MinCount: api.IntPointer(region.MinCount),
+		}
+		if region.Weight > 0 {
+			in.Weight = api.IntPointer(region.Weight)
+		}
+
+		input.Regions = append(input.Regions, in)
+	}
+
+	return input
+}
+
+// FromAPI returns the autoscaling settings the given autoscaling config of an
+// app denotes.
+func FromAPI(as *api.AutoscalingConfig) *Config {
+	cfg := &Config{
+		Strategy: StrategyDisabled,
+	}
+
+	if as == nil || !as.Enabled {
+		return cfg
+	}
+
+	cfg.Strategy = StrategyStandard
+	if as.BalanceRegions {
+		cfg.Strategy = StrategyBalanced
+	}
+	cfg.MinCount = as.MinCount
+	cfg.MaxCount = as.MaxCount
+
+	if len(as.Regions) > 0 {
+		cfg.Regions = make(map[string]Region, len(as.Regions))
+		for _, region := range as.Regions {
+			cfg.Regions[region.Code] = Region{
+				MinCount: region.MinCount,
+				Weight:   region.Weight,
+			}
+		}
+	}
+
+	return cfg
+}
+
+// Section returns the autoscale section which declares cfg.
+func (cfg *Config) Section() map[string]interface{} {
+	section := map[string]interface{}{
+		"strategy": cfg.Strategy,
+	}
+
+	if cfg.Strategy == StrategyDisabled {
+		return section
+	}
+
+	section["min_count"] = cfg.MinCount
+	section["max_count"] = cfg.MaxCount
+
+	if len(cfg.Regions) > 0 {
+		regions := make(map[string]interface{}, len(cfg.Regions))
+		for code, region := range cfg.Regions {
+			settings := map[string]interface{}{
+				"min_count": region.MinCount,
+			}
+			if region.Weight > 0 {
+				settings["weight"] = region.Weight
+			}
+
+			regions[code] = settings
+		}
+
+		section["regions"] = regions
+	}
+
+	return section
+}
+
+// String returns a summary of cfg.
+func (cfg *Config) String() string {
+	if cfg.Strategy == StrategyDisabled {
+		return "disabled"
+	}
+
+	s := fmt.Sprintf("%s, min=%d max=%d", cfg.Strategy, cfg.MinCount, cfg.MaxCount)
+	for _, code := range cfg.regionCodes() {
+		s += fmt.Sprintf(" %s:min=%d", code, cfg.Regions[code].MinCount)
+	}
+
+	return s
+}
+
+func (cfg *Config) regionCodes() []string {
+	codes := make([]string, 0, len(cfg.Regions))
+	for code := range cfg.Regions {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes
+}
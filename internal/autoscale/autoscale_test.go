@@ -0,0 +1,123 @@
+package autoscale
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+)
+
+const sample = `
+app = "app"
+
+[autoscale]
+  strategy = "balanced"
+  min_count = 2
+  max_count = 10
+
+  [autoscale.regions.ord]
+    min_count = 1
+    weight = 2
+`
+
+func decode(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+
+	def := map[string]interface{}{}
+	_, err := toml.Decode(data, &def)
+	require.NoError(t, err)
+
+	return def
+}
+
+func TestFromDefinition(t *testing.T) {
+	cfg, err := FromDefinition(decode(t, sample))
+	require.NoError(t, err)
+
+	assert.Equal(t, &Config{
+		Strategy: StrategyBalanced,
+		MinCount: 2,
+		MaxCount: 10,
+		Regions: map[string]Region{
+			"ord": {MinCount: 1, Weight: 2},
+		},
+	}, cfg)
+
+	cfg, err = FromDefinition(map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+}
+
+func TestFromDefinitionInvalid(t *testing.T) {
+	cases := map[string]string{
+		`strategy = "eager"`:           "autoscale.strategy must be one of balanced, standard or disabled",
+		`min_count = 1`:                "autoscale.max_count must be set",
+		"min_count = 3\nmax_count = 2": "autoscale.min_count (3) exceeds autoscale.max_count (2)",
+		`max_count = "ten"`:            "autoscale.max_count: must be a number",
+		`size = 1`:                     "autoscale.size: unknown setting",
+		"max_count = 1\nregions = { ord = { min_count = 2 } }": "the min_count of the regions of autoscale add up to 2, which exceeds autoscale.max_count (1)",
+	}
+
+	for section, expected := range cases {
+		_, err := FromDefinition(decode(t, "[autoscale]\n"+section))
+		assert.EqualError(t, err, expected, section)
+	}
+}
+
+func TestTake(t *testing.T) {
+	def := decode(t, sample)
+
+	cfg, err := Take(def)
+	require.NoError(t, err)
+	assert.NotNil(t, cfg)
+	assert.NotContains(t, def, Section)
+}
+
+func TestInput(t *testing.T) {
+	cfg, err := FromDefinition(decode(t, sample))
+	require.NoError(t, err)
+
+	assert.Equal(t, api.UpdateAutoscaleConfigInput{
+		AppID:          "app",
+		Enabled:        api.BoolPointer(true),
+		MinCount:       api.IntPointer(2),
+		MaxCount:       api.IntPointer(10),
+		BalanceRegions: api.BoolPointer(true),
+		ResetRegions:   api.BoolPointer(true),
+		Regions: []api.AutoscaleRegionConfigInput{
+			{Code: "ord", MinCount: api.IntPointer(1), Weight: api.IntPointer(2)},
+		},
+	}, cfg.Input("app"))
+
+	disabled := &Config{Strategy: StrategyDisabled}
+	assert.Equal(t, api.UpdateAutoscaleConfigInput{
+		AppID:   "app",
+		Enabled: api.BoolPointer(false),
+	}, disabled.Input("app"))
+}
+
+func TestRoundTrip(t *testing.T) {
+	cfg := FromAPI(&api.AutoscalingConfig{
+		Enabled:  true,
+		MinCount: 1,
+		MaxCount: 4,
+		Regions: []api.AutoscalingRegionConfig{
+			{Code: "ams", MinCount: 1},
+		},
+	})
+
+	def := map[string]interface{}{Section: cfg.Section()}
+
+	var buf bytes.Buffer
+	require.NoError(t, toml.NewEncoder(&buf).Encode(def))
+
+	decoded, err := FromDefinition(decode(t, buf.String()))
+	require.NoError(t, err)
+	assert.Equal(t, cfg, decoded)
+
+	assert.Equal(t, &Config{Strategy: StrategyDisabled}, FromAPI(&api.AutoscalingConfig{}))
+}
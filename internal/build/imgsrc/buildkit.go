@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/docker/docker/api/types"
 	dockerclient "github.com/docker/docker/client"
@@ -17,6 +18,7 @@ import (
 	buildkitClient "github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/auth"
+	"github.com/moby/buildkit/session/sshforward/sshprovider"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"github.com/superfly/flyctl/flyctl"
@@ -137,6 +139,44 @@ func (t *tracer) write(msg jsonmessage.JSONMessage) {
 	t.displayCh <- &s
 }
 
+// newBuildkitSSHProvider returns the session attachable which forwards the ssh
+// agents or keys the given specs denote to the build. Specs follow the
+// default|<id>[=<socket>|<key>[,<key>]] format of docker build --ssh.
+func newBuildkitSSHProvider(specs []string) (session.Attachable, error) {
+	configs, err := parseSSHSpecs(specs)
+	if err != nil {
+		return nil, err
+	}
+
+	return sshprovider.NewSSHAgentProvider(configs)
+}
+
+func parseSSHSpecs(specs []string) ([]sshprovider.AgentConfig, error) {
+	configs := make([]sshprovider.AgentConfig, 0, len(specs))
+
+	for _, spec := range specs {
+		id, paths := spec, ""
+		if i := strings.Index(spec, "="); i >= 0 {
+			id, paths = spec[:i], spec[i+1:]
+		}
+
+		if id == "" {
+			return nil, fmt.Errorf("invalid ssh spec %q: missing id", spec)
+		}
+
+		cfg := sshprovider.AgentConfig{
+			ID: id,
+		}
+		if paths != "" {
+			cfg.Paths = strings.Split(paths, ",")
+		}
+
+		configs = append(configs, cfg)
+	}
+
+	return configs, nil
+}
+
 func newBuildkitAuthProvider() session.Attachable {
 	return &buildkitAuthProvider{}
 }
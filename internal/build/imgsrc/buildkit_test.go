@@ -0,0 +1,25 @@
+package imgsrc
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/session/sshforward/sshprovider"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSSHSpecs(t *testing.T) {
+	configs, err := parseSSHSpecs([]string{
+		"default",
+		"github=/tmp/agent.sock",
+		"keys=id_rsa,id_ed25519",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []sshprovider.AgentConfig{
+		{ID: "default"},
+		{ID: "github", Paths: []string{"/tmp/agent.sock"}},
+		{ID: "keys", Paths: []string{"id_rsa", "id_ed25519"}},
+	}, configs)
+
+	_, err = parseSSHSpecs([]string{"=/tmp/agent.sock"})
+	assert.Error(t, err)
+}
@@ -29,6 +29,10 @@ func (*buildpacksBuilder) Run(ctx context.Context, dockerFactory *dockerClientFa
 		return nil, nil
 	}
 
+	if len(opts.SSH) > 0 {
+		return nil, errSSHRequiresBuildKit
+	}
+
 	builder := opts.Builder
 	buildpacks := opts.Buildpacks
 
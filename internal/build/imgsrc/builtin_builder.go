@@ -29,6 +29,10 @@ func (ds *builtinBuilder) Run(ctx context.Context, dockerFactory *dockerClientFa
 		return nil, nil
 	}
 
+	if len(opts.SSH) > 0 {
+		return nil, errSSHRequiresBuildKit
+	}
+
 	builtin, err := builtins.GetBuiltin(opts.BuiltIn)
 	if err != nil {
 		return nil, err
@@ -69,6 +73,8 @@ func (ds *builtinBuilder) Run(ctx context.Context, dockerFactory *dockerClientFa
 	}
 	cmdfmt.PrintDone(streams.ErrOut, "Creating build context done")
 
+	r = limitUpload(ctx, r, opts.UploadLimit)
+
 	var imageID string
 
 	serverInfo, err := docker.Info(ctx)
@@ -80,11 +86,14 @@ func (ds *builtinBuilder) Run(ctx context.Context, dockerFactory *dockerClientFa
 	msg := fmt.Sprintf("docker host: %s %s %s", serverInfo.ServerVersion, serverInfo.OSType, serverInfo.Architecture)
 	cmdfmt.PrintDone(streams.ErrOut, msg)
 
-	buildArgs := normalizeBuildArgsForDocker(opts.BuildArgs)
+	buildArgs := normalizeBuildArgsForDocker(withProxyBuildArgs(dockerFactory.mode, opts.BuildArgs))
+
+	buildCtx, wd := watchBuild(ctx, dockerFactory.mode, opts)
+	defer wd.Stop()
 
-	imageID, err = runClassicBuild(ctx, streams, docker, r, opts, "", buildArgs)
+	imageID, err = runClassicBuild(buildCtx, streams, docker, r, opts, "", buildArgs, wd)
 	if err != nil {
-		return nil, errors.Wrap(err, "error building")
+		return nil, errors.Wrap(wd.Err(ctx, docker, err), "error building")
 	}
 
 	cmdfmt.PrintDone(streams.ErrOut, "Building image done")
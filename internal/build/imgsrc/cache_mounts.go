@@ -0,0 +1,96 @@
+package imgsrc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// DefaultCachePaths denotes the paths dependency caches are mounted at when
+// none are configured: the download caches of pip, yarn and Go (~/.cache), npm
+// and bundler.
+var DefaultCachePaths = []string{"~/.cache", "~/.npm", "~/.bundle/cache"}
+
+// installTargets denotes the names of the directories package managers install
+// dependencies into. Since cache mounts aren't part of the image, dependencies
+// installed into them would be missing from it.
+var installTargets = map[string]struct{}{
+	"node_modules":  {},
+	"vendor":        {},
+	"vendor/bundle": {},
+	"site-packages": {},
+}
+
+var runInstruction = regexp.MustCompile(`(?i)^(\s*RUN)(\s+)`)
+
+// cacheMountTarget returns the absolute or WORKDIR relative path the given
+// cache path denotes; ~ denotes the home directory of root, which builds run
+// as unless told otherwise. Paths dependencies are installed into, such as
+// node_modules, are rejected.
+func cacheMountTarget(p string) (string, error) {
+	if p == "" || strings.ContainsAny(p, ", \t") {
+		return "", fmt.Errorf("invalid cache path %q", p)
+	}
+
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		p = "/root" + p[1:]
+	}
+	p = path.Clean(p)
+
+	for target := range installTargets {
+		if p == target || strings.HasSuffix(p, "/"+target) {
+			return "", fmt.Errorf("cache path %q is where dependencies are installed, which would leave them out of the image; mount the download cache of the package manager instead", p)
+		}
+	}
+
+	return p, nil
+}
+
+// addCacheMounts returns the given Dockerfile with the RUN instructions it
+// contains mounting BuildKit cache mounts at the given paths. The mounts are
+// keyed by app so that they outlive builds on the same builder. Their contents
+// are left out of the image, which is why only download caches, from which
+// package managers install into the image, may be mounted.
+func addCacheMounts(dockerfile []byte, appName string, paths []string) ([]byte, error) {
+	var mounts strings.Builder
+	for _, p := range paths {
+		target, err := cacheMountTarget(p)
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Fprintf(&mounts, "--mount=type=cache,id=%s:%s,target=%s,sharing=locked ", appName, target, target)
+	}
+
+	var (
+		out          bytes.Buffer
+		continuation bool
+	)
+
+	s := bufio.NewScanner(bytes.NewReader(dockerfile))
+	for s.Scan() {
+		line := s.Text()
+
+		// lines continuing an instruction are never instructions themselves
+		if !continuation {
+			line = runInstruction.ReplaceAllString(line, "${1}${2}"+mounts.String())
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "#") {
+			continuation = strings.HasSuffix(trimmed, `\`)
+		}
+
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
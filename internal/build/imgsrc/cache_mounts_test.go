@@ -0,0 +1,47 @@
+package imgsrc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddCacheMounts(t *testing.T) {
+	const dockerfile = `FROM ruby:3
+# RUN this is a comment
+RUN bundle install
+run apt-get update && \
+    RUN-like continuation
+COPY . .
+`
+
+	out, err := addCacheMounts([]byte(dockerfile), "my-app", []string{"~/.cache", "~/.bundle/cache/"})
+	require.NoError(t, err)
+
+	const mounts = "--mount=type=cache,id=my-app:/root/.cache,target=/root/.cache,sharing=locked " +
+		"--mount=type=cache,id=my-app:/root/.bundle/cache,target=/root/.bundle/cache,sharing=locked "
+
+	assert.Equal(t, `FROM ruby:3
+# RUN this is a comment
+RUN `+mounts+`bundle install
+run `+mounts+`apt-get update && \
+    RUN-like continuation
+COPY . .
+`, string(out))
+
+	_, err = addCacheMounts([]byte(dockerfile), "my-app", []string{"a,b"})
+	assert.Error(t, err)
+}
+
+func TestCacheMountTargetRejectsInstallTargets(t *testing.T) {
+	for _, p := range []string{"node_modules", "/app/node_modules/", "vendor/bundle", "./vendor"} {
+		_, err := cacheMountTarget(p)
+		assert.Error(t, err, p)
+	}
+
+	for _, p := range []string{"~/.npm", "~/.cache/yarn", "/usr/local/bundle/cache"} {
+		_, err := cacheMountTarget(p)
+		assert.NoError(t, err, p)
+	}
+}
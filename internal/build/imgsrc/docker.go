@@ -20,15 +20,22 @@ import (
 	"github.com/superfly/flyctl/flyctl"
 	"github.com/superfly/flyctl/helpers"
 	"github.com/superfly/flyctl/internal/sentry"
+	"github.com/superfly/flyctl/internal/tracing"
 	"github.com/superfly/flyctl/pkg/agent"
 	"github.com/superfly/flyctl/pkg/builder"
 	"github.com/superfly/flyctl/pkg/iostreams"
 	"github.com/superfly/flyctl/terminal"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type dockerClientFactory struct {
 	mode    DockerDaemonType
 	buildFn func(ctx context.Context) (*dockerclient.Client, error)
+
+	// selfHosted denotes the self-hosted builder remote builds run on, if
+	// any. It's reset once the builder fails its health check and builds
+	// fail over to the Fly-managed builder.
+	selfHosted *SelfHostedBuilder
 }
 
 func newDockerClientFactory(daemonType DockerDaemonType, apiClient *api.Client, appName string, streams *iostreams.IOStreams) *dockerClientFactory {
@@ -53,20 +60,37 @@ func newDockerClientFactory(daemonType DockerDaemonType, apiClient *api.Client,
 		terminal.Debug("trying remote docker daemon")
 		var cachedDocker *dockerclient.Client
 
-		return &dockerClientFactory{
+		f := &dockerClientFactory{
 			mode: DockerDaemonTypeRemote,
-			buildFn: func(ctx context.Context) (*dockerclient.Client, error) {
-				if cachedDocker != nil {
+		}
+		f.buildFn = func(ctx context.Context) (*dockerclient.Client, error) {
+			if cachedDocker != nil {
+				return cachedDocker, nil
+			}
+
+			if b := f.selfHosted; b != nil {
+				c, err := newSelfHostedDockerClient(ctx, apiClient, b, streams)
+				switch {
+				case err == nil:
+					cachedDocker = c
 					return cachedDocker, nil
-				}
-				c, err := newRemoteDockerClient(ctx, apiClient, appName, streams)
-				if err != nil {
+				case b.NoFailover:
 					return nil, err
 				}
-				cachedDocker = c
-				return cachedDocker, nil
-			},
+
+				terminal.Warnf("%v; falling back to the Fly-managed builder\n", err)
+				f.selfHosted = nil
+			}
+
+			c, err := newRemoteDockerClient(ctx, apiClient, appName, streams)
+			if err != nil {
+				return nil, err
+			}
+			cachedDocker = c
+			return cachedDocker, nil
 		}
+
+		return f
 	}
 
 	return &dockerClientFactory{
@@ -141,12 +165,14 @@ func NewLocalDockerClient() (*dockerclient.Client, error) {
 	return c, nil
 }
 
-func newRemoteDockerClient(ctx context.Context, apiClient *api.Client, appName string, streams *iostreams.IOStreams) (*dockerclient.Client, error) {
+func newRemoteDockerClient(ctx context.Context, apiClient *api.Client, appName string, streams *iostreams.IOStreams) (_ *dockerclient.Client, err error) {
+	ctx, span := tracing.Start(ctx, "imgsrc.remote_builder")
+	defer func() { tracing.End(span, err) }()
+
 	startedAt := time.Now()
 
 	var host string
 	var app *api.App
-	var err error
 	var machine *api.Machine
 	machine, app, err = builder.RemoteBuilderMachine(ctx, apiClient, appName)
 	if err != nil {
@@ -154,6 +180,7 @@ func newRemoteDockerClient(ctx context.Context, apiClient *api.Client, appName s
 	}
 	remoteBuilderAppName := app.Name
 	remoteBuilderOrg := app.Organization.Slug
+	span.SetAttributes(attribute.String("builder.app", remoteBuilderAppName))
 
 	if host != "" {
 		terminal.Debugf("Remote Docker builder host: %s\n", host)
@@ -261,21 +288,32 @@ func buildRemoteClientOpts(ctx context.Context, apiClient *api.Client, appName,
 		return nil, fmt.Errorf("error fetching target app: %w", err)
 	}
 
-	var agentclient *agent.Client
-	if agentclient, err = agent.Establish(ctx, apiClient); err != nil {
-		return
+	var dial dockerclient.Opt
+	if dial, err = orgDialOpt(ctx, apiClient, app.Organization.Slug); err == nil {
+		opts = append(opts, dial)
 	}
 
-	var dialer agent.Dialer
-	if dialer, err = agentclient.Dialer(ctx, app.Organization.Slug); err != nil {
-		return
+	return
+}
+
+// orgDialOpt returns the option which has the Docker client dial through the
+// WireGuard tunnel of the given organization.
+func orgDialOpt(ctx context.Context, apiClient *api.Client, orgSlug string) (dockerclient.Opt, error) {
+	agentclient, err := agent.Establish(ctx, apiClient)
+	if err != nil {
+		return nil, err
 	}
 
-	if err = agentclient.WaitForTunnel(ctx, app.Organization.Slug); err == nil {
-		opts = append(opts, dockerclient.WithDialContext(dialer.DialContext))
+	dialer, err := agentclient.Dialer(ctx, orgSlug)
+	if err != nil {
+		return nil, err
 	}
 
-	return
+	if err := agentclient.WaitForTunnel(ctx, orgSlug); err != nil {
+		return nil, err
+	}
+
+	return dockerclient.WithDialContext(dialer.DialContext), nil
 }
 
 func waitForDaemon(parent context.Context, client *dockerclient.Client) (up bool, err error) {
@@ -24,8 +24,11 @@ import (
 	"github.com/pkg/errors"
 	"github.com/superfly/flyctl/helpers"
 	"github.com/superfly/flyctl/internal/cmdfmt"
+	"github.com/superfly/flyctl/internal/netproxy"
+	"github.com/superfly/flyctl/internal/tracing"
 	"github.com/superfly/flyctl/pkg/iostreams"
 	"github.com/superfly/flyctl/terminal"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -115,6 +118,25 @@ func (ds *dockerfileBuilder) Run(ctx context.Context, dockerFactory *dockerClien
 		relativedockerfilePath = p
 	}
 
+	if len(opts.CachePaths) > 0 {
+		dockerfileData, err := os.ReadFile(dockerfile)
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading Dockerfile")
+		}
+
+		if dockerfileData, err = addCacheMounts(dockerfileData, opts.AppName, opts.CachePaths); err != nil {
+			return nil, err
+		}
+
+		name := "Dockerfile"
+		if relativedockerfilePath != "" {
+			name = filepath.ToSlash(relativedockerfilePath)
+		}
+		archiveOpts.additions = map[string][]byte{
+			name: dockerfileData,
+		}
+	}
+
 	// Start tracking this build
 
 	// Create the docker build context as a compressed tar stream
@@ -124,6 +146,8 @@ func (ds *dockerfileBuilder) Run(ctx context.Context, dockerFactory *dockerClien
 	}
 	cmdfmt.PrintDone(streams.ErrOut, "Creating build context done")
 
+	r = limitUpload(ctx, r, opts.UploadLimit)
+
 	// Setup an upload progress bar
 	progressOutput := streamformatter.NewProgressOutput(streams.Out)
 	if !streams.IsStdoutTTY() {
@@ -144,26 +168,46 @@ func (ds *dockerfileBuilder) Run(ctx context.Context, dockerFactory *dockerClien
 		return nil, errors.Wrap(err, "error fetching docker server info")
 	}
 
+	// builds share remote builders, which may limit how many run at once
+	if dockerFactory.mode.IsRemote() {
+		done, err := waitForBuildSlot(ctx, docker, streams, opts.AppName)
+		if err != nil {
+			return nil, err
+		}
+		defer done()
+	}
+
 	cmdfmt.PrintBegin(streams.ErrOut, "Building image with Docker")
 	msg := fmt.Sprintf("docker host: %s %s %s", serverInfo.ServerVersion, serverInfo.OSType, serverInfo.Architecture)
 	cmdfmt.PrintDone(streams.ErrOut, msg)
 
-	buildArgs := normalizeBuildArgsForDocker(opts.BuildArgs)
+	buildArgs := normalizeBuildArgsForDocker(withProxyBuildArgs(dockerFactory.mode, opts.BuildArgs))
 
 	buildkitEnabled, err := buildkitEnabled(docker)
 	terminal.Debugf("buildkitEnabled", buildkitEnabled)
 	if err != nil {
 		return nil, errors.Wrap(err, "error checking for buildkit support")
 	}
+	if !buildkitEnabled && len(opts.SSH) > 0 {
+		return nil, errSSHRequiresBuildKit
+	}
+	if !buildkitEnabled && len(opts.CachePaths) > 0 {
+		return nil, errCacheRequiresBuildKit
+	}
+
+	// remote builds which go silent are cancelled rather than left hanging
+	buildCtx, wd := watchBuild(ctx, dockerFactory.mode, opts)
+	defer wd.Stop()
+
 	if buildkitEnabled {
-		imageID, err = runBuildKitBuild(ctx, streams, docker, r, opts, relativedockerfilePath, buildArgs)
+		imageID, err = runBuildKitBuild(buildCtx, streams, docker, r, opts, relativedockerfilePath, buildArgs, wd)
 		if err != nil {
-			return nil, errors.Wrap(err, "error building")
+			return nil, errors.Wrap(wd.Err(ctx, docker, err), "error building")
 		}
 	} else {
-		imageID, err = runClassicBuild(ctx, streams, docker, r, opts, relativedockerfilePath, buildArgs)
+		imageID, err = runClassicBuild(buildCtx, streams, docker, r, opts, relativedockerfilePath, buildArgs, wd)
 		if err != nil {
-			return nil, errors.Wrap(err, "error building")
+			return nil, errors.Wrap(wd.Err(ctx, docker, err), "error building")
 		}
 	}
 
@@ -203,7 +247,24 @@ func normalizeBuildArgsForDocker(buildArgs map[string]string) map[string]*string
 	return out
 }
 
-func runClassicBuild(ctx context.Context, streams *iostreams.IOStreams, docker *dockerclient.Client, r io.ReadCloser, opts ImageOptions, dockerfilePath string, buildArgs map[string]*string) (imageID string, err error) {
+// withProxyBuildArgs adds the proxy settings of the environment to the given
+// build arguments of builds on local daemons, like the Docker CLI does, so that
+// builds behind proxies can reach the network. Remote builders run on Fly and
+// don't need them. Build arguments set explicitly take precedence.
+func withProxyBuildArgs(mode DockerDaemonType, buildArgs map[string]string) map[string]string {
+	if !mode.IsLocal() {
+		return buildArgs
+	}
+
+	args := netproxy.BuildArgs()
+	for k, v := range buildArgs {
+		args[k] = v
+	}
+
+	return args
+}
+
+func runClassicBuild(ctx context.Context, streams *iostreams.IOStreams, docker *dockerclient.Client, r io.ReadCloser, opts ImageOptions, dockerfilePath string, buildArgs map[string]*string, wd *watchdog) (imageID string, err error) {
 	options := types.ImageBuildOptions{
 		Tags:        []string{opts.Tag},
 		BuildArgs:   buildArgs,
@@ -220,6 +281,8 @@ func runClassicBuild(ctx context.Context, streams *iostreams.IOStreams, docker *
 	}
 	defer resp.Body.Close()
 
+	body := wd.Reader(resp.Body)
+
 	idCallback := func(m jsonmessage.JSONMessage) {
 		var aux types.BuildResult
 		if err := json.Unmarshal(*m.Aux, &aux); err != nil {
@@ -228,7 +291,19 @@ func runClassicBuild(ctx context.Context, streams *iostreams.IOStreams, docker *
 		imageID = aux.ID
 	}
 
-	if err := jsonmessage.DisplayJSONMessagesStream(resp.Body, streams.ErrOut, streams.StderrFd(), streams.IsStderrTTY(), idCallback); err != nil {
+	// quiet builds only output the build log in case of failure
+	if streams.IsQuiet() {
+		var buildLog bytes.Buffer
+		if err := jsonmessage.DisplayJSONMessagesStream(body, &buildLog, 0, false, idCallback); err != nil {
+			_, _ = buildLog.WriteTo(streams.ErrOut)
+
+			return "", errors.Wrap(err, "error rendering build status stream")
+		}
+
+		return imageID, nil
+	}
+
+	if err := jsonmessage.DisplayJSONMessagesStream(body, streams.ErrOut, streams.StderrFd(), streams.IsStderrTTY(), idCallback); err != nil {
 		return "", errors.Wrap(err, "error rendering build status stream")
 	}
 
@@ -237,7 +312,7 @@ func runClassicBuild(ctx context.Context, streams *iostreams.IOStreams, docker *
 
 const uploadRequestRemote = "upload-request"
 
-func runBuildKitBuild(ctx context.Context, streams *iostreams.IOStreams, docker *dockerclient.Client, r io.ReadCloser, opts ImageOptions, dockerfilePath string, buildArgs map[string]*string) (imageID string, err error) {
+func runBuildKitBuild(ctx context.Context, streams *iostreams.IOStreams, docker *dockerclient.Client, r io.ReadCloser, opts ImageOptions, dockerfilePath string, buildArgs map[string]*string, wd *watchdog) (imageID string, err error) {
 	s, err := createBuildSession(opts.WorkingDir)
 	if err != nil {
 		panic(err)
@@ -248,8 +323,19 @@ func runBuildKitBuild(ctx context.Context, streams *iostreams.IOStreams, docker
 		panic("buildkit not supported")
 	}
 
+	// the agent is only reachable via the session, for the lifetime of the build
+	if len(opts.SSH) > 0 {
+		sshProvider, err := newBuildkitSSHProvider(opts.SSH)
+		if err != nil {
+			return "", errors.Wrap(err, "error forwarding ssh agent")
+		}
+		s.Allow(sshProvider)
+	}
+
 	eg, errCtx := errgroup.WithContext(ctx)
 
+	plainBuildOutput := bytes.NewBuffer(nil)
+
 	dialSession := func(ctx context.Context, proto string, meta map[string][]string) (net.Conn, error) {
 		return docker.DialHijack(errCtx, "/session", proto, meta)
 	}
@@ -312,7 +398,8 @@ func runBuildKitBuild(ctx context.Context, streams *iostreams.IOStreams, docker
 			termFd, isTerm := term.GetFdInfo(os.Stderr)
 			tracer := newTracer()
 			var c2 console.Console
-			if isTerm {
+			// verbose builds output the plain, full build log
+			if isTerm && !streams.IsVerbose() {
 				if cons, err := console.ConsoleFromFile(os.Stderr); err == nil {
 					c2 = cons
 				}
@@ -333,11 +420,18 @@ func runBuildKitBuild(ctx context.Context, streams *iostreams.IOStreams, docker
 			})
 
 			eg.Go(func() error {
+				// quiet builds drain the progress and only output the build log
+				// in case of failure
+				if streams.IsQuiet() {
+					for range consoleLogs {
+					}
+
+					return nil
+				}
+
 				return progressui.DisplaySolveStatus(context.TODO(), "", c2, os.Stderr, consoleLogs)
 			})
 
-			plainBuildOutput := bytes.NewBuffer(nil)
-
 			eg.Go(func() error {
 				return progressui.DisplaySolveStatus(context.TODO(), "", nil, plainBuildOutput, plainLogs)
 			})
@@ -358,11 +452,11 @@ func runBuildKitBuild(ctx context.Context, streams *iostreams.IOStreams, docker
 
 			buf := bytes.NewBuffer(nil)
 
-			if err := jsonmessage.DisplayJSONMessagesStream(resp.Body, buf, termFd, isTerm, auxCallback); err != nil {
+			if err := jsonmessage.DisplayJSONMessagesStream(wd.Reader(resp.Body), buf, termFd, isTerm, auxCallback); err != nil {
 				return err
 			}
 
-			if os.Getenv("LOG_LEVEL") == "debug" {
+			if os.Getenv("LOG_LEVEL") == "debug" || streams.Verbosity() >= iostreams.VerbosityDebug {
 				f, err := os.OpenFile("build.log", os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
 				if err != nil {
 					return err
@@ -377,13 +471,22 @@ func runBuildKitBuild(ctx context.Context, streams *iostreams.IOStreams, docker
 	})
 
 	if err := eg.Wait(); err != nil {
+		// quiet builds only output the build log in case of failure
+		if streams.IsQuiet() {
+			_, _ = plainBuildOutput.WriteTo(streams.ErrOut)
+		}
+
 		return "", err
 	}
 
 	return imageID, nil
 }
 
-func pushToFly(ctx context.Context, docker *dockerclient.Client, streams *iostreams.IOStreams, tag string) error {
+// pushImage makes a single attempt at pushing the image tag names.
+func pushImage(ctx context.Context, docker *dockerclient.Client, streams *iostreams.IOStreams, tag string) (err error) {
+	ctx, span := tracing.Start(ctx, "imgsrc.push", attribute.String("image.tag", tag))
+	defer func() { tracing.End(span, err) }()
+
 	pushResp, err := docker.ImagePush(ctx, tag, types.ImagePushOptions{
 		RegistryAuth: flyRegistryAuth(),
 	})
@@ -392,7 +495,13 @@ func pushToFly(ctx context.Context, docker *dockerclient.Client, streams *iostre
 	}
 	defer pushResp.Close()
 
-	err = jsonmessage.DisplayJSONMessagesStream(pushResp, streams.ErrOut, streams.StderrFd(), streams.IsStderrTTY(), nil)
+	// quiet pushes only report errors
+	var out io.Writer = streams.ErrOut
+	if streams.IsQuiet() {
+		out = io.Discard
+	}
+
+	err = jsonmessage.DisplayJSONMessagesStream(pushResp, out, streams.StderrFd(), streams.IsStderrTTY() && !streams.IsQuiet(), nil)
 	if err != nil {
 		var msgerr *jsonmessage.JSONError
 
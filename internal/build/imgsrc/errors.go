@@ -1,6 +1,10 @@
 package imgsrc
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 type RegistryUnauthorizedError struct {
 	Tag string
@@ -9,3 +13,29 @@ type RegistryUnauthorizedError struct {
 func (err *RegistryUnauthorizedError) Error() string {
 	return fmt.Sprintf("you are not authorized to push \"%s\"", err.Tag)
 }
+
+// errSSHRequiresBuildKit is returned when ssh forwarding is requested for a
+// build which doesn't run on BuildKit.
+var errSSHRequiresBuildKit = errors.New("ssh forwarding is only supported for Dockerfile builds running on BuildKit")
+
+// errCacheRequiresBuildKit is returned when dependency caching is requested
+// for a build which doesn't run on BuildKit.
+var errCacheRequiresBuildKit = errors.New("dependency caching is only supported for Dockerfile builds running on BuildKit")
+
+// BuildStalledError is returned by remote builds which produced no output
+// for longer than the stall timeout of their ImageOptions.
+type BuildStalledError struct {
+	Timeout time.Duration
+	// BuilderResponding reports whether the builder's docker daemon still
+	// answered pings once the build stalled.
+	BuilderResponding bool
+}
+
+func (err *BuildStalledError) Error() string {
+	state := "the builder is not responding"
+	if err.BuilderResponding {
+		state = "the builder is still responding"
+	}
+
+	return fmt.Sprintf("the build produced no output for %s and was cancelled; %s", err.Timeout, state)
+}
@@ -0,0 +1,94 @@
+package imgsrc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	dockerclient "github.com/docker/docker/client"
+	"github.com/jpillora/backoff"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+)
+
+// pushAttempts is the number of times pushes interrupted by the network are
+// attempted.
+const pushAttempts = 5
+
+// pushRetryDelay is the delay before the first retry of interrupted pushes;
+// later retries back off from it.
+var pushRetryDelay = 2 * time.Second
+
+// pushToFly pushes the image tag names to the Fly registry. Pushes the network
+// interrupts are retried with backoff; since the registry keeps the layers
+// which were pushed in full, retries only push the rest.
+func pushToFly(ctx context.Context, docker *dockerclient.Client, streams *iostreams.IOStreams, tag string) error {
+	return retryInterruptedPush(ctx, streams, func() error {
+		return pushImage(ctx, docker, streams, tag)
+	})
+}
+
+func retryInterruptedPush(ctx context.Context, streams *iostreams.IOStreams, push func() error) (err error) {
+	b := &backoff.Backoff{
+		Min:    pushRetryDelay,
+		Max:    30 * time.Second,
+		Factor: 2,
+		Jitter: true,
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err = push(); err == nil || ctx.Err() != nil || !isInterruptedPush(err) || attempt == pushAttempts {
+			return
+		}
+
+		wait := b.Duration()
+		fmt.Fprintf(streams.ErrOut, "Push interrupted: %v\nRetrying in %s; layers which were pushed are skipped (attempt %d of %d)\n",
+			err, wait.Round(time.Second), attempt+1, pushAttempts)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// interruptedPushErrors are the fragments of the errors of pushes the network
+// interrupted.
+var interruptedPushErrors = []string{
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"unexpected eof",
+	"i/o timeout",
+	"tls handshake timeout",
+	"timeout awaiting response headers",
+	"network is unreachable",
+	"no such host",
+	"blob upload unknown",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+}
+
+func isInterruptedPush(err error) bool {
+	var unauthorized *RegistryUnauthorizedError
+	if errors.As(err, &unauthorized) {
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	if strings.HasSuffix(msg, ": eof") || msg == "eof" {
+		return true
+	}
+
+	for _, fragment := range interruptedPushErrors {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
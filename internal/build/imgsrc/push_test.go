@@ -0,0 +1,52 @@
+package imgsrc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+)
+
+func TestIsInterruptedPush(t *testing.T) {
+	cases := map[error]bool{
+		errors.New("error rendering push status stream: read tcp 10.0.0.2:5000: connection reset by peer"): true,
+		errors.New("error pushing image to registry: unexpected EOF"):                                      true,
+		errors.New("error rendering push status stream: EOF"):                                              true,
+		errors.New("received unexpected HTTP status: 503 Service Unavailable"):                             true,
+		errors.New("manifest invalid"):                                                                     false,
+		&RegistryUnauthorizedError{Tag: "registry.fly.io/app:deployment-1"}:                                false,
+	}
+
+	for err, expected := range cases {
+		assert.Equal(t, expected, isInterruptedPush(err), err.Error())
+	}
+}
+
+func TestRetryInterruptedPush(t *testing.T) {
+	defer func(delay time.Duration) { pushRetryDelay = delay }(pushRetryDelay)
+	pushRetryDelay = time.Millisecond
+
+	streams, _, _, _ := iostreams.Test()
+
+	var attempts int
+	err := retryInterruptedPush(context.Background(), streams, func() error {
+		if attempts++; attempts == 1 {
+			return errors.New("broken pipe")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+
+	attempts = 0
+	err = retryInterruptedPush(context.Background(), streams, func() error {
+		attempts++
+		return errors.New("manifest invalid")
+	})
+	assert.EqualError(t, err, "manifest invalid")
+	assert.Equal(t, 1, attempts)
+}
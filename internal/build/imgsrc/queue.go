@@ -0,0 +1,602 @@
+package imgsrc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azazeal/pause"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	volumetypes "github.com/docker/docker/api/types/volume"
+	dockerclient "github.com/docker/docker/client"
+
+	"github.com/superfly/flyctl/internal/cmdfmt"
+	"github.com/superfly/flyctl/pkg/iostreams"
+	"github.com/superfly/flyctl/terminal"
+)
+
+// Remote builders are shared by the builds of the apps of an organization.
+// Builds which find as many others running on their builder as the queue
+// settings of the builder allow wait for their turn, in the order they
+// arrived in, save for the ones of priority apps which jump the queue.
+//
+// The queue is kept on the builder itself, as Docker volumes: settings, stats
+// and entries are labels of volumes, and each build keeps its entry alive by
+// replacing the entry's volume every queueHeartbeat. Entries which go
+// unreplaced for queueStaleAfter, such as those of builds which crashed, are
+// dropped. Stats are kept apart from the settings so that the builds which
+// record them never write over settings admins change. Admission is best
+// effort; builds which check the queue at the same time may exceed the
+// concurrency briefly.
+const (
+	queueLabel        = "fly.build-queue"
+	queueEntryKind    = "entry"
+	queueSettingsKind = "settings"
+	queueStatsKind    = "stats"
+
+	queueHeartbeat  = 10 * time.Second
+	queueStaleAfter = 45 * time.Second
+	queuePoll       = 5 * time.Second
+)
+
+// BuildQueueSettings wraps the queue settings of a builder.
+type BuildQueueSettings struct {
+	// Concurrency denotes the number of builds which may run at once. Zero
+	// denotes no limit, in which case builds never queue.
+	Concurrency int `json:"concurrency"`
+
+	// PriorityApps denotes the apps the builds of which jump the queue, such
+	// as production ones.
+	PriorityApps []string `json:"priority_apps"`
+
+	// AverageBuild denotes the moving average of the duration of the builds
+	// which ran, used to estimate waits. It's read from the stats of the queue
+	// and never written by SetSettings.
+	AverageBuild time.Duration `json:"average_build"`
+}
+
+func (s *BuildQueueSettings) isPriority(appName string) bool {
+	for _, name := range s.PriorityApps {
+		if name == appName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BuildQueueEntry wraps a build which is either queued or running.
+type BuildQueueEntry struct {
+	ID       string    `json:"id"`
+	App      string    `json:"app"`
+	Priority bool      `json:"priority"`
+	Building bool      `json:"building"`
+	Enqueued time.Time `json:"enqueued"`
+	Started  time.Time `json:"started,omitempty"`
+}
+
+// BuildQueue is the build queue of a builder.
+type BuildQueue struct {
+	docker *dockerclient.Client
+}
+
+// BuildQueue returns the build queue of the builder remote builds run on.
+func (r *Resolver) BuildQueue(ctx context.Context) (*BuildQueue, error) {
+	if !r.dockerFactory.mode.IsRemote() {
+		return nil, fmt.Errorf("builds of %s do not run on a remote builder", r.appName)
+	}
+
+	docker, err := r.dockerFactory.buildFn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BuildQueue{docker: docker}, nil
+}
+
+func (q *BuildQueue) volumes(ctx context.Context, kind string) ([]*types.Volume, error) {
+	res, err := q.docker.VolumeList(ctx, filters.NewArgs(filters.Arg("label", queueLabel+"="+kind)))
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Volumes, nil
+}
+
+// Settings returns the queue settings of the builder.
+func (q *BuildQueue) Settings(ctx context.Context) (s BuildQueueSettings, err error) {
+	volumes, err := q.volumes(ctx, queueSettingsKind)
+	if err != nil {
+		return s, fmt.Errorf("failed reading build queue settings: %w", err)
+	}
+
+	s, _ = settingsFrom(volumes)
+
+	if average, ok, err := q.averageBuild(ctx); err != nil {
+		return s, err
+	} else if ok {
+		s.AverageBuild = average
+	}
+
+	return s, nil
+}
+
+// SetSettings replaces the queue settings of the builder, save for the
+// average build duration, which is part of the stats of the queue.
+func (q *BuildQueue) SetSettings(ctx context.Context, s BuildQueueSettings) error {
+	name := fmt.Sprintf("fly-build-queue-settings-%d", time.Now().UnixNano())
+
+	if err := q.replace(ctx, queueSettingsKind, name, settingsLabels(s)); err != nil {
+		return fmt.Errorf("failed writing build queue settings: %w", err)
+	}
+
+	return nil
+}
+
+// averageBuild returns the average build duration the stats of the queue
+// hold, if any.
+func (q *BuildQueue) averageBuild(ctx context.Context) (time.Duration, bool, error) {
+	volumes, err := q.volumes(ctx, queueStatsKind)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed reading build queue stats: %w", err)
+	}
+
+	average, ok := averageFrom(volumes)
+
+	return average, ok, nil
+}
+
+// replace writes the named volume of the given kind and removes the ones it
+// supersedes.
+func (q *BuildQueue) replace(ctx context.Context, kind, name string, labels map[string]string) error {
+	previous, err := q.volumes(ctx, kind)
+	if err != nil {
+		return err
+	}
+
+	if _, err := q.docker.VolumeCreate(ctx, volumetypes.VolumeCreateBody{Name: name, Labels: labels}); err != nil {
+		return err
+	}
+
+	for _, v := range previous {
+		_ = q.docker.VolumeRemove(ctx, v.Name, true)
+	}
+
+	return nil
+}
+
+// Entries returns the builds on the builder in the order they run in,
+// dropping the entries of builds which went away.
+func (q *BuildQueue) Entries(ctx context.Context) ([]BuildQueueEntry, error) {
+	info, err := q.docker.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching builder time: %w", err)
+	}
+
+	now, err := time.Parse(time.RFC3339Nano, info.SystemTime)
+	if err != nil {
+		now = time.Now()
+	}
+
+	volumes, err := q.volumes(ctx, queueEntryKind)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading build queue: %w", err)
+	}
+
+	entries, stale := entriesFrom(volumes, now)
+	for _, name := range stale {
+		_ = q.docker.VolumeRemove(ctx, name, true)
+	}
+
+	return entries, nil
+}
+
+func settingsLabels(s BuildQueueSettings) map[string]string {
+	return map[string]string{
+		queueLabel:                    queueSettingsKind,
+		queueLabel + ".concurrency":   strconv.Itoa(s.Concurrency),
+		queueLabel + ".priority-apps": strings.Join(s.PriorityApps, ","),
+	}
+}
+
+func statsLabels(average time.Duration) map[string]string {
+	return map[string]string{
+		queueLabel:                    queueStatsKind,
+		queueLabel + ".average-build": strconv.FormatInt(int64(average/time.Second), 10),
+	}
+}
+
+// latestVolume returns the latest of the given settings or stats volumes,
+// which are named after the time they were written at.
+func latestVolume(volumes []*types.Volume) (latest *types.Volume) {
+	for _, v := range volumes {
+		if latest == nil || v.Name > latest.Name {
+			latest = v
+		}
+	}
+
+	return
+}
+
+// settingsFrom returns the settings the latest of the given volumes hold.
+// Settings written before stats were kept apart carry the average build
+// duration too.
+func settingsFrom(volumes []*types.Volume) (s BuildQueueSettings, ok bool) {
+	latest := latestVolume(volumes)
+	if latest == nil {
+		return s, false
+	}
+
+	labels := latest.Labels
+	s.Concurrency, _ = strconv.Atoi(labels[queueLabel+".concurrency"])
+
+	if apps := labels[queueLabel+".priority-apps"]; apps != "" {
+		s.PriorityApps = strings.Split(apps, ",")
+	}
+
+	if secs, err := strconv.ParseInt(labels[queueLabel+".average-build"], 10, 64); err == nil {
+		s.AverageBuild = time.Duration(secs) * time.Second
+	}
+
+	return s, true
+}
+
+// averageFrom returns the average build duration the latest of the given
+// stats volumes holds.
+func averageFrom(volumes []*types.Volume) (time.Duration, bool) {
+	latest := latestVolume(volumes)
+	if latest == nil {
+		return 0, false
+	}
+
+	secs, err := strconv.ParseInt(latest.Labels[queueLabel+".average-build"], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Duration(secs) * time.Second, true
+}
+
+func entryLabels(e BuildQueueEntry, gen int) map[string]string {
+	labels := map[string]string{
+		queueLabel:               queueEntryKind,
+		queueLabel + ".id":       e.ID,
+		queueLabel + ".gen":      strconv.Itoa(gen),
+		queueLabel + ".app":      e.App,
+		queueLabel + ".priority": strconv.FormatBool(e.Priority),
+		queueLabel + ".building": strconv.FormatBool(e.Building),
+		queueLabel + ".enqueued": strconv.FormatInt(e.Enqueued.UnixNano(), 10),
+	}
+
+	if !e.Started.IsZero() {
+		labels[queueLabel+".started"] = strconv.FormatInt(e.Started.UnixNano(), 10)
+	}
+
+	return labels
+}
+
+// entriesFrom returns the entries the given volumes hold, in the order they
+// run in, along with the names of the volumes which are stale as of now.
+func entriesFrom(volumes []*types.Volume, now time.Time) (entries []BuildQueueEntry, stale []string) {
+	latest := map[string]*types.Volume{}
+
+	for _, v := range volumes {
+		created, err := time.Parse(time.RFC3339Nano, v.CreatedAt)
+		if err != nil || now.Sub(created) > queueStaleAfter {
+			stale = append(stale, v.Name)
+
+			continue
+		}
+
+		id := v.Labels[queueLabel+".id"]
+		if prev := latest[id]; prev == nil || generation(v) > generation(prev) {
+			latest[id] = v
+		}
+	}
+
+	for id, v := range latest {
+		labels := v.Labels
+
+		e := BuildQueueEntry{
+			ID:       id,
+			App:      labels[queueLabel+".app"],
+			Priority: labels[queueLabel+".priority"] == "true",
+			Building: labels[queueLabel+".building"] == "true",
+			Enqueued: unixNano(labels[queueLabel+".enqueued"]),
+			Started:  unixNano(labels[queueLabel+".started"]),
+		}
+
+		entries = append(entries, e)
+	}
+
+	sortQueue(entries)
+
+	return
+}
+
+func generation(v *types.Volume) int {
+	gen, _ := strconv.Atoi(v.Labels[queueLabel+".gen"])
+
+	return gen
+}
+
+func unixNano(s string) time.Time {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(0, n)
+}
+
+// sortQueue sorts entries in the order they run in: running builds first,
+// then queued builds of priority apps and then the rest, each in the order
+// they arrived in.
+func sortQueue(entries []BuildQueueEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+
+		switch {
+		case a.Building != b.Building:
+			return a.Building
+		case a.Building:
+			return a.Started.Before(b.Started)
+		case a.Priority != b.Priority:
+			return a.Priority
+		default:
+			return a.Enqueued.Before(b.Enqueued)
+		}
+	})
+}
+
+// queuePosition returns the position of the named entry among the queued
+// ones, and whether it may start building given the concurrency.
+func queuePosition(entries []BuildQueueEntry, id string, concurrency int) (position int, admit bool) {
+	var building, ahead int
+
+	for _, e := range entries {
+		switch {
+		case e.ID == id:
+			position = ahead + 1
+			admit = concurrency <= 0 || building+ahead < concurrency
+
+			return
+		case e.Building:
+			building++
+		default:
+			ahead++
+		}
+	}
+
+	return 0, true
+}
+
+// estimateWait estimates how long the build at the given queue position
+// waits for, or returns zero when it can't.
+func estimateWait(position, concurrency int, average time.Duration) time.Duration {
+	if concurrency <= 0 || average <= 0 || position <= 0 {
+		return 0
+	}
+
+	rounds := (position + concurrency - 1) / concurrency
+
+	return time.Duration(rounds) * average
+}
+
+// queueTicket keeps the entry of a build in the queue alive.
+type queueTicket struct {
+	queue *BuildQueue
+
+	mu    sync.Mutex
+	entry BuildQueueEntry
+	gen   int
+	name  string
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func (q *BuildQueue) join(ctx context.Context, e BuildQueueEntry) (*queueTicket, error) {
+	t := &queueTicket{
+		queue: q,
+		entry: e,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+
+	if err := t.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	go t.heartbeat()
+
+	return t, nil
+}
+
+// refresh replaces the volume of the entry, creating its replacement before
+// removing it so the entry never disappears.
+func (t *queueTicket) refresh(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.gen++
+	name := fmt.Sprintf("fly-build-queue-%s-%d", t.entry.ID, t.gen)
+
+	if _, err := t.queue.docker.VolumeCreate(ctx, volumetypes.VolumeCreateBody{Name: name, Labels: entryLabels(t.entry, t.gen)}); err != nil {
+		return fmt.Errorf("failed joining the build queue: %w", err)
+	}
+
+	if t.name != "" {
+		_ = t.queue.docker.VolumeRemove(ctx, t.name, true)
+	}
+	t.name = name
+
+	return nil
+}
+
+func (t *queueTicket) heartbeat() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(queueHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), queueHeartbeat)
+			if err := t.refresh(ctx); err != nil {
+				terminal.Debugf("failed refreshing build queue entry: %v\n", err)
+			}
+			cancel()
+		}
+	}
+}
+
+func (t *queueTicket) start(ctx context.Context) error {
+	t.mu.Lock()
+	t.entry.Building = true
+	t.entry.Started = time.Now()
+	t.mu.Unlock()
+
+	return t.refresh(ctx)
+}
+
+func (t *queueTicket) leave() {
+	close(t.stop)
+	<-t.done
+
+	ctx, cancel := context.WithTimeout(context.Background(), queueHeartbeat)
+	defer cancel()
+
+	_ = t.queue.docker.VolumeRemove(ctx, t.name, true)
+}
+
+func newQueueID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// waitForBuildSlot queues the build of the named app on the builder docker
+// denotes, in case its queue settings limit concurrency, and reports its
+// position until it's the build's turn. Faults of the queue never fail
+// builds; they build right away instead.
+//
+// Callers must call the returned func once the build is done.
+func waitForBuildSlot(ctx context.Context, docker *dockerclient.Client, streams *iostreams.IOStreams, appName string) (done func(), err error) {
+	q := &BuildQueue{docker: docker}
+	done = func() {}
+
+	settings, err := q.Settings(ctx)
+	if err != nil || settings.Concurrency <= 0 {
+		if err != nil {
+			terminal.Debugf("skipping the build queue: %v\n", err)
+		}
+
+		return done, nil
+	}
+
+	t, err := q.join(ctx, BuildQueueEntry{
+		ID:       newQueueID(),
+		App:      appName,
+		Priority: settings.isPriority(appName),
+		Enqueued: time.Now(),
+	})
+	if err != nil {
+		terminal.Debugf("skipping the build queue: %v\n", err)
+
+		return done, nil
+	}
+
+	var (
+		reported int
+		joined   bool
+	)
+
+	for {
+		entries, err := q.Entries(ctx)
+		if err != nil {
+			terminal.Debugf("skipping the build queue: %v\n", err)
+
+			break
+		}
+
+		position, admit := queuePosition(entries, t.entry.ID, settings.Concurrency)
+		if admit {
+			break
+		}
+
+		if !joined {
+			joined = true
+			cmdfmt.PrintBegin(streams.ErrOut, fmt.Sprintf("Waiting for a build slot on the remote builder (%d builds run at once)", settings.Concurrency))
+		}
+
+		if position != reported {
+			reported = position
+
+			msg := fmt.Sprintf("Queue position %d", position)
+			if wait := estimateWait(position, settings.Concurrency, settings.AverageBuild); wait > 0 {
+				msg += fmt.Sprintf(", estimated wait %s", wait.Round(time.Second))
+			}
+			fmt.Fprintln(streams.ErrOut, msg)
+		}
+
+		if pause.For(ctx, queuePoll); ctx.Err() != nil {
+			t.leave()
+
+			return nil, ctx.Err()
+		}
+	}
+
+	if joined {
+		cmdfmt.PrintDone(streams.ErrOut, "Build slot acquired")
+	}
+
+	if err := t.start(ctx); err != nil {
+		terminal.Debugf("failed marking build started: %v\n", err)
+	}
+
+	return func() {
+		elapsed := time.Since(t.entry.Started)
+		t.leave()
+
+		q.recordBuild(elapsed)
+	}, nil
+}
+
+// recordBuild folds the duration of a build into the average the stats of the
+// queue track. It never touches the settings of the queue, which only admins
+// of the organization may change.
+func (q *BuildQueue) recordBuild(elapsed time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), queueHeartbeat)
+	defer cancel()
+
+	average, ok, err := q.averageBuild(ctx)
+	if err != nil {
+		terminal.Debugf("failed recording build duration: %v\n", err)
+
+		return
+	}
+
+	if !ok || average <= 0 {
+		average = elapsed
+	} else {
+		average = (4*average + elapsed) / 5
+	}
+
+	name := fmt.Sprintf("fly-build-queue-stats-%d", time.Now().UnixNano())
+
+	if err := q.replace(ctx, queueStatsKind, name, statsLabels(average)); err != nil {
+		terminal.Debugf("failed recording build duration: %v\n", err)
+	}
+}
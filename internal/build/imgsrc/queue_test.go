@@ -0,0 +1,123 @@
+package imgsrc
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntriesFrom(t *testing.T) {
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	volume := func(name string, age time.Duration, e BuildQueueEntry, gen int) *types.Volume {
+		return &types.Volume{
+			Name:      name,
+			CreatedAt: now.Add(-age).Format(time.RFC3339),
+			Labels:    entryLabels(e, gen),
+		}
+	}
+
+	var (
+		base     = now.Add(-time.Hour)
+		building = BuildQueueEntry{ID: "a", App: "api", Building: true, Enqueued: base, Started: base.Add(time.Minute)}
+		queued   = BuildQueueEntry{ID: "b", App: "web", Enqueued: base.Add(2 * time.Minute)}
+		priority = BuildQueueEntry{ID: "c", App: "prod", Priority: true, Enqueued: base.Add(3 * time.Minute)}
+		gone     = BuildQueueEntry{ID: "d", App: "old", Enqueued: base}
+	)
+
+	entries, stale := entriesFrom([]*types.Volume{
+		volume("b-1", 20*time.Second, queued, 1),
+		volume("b-2", 5*time.Second, queued, 2),
+		volume("c-1", 0, priority, 1),
+		volume("d-1", time.Minute, gone, 7),
+		volume("a-3", 0, building, 3),
+	}, now)
+
+	assert.Equal(t, []string{"d-1"}, stale)
+
+	require.Len(t, entries, 3)
+	assert.Equal(t, "a", entries[0].ID)
+	assert.Equal(t, "c", entries[1].ID)
+	assert.Equal(t, "b", entries[2].ID)
+	assert.True(t, entries[0].Building)
+	assert.Equal(t, building.Started.UnixNano(), entries[0].Started.UnixNano())
+}
+
+func TestQueuePosition(t *testing.T) {
+	entries := []BuildQueueEntry{
+		{ID: "a", Building: true},
+		{ID: "b"},
+		{ID: "c"},
+	}
+
+	cases := []struct {
+		id          string
+		concurrency int
+		position    int
+		admit       bool
+	}{
+		{"b", 1, 1, false},
+		{"b", 2, 1, true},
+		{"c", 2, 2, false},
+		{"c", 3, 2, true},
+		{"c", 0, 2, true},
+		{"missing", 1, 0, true},
+	}
+
+	for _, c := range cases {
+		position, admit := queuePosition(entries, c.id, c.concurrency)
+
+		assert.Equal(t, c.position, position, c.id+"/"+strconv.Itoa(c.concurrency))
+		assert.Equal(t, c.admit, admit, c.id+"/"+strconv.Itoa(c.concurrency))
+	}
+}
+
+func TestEstimateWait(t *testing.T) {
+	assert.Equal(t, 2*time.Minute, estimateWait(1, 2, 2*time.Minute))
+	assert.Equal(t, 4*time.Minute, estimateWait(3, 2, 2*time.Minute))
+	assert.Zero(t, estimateWait(3, 2, 0))
+	assert.Zero(t, estimateWait(3, 0, time.Minute))
+}
+
+func TestSettingsFrom(t *testing.T) {
+	_, ok := settingsFrom(nil)
+	assert.False(t, ok)
+
+	older := BuildQueueSettings{Concurrency: 1}
+	newer := BuildQueueSettings{Concurrency: 3, PriorityApps: []string{"prod", "api"}}
+
+	s, ok := settingsFrom([]*types.Volume{
+		{Name: "fly-build-queue-settings-2", Labels: settingsLabels(newer)},
+		{Name: "fly-build-queue-settings-1", Labels: settingsLabels(older)},
+	})
+	require.True(t, ok)
+	assert.Equal(t, newer, s)
+	assert.True(t, s.isPriority("api"))
+	assert.False(t, s.isPriority("web"))
+
+	// settings written before stats were kept apart
+	legacy := settingsLabels(older)
+	legacy[queueLabel+".average-build"] = "30"
+
+	s, ok = settingsFrom([]*types.Volume{{Name: "fly-build-queue-settings-1", Labels: legacy}})
+	require.True(t, ok)
+	assert.Equal(t, 30*time.Second, s.AverageBuild)
+}
+
+func TestAverageFrom(t *testing.T) {
+	_, ok := averageFrom(nil)
+	assert.False(t, ok)
+
+	average, ok := averageFrom([]*types.Volume{
+		{Name: "fly-build-queue-stats-1", Labels: statsLabels(time.Minute)},
+		{Name: "fly-build-queue-stats-2", Labels: statsLabels(90 * time.Second)},
+	})
+	require.True(t, ok)
+	assert.Equal(t, 90*time.Second, average)
+
+	assert.NotContains(t, settingsLabels(BuildQueueSettings{AverageBuild: time.Minute}), queueLabel+".average-build")
+}
@@ -4,10 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/superfly/flyctl/pkg/iostreams"
 
 	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/tracing"
+	"github.com/superfly/flyctl/pkg/builder"
 	"github.com/superfly/flyctl/terminal"
 )
 
@@ -27,6 +32,20 @@ type ImageOptions struct {
 	BuiltInSettings map[string]interface{}
 	Builder         string
 	Buildpacks      []string
+	// SSH holds the ssh agent sockets or keys to expose to the build, in the
+	// default|<id>[=<socket>|<key>[,<key>]] format of docker build --ssh.
+	SSH []string
+	// CachePaths holds the paths the RUN instructions of Dockerfile builds
+	// mount the persistent dependency caches of the app at.
+	CachePaths []string
+	// Static is the static site to deploy without a Dockerfile, if any.
+	Static *StaticSite
+	// StallTimeout is how long remote builds may go without output before
+	// they're cancelled with a BuildStalledError. Zero disables the check.
+	StallTimeout time.Duration
+	// UploadLimit caps the rate, in bytes per second, at which the build
+	// context is sent to the builder. Zero means no limit.
+	UploadLimit int64
 }
 
 type RefOptions struct {
@@ -47,10 +66,14 @@ type DeploymentImage struct {
 type Resolver struct {
 	dockerFactory *dockerClientFactory
 	apiClient     *api.Client
+	appName       string
 }
 
 // ResolveReference returns an Image give an reference using either the local docker daemon or remote registry
 func (r *Resolver) ResolveReference(ctx context.Context, streams *iostreams.IOStreams, opts RefOptions) (img *DeploymentImage, err error) {
+	ctx, span := tracing.Start(ctx, "imgsrc.resolve", attribute.String("image.ref", opts.ImageRef))
+	defer func() { tracing.End(span, err) }()
+
 	strategies := []imageResolver{
 		&localImageResolver{},
 		&remoteImageResolver{flyApi: r.apiClient},
@@ -64,6 +87,8 @@ func (r *Resolver) ResolveReference(ctx context.Context, streams *iostreams.IOSt
 			return nil, err
 		}
 		if img != nil {
+			span.SetAttributes(attribute.String("imgsrc.strategy", s.Name()))
+
 			return img, nil
 		}
 	}
@@ -71,8 +96,11 @@ func (r *Resolver) ResolveReference(ctx context.Context, streams *iostreams.IOSt
 	return nil, fmt.Errorf("could not find image \"%s\"", opts.ImageRef)
 }
 
-// BuildImage converts source code to an image using a Dockerfile, buildpacks, or builtins.
+// BuildImage converts source code to an image using a Dockerfile, buildpacks, builtins, or a static site directory.
 func (r *Resolver) BuildImage(ctx context.Context, streams *iostreams.IOStreams, opts ImageOptions) (img *DeploymentImage, err error) {
+	ctx, span := tracing.Start(ctx, "imgsrc.build", attribute.String("app.name", opts.AppName))
+	defer func() { tracing.End(span, err) }()
+
 	if !r.dockerFactory.mode.IsAvailable() {
 		return nil, errors.New("docker is unavailable to build the deployment image")
 	}
@@ -82,6 +110,7 @@ func (r *Resolver) BuildImage(ctx context.Context, streams *iostreams.IOStreams,
 	}
 
 	strategies := []imageBuilder{
+		&staticBuilder{},
 		&buildpacksBuilder{},
 		&dockerfileBuilder{},
 		&builtinBuilder{},
@@ -99,12 +128,17 @@ func (r *Resolver) BuildImage(ctx context.Context, streams *iostreams.IOStreams,
 	}
 	for _, s := range strategies {
 		terminal.Debugf("Trying '%s' strategy\n", s.Name())
-		img, err = s.Run(ctx, r.dockerFactory, streams, opts)
+		img, err = runStrategy(ctx, s, r.dockerFactory, streams, opts)
 		terminal.Debugf("result image:%+v error:%v\n", img, err)
 		if err != nil {
 			return nil, err
 		}
 		if img != nil {
+			span.SetAttributes(
+				attribute.String("imgsrc.strategy", s.Name()),
+				attribute.Int64("image.size", img.Size),
+			)
+
 			return img, nil
 		}
 	}
@@ -112,10 +146,94 @@ func (r *Resolver) BuildImage(ctx context.Context, streams *iostreams.IOStreams,
 	return nil, errors.New("app does not have a Dockerfile or buildpacks configured. See https://fly.io/docs/reference/configuration/#the-build-section")
 }
 
+// RestartBuilder restarts the machine of the remote builder builds run on,
+// and has the builds which follow reconnect to it once it's back up.
+func (r *Resolver) RestartBuilder(ctx context.Context, streams *iostreams.IOStreams) (err error) {
+	ctx, span := tracing.Start(ctx, "imgsrc.restart_builder")
+	defer func() { tracing.End(span, err) }()
+
+	if !r.dockerFactory.mode.IsRemote() {
+		return errors.New("builds don't run on a remote builder")
+	}
+
+	if b := r.dockerFactory.selfHosted; b != nil {
+		return fmt.Errorf("builds run on the self-hosted builder %s, which can't be restarted by flyctl", b.Host)
+	}
+
+	machine, app, err := builder.RemoteBuilderMachine(ctx, r.apiClient, r.appName)
+	if err != nil {
+		return err
+	}
+
+	if machine == nil {
+		return errors.New("the remote builder is set via FLY_REMOTE_BUILDER_HOST and can't be restarted by flyctl")
+	}
+
+	span.SetAttributes(attribute.String("builder.app", app.Name))
+
+	if _, err = r.apiClient.StopMachine(ctx, api.StopMachineInput{AppID: app.Name, ID: machine.ID}); err != nil {
+		return fmt.Errorf("failed stopping builder machine %s: %w", machine.ID, err)
+	}
+
+	if err = waitForMachineState(ctx, r.apiClient, app.Name, machine.ID, "stopped"); err != nil {
+		return err
+	}
+
+	if _, err = r.apiClient.StartMachine(ctx, api.StartMachineInput{AppID: app.Name, ID: machine.ID}); err != nil {
+		return fmt.Errorf("failed starting builder machine %s: %w", machine.ID, err)
+	}
+
+	// the cached client points at the daemon which was just restarted
+	r.dockerFactory = newDockerClientFactory(DockerDaemonTypeRemote, r.apiClient, r.appName, streams)
+
+	return nil
+}
+
+// builderStateTimeout is how long the builder machine may take to settle
+// into a state.
+const builderStateTimeout = 2 * time.Minute
+
+func waitForMachineState(ctx context.Context, client *api.Client, appName, id, state string) error {
+	ctx, cancel := context.WithTimeout(ctx, builderStateTimeout)
+	defer cancel()
+
+	for {
+		machine, err := client.GetMachine(ctx, appName, id)
+		if err != nil {
+			return fmt.Errorf("failed retrieving builder machine %s: %w", id, err)
+		}
+
+		if machine.State == state {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("builder machine %s did not become %s in time (currently %s)", id, state, machine.State)
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// UseSelfHostedBuilder has remote builds run on the given self-hosted builder
+// instead of the Fly-managed one. Unless b disables it, builds fall back to
+// the Fly-managed builder when b fails its health check.
+func (r *Resolver) UseSelfHostedBuilder(b *SelfHostedBuilder) {
+	r.dockerFactory.selfHosted = b
+}
+
+func runStrategy(ctx context.Context, s imageBuilder, dockerFactory *dockerClientFactory, streams *iostreams.IOStreams, opts ImageOptions) (img *DeploymentImage, err error) {
+	ctx, span := tracing.Start(ctx, "imgsrc.build.strategy", attribute.String("imgsrc.strategy", s.Name()))
+	defer func() { tracing.End(span, err) }()
+
+	return s.Run(ctx, dockerFactory, streams, opts)
+}
+
 func NewResolver(daemonType DockerDaemonType, apiClient *api.Client, appName string, iostreams *iostreams.IOStreams) *Resolver {
 	return &Resolver{
 		dockerFactory: newDockerClientFactory(daemonType, apiClient, appName, iostreams),
 		apiClient:     apiClient,
+		appName:       appName,
 	}
 }
 
@@ -0,0 +1,175 @@
+package imgsrc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	dockerclient "github.com/docker/docker/client"
+	"github.com/pkg/errors"
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/tracing"
+	"github.com/superfly/flyctl/pkg/iostreams"
+	"github.com/superfly/flyctl/terminal"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SelfHostedBuilder denotes a builder an organization runs itself, instead of
+// the Fly-managed one. It must serve the Docker Engine API, as dockerd does.
+type SelfHostedBuilder struct {
+	// Org denotes the slug of the organization the builder is registered
+	// with.
+	Org string
+
+	// Host denotes the address of the builder, e.g. tcp://builder.internal:2375.
+	Host string
+
+	// NoFailover instructs remote builds to fail when the builder is
+	// unhealthy, instead of falling back to the Fly-managed builder.
+	NoFailover bool
+}
+
+// selfHostedCheckTimeout is how long a self-hosted builder has to respond to
+// its health check.
+const selfHostedCheckTimeout = 10 * time.Second
+
+// NormalizeBuilderHost returns the given address of a self-hosted builder in
+// the form the Docker client expects; tcp://host:port.
+//
+// Builders have to be on the private network of their organization. The
+// Docker Engine API they serve is unauthenticated plaintext, and pushes hand
+// them the registry credentials of the user, which include their access
+// token; only the WireGuard tunnel keeps those private.
+func NormalizeBuilderHost(host string) (string, error) {
+	if !strings.Contains(host, "://") {
+		host = "tcp://" + host
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid builder address %q: %w", host, err)
+	}
+
+	switch {
+	case u.Scheme != "tcp":
+		return "", fmt.Errorf("invalid builder address %q: only tcp:// addresses are supported", host)
+	case u.Hostname() == "" || u.Port() == "":
+		return "", fmt.Errorf("invalid builder address %q: expected tcp://host:port", host)
+	case u.Path != "" && u.Path != "/":
+		return "", fmt.Errorf("invalid builder address %q: paths are not supported", host)
+	}
+
+	normalized := "tcp://" + net.JoinHostPort(u.Hostname(), u.Port())
+	if !isPrivateBuilderHost(normalized) {
+		return "", fmt.Errorf("invalid builder address %q: builders must be on the private network of their organization, e.g. builder.internal, builder.flycast or an fdaa: address", host)
+	}
+
+	return normalized, nil
+}
+
+// isPrivateBuilderHost reports whether the given address of a self-hosted
+// builder is on the private network of its organization, and has to be dialed
+// through its WireGuard tunnel.
+func isPrivateBuilderHost(host string) bool {
+	u, err := url.Parse(host)
+	if err != nil {
+		return false
+	}
+
+	name := u.Hostname()
+	if strings.HasSuffix(name, ".internal") || strings.HasSuffix(name, ".flycast") {
+		return true
+	}
+
+	ip := net.ParseIP(name)
+
+	return ip != nil && ip.To4() == nil && strings.HasPrefix(strings.ToLower(name), "fdaa:")
+}
+
+// CheckSelfHostedBuilder returns an error in case the given self-hosted
+// builder can't be reached or doesn't respond to pings in time.
+func CheckSelfHostedBuilder(ctx context.Context, apiClient *api.Client, b *SelfHostedBuilder) error {
+	client, err := selfHostedDockerClient(ctx, apiClient, b)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return pingSelfHostedBuilder(ctx, client)
+}
+
+func newSelfHostedDockerClient(ctx context.Context, apiClient *api.Client, b *SelfHostedBuilder, streams *iostreams.IOStreams) (_ *dockerclient.Client, err error) {
+	ctx, span := tracing.Start(ctx, "imgsrc.self_hosted_builder", attribute.String("builder.host", b.Host))
+	defer func() { tracing.End(span, err) }()
+
+	if msg := fmt.Sprintf("Waiting for self-hosted builder %s...", b.Host); streams.IsInteractive() {
+		streams.StartProgressIndicatorMsg(msg)
+	} else {
+		fmt.Fprintln(streams.ErrOut, msg)
+	}
+
+	client, err := selfHostedDockerClient(ctx, apiClient, b)
+	if err == nil {
+		err = pingSelfHostedBuilder(ctx, client)
+	}
+
+	if err != nil {
+		streams.StopProgressIndicator()
+
+		return nil, fmt.Errorf("self-hosted builder %s is unhealthy: %w", b.Host, err)
+	}
+
+	if msg := fmt.Sprintf("Self-hosted builder %s ready", b.Host); streams.IsInteractive() {
+		streams.StopProgressIndicatorMsg(msg)
+	} else {
+		fmt.Fprintln(streams.ErrOut, msg)
+	}
+
+	return client, nil
+}
+
+func selfHostedDockerClient(ctx context.Context, apiClient *api.Client, b *SelfHostedBuilder) (*dockerclient.Client, error) {
+	opts := []dockerclient.Opt{
+		dockerclient.WithAPIVersionNegotiation(),
+		dockerclient.WithHost(b.Host),
+	}
+
+	// registrations predating the restriction to private hosts must not be
+	// dialed in plaintext either
+	if !isPrivateBuilderHost(b.Host) {
+		return nil, fmt.Errorf("builder %s is not on the private network of %s; register a private one with 'fly builders register'", b.Host, b.Org)
+	}
+
+	terminal.Debugf("connecting to self-hosted builder %s over the wireguard tunnel of %s\n", b.Host, b.Org)
+
+	dial, err := orgDialOpt(ctx, apiClient, b.Org)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, dial)
+
+	client, err := dockerclient.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating docker client: %w", err)
+	}
+
+	return client, nil
+}
+
+func pingSelfHostedBuilder(ctx context.Context, client *dockerclient.Client) error {
+	ctx, cancel := context.WithTimeout(ctx, selfHostedCheckTimeout)
+	defer cancel()
+
+	if _, err := client.Ping(ctx); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("no response within %s", selfHostedCheckTimeout)
+		}
+
+		return err
+	}
+
+	return nil
+}
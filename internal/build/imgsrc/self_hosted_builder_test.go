@@ -0,0 +1,54 @@
+package imgsrc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeBuilderHost(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected string
+		ok       bool
+	}{
+		{"builder.internal:2375", "tcp://builder.internal:2375", true},
+		{"tcp://builder.flycast:2375", "tcp://builder.flycast:2375", true},
+		{"tcp://[fdaa:0:1::3]:2375/", "tcp://[fdaa:0:1::3]:2375", true},
+		{"builder.example.com:2375", "", false},
+		{"tcp://10.0.0.1:2375", "", false},
+		{"builder.internal", "", false},
+		{"unix:///var/run/docker.sock", "", false},
+		{"tcp://builder.example.com:2375/v1", "", false},
+	}
+
+	for _, test := range tests {
+		host, err := NormalizeBuilderHost(test.host)
+		if !test.ok {
+			assert.Error(t, err, test.host)
+
+			continue
+		}
+
+		assert.NoError(t, err, test.host)
+		assert.Equal(t, test.expected, host)
+	}
+}
+
+func TestIsPrivateBuilderHost(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected bool
+	}{
+		{"tcp://builder.internal:2375", true},
+		{"tcp://builder.flycast:2375", true},
+		{"tcp://[fdaa:0:1::3]:2375", true},
+		{"tcp://[2001:db8::1]:2375", false},
+		{"tcp://10.0.0.1:2375", false},
+		{"tcp://builder.example.com:2375", false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, isPrivateBuilderHost(test.host), test.host)
+	}
+}
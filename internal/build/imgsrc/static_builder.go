@@ -0,0 +1,194 @@
+package imgsrc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/superfly/flyctl/internal/cmdfmt"
+	"github.com/superfly/flyctl/pkg/iostreams"
+	"github.com/superfly/flyctl/terminal"
+)
+
+// StaticSite describes a directory of prebuilt files to serve, which is
+// deployed on a managed web server image instead of a Dockerfile.
+type StaticSite struct {
+	// Dir is the directory holding the files, relative to the working
+	// directory.
+	Dir string
+	// Fallback is the file served for paths which match no file, e.g.
+	// /index.html for single page apps. Unmatched paths 404 when empty.
+	Fallback string
+	// CacheControl maps path patterns, such as /assets/*, to the
+	// Cache-Control header of the files they match.
+	CacheControl map[string]string
+}
+
+const (
+	staticSiteImage = "caddy:2-alpine"
+	staticSitePort  = 8080
+
+	// staticSiteFiles is the directory of the build context the generated
+	// Dockerfile and Caddyfile are added under.
+	staticSiteFiles = ".fly-static"
+)
+
+type staticBuilder struct{}
+
+func (*staticBuilder) Name() string {
+	return "Static"
+}
+
+func (*staticBuilder) Run(ctx context.Context, dockerFactory *dockerClientFactory, streams *iostreams.IOStreams, opts ImageOptions) (*DeploymentImage, error) {
+	if opts.Static == nil {
+		terminal.Debug("fly.toml does not include a static site config")
+		return nil, nil
+	}
+
+	if !dockerFactory.mode.IsAvailable() {
+		terminal.Debug("docker daemon not available, skipping")
+		return nil, nil
+	}
+
+	dir := opts.Static.Dir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(opts.WorkingDir, dir)
+	}
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return nil, fmt.Errorf("static site directory %s does not exist; build the site before deploying it", opts.Static.Dir)
+	}
+
+	dockerfile, caddyfile, err := staticSiteConfig(opts.Static)
+	if err != nil {
+		return nil, err
+	}
+
+	docker, err := dockerFactory.buildFn(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error connecting to docker")
+	}
+
+	defer clearDeploymentTags(ctx, docker, opts.Tag)
+
+	cmdfmt.PrintBegin(streams.ErrOut, "Packaging static site")
+
+	r, err := archiveDirectory(archiveOptions{
+		sourcePath: dir,
+		compressed: dockerFactory.mode.IsRemote(),
+		additions: map[string][]byte{
+			staticSiteFiles + "/Dockerfile": []byte(dockerfile),
+			staticSiteFiles + "/Caddyfile":  []byte(caddyfile),
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error archiving static site")
+	}
+
+	cmdfmt.PrintDone(streams.ErrOut, "Packaging static site done")
+
+	r = limitUpload(ctx, r, opts.UploadLimit)
+
+	cmdfmt.PrintBegin(streams.ErrOut, "Building static site image")
+
+	// the generated Dockerfile has a single stage
+	opts.Target = ""
+
+	buildCtx, wd := watchBuild(ctx, dockerFactory.mode, opts)
+	defer wd.Stop()
+
+	imageID, err := runClassicBuild(buildCtx, streams, docker, r, opts, staticSiteFiles+"/Dockerfile", nil, wd)
+	if err != nil {
+		return nil, errors.Wrap(wd.Err(ctx, docker, err), "error building")
+	}
+
+	cmdfmt.PrintDone(streams.ErrOut, "Building static site image done")
+
+	if opts.Publish {
+		cmdfmt.PrintBegin(streams.ErrOut, "Pushing image to fly")
+
+		if err := pushToFly(ctx, docker, streams, opts.Tag); err != nil {
+			return nil, err
+		}
+
+		cmdfmt.PrintDone(streams.ErrOut, "Pushing image done")
+	}
+
+	img, _, err := docker.ImageInspectWithRaw(ctx, imageID)
+	if err != nil {
+		return nil, errors.Wrap(err, "count not find built image")
+	}
+
+	return &DeploymentImage{
+		ID:   img.ID,
+		Tag:  opts.Tag,
+		Size: img.Size,
+	}, nil
+}
+
+// staticSiteConfig returns the Dockerfile and the Caddyfile of the image
+// serving the given site.
+func staticSiteConfig(site *StaticSite) (dockerfile, caddyfile string, err error) {
+	if site.Fallback != "" && !strings.HasPrefix(site.Fallback, "/") {
+		err = fmt.Errorf("static fallback %q must be an absolute path, such as /index.html", site.Fallback)
+
+		return
+	}
+
+	// apply the least specific patterns first, so that the header directives
+	// of longer, more specific patterns win
+	patterns := make([]string, 0, len(site.CacheControl))
+	for pattern := range site.CacheControl {
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) < len(patterns[j])
+		}
+
+		return patterns[i] < patterns[j]
+	})
+
+	var b strings.Builder
+
+	b.WriteString("{\n\tadmin off\n\tauto_https off\n}\n\n")
+	fmt.Fprintf(&b, ":%d {\n\troot * /srv\n\tencode zstd gzip\n", staticSitePort)
+
+	for i, pattern := range patterns {
+		value := site.CacheControl[pattern]
+
+		switch {
+		case pattern != "*" && !strings.HasPrefix(pattern, "/"):
+			err = fmt.Errorf("static cache pattern %q must be * or start with /", pattern)
+		case strings.ContainsAny(pattern, " \t\r\n\"{}"):
+			err = fmt.Errorf("static cache pattern %q must not contain whitespace, quotes or braces", pattern)
+		case strings.ContainsAny(value, "\r\n\""):
+			err = fmt.Errorf("static cache header %q for %s must not contain newlines or quotes", value, pattern)
+		}
+		if err != nil {
+			return
+		}
+
+		fmt.Fprintf(&b, "\n\t@cache%d path %s\n\theader @cache%d Cache-Control %q\n", i, pattern, i, value)
+	}
+
+	b.WriteString("\n")
+	if site.Fallback != "" {
+		fmt.Fprintf(&b, "\ttry_files {path} {path}/ %s\n", site.Fallback)
+	}
+	b.WriteString("\tfile_server\n}\n")
+
+	caddyfile = b.String()
+
+	dockerfile = fmt.Sprintf(`FROM %s
+COPY %s/Caddyfile /etc/caddy/Caddyfile
+COPY . /srv
+RUN rm -rf /srv/%s
+EXPOSE %d
+`, staticSiteImage, staticSiteFiles, staticSiteFiles, staticSitePort)
+
+	return
+}
@@ -0,0 +1,64 @@
+package imgsrc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticSiteConfig(t *testing.T) {
+	dockerfile, caddyfile, err := staticSiteConfig(&StaticSite{
+		Dir:      "dist",
+		Fallback: "/index.html",
+		CacheControl: map[string]string{
+			"/assets/*": "public, max-age=31536000, immutable",
+			"*":         "no-cache",
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.Contains(t, dockerfile, "FROM "+staticSiteImage+"\n")
+	assert.Contains(t, dockerfile, "COPY .fly-static/Caddyfile /etc/caddy/Caddyfile\n")
+	assert.Contains(t, dockerfile, "RUN rm -rf /srv/.fly-static\n")
+
+	assert.Equal(t, `{
+	admin off
+	auto_https off
+}
+
+:8080 {
+	root * /srv
+	encode zstd gzip
+
+	@cache0 path *
+	header @cache0 Cache-Control "no-cache"
+
+	@cache1 path /assets/*
+	header @cache1 Cache-Control "public, max-age=31536000, immutable"
+
+	try_files {path} {path}/ /index.html
+	file_server
+}
+`, caddyfile)
+}
+
+func TestStaticSiteConfigWithoutFallback(t *testing.T) {
+	_, caddyfile, err := staticSiteConfig(&StaticSite{Dir: "public"})
+	assert.NoError(t, err)
+	assert.NotContains(t, caddyfile, "try_files")
+	assert.Contains(t, caddyfile, "\tfile_server\n")
+}
+
+func TestStaticSiteConfigValidation(t *testing.T) {
+	cases := []*StaticSite{
+		{Dir: "dist", Fallback: "index.html"},
+		{Dir: "dist", CacheControl: map[string]string{"assets/*": "no-cache"}},
+		{Dir: "dist", CacheControl: map[string]string{"/a b": "no-cache"}},
+		{Dir: "dist", CacheControl: map[string]string{"/assets/*": "no-cache\"\nfile_server"}},
+	}
+
+	for _, site := range cases {
+		_, _, err := staticSiteConfig(site)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,55 @@
+package imgsrc
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// limitUpload returns a reader which reads r at no more than limit bytes per
+// second. Non-positive limits return r as is.
+func limitUpload(ctx context.Context, r io.ReadCloser, limit int64) io.ReadCloser {
+	if limit <= 0 {
+		return r
+	}
+
+	return &limitedReader{ctx: ctx, r: r, limit: limit}
+}
+
+type limitedReader struct {
+	ctx   context.Context
+	r     io.ReadCloser
+	limit int64
+
+	start time.Time
+	read  int64
+}
+
+func (l *limitedReader) Read(p []byte) (n int, err error) {
+	if l.start.IsZero() {
+		l.start = time.Now()
+	}
+
+	// reading a tenth of a second's worth at most at once keeps the rate even
+	if max := l.limit/10 + 1; int64(len(p)) > max {
+		p = p[:max]
+	}
+
+	n, err = l.r.Read(p)
+	l.read += int64(n)
+
+	due := time.Duration(float64(l.read) / float64(l.limit) * float64(time.Second))
+	if wait := due - time.Since(l.start); wait > 0 {
+		select {
+		case <-l.ctx.Done():
+			return n, l.ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+
+	return
+}
+
+func (l *limitedReader) Close() error {
+	return l.r.Close()
+}
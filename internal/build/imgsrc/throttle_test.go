@@ -0,0 +1,40 @@
+package imgsrc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitUpload(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 3000)
+	r := limitUpload(context.Background(), io.NopCloser(bytes.NewReader(data)), 10000)
+
+	start := time.Now()
+	read, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	assert.Equal(t, data, read)
+	assert.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond, "3KB at 10KB/s take about 300ms")
+}
+
+func TestLimitUploadUnlimited(t *testing.T) {
+	r := io.NopCloser(bytes.NewReader(nil))
+
+	assert.Equal(t, r, limitUpload(context.Background(), r, 0))
+}
+
+func TestLimitUploadCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := limitUpload(ctx, io.NopCloser(bytes.NewReader(make([]byte, 1000))), 10)
+
+	_, err := io.ReadAll(r)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,137 @@
+package imgsrc
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	dockerclient "github.com/docker/docker/client"
+)
+
+// watchdog cancels builds once their output has been silent for longer than
+// its timeout.
+type watchdog struct {
+	timeout time.Duration
+	cancel  context.CancelFunc
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	stalled bool
+}
+
+// newWatchdog returns a context which the returned watchdog cancels once the
+// readers it wraps go silent for longer than timeout. The timer starts with
+// the first reader being wrapped. Stop the watchdog to release its
+// resources.
+func newWatchdog(ctx context.Context, timeout time.Duration) (context.Context, *watchdog) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return ctx, &watchdog{
+		timeout: timeout,
+		cancel:  cancel,
+	}
+}
+
+// watchBuild returns the context to run builds with opts in, along with the
+// watchdog of the build in case it runs remotely and opts set a stall
+// timeout.
+func watchBuild(ctx context.Context, mode DockerDaemonType, opts ImageOptions) (context.Context, *watchdog) {
+	if !mode.IsRemote() || opts.StallTimeout <= 0 {
+		return ctx, nil
+	}
+
+	return newWatchdog(ctx, opts.StallTimeout)
+}
+
+// Reader returns a reader which resets the timeout of the watchdog on each
+// read from r. Nil watchdogs return r as is.
+func (w *watchdog) Reader(r io.Reader) io.Reader {
+	if w == nil {
+		return r
+	}
+
+	w.kick()
+
+	return &watchedReader{r: r, w: w}
+}
+
+func (w *watchdog) kick() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.timeout, w.fire)
+
+		return
+	}
+
+	w.timer.Reset(w.timeout)
+}
+
+func (w *watchdog) fire() {
+	w.mu.Lock()
+	w.stalled = true
+	w.mu.Unlock()
+
+	w.cancel()
+}
+
+// Stalled reports whether the watchdog cancelled the build.
+func (w *watchdog) Stalled() bool {
+	if w == nil {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.stalled
+}
+
+// Stop stops the watchdog and cancels its context.
+func (w *watchdog) Stop() {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+
+	w.cancel()
+}
+
+// Err returns a BuildStalledError for builds the watchdog cancelled, with
+// docker probed for whether the builder is still responding, or err as is
+// otherwise.
+func (w *watchdog) Err(ctx context.Context, docker *dockerclient.Client, err error) error {
+	if err == nil || !w.Stalled() {
+		return err
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, pingErr := docker.Ping(pingCtx)
+
+	return &BuildStalledError{
+		Timeout:           w.timeout,
+		BuilderResponding: pingErr == nil,
+	}
+}
+
+type watchedReader struct {
+	r io.Reader
+	w *watchdog
+}
+
+func (r *watchedReader) Read(p []byte) (n int, err error) {
+	if n, err = r.r.Read(p); n > 0 {
+		r.w.kick()
+	}
+
+	return
+}
@@ -0,0 +1,68 @@
+package imgsrc
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchdog(t *testing.T) {
+	ctx, wd := newWatchdog(context.Background(), 100*time.Millisecond)
+	defer wd.Stop()
+
+	pr, pw := io.Pipe()
+	r := wd.Reader(pr)
+
+	go func() {
+		// keep the build alive for a while, then go silent
+		for i := 0; i < 5; i++ {
+			time.Sleep(40 * time.Millisecond)
+			_, _ = pw.Write([]byte("step\n"))
+		}
+	}()
+
+	buf := make([]byte, 16)
+	for i := 0; i < 5; i++ {
+		_, err := r.Read(buf)
+		require.NoError(t, err)
+		assert.NoError(t, ctx.Err(), "output resets the timeout")
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("watchdog did not fire")
+	}
+
+	assert.True(t, wd.Stalled())
+}
+
+func TestWatchdogStop(t *testing.T) {
+	ctx, wd := newWatchdog(context.Background(), 50*time.Millisecond)
+	_ = wd.Reader(nil)
+	wd.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	assert.Error(t, ctx.Err())
+	assert.False(t, wd.Stalled())
+	assert.NoError(t, wd.Err(context.Background(), nil, nil))
+}
+
+func TestWatchBuild(t *testing.T) {
+	ctx := context.Background()
+
+	_, wd := watchBuild(ctx, DockerDaemonTypeLocal, ImageOptions{StallTimeout: time.Minute})
+	assert.Nil(t, wd, "local builds aren't watched")
+
+	_, wd = watchBuild(ctx, DockerDaemonTypeRemote, ImageOptions{})
+	assert.Nil(t, wd, "zero timeouts disable the watchdog")
+
+	_, wd = watchBuild(ctx, DockerDaemonTypeRemote, ImageOptions{StallTimeout: time.Minute})
+	require.NotNil(t, wd)
+	wd.Stop()
+}
@@ -4,19 +4,25 @@ package cli
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/spf13/cobra"
 
 	"github.com/superfly/flyctl/pkg/iostreams"
 
+	"github.com/superfly/flyctl/internal/env"
 	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/internal/logger"
+	"github.com/superfly/flyctl/internal/tracing"
 
+	"github.com/superfly/flyctl/internal/cli/internal/command/help"
 	"github.com/superfly/flyctl/internal/cli/internal/command/root"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
 )
 
 // Run runs the command line interface with the given arguments and reports the
@@ -32,20 +38,112 @@ func Run(ctx context.Context, io *iostreams.IOStreams, args ...string) int {
 
 	cs := io.ColorScheme()
 
-	switch _, err := cmd.ExecuteContextC(ctx); {
-	case err == nil:
-		return 0
-	case errors.Is(err, context.Canceled), errors.Is(err, terminal.InterruptErr):
-		return 127
-	case errors.Is(err, context.DeadlineExceeded):
+	shutdownTracing, err := tracing.Init(ctx)
+	if err != nil {
+		printError(io.ErrOut, cs, fmt.Errorf("failed initializing tracing: %w", err))
+
+		return 1
+	}
+	defer shutdownTracing()
+
+	if help.WantsExamples(args) {
+		if c, rest, err := cmd.Find(args); err == nil {
+			if err := help.Examples(io.Out, c, rest); err != nil {
+				printError(io.ErrOut, cs, err)
+
+				return exitCode(err)
+			}
+
+			return 0
+		}
+	}
+
+	if wantsErrorJSON(nil, args) {
+		// keep cobra from printing errors and usage along with the envelope
+		cmd.SilenceErrors, cmd.SilenceUsage = true, true
+	}
+
+	timeout, err := commandTimeout(cmd, args)
+	if err != nil {
 		printError(io.ErrOut, cs, err)
 
-		return 126
-	default:
+		return exitCode(err)
+	}
+
+	c, err := execute(ctx, cmd, timeout)
+	if err == nil {
+		return 0
+	}
+
+	code := exitCode(err)
+
+	switch {
+	case wantsErrorJSON(c, args):
+		printErrorJSON(io.ErrOut, err, code)
+	case code != flyerr.ExitCodeCancelled:
 		printError(io.ErrOut, cs, err)
+	}
 
-		return 1
+	return code
+}
+
+// errorJSONEnvKey is the environment variable which, when truthy, has errors
+// printed as JSON objects, like --error-json does.
+const errorJSONEnvKey = "FLY_ERROR_JSON"
+
+func wantsErrorJSON(cmd *cobra.Command, args []string) bool {
+	if env.IsTruthy(errorJSONEnvKey) {
+		return true
+	}
+
+	if cmd != nil {
+		if v, err := cmd.Flags().GetBool(flag.ErrorJSONName); err == nil && v {
+			return true
+		}
 	}
+
+	// flags may have failed to parse
+	for _, arg := range args {
+		switch arg {
+		case "--":
+			return false
+		case "--" + flag.ErrorJSONName, "--" + flag.ErrorJSONName + "=true":
+			return true
+		}
+	}
+
+	return false
+}
+
+// usageErrorPrefixes are the prefixes of the errors cobra reports invalid
+// invocations with, which don't go through the flag error func.
+var usageErrorPrefixes = []string{
+	"unknown command",
+	"required flag(s)",
+	"if any flags in the group",
+}
+
+func exitCode(err error) int {
+	if errors.Is(err, terminal.InterruptErr) {
+		return flyerr.ExitCodeCancelled
+	}
+
+	if flyerr.GetExitCode(err) == 0 {
+		for _, prefix := range usageErrorPrefixes {
+			if strings.HasPrefix(err.Error(), prefix) {
+				return flyerr.ExitCodeValidation
+			}
+		}
+	}
+
+	return flyerr.ExitCode(err)
+}
+
+func printErrorJSON(w io.Writer, err error, code int) {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	_ = enc.Encode(flyerr.NewEnvelope(err, code))
 }
 
 func printError(w io.Writer, cs *iostreams.ColorScheme, err error) {
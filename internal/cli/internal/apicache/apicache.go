@@ -0,0 +1,232 @@
+// Package apicache implements the offline cache of API responses, which read
+// commands and shell completion fall back to while the API is unreachable.
+package apicache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/cli/internal/format"
+	"github.com/superfly/flyctl/internal/logger"
+)
+
+// DirName denotes the name of the directory, under the config directory, the
+// cache keeps its entries in.
+const DirName = "cache"
+
+// AppsKey is the key the apps list of the account is cached under, which
+// shell completion completes app names from.
+const AppsKey = "apps"
+
+// Dir returns the directory the cached responses the given access token got
+// from the API at the given base URL are kept in, so that accounts don't see
+// the responses of one another.
+func Dir(configDir, apiBaseURL, accessToken string) string {
+	sum := sha256.Sum256([]byte(apiBaseURL + "\n" + accessToken))
+
+	return filepath.Join(configDir, DirName, hex.EncodeToString(sum[:8]))
+}
+
+// Cache wraps the functionality of the offline cache. A nil Cache caches
+// nothing; its methods fetch from the API every time.
+type Cache struct {
+	dir     string
+	ttl     time.Duration
+	refresh bool
+	now     func() time.Time
+}
+
+// New returns a Cache which keeps the responses it caches in dir and serves
+// them for up to ttl. Caches which refresh don't serve cached responses, but
+// still cache the responses they fetch.
+func New(dir string, ttl time.Duration, refresh bool) *Cache {
+	return &Cache{
+		dir:     dir,
+		ttl:     ttl,
+		refresh: refresh,
+		now:     time.Now,
+	}
+}
+
+type entry struct {
+	FetchedAt time.Time       `json:"fetched_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Fetch calls fetch, which stores the response it fetches from the API in v,
+// a pointer, and caches the response under key. In case the API is
+// unavailable, Fetch stores the response it cached under key, if any, in v
+// instead and warns that it's stale.
+//
+// Fetch suits responses which change as the user works with the platform,
+// such as those listing apps or releases.
+func (c *Cache) Fetch(ctx context.Context, key string, v interface{}, fetch func(context.Context) error) error {
+	if c == nil {
+		return fetch(ctx)
+	}
+
+	err := fetch(ctx)
+	if err == nil {
+		c.store(ctx, key, v)
+
+		return nil
+	}
+
+	if c.refresh || !Unavailable(err) {
+		return err
+	}
+
+	fetchedAt, ok := c.Cached(key, v)
+	if !ok {
+		return err
+	}
+
+	logger.FromContext(ctx).Warnf("%v; showing the response cached %s", err, format.RelativeTime(fetchedAt))
+
+	return nil
+}
+
+// FetchCached is like Fetch, but stores the response it cached under key in v
+// without asking the API for as long as the response is cached.
+//
+// FetchCached suits responses which seldom change, such as those listing the
+// regions of the platform.
+func (c *Cache) FetchCached(ctx context.Context, key string, v interface{}, fetch func(context.Context) error) error {
+	if c != nil && !c.refresh {
+		if _, ok := c.Cached(key, v); ok {
+			return nil
+		}
+	}
+
+	return c.Fetch(ctx, key, v, fetch)
+}
+
+// Cached stores the response cached under key, if any, in v and reports when
+// it was fetched. Responses cached for longer than the ttl of c are removed
+// rather than stored.
+func (c *Cache) Cached(key string, v interface{}) (fetchedAt time.Time, ok bool) {
+	if c == nil {
+		return
+	}
+
+	path := c.path(key)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || c.now().Sub(e.FetchedAt) > c.ttl {
+		_ = os.Remove(path)
+
+		return
+	}
+
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return
+	}
+
+	return e.FetchedAt, true
+}
+
+// store caches v under key. Failing to cache is logged, rather than failing
+// the command which fetched v.
+func (c *Cache) store(ctx context.Context, key string, v interface{}) {
+	if err := c.write(key, v); err != nil {
+		logger.FromContext(ctx).Debugf("failed caching %s: %v", key, err)
+	}
+}
+
+func (c *Cache) write(key string, v interface{}) (err error) {
+	e := entry{
+		FetchedAt: c.now(),
+	}
+
+	if e.Data, err = json.Marshal(v); err != nil {
+		return
+	}
+
+	var data []byte
+	if data, err = json.Marshal(e); err != nil {
+		return
+	}
+
+	path := c.path(key)
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+
+	// readers never see partially written entries
+	var f *os.File
+	if f, err = os.CreateTemp(filepath.Dir(path), ".entry.*"); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+
+		return
+	}
+
+	if err = f.Close(); err != nil {
+		return
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// path returns the path of the file the entry of the given key is kept in.
+// Keys are slash separated, e.g. releases/myapp.
+func (c *Cache) path(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	return filepath.Join(c.dir, filepath.Join(segments...)+".json")
+}
+
+// Unavailable reports whether err denotes that the API is unreachable or
+// failing, rather than denying the request.
+func Unavailable(err error) bool {
+	var (
+		urlErr *url.Error
+		netErr net.Error
+		apiErr *api.ApiError
+	)
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return false
+	case errors.As(err, &urlErr), errors.As(err, &netErr):
+		return true
+	case errors.As(err, &apiErr):
+		return apiErr.Status == 429 || apiErr.Status >= 500
+	}
+
+	// the GraphQL client reports statuses by message only
+	msg := err.Error()
+	if i := strings.Index(msg, "non-200 status code: "); i >= 0 {
+		status := msg[i+len("non-200 status code: "):]
+
+		return strings.HasPrefix(status, "5") || strings.HasPrefix(status, "429")
+	}
+
+	return false
+}
@@ -0,0 +1,134 @@
+package apicache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/logger"
+)
+
+var errUnreachable = &url.Error{Op: "Post", URL: "https://api.fly.io/graphql", Err: errors.New("connection refused")}
+
+func newTestCache(t *testing.T, refresh bool) (*Cache, *time.Time) {
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	c := New(t.TempDir(), time.Hour, refresh)
+	c.now = func() time.Time { return now }
+
+	return c, &now
+}
+
+func fetching(apps []string, err error, calls *int) func(*[]string) func(context.Context) error {
+	return func(v *[]string) func(context.Context) error {
+		return func(context.Context) error {
+			*calls++
+			if err == nil {
+				*v = apps
+			}
+
+			return err
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	var out bytes.Buffer
+	ctx := logger.NewContext(context.Background(), logger.New(&out, logger.Warn))
+
+	c, now := newTestCache(t, false)
+
+	var calls int
+
+	var apps []string
+	require.NoError(t, c.Fetch(ctx, "apps", &apps, fetching([]string{"a", "b"}, nil, &calls)(&apps)))
+
+	// failures to reach the API fall back to the cache
+	apps = nil
+	require.NoError(t, c.Fetch(ctx, "apps", &apps, fetching(nil, errUnreachable, &calls)(&apps)))
+	assert.Equal(t, []string{"a", "b"}, apps)
+	assert.Contains(t, out.String(), "connection refused; showing the response cached")
+
+	// while the API's refusals don't
+	denied := errors.New("not authorized")
+	assert.Equal(t, denied, c.Fetch(ctx, "apps", &apps, fetching(nil, denied, &calls)(&apps)))
+
+	// and neither do responses cached for longer than the ttl
+	*now = now.Add(2 * time.Hour)
+	assert.Equal(t, errUnreachable, c.Fetch(ctx, "apps", &apps, fetching(nil, errUnreachable, &calls)(&apps)))
+
+	assert.Equal(t, 4, calls)
+}
+
+func TestFetchCached(t *testing.T) {
+	ctx := logger.NewContext(context.Background(), logger.New(&bytes.Buffer{}, logger.Warn))
+
+	c, _ := newTestCache(t, false)
+
+	var calls int
+
+	var regions []string
+	require.NoError(t, c.FetchCached(ctx, "platform/regions", &regions, fetching([]string{"iad"}, nil, &calls)(&regions)))
+
+	regions = nil
+	require.NoError(t, c.FetchCached(ctx, "platform/regions", &regions, fetching([]string{"ord"}, nil, &calls)(&regions)))
+	assert.Equal(t, []string{"iad"}, regions, "served from the cache")
+	assert.Equal(t, 1, calls)
+
+	// refreshing caches ask the API, and cache its response
+	r := New(c.dir, c.ttl, true)
+	r.now = c.now
+
+	require.NoError(t, r.FetchCached(ctx, "platform/regions", &regions, fetching([]string{"ord"}, nil, &calls)(&regions)))
+	assert.Equal(t, []string{"ord"}, regions)
+
+	regions = nil
+	require.NoError(t, c.FetchCached(ctx, "platform/regions", &regions, fetching(nil, errUnreachable, &calls)(&regions)))
+	assert.Equal(t, []string{"ord"}, regions)
+	assert.Equal(t, 2, calls)
+}
+
+func TestNilCache(t *testing.T) {
+	var (
+		c     *Cache
+		calls int
+		apps  []string
+	)
+
+	assert.Equal(t, errUnreachable, c.FetchCached(context.Background(), "apps", &apps, fetching(nil, errUnreachable, &calls)(&apps)))
+	assert.Equal(t, 1, calls)
+}
+
+func TestDir(t *testing.T) {
+	a := Dir("/home/.fly", "https://api.fly.io", "token-a")
+
+	assert.Equal(t, a, Dir("/home/.fly", "https://api.fly.io", "token-a"))
+	assert.NotEqual(t, a, Dir("/home/.fly", "https://api.fly.io", "token-b"))
+	assert.NotEqual(t, a, Dir("/home/.fly", "http://localhost:4000", "token-a"))
+}
+
+func TestUnavailable(t *testing.T) {
+	cases := map[error]bool{
+		errUnreachable:             true,
+		&api.ApiError{Status: 503}: true,
+		&api.ApiError{Status: 429}: true,
+		&api.ApiError{Status: 404}: false,
+
+		errors.New("server returned a non-200 status code: 502"): true,
+		errors.New("server returned a non-200 status code: 400"): false,
+		errors.New("Could not find App"):                         false,
+		fmt.Errorf("failed: %w", context.Canceled):               false,
+	}
+
+	for err, exp := range cases {
+		assert.Equal(t, exp, Unavailable(err), err.Error())
+	}
+}
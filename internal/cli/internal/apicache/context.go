@@ -0,0 +1,18 @@
+package apicache
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext derives a context that carries c from ctx.
+func NewContext(ctx context.Context, c *Cache) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the Cache ctx carries, or nil in case ctx carries none,
+// which caches nothing.
+func FromContext(ctx context.Context) *Cache {
+	c, _ := ctx.Value(contextKey{}).(*Cache)
+
+	return c
+}
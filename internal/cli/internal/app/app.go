@@ -20,24 +20,19 @@ import (
 // DefaultConfigFileName denotes the default application configuration file name.
 const DefaultConfigFileName = "fly.toml"
 
-// LoadConfig loads the app config at the given path.
+// LoadConfig loads the app config at the given path. The format of the file is
+// determined by its extension; see FormatFromPath.
 func LoadConfig(path string) (cfg *Config, err error) {
-	cfg = &Config{
-		Definition: map[string]interface{}{},
-	}
-
-	file, err := os.Open(path)
+	data, err := decodeFile(path)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if e := file.Close(); err == nil {
-			err = e
-		}
-	}()
 
-	cfg.Path = path
-	err = cfg.unmarshalTOML(file)
+	cfg = &Config{
+		Definition: map[string]interface{}{},
+		Path:       path,
+	}
+	err = cfg.unmarshalNativeMap(data)
 
 	return
 }
@@ -48,6 +43,10 @@ type Config struct {
 	Build      *Build
 	Definition map[string]interface{}
 	Path       string
+
+	// Environments holds the overrides of the [environments.<name>] sections
+	// keyed by environment name.
+	Environments map[string]map[string]interface{}
 }
 
 type Build struct {
@@ -62,6 +61,17 @@ type Build struct {
 	// Or...
 	Dockerfile        string
 	DockerBuildTarget string
+	// CachePaths holds the paths of the package manager download caches builds
+	// which cache dependencies mount.
+	CachePaths []string
+	// Static is the directory of a prebuilt static site to deploy on a managed
+	// web server, without a Dockerfile.
+	Static string
+	// StaticFallback is the file static sites serve for unmatched paths.
+	StaticFallback string
+	// StaticCache maps the path patterns of static sites to the Cache-Control
+	// header of the files they match.
+	StaticCache map[string]string
 }
 
 func (c *Config) HasDefinition() bool {
@@ -97,18 +107,10 @@ func (c *Config) DockerBuildTarget() string {
 	return c.Build.DockerBuildTarget
 }
 
+// EncodeTo writes c to w in the format of the file c was loaded from,
+// defaulting to TOML.
 func (c *Config) EncodeTo(w io.Writer) error {
-	return c.marshalTOML(w)
-}
-
-func (c *Config) unmarshalTOML(r io.Reader) (err error) {
-	var data map[string]interface{}
-
-	if _, err = toml.DecodeReader(r, &data); err == nil {
-		err = c.unmarshalNativeMap(data)
-	}
-
-	return
+	return c.Encode(w, FormatFromPath(c.Path))
 }
 
 func (c *Config) unmarshalNativeMap(data map[string]interface{}) error {
@@ -120,6 +122,9 @@ func (c *Config) unmarshalNativeMap(data map[string]interface{}) error {
 	c.Build = unmarshalBuild(data)
 	delete(data, "build")
 
+	c.Environments = unmarshalEnvironments(data)
+	delete(data, environmentsKey)
+
 	for k := range c.Definition {
 		delete(c.Definition, k)
 	}
@@ -170,18 +175,98 @@ func unmarshalBuild(data map[string]interface{}) *Build {
 			b.Dockerfile = fmt.Sprint(v)
 		case "build_target":
 			b.DockerBuildTarget = fmt.Sprint(v)
+		case "cache_paths":
+			if pathSlice, ok := v.([]interface{}); ok {
+				for _, p := range pathSlice {
+					b.CachePaths = append(b.CachePaths, fmt.Sprint(p))
+				}
+			}
+		case "static":
+			b.Static = fmt.Sprint(v)
+		case "static_fallback":
+			b.StaticFallback = fmt.Sprint(v)
+		case "static_cache":
+			if cacheMap, ok := v.(map[string]interface{}); ok {
+				b.StaticCache = make(map[string]string, len(cacheMap))
+				for pattern, value := range cacheMap {
+					b.StaticCache[pattern] = fmt.Sprint(value)
+				}
+			}
 		default:
 			b.Args[k] = fmt.Sprint(v)
 		}
 	}
 
-	if b.Builder == "" && b.Builtin == "" && b.Image == "" && b.Dockerfile == "" && len(b.Args) == 0 {
+	if b.Builder == "" && b.Builtin == "" && b.Image == "" && b.Dockerfile == "" && len(b.Args) == 0 && len(b.CachePaths) == 0 && b.Static == "" {
 		return nil
 	}
 
 	return b
 }
 
+// toMap returns the native map representation of b.
+func (b *Build) toMap() map[string]interface{} {
+	data := map[string]interface{}{}
+	if b == nil {
+		return data
+	}
+
+	if b.Builder != "" {
+		data["builder"] = b.Builder
+	}
+	if len(b.Buildpacks) > 0 {
+		buildpacks := make([]interface{}, 0, len(b.Buildpacks))
+		for _, bp := range b.Buildpacks {
+			buildpacks = append(buildpacks, bp)
+		}
+		data["buildpacks"] = buildpacks
+	}
+	if len(b.Args) > 0 {
+		args := make(map[string]interface{}, len(b.Args))
+		for k, v := range b.Args {
+			args[k] = v
+		}
+		data["args"] = args
+	}
+	if b.Builtin != "" {
+		data["builtin"] = b.Builtin
+		if len(b.Settings) > 0 {
+			data["settings"] = b.Settings
+		}
+	}
+	if b.Image != "" {
+		data["image"] = b.Image
+	}
+	if b.Dockerfile != "" {
+		data["dockerfile"] = b.Dockerfile
+	}
+	if b.DockerBuildTarget != "" {
+		data["build_target"] = b.DockerBuildTarget
+	}
+	if len(b.CachePaths) > 0 {
+		paths := make([]interface{}, 0, len(b.CachePaths))
+		for _, p := range b.CachePaths {
+			paths = append(paths, p)
+		}
+		data["cache_paths"] = paths
+	}
+	if b.Static != "" {
+		data["static"] = b.Static
+		if b.StaticFallback != "" {
+			data["static_fallback"] = b.StaticFallback
+		}
+		if len(b.StaticCache) > 0 {
+			cache := make(map[string]interface{}, len(b.StaticCache))
+			for pattern, value := range b.StaticCache {
+				cache[pattern] = value
+			}
+			data["static_cache"] = cache
+		}
+	}
+
+	return data
+}
+
 func (c *Config) marshalTOML(w io.Writer) error {
 	var b bytes.Buffer
 
@@ -196,48 +281,22 @@ func (c *Config) marshalTOML(w io.Writer) error {
 		return err
 	}
 
-	rawData = c.Definition
+	rawData = c.fullDefinition()
 
-	if c.Build != nil {
-		buildData := map[string]interface{}{}
-		if c.Build.Builder != "" {
-			buildData["builder"] = c.Build.Builder
-		}
-		if len(c.Build.Buildpacks) > 0 {
-			buildData["buildpacks"] = c.Build.Buildpacks
-		}
-		if len(c.Build.Args) > 0 {
-			buildData["args"] = c.Build.Args
-		}
-		if c.Build.Builtin != "" {
-			buildData["builtin"] = c.Build.Builtin
-			if len(c.Build.Settings) > 0 {
-				buildData["settings"] = c.Build.Settings
-			}
-		}
-		if c.Build.Image != "" {
-			buildData["image"] = c.Build.Image
-		}
-		if c.Build.Dockerfile != "" {
-			buildData["dockerfile"] = c.Build.Dockerfile
-		}
-		rawData["build"] = buildData
-	}
-
-	if len(c.Definition) > 0 {
+	if len(rawData) > 0 {
 		// roundtrip through json encoder to convert float64 numbers to json.Number, otherwise numbers are floats in toml
 		var buf bytes.Buffer
-		if err := json.NewEncoder(&buf).Encode(c.Definition); err != nil {
+		if err := json.NewEncoder(&buf).Encode(rawData); err != nil {
 			return err
 		}
 
 		d := json.NewDecoder(&buf)
 		d.UseNumber()
-		if err := d.Decode(&c.Definition); err != nil {
+		if err := d.Decode(&rawData); err != nil {
 			return err
 		}
 
-		if err := encoder.Encode(c.Definition); err != nil {
+		if err := encoder.Encode(rawData); err != nil {
 			return err
 		}
 	}
@@ -246,6 +305,7 @@ func (c *Config) marshalTOML(w io.Writer) error {
 	return err
 }
 
+// WriteToFile writes c to the named file in the format its extension denotes.
 func (c *Config) WriteToFile(filename string) (err error) {
 	if err = helpers.MkdirAll(filename); err != nil {
 		return
@@ -261,7 +321,7 @@ func (c *Config) WriteToFile(filename string) (err error) {
 		}
 	}()
 
-	err = c.EncodeTo(file)
+	err = c.Encode(file, FormatFromPath(filename))
 
 	return
 }
@@ -298,17 +358,25 @@ func (c *Config) InternalPort() (int, error) {
 		return -1, errors.New("could not find internal port setting")
 	}
 
-	services, ok := tmpservices.([]map[string]interface{})
-	if ok {
-		internalport, ok := services[0]["internal_port"].(int64)
-		if ok {
-			return int(internalport), nil
+	var service map[string]interface{}
+	switch services := tmpservices.(type) {
+	case []map[string]interface{}:
+		if len(services) > 0 {
+			service = services[0]
 		}
-		internalportfloat, ok := services[0]["internal_port"].(float64)
-		if ok {
-			return int(internalportfloat), nil
+	case []interface{}:
+		if len(services) > 0 {
+			service, _ = services[0].(map[string]interface{})
 		}
 	}
+
+	switch internalport := service["internal_port"].(type) {
+	case int64:
+		return int(internalport), nil
+	case float64:
+		return int(internalport), nil
+	}
+
 	return 8080, nil
 }
 
@@ -408,16 +476,19 @@ func (c *Config) SetEnvVariable(name, value string) {
 }
 
 func (c *Config) SetProcess(name, value string) {
-	var processes map[string]string
-
-	if rawProcesses, ok := c.Definition["processes"]; ok {
-		if castProcesses, ok := rawProcesses.(map[string]string); ok {
-			processes = castProcesses
+	var processes map[string]interface{}
+
+	// keep the groups the config already declares, however they were decoded
+	switch rawProcesses := c.Definition["processes"].(type) {
+	case map[string]interface{}:
+		processes = rawProcesses
+	case map[string]string:
+		processes = make(map[string]interface{}, len(rawProcesses))
+		for k, v := range rawProcesses {
+			processes[k] = v
 		}
-	}
-
-	if processes == nil {
-		processes = map[string]string{}
+	default:
+		processes = map[string]interface{}{}
 	}
 
 	processes[name] = value
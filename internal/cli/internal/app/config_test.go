@@ -1,6 +1,8 @@
 package app
 
 import (
+	"bytes"
+	"path/filepath"
 	"testing"
 
 	"github.com/BurntSushi/toml"
@@ -47,6 +49,17 @@ func TestLoadTOMLAppConfigWithBuilderNameAndArgs(t *testing.T) {
 	assert.Equal(t, p.Build.Args, map[string]string{"A": "B", "C": "D"})
 }
 
+func TestLoadTOMLAppConfigWithStaticSite(t *testing.T) {
+	const path = "./testdata/static.toml"
+
+	p, err := LoadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, p.Build.Static, "dist/")
+	assert.Equal(t, p.Build.StaticFallback, "/index.html")
+	assert.Equal(t, p.Build.StaticCache, map[string]string{"/assets/*": "public, max-age=31536000, immutable"})
+	assert.Empty(t, p.Build.Args)
+}
+
 func TestLoadTOMLAppConfigWithServices(t *testing.T) {
 	const path = "./testdata/services.toml"
 	p, err := LoadConfig(path)
@@ -59,3 +72,148 @@ func TestLoadTOMLAppConfigWithServices(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, p.Definition, rawData)
 }
+
+func TestApplyEnvironmentFromSection(t *testing.T) {
+	const path = "./testdata/environments.toml"
+
+	p, err := LoadConfig(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, p.Definition, "environments")
+
+	assert.NoError(t, p.ApplyEnvironment("staging"))
+	assert.Equal(t, "environments-staging", p.AppName)
+	assert.Equal(t, map[string]interface{}{"LOG_LEVEL": "debug", "PORT": "8080"}, p.Definition["env"])
+}
+
+func TestApplyEnvironmentFromOverlayFile(t *testing.T) {
+	const path = "./testdata/environments.toml"
+
+	p, err := LoadConfig(path)
+	assert.NoError(t, err)
+
+	assert.NoError(t, p.ApplyEnvironment("production"))
+	assert.Equal(t, "environments-production", p.AppName)
+	assert.Equal(t, "image/name", p.Build.Image)
+	assert.Equal(t, "Dockerfile.production", p.Build.Dockerfile)
+	assert.Equal(t, map[string]interface{}{"LOG_LEVEL": "warn", "PORT": "8080"}, p.Definition["env"])
+}
+
+func TestApplyUndefinedEnvironment(t *testing.T) {
+	const path = "./testdata/environments.toml"
+
+	p, err := LoadConfig(path)
+	assert.NoError(t, err)
+	assert.Error(t, p.ApplyEnvironment("qa"))
+}
+
+func TestEnvironmentsRoundtrip(t *testing.T) {
+	src, err := LoadConfig("./testdata/environments.toml")
+	assert.NoError(t, err)
+
+	for _, name := range []string{"fly.toml", "fly.yaml", "fly.json"} {
+		path := filepath.Join(t.TempDir(), name)
+		assert.NoError(t, src.WriteToFile(path), name)
+
+		dst, err := LoadConfig(path)
+		assert.NoError(t, err, name)
+		assert.Equal(t, src.Environments, dst.Environments, name)
+		assert.NotContains(t, dst.Definition, "environments", name)
+
+		assert.NoError(t, dst.ApplyEnvironment("staging"), name)
+		assert.Equal(t, "environments-staging", dst.AppName, name)
+	}
+}
+
+func TestOverlayFilePath(t *testing.T) {
+	assert.Equal(t, "dir/fly.staging.toml", OverlayFilePath("dir/fly.toml", "staging"))
+}
+
+func TestInterpolate(t *testing.T) {
+	const path = "./testdata/interpolation.toml"
+
+	p, err := LoadConfig(path)
+	assert.NoError(t, err)
+
+	vars := map[string]string{
+		"APP_NAME": "test-app",
+		"TAG":      "v1",
+		"HANDLER":  "http",
+	}
+	lookup := func(name string) (v string, ok bool) {
+		v, ok = vars[name]
+		return
+	}
+
+	undefined, err := p.Interpolate(lookup, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"REGION"}, undefined)
+	assert.Equal(t, "test-app", p.AppName)
+	assert.Equal(t, "registry.fly.io/test-app:v1", p.Build.Image)
+	assert.Equal(t, map[string]interface{}{"PRICE": "$5", "REGION": ""}, p.Definition["env"])
+
+	services := p.Definition["services"].([]map[string]interface{})
+	ports := services[0]["ports"].([]map[string]interface{})
+	assert.Equal(t, []interface{}{"http"}, ports[0]["handlers"])
+}
+
+func TestInterpolateStrict(t *testing.T) {
+	const path = "./testdata/interpolation.toml"
+
+	p, err := LoadConfig(path)
+	assert.NoError(t, err)
+
+	lookup := func(string) (string, bool) { return "", false }
+
+	_, err = p.Interpolate(lookup, true)
+	assert.EqualError(t, err, "app config references undefined variables: APP_NAME, HANDLER, REGION, TAG")
+}
+
+func TestLoadYAMLAndJSONAppConfig(t *testing.T) {
+	expected := map[string]interface{}{
+		"services": []interface{}{
+			map[string]interface{}{
+				"protocol":      "tcp",
+				"internal_port": int64(8080),
+				"ports": []interface{}{
+					map[string]interface{}{
+						"port":     int64(80),
+						"handlers": []interface{}{"http"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, path := range []string{"./testdata/services.yaml", "./testdata/services.json"} {
+		p, err := LoadConfig(path)
+		assert.NoError(t, err, path)
+		assert.Equal(t, "services", p.AppName, path)
+		assert.Equal(t, "image/name", p.Build.Image, path)
+		assert.Equal(t, expected, p.Definition, path)
+	}
+}
+
+func TestEncodeRoundtrip(t *testing.T) {
+	src, err := LoadConfig("./testdata/services.yaml")
+	assert.NoError(t, err)
+
+	for _, format := range []Format{TOMLFormat, YAMLFormat, JSONFormat} {
+		var buf bytes.Buffer
+		assert.NoError(t, src.Encode(&buf, format), format)
+
+		data, err := decode(&buf, format)
+		assert.NoError(t, err, format)
+
+		var dst Config
+		assert.NoError(t, dst.unmarshalNativeMap(data), format)
+		assert.Equal(t, src.AppName, dst.AppName, format)
+		assert.Equal(t, src.Build.Image, dst.Build.Image, format)
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	assert.Equal(t, YAMLFormat, FormatFromPath("fly.yml"))
+	assert.Equal(t, JSONFormat, FormatFromPath("dir/fly.JSON"))
+	assert.Equal(t, TOMLFormat, FormatFromPath("fly.toml"))
+	assert.Equal(t, TOMLFormat, FormatFromPath("config"))
+}
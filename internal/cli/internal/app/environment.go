@@ -0,0 +1,144 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// environmentsKey denotes the key of the app config section which holds the
+// per-environment overrides.
+const environmentsKey = "environments"
+
+// OverlayFilePath returns the path of the overlay file for the named
+// environment which accompanies the config file at the given path. For
+// ./fly.toml and staging that would be ./fly.staging.toml.
+func OverlayFilePath(path, env string) string {
+	ext := filepath.Ext(path)
+
+	return strings.TrimSuffix(path, ext) + "." + env + ext
+}
+
+// ApplyEnvironment merges the overrides of the named environment onto c.
+//
+// Overrides are first read from the [environments.<env>] section of the config
+// file and then from the overlay file next to it (see OverlayFilePath), should
+// one exist. Tables are merged recursively while any other value, arrays
+// included, replaces the one it overrides.
+func (c *Config) ApplyEnvironment(env string) (err error) {
+	var found bool
+
+	if section, ok := c.Environments[env]; ok {
+		found = true
+
+		c.applyOverrides(section)
+	}
+
+	if c.Path != "" {
+		path := OverlayFilePath(c.Path, env)
+
+		var data map[string]interface{}
+		switch data, err = decodeFile(path); {
+		case err == nil:
+			found = true
+
+			c.applyOverrides(data)
+		case errors.Is(err, fs.ErrNotExist):
+			err = nil
+		default:
+			return fmt.Errorf("failed loading overlay for environment %s from %s: %w", env, path, err)
+		}
+	}
+
+	if !found {
+		err = fmt.Errorf("environment %s is not defined in the app config nor in an overlay file", env)
+	}
+
+	return
+}
+
+func (c *Config) applyOverrides(data map[string]interface{}) {
+	data = copyMap(data)
+
+	if name, ok := (data["app"]).(string); ok {
+		c.AppName = name
+	}
+	delete(data, "app")
+
+	if build, ok := (data["build"]).(map[string]interface{}); ok {
+		raw := c.Build.toMap()
+		mergeMaps(raw, build)
+
+		c.Build = unmarshalBuild(map[string]interface{}{
+			"build": raw,
+		})
+	}
+	delete(data, "build")
+
+	// overlays may not define further environments
+	delete(data, environmentsKey)
+
+	if c.Definition == nil {
+		c.Definition = map[string]interface{}{}
+	}
+	mergeMaps(c.Definition, data)
+}
+
+// mergeMaps merges src onto dst recursively.
+func mergeMaps(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+
+		if srcIsMap && dstIsMap {
+			mergeMaps(dstMap, srcMap)
+
+			continue
+		}
+
+		if srcIsMap {
+			v = copyMap(srcMap)
+		}
+		dst[k] = v
+	}
+}
+
+func copyMap(src map[string]interface{}) map[string]interface{} {
+	dst := make(map[string]interface{}, len(src))
+
+	for k, v := range src {
+		if m, ok := v.(map[string]interface{}); ok {
+			v = copyMap(m)
+		}
+		dst[k] = v
+	}
+
+	return dst
+}
+
+func unmarshalEnvironments(data map[string]interface{}) map[string]map[string]interface{} {
+	raw, ok := (data[environmentsKey]).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	environments := make(map[string]map[string]interface{}, len(raw))
+	for name, v := range raw {
+		if section, ok := v.(map[string]interface{}); ok {
+			environments[name] = section
+		}
+	}
+
+	return environments
+}
+
+func marshalEnvironments(environments map[string]map[string]interface{}) map[string]interface{} {
+	data := make(map[string]interface{}, len(environments))
+	for name, section := range environments {
+		data[name] = copyMap(section)
+	}
+
+	return data
+}
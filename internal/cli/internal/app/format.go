@@ -0,0 +1,127 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/superfly/flyctl/internal/appconfig"
+)
+
+// Format denotes the format of an app config file.
+type Format string
+
+const (
+	// TOMLFormat denotes the TOML format.
+	TOMLFormat Format = "toml"
+
+	// YAMLFormat denotes the YAML format.
+	YAMLFormat Format = "yaml"
+
+	// JSONFormat denotes the JSON format.
+	JSONFormat Format = "json"
+)
+
+// ConfigFileNames denotes the names an app config file may have in order of
+// preference.
+var ConfigFileNames = []string{
+	DefaultConfigFileName,
+	"fly.yaml",
+	"fly.yml",
+	"fly.json",
+}
+
+// FormatFromPath returns the Format of the app config file at the given path
+// based on its extension. Files without a YAML or JSON extension are
+// considered TOML.
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return YAMLFormat
+	case ".json":
+		return JSONFormat
+	default:
+		return TOMLFormat
+	}
+}
+
+// decodeFile decodes the app config file at the given path into its native
+// map representation.
+func decodeFile(path string) (data map[string]interface{}, err error) {
+	var f *os.File
+	if f, err = os.Open(path); err != nil {
+		return
+	}
+	defer func() {
+		if e := f.Close(); err == nil {
+			err = e
+		}
+	}()
+
+	data, err = decode(f, FormatFromPath(path))
+
+	return
+}
+
+func decode(r io.Reader, format Format) (data map[string]interface{}, err error) {
+	switch format {
+	case TOMLFormat:
+		_, err = toml.DecodeReader(r, &data)
+	case YAMLFormat:
+		data, err = appconfig.DecodeYAML(r)
+	case JSONFormat:
+		data, err = appconfig.DecodeJSON(r)
+	default:
+		err = fmt.Errorf("unsupported app config format: %q", format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+
+	return
+}
+
+// Encode writes c to w in the given format.
+func (c *Config) Encode(w io.Writer, format Format) error {
+	switch format {
+	case TOMLFormat:
+		return c.marshalTOML(w)
+	case YAMLFormat:
+		return c.marshalYAML(w)
+	case JSONFormat:
+		return c.marshalJSON(w)
+	default:
+		return fmt.Errorf("unsupported app config format: %q", format)
+	}
+}
+
+// fullDefinition returns the definition of c, build and environments sections
+// included, minus its app name.
+func (c *Config) fullDefinition() map[string]interface{} {
+	data := copyMap(c.Definition)
+	if c.Build != nil {
+		data["build"] = c.Build.toMap()
+	}
+	if len(c.Environments) > 0 {
+		data[environmentsKey] = marshalEnvironments(c.Environments)
+	}
+
+	return data
+}
+
+func (c *Config) marshalYAML(w io.Writer) error {
+	return appconfig.EncodeYAML(w, c.AppName, c.fullDefinition())
+}
+
+func (c *Config) marshalJSON(w io.Writer) error {
+	return appconfig.EncodeJSON(w, c.AppName, c.fullDefinition())
+}
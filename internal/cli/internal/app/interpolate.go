@@ -0,0 +1,111 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// VarLookupFunc is the function signature Interpolate uses in order to resolve
+// the values of variables.
+type VarLookupFunc func(name string) (string, bool)
+
+var interpolationPattern = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Interpolate replaces the ${NAME} references the string values of c contain
+// with the values lookup resolves for them. $$ escapes a literal $.
+//
+// Interpolate returns the names of the variables lookup couldn't resolve in
+// sorted order. Should strict be set, Interpolate errors out in case there are
+// any. Otherwise they expand to an empty string.
+func (c *Config) Interpolate(lookup VarLookupFunc, strict bool) (undefined []string, err error) {
+	missing := map[string]struct{}{}
+
+	expand := func(s string) string {
+		return expandVars(s, lookup, missing)
+	}
+
+	c.AppName = expand(c.AppName)
+
+	if c.Build != nil {
+		raw := interpolateValue(c.Build.toMap(), expand)
+
+		c.Build = unmarshalBuild(map[string]interface{}{
+			"build": raw,
+		})
+	}
+
+	for k, v := range c.Definition {
+		c.Definition[k] = interpolateValue(v, expand)
+	}
+
+	if undefined = sortedNames(missing); strict && len(undefined) > 0 {
+		err = fmt.Errorf("app config references undefined variables: %s", strings.Join(undefined, ", "))
+	}
+
+	return
+}
+
+// ExpandVars replaces the ${NAME} references s contains with the values lookup
+// resolves for them, the same way Interpolate does. It returns the names of
+// the variables lookup couldn't resolve in sorted order.
+func ExpandVars(s string, lookup VarLookupFunc) (string, []string) {
+	undefined := map[string]struct{}{}
+
+	s = expandVars(s, lookup, undefined)
+
+	return s, sortedNames(undefined)
+}
+
+func expandVars(s string, lookup VarLookupFunc, undefined map[string]struct{}) string {
+	return interpolationPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if match == "$$" {
+			return "$"
+		}
+
+		name := match[2 : len(match)-1]
+		if v, ok := lookup(name); ok {
+			return v
+		}
+
+		undefined[name] = struct{}{}
+
+		return ""
+	})
+}
+
+func sortedNames(set map[string]struct{}) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func interpolateValue(v interface{}, expand func(string) string) interface{} {
+	switch v := v.(type) {
+	case string:
+		return expand(v)
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = interpolateValue(e, expand)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = interpolateValue(e, expand)
+		}
+	case []map[string]interface{}:
+		for _, e := range v {
+			interpolateValue(e, expand)
+		}
+	case map[string]string:
+		for k, e := range v {
+			v[k] = expand(e)
+		}
+	}
+
+	return v
+}
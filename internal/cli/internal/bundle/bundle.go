@@ -0,0 +1,350 @@
+// Package bundle implements reading and writing of app bundles; gzipped
+// tarballs which describe the reproducible state of an application.
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// Version denotes the version of the bundle format.
+const Version = 1
+
+const (
+	manifestFileName = "manifest.json"
+	stateFileName    = "state.json"
+)
+
+// Manifest wraps the metadata of a bundle.
+type Manifest struct {
+	Version      int       `json:"version"`
+	App          string    `json:"app"`
+	Organization string    `json:"organization"`
+	ExportedAt   time.Time `json:"exported_at"`
+}
+
+// Bundle wraps the reproducible state of an application.
+type Bundle struct {
+	Manifest Manifest `json:"-"`
+
+	// Definition denotes the application's deployed configuration.
+	Definition api.Definition `json:"definition"`
+
+	// Image denotes the reference of the image the application runs.
+	Image string `json:"image,omitempty"`
+
+	// SecretKeys denotes the names of the application's secrets. Values are
+	// never exported.
+	SecretKeys []string `json:"secret_keys"`
+
+	Volumes      []Volume               `json:"volumes"`
+	Certificates []string               `json:"certificates"`
+	Scale        Scale                  `json:"scale"`
+	Autoscaling  *api.AutoscalingConfig `json:"autoscaling,omitempty"`
+}
+
+// Volume wraps the metadata of a volume.
+type Volume struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name"`
+	Region    string `json:"region"`
+	SizeGb    int    `json:"size_gb"`
+	Encrypted bool   `json:"encrypted"`
+
+	// Snapshot, when set, denotes the ID of the snapshot the volume is
+	// restored from. Volumes without one are recreated empty.
+	Snapshot string `json:"snapshot,omitempty"`
+}
+
+// Scale wraps the scaling properties of an application.
+type Scale struct {
+	VMSize        string               `json:"vm_size"`
+	MemoryMB      int                  `json:"memory_mb"`
+	Counts        []api.TaskGroupCount `json:"counts"`
+	Regions       []string             `json:"regions"`
+	BackupRegions []string             `json:"backup_regions"`
+}
+
+// Collect returns the Bundle of the named application.
+func Collect(ctx context.Context, client *api.Client, appName string) (*Bundle, error) {
+	app, err := client.GetAppCompact(ctx, appName)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	b := &Bundle{
+		Manifest: Manifest{
+			Version:      Version,
+			App:          app.Name,
+			Organization: app.Organization.Slug,
+			ExportedAt:   time.Now().UTC(),
+		},
+	}
+
+	cfg, err := client.GetConfig(ctx, appName)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving config of %s: %w", appName, err)
+	}
+	b.Definition = cfg.Definition
+
+	info, err := client.GetImageInfo(ctx, appName)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving image of %s: %w", appName, err)
+	}
+	b.Image = imageRef(info.ImageDetails)
+
+	secrets, err := client.GetAppSecrets(ctx, appName)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving secrets of %s: %w", appName, err)
+	}
+	for _, secret := range secrets {
+		b.SecretKeys = append(b.SecretKeys, secret.Name)
+	}
+
+	volumes, err := client.GetVolumes(ctx, appName)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving volumes of %s: %w", appName, err)
+	}
+	for _, vol := range volumes {
+		b.Volumes = append(b.Volumes, Volume{
+			ID:        vol.ID,
+			Name:      vol.Name,
+			Region:    vol.Region,
+			SizeGb:    vol.SizeGb,
+			Encrypted: vol.Encrypted,
+		})
+	}
+
+	certs, err := client.GetAppCertificates(ctx, appName)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving certificates of %s: %w", appName, err)
+	}
+	for _, cert := range certs {
+		b.Certificates = append(b.Certificates, cert.Hostname)
+	}
+
+	size, counts, _, err := client.AppVMResources(ctx, appName)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving scale of %s: %w", appName, err)
+	}
+	b.Scale.VMSize = size.Name
+	b.Scale.MemoryMB = size.MemoryMB
+	b.Scale.Counts = counts
+
+	regions, backupRegions, err := client.ListAppRegions(ctx, appName)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving regions of %s: %w", appName, err)
+	}
+	b.Scale.Regions = regionCodes(regions)
+	b.Scale.BackupRegions = regionCodes(backupRegions)
+
+	if b.Autoscaling, err = client.AppAutoscalingConfig(ctx, appName); err != nil {
+		return nil, fmt.Errorf("failed retrieving autoscaling config of %s: %w", appName, err)
+	}
+
+	return b, nil
+}
+
+// UseLatestSnapshots sets the volumes of b to be restored from the latest
+// snapshots of the volumes they were collected from. It returns the names of
+// the volumes which have no snapshots, and so will be recreated empty.
+func (b *Bundle) UseLatestSnapshots(ctx context.Context, client *api.Client) (empty []string, err error) {
+	for i := range b.Volumes {
+		vol := &b.Volumes[i]
+
+		var snapshots []api.Snapshot
+		if snapshots, err = client.GetVolumeSnapshots(ctx, vol.ID); err != nil {
+			err = fmt.Errorf("failed retrieving snapshots of volume %s: %w", vol.ID, err)
+
+			return
+		}
+
+		if latest := latestSnapshot(snapshots); latest != nil {
+			vol.Snapshot = latest.ID
+		} else {
+			empty = append(empty, vol.Name)
+		}
+	}
+
+	return
+}
+
+func latestSnapshot(snapshots []api.Snapshot) (latest *api.Snapshot) {
+	for i := range snapshots {
+		if s := &snapshots[i]; latest == nil || s.CreatedAt.After(latest.CreatedAt) {
+			latest = s
+		}
+	}
+
+	return
+}
+
+func imageRef(img api.ImageVersion) string {
+	if img.Repository == "" {
+		return ""
+	}
+
+	ref := img.Repository
+	if img.Registry != "" {
+		ref = img.Registry + "/" + ref
+	}
+
+	switch {
+	case img.Digest != "":
+		return ref + "@" + img.Digest
+	case img.Tag != "":
+		return ref + ":" + img.Tag
+	default:
+		return ref
+	}
+}
+
+func regionCodes(regions []api.Region) (codes []string) {
+	for _, region := range regions {
+		codes = append(codes, region.Code)
+	}
+
+	return
+}
+
+// WriteFile writes b to the named file.
+func (b *Bundle) WriteFile(path string) (err error) {
+	var f *os.File
+	if f, err = os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600); err != nil {
+		return
+	}
+	defer func() {
+		if e := f.Close(); err == nil {
+			err = e
+		}
+	}()
+
+	err = b.Write(f)
+
+	return
+}
+
+// Write writes b to w.
+func (b *Bundle) Write(w io.Writer) (err error) {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	files := []struct {
+		name string
+		v    interface{}
+	}{
+		{manifestFileName, b.Manifest},
+		{stateFileName, b},
+	}
+
+	for _, file := range files {
+		if err = writeJSON(tw, file.name, b.Manifest.ExportedAt, file.v); err != nil {
+			return
+		}
+	}
+
+	if err = tw.Close(); err == nil {
+		err = gw.Close()
+	}
+
+	return
+}
+
+func writeJSON(tw *tar.Writer, name string, modTime time.Time, v interface{}) (err error) {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(v); err != nil {
+		return
+	}
+
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(buf.Len()),
+		ModTime: modTime,
+	}
+
+	if err = tw.WriteHeader(hdr); err == nil {
+		_, err = buf.WriteTo(tw)
+	}
+
+	return
+}
+
+// ReadFile reads the bundle the named file contains.
+func ReadFile(path string) (b *Bundle, err error) {
+	var f *os.File
+	if f, err = os.Open(path); err != nil {
+		return
+	}
+	defer func() {
+		if e := f.Close(); err == nil {
+			err = e
+		}
+	}()
+
+	b, err = Read(f)
+
+	return
+}
+
+// Read reads the bundle r contains.
+func Read(r io.Reader) (*Bundle, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed decompressing bundle: %w", err)
+	}
+	defer gr.Close()
+
+	var (
+		b           Bundle
+		hasManifest bool
+		hasState    bool
+	)
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed reading bundle: %w", err)
+		}
+
+		switch hdr.Name {
+		case manifestFileName:
+			hasManifest = true
+			err = json.NewDecoder(tr).Decode(&b.Manifest)
+		case stateFileName:
+			hasState = true
+			err = json.NewDecoder(tr).Decode(&b)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed decoding %s: %w", hdr.Name, err)
+		}
+	}
+
+	switch {
+	case !hasManifest:
+		return nil, fmt.Errorf("bundle is missing its %s", manifestFileName)
+	case !hasState:
+		return nil, fmt.Errorf("bundle is missing its %s", stateFileName)
+	case b.Manifest.Version > Version:
+		return nil, fmt.Errorf("bundle version %d is not supported; please upgrade", b.Manifest.Version)
+	}
+
+	return &b, nil
+}
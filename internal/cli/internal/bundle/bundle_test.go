@@ -0,0 +1,76 @@
+package bundle
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestRoundtrip(t *testing.T) {
+	exp := &Bundle{
+		Manifest: Manifest{
+			Version:      Version,
+			App:          "test-app",
+			Organization: "personal",
+			ExportedAt:   time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Definition: api.Definition{"kill_signal": "SIGINT"},
+		Image:      "registry.fly.io/test-app@sha256:abc",
+		SecretKeys: []string{"DATABASE_URL"},
+		Volumes: []Volume{
+			{Name: "data", Region: "ams", SizeGb: 10, Encrypted: true},
+		},
+		Certificates: []string{"example.com"},
+		Scale: Scale{
+			VMSize:   "shared-cpu-1x",
+			MemoryMB: 256,
+			Counts:   []api.TaskGroupCount{{Name: "app", Count: 2}},
+			Regions:  []string{"ams"},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, exp.Write(&buf))
+
+	got, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, exp, got)
+}
+
+func TestReadRejectsNewerVersions(t *testing.T) {
+	b := &Bundle{
+		Manifest: Manifest{
+			Version: Version + 1,
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, b.Write(&buf))
+
+	_, err := Read(&buf)
+	assert.Error(t, err)
+}
+
+func TestImageRef(t *testing.T) {
+	assert.Equal(t, "", imageRef(api.ImageVersion{}))
+	assert.Equal(t, "registry.fly.io/app:v1", imageRef(api.ImageVersion{Registry: "registry.fly.io", Repository: "app", Tag: "v1"}))
+	assert.Equal(t, "registry.fly.io/app@sha256:1", imageRef(api.ImageVersion{Registry: "registry.fly.io", Repository: "app", Tag: "v1", Digest: "sha256:1"}))
+}
+
+func TestLatestSnapshot(t *testing.T) {
+	assert.Nil(t, latestSnapshot(nil))
+
+	at := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := []api.Snapshot{
+		{ID: "vs_1", CreatedAt: at},
+		{ID: "vs_3", CreatedAt: at.Add(2 * time.Hour)},
+		{ID: "vs_2", CreatedAt: at.Add(time.Hour)},
+	}
+
+	assert.Equal(t, "vs_3", latestSnapshot(snapshots).ID)
+}
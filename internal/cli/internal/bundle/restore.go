@@ -0,0 +1,229 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/superfly/flyctl/api"
+
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+// RestoreOptions wraps the set of options Restore accepts.
+type RestoreOptions struct {
+	// Name denotes the name of the app to create. Defaults to the name of the
+	// exported app.
+	Name string
+
+	// Organization denotes the organization to create the app in.
+	Organization *api.Organization
+
+	// Region, when set, overrides the regions the bundle defines.
+	Region string
+
+	// SkipCertificates instructs Restore to not add the bundle's
+	// certificates to the new app.
+	SkipCertificates bool
+
+	// Secrets denotes the secrets to set on the new app before deploying it.
+	Secrets map[string]string
+
+	// SkipDeploy instructs Restore to not deploy the bundle's image, such as
+	// when secrets the image needs are yet to be set.
+	SkipDeploy bool
+}
+
+// Restore creates a new app off of b. Volumes are restored from their
+// snapshots, if any, or recreated empty. Secrets other than the ones opts
+// defines are left for the caller to set since their values are never
+// exported.
+func Restore(ctx context.Context, b *Bundle, opts RestoreOptions) (*api.App, *api.Release, error) {
+	client := client.FromContext(ctx).API()
+
+	name := opts.Name
+	if name == "" {
+		name = b.Manifest.App
+	}
+
+	tb := render.NewTextBlock(ctx, "Creating app ", name)
+
+	input := api.CreateAppInput{
+		Name:           name,
+		Runtime:        "FIRECRACKER",
+		OrganizationID: opts.Organization.ID,
+	}
+	if opts.Region != "" {
+		input.PreferredRegion = api.StringPointer(opts.Region)
+	}
+
+	app, err := client.CreateApp(ctx, input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed creating app %s: %w", name, err)
+	}
+	tb.Donef("Created app %s in organization %s", app.Name, opts.Organization.Slug)
+
+	if err := restoreVolumes(ctx, app, b, opts.Region); err != nil {
+		return app, nil, err
+	}
+
+	if err := restoreScale(ctx, app, b, opts.Region); err != nil {
+		return app, nil, err
+	}
+
+	if !opts.SkipCertificates {
+		restoreCertificates(ctx, app, b)
+	}
+
+	if len(opts.Secrets) > 0 {
+		tb = render.NewTextBlock(ctx, "Setting secrets")
+
+		if _, err := client.SetSecrets(ctx, app.Name, opts.Secrets); err != nil {
+			return app, nil, fmt.Errorf("failed setting secrets: %w", err)
+		}
+		tb.Donef("Set %d secrets", len(opts.Secrets))
+	}
+
+	if b.Image == "" || opts.SkipDeploy {
+		return app, nil, nil
+	}
+
+	tb = render.NewTextBlock(ctx, "Deploying ", b.Image)
+
+	release, _, err := client.DeployImage(ctx, api.DeployImageInput{
+		AppID:      app.ID,
+		Image:      b.Image,
+		Definition: api.DefinitionPtr(b.Definition),
+	})
+	if err != nil {
+		return app, nil, fmt.Errorf("failed deploying %s: %w", b.Image, err)
+	}
+	tb.Donef("release v%d created", release.Version)
+
+	return app, release, nil
+}
+
+func restoreVolumes(ctx context.Context, app *api.App, b *Bundle, region string) error {
+	if len(b.Volumes) == 0 {
+		return nil
+	}
+
+	tb := render.NewTextBlock(ctx, "Creating volumes")
+	client := client.FromContext(ctx).API()
+
+	for _, vol := range b.Volumes {
+		input := api.CreateVolumeInput{
+			AppID:     app.ID,
+			Name:      vol.Name,
+			Region:    vol.Region,
+			SizeGb:    vol.SizeGb,
+			Encrypted: vol.Encrypted,
+		}
+		if region != "" {
+			input.Region = region
+		}
+		if vol.Snapshot != "" {
+			input.SnapshotID = api.StringPointer(vol.Snapshot)
+		}
+
+		created, err := client.CreateVolume(ctx, input)
+		if err != nil {
+			return fmt.Errorf("failed creating volume %s: %w", vol.Name, err)
+		}
+
+		if vol.Snapshot != "" {
+			tb.Detailf("created volume %s (%dGB) in %s from snapshot %s", created.Name, created.SizeGb, created.Region, vol.Snapshot)
+		} else {
+			tb.Detailf("created volume %s (%dGB) in %s", created.Name, created.SizeGb, created.Region)
+		}
+	}
+
+	tb.Done("Created volumes")
+
+	return nil
+}
+
+func restoreScale(ctx context.Context, app *api.App, b *Bundle, region string) error {
+	tb := render.NewTextBlock(ctx, "Scaling app")
+	client := client.FromContext(ctx).API()
+
+	regions := b.Scale.Regions
+	backupRegions := b.Scale.BackupRegions
+	if region != "" {
+		regions, backupRegions = []string{region}, nil
+	}
+
+	if len(regions) > 0 || len(backupRegions) > 0 {
+		input := api.ConfigureRegionsInput{
+			AppID:         app.ID,
+			AllowRegions:  regions,
+			BackupRegions: backupRegions,
+		}
+
+		if _, _, err := client.ConfigureRegions(ctx, input); err != nil {
+			return fmt.Errorf("failed configuring regions: %w", err)
+		}
+		tb.Detailf("regions: %v", regions)
+	}
+
+	if b.Scale.VMSize != "" {
+		if _, err := client.SetAppVMSize(ctx, app.ID, "", b.Scale.VMSize, int64(b.Scale.MemoryMB)); err != nil {
+			return fmt.Errorf("failed setting VM size: %w", err)
+		}
+		tb.Detailf("vm size: %s (%dMB)", b.Scale.VMSize, b.Scale.MemoryMB)
+	}
+
+	if len(b.Scale.Counts) > 0 {
+		counts := make(map[string]int, len(b.Scale.Counts))
+		for _, c := range b.Scale.Counts {
+			counts[c.Name] = c.Count
+		}
+
+		if _, _, err := client.SetAppVMCount(ctx, app.ID, counts, nil); err != nil {
+			return fmt.Errorf("failed setting VM count: %w", err)
+		}
+		tb.Detailf("counts: %v", counts)
+	}
+
+	if as := b.Autoscaling; as != nil && as.Enabled {
+		input := api.UpdateAutoscaleConfigInput{
+			AppID:          app.ID,
+			Enabled:        api.BoolPointer(true),
+			MinCount:       api.IntPointer(as.MinCount),
+			MaxCount:       api.IntPointer(as.MaxCount),
+			BalanceRegions: api.BoolPointer(as.BalanceRegions),
+		}
+
+		if _, err := client.UpdateAutoscaleConfig(ctx, input); err != nil {
+			return fmt.Errorf("failed configuring autoscaling: %w", err)
+		}
+		tb.Detailf("autoscaling: min=%d max=%d", as.MinCount, as.MaxCount)
+	}
+
+	tb.Done("Scaled app")
+
+	return nil
+}
+
+// restoreCertificates adds the certificates of b to app. Since hostnames may
+// still be in use by the exported app, failures are reported but not fatal.
+func restoreCertificates(ctx context.Context, app *api.App, b *Bundle) {
+	if len(b.Certificates) == 0 {
+		return
+	}
+
+	tb := render.NewTextBlock(ctx, "Adding certificates")
+	client := client.FromContext(ctx).API()
+
+	for _, hostname := range b.Certificates {
+		if _, _, err := client.AddCertificate(ctx, app.Name, hostname); err != nil {
+			tb.Detailf("failed adding certificate for %s: %v", hostname, err)
+
+			continue
+		}
+
+		tb.Detailf("added certificate for %s", hostname)
+	}
+
+	tb.Done("Added certificates")
+}
@@ -29,9 +29,13 @@ func New() (cmd *cobra.Command) {
 	cmd.AddCommand(
 		newRun(),
 		newPing(),
+		newStatus(),
+		newLogs(),
 		newStart(),
 		newStop(),
 		newRestart(),
+		newInstallService(),
+		newUninstallService(),
 	)
 
 	if env.IsTruthy("DEV") {
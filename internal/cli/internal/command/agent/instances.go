@@ -56,7 +56,7 @@ func runInstances(ctx context.Context) (err error) {
 	}
 
 	out := iostreams.FromContext(ctx).Out
-	err = render.JSON(out, instances)
+	err = render.Structured(ctx, out, instances)
 
 	return
 }
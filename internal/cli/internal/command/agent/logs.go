@@ -0,0 +1,124 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/azazeal/pause"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/pkg/agent"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+)
+
+func newLogs() (cmd *cobra.Command) {
+	const (
+		short = "Show the logs of the Fly agent"
+		long  = short + `.
+
+The logs are those of the running agent or, in case none is running, those of
+the agent which started most recently.
+`
+	)
+
+	cmd = command.New("logs", short, long, runLogs)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.Int{
+			Name:        "lines",
+			Shorthand:   "n",
+			Description: "Number of lines to show from the end of the logs; 0 shows all of them",
+			Default:     100,
+		},
+		flag.Bool{
+			Name:        "follow",
+			Shorthand:   "f",
+			Description: "Keep showing the logs as the agent writes them",
+		},
+	)
+
+	return
+}
+
+func runLogs(ctx context.Context) (err error) {
+	var path string
+	if path, err = logFile(ctx); err != nil {
+		return
+	}
+
+	var f *os.File
+	if f, err = os.Open(path); err != nil {
+		return fmt.Errorf("failed opening agent log file: %w", err)
+	}
+	defer f.Close()
+
+	var data []byte
+	if data, err = io.ReadAll(f); err != nil {
+		return fmt.Errorf("failed reading agent log file: %w", err)
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	if _, err = out.Write(lastLines(data, flag.GetInt(ctx, "lines"))); err != nil || !flag.GetBool(ctx, "follow") {
+		return
+	}
+
+	for {
+		if pause.For(ctx, 500*time.Millisecond); ctx.Err() != nil {
+			return nil
+		}
+
+		if _, err = io.Copy(out, f); err != nil {
+			return fmt.Errorf("failed reading agent log file: %w", err)
+		}
+	}
+}
+
+var errForeground = errors.New("the agent runs in the foreground; its logs are written to its standard output")
+
+func logFile(ctx context.Context) (string, error) {
+	if client, err := dial(ctx); err == nil {
+		if status, err := client.Status(ctx); err == nil {
+			if status.LogFile == "" {
+				return "", errForeground
+			}
+
+			return status.LogFile, nil
+		}
+	}
+
+	return agent.LatestLogFile()
+}
+
+// lastLines returns the last n lines of data, or all of data in case n isn't
+// positive.
+func lastLines(data []byte, n int) []byte {
+	if n <= 0 {
+		return data
+	}
+
+	end := len(data)
+	if end > 0 && data[end-1] == '\n' {
+		end--
+	}
+
+	for i := end; i > 0; i-- {
+		if data[i-1] != '\n' {
+			continue
+		}
+
+		if n--; n == 0 {
+			return data[i:]
+		}
+	}
+
+	return data
+}
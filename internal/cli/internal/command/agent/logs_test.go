@@ -0,0 +1,19 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastLines(t *testing.T) {
+	const data = "one\ntwo\nthree\n"
+
+	assert.Equal(t, "three\n", string(lastLines([]byte(data), 1)))
+	assert.Equal(t, "two\nthree\n", string(lastLines([]byte(data), 2)))
+	assert.Equal(t, data, string(lastLines([]byte(data), 3)))
+	assert.Equal(t, data, string(lastLines([]byte(data), 10)))
+	assert.Equal(t, data, string(lastLines([]byte(data), 0)))
+	assert.Equal(t, "three", string(lastLines([]byte("one\ntwo\nthree"), 1)))
+	assert.Empty(t, lastLines(nil, 5))
+}
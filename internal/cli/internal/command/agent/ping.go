@@ -41,8 +41,8 @@ func runPing(ctx context.Context) (err error) {
 		return
 	}
 
-	if out := iostreams.FromContext(ctx).Out; config.FromContext(ctx).JSONOutput {
-		err = render.JSON(out, pong)
+	if out := iostreams.FromContext(ctx).Out; config.FromContext(ctx).StructuredOutput() {
+		err = render.Structured(ctx, out, pong)
 	} else {
 		var buf bytes.Buffer
 
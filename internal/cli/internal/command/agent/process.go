@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azazeal/pause"
+
+	"github.com/superfly/flyctl/internal/cli/internal/state"
+)
+
+func pidPath(ctx context.Context) string {
+	return filepath.Join(state.ConfigDirectory(ctx), "fly-agent.pid")
+}
+
+// writePIDFile records the PID of the running agent so that it may be killed
+// should it stop responding.
+func writePIDFile(ctx context.Context) (remove func(), err error) {
+	path := pidPath(ctx)
+	pid := strconv.Itoa(os.Getpid())
+
+	if err = os.WriteFile(path, []byte(pid+"\n"), 0600); err != nil {
+		return
+	}
+
+	remove = func() {
+		// only remove the file in case another agent hasn't replaced it
+		if data, err := os.ReadFile(path); err == nil && strings.TrimSpace(string(data)) == pid {
+			_ = os.Remove(path)
+		}
+	}
+
+	return
+}
+
+// killTimeout bounds the time the agent has to respond to a kill command
+// before it's considered wedged.
+const killTimeout = 5 * time.Second
+
+var errNotRunning = errors.New("the agent is not running")
+
+// stop stops the running agent. It asks the agent to shut down and, should
+// the agent not respond, kills its process.
+func stop(ctx context.Context) (killed bool, err error) {
+	if client, err := dial(ctx); err == nil {
+		kctx, cancel := context.WithTimeout(ctx, killTimeout)
+		defer cancel()
+
+		if err = client.Kill(kctx); err == nil {
+			return false, nil
+		}
+	}
+
+	var data []byte
+	switch data, err = os.ReadFile(pidPath(ctx)); {
+	case errors.Is(err, os.ErrNotExist):
+		return false, errNotRunning
+	case err != nil:
+		return false, fmt.Errorf("failed reading agent pid file: %w", err)
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return false, fmt.Errorf("invalid agent pid file: %w", err)
+	}
+
+	p, err := os.FindProcess(pid)
+	if err == nil {
+		err = p.Kill()
+	}
+
+	switch {
+	case errors.Is(err, os.ErrProcessDone):
+		_ = os.Remove(pidPath(ctx))
+
+		return false, errNotRunning
+	case err != nil:
+		return false, fmt.Errorf("failed killing agent process %d: %w", pid, err)
+	}
+
+	_ = os.Remove(pidPath(ctx))
+
+	// give the killed agent a moment to release its lock
+	pause.For(ctx, time.Second)
+
+	return true, nil
+}
@@ -48,8 +48,8 @@ func runResolve(ctx context.Context) (err error) {
 		return
 	}
 
-	if out := iostreams.FromContext(ctx).Out; config.FromContext(ctx).JSONOutput {
-		err = render.JSON(out, struct {
+	if out := iostreams.FromContext(ctx).Out; config.FromContext(ctx).StructuredOutput() {
+		err = render.Structured(ctx, out, struct {
 			Addr string `json:"addr"`
 		}{
 			Addr: addr,
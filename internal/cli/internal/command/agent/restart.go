@@ -2,16 +2,22 @@ package agent
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/spf13/cobra"
 
+	"github.com/superfly/flyctl/pkg/iostreams"
+
 	"github.com/superfly/flyctl/internal/cli/internal/command"
 )
 
 func newRestart() (cmd *cobra.Command) {
 	const (
 		short = "Restart the Fly agent"
-		long  = short + "\n"
+		long  = short + `. An agent which doesn't respond has its process killed
+before a new one starts.
+`
 	)
 
 	cmd = command.New("restart", short, long, runRestart,
@@ -24,8 +30,13 @@ func newRestart() (cmd *cobra.Command) {
 }
 
 func runRestart(ctx context.Context) error {
-	if client, err := dial(ctx); err == nil {
-		_ = client.Kill(ctx)
+	switch killed, err := stop(ctx); {
+	case errors.Is(err, errNotRunning):
+		break
+	case err != nil:
+		return fmt.Errorf("failed stopping agent: %w", err)
+	case killed:
+		fmt.Fprintln(iostreams.FromContext(ctx).ErrOut, "The agent did not respond; killed its process.")
 	}
 
 	_, err := establish(ctx)
@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// rotatingFile is an io.WriteCloser which appends to the file at path and
+// rotates it once it grows over maxSize, keeping the given number of backups
+// (path.1 being the most recent one).
+type rotatingFile struct {
+	path    string
+	maxSize int64
+	backups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+func openRotatingFile(path string, maxSize int64, backups int) (*rotatingFile, error) {
+	rf := &rotatingFile{
+		path:    path,
+		maxSize: maxSize,
+		backups: backups,
+	}
+
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+
+	return rf, nil
+}
+
+func (rf *rotatingFile) open() (err error) {
+	if rf.f, err = os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600); err != nil {
+		return
+	}
+
+	var info os.FileInfo
+	if info, err = rf.f.Stat(); err != nil {
+		_ = rf.f.Close()
+
+		return
+	}
+	rf.size = info.Size()
+
+	return
+}
+
+func (rf *rotatingFile) Write(p []byte) (n int, err error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.size > 0 && rf.size+int64(len(p)) > rf.maxSize {
+		if err = rf.rotate(); err != nil {
+			return
+		}
+	}
+
+	n, err = rf.f.Write(p)
+	rf.size += int64(n)
+
+	return
+}
+
+func (rf *rotatingFile) rotate() error {
+	if err := rf.f.Close(); err != nil {
+		return err
+	}
+
+	backup := func(i int) string {
+		return fmt.Sprintf("%s.%d", rf.path, i)
+	}
+
+	_ = os.Remove(backup(rf.backups))
+	for i := rf.backups - 1; i > 0; i-- {
+		_ = os.Rename(backup(i), backup(i+1))
+	}
+
+	if rf.backups > 0 {
+		if err := os.Rename(rf.path, backup(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(rf.path); err != nil {
+		return err
+	}
+
+	return rf.open()
+}
+
+func (rf *rotatingFile) Sync() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	return rf.f.Sync()
+}
+
+func (rf *rotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	return rf.f.Close()
+}
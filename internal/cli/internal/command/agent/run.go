@@ -30,12 +30,24 @@ func newRun() (cmd *cobra.Command) {
 	cmd.Args = cobra.MaximumNArgs(1)
 	cmd.Aliases = []string{"daemon-start"}
 
+	flag.Add(cmd,
+		flag.Bool{
+			Name:        "service",
+			Description: "Run as an OS service, logging to a file which gets rotated",
+			Hidden:      true,
+		},
+	)
+
 	return
 }
 
 func run(ctx context.Context) error {
-	logPath := flag.FirstArg(ctx)
-	logger, closeLogger, err := setupLogger(logPath)
+	logPath, service := flag.FirstArg(ctx), flag.GetBool(ctx, "service")
+	if service {
+		logPath = serviceLogPath(ctx)
+	}
+
+	logger, closeLogger, err := setupLogger(logPath, service)
 	if err != nil {
 		err = fmt.Errorf("failed setting up logger: %w", err)
 
@@ -57,20 +69,44 @@ func run(ctx context.Context) error {
 	}
 	defer unlock()
 
+	removePIDFile, err := writePIDFile(ctx)
+	if err != nil {
+		logger.Printf("failed writing pid file: %v", err)
+	} else {
+		defer removePIDFile()
+	}
+
 	opt := server.Options{
 		Socket:     socketPath(ctx),
 		Logger:     logger,
 		Client:     apiClient.API(),
 		Background: logPath != "",
 		ConfigFile: state.ConfigFile(ctx),
+		LogFile:    logPath,
 	}
 
 	return server.Run(ctx, opt)
 }
 
-func setupLogger(path string) (logger *log.Logger, close func(), err error) {
+const (
+	serviceLogMaxSize = 10 << 20
+	serviceLogBackups = 3
+)
+
+func setupLogger(path string, rotate bool) (logger *log.Logger, close func(), err error) {
 	var out io.Writer
-	if path != "" {
+	if rotate {
+		f, err := openRotatingFile(path, serviceLogMaxSize, serviceLogBackups)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		out = f
+		close = func() {
+			_ = f.Sync()
+			_ = f.Close()
+		}
+	} else if path != "" {
 		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
 		if err != nil {
 			return nil, nil, err
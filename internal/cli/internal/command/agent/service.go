@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/state"
+)
+
+// serviceName names the agent service across the service managers.
+const serviceName = "fly-agent"
+
+func newInstallService() (cmd *cobra.Command) {
+	const (
+		short = "Run the Fly agent as an OS service"
+		long  = short + `, so that it starts along with the user
+session and keeps its tunnels up, instead of being started on demand.
+
+The agent is registered as a systemd user unit on Linux, as a launchd agent on
+macOS and as a task which runs at logon on Windows, the latter so that the
+agent runs as the user whose configuration it uses. Each of them restarts the
+agent in case it fails, the Windows task after a minute. The service logs to a
+file in the configuration directory, which gets rotated once it grows over 10MB.
+`
+	)
+
+	cmd = command.New("install-service", short, long, runInstallService,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	return
+}
+
+func newUninstallService() (cmd *cobra.Command) {
+	const (
+		short = "Stop running the Fly agent as an OS service"
+		long  = short + `. The agent will again be started on demand.
+`
+	)
+
+	cmd = command.New("uninstall-service", short, long, runUninstallService)
+
+	cmd.Args = cobra.NoArgs
+
+	return
+}
+
+// service wraps the parameters of the agent's service definition.
+type service struct {
+	Executable string
+	Args       []string
+	LogPath    string
+}
+
+func newService(ctx context.Context) (*service, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed determining path to flyctl: %w", err)
+	}
+
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return nil, fmt.Errorf("failed determining path to flyctl: %w", err)
+	}
+
+	return &service{
+		Executable: exe,
+		Args:       []string{"agent", "run", "--service"},
+		LogPath:    serviceLogPath(ctx),
+	}, nil
+}
+
+func serviceLogPath(ctx context.Context) string {
+	return filepath.Join(state.ConfigDirectory(ctx), "agent-service.log")
+}
+
+var errServiceUnsupported = errors.New("running the agent as a service is not supported on this platform")
+
+func runInstallService(ctx context.Context) error {
+	svc, err := newService(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the service replaces any agent started on demand
+	if _, err := stop(ctx); err != nil && !errors.Is(err, errNotRunning) {
+		return fmt.Errorf("failed stopping running agent: %w", err)
+	}
+
+	path, err := installService(ctx, svc)
+	if err != nil {
+		return fmt.Errorf("failed installing agent service: %w", err)
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	fmt.Fprintf(out, "Installed the agent service at %s\n", path)
+	fmt.Fprintf(out, "The agent logs to %s\n", svc.LogPath)
+
+	return nil
+}
+
+func runUninstallService(ctx context.Context) error {
+	path, err := uninstallService(ctx)
+	if err != nil {
+		return fmt.Errorf("failed uninstalling agent service: %w", err)
+	}
+
+	fmt.Fprintf(iostreams.FromContext(ctx).Out, "Uninstalled the agent service from %s\n", path)
+
+	return nil
+}
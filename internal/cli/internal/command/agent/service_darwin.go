@@ -0,0 +1,111 @@
+//go:build darwin
+// +build darwin
+
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const launchdLabel = "io.fly.agent"
+
+var plistTemplate = template.Must(template.New("plist").Funcs(template.FuncMap{
+	"escape": func(s string) string {
+		var buf bytes.Buffer
+		_ = xml.EscapeText(&buf, []byte(s))
+
+		return buf.String()
+	},
+}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>` + launchdLabel + `</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>{{ escape .Executable }}</string>
+{{- range .Args }}
+		<string>{{ escape . }}</string>
+{{- end }}
+	</array>
+	<key>EnvironmentVariables</key>
+	<dict>
+		<key>FLY_NO_UPDATE_CHECK</key>
+		<string>1</string>
+	</dict>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+</dict>
+</plist>
+`))
+
+func plistPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist"), nil
+}
+
+func installService(_ context.Context, svc *service) (path string, err error) {
+	if path, err = plistPath(); err != nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = plistTemplate.Execute(&buf, svc); err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+
+	// reload definitions which already exist
+	_ = launchctl("unload", path)
+
+	if err = os.WriteFile(path, buf.Bytes(), 0644); err == nil {
+		err = launchctl("load", "-w", path)
+	}
+
+	return
+}
+
+func uninstallService(_ context.Context) (path string, err error) {
+	if path, err = plistPath(); err != nil {
+		return
+	}
+
+	if _, err = os.Stat(path); err != nil {
+		return
+	}
+
+	if err = launchctl("unload", "-w", path); err == nil {
+		err = os.Remove(path)
+	}
+
+	return
+}
+
+func launchctl(args ...string) error {
+	if out, err := exec.Command("launchctl", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("launchctl %s: %w: %s", strings.Join(args, " "), err, bytes.TrimSpace(out))
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+//go:build linux
+// +build linux
+
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var unitTemplate = template.Must(template.New("unit").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+}).Parse(`[Unit]
+Description=Fly agent
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+ExecStart={{ quote .Executable }}{{ range .Args }} {{ quote . }}{{ end }}
+Environment=FLY_NO_UPDATE_CHECK=1
+Restart=on-failure
+RestartSec=5
+
+[Install]
+WantedBy=default.target
+`))
+
+func unitPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "systemd", "user", serviceName+".service"), nil
+}
+
+func installService(_ context.Context, svc *service) (path string, err error) {
+	if path, err = unitPath(); err != nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = unitTemplate.Execute(&buf, svc); err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+
+	if err = os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return
+	}
+
+	if err = systemctl("daemon-reload"); err == nil {
+		err = systemctl("enable", "--now", serviceName+".service")
+	}
+
+	return
+}
+
+func uninstallService(_ context.Context) (path string, err error) {
+	if path, err = unitPath(); err != nil {
+		return
+	}
+
+	if _, err = os.Stat(path); err != nil {
+		return
+	}
+
+	if err = systemctl("disable", "--now", serviceName+".service"); err != nil {
+		return
+	}
+
+	if err = os.Remove(path); err == nil {
+		err = systemctl("daemon-reload")
+	}
+
+	return
+}
+
+func systemctl(args ...string) error {
+	args = append([]string{"--user"}, args...)
+
+	if out, err := exec.Command("systemctl", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("systemctl %s: %w: %s", strings.Join(args, " "), err, bytes.TrimSpace(out))
+	}
+
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package agent
+
+import "context"
+
+func installService(context.Context, *service) (string, error) {
+	return "", errServiceUnsupported
+}
+
+func uninstallService(context.Context) (string, error) {
+	return "", errServiceUnsupported
+}
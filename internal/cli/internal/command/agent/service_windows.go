@@ -0,0 +1,131 @@
+//go:build windows
+// +build windows
+
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strings"
+	"text/template"
+	"unicode/utf16"
+
+	"golang.org/x/sys/windows"
+)
+
+const taskName = "FlyAgent"
+
+// taskTemplate defines a task which starts the agent at logon of the user,
+// runs it without a time limit and restarts it in case it fails. Task
+// Scheduler only allows restart intervals of a minute or longer.
+var taskTemplate = template.Must(template.New("task").Funcs(template.FuncMap{
+	"escape": func(s string) string {
+		var buf bytes.Buffer
+		_ = xml.EscapeText(&buf, []byte(s))
+
+		return buf.String()
+	},
+}).Parse(`<?xml version="1.0" encoding="UTF-16"?>
+<Task version="1.2" xmlns="http://schemas.microsoft.com/windows/2004/02/mit/task">
+	<Triggers>
+		<LogonTrigger>
+			<Enabled>true</Enabled>
+			<UserId>{{ escape .User }}</UserId>
+		</LogonTrigger>
+	</Triggers>
+	<Principals>
+		<Principal id="Author">
+			<UserId>{{ escape .User }}</UserId>
+			<LogonType>InteractiveToken</LogonType>
+			<RunLevel>LeastPrivilege</RunLevel>
+		</Principal>
+	</Principals>
+	<Settings>
+		<MultipleInstancesPolicy>IgnoreNew</MultipleInstancesPolicy>
+		<DisallowStartIfOnBatteries>false</DisallowStartIfOnBatteries>
+		<StopIfGoingOnBatteries>false</StopIfGoingOnBatteries>
+		<ExecutionTimeLimit>PT0S</ExecutionTimeLimit>
+		<RestartOnFailure>
+			<Interval>PT1M</Interval>
+			<Count>999</Count>
+		</RestartOnFailure>
+	</Settings>
+	<Actions Context="Author">
+		<Exec>
+			<Command>{{ escape .Command }}</Command>
+			<Arguments>{{ escape .Arguments }}</Arguments>
+		</Exec>
+	</Actions>
+</Task>
+`))
+
+func installService(_ context.Context, svc *service) (path string, err error) {
+	path = `Task Scheduler\` + taskName
+
+	u, err := user.Current()
+	if err != nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = taskTemplate.Execute(&buf, map[string]string{
+		"User":      u.Username,
+		"Command":   svc.Executable,
+		"Arguments": windows.ComposeCommandLine(svc.Args),
+	}); err != nil {
+		return
+	}
+
+	f, err := os.CreateTemp("", "flyctl-task-*.xml")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+
+	// schtasks reads task definitions declared as UTF-16 as such
+	_, err = f.Write(encodeUTF16(buf.String()))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return
+	}
+
+	if err = schtasks("/Create", "/F", "/TN", taskName, "/XML", f.Name()); err == nil {
+		err = schtasks("/Run", "/TN", taskName)
+	}
+
+	return
+}
+
+// encodeUTF16 encodes s as little endian UTF-16, byte order mark first.
+func encodeUTF16(s string) []byte {
+	units := utf16.Encode(append([]rune{'\ufeff'}, []rune(s)...))
+
+	b := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(b[2*i:], u)
+	}
+
+	return b
+}
+
+func uninstallService(_ context.Context) (path string, err error) {
+	_ = schtasks("/End", "/TN", taskName)
+
+	return `Task Scheduler\` + taskName, schtasks("/Delete", "/F", "/TN", taskName)
+}
+
+func schtasks(args ...string) error {
+	if out, err := exec.Command("schtasks", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("schtasks %s: %w: %s", strings.Join(args, " "), err, bytes.TrimSpace(out))
+	}
+
+	return nil
+}
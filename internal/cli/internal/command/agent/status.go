@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/pkg/agent"
+	"github.com/superfly/flyctl/pkg/iostreams"
+	"github.com/superfly/flyctl/pkg/wg"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/format"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+)
+
+func newStatus() (cmd *cobra.Command) {
+	const (
+		short = "Show the status of the Fly agent"
+		long  = short + `: its version, the tunnels it maintains along with the
+transport each one carries WireGuard over, the WireGuard peers it tunnels with
+and the errors it recently replied with.
+
+The agent tunnels over UDP. Set ` + wg.TransportEnvKey + ` to udp or websocket before
+the agent starts to force either transport. The websocket relay's URL is read
+from ` + wg.WebSocketURLEnvKey + ` or, failing that, from the websocketurl of the
+organization's peer in the wire_guard_state section of the config file. Only
+with a relay configured does the agent probe new UDP tunnels, falling back to
+the websocket when they don't respond, as is the case when UDP is blocked.
+Tunnels whose relay drops are closed and rebuilt on next use.
+`
+	)
+
+	cmd = command.New("status", short, long, runStatus)
+
+	cmd.Args = cobra.NoArgs
+
+	return
+}
+
+func runStatus(ctx context.Context) (err error) {
+	var client *agent.Client
+	if client, err = dial(ctx); err != nil {
+		return
+	}
+
+	var status agent.StatusResponse
+	if status, err = client.Status(ctx); err != nil {
+		err = fmt.Errorf("failed querying agent status: %w", err)
+
+		return
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, out, status)
+	}
+
+	var buf bytes.Buffer
+
+	logFile := status.LogFile
+	if logFile == "" {
+		logFile = "-"
+	}
+
+	fmt.Fprintf(&buf, "%-10s: %d\n", "PID", status.PID)
+	fmt.Fprintf(&buf, "%-10s: %s\n", "Version", status.Version)
+	fmt.Fprintf(&buf, "%-10s: %t\n", "Background", status.Background)
+	fmt.Fprintf(&buf, "%-10s: %s\n", "Started", format.RelativeTime(status.StartedAt))
+	fmt.Fprintf(&buf, "%-10s: %s\n", "Log file", logFile)
+	fmt.Fprintln(&buf)
+
+	if _, err = buf.WriteTo(out); err != nil {
+		return
+	}
+
+	tunnels := make([][]string, 0, len(status.Tunnels))
+	for _, tunnel := range status.Tunnels {
+		handshake := "-"
+		if tunnel.LastHandshake != nil {
+			handshake = format.RelativeTime(*tunnel.LastHandshake)
+		}
+
+		tunnels = append(tunnels, []string{
+			tunnel.Org,
+			tunnel.Peer,
+			tunnel.Endpoint,
+			tunnel.Transport,
+			handshake,
+		})
+	}
+
+	if err = render.Table(out, "Tunnels", tunnels, "Organization", "Peer", "Endpoint", "Transport", "Last Handshake"); err != nil {
+		return
+	}
+
+	peers := make([][]string, 0, len(status.Peers))
+	for _, peer := range status.Peers {
+		peers = append(peers, []string{
+			peer.Org,
+			peer.Name,
+			peer.Region,
+			peer.IP,
+		})
+	}
+
+	if err = render.Table(out, "Peers", peers, "Organization", "Name", "Region", "IP"); err != nil {
+		return
+	}
+
+	errs := make([][]string, 0, len(status.Errors))
+	for i := len(status.Errors) - 1; i >= 0; i-- {
+		errs = append(errs, []string{
+			format.RelativeTime(status.Errors[i].At),
+			status.Errors[i].Message,
+		})
+	}
+
+	return render.Table(out, "Recent Errors", errs, "When", "Error")
+}
@@ -6,7 +6,7 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"github.com/superfly/flyctl/pkg/agent"
+	"github.com/superfly/flyctl/pkg/iostreams"
 
 	"github.com/superfly/flyctl/internal/cli/internal/command"
 )
@@ -14,7 +14,8 @@ import (
 func newStop() (cmd *cobra.Command) {
 	const (
 		short = "Stop the Fly agent"
-		long  = short + "\n"
+		long  = short + `, killing its process in case it doesn't respond.
+`
 	)
 
 	cmd = command.New("stop", short, long, runStop,
@@ -26,15 +27,15 @@ func newStop() (cmd *cobra.Command) {
 	return
 }
 
-func runStop(ctx context.Context) (err error) {
-	var client *agent.Client
-	if client, err = dial(ctx); err != nil {
-		return
+func runStop(ctx context.Context) error {
+	killed, err := stop(ctx)
+	if err != nil {
+		return fmt.Errorf("failed stopping agent: %w", err)
 	}
 
-	if err = client.Kill(ctx); err != nil {
-		err = fmt.Errorf("failed stopping agent: %w", err)
+	if killed {
+		fmt.Fprintln(iostreams.FromContext(ctx).ErrOut, "The agent did not respond; killed its process.")
 	}
 
-	return
+	return nil
 }
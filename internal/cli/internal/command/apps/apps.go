@@ -31,6 +31,9 @@ The LIST command will list all currently registered applications.
 		newRestart(),
 		NewOpen(),
 		NewReleases(),
+		newExport(),
+		newImport(),
+		newFork(),
 	)
 
 	return apps
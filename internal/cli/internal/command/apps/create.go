@@ -104,8 +104,8 @@ func RunCreate(ctx context.Context) (err error) {
 		CreateApp(ctx, input)
 
 	if err == nil {
-		if cfg.JSONOutput {
-			return render.JSON(io.Out, app)
+		if cfg.StructuredOutput() {
+			return render.Structured(ctx, io.Out, app)
 		}
 		fmt.Fprintf(io.Out, "New app created: %s\n", app.Name)
 	}
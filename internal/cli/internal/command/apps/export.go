@@ -0,0 +1,64 @@
+package apps
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/bundle"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+func newExport() *cobra.Command {
+	const (
+		long = `The APPS EXPORT command will write the reproducible state of an
+application (its configuration, current image, secret names, volumes,
+certificates and scale) into a bundle which may later be imported via the
+APPS IMPORT command. Secret values are never exported.
+`
+		short = "Export an app into a bundle"
+		usage = "export <PATH>"
+	)
+
+	cmd := command.New(usage, short, long, runExport,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func runExport(ctx context.Context) error {
+	var (
+		appName = app.NameFromContext(ctx)
+		path    = flag.FirstArg(ctx)
+		client  = client.FromContext(ctx).API()
+	)
+
+	b, err := bundle.Collect(ctx, client, appName)
+	if err != nil {
+		return err
+	}
+
+	if err := b.WriteFile(path); err != nil {
+		return fmt.Errorf("failed writing bundle to %s: %w", path, err)
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	fmt.Fprintf(out, "Exported %s to %s\n", appName, path)
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package apps
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/bundle"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/prompt"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+func newFork() *cobra.Command {
+	const (
+		long = `The APPS FORK command will create a functional copy of an application,
+such as an environment to load test against, in one go: the new app gets the
+configuration, scale and volumes of the application and is deployed with the
+image it currently runs.
+
+Secret values can't be read back, so the value of each secret is prompted for;
+secrets left blank, or all of them when not running interactively, have to be
+set via the secrets set command. Certificates aren't copied, since their
+hostnames point to the original application.
+
+With --with-data, volumes are restored from the latest snapshot of the volume
+they copy instead of being created empty. Since volumes are snapshotted
+daily, the restored data may be up to a day old.
+`
+		short = "Create a copy of an app"
+		usage = "fork <NAME>"
+	)
+
+	cmd := command.New(usage, short, long, runFork,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Org(),
+		flag.String{
+			Name:        "region",
+			Shorthand:   "r",
+			Description: "The region to create the copy in, overriding the regions of the app",
+		},
+		flag.Bool{
+			Name:        "with-data",
+			Description: "Restore the volumes of the copy from the latest snapshots of the app's volumes",
+		},
+		flag.Bool{
+			Name:        "skip-secrets",
+			Description: "Do not prompt for the values of the app's secrets",
+		},
+	)
+
+	return cmd
+}
+
+func runFork(ctx context.Context) (err error) {
+	var (
+		io      = iostreams.FromContext(ctx)
+		client  = client.FromContext(ctx).API()
+		appName = app.NameFromContext(ctx)
+		name    = flag.FirstArg(ctx)
+	)
+
+	b, err := bundle.Collect(ctx, client, appName)
+	if err != nil {
+		return
+	}
+
+	org, err := forkOrg(ctx, b.Manifest.Organization)
+	if err != nil {
+		return
+	}
+
+	if flag.GetBool(ctx, "with-data") {
+		var empty []string
+		if empty, err = b.UseLatestSnapshots(ctx, client); err != nil {
+			return
+		}
+
+		for _, vol := range empty {
+			fmt.Fprintf(io.ErrOut, "Volume %s has no snapshots and will be created empty\n", vol)
+		}
+	}
+
+	secrets, unset, err := promptSecrets(ctx, b.SecretKeys)
+	if err != nil {
+		return
+	}
+
+	fork, _, err := bundle.Restore(ctx, b, bundle.RestoreOptions{
+		Name:             name,
+		Organization:     org,
+		Region:           flag.GetString(ctx, "region"),
+		SkipCertificates: true,
+		Secrets:          secrets,
+	})
+	if err != nil {
+		return
+	}
+
+	if len(unset) > 0 {
+		colorize := io.ColorScheme()
+
+		fmt.Fprintln(io.ErrOut, colorize.Yellow("The following secrets have to be set via the secrets set command:"))
+		for _, key := range unset {
+			fmt.Fprintf(io.ErrOut, "  %s\n", key)
+		}
+	}
+
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, io.Out, fork)
+	}
+
+	fmt.Fprintf(io.Out, "Forked %s into %s\n", appName, fork.Name)
+
+	return nil
+}
+
+// forkOrg returns the organization the fork is created in; the one passed
+// in via flag or, by default, the one of the original application.
+func forkOrg(ctx context.Context, slug string) (*api.Organization, error) {
+	if flag.GetOrg(ctx) != "" {
+		return prompt.Org(ctx, nil)
+	}
+
+	org, err := client.FromContext(ctx).API().GetOrganizationBySlug(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving organization %s: %w", slug, err)
+	}
+
+	return &api.Organization{
+		ID:   org.ID,
+		Name: org.Name,
+		Slug: org.Slug,
+		Type: org.Type,
+	}, nil
+}
+
+// promptSecrets prompts for the values of the named secrets. It returns the
+// values which were entered and the names of the secrets which were left
+// blank or not prompted for.
+func promptSecrets(ctx context.Context, keys []string) (secrets map[string]string, unset []string, err error) {
+	if len(keys) == 0 {
+		return
+	}
+
+	if flag.GetBool(ctx, "skip-secrets") {
+		unset = keys
+
+		return
+	}
+
+	secrets = make(map[string]string, len(keys))
+
+	for i, key := range keys {
+		var value string
+
+		switch err = prompt.Password(ctx, &value, fmt.Sprintf("Value of secret %s (leave blank to skip):", key), false); {
+		case prompt.IsNonInteractive(err):
+			err = nil
+			unset = append(unset, keys[i:]...)
+
+			return
+		case err != nil:
+			return
+		case value == "":
+			unset = append(unset, key)
+		default:
+			secrets[key] = value
+		}
+	}
+
+	return
+}
@@ -0,0 +1,136 @@
+package apps
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/buildinfo"
+	"github.com/superfly/flyctl/internal/cli/internal/bundle"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/prompt"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+func newImport() *cobra.Command {
+	const (
+		long = `The APPS IMPORT command will create a new application from a bundle
+the APPS EXPORT command generated. Volumes are restored from the snapshots the
+bundle names, if any, or recreated empty.
+
+Secret values are never exported, so the value of each secret is prompted for
+and set before the image is deployed. In case any are left blank, or when not
+running interactively, the image is not deployed; set the secrets via the
+secrets set command and deploy the image via 'fly deploy --image' afterwards.
+
+Should the import fail part way, the partially created app is destroyed.
+`
+		short = "Import an app from a bundle"
+		usage = "import <PATH>"
+	)
+
+	cmd := command.New(usage, short, long, runImport,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.String{
+			Name:        "name",
+			Description: "The name of the app to create. Defaults to the name of the exported app",
+		},
+		flag.Org(),
+		flag.String{
+			Name:        "region",
+			Shorthand:   "r",
+			Description: "The region to recreate the app in, overriding the exported regions",
+		},
+		flag.Bool{
+			Name:        "skip-certificates",
+			Description: "Do not add the exported certificates to the new app",
+		},
+		flag.Bool{
+			Name:        "skip-secrets",
+			Description: "Do not prompt for the values of the app's secrets",
+		},
+	)
+
+	return cmd
+}
+
+func runImport(ctx context.Context) (err error) {
+	b, err := bundle.ReadFile(flag.FirstArg(ctx))
+	if err != nil {
+		return err
+	}
+
+	org, err := prompt.Org(ctx, nil)
+	if err != nil {
+		return
+	}
+
+	secrets, unset, err := promptSecrets(ctx, b.SecretKeys)
+	if err != nil {
+		return
+	}
+
+	app, _, err := bundle.Restore(ctx, b, bundle.RestoreOptions{
+		Name:             flag.GetString(ctx, "name"),
+		Organization:     org,
+		Region:           flag.GetString(ctx, "region"),
+		SkipCertificates: flag.GetBool(ctx, "skip-certificates"),
+		Secrets:          secrets,
+		SkipDeploy:       len(unset) > 0,
+	})
+	if err != nil {
+		if app != nil {
+			err = destroyPartialImport(ctx, app.Name, err)
+		}
+
+		return
+	}
+
+	io := iostreams.FromContext(ctx)
+
+	if len(unset) > 0 {
+		colorize := io.ColorScheme()
+
+		fmt.Fprintln(io.ErrOut, colorize.Yellow("The following secrets have to be set via the secrets set command:"))
+		for _, key := range unset {
+			fmt.Fprintf(io.ErrOut, "  %s\n", key)
+		}
+
+		if b.Image != "" {
+			fmt.Fprintf(io.ErrOut, "%s was not deployed; once the secrets are set, deploy it via:\n  %s deploy -a %s --image %s\n",
+				b.Image, buildinfo.Name(), app.Name, b.Image)
+		}
+	}
+
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, io.Out, app)
+	}
+
+	return nil
+}
+
+// destroyPartialImport destroys the named app, which an import which failed
+// with cause created, and returns the error to report.
+func destroyPartialImport(ctx context.Context, appName string, cause error) error {
+	tb := render.NewTextBlock(ctx, "Destroying partially imported app ", appName)
+
+	if err := client.FromContext(ctx).API().DeleteApp(ctx, appName); err != nil {
+		return fmt.Errorf("%w; failed destroying partially imported app %s, which has to be destroyed via the apps destroy command: %v",
+			cause, appName, err)
+	}
+
+	tb.Done("Destroyed ", appName)
+
+	return cause
+}
@@ -8,8 +8,10 @@ import (
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/pkg/iostreams"
 
+	"github.com/superfly/flyctl/internal/cli/internal/apicache"
 	"github.com/superfly/flyctl/internal/cli/internal/command"
 	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
 	"github.com/superfly/flyctl/internal/cli/internal/format"
 	"github.com/superfly/flyctl/internal/cli/internal/render"
 	"github.com/superfly/flyctl/internal/client"
@@ -25,9 +27,16 @@ be shown with its name, owner and when it was last deployed.
 		short = "List applications"
 	)
 
-	return command.New("list", short, long, runList,
+	cmd := command.New("list", short, long, runList,
 		command.RequireSession,
+		command.LoadAPICache,
 	)
+
+	flag.Add(cmd,
+		flag.NoCache(),
+	)
+
+	return cmd
 }
 
 func runList(ctx context.Context) (err error) {
@@ -35,13 +44,17 @@ func runList(ctx context.Context) (err error) {
 	client := client.FromContext(ctx)
 
 	var apps []api.App
-	if apps, err = client.API().GetApps(ctx, nil); err != nil {
+	if err = apicache.FromContext(ctx).Fetch(ctx, apicache.AppsKey, &apps, func(ctx context.Context) (err error) {
+		apps, err = client.API().GetApps(ctx, nil)
+
+		return
+	}); err != nil {
 		return
 	}
 
 	out := iostreams.FromContext(ctx).Out
-	if cfg.JSONOutput {
-		_ = render.JSON(out, apps)
+	if cfg.StructuredOutput() {
+		_ = render.Structured(ctx, out, apps)
 
 		return
 	}
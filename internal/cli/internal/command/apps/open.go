@@ -4,12 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
+	"strconv"
 
 	"github.com/skratchdot/open-golang/open"
 	"github.com/spf13/cobra"
 
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/agent"
 	"github.com/superfly/flyctl/pkg/iostreams"
+	"github.com/superfly/flyctl/pkg/proxy"
 
 	"github.com/superfly/flyctl/internal/cli/internal/app"
 	"github.com/superfly/flyctl/internal/cli/internal/command"
@@ -22,6 +27,10 @@ func NewOpen() (cmd *cobra.Command) {
 	const (
 		long = `Open browser to current deployed application. If an optional relative URI is specified, it is appended 
 to the root URL of the deployed application.
+
+With --private, the app is opened via a temporary local proxy over WireGuard
+instead, which works for apps without public IPs. The proxy forwards to the
+internal port of the app's first service, or --port, until interrupted.
 `
 		short = "Open browser to current deployed application"
 
@@ -38,16 +47,33 @@ to the root URL of the deployed application.
 	flag.Add(cmd,
 		flag.App(),
 		flag.AppConfig(),
+		flag.String{
+			Name:        "path",
+			Description: "Relative URI to open; an alternative to the RELATIVE_URI argument",
+		},
+		flag.String{
+			Name:        "scheme",
+			Description: "Scheme to open the app with, http or https; --private always uses http",
+			Default:     "http",
+		},
+		flag.Bool{
+			Name:        "private",
+			Description: "Open the app via a local proxy over WireGuard",
+		},
+		flag.Int{
+			Name:        "port",
+			Description: "Internal port --private forwards to; defaults to the internal port of the app config",
+		},
 	)
 
 	return
-
 }
 
 func runOpen(ctx context.Context) error {
 	appName := app.NameFromContext(ctx)
+	apiClient := client.FromContext(ctx).API()
 
-	app, err := client.FromContext(ctx).API().GetApp(ctx, appName)
+	app, err := apiClient.GetApp(ctx, appName)
 	if err != nil {
 		return fmt.Errorf("failed retrieving app %s: %w", appName, err)
 	}
@@ -56,12 +82,42 @@ func runOpen(ctx context.Context) error {
 		return errors.New("app has not been deployed yet. Please try deploying your app first")
 	}
 
-	appURL, err := url.Parse("http://" + app.Hostname)
+	relURI, err := relativeURI(ctx)
+	if err != nil {
+		return err
+	}
+
+	if flag.GetBool(ctx, "private") {
+		return openPrivate(ctx, apiClient, app, relURI)
+	}
+
+	scheme := flag.GetString(ctx, "scheme")
+	if scheme != "http" && scheme != "https" {
+		return fmt.Errorf("invalid scheme %q; use http or https", scheme)
+	}
+
+	return openURL(ctx, scheme+"://"+app.Hostname, relURI)
+}
+
+func relativeURI(ctx context.Context) (string, error) {
+	arg, path := flag.FirstArg(ctx), flag.GetString(ctx, "path")
+	if arg != "" && path != "" {
+		return "", errors.New("specify either a RELATIVE_URI or --path, not both")
+	}
+
+	if path != "" {
+		return path, nil
+	}
+
+	return arg, nil
+}
+
+func openURL(ctx context.Context, rootURL, relURI string) error {
+	appURL, err := url.Parse(rootURL)
 	if err != nil {
-		return fmt.Errorf("failed parsing app URL (hostname: %s): %w", app.Hostname, err)
+		return fmt.Errorf("failed parsing app URL (%s): %w", rootURL, err)
 	}
 
-	relURI := flag.FirstArg(ctx)
 	if appURL, err = appURL.Parse(relURI); err != nil {
 		return fmt.Errorf("failed parsing relative URI %s: %w", relURI, err)
 	}
@@ -75,3 +131,77 @@ func runOpen(ctx context.Context) error {
 
 	return nil
 }
+
+// openPrivate opens the app via a local proxy to its internal port which runs
+// until ctx is done.
+func openPrivate(ctx context.Context, apiClient *api.Client, app *api.App, relURI string) error {
+	port, err := privatePort(ctx, apiClient, app.Name)
+	if err != nil {
+		return err
+	}
+
+	agentclient, err := agent.Establish(ctx, apiClient)
+	if err != nil {
+		return fmt.Errorf("failed establishing agent connection: %w", err)
+	}
+
+	slug := app.Organization.Slug
+
+	dialer, err := agentclient.ConnectToTunnel(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	host := fmt.Sprintf("%s.internal", app.Name)
+	if err := agentclient.WaitForDNS(ctx, dialer, slug, host); err != nil {
+		return fmt.Errorf("%s: %w", host, err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed listening for the local proxy: %w", err)
+	}
+
+	server := &proxy.Server{
+		Addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		Listener: listener,
+		Dial:     dialer.DialContext,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ProxyServer(ctx)
+	}()
+
+	iostream := iostreams.FromContext(ctx)
+	fmt.Fprintf(iostream.ErrOut, "Proxying %s to %s; press Ctrl+C to stop\n", listener.Addr(), server.Addr)
+
+	if err := openURL(ctx, "http://"+listener.Addr().String(), relURI); err != nil {
+		return err
+	}
+
+	return <-errs
+}
+
+func privatePort(ctx context.Context, apiClient *api.Client, appName string) (int, error) {
+	if port := flag.GetInt(ctx, "port"); port > 0 {
+		return port, nil
+	}
+
+	cfg := app.ConfigFromContext(ctx)
+	if cfg == nil {
+		apiConfig, err := apiClient.GetConfig(ctx, appName)
+		if err != nil {
+			return 0, fmt.Errorf("failed fetching app config: %w", err)
+		}
+
+		cfg = &app.Config{Definition: apiConfig.Definition}
+	}
+
+	port, err := cfg.InternalPort()
+	if err != nil {
+		return 0, fmt.Errorf("%w; set --port", err)
+	}
+
+	return port, nil
+}
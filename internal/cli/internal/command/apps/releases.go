@@ -9,6 +9,7 @@ import (
 
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/cmd/presenters"
+	"github.com/superfly/flyctl/internal/cli/internal/apicache"
 	"github.com/superfly/flyctl/internal/cli/internal/app"
 	"github.com/superfly/flyctl/internal/cli/internal/command"
 	"github.com/superfly/flyctl/internal/cli/internal/config"
@@ -30,6 +31,7 @@ including type, when, success/fail and which user triggered the release.
 	cmd = command.New("releases", short, long, runReleases,
 		command.RequireSession,
 		command.RequireAppName,
+		command.LoadAPICache,
 	)
 
 	cmd.Args = cobra.NoArgs
@@ -37,6 +39,12 @@ including type, when, success/fail and which user triggered the release.
 	flag.Add(cmd,
 		flag.App(),
 		flag.AppConfig(),
+		flag.NoCache(),
+	)
+
+	cmd.AddCommand(
+		newReleasesDiff(),
+		newReleasesPrune(),
 	)
 
 	return
@@ -46,14 +54,19 @@ including type, when, success/fail and which user triggered the release.
 func runReleases(ctx context.Context) error {
 	appName := app.NameFromContext(ctx)
 
-	releases, err := client.FromContext(ctx).API().GetAppReleases(ctx, appName, 25)
+	var releases []api.Release
+	err := apicache.FromContext(ctx).Fetch(ctx, "releases/"+appName, &releases, func(ctx context.Context) (err error) {
+		releases, err = client.FromContext(ctx).API().GetAppReleases(ctx, appName, 25)
+
+		return
+	})
 	if err != nil {
 		return fmt.Errorf("failed retrieving app releases %s: %w", appName, err)
 	}
 
 	out := iostreams.FromContext(ctx).Out
-	if config.FromContext(ctx).JSONOutput {
-		return render.JSON(out, releases)
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, out, releases)
 	}
 
 	var rows [][]string
@@ -0,0 +1,263 @@
+package apps
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/cmd/presenters"
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/cmdutil"
+	"github.com/superfly/flyctl/pkg/iostreams"
+)
+
+func newReleasesDiff() (cmd *cobra.Command) {
+	const (
+		long = `Compare two releases of the application, showing who deployed each of them,
+the change in the image they run, the names of the environment variables which
+were added, removed or changed between them and the diff of the rest of the
+config they were deployed with.
+
+Versions may be given with or without their v prefix, e.g. v3 or 3.
+`
+		short = "Compare two app releases"
+	)
+
+	cmd = command.New("diff <v1> <v2>", short, long, runReleasesDiff,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.ExactArgs(2)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return
+}
+
+// ReleaseDiff wraps the differences between two releases.
+type ReleaseDiff struct {
+	From *api.Release `json:"from"`
+	To   *api.Release `json:"to"`
+
+	FromImage *api.Image `json:"from_image,omitempty"`
+	ToImage   *api.Image `json:"to_image,omitempty"`
+
+	EnvAdded   []string `json:"env_added"`
+	EnvRemoved []string `json:"env_removed"`
+	EnvChanged []string `json:"env_changed"`
+
+	ConfigDiff string `json:"config_diff"`
+}
+
+func runReleasesDiff(ctx context.Context) error {
+	var (
+		appName   = app.NameFromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+		args      = flag.Args(ctx)
+	)
+
+	var releases [2]*api.Release
+	for i, arg := range args {
+		version, err := parseReleaseVersion(arg)
+		if err != nil {
+			return err
+		}
+
+		if releases[i], err = apiClient.GetAppReleaseByVersion(ctx, appName, version); err != nil {
+			return fmt.Errorf("failed retrieving release v%d of %s: %w", version, appName, err)
+		} else if releases[i] == nil {
+			return fmt.Errorf("app %s has no release v%d", appName, version)
+		}
+	}
+
+	from, to := releases[0], releases[1]
+
+	diff, err := diffReleases(from, to)
+	if err != nil {
+		return err
+	}
+
+	diff.FromImage = resolveReleaseImage(ctx, apiClient, appName, from)
+	diff.ToImage = resolveReleaseImage(ctx, apiClient, appName, to)
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, out, diff)
+	}
+
+	return renderReleaseDiff(out, iostreams.FromContext(ctx).ColorScheme(), diff)
+}
+
+func parseReleaseVersion(arg string) (int, error) {
+	version, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(arg), "v"))
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("%q is not a release version, e.g. v3", arg)
+	}
+
+	return version, nil
+}
+
+// resolveReleaseImage returns the image the release deployed, resolving its
+// digest in case its reference does not hold one. It returns nil in case the
+// release deployed no image.
+func resolveReleaseImage(ctx context.Context, apiClient *api.Client, appName string, r *api.Release) *api.Image {
+	if r.ImageRef == "" {
+		return nil
+	}
+
+	if i := strings.Index(r.ImageRef, "@sha256:"); i >= 0 {
+		return &api.Image{
+			Ref:    r.ImageRef,
+			Digest: r.ImageRef[i+1:],
+		}
+	}
+
+	if img, err := apiClient.ResolveImageForApp(ctx, appName, r.ImageRef); err == nil && img != nil {
+		return &api.Image{
+			Ref:    r.ImageRef,
+			Digest: img.Digest,
+		}
+	}
+
+	return &api.Image{Ref: r.ImageRef}
+}
+
+func diffReleases(from, to *api.Release) (*ReleaseDiff, error) {
+	fromDef, toDef := releaseDefinition(from), releaseDefinition(to)
+
+	fromEnv, _ := fromDef["env"].(map[string]interface{})
+	toEnv, _ := toDef["env"].(map[string]interface{})
+
+	diff := &ReleaseDiff{
+		From: from,
+		To:   to,
+	}
+
+	for name, v := range toEnv {
+		switch prev, ok := fromEnv[name]; {
+		case !ok:
+			diff.EnvAdded = append(diff.EnvAdded, name)
+		case !reflect.DeepEqual(prev, v):
+			diff.EnvChanged = append(diff.EnvChanged, name)
+		}
+	}
+
+	for name := range fromEnv {
+		if _, ok := toEnv[name]; !ok {
+			diff.EnvRemoved = append(diff.EnvRemoved, name)
+		}
+	}
+
+	sort.Strings(diff.EnvAdded)
+	sort.Strings(diff.EnvRemoved)
+	sort.Strings(diff.EnvChanged)
+
+	// env values are left out of the config diff, as they're listed by name
+	delete(fromDef, "env")
+	delete(toDef, "env")
+
+	var err error
+	if diff.ConfigDiff, err = cmdutil.DiffDefinitions(
+		fmt.Sprintf("v%d", from.Version), fromDef,
+		fmt.Sprintf("v%d", to.Version), toDef,
+	); err != nil {
+		return nil, fmt.Errorf("failed comparing the configs of v%d and v%d: %w", from.Version, to.Version, err)
+	}
+
+	return diff, nil
+}
+
+// releaseDefinition returns a shallow copy of the definition the release was
+// deployed with.
+func releaseDefinition(r *api.Release) map[string]interface{} {
+	def := map[string]interface{}{}
+
+	if r.Config != nil {
+		for k, v := range r.Config.Definition {
+			def[k] = v
+		}
+	}
+
+	return def
+}
+
+func renderReleaseDiff(w io.Writer, cs *iostreams.ColorScheme, diff *ReleaseDiff) error {
+	var rows [][]string
+	for _, r := range []*api.Release{diff.From, diff.To} {
+		rows = append(rows, []string{
+			fmt.Sprintf("v%d", r.Version),
+			formatReleaseReason(r.Reason),
+			r.Status,
+			formatReleaseDescription(*r),
+			r.User.Email,
+			presenters.FormatRelativeTime(r.CreatedAt),
+		})
+	}
+
+	if err := render.Table(w, "Releases", rows, "Version", "Type", "Status", "Description", "User", "Date"); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, cs.Bold("Image"))
+	switch from, to := diff.FromImage, diff.ToImage; {
+	case from == nil && to == nil:
+		fmt.Fprintln(w, "  No image recorded for either release")
+	case from != nil && to != nil && from.Ref == to.Ref && from.Digest == to.Digest:
+		fmt.Fprintf(w, "  Unchanged: %s\n", formatImage(from))
+	default:
+		fmt.Fprintln(w, cs.Red("- "+formatImage(from)))
+		fmt.Fprintln(w, cs.Green("+ "+formatImage(to)))
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, cs.Bold("Environment Variables"))
+	if len(diff.EnvAdded)+len(diff.EnvRemoved)+len(diff.EnvChanged) == 0 {
+		fmt.Fprintln(w, "  No changes")
+	}
+	for _, name := range diff.EnvAdded {
+		fmt.Fprintln(w, cs.Green("+ "+name))
+	}
+	for _, name := range diff.EnvRemoved {
+		fmt.Fprintln(w, cs.Red("- "+name))
+	}
+	for _, name := range diff.EnvChanged {
+		fmt.Fprintln(w, cs.Yellow("~ "+name))
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, cs.Bold("Config"))
+	if diff.ConfigDiff == "" {
+		fmt.Fprintln(w, "  No changes")
+	} else {
+		fmt.Fprint(w, cmdutil.ColorizeDiff(diff.ConfigDiff, cs))
+	}
+
+	return nil
+}
+
+func formatImage(img *api.Image) string {
+	switch {
+	case img == nil:
+		return "(none)"
+	case img.Digest == "" || strings.HasSuffix(img.Ref, img.Digest):
+		return img.Ref
+	default:
+		return fmt.Sprintf("%s (%s)", img.Ref, img.Digest)
+	}
+}
@@ -0,0 +1,378 @@
+package apps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/cmd/presenters"
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/prompt"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/cmdutil"
+	"github.com/superfly/flyctl/internal/registry"
+	"github.com/superfly/flyctl/pkg/iostreams"
+)
+
+func newReleasesPrune() (cmd *cobra.Command) {
+	const (
+		long = `Delete old releases of the application along with the images only they
+deployed. Images are told apart by digest, so an image which a release that's
+kept deploys under another tag is kept too.
+
+Releases beyond the newest --keep which are older than --max-age are pruned;
+either may be omitted. Absent both, the release retention policy of the
+organization applies; see 'fly orgs release-policy'.
+
+The current release, the latest stable one (the last known good release) and
+the releases --protect names are never pruned.
+`
+		short = "Prune old app releases"
+	)
+
+	cmd = command.New("prune", short, long, runReleasesPrune,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Yes(),
+		flag.Int{
+			Name:        "keep",
+			Description: "Number of the newest releases to keep",
+		},
+		flag.String{
+			Name:        "max-age",
+			Description: "Only prune releases older than this, e.g. 90d or 720h",
+		},
+		flag.StringSlice{
+			Name:        "protect",
+			Description: "Versions of releases to never prune, e.g. v12",
+		},
+		flag.Bool{
+			Name:        "dry-run",
+			Description: "List the releases which would be pruned without pruning them",
+		},
+		flag.Bool{
+			Name:        "keep-images",
+			Description: "Keep the images of pruned releases in the registry",
+		},
+	)
+
+	cmd.Example = `fly releases prune --keep 50
+fly releases prune --max-age 90d --protect v120`
+
+	return
+}
+
+// PrunePlan wraps the releases prune deletes and the images only they
+// deployed.
+type PrunePlan struct {
+	Policy    api.ReleaseRetentionPolicy `json:"policy"`
+	Releases  []api.Release              `json:"releases"`
+	Images    []string                   `json:"images"`
+	Protected []int                      `json:"protected"`
+}
+
+func runReleasesPrune(ctx context.Context) error {
+	var (
+		appName   = app.NameFromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+		io        = iostreams.FromContext(ctx)
+		cfg       = config.FromContext(ctx)
+	)
+
+	policy, err := prunePolicy(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	protect, err := protectedVersions(flag.GetStringSlice(ctx, "protect"))
+	if err != nil {
+		return err
+	}
+
+	// releases which aren't seen can't be told apart from unused ones
+	releases, err := apiClient.GetAllAppReleases(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app releases %s: %w", appName, err)
+	}
+
+	reg := &registry.Client{
+		Host:  cfg.RegistryHost,
+		Token: cfg.AccessToken,
+	}
+
+	plan := planPrune(releases, *policy, protect, time.Now())
+	if !flag.GetBool(ctx, "keep-images") {
+		digests, err := resolveDigests(ctx, reg, appName, releases)
+		if err != nil {
+			return err
+		}
+
+		if plan.Images, err = orphanedImages(releases, plan.Releases, digests, reg.DigestRef); err != nil {
+			return err
+		}
+	}
+
+	if cfg.StructuredOutput() && flag.GetBool(ctx, "dry-run") {
+		return render.Structured(ctx, io.Out, plan)
+	}
+
+	if len(plan.Releases) == 0 {
+		fmt.Fprintf(io.ErrOut, "No releases of %s to prune\n", appName)
+
+		return nil
+	}
+
+	if !cfg.StructuredOutput() {
+		rows := make([][]string, 0, len(plan.Releases))
+		for _, release := range plan.Releases {
+			rows = append(rows, []string{
+				fmt.Sprintf("v%d", release.Version),
+				formatReleaseDescription(release),
+				presenters.FormatRelativeTime(release.CreatedAt),
+			})
+		}
+
+		if err := render.Table(io.Out, "Releases to prune", rows, "Version", "Description", "Date"); err != nil {
+			return err
+		}
+	}
+
+	if flag.GetBool(ctx, "dry-run") {
+		fmt.Fprintf(io.ErrOut, "Would prune %d releases and %d images; protected: %s\n",
+			len(plan.Releases), len(plan.Images), formatVersions(plan.Protected))
+
+		return nil
+	}
+
+	if !flag.GetYes(ctx) {
+		msg := fmt.Sprintf("Prune %d releases of %s and %d images?", len(plan.Releases), appName, len(plan.Images))
+
+		switch confirmed, err := prompt.Confirmf(ctx, msg); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	ids := make([]string, 0, len(plan.Releases))
+	for _, release := range plan.Releases {
+		ids = append(ids, release.ID)
+	}
+
+	deleted, err := apiClient.DeleteReleases(ctx, appName, ids)
+	if err != nil {
+		return fmt.Errorf("failed pruning releases of %s: %w", appName, err)
+	}
+
+	// images of releases which were pruned are not in use, so failing to
+	// delete them is reported but isn't fatal
+	var images int
+	for _, ref := range plan.Images {
+		if err := reg.DeleteImage(ctx, ref); err != nil {
+			fmt.Fprintf(io.ErrOut, "WARNING: %v\n", err)
+
+			continue
+		}
+		images++
+	}
+
+	if cfg.StructuredOutput() {
+		return render.Structured(ctx, io.Out, plan)
+	}
+
+	fmt.Fprintf(io.Out, "Pruned %d releases and %d images of %s\n", deleted, images, appName)
+
+	return nil
+}
+
+// prunePolicy returns the policy the flags denote or, in case they denote
+// none, the release retention policy of the organization of the named app.
+func prunePolicy(ctx context.Context, appName string) (*api.ReleaseRetentionPolicy, error) {
+	policy := &api.ReleaseRetentionPolicy{
+		Keep: flag.GetInt(ctx, "keep"),
+	}
+
+	if policy.Keep < 0 {
+		return nil, errors.New("--keep may not be negative")
+	}
+
+	if s := flag.GetString(ctx, "max-age"); s != "" {
+		age, err := cmdutil.ParseDays(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --max-age %q; %w", s, err)
+		}
+		policy.MaxAgeDays = age
+	}
+
+	if policy.Keep > 0 || policy.MaxAgeDays > 0 {
+		return policy, nil
+	}
+
+	apiClient := client.FromContext(ctx).API()
+
+	appCompact, err := apiClient.GetAppCompact(ctx, appName)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	org := appCompact.Organization.Slug
+
+	orgPolicy, err := apiClient.GetOrganizationReleaseRetentionPolicy(ctx, org)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving the release retention policy of %s: %w", org, err)
+	} else if orgPolicy == nil || (orgPolicy.Keep == 0 && orgPolicy.MaxAgeDays == 0) {
+		return nil, fmt.Errorf("organization %s has no release retention policy; specify --keep or --max-age", org)
+	}
+
+	return orgPolicy, nil
+}
+
+func protectedVersions(args []string) (map[int]bool, error) {
+	protect := make(map[int]bool, len(args))
+
+	for _, arg := range args {
+		version, err := parseReleaseVersion(arg)
+		if err != nil {
+			return nil, err
+		}
+		protect[version] = true
+	}
+
+	return protect, nil
+}
+
+// planPrune returns the plan which prunes releases by policy as of now. The
+// newest release, the latest stable one, releases in progress and those
+// protect names are never pruned.
+func planPrune(releases []api.Release, policy api.ReleaseRetentionPolicy, protect map[int]bool, now time.Time) *PrunePlan {
+	plan := &PrunePlan{
+		Policy: policy,
+	}
+
+	sorted := append([]api.Release(nil), releases...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version > sorted[j].Version
+	})
+
+	var latestStable bool
+	maxAge := time.Duration(policy.MaxAgeDays) * 24 * time.Hour
+
+	for i, release := range sorted {
+		protected := i == 0 || release.InProgress || protect[release.Version]
+		if release.Stable && !latestStable {
+			latestStable = true
+			protected = true
+		}
+
+		switch {
+		case protected:
+			plan.Protected = append(plan.Protected, release.Version)
+		case policy.Keep > 0 && i < policy.Keep:
+		case policy.MaxAgeDays > 0 && now.Sub(release.CreatedAt) < maxAge:
+		default:
+			plan.Releases = append(plan.Releases, release)
+		}
+	}
+
+	return plan
+}
+
+// resolveDigests maps the images of the given releases the repository of the
+// named app hosts to the digests of their manifests. Images the registry no
+// longer has are left out, and so are the ones of other repositories, which
+// releases deploy after apps fork, bundle restores or deploys of another app's
+// image, since they aren't the app's to delete.
+//
+// Deleting an image deletes its manifest, and with it every tag of it, so the
+// images of pruned releases may only be deleted once it's known which digests
+// the releases which are kept deploy.
+func resolveDigests(ctx context.Context, reg *registry.Client, appName string, releases []api.Release) (map[string]string, error) {
+	digests := map[string]string{}
+
+	for _, release := range releases {
+		ref := release.ImageRef
+		if _, seen := digests[ref]; seen || ref == "" || !reg.InRepository(ref, appName) {
+			continue
+		}
+
+		digest, err := reg.ResolveDigest(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed resolving the image of v%d; specify --keep-images to prune releases regardless: %w", release.Version, err)
+		}
+
+		digests[ref] = digest
+	}
+
+	return digests, nil
+}
+
+// orphanedImages returns references, by digest, to the images of the pruned
+// releases which no other release deploys, whichever tag the others deploy
+// them by.
+func orphanedImages(all, pruned []api.Release, digests map[string]string, digestRef func(ref, digest string) (string, error)) ([]string, error) {
+	prunedIDs := make(map[string]bool, len(pruned))
+	for _, release := range pruned {
+		prunedIDs[release.ID] = true
+	}
+
+	inUse := map[string]bool{}
+	for _, release := range all {
+		if !prunedIDs[release.ID] {
+			inUse[digests[release.ImageRef]] = true
+		}
+	}
+
+	seen := map[string]bool{}
+
+	var images []string
+	for _, release := range pruned {
+		digest := digests[release.ImageRef]
+		if digest == "" || inUse[digest] || seen[digest] {
+			continue
+		}
+		seen[digest] = true
+
+		ref, err := digestRef(release.ImageRef, digest)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, ref)
+	}
+
+	return images, nil
+}
+
+func formatVersions(versions []int) string {
+	if len(versions) == 0 {
+		return "none"
+	}
+
+	s := make([]string, 0, len(versions))
+	for _, v := range versions {
+		s = append(s, fmt.Sprintf("v%d", v))
+	}
+
+	return strings.Join(s, ", ")
+}
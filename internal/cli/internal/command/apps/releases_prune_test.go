@@ -0,0 +1,42 @@
+package apps
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/registry"
+)
+
+func TestPruneLeavesImagesOfOtherApps(t *testing.T) {
+	var resolved []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resolved = append(resolved, r.URL.Path)
+		w.Header().Set("Docker-Content-Digest", "sha256:"+strings.TrimPrefix(r.URL.Path, "/v2/"))
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	reg := &registry.Client{Host: host, Scheme: "http"}
+
+	all := []api.Release{
+		{ID: "1", Version: 1, ImageRef: host + "/app:deployment-1"},
+		{ID: "2", Version: 2, ImageRef: host + "/other:deployment-9"}, // e.g. restored from a bundle of other
+		{ID: "3", Version: 3, ImageRef: host + "/app:deployment-3"},
+	}
+
+	digests, err := resolveDigests(context.Background(), reg, "app", all)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/v2/app/manifests/deployment-1", "/v2/app/manifests/deployment-3"}, resolved)
+
+	images, err := orphanedImages(all, all[:2], digests, reg.DigestRef)
+	require.NoError(t, err)
+	assert.Equal(t, []string{host + "/app@sha256:app/manifests/deployment-1"}, images)
+}
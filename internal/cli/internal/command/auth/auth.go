@@ -127,6 +127,16 @@ func waitForCLISession(parent context.Context, logger *logger.Logger, w io.Write
 func persistAccessToken(ctx context.Context, token string) (err error) {
 	path := state.ConfigFile(ctx)
 
+	// the token of the context in use takes precedence over the top-level one
+	if name := config.FromContext(ctx).Context; name != "" {
+		if err = config.SetContextAccessToken(path, name, token); err != nil {
+			err = fmt.Errorf("failed persisting the access token of context %s in %s: %w\n",
+				name, path, err)
+		}
+
+		return
+	}
+
 	if err = config.SetAccessToken(path, token); err != nil {
 		err = fmt.Errorf("failed persisting %s in %s: %w\n",
 			config.AccessTokenFileKey, path, err)
@@ -2,14 +2,21 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
 	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
 	"github.com/superfly/flyctl/internal/cli/internal/flag"
 	"github.com/superfly/flyctl/internal/cli/internal/prompt"
+	"github.com/superfly/flyctl/internal/cli/internal/state"
+	"github.com/superfly/flyctl/internal/client"
 )
 
 func newLogin() *cobra.Command {
@@ -17,6 +24,13 @@ func newLogin() *cobra.Command {
 		long = `Logs a user into the Fly platform. Supports browser-based, 
 email/password and one-time-password authentication. Defaults to using 
 browser-based authentication.
+
+In CI, --oidc exchanges the OIDC ID token of the job for a short-lived access
+token to the organization --org names, so that pipelines may deploy without
+long-lived secrets. GitHub Actions jobs need the id-token: write permission;
+GitLab CI jobs need FLY_ID_TOKEN declared under id_tokens, with aud
+https://fly.io. Once the token is about to expire, later commands of the job
+exchange the ID token again.
 `
 		short = "Log in a user"
 	)
@@ -41,8 +55,15 @@ browser-based authentication.
 			Name:        "otp",
 			Description: "One time password",
 		},
+		flag.Bool{
+			Name:        "oidc",
+			Description: "Log in with the OIDC ID token of the CI job",
+		},
+		flag.Org(),
 	)
 
+	cmd.Example = `fly auth login --oidc --org acme`
+
 	return cmd
 }
 
@@ -55,6 +76,8 @@ func runLogin(ctx context.Context) error {
 	)
 
 	switch {
+	case flag.GetBool(ctx, "oidc"):
+		return runOIDCLogin(ctx)
 	case interactive, email != "", password != "", otp != "":
 		return runShellLogin(ctx, email, password, otp)
 	default:
@@ -113,3 +136,36 @@ func runShellLogin(ctx context.Context, email, password, otp string) (err error)
 
 	return
 }
+
+func runOIDCLogin(ctx context.Context) error {
+	cfg := config.FromContext(ctx)
+
+	org := cfg.Organization
+	if org == "" {
+		return errors.New("--org or FLY_ORG must be specified with --oidc")
+	}
+	if cfg.Context != "" {
+		return fmt.Errorf("OIDC logins may not be persisted in context %s; omit --context and unset FLY_CONTEXT", cfg.Context)
+	}
+
+	token, err := client.ExchangeOIDCToken(ctx, org)
+	if err != nil {
+		return err
+	}
+
+	path := state.ConfigFile(ctx)
+
+	session := config.OIDCSession{
+		Org:       org,
+		ExpiresAt: token.ExpiresAt,
+	}
+
+	if err := config.SetOIDCAccessToken(path, token.AccessToken, session); err != nil {
+		return fmt.Errorf("failed persisting %s in %s: %w", config.AccessTokenFileKey, path, err)
+	}
+
+	io := iostreams.FromContext(ctx)
+	fmt.Fprintf(io.Out, "successfully logged in to %s until %s\n", org, token.ExpiresAt.Format(time.RFC3339))
+
+	return nil
+}
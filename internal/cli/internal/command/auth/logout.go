@@ -44,6 +44,14 @@ func runLogout(ctx context.Context) (err error) {
 		return
 	}
 
+	if name := config.FromContext(ctx).Context; name != "" {
+		if err = config.SetContextAccessToken(path, name, ""); err != nil {
+			err = fmt.Errorf("failed clearing the access token of context %s: %w\n", name, err)
+
+			return
+		}
+	}
+
 	out := iostreams.FromContext(ctx).ErrOut
 
 	single := func(key string) {
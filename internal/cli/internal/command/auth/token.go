@@ -30,8 +30,8 @@ func runAuthToken(ctx context.Context) error {
 	cfg := config.FromContext(ctx)
 	token := cfg.AccessToken
 
-	if io := iostreams.FromContext(ctx); cfg.JSONOutput {
-		render.JSON(io.Out, map[string]string{"token": token})
+	if io := iostreams.FromContext(ctx); cfg.StructuredOutput() {
+		render.Structured(ctx, io.Out, map[string]string{"token": token})
 	} else {
 		fmt.Fprintln(io.Out, token)
 	}
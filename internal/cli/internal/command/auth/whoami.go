@@ -37,8 +37,8 @@ func runWhoAmI(ctx context.Context) error {
 	io := iostreams.FromContext(ctx)
 	cfg := config.FromContext(ctx)
 
-	if cfg.JSONOutput {
-		_ = render.JSON(io.Out, map[string]string{"email": user.Email})
+	if cfg.StructuredOutput() {
+		_ = render.Structured(ctx, io.Out, map[string]string{"email": user.Email})
 	} else {
 		fmt.Fprintln(io.Out, user.Email)
 	}
@@ -0,0 +1,315 @@
+// Package builders implements the builders command chain.
+package builders
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/build/imgsrc"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/prompt"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+// New initializes and returns a new builders Command.
+func New() (cmd *cobra.Command) {
+	const (
+		long = `Commands that manage the builders of organizations.
+
+Remote builds of the apps of an organization which has a self-hosted builder
+registered run on it instead of on the Fly-managed builder. Builders must
+serve the Docker Engine API over TCP, as dockerd does, with BuildKit enabled,
+on the private network of the organization: machines reached via their
+.internal or .flycast name or their 6PN address. They're dialed through the
+WireGuard tunnel of the organization, as the API is unauthenticated and
+pushes hand builders the registry credentials of the user.
+
+Builders are health checked before each build. Unless registered with
+--no-failover, builds fall back to the Fly-managed builder when theirs is
+unhealthy.
+
+Registrations belong to the organization, so every member and CI job of it
+builds on its builder.
+
+Builds of the apps of an organization share its builder. Admins may limit how
+many run at once, in which case builds queue for their turn; see 'fly builders
+settings' and 'fly builders queue'.
+`
+		short = "Manage builders and their build queues"
+	)
+
+	cmd = command.New("builders", short, long, nil)
+
+	cmd.AddCommand(
+		newRegister(),
+		newList(),
+		newCheck(),
+		newRemove(),
+		newQueue(),
+		newSettings(),
+	)
+
+	return
+}
+
+func newRegister() *cobra.Command {
+	const (
+		long = `Register the builder found at the given private address, in the form of
+tcp://host:port, with an organization, replacing any builder it had
+registered. The builder has to pass its health check to be registered.
+Registering builders requires being an admin of the organization.
+`
+		short = "Register a self-hosted builder"
+	)
+
+	cmd := command.New("register <address>", short, long, runRegister,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.Org(),
+		flag.Bool{
+			Name:        "no-failover",
+			Description: "Fail builds when the builder is unhealthy instead of falling back to the Fly-managed builder",
+		},
+	)
+
+	return cmd
+}
+
+func runRegister(ctx context.Context) error {
+	host, err := imgsrc.NormalizeBuilderHost(flag.FirstArg(ctx))
+	if err != nil {
+		return err
+	}
+
+	org, err := prompt.Org(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	b := imgsrc.SelfHostedBuilder{
+		Org:        org.Slug,
+		Host:       host,
+		NoFailover: flag.GetBool(ctx, "no-failover"),
+	}
+
+	if err := check(ctx, b); err != nil {
+		return err
+	}
+
+	_, err = client.FromContext(ctx).API().SetOrganizationSelfHostedBuilder(ctx, api.SetOrganizationSelfHostedBuilderInput{
+		OrganizationID: org.ID,
+		Host:           b.Host,
+		NoFailover:     b.NoFailover,
+	})
+	if err != nil {
+		return fmt.Errorf("failed registering builder: %w", err)
+	}
+
+	io := iostreams.FromContext(ctx)
+	fmt.Fprintf(io.Out, "Registered builder %s with %s\n", b.Host, b.Org)
+
+	return nil
+}
+
+func newList() *cobra.Command {
+	const (
+		long  = "List the self-hosted builders of the organizations you're a member of."
+		short = "List self-hosted builders"
+	)
+
+	cmd := command.New("list", short, long, runList,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.NoArgs
+	cmd.Aliases = []string{"ls"}
+
+	return cmd
+}
+
+func runList(ctx context.Context) error {
+	orgs, err := client.FromContext(ctx).API().GetSelfHostedBuilders(ctx)
+	if err != nil {
+		return fmt.Errorf("failed retrieving builders: %w", err)
+	}
+
+	builders := make([]imgsrc.SelfHostedBuilder, 0, len(orgs))
+	for _, org := range orgs {
+		builders = append(builders, selfHosted(org.Slug, org.SelfHostedBuilder))
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, out, builders)
+	}
+
+	rows := make([][]string, 0, len(builders))
+	for _, b := range builders {
+		failover := "yes"
+		if b.NoFailover {
+			failover = "no"
+		}
+
+		rows = append(rows, []string{b.Org, b.Host, failover})
+	}
+
+	return render.Table(out, "", rows, "Organization", "Host", "Failover")
+}
+
+func newCheck() *cobra.Command {
+	const (
+		long = `Health check the self-hosted builder of an organization, as builds do before
+they run on it.
+`
+		short = "Health check a self-hosted builder"
+	)
+
+	cmd := command.New("check", short, long, runCheck,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.Org(),
+	)
+
+	return cmd
+}
+
+func runCheck(ctx context.Context) error {
+	b, err := orgBuilder(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := check(ctx, *b); err != nil {
+		return err
+	}
+
+	io := iostreams.FromContext(ctx)
+	fmt.Fprintf(io.Out, "Builder %s of %s is healthy\n", b.Host, b.Org)
+
+	return nil
+}
+
+func newRemove() *cobra.Command {
+	const (
+		long = `Remove the self-hosted builder of an organization, so that its remote builds
+run on the Fly-managed builder. Removing builders requires being an admin of
+the organization.
+`
+		short = "Remove a self-hosted builder"
+	)
+
+	cmd := command.New("remove", short, long, runRemove,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.NoArgs
+	cmd.Aliases = []string{"rm"}
+
+	flag.Add(cmd,
+		flag.Org(),
+	)
+
+	return cmd
+}
+
+func runRemove(ctx context.Context) error {
+	org, err := prompt.Org(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	input := api.RemoveOrganizationSelfHostedBuilderInput{
+		OrganizationID: org.ID,
+	}
+
+	if err := client.FromContext(ctx).API().RemoveOrganizationSelfHostedBuilder(ctx, input); err != nil {
+		return fmt.Errorf("failed removing builder: %w", err)
+	}
+
+	io := iostreams.FromContext(ctx)
+	fmt.Fprintf(io.Out, "Removed the builder of %s\n", org.Slug)
+
+	return nil
+}
+
+func orgBuilder(ctx context.Context) (*imgsrc.SelfHostedBuilder, error) {
+	org, err := prompt.Org(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := client.FromContext(ctx).API().GetOrganizationSelfHostedBuilder(ctx, org.Slug)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("failed retrieving the builder of %s: %w", org.Slug, err)
+	case b == nil:
+		return nil, fmt.Errorf("organization %s has no self-hosted builder registered", org.Slug)
+	default:
+		sh := selfHosted(org.Slug, b)
+
+		return &sh, nil
+	}
+}
+
+func check(ctx context.Context, b imgsrc.SelfHostedBuilder) error {
+	tb := render.NewTextBlock(ctx, fmt.Sprintf("Checking builder %s", b.Host))
+
+	client := client.FromContext(ctx).API()
+	if err := imgsrc.CheckSelfHostedBuilder(ctx, client, &b); err != nil {
+		return fmt.Errorf("builder %s failed its health check: %w", b.Host, err)
+	}
+
+	tb.Done("Builder is healthy")
+
+	return nil
+}
+
+// ForApp returns the self-hosted builder the organization of the named app
+// has registered, or nil in case it has none or the app doesn't exist yet.
+func ForApp(ctx context.Context, client *api.Client, appName string) (*imgsrc.SelfHostedBuilder, error) {
+	app, err := client.GetAppCompact(ctx, appName)
+	if err != nil {
+		if api.IsNotFoundError(err) {
+			err = nil
+		}
+
+		return nil, err
+	}
+
+	org := app.Organization.Slug
+
+	b, err := client.GetOrganizationSelfHostedBuilder(ctx, org)
+	if err != nil || b == nil {
+		return nil, err
+	}
+
+	sh := selfHosted(org, b)
+
+	return &sh, nil
+}
+
+// selfHosted returns the self-hosted builder the organization with the given
+// slug registered.
+func selfHosted(org string, b *api.SelfHostedBuilder) imgsrc.SelfHostedBuilder {
+	return imgsrc.SelfHostedBuilder{
+		Org:        org,
+		Host:       b.Host,
+		NoFailover: b.NoFailover,
+	}
+}
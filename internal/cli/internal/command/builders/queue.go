@@ -0,0 +1,244 @@
+package builders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/internal/build/imgsrc"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/format"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+func newQueue() *cobra.Command {
+	const (
+		long = `List the builds which run or wait for their turn on the remote builder the
+builds of an app run on, in the order they run in.
+
+Builds queue once as many others run on the builder as its settings allow;
+see 'fly builders settings'.
+`
+		short = "List the build queue of a remote builder"
+	)
+
+	cmd := command.New("queue", short, long, runQueue,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func runQueue(ctx context.Context) error {
+	queue, err := buildQueue(ctx)
+	if err != nil {
+		return err
+	}
+
+	settings, err := queue.Settings(ctx)
+	if err != nil {
+		return err
+	}
+
+	entries, err := queue.Entries(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, entries)
+	}
+
+	var (
+		rows   = make([][]string, 0, len(entries))
+		queued int
+	)
+
+	for _, e := range entries {
+		position, state, since := "", "building", e.Started
+		if !e.Building {
+			queued++
+			position, state, since = strconv.Itoa(queued), "queued", e.Enqueued
+		}
+
+		priority := ""
+		if e.Priority {
+			priority = "yes"
+		}
+
+		rows = append(rows, []string{position, e.App, state, priority, format.RelativeTime(since)})
+	}
+
+	concurrency := "unlimited"
+	if settings.Concurrency > 0 {
+		concurrency = strconv.Itoa(settings.Concurrency)
+	}
+
+	title := fmt.Sprintf("Build Queue (concurrency %s)", concurrency)
+
+	return render.Table(out, title, rows, "Position", "App", "State", "Priority", "Since")
+}
+
+func newSettings() *cobra.Command {
+	const (
+		long = `Show or update the queue settings of the remote builder the builds of an app
+run on. Updating them requires being an admin of the organization of the app.
+
+Once --concurrency builds run on the builder at once, the ones which follow
+queue for their turn in the order they arrived in, save for the builds of the
+apps given via --priority-app, such as production ones, which jump the queue.
+A --concurrency of 0 lifts the limit.
+`
+		short = "Show or update the queue settings of a remote builder"
+	)
+
+	cmd := command.New("settings", short, long, runSettings,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Int{
+			Name:        "concurrency",
+			Description: "Number of builds which may run at once; 0 for no limit",
+		},
+		flag.StringSlice{
+			Name:        "priority-app",
+			Description: "App the builds of which jump the queue, replacing the current ones. Can be specified multiple times.",
+		},
+		flag.Bool{
+			Name:        "clear-priority-apps",
+			Description: "Remove all priority apps",
+		},
+	)
+
+	return cmd
+}
+
+func runSettings(ctx context.Context) error {
+	cmd := flag.FromContext(ctx)
+
+	update := cmd.Changed("concurrency") || cmd.Changed("priority-app") || cmd.Changed("clear-priority-apps")
+	if update {
+		if err := requireOrgAdmin(ctx); err != nil {
+			return err
+		}
+	}
+
+	queue, err := buildQueue(ctx)
+	if err != nil {
+		return err
+	}
+
+	settings, err := queue.Settings(ctx)
+	if err != nil {
+		return err
+	}
+
+	if update {
+		if cmd.Changed("concurrency") {
+			if settings.Concurrency = flag.GetInt(ctx, "concurrency"); settings.Concurrency < 0 {
+				return errors.New("--concurrency must not be negative")
+			}
+		}
+
+		if flag.GetBool(ctx, "clear-priority-apps") {
+			settings.PriorityApps = nil
+		}
+
+		if apps := flag.GetStringSlice(ctx, "priority-app"); len(apps) > 0 {
+			settings.PriorityApps = apps
+		}
+
+		if err := queue.SetSettings(ctx, settings); err != nil {
+			return err
+		}
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, settings)
+	}
+
+	concurrency := "unlimited"
+	if settings.Concurrency > 0 {
+		concurrency = strconv.Itoa(settings.Concurrency)
+	}
+
+	average := "-"
+	if settings.AverageBuild > 0 {
+		average = settings.AverageBuild.Round(time.Second).String()
+	}
+
+	return render.VerticalTable(out, "Build Queue Settings", [][]string{
+		{concurrency, strings.Join(settings.PriorityApps, ", "), average},
+	}, "Concurrency", "Priority Apps", "Average Build")
+}
+
+// buildQueue returns the build queue of the remote builder the builds of the
+// app run on.
+func buildQueue(ctx context.Context) (*imgsrc.BuildQueue, error) {
+	var (
+		appName   = app.NameFromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+		io        = iostreams.FromContext(ctx)
+	)
+
+	resolver := imgsrc.NewResolver(imgsrc.NewDockerDaemonType(false, true), apiClient, appName, io)
+
+	b, err := ForApp(ctx, apiClient, appName)
+	if err != nil {
+		return nil, fmt.Errorf("failed determining the self-hosted builder of %s: %w", appName, err)
+	} else if b != nil {
+		resolver.UseSelfHostedBuilder(b)
+	}
+
+	return resolver.BuildQueue(ctx)
+}
+
+func requireOrgAdmin(ctx context.Context) error {
+	var (
+		appName   = app.NameFromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+	)
+
+	compact, err := apiClient.GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	org, err := apiClient.GetOrganizationBySlug(ctx, compact.Organization.Slug)
+	if err != nil {
+		return fmt.Errorf("failed retrieving organization %s: %w", compact.Organization.Slug, err)
+	}
+
+	if !strings.EqualFold(org.ViewerRole, "admin") {
+		return fmt.Errorf("updating build queue settings requires being an admin of %s", org.Slug)
+	}
+
+	return nil
+}
@@ -47,8 +47,8 @@ func runList(ctx context.Context) (err error) {
 	}
 
 	out := iostreams.FromContext(ctx).Out
-	if cfg := config.FromContext(ctx); cfg.JSONOutput {
-		_ = render.JSON(out, builds)
+	if cfg := config.FromContext(ctx); cfg.StructuredOutput() {
+		_ = render.Structured(ctx, out, builds)
 
 		return
 	}
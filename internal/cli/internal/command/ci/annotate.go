@@ -0,0 +1,194 @@
+package ci
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/github"
+)
+
+func newAnnotate() *cobra.Command {
+	const (
+		long = `The annotate commands report the state of deployments to CI providers.
+`
+		short = "Report deployment states to CI providers"
+	)
+
+	cmd := command.New("annotate", short, long, nil)
+
+	cmd.AddCommand(
+		newAnnotateGitHub(),
+	)
+
+	return cmd
+}
+
+func newAnnotateGitHub() (cmd *cobra.Command) {
+	const (
+		long = `Create or update the GitHub deployment of the current commit to the given
+environment and set its status, so that the state of deployments of the app
+shows up in pull requests and the environments of the repository.
+
+The repository and token default to the GITHUB_REPOSITORY and GITHUB_TOKEN
+environment variables GitHub Actions set. The token needs the deployments
+write permission.
+`
+		short = "Report the state of a deployment to GitHub"
+	)
+
+	cmd = command.New("github", short, long, runAnnotateGitHub,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.String{
+			Name:        "state",
+			Description: "State of the deployment, one of " + strings.Join(github.States, ", "),
+			Default:     github.StateInProgress,
+		},
+		flag.String{
+			Name:        "environment",
+			Description: "GitHub environment the app is deployed to",
+			Default:     DefaultGitHubEnvironment,
+		},
+		flag.String{
+			Name:        "ref",
+			Description: "Commit, branch or tag being deployed. Defaults to GITHUB_SHA.",
+		},
+		flag.String{
+			Name:        "repo",
+			Description: "Repository of the deployment, as <owner>/<name>. Defaults to GITHUB_REPOSITORY.",
+		},
+		flag.String{
+			Name:        "description",
+			Description: "Description of the deployment status",
+		},
+		flag.Int{
+			Name:        "deployment-id",
+			Description: "ID of the GitHub deployment to update instead of the latest one of the ref",
+		},
+	)
+
+	return
+}
+
+func runAnnotateGitHub(ctx context.Context) error {
+	state := flag.GetString(ctx, "state")
+	if !isState(state) {
+		return fmt.Errorf("invalid state %q; use one of %s", state, strings.Join(github.States, ", "))
+	}
+
+	client, err := github.FromEnv(flag.GetString(ctx, "repo"))
+	if err != nil {
+		return err
+	}
+
+	a := GitHubAnnotation{
+		App:          app.NameFromContext(ctx),
+		Environment:  flag.GetString(ctx, "environment"),
+		Ref:          flag.GetString(ctx, "ref"),
+		DeploymentID: int64(flag.GetInt(ctx, "deployment-id")),
+	}
+
+	id, err := a.Annotate(ctx, client, state, flag.GetString(ctx, "description"))
+	if err != nil {
+		return err
+	}
+
+	if out := iostreams.FromContext(ctx).Out; config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, out, map[string]interface{}{
+			"deployment_id": id,
+			"state":         state,
+		})
+	}
+
+	tb := render.NewTextBlock(ctx)
+	tb.Donef("GitHub deployment %d of %s marked as %s", id, a.Environment, state)
+
+	return nil
+}
+
+// DefaultGitHubEnvironment denotes the environment GitHub deployments default
+// to.
+const DefaultGitHubEnvironment = "production"
+
+// GitHubAnnotation reports the states of the deployment of an app to a
+// GitHub deployment. The deployment, unless set, is the latest one of the ref
+// to the environment, or a new one in case there's none.
+type GitHubAnnotation struct {
+	App          string
+	Environment  string
+	Ref          string
+	DeploymentID int64
+}
+
+// Annotate sets the state of the deployment of a, returning its ID. Failed
+// deployments link to the releases of the app.
+func (a *GitHubAnnotation) Annotate(ctx context.Context, client *github.Client, state, description string) (int64, error) {
+	if a.Environment == "" {
+		a.Environment = DefaultGitHubEnvironment
+	}
+
+	if a.Ref == "" {
+		if a.Ref = os.Getenv("GITHUB_SHA"); a.Ref == "" {
+			return 0, fmt.Errorf("no ref to deploy; set --ref or GITHUB_SHA")
+		}
+	}
+
+	if a.DeploymentID == 0 {
+		d, err := client.EnsureDeployment(ctx, github.DeploymentInput{
+			Ref:         a.Ref,
+			Environment: a.Environment,
+			Description: fmt.Sprintf("Deployment of %s", a.App),
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		a.DeploymentID = d.ID
+	}
+
+	status := github.DeploymentStatus{
+		State:          state,
+		Description:    description,
+		Environment:    a.Environment,
+		EnvironmentURL: fmt.Sprintf("https://%s.fly.dev", a.App),
+		LogURL:         github.RunURL(),
+	}
+
+	switch state {
+	case github.StateFailure, github.StateError:
+		status.LogURL = fmt.Sprintf("https://fly.io/apps/%s/releases", a.App)
+	}
+
+	if err := client.CreateDeploymentStatus(ctx, a.DeploymentID, status); err != nil {
+		return 0, err
+	}
+
+	return a.DeploymentID, nil
+}
+
+func isState(state string) bool {
+	for _, s := range github.States {
+		if s == state {
+			return true
+		}
+	}
+
+	return false
+}
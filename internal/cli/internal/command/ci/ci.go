@@ -0,0 +1,26 @@
+// Package ci implements the ci command chain.
+package ci
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+)
+
+// New initializes and returns a new ci command.
+func New() *cobra.Command {
+	const (
+		long = `The CI commands integrate deployments with continuous integration
+providers.
+`
+		short = "Integrate with CI providers"
+	)
+
+	cmd := command.New("ci", short, long, nil)
+
+	cmd.AddCommand(
+		newAnnotate(),
+	)
+
+	return cmd
+}
@@ -9,20 +9,28 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/blang/semver"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/flyctl"
 	"github.com/superfly/flyctl/pkg/iostreams"
 
 	"github.com/superfly/flyctl/internal/buildinfo"
 	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/cmdutil"
 	"github.com/superfly/flyctl/internal/env"
+	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/internal/logger"
+	"github.com/superfly/flyctl/internal/sandbox"
+	"github.com/superfly/flyctl/internal/tracing"
 	"github.com/superfly/flyctl/internal/update"
 
+	"github.com/superfly/flyctl/internal/cli/internal/apicache"
 	"github.com/superfly/flyctl/internal/cli/internal/app"
 	"github.com/superfly/flyctl/internal/cli/internal/cache"
 	"github.com/superfly/flyctl/internal/cli/internal/config"
@@ -58,17 +66,23 @@ var commonPreparers = []Preparer{
 	ensureConfigDirPerms,
 	loadCache,
 	loadConfig,
+	applyTheme,
+	applyVerbosity,
+	checkExperiments,
 	initTaskManager,
 	startQueryingForNewRelease,
 	promptToUpdate,
 	initClient,
 	killOldAgent,
+	reapSandboxes,
 }
 
 // TODO: remove after migration is complete
 func WrapRunE(fn func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
 	return func(cmd *cobra.Command, args []string) (err error) {
-		ctx := cmd.Context()
+		ctx, span := tracing.Start(cmd.Context(), cmd.CommandPath())
+		defer func() { tracing.End(span, err) }()
+
 		ctx = NewContext(ctx, cmd)
 		ctx = flag.NewContext(ctx, cmd.Flags())
 
@@ -92,7 +106,9 @@ func newRunE(fn Runner, preparers ...Preparer) func(*cobra.Command, []string) er
 	}
 
 	return func(cmd *cobra.Command, _ []string) (err error) {
-		ctx := cmd.Context()
+		ctx, span := tracing.Start(cmd.Context(), cmd.CommandPath())
+		defer func() { tracing.End(span, err) }()
+
 		ctx = NewContext(ctx, cmd)
 		ctx = flag.NewContext(ctx, cmd.Flags())
 
@@ -269,17 +285,123 @@ func loadConfig(ctx context.Context) (context.Context, error) {
 		return nil, err
 	}
 
+	// Apply the selected context, overriding the credentials of the file
+	if err := cfg.ApplyContext(path, config.ContextName(flag.FromContext(ctx))); err != nil {
+		return nil, err
+	}
+
 	// Apply config from the environment, overriding anything from the file
 	cfg.ApplyEnv()
 
 	// Finally, apply command line options, overriding any previous setting
 	cfg.ApplyFlags(flag.FromContext(ctx))
 
+	// legacy commands and image pushes read the access token via viper
+	if cfg.Context != "" {
+		viper.Set(flyctl.ConfigAPIToken, cfg.AccessToken)
+	}
+
 	logger.Debug("config initialized.")
 
 	return config.NewContext(ctx, cfg), nil
 }
 
+// applyVerbosity sets the output verbosity the config denotes on the streams,
+// which the text blocks, build output and deployment monitoring honor. Debug
+// verbosity also enables debug logging.
+func applyVerbosity(ctx context.Context) (context.Context, error) {
+	io := iostreams.FromContext(ctx)
+	io.SetVerbosity(config.FromContext(ctx).Verbosity())
+
+	if io.Verbosity() >= iostreams.VerbosityDebug && logger.FromContext(ctx).Level() > logger.Debug {
+		ctx = logger.NewContext(ctx, logger.New(io.ErrOut, logger.Debug))
+	}
+
+	return ctx, nil
+}
+
+// applyTheme sets the theme the config denotes, if any, on the streams. The
+// no-color theme, like NO_COLOR, disables color altogether.
+func applyTheme(ctx context.Context) (context.Context, error) {
+	name := config.FromContext(ctx).Theme
+	if name == "" {
+		return ctx, nil
+	}
+
+	theme, err := iostreams.ParseTheme(name)
+	if err != nil {
+		return nil, err
+	}
+
+	io := iostreams.FromContext(ctx)
+	io.SetTheme(theme)
+
+	if theme.NoColor {
+		// the logger writes to the streams the theme replaced
+		ctx = logger.NewContext(ctx, logger.FromEnv(io.ErrOut))
+	}
+
+	return ctx, nil
+}
+
+// oidcRefreshMargin is how long before they expire the access tokens of OIDC
+// sessions are exchanged again, so that they don't expire mid-command.
+const oidcRefreshMargin = 10 * time.Minute
+
+// refreshOIDCToken exchanges the OIDC ID token of the CI job again once the
+// access token of the OIDC session it logged in with is about to expire.
+// Outside of the job, the token is left to expire.
+func refreshOIDCToken(ctx context.Context, cfg *config.Config) error {
+	session := cfg.OIDC
+	if session == nil || !session.Expired(time.Now(), oidcRefreshMargin) || !client.OIDCAvailable() {
+		return nil
+	}
+
+	logger := logger.FromContext(ctx)
+
+	token, err := client.ExchangeOIDCToken(ctx, session.Org)
+	if err != nil {
+		return fmt.Errorf("failed refreshing the access token of the OIDC session: %w", err)
+	}
+
+	session.ExpiresAt = token.ExpiresAt
+	if err := config.SetOIDCAccessToken(state.ConfigFile(ctx), token.AccessToken, *session); err != nil {
+		// the token remains valid for this command
+		logger.Warnf("failed persisting the refreshed access token: %v", err)
+	}
+
+	cfg.AccessToken = token.AccessToken
+	viper.Set(flyctl.ConfigAPIToken, token.AccessToken)
+
+	logger.Debugf("refreshed the access token of the OIDC session; it expires at %s", token.ExpiresAt)
+
+	return nil
+}
+
+// requestTracer returns the logger requests to the API are traced through, if
+// the user wants them traced via --debug-http, --debug-http-file or
+// LOG_LEVEL=trace.
+//
+// Trace files are appended to, so that the traces of the commands a support
+// ticket reproduces end up in one file; they're left open until flyctl exits.
+func requestTracer(ctx context.Context, cfg *config.Config) (api.RequestTracer, error) {
+	switch l := logger.FromContext(ctx); {
+	case cfg.DebugHTTPFile != "":
+		f, err := os.OpenFile(cfg.DebugHTTPFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed opening the trace file: %w", err)
+		}
+
+		return logger.New(f, logger.Trace), nil
+	case cfg.DebugHTTP:
+		return logger.New(iostreams.FromContext(ctx).ErrOut, logger.Trace), nil
+	case l.Level() <= logger.Trace:
+		return l, nil
+	default:
+		return nil, nil
+	}
+}
+
 func initClient(ctx context.Context) (context.Context, error) {
 	logger := logger.FromContext(ctx)
 	cfg := config.FromContext(ctx)
@@ -287,6 +409,21 @@ func initClient(ctx context.Context) (context.Context, error) {
 	// TODO: refactor so that api package does NOT depend on global state
 	api.SetBaseURL(cfg.APIBaseURL)
 	api.SetErrorLog(cfg.LogGQLErrors)
+
+	policy := api.DefaultRetryPolicy
+	policy.MaxRetries = cfg.APIMaxRetries
+	api.SetRetryPolicy(policy)
+
+	tracer, err := requestTracer(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	api.SetRequestTracer(tracer)
+
+	if err := refreshOIDCToken(ctx, cfg); err != nil {
+		return nil, err
+	}
+
 	c := client.FromToken(cfg.AccessToken)
 	logger.Debug("client initialized.")
 
@@ -428,6 +565,29 @@ func killOldAgent(ctx context.Context) (context.Context, error) {
 	return ctx, nil
 }
 
+// reapSandboxes destroys the expired sandboxes created from this machine, so
+// that sandboxes outliving their reaper process, e.g. past a reboot, are
+// destroyed by the next command which runs.
+func reapSandboxes(ctx context.Context) (context.Context, error) {
+	client := client.FromContext(ctx)
+	if !client.Authenticated() {
+		return ctx, nil
+	}
+
+	store := sandbox.NewStore(state.ConfigDirectory(ctx))
+
+	reaped, err := store.Reap(ctx, client.API())
+	for _, sb := range reaped {
+		fmt.Fprintf(iostreams.FromContext(ctx).ErrOut, "Destroyed expired sandbox %s\n", sb.Slug)
+	}
+
+	if err != nil {
+		logger.FromContext(ctx).Warnf("failed destroying expired sandboxes: %v", err)
+	}
+
+	return ctx, nil
+}
+
 // RequireSession is a Preparer which makes sure a session exists.
 func RequireSession(ctx context.Context) (context.Context, error) {
 	if !client.FromContext(ctx).Authenticated() {
@@ -437,6 +597,21 @@ func RequireSession(ctx context.Context) (context.Context, error) {
 	return ctx, nil
 }
 
+// LoadAPICache is a Preparer which loads the offline cache of API responses
+// of the current account, unless it's disabled. Commands which load it define
+// the flag.NoCache flag.
+func LoadAPICache(ctx context.Context) (context.Context, error) {
+	cfg := config.FromContext(ctx)
+	if cfg.CacheTTL <= 0 || cfg.AccessToken == "" {
+		return ctx, nil
+	}
+
+	dir := apicache.Dir(state.ConfigDirectory(ctx), cfg.APIBaseURL, cfg.AccessToken)
+	c := apicache.New(dir, cfg.CacheTTL, flag.GetNoCache(ctx))
+
+	return apicache.NewContext(ctx, c), nil
+}
+
 // LoadAppConfigIfPresent is a Preparer which loads the application's
 // configuration file from the path the user has selected via command line args
 // or the current working directory.
@@ -448,6 +623,18 @@ func LoadAppConfigIfPresent(ctx context.Context) (context.Context, error) {
 		case err == nil:
 			logger.Debugf("app config loaded from %s", path)
 
+			if env := environmentName(ctx); env != "" {
+				if err := cfg.ApplyEnvironment(env); err != nil {
+					return nil, err
+				}
+
+				logger.Debugf("applied overrides of environment %s", env)
+			}
+
+			if err := interpolateAppConfig(ctx, cfg); err != nil {
+				return nil, err
+			}
+
 			return app.WithConfig(ctx, cfg), nil // we loaded a configuration file
 		case errors.Is(err, fs.ErrNotExist):
 			logger.Debugf("no app config found at %s; skipped.", path)
@@ -461,23 +648,82 @@ func LoadAppConfigIfPresent(ctx context.Context) (context.Context, error) {
 	return ctx, nil
 }
 
+// environmentName returns the name of the environment the user has selected via
+// command line args or the environment. Only commands which define the
+// environment flag, which deploy alone does, apply environments; all others
+// load the config file as written, so that those writing it back don't persist
+// the overrides.
+func environmentName(ctx context.Context) string {
+	if flag.FromContext(ctx).Lookup(flag.EnvironmentName) == nil {
+		return ""
+	}
+
+	if name := flag.GetEnvironment(ctx); name != "" {
+		return name
+	}
+
+	return env.First("FLY_ENV_NAME")
+}
+
+// interpolateAppConfig resolves the variables cfg references from the values
+// the user has specified via command line args, falling back to the
+// environment. Only commands which define the var flag, which deploy alone
+// does, interpolate; all others use the config file as written, so that those
+// writing it back don't replace its references with their values.
+func interpolateAppConfig(ctx context.Context, cfg *app.Config) error {
+	if !flag.IsDefined(ctx, flag.VarName) {
+		return nil
+	}
+
+	vars, err := cmdutil.ParseKVStringsToMap(flag.GetVars(ctx))
+	if err != nil {
+		return fmt.Errorf("invalid vars: %w", err)
+	}
+
+	lookup := func(name string) (string, bool) {
+		if v, ok := vars[name]; ok {
+			return v, true
+		}
+
+		return os.LookupEnv(name)
+	}
+
+	undefined, err := cfg.Interpolate(lookup, flag.GetStrictVars(ctx))
+	if err == nil && len(undefined) > 0 {
+		logger.FromContext(ctx).Warnf("app config references undefined variables, which expand to empty strings: %s",
+			strings.Join(undefined, ", "))
+	}
+
+	return err
+}
+
 // appConfigFilePaths returns the possible paths at which we may find a fly.toml
-// in order of preference. it takes into consideration whether the user has
-// specified a command-line path to a config file.
+// (or any of the other app.ConfigFileNames) in order of preference. it takes
+// into consideration whether the user has specified a command-line path to a
+// config file.
 func appConfigFilePaths(ctx context.Context) (paths []string) {
+	dir := state.WorkingDirectory(ctx)
+
 	if p := flag.GetAppConfigFilePath(ctx); p != "" {
-		paths = append(paths, p, filepath.Join(p, app.DefaultConfigFileName))
+		if fi, err := os.Stat(p); err != nil || !fi.IsDir() {
+			paths = append(paths, p)
 
-		return
+			return
+		}
+
+		dir = p
 	}
 
-	wd := state.WorkingDirectory(ctx)
-	paths = append(paths, filepath.Join(wd, app.DefaultConfigFileName))
+	for _, name := range app.ConfigFileNames {
+		paths = append(paths, filepath.Join(dir, name))
+	}
 
 	return
 }
 
-var errRequireAppName = fmt.Errorf("we couldn't find a fly.toml nor an app specified by the -a flag. If you want to launch a new app, use '%s launch'", buildinfo.Name())
+var errRequireAppName = &flyerr.ValidationError{
+	Err: fmt.Errorf("we couldn't find a fly.toml nor an app specified by the -a flag. If you want to launch a new app, use '%s launch'", buildinfo.Name()),
+}
 
 // RequireAppName is a Preparer which makes sure the user has selected an
 // application name via command line arguments, the environment or an application
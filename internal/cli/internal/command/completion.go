@@ -0,0 +1,77 @@
+package command
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/logger"
+
+	"github.com/superfly/flyctl/internal/cli/internal/apicache"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/state"
+)
+
+// completionTimeout bounds how long completions which have to ask the API
+// keep the shell waiting.
+const completionTimeout = 3 * time.Second
+
+// CompleteAppNames completes the names of the apps of the current account.
+// It serves the apps list the offline cache holds, so that completion works
+// offline and doesn't wait on the API, and completes nothing on failure.
+func CompleteAppNames(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// whatever completions print, the shell takes for completions
+	ctx = logger.NewContext(ctx, logger.New(io.Discard, logger.Error))
+	ctx = flag.NewContext(ctx, cmd.Flags())
+
+	ctx, err := prepare(ctx, determineUserHomeDir, determineConfigDir, loadConfig)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cfg := config.FromContext(ctx)
+	if cfg.AccessToken == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var cache *apicache.Cache
+	if cfg.CacheTTL > 0 {
+		cache = apicache.New(apicache.Dir(state.ConfigDirectory(ctx), cfg.APIBaseURL, cfg.AccessToken), cfg.CacheTTL, false)
+	}
+
+	api.SetBaseURL(cfg.APIBaseURL)
+	apiClient := client.FromToken(cfg.AccessToken).API()
+
+	ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+	defer cancel()
+
+	var apps []api.App
+	if err := cache.FetchCached(ctx, apicache.AppsKey, &apps, func(ctx context.Context) (err error) {
+		apps, err = apiClient.GetApps(ctx, nil)
+
+		return
+	}); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, app := range apps {
+		if strings.HasPrefix(app.Name, toComplete) {
+			names = append(names, app.Name)
+		}
+	}
+
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
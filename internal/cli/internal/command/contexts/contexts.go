@@ -0,0 +1,284 @@
+// Package contexts implements the contexts command chain.
+package contexts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/cli/internal/state"
+)
+
+// New initializes and returns a new contexts Command.
+func New() (cmd *cobra.Command) {
+	const (
+		long = `Commands that manage named contexts.
+
+A context is a named set of an access token, a default organization, a default
+region and an API base URL, which lets those who work with several accounts
+switch between them without logging in again:
+
+  fly auth login
+  fly config contexts add acme --org acme --region iad
+  fly config contexts use acme
+
+Commands use the context in use, which the --context flag or FLY_CONTEXT
+override for one command. Flags and environment variables, such as
+--access-token or FLY_ORG, still take precedence over the settings of the
+context. 'fly auth login' stores the token it obtains in the context in use.
+
+Contexts are stored in the configuration file.
+`
+		short = "Manage named contexts"
+	)
+
+	cmd = command.New("contexts", short, long, nil)
+
+	cmd.Aliases = []string{"context"}
+
+	cmd.AddCommand(
+		newAdd(),
+		newList(),
+		newUse(),
+		newCurrent(),
+		newRemove(),
+	)
+
+	return
+}
+
+func newAdd() *cobra.Command {
+	const (
+		long = `Add a context of the given name, replacing any context of the same name. The
+context stores the access token --token denotes or, absent it, the current one.
+`
+		short = "Add a context"
+	)
+
+	cmd := command.New("add <name>", short, long, runAdd)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.Org(),
+		flag.Region(),
+		flag.String{
+			Name:        "token",
+			Description: "Access token of the context; defaults to the current one",
+		},
+		flag.String{
+			Name:        "api-base-url",
+			Description: "Base URL of the API, if not the default one",
+		},
+		flag.Bool{
+			Name:        "use",
+			Description: "Use the context once added",
+		},
+	)
+
+	cmd.Example = `fly config contexts add acme --org acme --region iad --use
+fly config contexts add staging --token "$STAGING_TOKEN" --api-base-url https://api.staging.example.com`
+
+	return cmd
+}
+
+func runAdd(ctx context.Context) error {
+	c := config.Context{
+		Name:        flag.FirstArg(ctx),
+		AccessToken: flag.GetString(ctx, "token"),
+		Org:         flag.GetOrg(ctx),
+		Region:      flag.GetRegion(ctx),
+		APIBaseURL:  flag.GetString(ctx, "api-base-url"),
+	}
+
+	if c.AccessToken == "" {
+		c.AccessToken = config.FromContext(ctx).AccessToken
+	}
+	if c.AccessToken == "" {
+		return errors.New("no access token to store; log in with 'fly auth login' or specify --token")
+	}
+
+	path := state.ConfigFile(ctx)
+	if err := config.SetContext(path, c); err != nil {
+		return fmt.Errorf("failed saving context: %w", err)
+	}
+
+	io := iostreams.FromContext(ctx)
+	fmt.Fprintf(io.Out, "Added context %s\n", c.Name)
+
+	if !flag.GetBool(ctx, "use") {
+		return nil
+	}
+
+	return use(ctx, c.Name)
+}
+
+func newList() *cobra.Command {
+	const (
+		long  = "List the contexts of the configuration file, marking the one in use."
+		short = "List contexts"
+	)
+
+	cmd := command.New("list", short, long, runList)
+
+	cmd.Args = cobra.NoArgs
+	cmd.Aliases = []string{"ls"}
+
+	return cmd
+}
+
+// listedContext is the structured output of list, which leaves out access
+// tokens.
+type listedContext struct {
+	Name       string `json:"name"`
+	Current    bool   `json:"current"`
+	Org        string `json:"org,omitempty"`
+	Region     string `json:"region,omitempty"`
+	APIBaseURL string `json:"api_base_url,omitempty"`
+}
+
+func runList(ctx context.Context) error {
+	contexts, current, err := config.Contexts(state.ConfigFile(ctx))
+	if err != nil {
+		return fmt.Errorf("failed reading contexts: %w", err)
+	}
+
+	listed := make([]listedContext, 0, len(contexts))
+	for _, c := range contexts {
+		listed = append(listed, listedContext{
+			Name:       c.Name,
+			Current:    c.Name == current,
+			Org:        c.Org,
+			Region:     c.Region,
+			APIBaseURL: c.APIBaseURL,
+		})
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, out, listed)
+	}
+
+	rows := make([][]string, 0, len(listed))
+	for _, c := range listed {
+		var marker string
+		if c.Current {
+			marker = "*"
+		}
+
+		rows = append(rows, []string{marker, c.Name, c.Org, c.Region, c.APIBaseURL})
+	}
+
+	return render.Table(out, "", rows, "", "Name", "Organization", "Region", "API Base URL")
+}
+
+func newUse() *cobra.Command {
+	const (
+		long = `Use the named context for the commands which follow. With --none, commands
+use the top-level access token of the configuration file again.
+`
+		short = "Use a context"
+	)
+
+	cmd := command.New("use [name]", short, long, runUse)
+
+	cmd.Args = cobra.MaximumNArgs(1)
+
+	flag.Add(cmd,
+		flag.Bool{
+			Name:        "none",
+			Description: "Stop using contexts",
+		},
+	)
+
+	return cmd
+}
+
+func runUse(ctx context.Context) error {
+	name := flag.FirstArg(ctx)
+
+	switch none := flag.GetBool(ctx, "none"); {
+	case none && name != "":
+		return errors.New("a context name and --none are mutually exclusive")
+	case !none && name == "":
+		return errors.New("specify the name of a context, or --none")
+	}
+
+	return use(ctx, name)
+}
+
+func use(ctx context.Context, name string) error {
+	if err := config.UseContext(state.ConfigFile(ctx), name); err != nil {
+		return fmt.Errorf("failed switching contexts: %w", err)
+	}
+
+	io := iostreams.FromContext(ctx)
+	if name == "" {
+		fmt.Fprintln(io.Out, "Stopped using contexts")
+	} else {
+		fmt.Fprintf(io.Out, "Using context %s\n", name)
+	}
+
+	return nil
+}
+
+func newCurrent() *cobra.Command {
+	const (
+		long = `Print the name of the context commands use, which --context or FLY_CONTEXT
+override.
+`
+		short = "Print the current context"
+	)
+
+	cmd := command.New("current", short, long, runCurrent)
+
+	cmd.Args = cobra.NoArgs
+
+	return cmd
+}
+
+func runCurrent(ctx context.Context) error {
+	name := config.FromContext(ctx).Context
+	if name == "" {
+		return errors.New("no context is in use")
+	}
+
+	fmt.Fprintln(iostreams.FromContext(ctx).Out, name)
+
+	return nil
+}
+
+func newRemove() *cobra.Command {
+	const (
+		long = `Remove the named context. Removing the context in use stops using contexts.
+`
+		short = "Remove a context"
+	)
+
+	cmd := command.New("remove <name>", short, long, runRemove)
+
+	cmd.Args = cobra.ExactArgs(1)
+	cmd.Aliases = []string{"rm"}
+
+	return cmd
+}
+
+func runRemove(ctx context.Context) error {
+	name := flag.FirstArg(ctx)
+
+	if err := config.RemoveContext(state.ConfigFile(ctx), name); err != nil {
+		return fmt.Errorf("failed removing context: %w", err)
+	}
+
+	fmt.Fprintf(iostreams.FromContext(ctx).Out, "Removed context %s\n", name)
+
+	return nil
+}
@@ -0,0 +1,158 @@
+// Package costs implements the costs command.
+package costs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/prompt"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/cmdutil"
+)
+
+// New initializes and returns a new costs Command.
+func New() *cobra.Command {
+	const (
+		long = `Show what an organization has spent in the current billing period so far,
+against the thresholds of its spend alerts; see 'fly orgs billing alerts'.
+`
+		short = "Show the current spend of an organization"
+	)
+
+	cmd := command.New("costs", short, long, run,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.Org(),
+	)
+
+	return cmd
+}
+
+// Report is the structured output of costs.
+type Report struct {
+	Org         string        `json:"org"`
+	SpentCents  int           `json:"spent_cents"`
+	PeriodStart time.Time     `json:"period_start"`
+	PeriodEnd   time.Time     `json:"period_end"`
+	Alerts      []AlertStatus `json:"alerts"`
+}
+
+// AlertStatus denotes how close the spend of an organization is to the
+// threshold of one of its alerts.
+type AlertStatus struct {
+	ThresholdCents int        `json:"threshold_cents"`
+	RemainingCents int        `json:"remaining_cents"`
+	Percent        int        `json:"percent"`
+	Channels       []string   `json:"channels"`
+	TriggeredAt    *time.Time `json:"triggered_at,omitempty"`
+}
+
+func run(ctx context.Context) error {
+	org, err := prompt.Org(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	spend, alerts, err := client.FromContext(ctx).API().GetOrganizationSpend(ctx, org.Slug)
+	if err != nil {
+		return fmt.Errorf("failed retrieving the spend of %s: %w", org.Slug, err)
+	} else if spend == nil {
+		return fmt.Errorf("organization %s has no billing period in progress", org.Slug)
+	}
+
+	report := newReport(org.Slug, spend, alerts)
+
+	io := iostreams.FromContext(ctx)
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, io.Out, report)
+	}
+
+	fmt.Fprintf(io.Out, "%s spent %s from %s to date (period ends %s)\n", report.Org,
+		cmdutil.FormatCents(report.SpentCents),
+		report.PeriodStart.Format("Jan 2"),
+		report.PeriodEnd.Format("Jan 2"))
+
+	if len(report.Alerts) == 0 {
+		fmt.Fprintf(io.Out, "\nNo spend alerts; add one with 'fly orgs billing alerts add %s --threshold <dollars>'\n", report.Org)
+
+		return nil
+	}
+
+	fmt.Fprintln(io.Out)
+
+	return render.Table(io.Out, "Spend alerts", alertRows(report.Alerts), "Threshold", "Spent", "Channels", "Status")
+}
+
+func newReport(org string, spend *api.OrganizationSpend, alerts []api.SpendAlert) *Report {
+	report := &Report{
+		Org:         org,
+		SpentCents:  spend.AmountCents,
+		PeriodStart: spend.PeriodStart,
+		PeriodEnd:   spend.PeriodEnd,
+		Alerts:      make([]AlertStatus, 0, len(alerts)),
+	}
+
+	for _, alert := range alerts {
+		status := AlertStatus{
+			ThresholdCents: alert.ThresholdCents,
+			Channels:       alert.HandlerNames,
+			TriggeredAt:    alert.TriggeredAt,
+		}
+
+		if alert.ThresholdCents > 0 {
+			status.Percent = spend.AmountCents * 100 / alert.ThresholdCents
+		}
+		if remaining := alert.ThresholdCents - spend.AmountCents; remaining > 0 {
+			status.RemainingCents = remaining
+		}
+
+		report.Alerts = append(report.Alerts, status)
+	}
+
+	sort.Slice(report.Alerts, func(i, j int) bool {
+		return report.Alerts[i].ThresholdCents < report.Alerts[j].ThresholdCents
+	})
+
+	return report
+}
+
+func alertRows(alerts []AlertStatus) [][]string {
+	rows := make([][]string, 0, len(alerts))
+
+	for _, alert := range alerts {
+		var status string
+		switch {
+		case alert.TriggeredAt != nil:
+			status = "triggered " + alert.TriggeredAt.Format("Jan 2")
+		case alert.RemainingCents == 0:
+			status = "crossed"
+		default:
+			status = fmt.Sprintf("%s left", cmdutil.FormatCents(alert.RemainingCents))
+		}
+
+		rows = append(rows, []string{
+			cmdutil.FormatCents(alert.ThresholdCents),
+			fmt.Sprintf("%d%%", alert.Percent),
+			strings.Join(alert.Channels, ", "),
+			status,
+		})
+	}
+
+	return rows
+}
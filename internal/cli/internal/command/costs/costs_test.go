@@ -0,0 +1,35 @@
+package costs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestNewReport(t *testing.T) {
+	triggered := time.Date(2022, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	spend := &api.OrganizationSpend{AmountCents: 15000}
+	alerts := []api.SpendAlert{
+		{ThresholdCents: 25000, HandlerNames: []string{"ops"}},
+		{ThresholdCents: 10000, HandlerNames: []string{"ops", "pd"}, TriggeredAt: &triggered},
+		{ThresholdCents: 12000},
+	}
+
+	report := newReport("acme", spend, alerts)
+
+	assert.Equal(t, []AlertStatus{
+		{ThresholdCents: 10000, Percent: 150, Channels: []string{"ops", "pd"}, TriggeredAt: &triggered},
+		{ThresholdCents: 12000, Percent: 125},
+		{ThresholdCents: 25000, RemainingCents: 10000, Percent: 60, Channels: []string{"ops"}},
+	}, report.Alerts)
+
+	assert.Equal(t, [][]string{
+		{"$100.00", "150%", "ops, pd", "triggered Mar 10"},
+		{"$120.00", "125%", "", "crossed"},
+		{"$250.00", "60%", "ops", "$100.00 left"},
+	}, alertRows(report.Alerts))
+}
@@ -0,0 +1,234 @@
+// Package cron implements the cron command chain.
+package cron
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+	"github.com/superfly/flyctl/pkg/machines"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/prompt"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/flyerr"
+)
+
+// New initializes and returns a new cron Command.
+func New() (cmd *cobra.Command) {
+	const (
+		long = `Commands that manage the scheduled machines of an app, which run recurring
+jobs. Machines are scheduled via the --schedule flag of 'flyctl machine run'.
+`
+		short = "Manage scheduled machines"
+	)
+
+	cmd = command.New("cron", short, long, nil)
+
+	cmd.AddCommand(
+		newList(),
+		newDelete(),
+	)
+
+	return
+}
+
+func newList() (cmd *cobra.Command) {
+	const (
+		long = `List the scheduled machines of an app, along with the time of their next
+run and the outcome of their last one.
+`
+		short = "List scheduled machines"
+	)
+
+	cmd = command.New("list", short, long, runList,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Aliases = []string{"ls"}
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return
+}
+
+// job wraps the status of a scheduled machine.
+type job struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Region   string            `json:"region"`
+	State    string            `json:"state"`
+	Schedule string            `json:"schedule"`
+	NextRun  *time.Time        `json:"next_run,omitempty"`
+	LastRun  *machines.LastRun `json:"last_run,omitempty"`
+}
+
+func runList(ctx context.Context) error {
+	var (
+		appName = app.NameFromContext(ctx)
+		client  = client.FromContext(ctx).API()
+	)
+
+	all, err := client.ListMachines(ctx, appName, "")
+	if err != nil {
+		return fmt.Errorf("failed listing machines: %w", err)
+	}
+
+	jobs := []*job{}
+	for _, m := range all {
+		if m.Config.Schedule == "" || m.State == "destroyed" || m.State == "destroying" {
+			continue
+		}
+
+		// listed machines don't carry their events
+		machine, err := client.GetMachine(ctx, appName, m.ID)
+		if err != nil {
+			return fmt.Errorf("failed retrieving machine %s: %w", m.ID, err)
+		}
+
+		jobs = append(jobs, newJob(machine))
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, jobs)
+	}
+
+	rows := make([][]string, 0, len(jobs))
+	for _, j := range jobs {
+		next, last, status := "-", "-", "never run"
+
+		if j.NextRun != nil {
+			next = j.NextRun.Format(time.RFC3339)
+		}
+
+		if r := j.LastRun; r != nil {
+			last = r.At.Format(time.RFC3339)
+
+			if r.Succeeded() {
+				status = "succeeded"
+			} else {
+				status = fmt.Sprintf("failed (exit code %d)", r.ExitCode)
+			}
+		}
+
+		if j.State == "started" {
+			status = "running"
+		}
+
+		rows = append(rows, []string{
+			j.ID,
+			j.Name,
+			j.Region,
+			j.Schedule,
+			next,
+			last,
+			status,
+		})
+	}
+
+	return render.Table(out, "", rows, "ID", "Name", "Region", "Schedule", "Next Run", "Last Run", "Status")
+}
+
+func newJob(m *api.Machine) *job {
+	j := &job{
+		ID:       m.ID,
+		Name:     m.Name,
+		Region:   m.Region,
+		State:    m.State,
+		Schedule: m.Config.Schedule,
+		LastRun:  machines.LastRunOf(m),
+	}
+
+	// schedules are validated upon scheduling; ones which fail to parse must
+	// have been set otherwise, and their next run is unknown
+	if s, err := machines.ParseSchedule(m.Config.Schedule); err == nil {
+		if next := s.Next(time.Now()); !next.IsZero() {
+			j.NextRun = &next
+		}
+	}
+
+	return j
+}
+
+func newDelete() (cmd *cobra.Command) {
+	const (
+		long = `Delete the scheduled machine of the given ID, so that its job no longer runs.
+`
+		short = "Delete a scheduled machine"
+	)
+
+	cmd = command.New("delete <id>", short, long, runDelete,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+	cmd.Aliases = []string{"rm"}
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Yes(),
+	)
+
+	return
+}
+
+func runDelete(ctx context.Context) error {
+	var (
+		appName = app.NameFromContext(ctx)
+		client  = client.FromContext(ctx).API()
+		id      = flag.FirstArg(ctx)
+	)
+
+	machine, err := client.GetMachine(ctx, appName, id)
+	if err != nil {
+		return fmt.Errorf("failed retrieving machine %s: %w", id, err)
+	}
+
+	if machine.Config.Schedule == "" {
+		return &flyerr.NotFoundError{
+			Err: fmt.Errorf("machine %s is not scheduled; see 'flyctl cron list'", id),
+		}
+	}
+
+	if !flag.GetYes(ctx) {
+		switch confirmed, err := prompt.Confirmf(ctx, "Delete machine %s, which runs on the schedule %s?", id, machine.Config.Schedule); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	input := api.RemoveMachineInput{
+		AppID: appName,
+		ID:    id,
+		Kill:  true,
+	}
+
+	if _, err := client.RemoveMachine(ctx, input); err != nil {
+		return fmt.Errorf("failed deleting machine %s: %w", id, err)
+	}
+
+	fmt.Fprintf(iostreams.FromContext(ctx).Out, "Deleted scheduled machine %s\n", id)
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/superfly/flyctl/internal/autoscale"
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+// applyAutoscale applies the autoscaling settings the app config declares.
+func applyAutoscale(ctx context.Context, cfg *autoscale.Config) error {
+	tb := render.NewTextBlock(ctx, "Applying autoscaling settings")
+
+	input := cfg.Input(app.NameFromContext(ctx))
+	if _, err := client.FromContext(ctx).API().UpdateAutoscaleConfig(ctx, input); err != nil {
+		return fmt.Errorf("failed applying autoscaling settings: %w", err)
+	}
+
+	tb.Donef("Applied autoscaling settings: %s", cfg)
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package deploy
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cmdutil"
+)
+
+// loadBuildArgsFile reads the build args the JSON or TOML file at the given
+// path defines, expanding any ${NAME} references their values contain from the
+// environment.
+func loadBuildArgsFile(path string) (map[string]string, error) {
+	var raw map[string]interface{}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading build arg file: %w", err)
+		}
+		defer f.Close()
+
+		// numbers are kept as written rather than turned into floats, which
+		// print large ones in exponent notation
+		dec := json.NewDecoder(f)
+		dec.UseNumber()
+
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("failed parsing build arg file %s: %w", path, err)
+		}
+	case ".toml":
+		if _, err := toml.DecodeFile(path, &raw); err != nil {
+			return nil, fmt.Errorf("failed parsing build arg file %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported build arg file format %q; use .json or .toml", ext)
+	}
+
+	args := make(map[string]string, len(raw))
+	undefined := map[string]struct{}{}
+
+	for k, v := range raw {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}, []map[string]interface{}:
+			return nil, fmt.Errorf("build arg %s in %s must be a scalar value", k, path)
+		}
+
+		value, missing := app.ExpandVars(fmt.Sprint(v), os.LookupEnv)
+		for _, name := range missing {
+			undefined[name] = struct{}{}
+		}
+
+		args[k] = value
+	}
+
+	if len(undefined) > 0 {
+		names := make([]string, 0, len(undefined))
+		for name := range undefined {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return nil, fmt.Errorf("build arg file %s references undefined environment variables: %s", path, strings.Join(names, ", "))
+	}
+
+	return args, nil
+}
+
+// dockerfileArg wraps an ARG declaration of a Dockerfile.
+type dockerfileArg struct {
+	Name       string
+	HasDefault bool
+}
+
+var argInstructionPattern = regexp.MustCompile(`(?i)^\s*ARG\s+(.+)$`)
+
+// parseDockerfileArgs returns the ARG declarations r contains.
+func parseDockerfileArgs(r io.Reader) ([]dockerfileArg, error) {
+	var (
+		args    []dockerfileArg
+		scanner = bufio.NewScanner(r)
+		line    string
+	)
+
+	for scanner.Scan() {
+		// join continued lines
+		line += scanner.Text()
+		if strings.HasSuffix(line, "\\") {
+			line = strings.TrimSuffix(line, "\\")
+
+			continue
+		}
+
+		if m := argInstructionPattern.FindStringSubmatch(line); m != nil {
+			for _, decl := range strings.Fields(m[1]) {
+				name, _, hasDefault := cmdutil.Cut(decl, "=")
+
+				args = append(args, dockerfileArg{
+					Name:       name,
+					HasDefault: hasDefault,
+				})
+			}
+		}
+
+		line = ""
+	}
+
+	return args, scanner.Err()
+}
+
+// predefinedBuildArgs denotes the build args Docker accepts without a
+// corresponding ARG declaration.
+var predefinedBuildArgs = map[string]struct{}{
+	"HTTP_PROXY":  {},
+	"http_proxy":  {},
+	"HTTPS_PROXY": {},
+	"https_proxy": {},
+	"FTP_PROXY":   {},
+	"ftp_proxy":   {},
+	"NO_PROXY":    {},
+	"no_proxy":    {},
+	"ALL_PROXY":   {},
+	"all_proxy":   {},
+}
+
+// automaticBuildArgs denotes the platform args BuildKit sets on its own, which
+// Dockerfiles declare without a default.
+var automaticBuildArgs = map[string]struct{}{
+	"TARGETPLATFORM": {},
+	"TARGETOS":       {},
+	"TARGETARCH":     {},
+	"TARGETVARIANT":  {},
+	"BUILDPLATFORM":  {},
+	"BUILDOS":        {},
+	"BUILDARCH":      {},
+	"BUILDVARIANT":   {},
+}
+
+// validateBuildArgs returns warnings about the build args which the Dockerfile
+// at the given path doesn't declare and the ARGs it declares without a default
+// which args don't set. It returns no warnings in case there's no Dockerfile.
+func validateBuildArgs(dockerfilePath string, args map[string]string) ([]string, error) {
+	f, err := os.Open(dockerfilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	declared, err := parseDockerfileArgs(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing %s: %w", dockerfilePath, err)
+	}
+
+	return buildArgWarnings(declared, args), nil
+}
+
+func buildArgWarnings(declared []dockerfileArg, args map[string]string) (warnings []string) {
+	// ARGs may be declared once per stage; any default will do
+	hasDefault := make(map[string]bool, len(declared))
+	for _, arg := range declared {
+		hasDefault[arg.Name] = hasDefault[arg.Name] || arg.HasDefault
+	}
+
+	var unused, missing []string
+	for name := range args {
+		_, isDeclared := hasDefault[name]
+		_, isPredefined := predefinedBuildArgs[name]
+
+		if !isDeclared && !isPredefined {
+			unused = append(unused, name)
+		}
+	}
+	for name, ok := range hasDefault {
+		_, isSet := args[name]
+		_, isAutomatic := automaticBuildArgs[name]
+		_, isPredefined := predefinedBuildArgs[name]
+
+		if !ok && !isSet && !isAutomatic && !isPredefined {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(unused)
+	sort.Strings(missing)
+
+	for _, name := range unused {
+		warnings = append(warnings, fmt.Sprintf("build arg %s is not declared in the Dockerfile and will be ignored", name))
+	}
+	for _, name := range missing {
+		warnings = append(warnings, fmt.Sprintf("Dockerfile ARG %s has no default and no build arg sets it", name))
+	}
+
+	return
+}
@@ -0,0 +1,78 @@
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadBuildArgsFile(t *testing.T) {
+	os.Setenv("BUILD_ARGS_TEST_VERSION", "v1")
+	defer os.Unsetenv("BUILD_ARGS_TEST_VERSION")
+
+	args, err := loadBuildArgsFile("./testdata/build-args.toml")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"VERSION":    "v1",
+		"PORT":       "8080",
+		"PRICE":      "$5",
+		"UNDECLARED": "x",
+	}, args)
+}
+
+func TestLoadBuildArgsFileWithUndefinedVars(t *testing.T) {
+	os.Unsetenv("BUILD_ARGS_TEST_VERSION")
+
+	_, err := loadBuildArgsFile("./testdata/build-args.toml")
+	assert.EqualError(t, err, "build arg file ./testdata/build-args.toml references undefined environment variables: BUILD_ARGS_TEST_VERSION")
+}
+
+func TestLoadBuildArgsFileKeepsNumbers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "build-args.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"BUILD_ID": 12345678901234567890, "RATIO": 1.50, "DEBUG": true}`), 0o600))
+
+	args, err := loadBuildArgsFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"BUILD_ID": "12345678901234567890",
+		"RATIO":    "1.50",
+		"DEBUG":    "true",
+	}, args)
+}
+
+func TestValidateBuildArgs(t *testing.T) {
+	warnings, err := validateBuildArgs("./testdata/Dockerfile", map[string]string{
+		"VERSION":     "v1",
+		"UNDECLARED":  "x",
+		"HTTP_PROXY":  "http://proxy",
+		"TARGET":      "prod",
+		"REGISTRY":    "ghcr.io",
+		"ANOTHER_ONE": "y",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"build arg ANOTHER_ONE is not declared in the Dockerfile and will be ignored",
+		"build arg UNDECLARED is not declared in the Dockerfile and will be ignored",
+		"Dockerfile ARG MIRROR has no default and no build arg sets it",
+	}, warnings)
+
+	warnings, err = validateBuildArgs("./testdata/missing", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+func TestBuildArgWarningsSkipAutomaticArgs(t *testing.T) {
+	warnings := buildArgWarnings([]dockerfileArg{
+		{Name: "TARGETPLATFORM"},
+		{Name: "BUILDARCH"},
+		{Name: "HTTP_PROXY"},
+		{Name: "MIRROR"},
+	}, nil)
+
+	assert.Equal(t, []string{
+		"Dockerfile ARG MIRROR has no default and no build arg sets it",
+	}, warnings)
+}
@@ -4,41 +4,186 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/superfly/flyctl/flyctl"
 	"github.com/superfly/flyctl/pkg/builder"
 	"github.com/superfly/flyctl/pkg/iostreams"
 	"github.com/superfly/flyctl/pkg/machines"
 	"github.com/superfly/flyctl/pkg/proxy"
-	"github.com/superfly/flyctl/pkg/retry"
 
 	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/autoscale"
 	"github.com/superfly/flyctl/internal/build/imgsrc"
 	"github.com/superfly/flyctl/internal/cli/internal/app"
 	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/command/builders"
+	"github.com/superfly/flyctl/internal/cli/internal/command/ci"
+	"github.com/superfly/flyctl/internal/cli/internal/command/load"
 	"github.com/superfly/flyctl/internal/cli/internal/command/ssh"
 	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/prompt"
 	"github.com/superfly/flyctl/internal/cli/internal/render"
 	"github.com/superfly/flyctl/internal/cli/internal/state"
 
 	"github.com/superfly/flyctl/internal/cli/internal/watch"
 	"github.com/superfly/flyctl/internal/client"
 	"github.com/superfly/flyctl/internal/cmdutil"
+	"github.com/superfly/flyctl/internal/configcrypt"
+	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/internal/logger"
+	"github.com/superfly/flyctl/internal/processgroup"
+	"github.com/superfly/flyctl/internal/secrets"
+	"github.com/superfly/flyctl/internal/secrets/provider"
+	"github.com/superfly/flyctl/internal/tracing"
 	"github.com/superfly/flyctl/pkg/agent"
 )
 
 func New() (cmd *cobra.Command) {
 	const (
 		long = `Deploy Fly applications from source or an image using a local or remote builder.
+
+Failed deployments exit with code 6, or with 127 when cancelled. With
+--error-json, the error printed on stderr tells the phase the deployment
+failed at via its reason:
+
+  build_failed               the image failed to build or could not be resolved
+  release_command_failed     the release command failed
+  health_checks_failed       the instances of the release failed to become healthy
+  load_test_failed           the load test the deployment is gated on failed
+  requirements_unmet         requirements the app config declares are not met
+  runtime_assertions_failed  new instances failed the --verify-runtime checks
+
+Deployment events (build_started, build_finished, release_created,
+deploy_succeeded, deploy_failed and rollback) may be POSTed to webhooks set via
+--notify-url or the notify_url setting of the [deploy] section of the app
+config. Payloads are JSON objects, or messages fit for Slack or Discord
+incoming webhooks as the webhook URL or --notify-format denote.
+
+Apps which declare process groups may deploy some of them with --only-process,
+e.g. --only-process web, to hotfix one tier without cycling the rest; the
+other groups keep running their current release, and only the instances of the
+deployed groups are monitored.
+
+With --notify, or once enabled via 'fly settings notifications on', a desktop
+notification is sent when the build finishes and once the deployment succeeds
+or fails.
+
+With --report, e.g. --report junit=deploy.xml, a JUnit or TAP test report of
+the deployment is written once it ends, whatever its outcome, for CI systems
+which only display test reports. Each phase of the deployment is a test case,
+as is the health of each instance, or each health check of it, once the
+deployment has been monitored.
+
+Setting FLY_OTEL_EXPORTER to otlp exports OpenTelemetry traces of the
+configuration, build, push, release and monitoring phases of deployments to
+the collector OTEL_EXPORTER_OTLP_ENDPOINT denotes; setting it to stderr writes
+them to the standard error stream instead.
+
+Deployments may be gated on prerequisites via the require setting of the
+[deploy] section of the app config. Requirements are checked before the image
+is built, and the deployment fails right away unless all of them are met;
+--skip-requirements skips the checks:
+
+  [deploy]
+    require = [
+      "postgres:my-db",         # app my-db is deployed and healthy
+      "app:my-api",             # likewise, for any app
+      "secret:STRIPE_KEY",      # secret STRIPE_KEY is set
+      "cert:www.example.com",   # certificate for www.example.com is issued
+    ]
+
+With --verify-runtime, the new instances are verified against the runtime
+assertions the verify setting of the [deploy] section declares once they're
+healthy, by running checks on each of them: over SSH for releases, through the
+Machines API for the started machines which run the deployed image. Assertions
+which fail are retried for 30 seconds before the deployment fails; it isn't
+rolled back:
+
+  [deploy]
+    verify = [
+      "port",                   # the internal ports of the services listen
+      "port:9091",              # so does port 9091
+      "env:DATABASE_URL",       # DATABASE_URL is set
+      "mount:/data",            # a volume is mounted at /data
+    ]
+
+Values of the app config encrypted via 'fly config lock' are decrypted before
+the config is sent to the platform, which requires an identity of one of the
+recipients of the [encryption] section; see 'fly config unlock'.
+
+Remote builds which produce no output for --build-stall-timeout minutes are
+cancelled as stuck, after which flyctl offers to restart the remote builder and
+retry the build; --auto-recover-builder does so without prompting.
+
+--upload-limit caps the rate at which the build context is sent to the builder,
+e.g. 5MB/s, leaving bandwidth for everything else on constrained connections.
+Pushes of the image which the network interrupts are retried; the registry
+keeps the layers which were pushed in full, so retries only push the rest.
+
+Remote builds of apps whose organization has a self-hosted builder registered
+run on it; see 'fly builders'. Unhealthy self-hosted builders fail over to the
+Fly-managed one unless registered with --no-failover. Remote builds wait for
+their turn, reporting their queue position, when as many builds run on their
+builder as its settings allow; see 'fly builders settings'.
+
+When the instances of a release fail to become healthy in an interactive
+session, flyctl offers a triage menu: view the logs of the failing instances,
+open a shell on one, retry with the immediate strategy, roll back to the last
+stable release, or open a community post pre-filled with the diagnostics of the
+deployment. --no-triage, JSON output and CI environments skip it.
+
+Prebuilt static sites deploy without a Dockerfile when the [build] section
+names their directory. Files are served on port 8080, so internal_port of the
+app's service should be 8080:
+
+  [build]
+    static = "dist/"
+    static_fallback = "/index.html"  # serve index.html for unknown paths (SPAs)
+
+    [build.static_cache]
+      "/assets/*" = "public, max-age=31536000, immutable"
+
+With --env-name, or FLY_ENV_NAME, the overrides of the named environment are
+merged onto the app config before deploying: those of its [environments.<name>]
+section first, then those of the fly.<name>.toml overlay next to the config
+file. Only deploy applies environments; other commands use the config file as
+written.
+
+String values of the app config may reference variables as ${NAME}, which
+deploy resolves from --var, falling back to the environment; $$ escapes a
+literal $. Undefined variables expand to empty strings with a warning, or fail
+the deployment with --strict-vars. Other commands don't interpolate.
+
+Apps which run on machines are deployed by updating the image and env of each
+of their machines instead of via a release, with the rolling or immediate
+strategies only. Unless --strategy is immediate, machines are updated one at a
+time; each has --wait-timeout seconds to start, stay started and pass its
+health checks before the next one is updated.
+
+With --at, the image is built or resolved right away and deployed at the given
+time by a background flyctl process, which requires this machine to stay on
+until then. See 'fly deploys scheduled' to list and cancel such deployments.
+
+With --load-test-rps, the deployment is load tested once it finishes, as
+'fly load run' would, and fails when --load-test-max-p99 or
+--load-test-max-error-rate is exceeded. Failed load tests don't roll the
+deployment back. Bluegreen deployments can't be load tested: the platform moves
+traffic to the green instances as soon as they pass their health checks, so
+there's no point before cutover to test them at, and --load-test-rps is
+rejected for them.
+
+Behaviors which are still experimental may be enabled for a single deployment
+via --experiments; see 'fly settings experiments'.
 	`
 		short = "Deploy Fly applications"
 	)
@@ -50,10 +195,18 @@ func New() (cmd *cobra.Command) {
 	)
 
 	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.Example = `flyctl deploy -a $APP
+flyctl deploy --image registry.fly.io/$APP:deployment-123 -a $APP
+flyctl deploy --remote-only --strategy rolling -a $APP
+flyctl deploy --at 2024-01-01T02:00Z -a $APP
+flyctl deploy --report junit=deploy.xml --report tap=deploy.tap -a $APP`
 
 	flag.Add(cmd,
 		flag.App(),
 		flag.AppConfig(),
+		flag.Environment(),
+		flag.Var(),
+		flag.StrictVars(),
 		flag.Region(),
 		flag.Image(),
 		flag.Now(),
@@ -82,6 +235,10 @@ func New() (cmd *cobra.Command) {
 			Name:        "build-arg",
 			Description: "Set of build time variables in the form of NAME=VALUE pairs. Can be specified multiple times.",
 		},
+		flag.String{
+			Name:        "build-arg-file",
+			Description: "Path to a JSON or TOML file of build time variables. Values may reference environment variables as ${NAME}.",
+		},
 		flag.String{
 			Name:        "build-target",
 			Description: "Set the target build stage to build if the Dockerfile has more than one stage",
@@ -90,37 +247,289 @@ func New() (cmd *cobra.Command) {
 			Name:        "no-cache",
 			Description: "Do not use the build cache when building the image",
 		},
+		flag.StringArray{
+			Name:        "ssh",
+			Description: "SSH agent socket or keys to expose to the build, in the form of default|<id>[=<socket>|<key>[,<key>]]. Can be specified multiple times.",
+		},
+		flag.Bool{
+			Name:        "bundle-install",
+			Description: "Mount the download caches of package managers, which persist across builds of the app on the builder, into the RUN instructions of the Dockerfile",
+		},
+		flag.StringSlice{
+			Name:        "cache-path",
+			Description: "Path of a package manager download cache to mount with --bundle-install, overriding the cache_paths of the [build] section. Defaults to ~/.cache, ~/.npm and ~/.bundle/cache. Install targets such as node_modules are rejected, since mounts aren't part of the image. Can be specified multiple times.",
+		},
+		flag.Int{
+			Name:        "build-stall-timeout",
+			Description: "Minutes a remote build may go without output before it's cancelled as stuck. 0 disables the check.",
+			Default:     defaultBuildStallTimeout,
+		},
+		flag.Bool{
+			Name:        "auto-recover-builder",
+			Description: "Restart the remote builder and retry the build once in case the build gets stuck, without prompting",
+		},
+		flag.String{
+			Name:        "upload-limit",
+			Description: "Cap the rate at which the build context is sent to the builder, e.g. 5MB/s",
+		},
 		flag.Bool{
 			Name:        "nix",
 			Description: "Build with Nix on a remote builder",
 		},
+		flag.Bool{
+			Name:        "staged-secrets",
+			Description: "Apply the secrets staged via secrets set --stage as part of the release",
+			Default:     true,
+		},
+		flag.StringArray{
+			Name:        "secrets-from",
+			Description: "Secrets manager source to fetch secrets from and set as part of the release, as vault://<mount>/<path>, aws://<secret> or gcp://<project>/<secret>. Can be specified multiple times.",
+		},
+		flag.StringSlice{
+			Name:        "notify-url",
+			Description: "Webhook URL to POST deployment events to, overriding the notify_url of the [deploy] section. Can be specified multiple times.",
+		},
+		flag.Bool{
+			Name:        "notify",
+			Description: "Send a desktop notification when the build finishes and once the deployment succeeds or fails. Defaults to the desktop notifications setting.",
+		},
+		flag.String{
+			Name:        "notify-format",
+			Description: "Payload format of the deployment events, one of json, slack or discord. Detected from the webhook URL by default.",
+		},
+		flag.Bool{
+			Name:        "github-deployment",
+			Description: "Report the progress of the deployment as a GitHub deployment of GITHUB_SHA, using GITHUB_TOKEN",
+		},
+		flag.String{
+			Name:        "github-environment",
+			Description: "GitHub environment to report the deployment to with --github-deployment",
+			Default:     ci.DefaultGitHubEnvironment,
+		},
+		flag.Bool{
+			Name:        "show-diff",
+			Description: "Show the differences between the deployed and the new app config before creating the release",
+		},
+		flag.Int{
+			Name:        "wait-timeout",
+			Description: "Seconds to wait for each machine to start when deploying an app which runs on machines",
+			Default:     defaultMachineWaitTimeout,
+		},
+		flag.StringSlice{
+			Name:        "only-process",
+			Description: "Process group to deploy, leaving the rest running their current release. Can be specified multiple times.",
+		},
+		flag.String{
+			Name:        "at",
+			Description: "Build the image now and deploy it at the given time, such as 2024-01-01T02:00Z. Times without a zone are local.",
+		},
+		flag.Bool{
+			Name:        "skip-requirements",
+			Description: "Skip checking the requirements of the [deploy] section of the app config",
+		},
+		flag.Bool{
+			Name:        "no-triage",
+			Description: "Don't offer the triage menu when the deployment fails",
+		},
+		flag.Bool{
+			Name:        "verify-runtime",
+			Description: "Verify the new instances against the runtime assertions of the verify setting of the [deploy] section once they're healthy",
+		},
+		flag.StringSlice{
+			Name:        "report",
+			Description: "Write a test report of the phases of the deployment and the health of its instances, as <format>=<path> with a format of junit or tap, e.g. junit=deploy.xml. Can be specified multiple times.",
+		},
+		flag.Experiments(),
 	)
+	flag.Add(cmd, load.GateFlags()...)
 
 	return
 }
 
-func run(ctx context.Context) error {
+func run(ctx context.Context) (err error) {
+	if names := command.EnabledExperiments(ctx); len(names) > 0 {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("experiments", strings.Join(names, ",")))
+
+		fmt.Fprintf(iostreams.FromContext(ctx).ErrOut, "Experiments enabled: %s\n", strings.Join(names, ", "))
+	}
+
+	var at time.Time
+	if s := flag.GetString(ctx, "at"); s != "" {
+		if flag.GetBuildOnly(ctx) {
+			return errors.New("--at and --build-only are mutually exclusive")
+		}
+
+		if at, err = parseDeployTime(s, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	if err := load.ValidateGate(ctx); err != nil {
+		return err
+	} else if flag.GetDetach(ctx) && flag.GetInt(ctx, "load-test-rps") > 0 {
+		return errors.New("--load-test-rps and --detach are mutually exclusive")
+	} else if flag.GetDetach(ctx) && flag.GetBool(ctx, "verify-runtime") {
+		return errors.New("--verify-runtime and --detach are mutually exclusive")
+	}
+
+	report, err := newReporter(ctx)
+	if err != nil {
+		return err
+	}
+
+	var monitored bool
+	defer func() { report.finished(ctx, monitored) }()
+
+	endPhase := report.phase("config")
 	appConfig, err := determineAppConfig(ctx)
+	if err == nil {
+		err = validateOnlyProcess(ctx, appConfig)
+	}
+	if err == nil && flag.GetInt(ctx, "load-test-rps") > 0 && deployStrategy(ctx, appConfig) == "bluegreen" {
+		err = errLoadTestBluegreen
+	}
+
+	var assertions []runtimeAssertion
+	if err == nil && flag.GetBool(ctx, "verify-runtime") {
+		if assertions, err = runtimeAssertions(appConfig); err == nil && len(assertions) == 0 {
+			err = &flyerr.ValidationError{
+				Err: errors.New("--verify-runtime requires the verify setting of the [deploy] section of the app config"),
+			}
+		}
+	}
+	endPhase(err)
+	if err != nil {
+		return err
+	}
+
+	if !flag.GetBuildOnly(ctx) && !flag.GetBool(ctx, "skip-requirements") {
+		endPhase := report.phase("requirements")
+		err := checkRequirements(ctx, appConfig)
+		endPhase(err)
+		if err != nil {
+			return err
+		}
+	}
+
+	notifier, err := newNotifier(ctx, appConfig)
 	if err != nil {
 		return err
 	}
 
+	defer func() {
+		// the outcome of build only and detached deployments is not known
+		if err != nil || monitored {
+			notifier.finished(ctx, err)
+		}
+	}()
+
+	notifier.buildStarted(ctx)
+
 	// Fetch an image ref or build from source to get the final image reference to deploy
+	endPhase = report.phase("build")
 	img, err := determineImage(ctx, appConfig)
+	endPhase(err)
 
 	if err != nil {
-		return fmt.Errorf("failed to fetch an image or build from source: %w", err)
+		return &flyerr.BuildError{
+			Err: fmt.Errorf("failed to fetch an image or build from source: %w", err),
+		}
 	}
 
+	notifier.buildFinished(ctx, img.Tag)
+
 	if flag.GetBuildOnly(ctx) {
 		return nil
 	}
 
-	release, releaseCommand, err := createRelease(ctx, appConfig, img)
+	if !at.IsZero() {
+		return schedule(ctx, at, appConfig, img)
+	}
+
+	// the autoscale section stays local and is applied along with the release
+	scaling, err := autoscale.Take(appConfig.Definition)
+	if err != nil {
+		return err
+	}
+
+	// so do the counts and VM sizes of process groups, which only apply to
+	// the groups the deployment updates
+	groups, err := processgroup.Normalize(appConfig.Definition)
+	if err != nil {
+		return err
+	}
+	groups = selectProcessGroups(groups, flag.GetStringSlice(ctx, "only-process"))
+
+	if flag.GetBool(ctx, "show-diff") {
+		if err := showConfigDiff(ctx, appConfig); err != nil {
+			return err
+		}
+	}
+
+	machinesApp, err := isMachinesApp(ctx)
+	if err != nil {
+		return err
+	}
+
+	if machinesApp {
+		if len(flag.GetStringSlice(ctx, "only-process")) > 0 {
+			return errors.New("--only-process isn't supported for machines apps")
+		}
+
+		if scaling != nil {
+			render.NewTextBlock(ctx).Detailf("WARNING: the %s section of the app config doesn't apply to machines apps; skipped it", autoscale.Section)
+		}
+		if len(processgroup.Counts(groups)) > 0 || len(processgroup.Sized(groups)) > 0 {
+			render.NewTextBlock(ctx).Detailf("WARNING: the counts and VM sizes of process groups don't apply to machines apps; skipped them")
+		}
+
+		monitored = !flag.GetDetach(ctx)
+
+		endPhase := report.phase("machines")
+		machinesCtx, span := tracing.Start(ctx, "deploy.machines")
+		err = deployToMachines(machinesCtx, appConfig, img)
+		tracing.End(span, err)
+		endPhase(err)
+		if err != nil || !monitored {
+			return err
+		}
+
+		if err = verify(ctx, report, assertions, nil, img.Tag); err != nil {
+			return err
+		}
+
+		return loadTest(ctx, report)
+	}
+
+	endPhase = report.phase("release")
+	releaseCtx, span := tracing.Start(ctx, "deploy.release")
+	release, releaseCommand, err := createRelease(releaseCtx, appConfig, img)
+	tracing.End(span, err)
+	endPhase(err)
 	if err != nil {
 		return err
 	}
 
+	if len(processgroup.Counts(groups)) > 0 || len(processgroup.Sized(groups)) > 0 {
+		endPhase := report.phase("processes")
+		err := scaleProcessGroups(ctx, groups)
+		endPhase(err)
+		if err != nil {
+			return err
+		}
+	}
+
+	if scaling != nil {
+		endPhase := report.phase("autoscale")
+		err := applyAutoscale(ctx, scaling)
+		endPhase(err)
+		if err != nil {
+			return err
+		}
+	}
+
+	notifier.releaseCreated(ctx, release.Version, img.Tag)
+
 	if flag.GetDetach(ctx) {
 		return nil
 	}
@@ -135,7 +544,13 @@ func run(ctx context.Context) error {
 		tb := render.NewTextBlock(ctx, fmt.Sprintf("Release command detected: %s\n", releaseCommand.Command))
 		tb.Done("This release will not be available until the release command succeeds.")
 
-		if err := watch.ReleaseCommand(ctx, releaseCommand.ID); err != nil {
+		endPhase := report.phase("release_command")
+		releaseCommandCtx, span := tracing.Start(ctx, "deploy.release_command",
+			attribute.String("release_command.id", releaseCommand.ID))
+		err := watch.ReleaseCommand(releaseCommandCtx, releaseCommand.ID)
+		tracing.End(span, err)
+		endPhase(err)
+		if err != nil {
 			return err
 		}
 
@@ -146,20 +561,98 @@ func run(ctx context.Context) error {
 		}
 	}
 
+	monitored = true
+
 	if release.DeploymentStrategy == "IMMEDIATE" {
 		logger := logger.FromContext(ctx)
 		logger.Debug("immediate deployment strategy, nothing to monitor")
+		report.skip("monitor")
+
+		if err = verify(ctx, report, assertions, release, img.Tag); err != nil {
+			return err
+		}
+
+		return loadTest(ctx, report)
+	}
 
+	endPhase = report.phase("monitor")
+	watchCtx, span := tracing.Start(ctx, "deploy.watch",
+		attribute.Int("release.version", release.Version))
+	err = watch.Deployment(watchCtx, release.EvaluationID, flag.GetStringSlice(ctx, "only-process")...)
+	tracing.End(span, err)
+	endPhase(err)
+	if err != nil {
+		return triageFailure(ctx, appConfig, img.Tag, release, err)
+	}
+
+	if err = verify(ctx, report, assertions, release, img.Tag); err != nil {
+		return err
+	}
+
+	return loadTest(ctx, report)
+}
+
+// verify verifies the new instances against the given runtime assertions, if
+// any; see verifyRuntime.
+func verify(ctx context.Context, report *reporter, assertions []runtimeAssertion, release *api.Release, image string) error {
+	if len(assertions) == 0 {
 		return nil
 	}
 
-	err = watch.Deployment(ctx, release.EvaluationID)
+	endPhase := report.phase("verify")
+	err := verifyRuntime(ctx, assertions, release, image)
+	endPhase(err)
 
 	return err
 }
 
+var errLoadTestBluegreen = &flyerr.ValidationError{
+	Err: errors.New("--load-test-rps can't gate bluegreen deployments, which move traffic to the green instances as soon as they pass their health checks; use another strategy or load test a fork of the app"),
+}
+
+// deployStrategy returns the strategy the deployment uses in lower case: the
+// one of the strategy flag or, in its absence, the one of the [deploy] section
+// of the app config. It returns an empty string in case neither sets one.
+func deployStrategy(ctx context.Context, appConfig *app.Config) string {
+	if val := flag.GetString(ctx, "strategy"); val != "" {
+		return strings.ToLower(val)
+	}
+
+	return configStrategy(appConfig)
+}
+
+func configStrategy(appConfig *app.Config) string {
+	deploy, ok := appConfig.Definition["deploy"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	strategy, _ := deploy["strategy"].(string)
+
+	return strings.ToLower(strategy)
+}
+
+// loadTest runs the load test the deployment is gated on, if any.
+func loadTest(ctx context.Context, report *reporter) (err error) {
+	if flag.GetInt(ctx, "load-test-rps") <= 0 {
+		return nil
+	}
+
+	endPhase := report.phase("load_test")
+	ctx, span := tracing.Start(ctx, "deploy.load_test")
+	defer func() {
+		tracing.End(span, err)
+		endPhase(err)
+	}()
+
+	return load.Gate(ctx, app.NameFromContext(ctx))
+}
+
 // determineAppConfig fetches the app config from a local file, or in its absence, from the API
 func determineAppConfig(ctx context.Context) (cfg *app.Config, err error) {
+	ctx, span := tracing.Start(ctx, "deploy.config")
+	defer func() { tracing.End(span, err) }()
+
 	tb := render.NewTextBlock(ctx, "Verifying app config")
 	client := client.FromContext(ctx).API()
 
@@ -190,14 +683,85 @@ func determineAppConfig(ctx context.Context) (cfg *app.Config, err error) {
 		cfg.SetEnvVariables(parsedEnv)
 	}
 
+	// the values of fields encrypted via fly config lock are decrypted, and
+	// the encryption section stays local
+	var decrypted int
+	if decrypted, err = configcrypt.Unlock(ctx, cfg.Definition); err != nil {
+		err = fmt.Errorf("failed decrypting app config: %w", err)
+
+		return
+	} else if decrypted > 0 {
+		tb.Detailf("Decrypted %d encrypted values", decrypted)
+	}
+	delete(cfg.Definition, configcrypt.Section)
+
+	if problems := append(cmdutil.ValidateKillSettings(cfg.Definition), machines.ValidateTunables(cfg.Definition)...); len(problems) > 0 {
+		err = fmt.Errorf("invalid app config: %s", strings.Join(problems, "; "))
+
+		return
+	}
+
+	if _, err = autoscale.FromDefinition(cfg.Definition); err != nil {
+		err = fmt.Errorf("invalid app config: %w", err)
+
+		return
+	}
+
+	if _, err = processgroup.FromDefinition(cfg.Definition); err != nil {
+		err = fmt.Errorf("invalid app config: %w", err)
+
+		return
+	}
+
 	tb.Done("Verified app config")
 
 	return
 }
 
+// showConfigDiff prints the differences between the deployed app config and
+// the one about to be deployed, as both are normalized by the platform.
+func showConfigDiff(ctx context.Context, appConfig *app.Config) error {
+	var (
+		appName = app.NameFromContext(ctx)
+		client  = client.FromContext(ctx).API()
+		io      = iostreams.FromContext(ctx)
+	)
+
+	tb := render.NewTextBlock(ctx, "Comparing app config against the deployed one")
+
+	deployed, err := client.GetConfig(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed fetching deployed app config: %w", err)
+	}
+
+	parsed, err := client.ParseConfig(ctx, appName, appConfig.Definition)
+	if err != nil {
+		return fmt.Errorf("failed parsing app config: %w", err)
+	}
+
+	diff, err := cmdutil.DiffDefinitions("deployed", deployed.Definition, "new", parsed.Definition)
+	if err != nil {
+		return fmt.Errorf("failed comparing app configs: %w", err)
+	}
+
+	if diff == "" {
+		tb.Done("No changes to the app config")
+
+		return nil
+	}
+
+	tb.Print(cmdutil.ColorizeDiff(diff, io.ColorScheme()))
+	tb.Done("Compared app configs")
+
+	return nil
+}
+
 // determineImage picks the deployment strategy, builds the image and returns a
 // DeploymentImage struct
 func determineImage(ctx context.Context, appConfig *app.Config) (img *imgsrc.DeploymentImage, err error) {
+	ctx, span := tracing.Start(ctx, "deploy.build")
+	defer func() { tracing.End(span, err) }()
+
 	tb := render.NewTextBlock(ctx, "Building image")
 	workingDirectory := state.WorkingDirectory(ctx)
 
@@ -217,6 +781,15 @@ func determineImage(ctx context.Context, appConfig *app.Config) (img *imgsrc.Dep
 
 	resolver := imgsrc.NewResolver(daemonType, client, appName, io)
 
+	if daemonType.AllowRemote() {
+		var b *imgsrc.SelfHostedBuilder
+		if b, err = builders.ForApp(ctx, client, appName); err != nil {
+			return nil, fmt.Errorf("failed determining the self-hosted builder of %s: %w", appName, err)
+		} else if b != nil {
+			resolver.UseSelfHostedBuilder(b)
+		}
+	}
+
 	var imageRef string
 	if imageRef, err = fetchImageRef(ctx, appConfig); err != nil {
 		return
@@ -247,6 +820,15 @@ func determineImage(ctx context.Context, appConfig *app.Config) (img *imgsrc.Dep
 		return
 	}
 
+	var uploadLimit int64
+	if s := flag.GetString(ctx, "upload-limit"); s != "" {
+		if uploadLimit, err = cmdutil.ParseByteRate(s); err != nil {
+			err = fmt.Errorf("invalid --upload-limit %q; %w", s, err)
+
+			return
+		}
+	}
+
 	// We're building from source
 	opts := imgsrc.ImageOptions{
 		AppName:         app.NameFromContext(ctx),
@@ -259,12 +841,44 @@ func determineImage(ctx context.Context, appConfig *app.Config) (img *imgsrc.Dep
 		BuiltInSettings: build.Settings,
 		Builder:         build.Builder,
 		Buildpacks:      build.Buildpacks,
+		SSH:             flag.GetStringArray(ctx, "ssh"),
+		StallTimeout:    time.Duration(flag.GetInt(ctx, "build-stall-timeout")) * time.Minute,
+		UploadLimit:     uploadLimit,
+	}
+
+	if build.Static != "" {
+		opts.Static = &imgsrc.StaticSite{
+			Dir:          build.Static,
+			Fallback:     build.StaticFallback,
+			CacheControl: build.StaticCache,
+		}
+	}
+
+	if flag.GetBool(ctx, "bundle-install") {
+		opts.CachePaths = cachePaths(ctx, build)
 	}
 
 	if opts.DockerfilePath, err = resolveDockerfilePath(ctx, appConfig); err != nil {
 		return
 	}
 
+	// buildpacks, builtins & static sites don't consume ARG declarations
+	if opts.Builder == "" && opts.BuiltIn == "" && opts.Static == nil {
+		dockerfilePath := opts.DockerfilePath
+		if dockerfilePath == "" {
+			dockerfilePath = filepath.Join(opts.WorkingDir, "Dockerfile")
+		}
+
+		var warnings []string
+		if warnings, err = validateBuildArgs(dockerfilePath, buildArgs); err != nil {
+			return
+		}
+
+		for _, warning := range warnings {
+			tb.Detailf("WARNING: %s", warning)
+		}
+	}
+
 	if target := appConfig.DockerBuildTarget(); target != "" {
 		opts.Target = target
 	} else if target := flag.GetString(ctx, "build-target"); target != "" {
@@ -272,7 +886,21 @@ func determineImage(ctx context.Context, appConfig *app.Config) (img *imgsrc.Dep
 	}
 
 	// finally, build the image
-	if img, err = resolver.BuildImage(ctx, io, opts); err == nil && img == nil {
+	img, err = resolver.BuildImage(ctx, io, opts)
+
+	var stalled *imgsrc.BuildStalledError
+	if errors.As(err, &stalled) {
+		tb.Detailf("WARNING: %v", stalled)
+
+		if err = recoverBuilder(ctx, resolver); err != nil {
+			return nil, err
+		}
+
+		tb.Detail("Retrying the build on the restarted builder")
+		img, err = resolver.BuildImage(ctx, io, opts)
+	}
+
+	if err == nil && img == nil {
 		err = errors.New("no image specified")
 	}
 
@@ -284,6 +912,50 @@ func determineImage(ctx context.Context, appConfig *app.Config) (img *imgsrc.Dep
 	return
 }
 
+// defaultBuildStallTimeout is the default number of minutes remote builds
+// may go without output.
+const defaultBuildStallTimeout = 10
+
+// recoverBuilder restarts the remote builder of a stuck build, once the user
+// confirms to, or right away with --auto-recover-builder.
+func recoverBuilder(ctx context.Context, resolver *imgsrc.Resolver) error {
+	if !flag.GetBool(ctx, "auto-recover-builder") {
+		switch confirmed, err := prompt.Confirm(ctx, "The build appears to be stuck. Restart the remote builder and retry the build?"); {
+		case prompt.IsNonInteractive(err):
+			return errors.New("the build appears to be stuck; pass --auto-recover-builder to restart the remote builder and retry the build")
+		case err != nil:
+			return err
+		case !confirmed:
+			return errors.New("the build appears to be stuck")
+		}
+	}
+
+	tb := render.NewTextBlock(ctx, "Restarting remote builder")
+
+	if err := resolver.RestartBuilder(ctx, iostreams.FromContext(ctx)); err != nil {
+		return fmt.Errorf("failed restarting remote builder: %w", err)
+	}
+
+	tb.Done("Remote builder restarted")
+
+	return nil
+}
+
+// cachePaths returns the paths dependency caches should be mounted at; the
+// ones given on the command line, or in their absence the ones the app config
+// denotes, or in their absence the default ones.
+func cachePaths(ctx context.Context, build *app.Build) []string {
+	if paths := flag.GetStringSlice(ctx, "cache-path"); len(paths) > 0 {
+		return paths
+	}
+
+	if len(build.CachePaths) > 0 {
+		return build.CachePaths
+	}
+
+	return imgsrc.DefaultCachePaths
+}
+
 // resolveDockerfilePath returns the absolute path to the Dockerfile
 // if one was specified in the app config or a command line argument
 func resolveDockerfilePath(ctx context.Context, appConfig *app.Config) (path string, err error) {
@@ -307,7 +979,20 @@ func mergeBuildArgs(ctx context.Context, args map[string]string) (map[string]str
 		args = make(map[string]string)
 	}
 
+	// set Docker build args from the build arg file, overriding similar ones from the config
+	if path := flag.GetString(ctx, "build-arg-file"); path != "" {
+		fileBuildArgs, err := loadBuildArgsFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for k, v := range fileBuildArgs {
+			args[k] = v
+		}
+	}
+
 	// set additional Docker build args from the command line, overriding similar ones from the config
+	// and the build arg file
 	cliBuildArgs, err := cmdutil.ParseKVStringsToMap(flag.GetStringSlice(ctx, "build-arg"))
 	if err != nil {
 		return nil, fmt.Errorf("invalid build args: %w", err)
@@ -351,15 +1036,108 @@ func createRelease(ctx context.Context, appConfig *app.Config, img *imgsrc.Deplo
 		input.Definition = api.DefinitionPtr(appConfig.Definition)
 	}
 
+	if only := flag.GetStringSlice(ctx, "only-process"); len(only) > 0 {
+		input.ProcessGroups = only
+		tb.Detailf("updating only the %s process groups", strings.Join(only, ", "))
+	}
+
+	var staged *secrets.Staged
+	if flag.GetBool(ctx, "staged-secrets") {
+		var err error
+		if staged, err = applyStagedSecrets(&input); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if !staged.IsEmpty() {
+		tb.Detailf("applying %d staged secrets and %d staged secret removals", len(staged.Set), len(staged.Unset))
+	}
+
+	if sources := flag.GetStringArray(ctx, "secrets-from"); len(sources) > 0 {
+		n, err := applyExternalSecrets(ctx, &input, sources)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		tb.Detailf("applying %d secrets from %s", n, strings.Join(sources, ", "))
+	}
+
 	// Start deployment of the determined image
 	client := client.FromContext(ctx).API()
 
 	release, releaseCommand, err := client.DeployImage(ctx, input)
-	if err == nil {
-		tb.Donef("release v%d created\n", release.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the staged secrets are part of the release now
+	if !staged.IsEmpty() {
+		if err := secrets.ClearStaged(input.AppID); err != nil {
+			return nil, nil, fmt.Errorf("failed clearing staged secrets: %w", err)
+		}
+	}
+
+	tb.Donef("release v%d created\n", release.Version)
+
+	return release, releaseCommand, nil
+}
+
+// applyStagedSecrets adds the secrets staged for the app to the given release
+// input so that code and secrets change in the same release.
+func applyStagedSecrets(input *api.DeployImageInput) (*secrets.Staged, error) {
+	staged, err := secrets.LoadStaged(input.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading staged secrets: %w", err)
+	}
+
+	if staged.IsEmpty() {
+		return staged, nil
+	}
+
+	for _, k := range staged.Names() {
+		input.Secrets = append(input.Secrets, api.SetSecretsInputSecret{Key: k, Value: staged.Set[k]})
+	}
+	input.UnsetSecrets = staged.Unset
+
+	return staged, nil
+}
+
+// applyExternalSecrets adds the secrets the given secrets manager sources
+// denote to the given release input, overriding any staged secrets of the same
+// name. It returns the number of secrets it added.
+func applyExternalSecrets(ctx context.Context, input *api.DeployImageInput, sources []string) (int, error) {
+	fetched, err := provider.FetchAll(ctx, sources)
+	if err != nil {
+		return 0, err
+	}
+
+	keep := input.Secrets[:0]
+	for _, s := range input.Secrets {
+		if _, ok := fetched[s.Key]; !ok {
+			keep = append(keep, s)
+		}
+	}
+	input.Secrets = keep
+
+	var unset []string
+	for _, k := range input.UnsetSecrets {
+		if _, ok := fetched[k]; !ok {
+			unset = append(unset, k)
+		}
+	}
+	input.UnsetSecrets = unset
+
+	names := make([]string, 0, len(fetched))
+	for k := range fetched {
+		names = append(names, k)
 	}
+	sort.Strings(names)
 
-	return release, releaseCommand, err
+	for _, k := range names {
+		input.Secrets = append(input.Secrets, api.SetSecretsInputSecret{Key: k, Value: fetched[k]})
+	}
+
+	return len(names), nil
 }
 
 func NixSourceBuild(ctx context.Context, workingDirectory string) (img *imgsrc.DeploymentImage, err error) {
@@ -393,23 +1171,17 @@ func NixSourceBuild(ctx context.Context, workingDirectory string) (img *imgsrc.D
 		RemoteHost: machines.IpAddress(builderMachine),
 	}
 
-	// run the rsync proxy in the background
+	// bind the rsync proxy before running it in the background, so rsync may
+	// connect to it right away
 	proxyCtx, cancelProxy := context.WithCancel(ctx)
 	defer cancelProxy()
 
-	go func() {
-		proxy.Connect(proxyCtx, params)
-	}()
-
-	// Wait for the rsync proxy to come alive
-	fn := func() error {
-		time.Sleep(1 * time.Second)
-		return waitForLocalPort(ctx, "8873")
+	srv, err := proxy.NewServer(proxyCtx, params)
+	if err != nil {
+		return nil, fmt.Errorf("rsync proxy failed to start: %w", err)
 	}
 
-	if err := retry.Retry(fn, 10); err != nil {
-		return nil, fmt.Errorf("rsync proxy failed to connect after 10 seconds: %w", err)
-	}
+	go srv.ProxyServer(proxyCtx)
 
 	fmt.Fprintf(io.Out, "Proxy connected. Syncing source code to the remote builder %s\n", builderApp.Name)
 
@@ -443,9 +1215,3 @@ func NixSourceBuild(ctx context.Context, workingDirectory string) (img *imgsrc.D
 
 	return di, err
 }
-
-func waitForLocalPort(ctx context.Context, port string) (err error) {
-	timeout := time.Second
-	_, err = net.DialTimeout("tcp", "localhost:"+port, timeout)
-	return
-}
@@ -0,0 +1,18 @@
+package deploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+)
+
+func TestConfigStrategy(t *testing.T) {
+	cfg := &app.Config{Definition: map[string]interface{}{
+		"deploy": map[string]interface{}{"strategy": "BlueGreen"},
+	}}
+	assert.Equal(t, "bluegreen", configStrategy(cfg))
+
+	assert.Empty(t, configStrategy(&app.Config{Definition: map[string]interface{}{}}))
+}
@@ -0,0 +1,57 @@
+package deploy
+
+import (
+	"context"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command/ci"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/github"
+)
+
+// githubSink reports the events of a deployment as the statuses of a GitHub
+// deployment.
+type githubSink struct {
+	client     *github.Client
+	annotation ci.GitHubAnnotation
+}
+
+func newGitHubSink(ctx context.Context) (*githubSink, error) {
+	client, err := github.FromEnv("")
+	if err != nil {
+		return nil, err
+	}
+
+	return &githubSink{
+		client: client,
+		annotation: ci.GitHubAnnotation{
+			App:         app.NameFromContext(ctx),
+			Environment: flag.GetString(ctx, "github-environment"),
+		},
+	}, nil
+}
+
+func (s *githubSink) deliver(ctx context.Context, e event) error {
+	state := github.StateInProgress
+	switch e.Type {
+	case eventDeploySucceeded:
+		state = github.StateSuccess
+	case eventDeployFailed, eventRollback:
+		state = github.StateFailure
+	}
+
+	// the status is reported even when the deployment was interrupted
+	_, err := s.annotation.Annotate(context.Background(), s.client, state, truncate(e.String(), 140))
+
+	return err
+}
+
+// truncate truncates s to n runes, which is as long as GitHub allows
+// descriptions of deployment statuses to be.
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+
+	return s
+}
@@ -0,0 +1,212 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azazeal/pause"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/build/imgsrc"
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/flyerr"
+	"github.com/superfly/flyctl/pkg/machines"
+)
+
+// machineStabilityPeriod is how long updated machines have to stay started
+// for before the next one is updated.
+const machineStabilityPeriod = 10 * time.Second
+
+// machineCheckInterval is how often the health checks of updated machines are
+// polled while they don't pass.
+const machineCheckInterval = 2 * time.Second
+
+const defaultMachineWaitTimeout = 120
+
+// isMachinesApp reports whether the app of ctx runs on the machines platform,
+// which apps are deployed to by updating their machines instead of via
+// releases.
+func isMachinesApp(ctx context.Context) (bool, error) {
+	client := client.FromContext(ctx).API()
+
+	app, err := client.GetAppCompact(ctx, app.NameFromContext(ctx))
+	if err != nil {
+		return false, fmt.Errorf("failed retrieving app: %w", err)
+	}
+
+	return app.PlatformVersion == "machines", nil
+}
+
+// deployToMachines updates the machines of the app of ctx to run img with the
+// env and guest tunables of appConfig. Unless the immediate strategy applies,
+// machines are updated one at a time, waiting for each to start, stay started
+// and pass its health checks before moving on to the next.
+func deployToMachines(ctx context.Context, appConfig *app.Config, img *imgsrc.DeploymentImage) error {
+	var (
+		appName = app.NameFromContext(ctx)
+		client  = client.FromContext(ctx).API()
+	)
+
+	strategy := strings.ToLower(flag.GetString(ctx, "strategy"))
+	switch strategy {
+	case "", "rolling", "immediate":
+		break
+	default:
+		return fmt.Errorf("the %s strategy is not supported for apps running on machines; use rolling or immediate", strategy)
+	}
+
+	timeout := flag.GetInt(ctx, "wait-timeout")
+	if timeout <= 0 {
+		return errors.New("--wait-timeout must be positive")
+	}
+
+	all, err := client.ListMachines(ctx, appName, "")
+	if err != nil {
+		return fmt.Errorf("failed listing machines: %w", err)
+	}
+
+	var targets []*api.Machine
+	for _, m := range all {
+		if m.State != "destroyed" && m.State != "destroying" {
+			targets = append(targets, m)
+		}
+	}
+
+	if len(targets) == 0 {
+		return fmt.Errorf("app %s has no machines to deploy to; create one with 'fly machine run'", appName)
+	}
+
+	tunables, problems := machines.TunablesFromDefinition(appConfig.Definition)
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid guest tunables: %s", strings.Join(problems, "; "))
+	}
+
+	wait := strategy != "immediate" && !flag.GetDetach(ctx)
+
+	tb := render.NewTextBlock(ctx, fmt.Sprintf("Updating %d machines of %s to %s", len(targets), appName, img.Tag))
+
+	for i, m := range targets {
+		wasStarted := m.State == "started"
+
+		cfg := machineConfig(m.Config, appConfig.Definition, img.Tag)
+		tunables.Apply(&cfg)
+
+		input := api.LaunchMachineInput{
+			AppID:  appName,
+			ID:     m.ID,
+			Name:   m.Name,
+			Region: m.Region,
+			Config: &cfg,
+		}
+
+		if _, _, err := client.LaunchMachine(ctx, input); err != nil {
+			return fmt.Errorf("failed updating machine %s (%d of %d machines updated): %w", m.ID, i, len(targets), err)
+		}
+
+		if !wait || !wasStarted {
+			tb.Detailf("Updated machine %s [%s]", m.ID, m.Region)
+
+			continue
+		}
+
+		if err := waitForHealthyMachine(ctx, client, appName, m.ID, time.Duration(timeout)*time.Second); err != nil {
+			return &flyerr.HealthCheckError{
+				Err: fmt.Errorf("%w; %d of %d machines updated, see 'fly machine logs %s'", err, i+1, len(targets), m.ID),
+			}
+		}
+
+		tb.Detailf("Updated machine %s [%s], which is started", m.ID, m.Region)
+	}
+
+	tb.Donef("Updated %d machines", len(targets))
+
+	return nil
+}
+
+// waitForHealthyMachine waits for the given machine to start, stay started
+// for machineStabilityPeriod and pass its health checks, all within timeout.
+func waitForHealthyMachine(ctx context.Context, client *api.Client, appName, id string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	if err := machines.WaitForState(ctx, client, appName, id, "started", timeout); err != nil {
+		return err
+	}
+
+	pause.For(ctx, machineStabilityPeriod)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		machine, err := client.GetMachine(ctx, appName, id)
+		if err != nil {
+			return fmt.Errorf("failed retrieving machine %s: %w", id, err)
+		}
+
+		if machine.State != "started" {
+			return fmt.Errorf("machine %s is %s after starting", id, machine.State)
+		}
+
+		failing := failingChecks(machine.Checks)
+		if len(failing) == 0 {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("health checks of machine %s did not pass in time: %s", id, strings.Join(failing, "; "))
+		}
+
+		pause.For(ctx, machineCheckInterval)
+	}
+}
+
+// failingChecks describes the given health checks which don't pass.
+func failingChecks(checks []*api.CheckState) (failing []string) {
+	for _, check := range checks {
+		if check.Status == "passing" {
+			continue
+		}
+
+		desc := fmt.Sprintf("%s is %s", check.Name, check.Status)
+		if output := strings.TrimSpace(check.Output); output != "" {
+			desc += " (" + output + ")"
+		}
+
+		failing = append(failing, desc)
+	}
+
+	return
+}
+
+// machineConfig returns cfg updated to run image with the env of the given
+// app config definition.
+func machineConfig(cfg api.MachineConfig, definition map[string]interface{}, image string) api.MachineConfig {
+	cfg.Image = image
+
+	env := make(map[string]string, len(cfg.Env))
+	for k, v := range cfg.Env {
+		env[k] = v
+	}
+
+	switch vars := definition["env"].(type) {
+	case map[string]string:
+		for k, v := range vars {
+			env[k] = v
+		}
+	case map[string]interface{}:
+		for k, v := range vars {
+			env[k] = fmt.Sprint(v)
+		}
+	}
+
+	cfg.Env = env
+
+	return cfg
+}
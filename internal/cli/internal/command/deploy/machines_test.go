@@ -0,0 +1,42 @@
+package deploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestMachineConfig(t *testing.T) {
+	current := api.MachineConfig{
+		Image: "registry.fly.io/app:deployment-1",
+		Env:   map[string]string{"A": "1", "B": "2"},
+	}
+
+	cfg := machineConfig(current, map[string]interface{}{
+		"env": map[string]interface{}{"B": "3", "PORT": int64(8080)},
+	}, "registry.fly.io/app:deployment-2")
+
+	assert.Equal(t, "registry.fly.io/app:deployment-2", cfg.Image)
+	assert.Equal(t, map[string]string{"A": "1", "B": "3", "PORT": "8080"}, cfg.Env)
+
+	// the current config is left as is
+	assert.Equal(t, "registry.fly.io/app:deployment-1", current.Image)
+	assert.Equal(t, map[string]string{"A": "1", "B": "2"}, current.Env)
+}
+
+func TestFailingChecks(t *testing.T) {
+	assert.Empty(t, failingChecks(nil))
+
+	checks := []*api.CheckState{
+		{Name: "http", Status: "passing"},
+		{Name: "tcp", Status: "critical", Output: "  connection refused\n"},
+		{Name: "disk", Status: "warning"},
+	}
+
+	assert.Equal(t, []string{
+		"tcp is critical (connection refused)",
+		"disk is warning",
+	}, failingChecks(checks))
+}
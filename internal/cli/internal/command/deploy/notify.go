@@ -0,0 +1,322 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/cli/internal/watch"
+	"github.com/superfly/flyctl/internal/desktop"
+)
+
+// The types of the events deployments emit.
+const (
+	eventBuildStarted    = "build_started"
+	eventBuildFinished   = "build_finished"
+	eventReleaseCreated  = "release_created"
+	eventDeploySucceeded = "deploy_succeeded"
+	eventDeployFailed    = "deploy_failed"
+	eventRollback        = "rollback"
+)
+
+// event wraps the progress of a deployment.
+type event struct {
+	Type    string    `json:"event"`
+	App     string    `json:"app"`
+	Version int       `json:"version,omitempty"`
+	Image   string    `json:"image,omitempty"`
+	Error   string    `json:"error,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// String implements fmt.Stringer for e, rendering it as the message chat
+// webhooks receive.
+func (e event) String() string {
+	switch e.Type {
+	case eventBuildStarted:
+		return fmt.Sprintf("%s: build started", e.App)
+	case eventBuildFinished:
+		return fmt.Sprintf("%s: build finished with image %s", e.App, e.Image)
+	case eventReleaseCreated:
+		return fmt.Sprintf("%s: release v%d created with image %s", e.App, e.Version, e.Image)
+	case eventDeploySucceeded:
+		return fmt.Sprintf("%s: v%d deployed successfully", e.App, e.Version)
+	case eventRollback:
+		return fmt.Sprintf("%s: v%d failed and is being rolled back", e.App, e.Version)
+	default:
+		if e.Version > 0 {
+			return fmt.Sprintf("%s: v%d failed to deploy: %s", e.App, e.Version, e.Error)
+		}
+
+		return fmt.Sprintf("%s: deployment failed: %s", e.App, e.Error)
+	}
+}
+
+// The formats of the payloads webhooks receive.
+const (
+	payloadJSON    = "json"
+	payloadSlack   = "slack"
+	payloadDiscord = "discord"
+)
+
+// notifier reports the events of a deployment to sinks, like webhooks.
+// Delivery failures are reported as warnings; they never fail deployments.
+type notifier struct {
+	app     string
+	sinks   []sink
+	version int
+	image   string
+}
+
+// sink is implemented by the destinations of deployment events.
+type sink interface {
+	deliver(ctx context.Context, e event) error
+}
+
+// desktopNotification sends the events which end stages of deployments as
+// desktop notifications.
+type desktopNotification struct {
+	send func(ctx context.Context, title, message string) error
+}
+
+type webhook struct {
+	url    string
+	format string
+	client *http.Client
+}
+
+// newNotifier returns a notifier for the webhooks the --notify-url flags
+// denote, or in their absence, the notify_url the [deploy] section of the
+// given app config denotes.
+func newNotifier(ctx context.Context, appConfig *app.Config) (*notifier, error) {
+	urls := flag.GetStringSlice(ctx, "notify-url")
+	format := flag.GetString(ctx, "notify-format")
+
+	if len(urls) == 0 {
+		urls, format = notifyConfig(appConfig, format)
+	}
+
+	n := &notifier{
+		app: app.NameFromContext(ctx),
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	for _, rawURL := range urls {
+		u, err := url.Parse(rawURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			// the url isn't quoted as it likely holds the secret of the webhook
+			return nil, errors.New("invalid notify url; notify urls must be http or https urls")
+		}
+
+		hookFormat := format
+		if hookFormat == "" {
+			hookFormat = detectPayloadFormat(u)
+		}
+
+		switch hookFormat {
+		case payloadJSON, payloadSlack, payloadDiscord:
+		default:
+			return nil, fmt.Errorf("invalid notify format %q; use one of json, slack or discord", hookFormat)
+		}
+
+		n.sinks = append(n.sinks, &webhook{url: rawURL, format: hookFormat, client: client})
+	}
+
+	// --notify=false disables the desktop notifications the settings enable
+	desktopNotifications := config.FromContext(ctx).DesktopNotifications
+	if flag.FromContext(ctx).Changed("notify") {
+		desktopNotifications = flag.GetBool(ctx, "notify")
+	}
+	if desktopNotifications {
+		n.sinks = append(n.sinks, &desktopNotification{send: desktop.Notify})
+	}
+
+	if flag.GetBool(ctx, "github-deployment") {
+		s, err := newGitHubSink(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		n.sinks = append(n.sinks, s)
+	}
+
+	return n, nil
+}
+
+// notifyConfig returns the notify_url and notify_format settings of the
+// [deploy] section of the given app config. The given format takes
+// precedence over the configured one. notify_url may be a list.
+func notifyConfig(appConfig *app.Config, format string) (urls []string, _ string) {
+	deploy, ok := appConfig.Definition["deploy"].(map[string]interface{})
+	if !ok {
+		return nil, format
+	}
+
+	switch v := deploy["notify_url"].(type) {
+	case string:
+		urls = append(urls, v)
+	case []interface{}:
+		for _, u := range v {
+			urls = append(urls, fmt.Sprint(u))
+		}
+	}
+
+	if f, ok := deploy["notify_format"].(string); ok && format == "" {
+		format = f
+	}
+
+	return urls, format
+}
+
+func detectPayloadFormat(u *url.URL) string {
+	host := strings.ToLower(u.Hostname())
+
+	switch {
+	case host == "hooks.slack.com":
+		return payloadSlack
+	case (host == "discord.com" || host == "discordapp.com") && strings.HasPrefix(u.Path, "/api/webhooks/"):
+		return payloadDiscord
+	default:
+		return payloadJSON
+	}
+}
+
+func (n *notifier) buildStarted(ctx context.Context) {
+	n.notify(ctx, event{Type: eventBuildStarted})
+}
+
+func (n *notifier) buildFinished(ctx context.Context, image string) {
+	n.image = image
+
+	n.notify(ctx, event{Type: eventBuildFinished})
+}
+
+func (n *notifier) releaseCreated(ctx context.Context, version int, image string) {
+	n.version, n.image = version, image
+
+	n.notify(ctx, event{Type: eventReleaseCreated})
+}
+
+// finished reports the outcome of the deployment err denotes.
+func (n *notifier) finished(ctx context.Context, err error) {
+	if err == nil {
+		n.notify(ctx, event{Type: eventDeploySucceeded})
+
+		return
+	}
+
+	e := event{
+		Type:  eventDeployFailed,
+		Error: err.Error(),
+	}
+
+	var rollback *watch.RollbackError
+	if errors.As(err, &rollback) {
+		e.Type = eventRollback
+	}
+
+	n.notify(ctx, e)
+}
+
+func (n *notifier) notify(ctx context.Context, e event) {
+	if len(n.sinks) == 0 {
+		return
+	}
+
+	e.App = n.app
+	e.Version = n.version
+	e.Image = n.image
+	e.Time = time.Now().UTC()
+
+	for _, s := range n.sinks {
+		if err := s.deliver(ctx, e); err != nil {
+			render.NewTextBlock(ctx).Detailf("WARNING: failed delivering %s event: %v", e.Type, err)
+		}
+	}
+}
+
+func (d *desktopNotification) deliver(_ context.Context, e event) error {
+	var title string
+	switch e.Type {
+	case eventBuildFinished:
+		title = "Build finished"
+	case eventDeploySucceeded:
+		title = "Deployment succeeded"
+	case eventDeployFailed, eventRollback:
+		title = "Deployment failed"
+	default:
+		return nil
+	}
+
+	// notifications must not fail because the deployment was interrupted
+	err := d.send(context.Background(), title, e.String())
+	if errors.Is(err, desktop.ErrUnsupported) {
+		// there's no point in warning about each event
+		d.send = func(context.Context, string, string) error { return nil }
+	}
+
+	return err
+}
+
+func (hook *webhook) deliver(_ context.Context, e event) error {
+	var payload interface{} = e
+	switch hook.format {
+	case payloadSlack:
+		payload = map[string]string{"text": e.String()}
+	case payloadDiscord:
+		payload = map[string]string{"content": e.String()}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	// deliveries must not fail because the deployment was interrupted
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, hook.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := hook.client.Do(req)
+	if err != nil {
+		// the errors of the client quote the URL, which holds the secret
+		// of the webhook more often than not
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		return fmt.Errorf("webhook %s: %w", hook.origin(), err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responded with %s", hook.origin(), res.Status)
+	}
+
+	return nil
+}
+
+// origin returns the scheme and host of the URL of the webhook, which are
+// safe to print, unlike its path and query.
+func (hook *webhook) origin() string {
+	u, err := url.Parse(hook.url)
+	if err != nil {
+		return "(invalid url)"
+	}
+
+	return u.Scheme + "://" + u.Host
+}
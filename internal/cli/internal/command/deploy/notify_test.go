@@ -0,0 +1,152 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/watch"
+	"github.com/superfly/flyctl/internal/desktop"
+	"github.com/superfly/flyctl/internal/flyerr"
+)
+
+func TestDetectPayloadFormat(t *testing.T) {
+	cases := map[string]string{
+		"https://hooks.slack.com/services/T0/B0/X":        payloadSlack,
+		"https://discord.com/api/webhooks/1/token":        payloadDiscord,
+		"https://discordapp.com/api/webhooks/1/token":     payloadDiscord,
+		"https://discord.com/channels/1":                  payloadJSON,
+		"https://ci.example.com/hooks/deployments?key=ab": payloadJSON,
+	}
+
+	for rawURL, format := range cases {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+
+		assert.Equal(t, format, detectPayloadFormat(u), rawURL)
+	}
+}
+
+func TestNotifyConfig(t *testing.T) {
+	cfg := &app.Config{Definition: map[string]interface{}{
+		"deploy": map[string]interface{}{
+			"notify_url":    []interface{}{"https://a.example.com", "https://b.example.com"},
+			"notify_format": "slack",
+		},
+	}}
+
+	urls, format := notifyConfig(cfg, "")
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, urls)
+	assert.Equal(t, payloadSlack, format)
+
+	_, format = notifyConfig(cfg, payloadDiscord)
+	assert.Equal(t, payloadDiscord, format)
+
+	urls, _ = notifyConfig(&app.Config{}, "")
+	assert.Empty(t, urls)
+}
+
+func TestNotifier(t *testing.T) {
+	var payloads []map[string]interface{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var payload map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		payloads = append(payloads, payload)
+	}))
+	defer srv.Close()
+
+	n := &notifier{
+		app: "my-app",
+		sinks: []sink{
+			&webhook{url: srv.URL, format: payloadJSON, client: srv.Client()},
+			&webhook{url: srv.URL, format: payloadSlack, client: srv.Client()},
+		},
+	}
+
+	ctx := context.Background()
+	n.releaseCreated(ctx, 3, "registry.fly.io/my-app:deployment-1")
+	n.finished(ctx, &flyerr.HealthCheckError{Err: &watch.RollbackError{Version: 3}})
+
+	require.Len(t, payloads, 4)
+
+	assert.Equal(t, eventReleaseCreated, payloads[0]["event"])
+	assert.Equal(t, "my-app", payloads[0]["app"])
+	assert.EqualValues(t, 3, payloads[0]["version"])
+	assert.Equal(t, "my-app: release v3 created with image registry.fly.io/my-app:deployment-1", payloads[1]["text"])
+
+	assert.Equal(t, eventRollback, payloads[2]["event"])
+	assert.Equal(t, "my-app: v3 failed and is being rolled back", payloads[3]["text"])
+
+	n.version = 0
+	n.finished(ctx, errors.New("boom"))
+	assert.Equal(t, "my-app: deployment failed: boom", payloads[5]["text"])
+}
+
+func TestWebhookErrorsOmitSecrets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	hook := &webhook{url: srv.URL + "/hooks/secret-token?key=secret", format: payloadJSON, client: srv.Client()}
+
+	err := hook.deliver(context.Background(), event{Type: eventDeploySucceeded})
+	assert.EqualError(t, err, "webhook "+srv.URL+" responded with 404 Not Found")
+
+	srv.Close()
+
+	err = hook.deliver(context.Background(), event{Type: eventDeploySucceeded})
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret")
+}
+
+func TestDesktopNotification(t *testing.T) {
+	var titles, messages []string
+
+	d := &desktopNotification{
+		send: func(_ context.Context, title, message string) error {
+			titles = append(titles, title)
+			messages = append(messages, message)
+
+			return nil
+		},
+	}
+
+	n := &notifier{
+		app:   "my-app",
+		sinks: []sink{d},
+	}
+
+	ctx := context.Background()
+	n.buildStarted(ctx)
+	n.buildFinished(ctx, "registry.fly.io/my-app:deployment-1")
+	n.releaseCreated(ctx, 3, "registry.fly.io/my-app:deployment-1")
+	n.finished(ctx, nil)
+
+	assert.Equal(t, []string{"Build finished", "Deployment succeeded"}, titles)
+	assert.Equal(t, []string{
+		"my-app: build finished with image registry.fly.io/my-app:deployment-1",
+		"my-app: v3 deployed successfully",
+	}, messages)
+
+	var sent int
+	d.send = func(context.Context, string, string) error {
+		sent++
+
+		return desktop.ErrUnsupported
+	}
+
+	assert.ErrorIs(t, d.deliver(ctx, event{Type: eventDeployFailed}), desktop.ErrUnsupported)
+	assert.NoError(t, d.deliver(ctx, event{Type: eventDeployFailed}))
+	assert.Equal(t, 1, sent)
+}
@@ -0,0 +1,111 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/processgroup"
+)
+
+// scaleProcessGroups applies the counts and VM sizes the process groups of the
+// app config declare.
+func scaleProcessGroups(ctx context.Context, groups []processgroup.Group) error {
+	var (
+		appName   = app.NameFromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+		tb        = render.NewTextBlock(ctx, "Scaling process groups")
+	)
+
+	for _, group := range processgroup.Sized(groups) {
+		size, err := apiClient.SetAppVMSize(ctx, appName, group.Name, group.VMSize, int64(group.MemoryMB))
+		if err != nil {
+			return fmt.Errorf("failed sizing process group %s: %w", group.Name, err)
+		}
+
+		tb.Detailf("%s runs on %s VMs with %d MB of memory", group.Name, size.Name, size.MemoryMB)
+	}
+
+	counts := processgroup.Counts(groups)
+	if len(counts) == 0 {
+		tb.Done("Scaled process groups")
+
+		return nil
+	}
+
+	scaled, warnings, err := apiClient.SetAppVMCount(ctx, appName, counts, nil)
+	if err != nil {
+		return fmt.Errorf("failed scaling process groups: %w", err)
+	}
+
+	for _, warning := range warnings {
+		tb.Detailf("WARNING: %s", warning)
+	}
+
+	summary := make([]string, 0, len(scaled))
+	for _, tg := range scaled {
+		summary = append(summary, fmt.Sprintf("%s=%d", tg.Name, tg.Count))
+	}
+	sort.Strings(summary)
+
+	tb.Donef("Scaled process groups: %s", strings.Join(summary, " "))
+
+	return nil
+}
+
+// validateOnlyProcess checks that the groups --only-process names are declared
+// in the processes section of the app config.
+func validateOnlyProcess(ctx context.Context, appConfig *app.Config) error {
+	only := flag.GetStringSlice(ctx, "only-process")
+	if len(only) == 0 {
+		return nil
+	}
+
+	groups, err := processgroup.FromDefinition(appConfig.Definition)
+	if err != nil {
+		return err
+	} else if len(groups) == 0 {
+		return errors.New("--only-process requires the app config to declare process groups")
+	}
+
+	declared := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		declared[group.Name] = true
+	}
+
+	for _, name := range only {
+		if !declared[name] {
+			return fmt.Errorf("process group %q not found; the app config declares %s", name, strings.Join(processgroup.Names(groups), ", "))
+		}
+	}
+
+	return nil
+}
+
+// selectProcessGroups returns the groups only names, or all groups in case it
+// names none.
+func selectProcessGroups(groups []processgroup.Group, only []string) []processgroup.Group {
+	if len(only) == 0 {
+		return groups
+	}
+
+	selected := make(map[string]bool, len(only))
+	for _, name := range only {
+		selected[name] = true
+	}
+
+	var ret []processgroup.Group
+	for _, group := range groups {
+		if selected[group.Name] {
+			ret = append(ret, group)
+		}
+	}
+
+	return ret
+}
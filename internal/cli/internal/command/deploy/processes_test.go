@@ -0,0 +1,20 @@
+package deploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/internal/processgroup"
+)
+
+func TestSelectProcessGroups(t *testing.T) {
+	groups := []processgroup.Group{
+		{Name: "web", Command: "bin/rails server"},
+		{Name: "worker", Command: "bundle exec sidekiq"},
+	}
+
+	assert.Equal(t, groups, selectProcessGroups(groups, nil))
+	assert.Equal(t, groups[:1], selectProcessGroups(groups, []string{"web"}))
+	assert.Empty(t, selectProcessGroups(groups, []string{"cron"}))
+}
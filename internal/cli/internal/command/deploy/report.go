@@ -0,0 +1,355 @@
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+// The formats of the reports --report writes.
+const (
+	reportJUnit = "junit"
+	reportTAP   = "tap"
+)
+
+// reportFile denotes a report --report writes.
+type reportFile struct {
+	format string
+	path   string
+}
+
+// reportCase denotes the outcome of a phase of the deployment or of a health
+// check of one of the instances it deployed, as a test case of a report.
+type reportCase struct {
+	suite    string
+	class    string
+	name     string
+	duration time.Duration
+	failure  string
+	skipped  bool
+}
+
+// reporter records the phases of a deployment and the health of the instances
+// it deployed, and renders them as test reports for CI systems which only
+// understand those. Failing to write reports never fails deployments.
+type reporter struct {
+	app   string
+	files []reportFile
+	cases []reportCase
+	start time.Time
+}
+
+// newReporter returns a reporter for the reports the --report flags denote.
+func newReporter(ctx context.Context) (*reporter, error) {
+	r := &reporter{
+		app:   app.NameFromContext(ctx),
+		start: time.Now(),
+	}
+
+	for _, spec := range flag.GetStringSlice(ctx, "report") {
+		f, err := parseReportFile(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		r.files = append(r.files, f)
+	}
+
+	return r, nil
+}
+
+func parseReportFile(spec string) (reportFile, error) {
+	i := strings.Index(spec, "=")
+	if i < 0 || spec[i+1:] == "" {
+		return reportFile{}, fmt.Errorf("invalid report %q; use <format>=<path>, e.g. junit=deploy.xml", spec)
+	}
+
+	f := reportFile{
+		format: strings.ToLower(spec[:i]),
+		path:   spec[i+1:],
+	}
+
+	switch f.format {
+	case reportJUnit, reportTAP:
+		return f, nil
+	default:
+		return reportFile{}, fmt.Errorf("invalid report format %q; use one of junit or tap", spec[:i])
+	}
+}
+
+// phase records the start of the named phase of the deployment and returns
+// the function which records its outcome.
+func (r *reporter) phase(name string) func(error) {
+	start := time.Now()
+
+	return func(err error) {
+		c := reportCase{
+			suite:    "phases",
+			class:    "deploy",
+			name:     name,
+			duration: time.Since(start),
+		}
+		if err != nil {
+			c.failure = err.Error()
+		}
+
+		r.cases = append(r.cases, c)
+	}
+}
+
+// skip records the named phase of the deployment as skipped.
+func (r *reporter) skip(name string) {
+	r.cases = append(r.cases, reportCase{
+		suite:   "phases",
+		class:   "deploy",
+		name:    name,
+		skipped: true,
+	})
+}
+
+// finished records the health of the instances the deployment left running,
+// in case it was monitored, and writes the reports.
+func (r *reporter) finished(ctx context.Context, monitored bool) {
+	if len(r.files) == 0 {
+		return
+	}
+
+	if monitored {
+		if err := r.recordHealth(ctx); err != nil {
+			render.NewTextBlock(ctx).Detailf("WARNING: failed retrieving instance health for the deploy report: %v", err)
+		}
+	}
+
+	for _, f := range r.files {
+		var (
+			data []byte
+			err  error
+		)
+
+		switch f.format {
+		case reportJUnit:
+			data, err = r.junit()
+		case reportTAP:
+			data = r.tap()
+		}
+
+		if err == nil {
+			err = os.WriteFile(f.path, data, 0644)
+		}
+
+		if err != nil {
+			render.NewTextBlock(ctx).Detailf("WARNING: failed writing %s report %s: %v", f.format, f.path, err)
+		}
+	}
+}
+
+func (r *reporter) recordHealth(ctx context.Context) error {
+	apiClient := client.FromContext(ctx).API()
+
+	machinesApp, err := isMachinesApp(ctx)
+	if err != nil {
+		return err
+	}
+
+	if machinesApp {
+		machines, err := apiClient.ListMachines(ctx, r.app, "")
+		if err != nil {
+			return err
+		}
+
+		for _, m := range machines {
+			if m.State == "destroyed" || m.State == "destroying" {
+				continue
+			}
+
+			c := reportCase{
+				suite: "health",
+				class: m.Region,
+				name:  fmt.Sprintf("machine %s started", m.ID),
+			}
+			if m.State != "started" {
+				c.failure = fmt.Sprintf("machine %s is %s", m.ID, m.State)
+			}
+
+			r.cases = append(r.cases, c)
+		}
+
+		return nil
+	}
+
+	status, err := apiClient.GetAppStatus(ctx, r.app, false)
+	if err != nil {
+		return err
+	}
+
+	for _, alloc := range status.Allocations {
+		if len(alloc.Checks) == 0 {
+			c := reportCase{
+				suite: "health",
+				class: alloc.Region,
+				name:  fmt.Sprintf("instance %s healthy", alloc.IDShort),
+			}
+			if !alloc.Healthy {
+				c.failure = fmt.Sprintf("instance %s is %s", alloc.IDShort, alloc.Status)
+			}
+
+			r.cases = append(r.cases, c)
+
+			continue
+		}
+
+		for _, check := range alloc.Checks {
+			c := reportCase{
+				suite: "health",
+				class: alloc.Region,
+				name:  fmt.Sprintf("instance %s check %s", alloc.IDShort, check.Name),
+			}
+			if check.Status != "passing" {
+				c.failure = fmt.Sprintf("check %s is %s: %s", check.Name, check.Status, check.Output)
+			}
+
+			r.cases = append(r.cases, c)
+		}
+	}
+
+	return nil
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (r *reporter) junit() ([]byte, error) {
+	root := junitTestSuites{
+		Name: "deploy " + r.app,
+		Time: seconds(time.Since(r.start)),
+	}
+
+	for _, suite := range []string{"phases", "health"} {
+		s := junitTestSuite{
+			Name: fmt.Sprintf("%s %s", r.app, suite),
+		}
+
+		var total time.Duration
+		for _, c := range r.cases {
+			if c.suite != suite {
+				continue
+			}
+
+			tc := junitTestCase{
+				ClassName: c.class,
+				Name:      c.name,
+				Time:      seconds(c.duration),
+			}
+
+			switch {
+			case c.skipped:
+				tc.Skipped = &struct{}{}
+				s.Skipped++
+			case c.failure != "":
+				tc.Failure = &junitFailure{Message: firstLine(c.failure), Text: c.failure}
+				s.Failures++
+			}
+
+			total += c.duration
+			s.Tests++
+			s.Cases = append(s.Cases, tc)
+		}
+
+		if s.Tests == 0 {
+			continue
+		}
+
+		s.Time = seconds(total)
+
+		root.Tests += s.Tests
+		root.Failures += s.Failures
+		root.Skipped += s.Skipped
+		root.Suites = append(root.Suites, s)
+	}
+
+	data, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+func (r *reporter) tap() []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintln(&buf, "TAP version 13")
+	fmt.Fprintf(&buf, "1..%d\n", len(r.cases))
+
+	for i, c := range r.cases {
+		desc := fmt.Sprintf("%s: %s", c.suite, c.name)
+		if c.class != "" && c.suite == "health" {
+			desc = fmt.Sprintf("%s: [%s] %s", c.suite, c.class, c.name)
+		}
+
+		switch {
+		case c.skipped:
+			fmt.Fprintf(&buf, "ok %d - %s # SKIP\n", i+1, desc)
+		case c.failure != "":
+			fmt.Fprintf(&buf, "not ok %d - %s\n", i+1, desc)
+			fmt.Fprintln(&buf, "  ---")
+			fmt.Fprintf(&buf, "  message: %q\n", c.failure)
+			fmt.Fprintf(&buf, "  duration_ms: %d\n", c.duration.Milliseconds())
+			fmt.Fprintln(&buf, "  ...")
+		default:
+			fmt.Fprintf(&buf, "ok %d - %s\n", i+1, desc)
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+
+	return s
+}
@@ -0,0 +1,73 @@
+package deploy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReportFile(t *testing.T) {
+	f, err := parseReportFile("JUnit=out/deploy.xml")
+	require.NoError(t, err)
+	assert.Equal(t, reportFile{format: reportJUnit, path: "out/deploy.xml"}, f)
+
+	for _, spec := range []string{"junit", "junit=", "xunit=deploy.xml"} {
+		_, err := parseReportFile(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func testReporter() *reporter {
+	return &reporter{
+		app:   "my-app",
+		start: time.Now(),
+		cases: []reportCase{
+			{suite: "phases", class: "deploy", name: "build", duration: 1500 * time.Millisecond},
+			{suite: "phases", class: "deploy", name: "monitor", duration: time.Second, failure: "v2 failed\ndetails"},
+			{suite: "phases", class: "deploy", name: "load_test", skipped: true},
+			{suite: "health", class: "iad", name: "instance abcd check http"},
+		},
+	}
+}
+
+func TestReporterJUnit(t *testing.T) {
+	data, err := testReporter().junit()
+	require.NoError(t, err)
+
+	got := string(data)
+	assert.True(t, strings.HasPrefix(got, "<?xml"))
+	assert.Contains(t, got, `<testsuites name="deploy my-app" tests="4" failures="1" skipped="1"`)
+	assert.Contains(t, got, `<testsuite name="my-app phases" tests="3" failures="1" skipped="1" time="2.500">`)
+	assert.Contains(t, got, `<testcase classname="deploy" name="build" time="1.500"></testcase>`)
+	assert.Contains(t, got, `<failure message="v2 failed">v2 failed&#xA;details</failure>`)
+	assert.Contains(t, got, `<skipped></skipped>`)
+	assert.Contains(t, got, `<testcase classname="iad" name="instance abcd check http" time="0.000"></testcase>`)
+}
+
+func TestReporterTAP(t *testing.T) {
+	r := testReporter()
+
+	end := r.phase("release")
+	end(errors.New("boom"))
+
+	assert.Equal(t, `TAP version 13
+1..5
+ok 1 - phases: build
+not ok 2 - phases: monitor
+  ---
+  message: "v2 failed\ndetails"
+  duration_ms: 1000
+  ...
+ok 3 - phases: load_test # SKIP
+ok 4 - health: [iad] instance abcd check http
+not ok 5 - phases: release
+  ---
+  message: "boom"
+  duration_ms: 0
+  ...
+`, string(r.tap()))
+}
@@ -0,0 +1,246 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/flyerr"
+	"github.com/superfly/flyctl/internal/tracing"
+)
+
+// The kinds of the requirements deployments may be gated on.
+const (
+	requirePostgres = "postgres"
+	requireApp      = "app"
+	requireSecret   = "secret"
+	requireCert     = "cert"
+)
+
+// requirement wraps a prerequisite of deployments, as declared by the require
+// setting of the [deploy] section of the app config, e.g. "secret:STRIPE_KEY".
+type requirement struct {
+	kind   string
+	target string
+}
+
+func (r requirement) String() string {
+	return r.kind + ":" + r.target
+}
+
+// requirements returns the requirements the app config declares.
+func requirements(appConfig *app.Config) (reqs []requirement, err error) {
+	deploy, ok := appConfig.Definition["deploy"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	var specs []string
+	switch v := deploy["require"].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		specs = append(specs, v)
+	case []interface{}:
+		for _, s := range v {
+			specs = append(specs, fmt.Sprint(s))
+		}
+	default:
+		return nil, &flyerr.ValidationError{
+			Err: errors.New("the require setting of the [deploy] section must be a list of strings"),
+		}
+	}
+
+	for _, spec := range specs {
+		var r requirement
+		if r, err = parseRequirement(spec); err != nil {
+			return nil, &flyerr.ValidationError{Err: err}
+		}
+
+		reqs = append(reqs, r)
+	}
+
+	return
+}
+
+func parseRequirement(spec string) (r requirement, err error) {
+	i := strings.Index(spec, ":")
+	if i < 0 {
+		err = fmt.Errorf("invalid deploy requirement %q; expected the form of kind:target", spec)
+
+		return
+	}
+
+	r.kind = strings.ToLower(strings.TrimSpace(spec[:i]))
+	r.target = strings.TrimSpace(spec[i+1:])
+
+	switch {
+	case r.kind != requirePostgres && r.kind != requireApp && r.kind != requireSecret && r.kind != requireCert:
+		err = fmt.Errorf("invalid deploy requirement %q; kind must be one of postgres, app, secret or cert", spec)
+	case r.target == "":
+		err = fmt.Errorf("invalid deploy requirement %q; target is missing", spec)
+	}
+
+	return
+}
+
+// checkRequirements evaluates the requirements the app config declares,
+// failing with the ones which are not met.
+func checkRequirements(ctx context.Context, appConfig *app.Config) (err error) {
+	reqs, err := requirements(appConfig)
+	if err != nil || len(reqs) == 0 {
+		return err
+	}
+
+	ctx, span := tracing.Start(ctx, "deploy.require")
+	defer func() { tracing.End(span, err) }()
+
+	tb := render.NewTextBlock(ctx, "Checking deploy requirements")
+
+	c := &requirementChecker{
+		client:  client.FromContext(ctx).API(),
+		appName: app.NameFromContext(ctx),
+	}
+
+	var unmet []string
+	for _, r := range reqs {
+		if checkErr := c.check(ctx, r); checkErr != nil {
+			tb.Detailf("%s: %v", r, checkErr)
+
+			unmet = append(unmet, fmt.Sprintf("%s (%v)", r, checkErr))
+
+			continue
+		}
+
+		tb.Detailf("%s: ok", r)
+	}
+
+	if len(unmet) > 0 {
+		return &flyerr.RequirementError{
+			Err: fmt.Errorf("%d of %d deploy requirements are not met: %s", len(unmet), len(reqs), strings.Join(unmet, "; ")),
+		}
+	}
+
+	tb.Done("All deploy requirements are met")
+
+	return nil
+}
+
+// requirementChecker evaluates requirements on behalf of the named app,
+// fetching its secrets and certificates at most once.
+type requirementChecker struct {
+	client  *api.Client
+	appName string
+
+	secrets []api.Secret
+	certs   []api.AppCertificateCompact
+}
+
+func (c *requirementChecker) check(ctx context.Context, r requirement) error {
+	switch r.kind {
+	case requireSecret:
+		return c.checkSecret(ctx, r.target)
+	case requireCert:
+		return c.checkCert(ctx, r.target)
+	default:
+		return c.checkApp(ctx, r.target)
+	}
+}
+
+func (c *requirementChecker) checkSecret(ctx context.Context, name string) (err error) {
+	if c.secrets == nil {
+		if c.secrets, err = c.client.GetAppSecrets(ctx, c.appName); err != nil {
+			return fmt.Errorf("failed fetching secrets: %w", err)
+		}
+	}
+
+	for _, s := range c.secrets {
+		if s.Name == name {
+			return nil
+		}
+	}
+
+	return errors.New("secret is not set")
+}
+
+func (c *requirementChecker) checkCert(ctx context.Context, hostname string) (err error) {
+	if c.certs == nil {
+		if c.certs, err = c.client.GetAppCertificates(ctx, c.appName); err != nil {
+			return fmt.Errorf("failed fetching certificates: %w", err)
+		}
+	}
+
+	for _, cert := range c.certs {
+		if !strings.EqualFold(cert.Hostname, hostname) {
+			continue
+		}
+
+		if cert.ClientStatus != "Ready" {
+			return fmt.Errorf("certificate is %s", strings.ToLower(cert.ClientStatus))
+		}
+
+		return nil
+	}
+
+	return errors.New("no certificate exists for the hostname")
+}
+
+// checkApp checks that the named app, e.g. an attached Postgres cluster, is
+// deployed and healthy.
+func (c *requirementChecker) checkApp(ctx context.Context, appName string) error {
+	compact, err := c.client.GetAppCompact(ctx, appName)
+	if err != nil {
+		if api.IsNotFoundError(err) {
+			return errors.New("app does not exist")
+		}
+
+		return fmt.Errorf("failed fetching app: %w", err)
+	}
+
+	if compact.PlatformVersion == "machines" {
+		machines, err := c.client.ListMachines(ctx, appName, "")
+		if err != nil {
+			return fmt.Errorf("failed listing machines: %w", err)
+		}
+
+		for _, m := range machines {
+			if m.State == "started" {
+				return nil
+			}
+		}
+
+		return errors.New("no machine is started")
+	}
+
+	status, err := c.client.GetAppStatus(ctx, appName, false)
+	if err != nil {
+		return fmt.Errorf("failed fetching app status: %w", err)
+	}
+
+	return allocationsHealthy(status)
+}
+
+func allocationsHealthy(status *api.AppStatus) error {
+	if !status.Deployed || len(status.Allocations) == 0 {
+		return errors.New("app is not deployed")
+	}
+
+	var unhealthy int
+	for _, alloc := range status.Allocations {
+		if !alloc.Healthy || alloc.CriticalCheckCount > 0 {
+			unhealthy++
+		}
+	}
+
+	if unhealthy > 0 {
+		return fmt.Errorf("%d of %d instances are unhealthy", unhealthy, len(status.Allocations))
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package deploy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/flyerr"
+)
+
+func TestRequirements(t *testing.T) {
+	cfg := &app.Config{Definition: map[string]interface{}{
+		"deploy": map[string]interface{}{
+			"require": []interface{}{"postgres:my-db", " Secret : STRIPE_KEY", "cert:www.example.com"},
+		},
+	}}
+
+	reqs, err := requirements(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []requirement{
+		{kind: requirePostgres, target: "my-db"},
+		{kind: requireSecret, target: "STRIPE_KEY"},
+		{kind: requireCert, target: "www.example.com"},
+	}, reqs)
+
+	cfg.Definition["deploy"] = map[string]interface{}{"require": "app:my-api"}
+	reqs, err = requirements(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []requirement{{kind: requireApp, target: "my-api"}}, reqs)
+
+	reqs, err = requirements(&app.Config{Definition: map[string]interface{}{}})
+	assert.NoError(t, err)
+	assert.Empty(t, reqs)
+}
+
+func TestRequirementsInvalid(t *testing.T) {
+	for _, v := range []interface{}{
+		[]interface{}{"STRIPE_KEY"},
+		[]interface{}{"redis:my-cache"},
+		[]interface{}{"secret:"},
+		42,
+	} {
+		cfg := &app.Config{Definition: map[string]interface{}{
+			"deploy": map[string]interface{}{"require": v},
+		}}
+
+		_, err := requirements(cfg)
+
+		var verr *flyerr.ValidationError
+		assert.True(t, errors.As(err, &verr), "%v", v)
+	}
+}
+
+func TestAllocationsHealthy(t *testing.T) {
+	assert.Error(t, allocationsHealthy(&api.AppStatus{}))
+
+	status := &api.AppStatus{
+		Deployed: true,
+		Allocations: []*api.AllocationStatus{
+			{Healthy: true},
+			{Healthy: true, CriticalCheckCount: 1},
+		},
+	}
+	assert.EqualError(t, allocationsHealthy(status), "1 of 2 instances are unhealthy")
+
+	status.Allocations[1].CriticalCheckCount = 0
+	assert.NoError(t, allocationsHealthy(status))
+}
@@ -0,0 +1,137 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/superfly/flyctl/internal/build/imgsrc"
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/cli/internal/state"
+	"github.com/superfly/flyctl/internal/deployment"
+)
+
+// deployTimeLayouts are the layouts --at accepts. Times without a zone are
+// local.
+var deployTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// parseDeployTime parses the time --at denotes, which must be after now.
+func parseDeployTime(s string, now time.Time) (time.Time, error) {
+	for _, layout := range deployTimeLayouts {
+		at, err := time.ParseInLocation(layout, s, now.Location())
+		if err != nil {
+			continue
+		}
+
+		if !at.After(now) {
+			return time.Time{}, fmt.Errorf("--at %s is in the past", s)
+		}
+
+		return at, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid --at %q: expected a time such as 2024-01-01T02:00Z", s)
+}
+
+// unforwardedFlags are the flags of the deploy command which scheduled
+// deployments don't pass on, since they concern the build which has already
+// happened or are set explicitly.
+var unforwardedFlags = map[string]bool{
+	"at":                       true,
+	flag.NowName:               true,
+	flag.AppName:               true,
+	flag.AppConfigFilePathName: true,
+	flag.ImageName:             true,
+	"remote-only":              true,
+	"local-only":               true,
+	"build-only":               true,
+	"dockerfile":               true,
+	"image-label":              true,
+	"build-arg":                true,
+	"build-arg-file":           true,
+	"build-target":             true,
+	"no-cache":                 true,
+	"ssh":                      true,
+	"bundle-install":           true,
+	"cache-path":               true,
+	"build-stall-timeout":      true,
+	"auto-recover-builder":     true,
+	"upload-limit":             true,
+	"nix":                      true,
+	"show-diff":                true,
+}
+
+// scheduledArgs returns the arguments of the deploy command which deploys img
+// on behalf of a scheduled deployment.
+func scheduledArgs(ctx context.Context, appConfig *app.Config, img *imgsrc.DeploymentImage) ([]string, error) {
+	args := []string{
+		"deploy",
+		"--" + flag.AppName, app.NameFromContext(ctx),
+		"--" + flag.ImageName, img.Tag,
+		"--" + flag.NowName,
+	}
+
+	if appConfig.Path != "" {
+		path, err := filepath.Abs(appConfig.Path)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--"+flag.AppConfigFilePathName, path)
+	}
+
+	command.FromContext(ctx).Flags().Visit(func(f *pflag.Flag) {
+		if unforwardedFlags[f.Name] {
+			return
+		}
+
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range sv.GetSlice() {
+				args = append(args, fmt.Sprintf("--%s=%s", f.Name, v))
+			}
+
+			return
+		}
+
+		args = append(args, fmt.Sprintf("--%s=%s", f.Name, f.Value.String()))
+	})
+
+	return args, nil
+}
+
+// schedule schedules the deployment of the built img for the given time.
+func schedule(ctx context.Context, at time.Time, appConfig *app.Config, img *imgsrc.DeploymentImage) error {
+	args, err := scheduledArgs(ctx, appConfig, img)
+	if err != nil {
+		return err
+	}
+
+	d := &deployment.Scheduled{
+		AppName: app.NameFromContext(ctx),
+		Image:   img.Tag,
+		At:      at.UTC(),
+		Args:    args,
+	}
+
+	if err := deployment.NewSchedule(state.ConfigDirectory(ctx)).Add(ctx, d); err != nil {
+		return fmt.Errorf("failed scheduling deployment: %w", err)
+	}
+
+	tb := render.NewTextBlock(ctx, "Scheduling deployment")
+	tb.Detailf("Image %s will be deployed at %s (%s local time)", img.Tag, d.At.Format(time.RFC3339), at.Local().Format("2006-01-02 15:04"))
+	tb.Detailf("This machine must stay on and online until then; the deployment log will be written to %s", d.LogFile)
+	tb.Donef("Scheduled deployment %s; cancel it with 'fly deploys scheduled cancel %s'", d.ID, d.ID)
+
+	return nil
+}
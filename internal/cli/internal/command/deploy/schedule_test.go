@@ -0,0 +1,30 @@
+package deploy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDeployTime(t *testing.T) {
+	now := time.Date(2023, 12, 31, 12, 0, 0, 0, time.UTC)
+
+	at, err := parseDeployTime("2024-01-01T02:00Z", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC), at)
+
+	at, err = parseDeployTime("2024-01-01T02:00:30+01:00", now)
+	assert.NoError(t, err)
+	assert.True(t, at.Equal(time.Date(2024, 1, 1, 1, 0, 30, 0, time.UTC)))
+
+	at, err = parseDeployTime("2024-01-01 02:00", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC), at)
+
+	_, err = parseDeployTime("2023-12-31T11:59Z", now)
+	assert.Error(t, err)
+
+	_, err = parseDeployTime("tomorrow", now)
+	assert.Error(t, err)
+}
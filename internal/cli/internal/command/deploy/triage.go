@@ -0,0 +1,417 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/skratchdot/open-golang/open"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/buildinfo"
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/prompt"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/cli/internal/watch"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/env"
+	"github.com/superfly/flyctl/internal/flyerr"
+	"github.com/superfly/flyctl/pkg/iostreams"
+)
+
+const (
+	// triageLogLimit is the number of recent log lines triage fetches per
+	// failing instance.
+	triageLogLimit = 50
+
+	// supportLogLines is the number of log lines of each failing instance
+	// community posts include.
+	supportLogLines = 20
+
+	// maxSupportBodyLen caps the length of the body of community posts so that
+	// the URL which pre-fills them stays within what browsers accept.
+	maxSupportBodyLen = 6000
+
+	supportPostURL = "https://community.fly.io/new-topic"
+)
+
+// failedDeployment wraps the state triage of a failed deployment operates on.
+type failedDeployment struct {
+	appName   string
+	appConfig *app.Config
+	image     string
+	release   *api.Release
+	err       error
+
+	// instances are the failing instances of the release along with their
+	// recent logs.
+	instances []*api.AllocationStatus
+
+	// stable is the latest stable release before the failed one, if any.
+	stable *api.Release
+}
+
+// shouldTriage reports whether failed deployments offer the triage menu,
+// which they only do for interactive sessions outside of CI.
+func shouldTriage(ctx context.Context) bool {
+	return iostreams.FromContext(ctx).IsInteractive() &&
+		!config.FromContext(ctx).JSONOutput &&
+		!env.IsCI() &&
+		!flag.GetBool(ctx, "no-triage")
+}
+
+// triageFailure offers a menu of ways to diagnose and recover from the failure
+// of the given release to become healthy. It returns err unless the chosen
+// way recovers the app.
+func triageFailure(ctx context.Context, appConfig *app.Config, image string, release *api.Release, err error) error {
+	var hc *flyerr.HealthCheckError
+	if !errors.As(err, &hc) || !shouldTriage(ctx) {
+		return err
+	}
+
+	f := &failedDeployment{
+		appName:   app.NameFromContext(ctx),
+		appConfig: appConfig,
+		image:     image,
+		release:   release,
+		err:       err,
+	}
+
+	out := iostreams.FromContext(ctx).ErrOut
+
+	// triage is best effort; failing to gather the state of the app leaves
+	// out the options which depend on it
+	if f.instances, err = failingInstances(ctx, f.appName, release.Version); err != nil {
+		fmt.Fprintf(out, "WARNING: failed retrieving the failing instances of v%d: %v\n", release.Version, err)
+	}
+
+	// the platform rolls back on its own when it can
+	var rollback *watch.RollbackError
+	if !errors.As(f.err, &rollback) {
+		if f.stable, err = lastStableRelease(ctx, f.appName, release.Version); err != nil {
+			fmt.Fprintf(out, "WARNING: failed retrieving the releases of %s: %v\n", f.appName, err)
+		}
+	}
+
+	for {
+		options, actions := f.options()
+
+		var index int
+		if err := prompt.Select(ctx, &index, "The deployment failed. What now?", "", options...); err != nil {
+			return f.err
+		}
+
+		done, err := actions[index](ctx)
+		if done {
+			return err
+		} else if err != nil {
+			fmt.Fprintf(out, "%v\n", err)
+		}
+	}
+}
+
+// triageAction is an option of the triage menu. It reports whether triage is
+// done, with the error the deployment ends with.
+type triageAction func(context.Context) (bool, error)
+
+func (f *failedDeployment) options() (options []string, actions []triageAction) {
+	add := func(option string, action triageAction) {
+		options = append(options, option)
+		actions = append(actions, action)
+	}
+
+	if len(f.instances) > 0 {
+		add("View the logs of the failing instances", f.viewLogs)
+		add("Open a shell on a failing instance", f.openShell)
+	}
+
+	add("Retry with the immediate strategy", f.retryImmediate)
+
+	if f.stable != nil {
+		add(fmt.Sprintf("Roll back to v%d, the last stable release", f.stable.Version), f.rollBack)
+	}
+
+	add("Open a community post with the diagnostics of the deployment", f.openSupportPost)
+	add("Exit", func(context.Context) (bool, error) {
+		return true, f.err
+	})
+
+	return
+}
+
+func (f *failedDeployment) viewLogs(ctx context.Context) (bool, error) {
+	out := iostreams.FromContext(ctx).Out
+
+	for _, alloc := range f.instances {
+		fmt.Fprintf(out, "\nInstance %s [%s] %s:\n", alloc.IDShort, alloc.TaskName, instanceFailure(alloc))
+
+		if len(alloc.RecentLogs) == 0 {
+			fmt.Fprintln(out, "  no recent logs")
+
+			continue
+		}
+
+		watch.RenderLogs(ctx, alloc)
+	}
+
+	fmt.Fprintln(out)
+
+	return false, nil
+}
+
+func (f *failedDeployment) openShell(ctx context.Context) (bool, error) {
+	alloc := f.instances[0]
+
+	if len(f.instances) > 1 {
+		options := make([]string, 0, len(f.instances))
+		for _, alloc := range f.instances {
+			options = append(options, fmt.Sprintf("%s [%s] in %s, %s", alloc.IDShort, alloc.TaskName, alloc.Region, instanceFailure(alloc)))
+		}
+
+		var index int
+		if err := prompt.Select(ctx, &index, "Which instance?", "", options...); err != nil {
+			return false, err
+		}
+		alloc = f.instances[index]
+	}
+
+	if alloc.PrivateIP == "" {
+		return false, fmt.Errorf("instance %s has no private IP to connect to", alloc.IDShort)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return false, fmt.Errorf("failed locating flyctl: %w", err)
+	}
+
+	io := iostreams.FromContext(ctx)
+
+	cmd := exec.CommandContext(ctx, exe, "ssh", "console", "--app", f.appName, alloc.PrivateIP)
+	cmd.Stdin = io.In
+	cmd.Stdout = io.Out
+	cmd.Stderr = io.ErrOut
+
+	// instances which crash end the session; the menu is shown again
+	if err := cmd.Run(); err != nil {
+		return false, fmt.Errorf("shell on instance %s ended: %w", alloc.IDShort, err)
+	}
+
+	return false, nil
+}
+
+func (f *failedDeployment) retryImmediate(ctx context.Context) (bool, error) {
+	tb := render.NewTextBlock(ctx, "Retrying with the immediate strategy")
+
+	input := api.DeployImageInput{
+		AppID:    f.appName,
+		Image:    f.image,
+		Strategy: api.StringPointer("IMMEDIATE"),
+	}
+
+	if len(f.appConfig.Definition) > 0 {
+		input.Definition = api.DefinitionPtr(f.appConfig.Definition)
+	}
+
+	if only := flag.GetStringSlice(ctx, "only-process"); len(only) > 0 {
+		input.ProcessGroups = only
+	}
+
+	release, _, err := client.FromContext(ctx).API().DeployImage(ctx, input)
+	if err != nil {
+		return false, fmt.Errorf("failed retrying the deployment: %w", err)
+	}
+
+	tb.Donef("release v%d created; its instances replace the running ones without waiting for health checks\n", release.Version)
+
+	return true, nil
+}
+
+func (f *failedDeployment) rollBack(ctx context.Context) (bool, error) {
+	tb := render.NewTextBlock(ctx, fmt.Sprintf("Rolling back to v%d", f.stable.Version))
+
+	apiClient := client.FromContext(ctx).API()
+
+	stable, err := apiClient.GetAppReleaseByVersion(ctx, f.appName, f.stable.Version)
+	if err != nil {
+		return false, fmt.Errorf("failed retrieving release v%d: %w", f.stable.Version, err)
+	} else if stable.ImageRef == "" {
+		return false, fmt.Errorf("release v%d has no image to roll back to", stable.Version)
+	}
+
+	input := api.DeployImageInput{
+		AppID: f.appName,
+		Image: stable.ImageRef,
+	}
+
+	if stable.Config != nil && len(stable.Config.Definition) > 0 {
+		input.Definition = api.DefinitionPtr(stable.Config.Definition)
+	}
+
+	release, _, err := apiClient.DeployImage(ctx, input)
+	if err != nil {
+		return false, fmt.Errorf("failed rolling back to v%d: %w", stable.Version, err)
+	}
+
+	tb.Donef("release v%d created with the image and config of v%d\n", release.Version, stable.Version)
+
+	if release.DeploymentStrategy == "IMMEDIATE" {
+		return true, nil
+	}
+
+	return true, watch.Deployment(ctx, release.EvaluationID)
+}
+
+func (f *failedDeployment) openSupportPost(ctx context.Context) (bool, error) {
+	out := iostreams.FromContext(ctx).ErrOut
+
+	u := supportPostLink(f.diagnostics())
+
+	fmt.Fprintf(out, "Opening a community post with the diagnostics of the deployment; review it before posting, as logs may include sensitive data:\n%s\n", u)
+
+	if err := open.Run(u); err != nil {
+		fmt.Fprintf(out, "failed opening the browser: %v\n", err)
+	}
+
+	return false, nil
+}
+
+// diagnostics returns the Markdown body of community posts about f.
+func (f *failedDeployment) diagnostics() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "My deployment of %s failed.\n\n", f.appName)
+	fmt.Fprintf(&b, "### Deployment\n\n")
+	fmt.Fprintf(&b, "- Release: v%d (%s strategy)\n", f.release.Version, strings.ToLower(f.release.DeploymentStrategy))
+	fmt.Fprintf(&b, "- Image: %s\n", f.image)
+	fmt.Fprintf(&b, "- flyctl: %s (%s/%s)\n", buildinfo.Version(), buildinfo.OS(), buildinfo.Arch())
+	fmt.Fprintf(&b, "- Error: %v\n", f.err)
+
+	if len(f.instances) == 0 {
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "\n### Failing instances\n")
+
+	for _, alloc := range f.instances {
+		fmt.Fprintf(&b, "\n%s [%s] in %s %s\n", alloc.IDShort, alloc.TaskName, alloc.Region, instanceFailure(alloc))
+
+		logs := alloc.RecentLogs
+		if len(logs) > supportLogLines {
+			logs = logs[len(logs)-supportLogLines:]
+		}
+
+		if len(logs) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n```\n")
+		for _, e := range logs {
+			fmt.Fprintf(&b, "%s [%s] %s\n", e.Timestamp, e.Level, e.Message)
+		}
+		fmt.Fprintf(&b, "```\n")
+	}
+
+	return b.String()
+}
+
+// supportPostLink returns the URL of the community post pre-filled with the
+// given body, truncating it so that the URL stays short enough for browsers.
+func supportPostLink(body string) string {
+	if len(body) > maxSupportBodyLen {
+		const suffix = "\n\n(truncated)\n"
+
+		body = body[:maxSupportBodyLen-len(suffix)]
+
+		// leave code blocks which the truncation cuts short closed
+		if strings.Count(body, "```")%2 == 1 {
+			body += "\n```"
+		}
+		body += suffix
+	}
+
+	values := url.Values{
+		"title":    {"Deployment failed"},
+		"body":     {body},
+		"category": {"questions-help"},
+	}
+
+	return supportPostURL + "?" + values.Encode()
+}
+
+// failingInstances returns the instances of the given version of the named
+// app which failed or are unhealthy, along with their recent logs, sorted by
+// ID.
+func failingInstances(ctx context.Context, appName string, version int) ([]*api.AllocationStatus, error) {
+	apiClient := client.FromContext(ctx).API()
+
+	status, err := apiClient.GetAppStatus(ctx, appName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var failing []*api.AllocationStatus
+	for _, alloc := range status.Allocations {
+		if alloc.Version != version || !isFailing(alloc) {
+			continue
+		}
+
+		if logged, err := apiClient.GetAllocationStatus(ctx, appName, alloc.ID, triageLogLimit); err == nil {
+			alloc.RecentLogs = logged.RecentLogs
+		}
+
+		failing = append(failing, alloc)
+	}
+
+	sort.Slice(failing, func(i, j int) bool {
+		return failing[i].ID < failing[j].ID
+	})
+
+	return failing, nil
+}
+
+func isFailing(alloc *api.AllocationStatus) bool {
+	return alloc.Failed || alloc.Status == "failed" || alloc.Restarts > 0 || alloc.CriticalCheckCount > 0 || !alloc.Healthy
+}
+
+func instanceFailure(alloc *api.AllocationStatus) string {
+	switch {
+	case alloc.Failed || alloc.Status == "failed":
+		return "failed"
+	case alloc.Restarts > 0:
+		return fmt.Sprintf("crashed (%d restarts)", alloc.Restarts)
+	case alloc.CriticalCheckCount > 0:
+		return fmt.Sprintf("is failing %d health checks", alloc.CriticalCheckCount)
+	default:
+		return fmt.Sprintf("is %s", alloc.Status)
+	}
+}
+
+// lastStableRelease returns the latest stable release of the named app before
+// the given version, or nil in case there is none.
+func lastStableRelease(ctx context.Context, appName string, version int) (*api.Release, error) {
+	releases, err := client.FromContext(ctx).API().GetAppReleases(ctx, appName, 25)
+	if err != nil {
+		return nil, err
+	}
+
+	return stableBefore(releases, version), nil
+}
+
+func stableBefore(releases []api.Release, version int) (stable *api.Release) {
+	for i := range releases {
+		release := &releases[i]
+
+		if release.Stable && release.Version < version && (stable == nil || release.Version > stable.Version) {
+			stable = release
+		}
+	}
+
+	return
+}
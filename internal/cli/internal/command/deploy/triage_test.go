@@ -0,0 +1,74 @@
+package deploy
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestStableBefore(t *testing.T) {
+	releases := []api.Release{
+		{Version: 12},
+		{Version: 11, Stable: true},
+		{Version: 10},
+		{Version: 9, Stable: true},
+	}
+
+	assert.Equal(t, 11, stableBefore(releases, 12).Version)
+	assert.Equal(t, 9, stableBefore(releases, 11).Version)
+	assert.Nil(t, stableBefore(releases, 9))
+}
+
+func TestSupportPostLink(t *testing.T) {
+	link := supportPostLink("body")
+
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, "community.fly.io", u.Host)
+	assert.Equal(t, "body", u.Query().Get("body"))
+	assert.Equal(t, "Deployment failed", u.Query().Get("title"))
+
+	long := "```\n" + strings.Repeat("log line\n", 1000)
+
+	u, err = url.Parse(supportPostLink(long))
+	require.NoError(t, err)
+
+	body := u.Query().Get("body")
+	assert.LessOrEqual(t, len(body), maxSupportBodyLen+4)
+	assert.True(t, strings.HasSuffix(body, "```\n\n(truncated)\n"))
+	assert.Equal(t, 0, strings.Count(body, "```")%2)
+}
+
+func TestDiagnostics(t *testing.T) {
+	f := &failedDeployment{
+		appName: "app",
+		image:   "registry.fly.io/app:deployment-1",
+		release: &api.Release{Version: 3, DeploymentStrategy: "ROLLING"},
+		err:     errors.New("health checks failed"),
+		instances: []*api.AllocationStatus{
+			{
+				IDShort:  "abcd1234",
+				TaskName: "app",
+				Region:   "iad",
+				Restarts: 2,
+				RecentLogs: []api.LogEntry{
+					{Timestamp: "2022-01-01T00:00:00Z", Level: "info", Message: "listening on :3000"},
+				},
+			},
+		},
+	}
+
+	diagnostics := f.diagnostics()
+
+	assert.Contains(t, diagnostics, "- Release: v3 (rolling strategy)\n")
+	assert.Contains(t, diagnostics, "- Image: registry.fly.io/app:deployment-1\n")
+	assert.Contains(t, diagnostics, "- Error: health checks failed\n")
+	assert.Contains(t, diagnostics, "abcd1234 [app] in iad crashed (2 restarts)\n")
+	assert.Contains(t, diagnostics, "2022-01-01T00:00:00Z [info] listening on :3000\n")
+}
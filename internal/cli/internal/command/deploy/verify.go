@@ -0,0 +1,369 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azazeal/pause"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/agent"
+	"github.com/superfly/flyctl/pkg/machines"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command/ssh"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/flyerr"
+	"github.com/superfly/flyctl/internal/tracing"
+)
+
+// The kinds of the runtime assertions new instances may be verified against.
+const (
+	assertPort  = "port"
+	assertEnv   = "env"
+	assertMount = "mount"
+)
+
+const (
+	// runtimeVerifyGrace is how long the assertions new instances fail are
+	// retried for, as their processes may still be starting.
+	runtimeVerifyGrace = 30 * time.Second
+
+	runtimeVerifyInterval = 2 * time.Second
+)
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// runtimeAssertion wraps an assertion about the runtime of new instances, as
+// declared by the verify setting of the [deploy] section of the app config,
+// e.g. "mount:/data".
+type runtimeAssertion struct {
+	kind   string
+	target string
+}
+
+func (a runtimeAssertion) String() string {
+	return a.kind + ":" + a.target
+}
+
+// script returns the shell script which exits successfully when a holds on
+// the instance it runs on.
+func (a runtimeAssertion) script() string {
+	switch a.kind {
+	case assertPort:
+		// sockets which listen are in state 0A, their ports in hex
+		port, _ := strconv.Atoi(a.target)
+
+		return fmt.Sprintf("grep -q ':%04X [0-9A-F]*:[0-9A-F]* 0A ' /proc/net/tcp /proc/net/tcp6", port)
+	case assertEnv:
+		// the environment of SSH sessions may differ from the app's
+		return fmt.Sprintf(`[ -n "${%[1]s}" ] || grep -qz '^%[1]s=' /proc/1/environ`, a.target)
+	default:
+		return fmt.Sprintf("grep -q ' %s ' /proc/mounts", a.target)
+	}
+}
+
+// runtimeAssertions returns the runtime assertions the app config declares. A
+// port assertion without a port stands for the internal ports of all of the
+// services of the app.
+func runtimeAssertions(appConfig *app.Config) (assertions []runtimeAssertion, err error) {
+	deploy, ok := appConfig.Definition["deploy"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	var specs []string
+	switch v := deploy["verify"].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		specs = append(specs, v)
+	case []interface{}:
+		for _, s := range v {
+			specs = append(specs, fmt.Sprint(s))
+		}
+	default:
+		return nil, &flyerr.ValidationError{
+			Err: errors.New("the verify setting of the [deploy] section must be a list of strings"),
+		}
+	}
+
+	for _, spec := range specs {
+		if strings.EqualFold(strings.TrimSpace(spec), assertPort) {
+			ports := internalPorts(appConfig.Definition)
+			if len(ports) == 0 {
+				return nil, &flyerr.ValidationError{
+					Err: fmt.Errorf("invalid runtime assertion %q; the app config declares no services the internal ports of which to verify", spec),
+				}
+			}
+
+			for _, port := range ports {
+				assertions = append(assertions, runtimeAssertion{kind: assertPort, target: strconv.Itoa(port)})
+			}
+
+			continue
+		}
+
+		var a runtimeAssertion
+		if a, err = parseRuntimeAssertion(spec); err != nil {
+			return nil, &flyerr.ValidationError{Err: err}
+		}
+
+		assertions = append(assertions, a)
+	}
+
+	return
+}
+
+func parseRuntimeAssertion(spec string) (a runtimeAssertion, err error) {
+	i := strings.Index(spec, ":")
+	if i < 0 {
+		err = fmt.Errorf("invalid runtime assertion %q; expected the form of kind:target", spec)
+
+		return
+	}
+
+	a.kind = strings.ToLower(strings.TrimSpace(spec[:i]))
+	a.target = strings.TrimSpace(spec[i+1:])
+
+	switch a.kind {
+	case assertPort:
+		if port, convErr := strconv.Atoi(a.target); convErr != nil || port < 1 || port > 65535 {
+			err = fmt.Errorf("invalid runtime assertion %q; port must be between 1 and 65535", spec)
+		}
+	case assertEnv:
+		if !envNamePattern.MatchString(a.target) {
+			err = fmt.Errorf("invalid runtime assertion %q; %q is not a valid environment variable name", spec, a.target)
+		}
+	case assertMount:
+		if !strings.HasPrefix(a.target, "/") || strings.ContainsAny(a.target, " \t\n'\"\\") {
+			err = fmt.Errorf("invalid runtime assertion %q; mount must be an absolute path without whitespace or quotes", spec)
+		} else if len(a.target) > 1 {
+			a.target = strings.TrimSuffix(a.target, "/")
+		}
+	default:
+		err = fmt.Errorf("invalid runtime assertion %q; kind must be one of port, env or mount", spec)
+	}
+
+	return
+}
+
+// internalPorts returns the distinct internal ports of the services the given
+// definition declares.
+func internalPorts(definition map[string]interface{}) (ports []int) {
+	services, _ := definition["services"].([]interface{})
+
+	seen := map[int]bool{}
+	for _, s := range services {
+		service, _ := s.(map[string]interface{})
+
+		var port int
+		switch v := service["internal_port"].(type) {
+		case int64:
+			port = int(v)
+		case float64:
+			port = int(v)
+		case int:
+			port = v
+		}
+
+		if port > 0 && !seen[port] {
+			seen[port] = true
+			ports = append(ports, port)
+		}
+	}
+
+	return
+}
+
+// runtimeTarget wraps an instance runtime assertions run on.
+type runtimeTarget struct {
+	id     string
+	region string
+	run    func(ctx context.Context, script string) error
+}
+
+// verifyRuntime verifies the given assertions on the new instances of the app
+// of ctx: the allocations of release or, absent a release, the started
+// machines which run image. Assertions which fail are retried for
+// runtimeVerifyGrace.
+func verifyRuntime(ctx context.Context, assertions []runtimeAssertion, release *api.Release, image string) (err error) {
+	ctx, span := tracing.Start(ctx, "deploy.verify")
+	defer func() { tracing.End(span, err) }()
+
+	var targets []runtimeTarget
+	if release == nil {
+		targets, err = machineTargets(ctx, image)
+	} else {
+		targets, err = allocationTargets(ctx, release.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	tb := render.NewTextBlock(ctx, fmt.Sprintf("Verifying %d runtime assertions on %d instances", len(assertions), len(targets)))
+
+	deadline := time.Now().Add(runtimeVerifyGrace)
+
+	var failed []string
+	for _, t := range targets {
+		for _, a := range assertions {
+			checkErr := t.run(ctx, a.script())
+			for checkErr != nil && time.Now().Before(deadline) {
+				if pause.For(ctx, runtimeVerifyInterval); ctx.Err() != nil {
+					return ctx.Err()
+				}
+
+				checkErr = t.run(ctx, a.script())
+			}
+
+			if checkErr != nil {
+				tb.Detailf("%s [%s] %s: %v", t.id, t.region, a, checkErr)
+
+				failed = append(failed, fmt.Sprintf("%s on %s", a, t.id))
+
+				continue
+			}
+
+			tb.Detailf("%s [%s] %s: ok", t.id, t.region, a)
+		}
+	}
+
+	if len(failed) > 0 {
+		return &flyerr.RuntimeAssertionError{
+			Err: fmt.Errorf("%d runtime assertions failed: %s", len(failed), strings.Join(failed, "; ")),
+		}
+	}
+
+	tb.Done("All runtime assertions hold")
+
+	return nil
+}
+
+// allocationTargets returns the running allocations of the given version of
+// the app of ctx, which assertions run on over SSH.
+func allocationTargets(ctx context.Context, version int) ([]runtimeTarget, error) {
+	var (
+		appName   = app.NameFromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+	)
+
+	status, err := apiClient.GetAppStatus(ctx, appName, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching app status: %w", err)
+	}
+
+	var allocs []*api.AllocationStatus
+	for _, alloc := range status.Allocations {
+		if alloc.Version == version && alloc.Status == "running" && alloc.PrivateIP != "" {
+			allocs = append(allocs, alloc)
+		}
+	}
+
+	if len(allocs) == 0 {
+		return nil, &flyerr.RuntimeAssertionError{
+			Err: fmt.Errorf("no instances of v%d are running to verify", version),
+		}
+	}
+
+	appInfo, err := apiClient.GetApp(ctx, appName)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching app: %w", err)
+	}
+
+	agentClient, err := agent.Establish(ctx, apiClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed establishing agent: %w", err)
+	}
+
+	dialer, err := agentClient.Dialer(ctx, appInfo.Organization.Slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed building tunnel for %s: %w", appInfo.Organization.Slug, err)
+	}
+
+	if err := agentClient.WaitForTunnel(ctx, appInfo.Organization.Slug); err != nil {
+		return nil, fmt.Errorf("tunnel unavailable: %w", err)
+	}
+
+	targets := make([]runtimeTarget, 0, len(allocs))
+	for _, alloc := range allocs {
+		addr := alloc.PrivateIP
+
+		targets = append(targets, runtimeTarget{
+			id:     alloc.IDShort,
+			region: alloc.Region,
+			run: func(ctx context.Context, script string) error {
+				_, err := ssh.RunSSHCommand(ctx, appInfo, dialer, &addr, "sh -c "+shellQuote(script))
+
+				return err
+			},
+		})
+	}
+
+	return targets, nil
+}
+
+// deployedMachines returns the given machines which are started and run
+// image, leaving out the ones a deployment skipped or hasn't reached.
+func deployedMachines(all []*api.Machine, image string) (deployed []*api.Machine) {
+	for _, m := range all {
+		if m.State == "started" && m.Config.Image == image {
+			deployed = append(deployed, m)
+		}
+	}
+
+	return
+}
+
+// machineTargets returns the started machines of the app of ctx which run the
+// deployed image, which assertions run on through the exec endpoint of the
+// Machines API.
+func machineTargets(ctx context.Context, image string) ([]runtimeTarget, error) {
+	appName := app.NameFromContext(ctx)
+
+	all, err := client.FromContext(ctx).API().ListMachines(ctx, appName, "started")
+	if err != nil {
+		return nil, fmt.Errorf("failed listing machines: %w", err)
+	}
+
+	execClient := machines.NewExecClient(config.FromContext(ctx).AccessToken)
+
+	var targets []runtimeTarget
+	for _, m := range deployedMachines(all, image) {
+		id := m.ID
+
+		targets = append(targets, runtimeTarget{
+			id:     id,
+			region: m.Region,
+			run: func(ctx context.Context, script string) error {
+				res, err := execClient.Exec(ctx, appName, id, []string{"sh", "-c", script}, machines.DefaultExecTimeout)
+				if err != nil {
+					return err
+				} else if res.ExitCode != 0 {
+					return fmt.Errorf("exited with %d %s", res.ExitCode, strings.TrimSpace(res.Stderr))
+				}
+
+				return nil
+			},
+		})
+	}
+
+	if len(targets) == 0 {
+		return nil, &flyerr.RuntimeAssertionError{
+			Err: fmt.Errorf("no started machines of %s run %s to verify", appName, image),
+		}
+	}
+
+	return targets, nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
@@ -0,0 +1,88 @@
+package deploy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/flyerr"
+)
+
+func TestRuntimeAssertions(t *testing.T) {
+	cfg := &app.Config{Definition: map[string]interface{}{
+		"services": []interface{}{
+			map[string]interface{}{"internal_port": int64(8080)},
+			map[string]interface{}{"internal_port": int64(8080)},
+			map[string]interface{}{"internal_port": int64(9000)},
+		},
+		"deploy": map[string]interface{}{
+			"verify": []interface{}{"port", " Env : DATABASE_URL", "mount:/data/", "port:9091"},
+		},
+	}}
+
+	assertions, err := runtimeAssertions(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []runtimeAssertion{
+		{kind: assertPort, target: "8080"},
+		{kind: assertPort, target: "9000"},
+		{kind: assertEnv, target: "DATABASE_URL"},
+		{kind: assertMount, target: "/data"},
+		{kind: assertPort, target: "9091"},
+	}, assertions)
+
+	assertions, err = runtimeAssertions(&app.Config{Definition: map[string]interface{}{}})
+	assert.NoError(t, err)
+	assert.Empty(t, assertions)
+}
+
+func TestRuntimeAssertionsInvalid(t *testing.T) {
+	for _, v := range []interface{}{
+		[]interface{}{"port"},
+		[]interface{}{"port:http"},
+		[]interface{}{"port:70000"},
+		[]interface{}{"env:DATABASE-URL"},
+		[]interface{}{"env:$(reboot)"},
+		[]interface{}{"mount:data"},
+		[]interface{}{"mount:/my data"},
+		[]interface{}{"disk:/data"},
+		42,
+	} {
+		cfg := &app.Config{Definition: map[string]interface{}{
+			"deploy": map[string]interface{}{"verify": v},
+		}}
+
+		_, err := runtimeAssertions(cfg)
+
+		var verr *flyerr.ValidationError
+		assert.True(t, errors.As(err, &verr), "%v", v)
+	}
+}
+
+func TestRuntimeAssertionScript(t *testing.T) {
+	assert.Equal(t, "grep -q ':1F90 [0-9A-F]*:[0-9A-F]* 0A ' /proc/net/tcp /proc/net/tcp6",
+		runtimeAssertion{kind: assertPort, target: "8080"}.script())
+	assert.Equal(t, `[ -n "${DATABASE_URL}" ] || grep -qz '^DATABASE_URL=' /proc/1/environ`,
+		runtimeAssertion{kind: assertEnv, target: "DATABASE_URL"}.script())
+	assert.Equal(t, "grep -q ' /data ' /proc/mounts",
+		runtimeAssertion{kind: assertMount, target: "/data"}.script())
+
+	assert.Equal(t, `'echo '"'"'hi'"'"''`, shellQuote("echo 'hi'"))
+}
+
+func TestDeployedMachines(t *testing.T) {
+	const image = "registry.fly.io/app:deployment-2"
+
+	all := []*api.Machine{
+		{ID: "new", State: "started", Config: api.MachineConfig{Image: image}},
+		{ID: "old", State: "started", Config: api.MachineConfig{Image: "registry.fly.io/app:deployment-1"}},
+		{ID: "stopped", State: "stopped", Config: api.MachineConfig{Image: image}},
+	}
+
+	deployed := deployedMachines(all, image)
+	require.Len(t, deployed, 1)
+	assert.Equal(t, "new", deployed[0].ID)
+}
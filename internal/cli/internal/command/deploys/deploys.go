@@ -0,0 +1,211 @@
+// Package deploys implements the deploys command chain.
+package deploys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/cli/internal/state"
+	"github.com/superfly/flyctl/internal/deployment"
+	"github.com/superfly/flyctl/internal/flyerr"
+)
+
+// New initializes and returns a new deploys Command.
+func New() (cmd *cobra.Command) {
+	const (
+		long = `Commands that manage deployments.
+`
+		short = "Manage deployments"
+	)
+
+	cmd = command.New("deploys", short, long, nil)
+
+	cmd.AddCommand(
+		newScheduled(),
+	)
+
+	return
+}
+
+func newScheduled() (cmd *cobra.Command) {
+	const (
+		long = `Commands that manage the deployments scheduled via deploy --at.
+
+Scheduled deployments are run by a background flyctl process on the machine
+they were scheduled from, which must stay on and online until they're due.
+Deployments the processes of which are gone, e.g. since the machine
+restarted, are listed as not running.
+`
+		short = "Manage scheduled deployments"
+	)
+
+	cmd = command.New("scheduled", short, long, nil)
+
+	cmd.AddCommand(
+		newScheduledList(),
+		newScheduledCancel(),
+		newScheduledRun(),
+	)
+
+	return
+}
+
+func newScheduledList() (cmd *cobra.Command) {
+	const (
+		long = `List the deployments scheduled from this machine, optionally of a single
+app only.
+`
+		short = "List scheduled deployments"
+	)
+
+	cmd = command.New("list", short, long, runScheduledList)
+
+	cmd.Aliases = []string{"ls"}
+
+	flag.Add(cmd,
+		flag.App(),
+	)
+
+	return
+}
+
+// waiterStartupGrace is how long after being scheduled deployments are
+// considered waited for, while their waiter starts.
+const waiterStartupGrace = time.Minute
+
+// scheduledStatus wraps a scheduled deployment with the status of the
+// process waiting to run it.
+type scheduledStatus struct {
+	*deployment.Scheduled
+	WaiterRunning bool `json:"waiter_running"`
+}
+
+func runScheduledList(ctx context.Context) error {
+	schedule := deployment.NewSchedule(state.ConfigDirectory(ctx))
+
+	scheduled, err := schedule.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	if appName := flag.GetApp(ctx); appName != "" {
+		filtered := scheduled[:0]
+		for _, d := range scheduled {
+			if d.AppName == appName {
+				filtered = append(filtered, d)
+			}
+		}
+		scheduled = filtered
+	}
+
+	statuses := make([]scheduledStatus, 0, len(scheduled))
+	for _, d := range scheduled {
+		running, err := schedule.WaiterRunning(d)
+		if err != nil {
+			return fmt.Errorf("failed checking the waiter of %s: %w", d.ID, err)
+		}
+
+		statuses = append(statuses, scheduledStatus{
+			Scheduled:     d,
+			WaiterRunning: running || time.Since(d.CreatedAt) < waiterStartupGrace,
+		})
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	if cfg := config.FromContext(ctx); cfg.JSONOutput {
+		return render.JSON(out, statuses)
+	}
+
+	var stopped int
+
+	rows := make([][]string, 0, len(statuses))
+	for _, d := range statuses {
+		status := "scheduled"
+		switch {
+		case !d.WaiterRunning:
+			status = "not running"
+			stopped++
+		case !d.At.After(time.Now()):
+			status = "deploying"
+		}
+
+		rows = append(rows, []string{
+			d.ID,
+			d.AppName,
+			d.Image,
+			d.At.Local().Format(time.RFC3339),
+			status,
+			d.LogFile,
+		})
+	}
+
+	if err := render.Table(out, "", rows, "ID", "App", "Image", "At", "Status", "Log"); err != nil {
+		return err
+	}
+
+	if stopped > 0 {
+		fmt.Fprintf(iostreams.FromContext(ctx).ErrOut, "\n%d of the deployments won't run, since the processes waiting to run them are gone, e.g. as the machine restarted.\nCancel them and schedule them again with 'fly deploy --at'.\n", stopped)
+	}
+
+	return nil
+}
+
+func newScheduledCancel() (cmd *cobra.Command) {
+	const (
+		long = `Cancel the scheduled deployment of the given ID.
+`
+		short = "Cancel a scheduled deployment"
+	)
+
+	cmd = command.New("cancel <id>", short, long, runScheduledCancel)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	return
+}
+
+func runScheduledCancel(ctx context.Context) error {
+	id := flag.FirstArg(ctx)
+
+	d, err := deployment.NewSchedule(state.ConfigDirectory(ctx)).Cancel(ctx, id)
+	if errors.Is(err, deployment.ErrScheduledNotFound) {
+		return &flyerr.NotFoundError{
+			Err: fmt.Errorf("no deployment %s is scheduled; see 'fly deploys scheduled list'", id),
+		}
+	} else if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(iostreams.FromContext(ctx).Out, "Cancelled the deployment of %s to %s scheduled for %s\n",
+		d.Image, d.AppName, d.At.Local().Format(time.RFC3339))
+
+	return nil
+}
+
+func newScheduledRun() (cmd *cobra.Command) {
+	const (
+		short = "Run a scheduled deployment once it's due"
+		long  = short + "\n"
+	)
+
+	cmd = command.New("run <id>", short, long, runScheduledRun)
+
+	cmd.Args = cobra.ExactArgs(1)
+	cmd.Hidden = true
+
+	return
+}
+
+func runScheduledRun(ctx context.Context) error {
+	return deployment.NewSchedule(state.ConfigDirectory(ctx)).Run(ctx, flag.FirstArg(ctx))
+}
@@ -37,7 +37,8 @@ but may be useful if diagnosing a DNS bug) and A and CNAME
 (if you're using the server to test recursive lookups.)
 Note that this resolves names against the server for the current organization. You can
 set the organization with -o <org-slug>; otherwise, the command uses the organization
-attached to the current app (you can pass an app in with -a <appname>).`
+attached to the current app (you can pass an app in with -a <appname>).
+Private hostnames added with 'fly hostnames add' resolve like app names do.`
 
 		short = "Make DNS requests against Fly.io's internal DNS server"
 	)
@@ -0,0 +1,235 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	dockerclient "github.com/docker/docker/client"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/agent"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+// hopTimeout denotes the time each hop of the builder check is given.
+const hopTimeout = 30 * time.Second
+
+// hop wraps the outcome of checking one of the hops builds go through.
+type hop struct {
+	name    string
+	latency time.Duration
+	err     error
+}
+
+// finding is returned by hops which could not be checked for reasons which
+// are not failures, such as there being no builder yet. It ends the check
+// without failing it.
+type finding string
+
+func (f finding) Error() string {
+	return string(f)
+}
+
+// builderCheck checks the connectivity to the remote builder of the app, hop
+// by hop, retaining the outcome of each.
+type builderCheck struct {
+	mu   sync.Mutex
+	hops []hop
+}
+
+// timed runs fn as the hop with the given name and records its outcome. In
+// case fn returns a finding, timed returns errSkipped.
+func (bc *builderCheck) timed(ctx context.Context, name string, fn func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, hopTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+
+	bc.mu.Lock()
+	bc.hops = append(bc.hops, hop{name: name, latency: time.Since(start), err: err})
+	bc.mu.Unlock()
+
+	var f finding
+	switch {
+	case errors.As(err, &f):
+		return errSkipped
+	case err != nil:
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return nil
+}
+
+func (bc *builderCheck) run(ctx context.Context) (err error) {
+	appName := app.NameFromContext(ctx)
+	if appName == "" {
+		return errSkipped
+	}
+
+	apiClient := client.FromContext(ctx).API()
+
+	app, err := apiClient.GetApp(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app: %w", err)
+	}
+
+	var (
+		ac     *agent.Client
+		dialer agent.Dialer
+		slug   = app.Organization.Slug
+	)
+
+	if err = bc.timed(ctx, "agent tunnel", func(ctx context.Context) (err error) {
+		if ac, err = agent.Establish(ctx, apiClient); err != nil {
+			return
+		}
+
+		if dialer, err = ac.Dialer(ctx, slug); err == nil {
+			err = ac.Probe(ctx, slug)
+		}
+
+		return
+	}); err != nil {
+		return
+	}
+
+	var host string
+	if err = bc.timed(ctx, "builder machine", func(ctx context.Context) error {
+		machine, err := lookupBuilderMachine(ctx, apiClient, slug)
+		if err != nil {
+			return err
+		}
+
+		for _, ip := range machine.IPs.Nodes {
+			if ip.Kind == "privatenet" {
+				host = net.JoinHostPort(ip.IP, "2375")
+
+				break
+			}
+		}
+
+		if host == "" {
+			return errors.New("builder machine does not have a private IP")
+		}
+
+		conn, err := dialer.DialContext(ctx, "tcp", host)
+		if err == nil {
+			err = conn.Close()
+		}
+
+		return err
+	}); err != nil {
+		return
+	}
+
+	docker, err := dockerclient.NewClientWithOpts(
+		dockerclient.WithAPIVersionNegotiation(),
+		dockerclient.WithHost("tcp://"+host),
+		dockerclient.WithDialContext(dialer.DialContext),
+	)
+	if err != nil {
+		return fmt.Errorf("failed creating docker client: %w", err)
+	}
+	defer docker.Close()
+
+	if err = bc.timed(ctx, "docker api", func(ctx context.Context) error {
+		_, err := docker.Ping(ctx)
+
+		return err
+	}); err != nil {
+		return
+	}
+
+	cfg := config.FromContext(ctx)
+
+	// logging in from the builder verifies it can reach the registry and
+	// authenticate the way pushes do
+	return bc.timed(ctx, "registry", func(ctx context.Context) error {
+		_, err := docker.RegistryLogin(ctx, types.AuthConfig{
+			Username:      "x",
+			Password:      cfg.AccessToken,
+			ServerAddress: cfg.RegistryHost,
+		})
+
+		return err
+	})
+}
+
+// lookupBuilderMachine returns the started machine of the remote builder of the
+// organization with the given slug. Unlike builder.RemoteBuilderMachine it
+// neither provisions nor wakes the builder, which would bill the organization
+// for a diagnostic; a builder which is missing or stopped is a finding instead.
+func lookupBuilderMachine(ctx context.Context, apiClient *api.Client, slug string) (*api.Machine, error) {
+	if os.Getenv("FLY_REMOTE_BUILDER_HOST") != "" {
+		return nil, finding("FLY_REMOTE_BUILDER_HOST is set; no builder machine to check")
+	}
+
+	builderApp, err := apiClient.GetOrganizationRemoteBuilderApp(ctx, slug)
+	switch {
+	case err != nil:
+		return nil, err
+	case builderApp == nil:
+		return nil, finding("no remote builder yet; the first remote build creates one")
+	}
+
+	machines, err := apiClient.ListMachines(ctx, builderApp.ID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range machines {
+		if m.State == "started" {
+			return m, nil
+		}
+	}
+
+	if len(machines) == 0 {
+		return nil, finding("remote builder has no machine; the next remote build creates one")
+	}
+
+	return nil, finding(fmt.Sprintf("remote builder is %s; the next remote build wakes it", machines[0].State))
+}
+
+// render renders the hops bc checked, if any.
+func (bc *builderCheck) render(ctx context.Context) error {
+	if len(bc.hops) == 0 {
+		return nil
+	}
+
+	io := iostreams.FromContext(ctx)
+	colorize := io.ColorScheme()
+
+	rows := make([][]string, 0, len(bc.hops))
+	for _, h := range bc.hops {
+		rows = append(rows, []string{
+			h.name,
+			h.latency.Round(time.Millisecond).String(),
+			hopReason(colorize, h.err),
+		})
+	}
+
+	fmt.Fprintln(io.Out)
+
+	return render.Table(io.Out, "Remote builder", rows, "Hop", "Latency", "Status")
+}
+
+func hopReason(colorize *iostreams.ColorScheme, err error) string {
+	var f finding
+	if errors.As(err, &f) {
+		return colorize.Yellow(f.Error())
+	}
+
+	return toReason(colorize, err)
+}
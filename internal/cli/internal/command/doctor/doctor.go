@@ -37,7 +37,12 @@ import (
 func New() (cmd *cobra.Command) {
 	const (
 		short = `The DOCTOR command allows you to debug your Fly environment`
-		long  = short + "\n"
+		long  = short + `
+
+When run for an app, the remote builder check connects to the builder of the
+app hop by hop (agent tunnel, builder machine, Docker API and registry) and
+reports the latency of each, telling where builds which hang get stuck.
+`
 	)
 
 	cmd = command.New("doctor", short, long, run,
@@ -61,21 +66,36 @@ var runners = map[string]runner{
 	"Agent":          runAgent,
 	"Probe (app)":    runProbeApp,
 	"Unix socket":    runUnixSocket,
+	"Proxy":          runProxy,
 	// "UDP":            runUDP,
 }
 
 func run(ctx context.Context) error {
-	errors := runInParallel(ctx, runtime.GOMAXPROCS(0), runners)
+	bc := &builderCheck{}
+
+	all := make(map[string]runner, len(runners)+1)
+	for key, r := range runners {
+		all[key] = r
+	}
+	all["Remote builder"] = bc.run
+
+	errors := runInParallel(ctx, runtime.GOMAXPROCS(0), all)
 
 	if err := ctx.Err(); err != nil {
 		return err
 	}
 
-	if config.FromContext(ctx).JSONOutput {
+	if config.FromContext(ctx).StructuredOutput() {
 		return renderJSON(ctx, errors)
 	}
 
-	return renderTable(ctx, errors)
+	if err := renderTable(ctx, errors); err != nil {
+		return err
+	}
+
+	renderProxy(ctx)
+
+	return bc.render(ctx)
 }
 
 type limiter chan struct{}
@@ -132,7 +152,7 @@ func renderJSON(ctx context.Context, errors map[string]error) error {
 	}
 
 	out := iostreams.FromContext(ctx).Out
-	return render.JSON(out, m)
+	return render.Structured(ctx, out, m)
 }
 
 func renderTable(ctx context.Context, errors map[string]error) error {
@@ -0,0 +1,69 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/netproxy"
+)
+
+// runProxy checks that the API is reachable via the proxy the environment
+// denotes, if any.
+func runProxy(ctx context.Context) (err error) {
+	desc, addr, err := apiProxy(ctx)
+	switch {
+	case err != nil:
+		return
+	case desc == "":
+		return errSkipped
+	}
+
+	var conn net.Conn
+	if conn, err = netproxy.DialContext(ctx, "tcp", addr); err != nil {
+		return fmt.Errorf("failed reaching %s via %s: %w", addr, desc, err)
+	}
+
+	return conn.Close()
+}
+
+// apiProxy returns the description of the proxy requests to the API go
+// through along with the address of the API.
+func apiProxy(ctx context.Context) (desc, addr string, err error) {
+	baseURL := config.FromContext(ctx).APIBaseURL
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return
+	}
+
+	if addr = u.Host; u.Port() == "" {
+		port := "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+		addr = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	desc, err = netproxy.Describe(baseURL)
+
+	return
+}
+
+// renderProxy reports the proxy in use, if any.
+func renderProxy(ctx context.Context) {
+	desc, _, err := apiProxy(ctx)
+	if err != nil || desc == "" {
+		return
+	}
+
+	io := iostreams.FromContext(ctx)
+
+	fmt.Fprintln(io.Out)
+	fmt.Fprintf(io.Out, "Proxy in use: %s\n", desc)
+	fmt.Fprintln(io.Out, "WireGuard tunnels use UDP and can't go through proxies; UDP port 51820 must be reachable.")
+}
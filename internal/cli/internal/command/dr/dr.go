@@ -0,0 +1,27 @@
+// Package dr implements the dr command chain.
+package dr
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+)
+
+// New initializes and returns a new dr command.
+func New() *cobra.Command {
+	const (
+		long = `The DR commands help with planning and rehearsing the recovery of an
+application from a disaster.
+`
+		short = "Plan and rehearse disaster recovery"
+	)
+
+	cmd := command.New("dr", short, long, nil)
+
+	cmd.AddCommand(
+		newPlan(),
+		newRehearse(),
+	)
+
+	return cmd
+}
@@ -0,0 +1,265 @@
+package dr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/buildinfo"
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/bundle"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+// staleSnapshotAge denotes the age after which a volume's latest snapshot is
+// considered too old to recover from.
+const staleSnapshotAge = 24 * time.Hour
+
+func newPlan() *cobra.Command {
+	const (
+		long = `The DR PLAN command generates a recovery runbook for an application.
+The runbook lists the volume snapshots to restore from, the secrets which must
+be set again and the steps to recreate the application in a backup region.
+
+The plan checks the platform offers the recovery region, but not whether the
+region has the capacity to fit the application; 'fly dr rehearse' proves that.
+`
+		short = "Generate a disaster recovery runbook"
+	)
+
+	cmd := command.New("plan", short, long, runPlan,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.String{
+			Name:        "region",
+			Shorthand:   "r",
+			Description: "The region to recover into. Defaults to the app's first backup region",
+		},
+		flag.String{
+			Name:        "output",
+			Shorthand:   "o",
+			Description: "Path to write the app's config bundle to",
+		},
+	)
+
+	return cmd
+}
+
+// Plan wraps a recovery runbook.
+type Plan struct {
+	App          string           `json:"app"`
+	Organization string           `json:"organization"`
+	Region       string           `json:"region"`
+	Bundle       string           `json:"bundle,omitempty"`
+	Volumes      []VolumeRecovery `json:"volumes"`
+	SecretKeys   []string         `json:"secret_keys"`
+	Warnings     []string         `json:"warnings"`
+
+	bundle *bundle.Bundle
+}
+
+// VolumeRecovery wraps the properties of a volume's recovery.
+type VolumeRecovery struct {
+	Name       string     `json:"name"`
+	Region     string     `json:"region"`
+	SizeGb     int        `json:"size_gb"`
+	SnapshotID string     `json:"snapshot_id,omitempty"`
+	SnapshotAt *time.Time `json:"snapshot_at,omitempty"`
+}
+
+func runPlan(ctx context.Context) error {
+	plan, err := buildPlan(ctx, app.NameFromContext(ctx), flag.GetString(ctx, "region"))
+	if err != nil {
+		return err
+	}
+
+	if path := flag.GetString(ctx, "output"); path != "" {
+		if err := plan.bundle.WriteFile(path); err != nil {
+			return fmt.Errorf("failed writing bundle to %s: %w", path, err)
+		}
+		plan.Bundle = path
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, out, plan)
+	}
+
+	return writeRunbook(out, plan)
+}
+
+func buildPlan(ctx context.Context, appName, region string) (*Plan, error) {
+	client := client.FromContext(ctx).API()
+
+	b, err := bundle.Collect(ctx, client, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{
+		App:          b.Manifest.App,
+		Organization: b.Manifest.Organization,
+		SecretKeys:   b.SecretKeys,
+		bundle:       b,
+	}
+
+	if plan.Region, err = determineRegion(ctx, b, region); err != nil {
+		return nil, err
+	}
+
+	// the volumes of the bundle are set to be restored from the snapshots the
+	// plan lists, so that rehearsals recover the data the runbook does
+	for i := range b.Volumes {
+		vol := &b.Volumes[i]
+
+		rec := VolumeRecovery{
+			Name:   vol.Name,
+			Region: vol.Region,
+			SizeGb: vol.SizeGb,
+		}
+
+		snapshots, err := client.GetVolumeSnapshots(ctx, vol.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed retrieving snapshots of volume %s: %w", vol.ID, err)
+		}
+
+		sort.Slice(snapshots, func(i, j int) bool {
+			return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+		})
+
+		switch {
+		case len(snapshots) == 0:
+			plan.Warnings = append(plan.Warnings,
+				fmt.Sprintf("volume %s (%s) has no snapshots; its data can't be recovered", vol.Name, vol.ID))
+		default:
+			latest := snapshots[0]
+			rec.SnapshotID = latest.ID
+			rec.SnapshotAt = &latest.CreatedAt
+			vol.Snapshot = latest.ID
+
+			if time.Since(latest.CreatedAt) > staleSnapshotAge {
+				plan.Warnings = append(plan.Warnings,
+					fmt.Sprintf("the latest snapshot of volume %s (%s) was taken %s", vol.Name, vol.ID, humanize.Time(latest.CreatedAt)))
+			}
+		}
+
+		plan.Volumes = append(plan.Volumes, rec)
+	}
+
+	return plan, nil
+}
+
+// determineRegion returns the region to recover into after making sure the
+// platform offers it. The API doesn't expose the capacity of regions, so
+// whether the region can fit the app is left for rehearsals to prove.
+func determineRegion(ctx context.Context, b *bundle.Bundle, region string) (string, error) {
+	if region == "" {
+		if len(b.Scale.BackupRegions) == 0 {
+			return "", fmt.Errorf("app %s has no backup regions; please specify the region to recover into", b.Manifest.App)
+		}
+
+		region = b.Scale.BackupRegions[0]
+	}
+
+	regions, _, err := client.FromContext(ctx).API().PlatformRegions(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed retrieving regions: %w", err)
+	}
+
+	for _, r := range regions {
+		if r.Code == region {
+			return region, nil
+		}
+	}
+
+	return "", fmt.Errorf("region %s is not currently available on the platform", region)
+}
+
+func writeRunbook(w io.Writer, plan *Plan) error {
+	var (
+		buf  bytes.Buffer
+		name = buildinfo.Name()
+		step int
+	)
+
+	stepf := func(format string, a ...interface{}) {
+		step++
+		fmt.Fprintf(&buf, "\n%d. %s\n", step, fmt.Sprintf(format, a...))
+	}
+
+	fmt.Fprintf(&buf, "# Recovery runbook for %s\n\n", plan.App)
+	fmt.Fprintf(&buf, "Organization: %s\n", plan.Organization)
+	fmt.Fprintf(&buf, "Recovery region: %s\n", plan.Region)
+
+	if len(plan.Warnings) > 0 {
+		fmt.Fprintln(&buf, "\n## Warnings")
+		for _, warning := range plan.Warnings {
+			fmt.Fprintf(&buf, "\n  * %s", warning)
+		}
+		fmt.Fprintln(&buf)
+	}
+
+	fmt.Fprintln(&buf, "\n## Steps")
+
+	bundlePath := plan.Bundle
+	if bundlePath == "" {
+		bundlePath = plan.App + ".tar.gz"
+
+		stepf("Export the app's config bundle (do this ahead of time):")
+		fmt.Fprintf(&buf, "   %s apps export %s -a %s\n", name, bundlePath, plan.App)
+	}
+
+	stepf("Recreate the app in %s:", plan.Region)
+	fmt.Fprintf(&buf, "   %s apps import %s --name %s-recovered --region %s\n", name, bundlePath, plan.App, plan.Region)
+
+	if len(plan.SecretKeys) > 0 {
+		stepf("Set the app's secrets again:")
+		fmt.Fprintf(&buf, "   %s secrets set -a %s-recovered", name, plan.App)
+		for _, key := range plan.SecretKeys {
+			fmt.Fprintf(&buf, " \\\n     %s=<value>", key)
+		}
+		fmt.Fprintln(&buf)
+	}
+
+	if len(plan.Volumes) > 0 {
+		stepf("Restore the data of the app's volumes:")
+		for _, vol := range plan.Volumes {
+			if vol.SnapshotID == "" {
+				fmt.Fprintf(&buf, "   * %s (%dGB): no snapshot available\n", vol.Name, vol.SizeGb)
+
+				continue
+			}
+
+			fmt.Fprintf(&buf, "   * %s (%dGB): from snapshot %s taken %s\n",
+				vol.Name, vol.SizeGb, vol.SnapshotID, vol.SnapshotAt.Format(time.RFC3339))
+		}
+	}
+
+	stepf("Verify the recovered app is healthy:")
+	fmt.Fprintf(&buf, "   %s status -a %s-recovered\n", name, plan.App)
+
+	stepf("Point the app's DNS records (or certificates) to the recovered app.")
+
+	_, err := buf.WriteTo(w)
+
+	return err
+}
@@ -0,0 +1,213 @@
+package dr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/bundle"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/prompt"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+const rehearsalPollInterval = 5 * time.Second
+
+func newRehearse() *cobra.Command {
+	const (
+		long = `The DR REHEARSE command proves an application's recovery plan works by
+standing up a copy of the application in a sandbox organization, waiting for
+it to become healthy and destroying it afterwards.
+
+Volumes are restored from the snapshots the recovery plan lists; those which
+have no snapshots are recreated empty. Secrets are not copied, so applications
+which can't boot without their secrets will fail the rehearsal.
+`
+		short = "Rehearse the recovery of an app in a sandbox organization"
+	)
+
+	cmd := command.New("rehearse", short, long, runRehearse,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Org(),
+		flag.String{
+			Name:        "region",
+			Shorthand:   "r",
+			Description: "The region to recover into. Defaults to the app's first backup region",
+		},
+		flag.Int{
+			Name:        "wait-timeout",
+			Description: "Seconds to wait for the recovered app to become healthy",
+			Default:     300,
+		},
+		flag.Bool{
+			Name:        "keep",
+			Description: "Do not destroy the recovered app once the rehearsal completes",
+		},
+	)
+
+	return cmd
+}
+
+// Rehearsal wraps the outcome of a rehearsal.
+type Rehearsal struct {
+	App          string        `json:"app"`
+	Copy         string        `json:"copy"`
+	Organization string        `json:"organization"`
+	Region       string        `json:"region"`
+	Successful   bool          `json:"successful"`
+	Status       string        `json:"status"`
+	Duration     time.Duration `json:"duration_ns"`
+	Kept         bool          `json:"kept"`
+}
+
+func runRehearse(ctx context.Context) (err error) {
+	appName := app.NameFromContext(ctx)
+
+	plan, err := buildPlan(ctx, appName, flag.GetString(ctx, "region"))
+	if err != nil {
+		return
+	}
+
+	org, err := prompt.Org(ctx, nil)
+	if err != nil {
+		return
+	}
+
+	if org.Slug == plan.Organization {
+		return fmt.Errorf("rehearsals must run in an organization other than %s's (%s)", appName, org.Slug)
+	}
+
+	for _, warning := range plan.Warnings {
+		fmt.Fprintln(iostreams.FromContext(ctx).ErrOut, iostreams.FromContext(ctx).ColorScheme().Yellow("Warning: "+warning))
+	}
+
+	r := &Rehearsal{
+		App:          appName,
+		Copy:         fmt.Sprintf("%s-dr-%d", appName, time.Now().Unix()),
+		Organization: org.Slug,
+		Region:       plan.Region,
+		Kept:         flag.GetBool(ctx, "keep"),
+	}
+
+	start := time.Now()
+
+	// the cleanup is registered before restoring, since restores which fail
+	// part way leave the copy behind
+	if !r.Kept {
+		copyName := r.Copy
+
+		defer func() {
+			if e := destroyCopy(ctx, copyName); err == nil {
+				err = e
+			}
+		}()
+	}
+
+	if _, _, err = bundle.Restore(ctx, plan.bundle, bundle.RestoreOptions{
+		Name:             r.Copy,
+		Organization:     org,
+		Region:           plan.Region,
+		SkipCertificates: true,
+	}); err != nil {
+		return
+	}
+
+	timeout := time.Duration(flag.GetInt(ctx, "wait-timeout")) * time.Second
+	if r.Status, r.Successful, err = waitForHealthy(ctx, r.Copy, timeout); err != nil {
+		return
+	}
+	r.Duration = time.Since(start)
+
+	if config.FromContext(ctx).StructuredOutput() {
+		err = render.Structured(ctx, iostreams.FromContext(ctx).Out, r)
+	} else {
+		tb := render.NewTextBlock(ctx)
+		if r.Successful {
+			tb.Donef("Rehearsal succeeded; %s recovered in %s as %s", appName, r.Duration.Round(time.Second), r.Copy)
+		} else {
+			tb.Donef("Rehearsal failed; %s ended up %s", r.Copy, r.Status)
+		}
+	}
+
+	if err == nil && !r.Successful {
+		err = errors.New("recovery rehearsal failed")
+	}
+
+	return
+}
+
+// waitForHealthy polls the named app's latest deployment until it either
+// completes or timeout elapses.
+func waitForHealthy(ctx context.Context, appName string, timeout time.Duration) (status string, successful bool, err error) {
+	client := client.FromContext(ctx).API()
+
+	tb := render.NewTextBlock(ctx, "Waiting for ", appName, " to become healthy")
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(rehearsalPollInterval)
+	defer ticker.Stop()
+
+	for {
+		s, e := client.GetAppStatus(ctx, appName, false)
+		switch {
+		case e == nil && s.DeploymentStatus != nil:
+			ds := s.DeploymentStatus
+
+			status = ds.Status
+			if !ds.InProgress {
+				successful = ds.Successful
+
+				return
+			}
+
+			tb.Detailf("%d desired, %d placed, %d healthy, %d unhealthy",
+				ds.DesiredCount, ds.PlacedCount, ds.HealthyCount, ds.UnhealthyCount)
+		case e != nil && ctx.Err() == nil:
+			err = fmt.Errorf("failed retrieving status of %s: %w", appName, e)
+
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			if status == "" {
+				status = "pending"
+			}
+			status = fmt.Sprintf("%s (timed out after %s)", status, timeout)
+
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func destroyCopy(ctx context.Context, appName string) error {
+	tb := render.NewTextBlock(ctx, "Destroying ", appName)
+
+	if err := client.FromContext(ctx).API().DeleteApp(ctx, appName); err != nil {
+		return fmt.Errorf("failed destroying %s: %w", appName, err)
+	}
+
+	tb.Done("Destroyed ", appName)
+
+	return nil
+}
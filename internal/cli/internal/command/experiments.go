@@ -0,0 +1,107 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/superfly/flyctl/internal/logger"
+
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+)
+
+// Experiment wraps a behavior of flyctl which ships disabled until users opt
+// into it, so that large changes may land gradually.
+type Experiment struct {
+	Name        string
+	Description string
+}
+
+// Experiments lists the known experiments. Experiments which graduate, or get
+// dropped, are removed from the list; enabling them has no effect.
+var Experiments = []Experiment{
+	{
+		Name:        "machines-rollout",
+		Description: "Deploy apps on the machines platform by replacing their machines one at a time",
+	},
+	{
+		Name:        "watch-ui",
+		Description: "Follow deployments through the redesigned deployment monitor",
+	},
+	{
+		Name:        "incremental-upload",
+		Description: "Upload only the parts of the build context remote builders don't already have",
+	},
+}
+
+// LookupExperiment returns the experiment with the given name.
+func LookupExperiment(name string) (Experiment, error) {
+	for _, e := range Experiments {
+		if e.Name == name {
+			return e, nil
+		}
+	}
+
+	names := make([]string, 0, len(Experiments))
+	for _, e := range Experiments {
+		names = append(names, e.Name)
+	}
+
+	return Experiment{}, fmt.Errorf("unknown experiment %q; use one of %s", name, strings.Join(names, ", "))
+}
+
+// ExperimentEnabled reports whether the user has enabled the named experiment,
+// either via settings, the FLY_EXPERIMENTS environment variable or the
+// experiments flag of the running command.
+func ExperimentEnabled(ctx context.Context, name string) bool {
+	for _, n := range config.FromContext(ctx).Experiments {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EnabledExperiments returns the sorted names of the known experiments the
+// user has enabled.
+func EnabledExperiments(ctx context.Context) (names []string) {
+	for _, e := range Experiments {
+		if ExperimentEnabled(ctx, e.Name) {
+			names = append(names, e.Name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return
+}
+
+// checkExperiments rejects unknown experiments requested via flag. Unknown
+// experiments enabled otherwise are most likely ones which have since
+// graduated and are merely logged.
+func checkExperiments(ctx context.Context) (context.Context, error) {
+	if fs := flag.FromContext(ctx); fs.Lookup(flag.ExperimentsName) != nil {
+		for _, name := range flag.GetStringSlice(ctx, flag.ExperimentsName) {
+			if _, err := LookupExperiment(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	logger := logger.FromContext(ctx)
+
+	for _, name := range config.FromContext(ctx).Experiments {
+		if _, err := LookupExperiment(name); err != nil {
+			logger.Debugf("ignoring experiment %q: %v", name, err)
+		}
+	}
+
+	if names := EnabledExperiments(ctx); len(names) > 0 {
+		logger.Debugf("experiments enabled: %s", strings.Join(names, ", "))
+	}
+
+	return ctx, nil
+}
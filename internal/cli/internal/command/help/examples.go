@@ -0,0 +1,85 @@
+package help
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/env"
+	"github.com/superfly/flyctl/internal/flyerr"
+)
+
+// appPlaceholder is what examples and guides refer to the app by.
+const appPlaceholder = "$APP"
+
+// Examples writes the examples of cmd to w, with the app they refer to
+// substituted by the one given via args, the environment or the fly.toml of
+// the working directory.
+func Examples(w io.Writer, cmd *cobra.Command, args []string) error {
+	// flags may not parse, since the required ones may be missing; we make do
+	// with the ones which do.
+	_ = cmd.ParseFlags(args)
+
+	if cmd.Example == "" {
+		return &flyerr.ValidationError{
+			Err: fmt.Errorf("there are no examples for '%s'; run '%s --help' for its usage", cmd.CommandPath(), cmd.CommandPath()),
+		}
+	}
+
+	_, err := fmt.Fprintln(w, substituteApp(cmd.Example, appName(cmd)))
+
+	return err
+}
+
+// WantsExamples reports whether args ask for the examples of a command.
+func WantsExamples(args []string) bool {
+	for _, arg := range args {
+		switch arg {
+		case "--":
+			return false
+		case "--examples", "--examples=true":
+			return true
+		}
+	}
+
+	return false
+}
+
+// appName returns the name of the app the user works with, if any. The
+// --app flag of cmd takes precedence over FLY_APP, which does over the
+// fly.toml of the working directory.
+func appName(cmd *cobra.Command) string {
+	if f := cmd.Flags().Lookup("app"); f != nil && f.Value.String() != "" {
+		return f.Value.String()
+	}
+
+	if name := env.First("FLY_APP"); name != "" {
+		return name
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	for _, name := range app.ConfigFileNames {
+		if cfg, err := app.LoadConfig(filepath.Join(wd, name)); err == nil && cfg.AppName != "" {
+			return cfg.AppName
+		}
+	}
+
+	return ""
+}
+
+func substituteApp(s, name string) string {
+	if name == "" {
+		return s
+	}
+
+	return strings.ReplaceAll(s, appPlaceholder, name)
+}
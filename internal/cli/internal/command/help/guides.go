@@ -0,0 +1,139 @@
+package help
+
+// Guide maps something users set out to do to the sequence of commands which
+// does it.
+type Guide struct {
+	// Title describes the task the way users would.
+	Title string
+	// Keywords are the additional words the guide is found by.
+	Keywords []string
+	// Steps are the commands which make up the task, in order.
+	Steps []Step
+}
+
+// Step is a single command of a Guide.
+type Step struct {
+	// Command is the path of the command, sans the root command.
+	Command string
+	// Args are the arguments and flags the command is run with.
+	Args string
+	// Note describes the step. When empty, the short description of the
+	// command is used instead.
+	Note string
+}
+
+// Guides returns the built-in guides.
+func Guides() []Guide {
+	return guides
+}
+
+var guides = []Guide{
+	{
+		Title:    "Launch and deploy a new app",
+		Keywords: []string{"create", "new", "start", "ship", "release", "dockerfile"},
+		Steps: []Step{
+			{Command: "launch", Note: "Generate a fly.toml for the source in the current directory"},
+			{Command: "deploy"},
+			{Command: "status", Args: "-a $APP"},
+			{Command: "open", Args: "-a $APP"},
+		},
+	},
+	{
+		Title:    "Use a custom domain",
+		Keywords: []string{"hostname", "dns", "certificate", "cert", "tls", "ssl", "https"},
+		Steps: []Step{
+			{Command: "ips list", Args: "-a $APP", Note: "List the addresses to point the domain's A and AAAA records at"},
+			{Command: "certs add", Args: "<hostname> -a $APP"},
+			{Command: "certs check", Args: "<hostname> -a $APP", Note: "Check on the DNS configuration and the issuing of the certificate"},
+		},
+	},
+	{
+		Title:    "Set secrets and environment variables",
+		Keywords: []string{"env", "environment", "variable", "config", "credential", "password", "key"},
+		Steps: []Step{
+			{Command: "secrets set", Args: "NAME=value -a $APP", Note: "Set secrets, which restarts the app with them exposed as environment variables"},
+			{Command: "secrets list", Args: "-a $APP"},
+		},
+	},
+	{
+		Title:    "Persist data on a volume",
+		Keywords: []string{"storage", "disk", "database", "file", "mount", "state"},
+		Steps: []Step{
+			{Command: "volumes create", Args: "<name> --region <region> -a $APP"},
+			{Command: "deploy", Args: "-a $APP", Note: "Deploy with the volume referenced by a [mounts] section of fly.toml"},
+			{Command: "volumes list", Args: "-a $APP"},
+		},
+	},
+	{
+		Title:    "Scale an app",
+		Keywords: []string{"instance", "count", "memory", "ram", "cpu", "vm", "size", "bigger", "more"},
+		Steps: []Step{
+			{Command: "scale show", Args: "-a $APP"},
+			{Command: "scale count", Args: "<count> -a $APP"},
+			{Command: "scale vm", Args: "<size> -a $APP"},
+			{Command: "scale memory", Args: "<megabytes> -a $APP"},
+		},
+	},
+	{
+		Title:    "Run an app in more regions",
+		Keywords: []string{"region", "location", "global", "latency", "move"},
+		Steps: []Step{
+			{Command: "platform regions", Note: "List the regions apps may run in"},
+			{Command: "regions add", Args: "<region> -a $APP"},
+			{Command: "scale count", Args: "<count> -a $APP", Note: "Run enough instances to cover the regions"},
+			{Command: "regions list", Args: "-a $APP"},
+		},
+	},
+	{
+		Title:    "Debug a running app",
+		Keywords: []string{"troubleshoot", "crash", "error", "broken", "logs", "shell", "health"},
+		Steps: []Step{
+			{Command: "status", Args: "-a $APP"},
+			{Command: "logs", Args: "-a $APP"},
+			{Command: "checks list", Args: "-a $APP"},
+			{Command: "ssh console", Args: "-a $APP"},
+		},
+	},
+	{
+		Title:    "Trace a failing request",
+		Keywords: []string{"502", "503", "request", "error", "gateway", "timeout", "proxy", "fly-request-id"},
+		Steps: []Step{
+			{Command: "trace", Args: "<fly-request-id> -a $APP", Note: "Show the edge, proxy and app log entries of the request as one timeline"},
+			{Command: "checks list", Args: "-a $APP"},
+		},
+	},
+	{
+		Title:    "Roll back to a previous release",
+		Keywords: []string{"rollback", "revert", "undo", "version", "image"},
+		Steps: []Step{
+			{Command: "releases", Args: "-a $APP"},
+			{Command: "image show", Args: "-a $APP", Note: "Show the image the app currently runs"},
+			{Command: "deploy", Args: "--image <image> -a $APP", Note: "Deploy the image of the release to roll back to"},
+		},
+	},
+	{
+		Title:    "Reach private services from your machine",
+		Keywords: []string{"private", "network", "tunnel", "port", "forward", "local", "wireguard", "vpn"},
+		Steps: []Step{
+			{Command: "proxy", Args: "<local:remote> -a $APP"},
+			{Command: "wireguard create", Note: "Set up a WireGuard peer for the whole private network"},
+		},
+	},
+	{
+		Title:    "Set up a Postgres database",
+		Keywords: []string{"database", "db", "sql", "postgresql", "pg"},
+		Steps: []Step{
+			{Command: "postgres create"},
+			{Command: "postgres attach", Args: "--postgres-app <postgres-app> -a $APP", Note: "Attach the cluster, which sets DATABASE_URL on the app"},
+			{Command: "postgres connect", Args: "-a <postgres-app>"},
+		},
+	},
+	{
+		Title:    "Copy files to and from an instance",
+		Keywords: []string{"file", "transfer", "upload", "download", "sftp", "scp", "copy"},
+		Steps: []Step{
+			{Command: "ssh cp", Args: "<local-path> :<remote-path> -a $APP"},
+			{Command: "ssh sftp", Args: "-a $APP"},
+		},
+	},
+}
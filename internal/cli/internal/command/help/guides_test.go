@@ -0,0 +1,25 @@
+package help_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command/help"
+	"github.com/superfly/flyctl/internal/cli/internal/command/root"
+)
+
+func TestGuideStepsExist(t *testing.T) {
+	cmd := root.New()
+
+	for _, g := range help.Guides() {
+		for _, step := range g.Steps {
+			c, rest, err := cmd.Find(strings.Fields(step.Command))
+			if assert.NoError(t, err, "%s: %s", g.Title, step.Command) {
+				assert.Empty(t, rest, "%s: %s", g.Title, step.Command)
+				assert.NotEqual(t, cmd, c, "%s: %s", g.Title, step.Command)
+			}
+		}
+	}
+}
@@ -0,0 +1,303 @@
+// Package help implements the help command, which, besides the help of
+// commands, searches the task-oriented guides and the commands themselves.
+package help
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/buildinfo"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+)
+
+// New initializes and returns a new help Command.
+func New() *cobra.Command {
+	const (
+		short = "Help on any command, or search for how to do a task"
+
+		long = `Shows the help of the given command. When the arguments don't name a command,
+they're taken as a search of the guides, which map tasks to the sequence of
+commands that do them, and of the commands themselves.
+
+For example:
+
+  fly help "custom domain"
+  fly help scale memory`
+
+		usage = "help [command | query]"
+	)
+
+	cmd := command.New(usage, short, long, run)
+
+	flag.Add(cmd,
+		flag.Bool{
+			Name:        "guides",
+			Description: "List all the guides",
+		},
+	)
+
+	return cmd
+}
+
+func run(ctx context.Context) error {
+	var (
+		cmd  = command.FromContext(ctx)
+		root = cmd.Root()
+		args = flag.Args(ctx)
+		out  = iostreams.FromContext(ctx).Out
+		app  = appName(cmd)
+	)
+
+	if flag.GetBool(ctx, "guides") {
+		for i, g := range guides {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			printGuide(out, root, g, app)
+		}
+
+		return nil
+	}
+
+	if len(args) == 0 {
+		return root.Help()
+	}
+
+	if c, rest, err := root.Find(args); err == nil && c != root && len(rest) == 0 {
+		return c.Help()
+	}
+
+	query := strings.Join(args, " ")
+
+	matchedGuides, matchedCommands := Search(root, query)
+	if len(matchedGuides) == 0 && len(matchedCommands) == 0 {
+		return &flyerr.ValidationError{
+			Err: fmt.Errorf("no guides or commands match %q; run '%s help --guides' to list all the guides", query, buildinfo.Name()),
+		}
+	}
+
+	if len(matchedGuides) > 0 {
+		fmt.Fprintf(out, "Guides matching %q:\n\n", query)
+
+		for i, g := range matchedGuides {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			printGuide(out, root, g, app)
+		}
+	}
+
+	if len(matchedCommands) > 0 {
+		if len(matchedGuides) > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "Commands matching %q:\n\n", query)
+
+		var width int
+		for _, c := range matchedCommands {
+			if l := len(c.CommandPath()); l > width {
+				width = l
+			}
+		}
+
+		for _, c := range matchedCommands {
+			fmt.Fprintf(out, "  %-*s  %s\n", width, c.CommandPath(), c.Short)
+		}
+	}
+
+	return nil
+}
+
+func printGuide(w io.Writer, root *cobra.Command, g Guide, app string) {
+	fmt.Fprintf(w, "  %s\n", g.Title)
+
+	for i, step := range g.Steps {
+		line := root.Name() + " " + step.Command
+		if step.Args != "" {
+			line += " " + step.Args
+		}
+		fmt.Fprintf(w, "    %d. %s\n", i+1, substituteApp(line, app))
+
+		note := step.Note
+		if note == "" {
+			if c := findCommand(root, step.Command); c != nil {
+				note = c.Short
+			}
+		}
+
+		if note != "" {
+			fmt.Fprintf(w, "       %s\n", note)
+		}
+	}
+}
+
+// findCommand returns the command of root at path, or nil when there's
+// none.
+func findCommand(root *cobra.Command, path string) *cobra.Command {
+	c, rest, err := root.Find(strings.Fields(path))
+	if err != nil || len(rest) > 0 || c == root {
+		return nil
+	}
+
+	return c
+}
+
+// Search returns the guides and the commands of root which match query,
+// best matches first. All of the words of query must match either.
+func Search(root *cobra.Command, query string) ([]Guide, []*cobra.Command) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	type scored struct {
+		index int
+		score int
+	}
+
+	var guideScores []scored
+	for i, g := range guides {
+		fields := []weightedText{
+			{g.Title, 3},
+			{strings.Join(g.Keywords, " "), 2},
+		}
+
+		for _, step := range g.Steps {
+			fields = append(fields, weightedText{step.Command, 1}, weightedText{step.Note, 1})
+		}
+
+		if score := match(terms, fields); score > 0 {
+			guideScores = append(guideScores, scored{i, score})
+		}
+	}
+
+	var (
+		commands      []*cobra.Command
+		commandScores []scored
+	)
+
+	walk(root, func(c *cobra.Command) {
+		fields := []weightedText{
+			{strings.TrimPrefix(c.CommandPath(), root.Name()+" "), 3},
+			{strings.Join(c.Aliases, " "), 3},
+			{c.Short, 2},
+			{c.Long, 1},
+		}
+
+		if score := match(terms, fields); score > 0 {
+			commandScores = append(commandScores, scored{len(commands), score})
+			commands = append(commands, c)
+		}
+	})
+
+	byScore := func(s []scored) func(i, j int) bool {
+		return func(i, j int) bool {
+			return s[i].score > s[j].score
+		}
+	}
+	sort.SliceStable(guideScores, byScore(guideScores))
+	sort.SliceStable(commandScores, byScore(commandScores))
+
+	matchedGuides := make([]Guide, 0, len(guideScores))
+	for _, s := range guideScores {
+		matchedGuides = append(matchedGuides, guides[s.index])
+	}
+
+	matchedCommands := make([]*cobra.Command, 0, len(commandScores))
+	for _, s := range commandScores {
+		matchedCommands = append(matchedCommands, commands[s.index])
+	}
+
+	return matchedGuides, matchedCommands
+}
+
+// walk calls fn for each of the available commands under c.
+func walk(c *cobra.Command, fn func(*cobra.Command)) {
+	for _, sub := range c.Commands() {
+		if !sub.IsAvailableCommand() {
+			continue
+		}
+
+		fn(sub)
+		walk(sub, fn)
+	}
+}
+
+type weightedText struct {
+	text   string
+	weight int
+}
+
+// match scores fields against terms. It reports 0 unless each of the terms
+// is found in at least one of the fields.
+func match(terms []string, fields []weightedText) (score int) {
+	words := make([][]string, len(fields))
+	for i, f := range fields {
+		words[i] = searchTerms(f.text)
+	}
+
+	for _, term := range terms {
+		var termScore int
+
+		for i, f := range fields {
+			for _, word := range words[i] {
+				if word == term {
+					termScore += f.weight
+
+					break
+				}
+			}
+		}
+
+		if termScore == 0 {
+			return 0
+		}
+
+		score += termScore
+	}
+
+	return
+}
+
+// stopWords are the words searches ignore.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "to": true, "of": true, "for": true,
+	"on": true, "in": true, "my": true, "i": true, "do": true, "how": true,
+	"and": true, "with": true,
+}
+
+// searchTerms splits s into lowercase words, with punctuation and plural
+// endings trimmed, so that "Domains," matches "domain".
+func searchTerms(s string) (terms []string) {
+	for _, word := range strings.FieldsFunc(strings.ToLower(s), isSeparator) {
+		if stopWords[word] {
+			continue
+		}
+
+		if len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+			word = strings.TrimSuffix(word, "s")
+		}
+
+		terms = append(terms, word)
+	}
+
+	return
+}
+
+func isSeparator(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r >= 0x80:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,99 @@
+package help
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRoot() *cobra.Command {
+	noop := func(*cobra.Command, []string) error { return nil }
+
+	root := &cobra.Command{Use: "flyctl"}
+
+	certs := &cobra.Command{Use: "certs", Short: "Manage certificates", RunE: noop}
+	certs.AddCommand(
+		&cobra.Command{Use: "add <hostname>", Short: "Add a certificate for a custom domain", RunE: noop},
+		&cobra.Command{Use: "list", Short: "List certificates", RunE: noop},
+	)
+
+	root.AddCommand(
+		certs,
+		&cobra.Command{Use: "ips", Short: "Manage IP addresses", RunE: noop},
+		&cobra.Command{Use: "deploy", Short: "Deploy an app", RunE: noop},
+		&cobra.Command{Use: "hidden", Short: "A hidden domain command", Hidden: true, RunE: noop},
+	)
+
+	return root
+}
+
+func TestSearch(t *testing.T) {
+	root := newTestRoot()
+
+	matchedGuides, matchedCommands := Search(root, "Custom Domains")
+	require.NotEmpty(t, matchedGuides)
+	assert.Equal(t, "Use a custom domain", matchedGuides[0].Title)
+
+	require.Len(t, matchedCommands, 1)
+	assert.Equal(t, "flyctl certs add", matchedCommands[0].CommandPath())
+
+	matchedGuides, matchedCommands = Search(root, "how do I get a certificate")
+	assert.Empty(t, matchedGuides, "all terms must match")
+	assert.Empty(t, matchedCommands)
+
+	matchedGuides, _ = Search(root, "ssl")
+	require.NotEmpty(t, matchedGuides)
+	assert.Equal(t, "Use a custom domain", matchedGuides[0].Title)
+
+	matchedGuides, matchedCommands = Search(root, "kubernetes")
+	assert.Empty(t, matchedGuides)
+	assert.Empty(t, matchedCommands)
+}
+
+func TestPrintGuide(t *testing.T) {
+	root := newTestRoot()
+
+	g := Guide{
+		Title: "Use a custom domain",
+		Steps: []Step{
+			{Command: "ips", Args: "-a $APP", Note: "Point DNS at these"},
+			{Command: "certs add", Args: "<hostname> -a $APP"},
+		},
+	}
+
+	var b strings.Builder
+	printGuide(&b, root, g, "my-app")
+
+	assert.Equal(t, `  Use a custom domain
+    1. flyctl ips -a my-app
+       Point DNS at these
+    2. flyctl certs add <hostname> -a my-app
+       Add a certificate for a custom domain
+`, b.String())
+}
+
+func TestExamples(t *testing.T) {
+	t.Setenv("FLY_APP", "")
+
+	cmd := &cobra.Command{
+		Use:     "status",
+		Example: "flyctl status -a $APP",
+	}
+	cmd.Flags().StringP("app", "a", "", "")
+
+	var b strings.Builder
+	require.NoError(t, Examples(&b, cmd, []string{"-a", "my-app", "--bogus"}))
+	assert.Equal(t, "flyctl status -a my-app\n", b.String())
+
+	cmd.Example = ""
+	assert.Error(t, Examples(&b, cmd, nil))
+}
+
+func TestWantsExamples(t *testing.T) {
+	assert.True(t, WantsExamples([]string{"deploy", "--examples"}))
+	assert.False(t, WantsExamples([]string{"deploy"}))
+	assert.False(t, WantsExamples([]string{"ssh", "console", "-C", "--", "--examples"}))
+}
@@ -51,8 +51,8 @@ func run(ctx context.Context) error {
 	}
 
 	out := iostreams.FromContext(ctx).Out
-	if config.FromContext(ctx).JSONOutput {
-		return render.JSON(out, changes)
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, out, changes)
 	}
 
 	var rows [][]string
@@ -0,0 +1,283 @@
+// Package hostnames implements the hostnames command chain.
+package hostnames
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/processgroup"
+)
+
+// privateDomain is the domain of the names instances resolve by on the
+// private network.
+const privateDomain = ".internal"
+
+var labelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// reservedLabels are the labels of the names the private network resolves on
+// its own, e.g. top2.nearest.of.myapp.internal.
+var reservedLabels = map[string]bool{
+	"nearest": true,
+	"global":  true,
+	"regions": true,
+	"vms":     true,
+}
+
+// New initializes and returns a new hostnames Command.
+func New() *cobra.Command {
+	const (
+		long = `Commands that manage the additional names an app, or a process group of it,
+resolves by on the private network, alongside <app>.internal.
+
+Consumers which address a service by a private hostname keep working when it
+moves to another app: the hostname is removed from the one app and added to the
+other, without changing the config of the consumers. Private hostnames resolve
+like app names do, e.g. via 'fly dig api.internal'.
+`
+		short = "Manage the private hostnames of an app"
+	)
+
+	cmd := command.New("hostnames", short, long, nil)
+
+	cmd.AddCommand(
+		newAdd(),
+		newList(),
+		newRemove(),
+	)
+
+	return cmd
+}
+
+func newAdd() *cobra.Command {
+	const (
+		long = `Add a private hostname to the app, which resolves to the addresses of its
+instances or, with --process-group, to those of the instances of the group.
+Names lacking the .internal suffix get it.
+`
+		short = "Add a private hostname to an app"
+		usage = "add <name>"
+	)
+
+	cmd := command.New(usage, short, long, runAdd,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.String{
+			Name:        "process-group",
+			Description: "Only resolve to the instances of the given process group",
+		},
+	)
+
+	cmd.Example = `fly hostnames add api.internal
+fly hostnames add queue --process-group worker`
+
+	return cmd
+}
+
+func runAdd(ctx context.Context) error {
+	name, err := normalizeName(flag.FirstArg(ctx))
+	if err != nil {
+		return err
+	}
+
+	group := flag.GetString(ctx, "process-group")
+	if err := validateProcessGroup(ctx, group); err != nil {
+		return err
+	}
+
+	appName := app.NameFromContext(ctx)
+
+	hostname, err := client.FromContext(ctx).API().AddPrivateHostname(ctx, api.AddPrivateHostnameInput{
+		AppID:        appName,
+		Name:         name,
+		ProcessGroup: group,
+	})
+	if err != nil {
+		return fmt.Errorf("failed adding private hostname %s: %w", name, err)
+	}
+
+	io := iostreams.FromContext(ctx)
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, io.Out, hostname)
+	}
+
+	target := appName
+	if hostname.ProcessGroup != "" {
+		target = fmt.Sprintf("the %s process group of %s", hostname.ProcessGroup, appName)
+	}
+
+	fmt.Fprintf(io.Out, "%s now resolves to the instances of %s; check with 'fly dig %s'\n", hostname.Name, target, hostname.Name)
+
+	return nil
+}
+
+func newList() *cobra.Command {
+	const (
+		long = `List the private hostnames of the app.
+`
+		short = "List the private hostnames of an app"
+	)
+
+	cmd := command.New("list", short, long, runList,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+	cmd.Aliases = []string{"ls"}
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func runList(ctx context.Context) error {
+	appName := app.NameFromContext(ctx)
+
+	hostnames, err := client.FromContext(ctx).API().GetPrivateHostnames(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving private hostnames of %s: %w", appName, err)
+	}
+
+	io := iostreams.FromContext(ctx)
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, io.Out, hostnames)
+	}
+
+	if len(hostnames) == 0 {
+		fmt.Fprintf(io.ErrOut, "%s has no private hostnames besides %s%s\n", appName, appName, privateDomain)
+
+		return nil
+	}
+
+	rows := make([][]string, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		group := hostname.ProcessGroup
+		if group == "" {
+			group = "all"
+		}
+
+		rows = append(rows, []string{
+			hostname.Name,
+			group,
+			humanize.Time(hostname.CreatedAt),
+		})
+	}
+
+	return render.Table(io.Out, "", rows, "Name", "Process Group", "Created At")
+}
+
+func newRemove() *cobra.Command {
+	const (
+		long = `Remove a private hostname from the app. Consumers which address the app by it
+fail to resolve it until it's added to another app.
+`
+		short = "Remove a private hostname from an app"
+		usage = "remove <name>"
+	)
+
+	cmd := command.New(usage, short, long, runRemove,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+	cmd.Aliases = []string{"rm"}
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func runRemove(ctx context.Context) error {
+	name, err := normalizeName(flag.FirstArg(ctx))
+	if err != nil {
+		return err
+	}
+
+	appName := app.NameFromContext(ctx)
+
+	input := api.RemovePrivateHostnameInput{
+		AppID: appName,
+		Name:  name,
+	}
+
+	if err := client.FromContext(ctx).API().RemovePrivateHostname(ctx, input); err != nil {
+		return fmt.Errorf("failed removing private hostname %s: %w", name, err)
+	}
+
+	fmt.Fprintf(iostreams.FromContext(ctx).Out, "Removed private hostname %s from %s\n", name, appName)
+
+	return nil
+}
+
+// normalizeName returns the fully qualified form of the given private
+// hostname, e.g. api.internal for api.
+func normalizeName(name string) (string, error) {
+	name = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+	name = strings.TrimSuffix(name, privateDomain)
+
+	if name == "" {
+		return "", errors.New("private hostnames may not be empty")
+	}
+
+	for _, label := range strings.Split(name, ".") {
+		switch {
+		case !labelPattern.MatchString(label):
+			return "", fmt.Errorf("invalid private hostname %s%s; labels consist of up to 63 letters, digits and hyphens", name, privateDomain)
+		case reservedLabels[label]:
+			return "", fmt.Errorf("invalid private hostname %s%s; %s is reserved", name, privateDomain, label)
+		}
+	}
+
+	return name + privateDomain, nil
+}
+
+// validateProcessGroup checks that the app config, if any, declares the given
+// process group.
+func validateProcessGroup(ctx context.Context, name string) error {
+	cfg := app.ConfigFromContext(ctx)
+	if name == "" || cfg == nil {
+		return nil
+	}
+
+	groups, err := processgroup.FromDefinition(cfg.Definition)
+	if err != nil || len(groups) == 0 {
+		return err
+	}
+
+	for _, group := range groups {
+		if group.Name == name {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("process group %q not found; the app config declares %s", name, strings.Join(processgroup.Names(groups), ", "))
+}
@@ -0,0 +1,30 @@
+package hostnames
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeName(t *testing.T) {
+	cases := map[string]string{
+		"api":               "api.internal",
+		"API.internal":      "api.internal",
+		"api.internal.":     "api.internal",
+		" v2.api ":          "v2.api.internal",
+		"queue-1.internal":  "queue-1.internal",
+		"a1b2.c3.internal.": "a1b2.c3.internal",
+	}
+
+	for name, expected := range cases {
+		got, err := normalizeName(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, got, name)
+	}
+
+	for _, name := range []string{"", ".internal", "-api", "api-", "api..v2", "my_api", "top2.nearest.of.api", "global.api"} {
+		_, err := normalizeName(name)
+		assert.Error(t, err, name)
+	}
+}
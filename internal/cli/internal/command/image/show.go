@@ -6,7 +6,9 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/pkg/iostreams"
+	"github.com/superfly/flyctl/pkg/machines"
 
 	"github.com/superfly/flyctl/internal/cli/internal/app"
 	"github.com/superfly/flyctl/internal/cli/internal/command"
@@ -53,25 +55,11 @@ func runShow(ctx context.Context) error {
 		return fmt.Errorf("failed to get image info: %w", err)
 	}
 
-	if cfg.JSONOutput {
-		return render.JSON(io.Out, app.ImageDetails)
+	if cfg.StructuredOutput() {
+		return render.Structured(ctx, io.Out, app.ImageDetails)
 	}
 
-	if app.ImageVersionTrackingEnabled && app.ImageUpgradeAvailable {
-		current := fmt.Sprintf("%s:%s", app.ImageDetails.Repository, app.ImageDetails.Tag)
-		latest := fmt.Sprintf("%s:%s", app.LatestImageDetails.Repository, app.LatestImageDetails.Tag)
-
-		if app.ImageDetails.Version != "" {
-			current = fmt.Sprintf("%s %s", current, app.ImageDetails.Version)
-		}
-
-		if app.LatestImageDetails.Version != "" {
-			latest = fmt.Sprintf("%s %s", latest, app.LatestImageDetails.Version)
-		}
-
-		var message = fmt.Sprintf("Update available! (%s -> %s)\n", current, latest)
-		message += "Run `flyctl image update` to migrate to the latest image version.\n"
-
+	if message := UpdateNotice(app, ""); message != "" {
 		fmt.Fprintln(io.ErrOut, colorize.Yellow(message))
 	}
 
@@ -99,3 +87,38 @@ func runShow(ctx context.Context) error {
 		"Digest",
 	)
 }
+
+// UpdateNotice returns the notice which tells that a newer version of the
+// image of the given app, as returned by GetImageInfo, is available, or an
+// empty string in case there's none. The update command it suggests names
+// appName, unless empty.
+func UpdateNotice(app *api.App, appName string) string {
+	if !app.ImageVersionTrackingEnabled || !app.ImageUpgradeAvailable {
+		return ""
+	}
+
+	current := fmt.Sprintf("%s:%s", app.ImageDetails.Repository, app.ImageDetails.Tag)
+	latest := fmt.Sprintf("%s:%s", app.LatestImageDetails.Repository, app.LatestImageDetails.Tag)
+
+	if app.ImageDetails.Version != "" {
+		current = fmt.Sprintf("%s %s", current, app.ImageDetails.Version)
+	}
+
+	if app.LatestImageDetails.Version != "" {
+		latest = fmt.Sprintf("%s %s", latest, app.LatestImageDetails.Version)
+	}
+
+	update := "flyctl image update"
+	if appName != "" {
+		update += " -a " + appName
+	}
+
+	message := fmt.Sprintf("Update available! (%s -> %s)\n", current, latest)
+	message += fmt.Sprintf("Run `%s` to migrate to the latest image version.\n", update)
+
+	if image := machines.BaseImageOf(app.LatestImageDetails.Repository); image != nil {
+		message += fmt.Sprintf("See %s for what changed.\n", image.Changelog)
+	}
+
+	return message
+}
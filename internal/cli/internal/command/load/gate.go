@@ -0,0 +1,69 @@
+package load
+
+import (
+	"context"
+	"errors"
+
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/flyerr"
+	"github.com/superfly/flyctl/internal/loadtest"
+)
+
+// gateFlagPrefix prefixes the names of the flags of the deploy command which
+// define the load test deployments are gated on.
+const gateFlagPrefix = "load-test-"
+
+// GateFlags returns the flags which define the load test Gate runs.
+func GateFlags() []flag.Flag {
+	return append([]flag.Flag{
+		flag.Int{
+			Name:        gateFlagPrefix + "rps",
+			Description: "Once deployed, load test the app at this total rate of requests per second and fail when the --load-test thresholds are exceeded",
+		},
+	}, testFlags(gateFlagPrefix)...)
+}
+
+// ValidateGate returns an error in case the GateFlags of ctx define an
+// invalid load test, so that deployments fail before they start.
+func ValidateGate(ctx context.Context) (err error) {
+	if gateEnabled(ctx) {
+		_, err = gateTest(ctx)
+	}
+
+	return
+}
+
+// Gate runs the load test the GateFlags of ctx define against the named app,
+// if any. The error it returns is a *flyerr.LoadTestError.
+func Gate(ctx context.Context, appName string) error {
+	if !gateEnabled(ctx) {
+		return nil
+	}
+
+	t, err := gateTest(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := Run(ctx, appName, t); err != nil {
+		return &flyerr.LoadTestError{Err: err}
+	}
+
+	return nil
+}
+
+func gateEnabled(ctx context.Context) bool {
+	return flag.GetInt(ctx, gateFlagPrefix+"rps") > 0
+}
+
+func gateTest(ctx context.Context) (t Test, err error) {
+	if t, err = testFromFlags(ctx, gateFlagPrefix); err != nil {
+		return
+	}
+
+	if t.Thresholds == (loadtest.Thresholds{}) {
+		err = errors.New("--load-test-rps requires --load-test-max-p99, --load-test-max-error-rate or both")
+	}
+
+	return
+}
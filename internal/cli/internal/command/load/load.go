@@ -0,0 +1,200 @@
+// Package load implements the load command chain.
+package load
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/loadtest"
+)
+
+// New initializes and returns a new load Command.
+func New() (cmd *cobra.Command) {
+	const (
+		long = `Commands that load test apps.
+`
+		short = "Load test apps"
+	)
+
+	cmd = command.New("load", short, long, nil)
+
+	cmd.AddCommand(
+		newRun(),
+		newAgent(),
+	)
+
+	return
+}
+
+func newRun() (cmd *cobra.Command) {
+	const (
+		long = `Send HTTP requests to an app at a constant rate and report on the latency
+distribution and error rate of the responses.
+
+Requests are sent from ephemeral machines, one per region, which are created
+in a temporary app and destroyed once the test finishes. The rate given is the
+total across regions. Requests which get no response, time out or are
+responded to with a 5xx status count as errors.
+
+By default the app's public URL is tested; pass --target to test another URL,
+such as a single path of the app.
+
+The command fails when the thresholds passed via --max-p99 and
+--max-error-rate are exceeded, so that it may gate pipelines. Deployments may
+be gated on a load test via the --load-test-rps flag of the deploy command,
+except for bluegreen deployments, which cut over before they could be tested.
+`
+		short = "Load test an app"
+	)
+
+	cmd = command.New("run", short, long, runRun,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Int{
+			Name:        "rps",
+			Description: "Total rate of requests to send per second",
+			Default:     10,
+		},
+	)
+	flag.Add(cmd, testFlags("")...)
+
+	return
+}
+
+func runRun(ctx context.Context) error {
+	t, err := testFromFlags(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	_, err = Run(ctx, app.NameFromContext(ctx), t)
+
+	return err
+}
+
+// testFlags returns the flags, other than the rate one, which define a Test.
+// Their names are prefixed with prefix.
+func testFlags(prefix string) []flag.Flag {
+	return []flag.Flag{
+		flag.Duration{
+			Name:        prefix + "duration",
+			Description: "How long to send requests for",
+			Default:     time.Minute,
+		},
+		flag.StringSlice{
+			Name:        prefix + "region",
+			Description: "Region to send requests from. Can be specified multiple times. Defaults to the regions of the app",
+		},
+		flag.String{
+			Name:        prefix + "target",
+			Description: "URL to send requests to. Defaults to the public URL of the app",
+		},
+		flag.Duration{
+			Name:        prefix + "max-p99",
+			Description: "Fail when the 99th percentile latency exceeds this",
+		},
+		flag.String{
+			Name:        prefix + "max-error-rate",
+			Description: "Fail when the error rate exceeds this, e.g. 1%",
+		},
+		flag.Bool{
+			Name:        prefix + "local",
+			Description: "Send requests from this machine instead of from ephemeral machines",
+		},
+		flag.String{
+			Name:        prefix + "image",
+			Description: "Image of the ephemeral machines. Must run flyctl. Defaults to the flyctl image of this version",
+		},
+	}
+}
+
+// testFromFlags returns the Test the flags of ctx define, the names of which
+// are prefixed with prefix.
+func testFromFlags(ctx context.Context, prefix string) (t Test, err error) {
+	t = Test{
+		RPS:      flag.GetInt(ctx, prefix+"rps"),
+		Duration: flag.GetDuration(ctx, prefix+"duration"),
+		Regions:  flag.GetStringSlice(ctx, prefix+"region"),
+		Target:   flag.GetString(ctx, prefix+"target"),
+		Local:    flag.GetBool(ctx, prefix+"local"),
+		Image:    flag.GetString(ctx, prefix+"image"),
+	}
+
+	t.Thresholds.MaxP99 = flag.GetDuration(ctx, prefix+"max-p99")
+
+	if s := flag.GetString(ctx, prefix+"max-error-rate"); s != "" {
+		if t.Thresholds.MaxErrorRate, err = loadtest.ParseRate(s); err != nil {
+			err = fmt.Errorf("invalid --%smax-error-rate: %w", prefix, err)
+		}
+	}
+
+	return
+}
+
+func newAgent() (cmd *cobra.Command) {
+	const (
+		short = "Send requests on behalf of a load test"
+		long  = short + "\n"
+	)
+
+	cmd = command.New("agent", short, long, runAgent)
+
+	cmd.Args = cobra.NoArgs
+	cmd.Hidden = true
+
+	flag.Add(cmd,
+		flag.String{
+			Name:        "url",
+			Description: "URL to send requests to",
+		},
+		flag.Int{
+			Name:        "rps",
+			Description: "Rate of requests to send per second",
+		},
+		flag.Duration{
+			Name:        "duration",
+			Description: "How long to send requests for",
+		},
+	)
+
+	return
+}
+
+// runAgent runs on the ephemeral machines of load tests and prints their
+// report on a single line, which Run picks up from their logs.
+func runAgent(ctx context.Context) error {
+	report, err := loadtest.Run(ctx, loadtest.Options{
+		URL:      flag.GetString(ctx, "url"),
+		RPS:      flag.GetInt(ctx, "rps"),
+		Duration: flag.GetDuration(ctx, "duration"),
+	})
+	if err != nil {
+		return err
+	}
+	report.Region = os.Getenv("FLY_REGION")
+
+	line, err := report.MarshalLine()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(iostreams.FromContext(ctx).Out, line)
+
+	return nil
+}
@@ -0,0 +1,385 @@
+package load
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+	"github.com/superfly/flyctl/pkg/logs"
+
+	"github.com/superfly/flyctl/internal/buildinfo"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/loadtest"
+	"github.com/superfly/flyctl/internal/logger"
+)
+
+const (
+	// reportTimeout is how long after a test should have finished its
+	// ephemeral machines have to report before Run gives up on them.
+	reportTimeout = 3 * time.Minute
+
+	cleanupTimeout = time.Minute
+)
+
+// Test wraps the parameters of a load test.
+type Test struct {
+	// Target denotes the URL to send requests to. Defaults to the public URL
+	// of the app.
+	Target string
+
+	// RPS denotes the total rate of requests per second to send.
+	RPS int
+
+	Duration time.Duration
+
+	// Regions denotes the regions to send requests from. Defaults to the
+	// regions of the app.
+	Regions []string
+
+	// Local instructs Run to send requests from this machine.
+	Local bool
+
+	// Image denotes the image of the ephemeral machines requests are sent
+	// from. Defaults to the flyctl image of this version.
+	Image string
+
+	Thresholds loadtest.Thresholds
+}
+
+// Run runs t against the named app, renders its report and checks it against
+// the thresholds of t.
+func Run(ctx context.Context, appName string, t Test) (*loadtest.Report, error) {
+	client := client.FromContext(ctx).API()
+
+	if t.RPS <= 0 {
+		return nil, errors.New("the rate of requests must be positive")
+	}
+
+	if t.Duration <= 0 {
+		return nil, errors.New("the duration must be positive")
+	}
+
+	if t.Target == "" {
+		app, err := client.GetAppCompact(ctx, appName)
+		if err != nil {
+			return nil, fmt.Errorf("failed retrieving app %s: %w", appName, err)
+		}
+
+		t.Target = "https://" + app.Hostname
+	}
+
+	var (
+		reports []*loadtest.Report
+		err     error
+	)
+
+	if t.Local {
+		reports, err = runLocal(ctx, t)
+	} else {
+		reports, err = runRemote(ctx, appName, t)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	total := loadtest.Merge(reports...)
+
+	if err := renderReports(ctx, t, reports, total); err != nil {
+		return nil, err
+	}
+
+	return total, t.Thresholds.Check(total)
+}
+
+func runLocal(ctx context.Context, t Test) ([]*loadtest.Report, error) {
+	tb := render.NewTextBlock(ctx, fmt.Sprintf("Sending %d requests per second to %s for %s", t.RPS, t.Target, t.Duration))
+
+	report, err := loadtest.Run(ctx, loadtest.Options{
+		URL:      t.Target,
+		RPS:      t.RPS,
+		Duration: t.Duration,
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Region = "local"
+
+	tb.Done("Load test finished")
+
+	return []*loadtest.Report{report}, nil
+}
+
+// runRemote sends requests from an ephemeral machine per region, which it
+// creates in a temporary app. Machines print their reports in their logs.
+func runRemote(ctx context.Context, appName string, t Test) (reports []*loadtest.Report, err error) {
+	client := client.FromContext(ctx).API()
+
+	regions := t.Regions
+	if len(regions) == 0 {
+		if regions, err = appRegions(ctx, client, appName); err != nil {
+			return
+		}
+	}
+
+	rates, err := splitRate(t.RPS, len(regions))
+	if err != nil {
+		return
+	}
+
+	app, err := client.GetAppCompact(ctx, appName)
+	if err != nil {
+		err = fmt.Errorf("failed retrieving app %s: %w", appName, err)
+
+		return
+	}
+
+	org, err := client.GetOrganizationBySlug(ctx, app.Organization.Slug)
+	if err != nil {
+		err = fmt.Errorf("failed retrieving organization %s: %w", app.Organization.Slug, err)
+
+		return
+	}
+
+	tb := render.NewTextBlock(ctx, fmt.Sprintf("Launching load generators in %d regions", len(regions)))
+
+	var (
+		loadApp  string
+		launched []string
+	)
+	defer func() {
+		cleanup(ctx, client, loadApp, launched)
+	}()
+
+	for i, region := range regions {
+		input := api.LaunchMachineInput{
+			AppID:   loadApp,
+			OrgSlug: org.ID,
+			Region:  region,
+			Config:  agentConfig(t, rates[i]),
+		}
+
+		machine, app, err := client.LaunchMachine(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed launching load generator in %s: %w", region, err)
+		}
+
+		if loadApp == "" {
+			loadApp = app.Name
+		}
+		launched = append(launched, machine.ID)
+
+		tb.Detailf("Launched load generator %s in %s sending %d requests per second", machine.ID, region, rates[i])
+	}
+
+	tb.Donef("Sending %d requests per second to %s for %s", t.RPS, t.Target, t.Duration)
+
+	timeout := t.Duration + reportTimeout
+
+	return collectReports(ctx, client, loadApp, launched, timeout)
+}
+
+func appRegions(ctx context.Context, client *api.Client, appName string) ([]string, error) {
+	regions, _, err := client.ListAppRegions(ctx, appName)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving regions of %s: %w", appName, err)
+	}
+
+	codes := make([]string, 0, len(regions))
+	for _, region := range regions {
+		codes = append(codes, region.Code)
+	}
+
+	if len(codes) == 0 {
+		return nil, errors.New("the regions to send requests from must be specified, since the app has none")
+	}
+
+	return codes, nil
+}
+
+// splitRate splits rps evenly into n rates, the first of which get any
+// remainder.
+func splitRate(rps, n int) ([]int, error) {
+	if rps < n {
+		return nil, fmt.Errorf("the rate of %d requests per second can't be split across %d regions", rps, n)
+	}
+
+	rates := make([]int, n)
+	for i := range rates {
+		rates[i] = rps / n
+		if i < rps%n {
+			rates[i]++
+		}
+	}
+
+	for _, rate := range rates {
+		if rate > loadtest.MaxRPS {
+			return nil, fmt.Errorf("the rate per region may not exceed %d requests per second; add regions", loadtest.MaxRPS)
+		}
+	}
+
+	return rates, nil
+}
+
+func agentConfig(t Test, rps int) *api.MachineConfig {
+	image := t.Image
+	if image == "" {
+		image = defaultImage()
+	}
+
+	return &api.MachineConfig{
+		Image: image,
+		Init: api.MachineInit{
+			Cmd: []string{
+				"load", "agent",
+				"--url", t.Target,
+				"--rps", strconv.Itoa(rps),
+				"--duration", t.Duration.String(),
+			},
+		},
+		Env: map[string]string{
+			"FLY_NO_UPDATE_CHECK": "1",
+		},
+		Restart: api.MachineRestart{
+			Policy: api.MachineRestartPolicyNo,
+		},
+	}
+}
+
+func defaultImage() string {
+	if buildinfo.IsDev() {
+		return "flyio/flyctl:latest"
+	}
+
+	return "flyio/flyctl:v" + buildinfo.Version().String()
+}
+
+// collectReports waits for each of the given machines of the app to print
+// its report in its logs.
+func collectReports(ctx context.Context, client *api.Client, appName string, ids []string, timeout time.Duration) (reports []*loadtest.Report, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	opts := &logs.LogOptions{
+		AppName: appName,
+	}
+
+	stream, err := logs.NewNatsStream(ctx, client, opts)
+	if err != nil {
+		logger.FromContext(ctx).Debugf("could not connect to wireguard tunnel, falling back to log polling: %v", err)
+
+		if stream, err = logs.NewPollingStream(ctx, client, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	// the app holds nothing but the load generators, so each instance which
+	// reports is one of them
+	reported := make(map[string]bool, len(ids))
+
+	entries := stream.Stream(ctx, opts)
+	for len(reported) < len(ids) {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for %d load generators to report", len(ids)-len(reported))
+		case entry, ok := <-entries:
+			if !ok {
+				if err := stream.Err(); err != nil {
+					return nil, fmt.Errorf("failed streaming the logs of the load generators: %w", err)
+				}
+
+				return nil, fmt.Errorf("the logs of the load generators ended before %d of them reported", len(ids)-len(reported))
+			}
+
+			report, err := loadtest.ParseLine(entry.Message)
+			if err != nil {
+				return nil, err
+			} else if report == nil || reported[entry.Instance] {
+				continue
+			}
+			reported[entry.Instance] = true
+
+			if report.Region == "" {
+				report.Region = entry.Region
+			}
+			reports = append(reports, report)
+		}
+	}
+
+	return
+}
+
+// cleanup destroys the given machines of the temporary app, and the app
+// itself.
+func cleanup(ctx context.Context, client *api.Client, appName string, ids []string) {
+	if appName == "" {
+		return
+	}
+
+	io := iostreams.FromContext(ctx)
+
+	// cleanup has to happen even when ctx is cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	for _, id := range ids {
+		input := api.RemoveMachineInput{
+			AppID: appName,
+			ID:    id,
+			Kill:  true,
+		}
+
+		if _, err := client.RemoveMachine(ctx, input); err != nil {
+			fmt.Fprintf(io.ErrOut, "failed destroying load generator %s: %v\n", id, err)
+		}
+	}
+
+	if err := client.DeleteApp(ctx, appName); err != nil {
+		fmt.Fprintf(io.ErrOut, "failed destroying the app of the load generators, %s: %v\n", appName, err)
+	}
+}
+
+func renderReports(ctx context.Context, t Test, reports []*loadtest.Report, total *loadtest.Report) error {
+	out := iostreams.FromContext(ctx).Out
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, struct {
+			Target  string             `json:"target"`
+			Regions []*loadtest.Report `json:"regions"`
+			Total   *loadtest.Report   `json:"total"`
+		}{t.Target, reports, total})
+	}
+
+	rows := make([][]string, 0, len(reports)+1)
+	for _, r := range reports {
+		rows = append(rows, reportRow(r.Region, r))
+	}
+	if len(reports) > 1 {
+		rows = append(rows, reportRow("total", total))
+	}
+
+	return render.Table(out, "", rows, "Region", "Requests", "RPS", "Errors", "p50", "p90", "p99", "Max", "Statuses")
+}
+
+func reportRow(region string, r *loadtest.Report) []string {
+	ms := func(d time.Duration) string {
+		return d.Round(time.Millisecond).String()
+	}
+
+	return []string{
+		region,
+		strconv.Itoa(r.Requests),
+		strconv.FormatFloat(r.RPS(), 'f', 1, 64),
+		loadtest.FormatRate(r.ErrorRate()),
+		ms(r.Latency.Quantile(.5)),
+		ms(r.Latency.Quantile(.9)),
+		ms(r.Latency.Quantile(.99)),
+		ms(r.Latency.Max),
+		r.StatusSummary(),
+	}
+}
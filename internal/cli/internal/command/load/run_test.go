@@ -0,0 +1,26 @@
+package load
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/internal/loadtest"
+)
+
+func TestSplitRate(t *testing.T) {
+	rates, err := splitRate(200, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []int{67, 67, 66}, rates)
+
+	rates, err = splitRate(10, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int{10}, rates)
+
+	_, err = splitRate(2, 3)
+	assert.Error(t, err)
+
+	_, err = splitRate(loadtest.MaxRPS+1, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,461 @@
+package logs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/superfly/flyctl/pkg/logs"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+func newExport() (cmd *cobra.Command) {
+	const (
+		long = `Export application logs to local files until interrupted.
+
+Logs are written as JSON lines to files named after the app and the time they
+were started at. Files are rotated once they reach --max-size megabytes or
+--max-age minutes and, unless --no-compress is set, gzipped once rotated.
+Entries are written in batches and files are only rotated between batches, so
+that no entry is ever split across files.
+`
+		short = "Export app logs to rotated local files"
+	)
+
+	cmd = command.New("export", short, long, runExport,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Region(),
+		flag.String{
+			Name:        "instance",
+			Shorthand:   "i",
+			Description: "Filter by instance ID",
+		},
+		flag.String{
+			Name:        "dir",
+			Shorthand:   "d",
+			Description: "Directory to write the log files to",
+			Default:     ".",
+		},
+		flag.Int{
+			Name:        "max-size",
+			Description: "Megabytes after which log files are rotated",
+			Default:     100,
+		},
+		flag.Int{
+			Name:        "max-age",
+			Description: "Minutes after which log files are rotated",
+			Default:     60,
+		},
+		flag.Int{
+			Name:        "max-files",
+			Description: "Number of log files to keep, oldest first to go; 0 keeps all of them",
+		},
+		flag.Bool{
+			Name:        "no-compress",
+			Description: "Do not gzip rotated log files",
+		},
+	)
+
+	return
+}
+
+const (
+	exportBatchSize     = 500
+	exportFlushInterval = time.Second
+)
+
+func runExport(ctx context.Context) (err error) {
+	appName := app.NameFromContext(ctx)
+	client := client.FromContext(ctx).API()
+
+	maxSize, maxAge := flag.GetInt(ctx, "max-size"), flag.GetInt(ctx, "max-age")
+	if maxSize < 1 || maxAge < 1 {
+		return errors.New("--max-size and --max-age must be positive")
+	}
+
+	dir := flag.GetString(ctx, "dir")
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed creating %s: %w", dir, err)
+	}
+
+	w := &rotatingWriter{
+		dir:      dir,
+		prefix:   appName,
+		maxSize:  int64(maxSize) << 20,
+		maxAge:   time.Duration(maxAge) * time.Minute,
+		maxFiles: flag.GetInt(ctx, "max-files"),
+		compress: !flag.GetBool(ctx, "no-compress"),
+		now:      time.Now,
+	}
+	defer func() {
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	opts := &logs.LogOptions{
+		AppName:    appName,
+		RegionCode: config.FromContext(ctx).Region,
+		VMID:       flag.GetString(ctx, "instance"),
+	}
+
+	tb := render.NewTextBlock(ctx, fmt.Sprintf("Exporting logs of %s to %s", appName, dir))
+	tb.Detail("press Ctrl+C to stop")
+
+	var eg *errgroup.Group
+	eg, ctx = errgroup.WithContext(ctx)
+
+	pollingCtx, cancelPolling := context.WithCancel(ctx)
+	pollEntries := poll(pollingCtx, eg, client, opts)
+	liveEntries := nats(ctx, eg, client, opts, cancelPolling)
+
+	eg.Go(func() error {
+		return exportStreams(ctx, w, pollEntries, liveEntries)
+	})
+
+	// interrupting the export is how it's meant to end
+	if err = eg.Wait(); errors.Is(err, context.Canceled) {
+		err = nil
+	}
+
+	if err == nil {
+		tb.Donef("exported %d log entries to %d files", w.entries, w.files)
+	}
+
+	return
+}
+
+// exportStreams batches the entries of the given streams into w until they
+// are all closed or ctx is done, in which case the pending batch is written.
+func exportStreams(ctx context.Context, w *rotatingWriter, streams ...<-chan logs.LogEntry) error {
+	entries := make(chan logs.LogEntry)
+
+	var eg errgroup.Group
+	for _, stream := range streams {
+		stream := stream
+
+		eg.Go(func() error {
+			for entry := range stream {
+				select {
+				case entries <- entry:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+
+			return nil
+		})
+	}
+
+	go func() {
+		_ = eg.Wait()
+		close(entries)
+	}()
+
+	ticker := time.NewTicker(exportFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, exportBatchSize)
+	flush := func() (err error) {
+		if len(batch) > 0 {
+			err = w.WriteBatch(batch)
+			batch = batch[:0]
+		}
+
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := flush(); err != nil {
+				return err
+			}
+
+			return ctx.Err()
+		case <-ticker.C:
+			if err := flush(); err != nil {
+				return err
+			}
+		case entry, ok := <-entries:
+			if !ok {
+				return flush()
+			}
+
+			line, err := json.Marshal(entry)
+			if err != nil {
+				return fmt.Errorf("failed encoding log entry: %w", err)
+			}
+
+			if batch = append(batch, line); len(batch) >= exportBatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// rotatingWriter writes batches of lines to files it rotates by size and age.
+type rotatingWriter struct {
+	dir      string
+	prefix   string
+	maxSize  int64
+	maxAge   time.Duration
+	maxFiles int
+	compress bool
+	now      func() time.Time
+
+	f      *os.File
+	buf    *bufio.Writer
+	size   int64
+	opened time.Time
+
+	entries int
+	files   int
+}
+
+const (
+	exportFileExt = ".ndjson"
+	gzipExt       = ".gz"
+)
+
+// WriteBatch writes the given lines to the current file, rotating it first in
+// case it's due. Batches are never split across files.
+func (w *rotatingWriter) WriteBatch(lines [][]byte) error {
+	var size int64
+	for _, line := range lines {
+		size += int64(len(line)) + 1
+	}
+
+	if w.f != nil && w.size > 0 && (w.size+size > w.maxSize || w.now().Sub(w.opened) >= w.maxAge) {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+
+	if w.f == nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+
+	for _, line := range lines {
+		if _, err := w.buf.Write(line); err != nil {
+			return err
+		}
+		if err := w.buf.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+
+	// flushing per batch makes sure files end in full lines between batches
+	if err := w.buf.Flush(); err != nil {
+		return fmt.Errorf("failed writing %s: %w", w.f.Name(), err)
+	}
+
+	w.size += size
+	w.entries += len(lines)
+
+	return nil
+}
+
+// Close closes and finalizes the current file.
+func (w *rotatingWriter) Close() error {
+	if w.f == nil {
+		return nil
+	}
+
+	return w.rotate()
+}
+
+func (w *rotatingWriter) open() (err error) {
+	now := w.now().UTC()
+	base := fmt.Sprintf("%s-%s", w.prefix, now.Format(exportTimeFormat))
+
+	// files may be rotated more than once a millisecond
+	path := filepath.Join(w.dir, base+exportFileExt)
+	for i := 1; exists(path) || exists(path+gzipExt); i++ {
+		path = filepath.Join(w.dir, fmt.Sprintf("%s-%d%s", base, i, exportFileExt))
+	}
+
+	if w.f, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600); err != nil {
+		return fmt.Errorf("failed creating log file: %w", err)
+	}
+
+	w.buf = bufio.NewWriter(w.f)
+	w.size = 0
+	w.opened = now
+	w.files++
+
+	return nil
+}
+
+func (w *rotatingWriter) rotate() error {
+	path := w.f.Name()
+
+	err := w.f.Close()
+	w.f, w.buf = nil, nil
+	if err != nil {
+		return fmt.Errorf("failed closing %s: %w", path, err)
+	}
+
+	if w.compress {
+		if err := gzipFile(path); err != nil {
+			return fmt.Errorf("failed compressing %s: %w", path, err)
+		}
+	}
+
+	return w.prune()
+}
+
+// exportTimeFormat is the format of the timestamps log files are named after.
+const exportTimeFormat = "20060102T150405.000Z"
+
+// exportFile wraps a log file of the writer, as named by openFile.
+type exportFile struct {
+	path string
+	at   time.Time
+	seq  int // distinguishes the files rotated within the same millisecond
+}
+
+// exportFiles returns the log files of the writer from oldest to newest. Only
+// files named exactly as openFile names them count, so that the files of apps
+// the prefix of which starts with the writer's are left alone.
+func (w *rotatingWriter) exportFiles() ([]exportFile, error) {
+	re := regexp.MustCompile(`^` + regexp.QuoteMeta(w.prefix) + `-(\d{8}T\d{6}\.\d{3}Z)(?:-(\d+))?` +
+		regexp.QuoteMeta(exportFileExt) + `(?:` + regexp.QuoteMeta(gzipExt) + `)?$`)
+
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []exportFile
+	for _, e := range entries {
+		m := re.FindStringSubmatch(e.Name())
+		if m == nil || e.IsDir() {
+			continue
+		}
+
+		at, err := time.Parse(exportTimeFormat, m[1])
+		if err != nil {
+			continue
+		}
+
+		seq, _ := strconv.Atoi(m[2])
+
+		files = append(files, exportFile{
+			path: filepath.Join(w.dir, e.Name()),
+			at:   at,
+			seq:  seq,
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].at.Equal(files[j].at) {
+			return files[i].at.Before(files[j].at)
+		}
+
+		return files[i].seq < files[j].seq
+	})
+
+	return files, nil
+}
+
+// prune removes the oldest log files beyond the ones to keep.
+func (w *rotatingWriter) prune() error {
+	if w.maxFiles < 1 {
+		return nil
+	}
+
+	files, err := w.exportFiles()
+	if err != nil {
+		return err
+	}
+
+	for len(files) > w.maxFiles {
+		if err := os.Remove(files[0].path); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+
+	return nil
+}
+
+// gzipFile replaces the file at the given path with a gzipped copy. The copy
+// only takes the name of the file once it's complete.
+func gzipFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer src.Close()
+
+	tmp := path + gzipExt + ".tmp"
+
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	zw.Name = filepath.Base(path)
+
+	if _, err = io.Copy(zw, src); err == nil {
+		err = zw.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+
+	if err = os.Rename(tmp, path+gzipExt); err != nil {
+		return
+	}
+
+	_ = src.Close()
+
+	return os.Remove(path)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+
+	return err == nil
+}
@@ -0,0 +1,151 @@
+package logs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingWriter(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	w := &rotatingWriter{
+		dir:      dir,
+		prefix:   "app",
+		maxSize:  10,
+		maxAge:   time.Minute,
+		compress: true,
+		now:      func() time.Time { return now },
+	}
+
+	// batches are kept together even when they exceed the max size
+	require.NoError(t, w.WriteBatch([][]byte{[]byte("aaaa"), []byte("bbbb"), []byte("cccc")}))
+
+	// rotated by size
+	now = now.Add(time.Second)
+	require.NoError(t, w.WriteBatch([][]byte{[]byte("dd")}))
+
+	// rotated by age
+	now = now.Add(time.Minute)
+	require.NoError(t, w.WriteBatch([][]byte{[]byte("ee")}))
+
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, 5, w.entries)
+	assert.Equal(t, 3, w.files)
+
+	files := listFiles(t, dir)
+	assert.Equal(t, []string{
+		"app-20220301T120000.000Z.ndjson.gz",
+		"app-20220301T120001.000Z.ndjson.gz",
+		"app-20220301T120101.000Z.ndjson.gz",
+	}, files)
+
+	assert.Equal(t, "aaaa\nbbbb\ncccc\n", gunzip(t, filepath.Join(dir, files[0])))
+	assert.Equal(t, "dd\n", gunzip(t, filepath.Join(dir, files[1])))
+	assert.Equal(t, "ee\n", gunzip(t, filepath.Join(dir, files[2])))
+}
+
+func TestRotatingWriterPrunes(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	w := &rotatingWriter{
+		dir:      dir,
+		prefix:   "app",
+		maxSize:  1,
+		maxAge:   time.Hour,
+		maxFiles: 2,
+		now:      func() time.Time { return now },
+	}
+
+	for _, line := range []string{"1", "2", "3", "4"} {
+		require.NoError(t, w.WriteBatch([][]byte{[]byte(line)}))
+
+		now = now.Add(time.Millisecond)
+	}
+	require.NoError(t, w.Close())
+
+	files := listFiles(t, dir)
+	require.Len(t, files, 2)
+
+	data, err := os.ReadFile(filepath.Join(dir, files[1]))
+	require.NoError(t, err)
+	assert.Equal(t, "4\n", string(data))
+}
+
+func TestRotatingWriterPrunesOwnFilesOnly(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{
+		"app-staging-20220301T110000.000Z.ndjson", // another app's
+		"app-notes.ndjson",
+		"app-20220301T120000.000Z-1.ndjson.gz", // rotated within the same millisecond
+		"app-20220301T120000.000Z.ndjson.gz",
+		"app-20220301T115959.999Z.ndjson",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+
+	w := &rotatingWriter{dir: dir, prefix: "app", maxFiles: 2}
+
+	files, err := w.exportFiles()
+	require.NoError(t, err)
+
+	var names []string
+	for _, f := range files {
+		names = append(names, filepath.Base(f.path))
+	}
+	assert.Equal(t, []string{
+		"app-20220301T115959.999Z.ndjson",
+		"app-20220301T120000.000Z.ndjson.gz",
+		"app-20220301T120000.000Z-1.ndjson.gz",
+	}, names)
+
+	require.NoError(t, w.prune())
+
+	assert.Equal(t, []string{
+		"app-20220301T120000.000Z-1.ndjson.gz",
+		"app-20220301T120000.000Z.ndjson.gz",
+		"app-notes.ndjson",
+		"app-staging-20220301T110000.000Z.ndjson",
+	}, listFiles(t, dir))
+}
+
+func listFiles(t *testing.T, dir string) (names []string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	return
+}
+
+func gunzip(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	out, err := io.ReadAll(zr)
+	require.NoError(t, err)
+
+	return string(out)
+}
@@ -0,0 +1,204 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/superfly/flyctl/pkg/logs"
+)
+
+// fieldFilter matches the log entries the field at path of which equals value.
+type fieldFilter struct {
+	path  []string
+	value string
+}
+
+func parseFieldFilters(specs []string) (filters []fieldFilter, err error) {
+	for _, spec := range specs {
+		i := strings.Index(spec, "=")
+		if i < 1 {
+			err = fmt.Errorf("invalid field filter %q; filters must be of the form name=value", spec)
+
+			return
+		}
+
+		filters = append(filters, fieldFilter{
+			path:  strings.Split(spec[:i], "."),
+			value: spec[i+1:],
+		})
+	}
+
+	return
+}
+
+// entryView filters log entries by the fields of their JSON messages and
+// projects these messages to a subset of their fields.
+type entryView struct {
+	filters []fieldFilter
+	fields  []string
+}
+
+// empty reports whether v neither filters nor projects entries.
+func (v *entryView) empty() bool {
+	return len(v.filters) == 0 && len(v.fields) == 0
+}
+
+// apply returns whether the given entry passes the filters of v along with
+// the fields of its message v projects it to, in order. Fields are nil for
+// entries v doesn't project.
+//
+// Filters match the fields of JSON messages first and the level, region and
+// instance of the entry second. Entries with messages which are not JSON
+// objects only pass filters on the latter.
+func (v *entryView) apply(entry logs.LogEntry) (ok bool, fields []projectedField) {
+	msg := parseMessage(entry.Message)
+
+	for _, f := range v.filters {
+		value, found := lookupField(msg, f.path)
+		if !found {
+			value, found = entryField(entry, f.path)
+		}
+
+		if !found || stringify(value) != f.value {
+			return
+		}
+	}
+
+	if msg != nil {
+		for _, name := range v.fields {
+			if value, found := lookupField(msg, strings.Split(name, ".")); found {
+				fields = append(fields, projectedField{name, value})
+			}
+		}
+	}
+
+	return true, fields
+}
+
+type projectedField struct {
+	name  string
+	value interface{}
+}
+
+// logfmt renders the given fields as space separated name=value pairs.
+func logfmt(fields []projectedField) string {
+	var b strings.Builder
+
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+
+		value := stringify(f.value)
+		if value == "" || strings.ContainsAny(value, " \"=\t\n") {
+			value = strconv.Quote(value)
+		}
+
+		fmt.Fprintf(&b, "%s=%s", f.name, value)
+	}
+
+	return b.String()
+}
+
+// compactJSON renders the given fields as a JSON object, preserving their
+// order.
+func compactJSON(fields []projectedField) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		name, err := json.Marshal(f.name)
+		if err != nil {
+			return "", err
+		}
+
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return "", err
+		}
+
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.String(), nil
+}
+
+// parseMessage returns the fields of the given message, or nil in case it's
+// not a JSON object.
+func parseMessage(msg string) map[string]interface{} {
+	if msg = strings.TrimSpace(msg); !strings.HasPrefix(msg, "{") {
+		return nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(msg))
+	dec.UseNumber()
+
+	var fields map[string]interface{}
+	if err := dec.Decode(&fields); err != nil {
+		return nil
+	}
+
+	return fields
+}
+
+func lookupField(fields map[string]interface{}, path []string) (interface{}, bool) {
+	var v interface{} = fields
+
+	for _, name := range path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		if v, ok = m[name]; !ok {
+			return nil, false
+		}
+	}
+
+	return v, true
+}
+
+func entryField(entry logs.LogEntry, path []string) (string, bool) {
+	if len(path) != 1 {
+		return "", false
+	}
+
+	switch path[0] {
+	case "level":
+		return entry.Level, true
+	case "region":
+		return entry.Region, true
+	case "instance":
+		return entry.Instance, true
+	default:
+		return "", false
+	}
+}
+
+func stringify(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, _ := json.Marshal(v)
+
+		return string(data)
+	}
+}
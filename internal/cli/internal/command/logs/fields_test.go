@@ -0,0 +1,70 @@
+package logs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/pkg/logs"
+)
+
+func TestParseFieldFilters(t *testing.T) {
+	filters, err := parseFieldFilters([]string{"level=error", "req.id=a=b", "empty="})
+	require.NoError(t, err)
+	assert.Equal(t, []fieldFilter{
+		{path: []string{"level"}, value: "error"},
+		{path: []string{"req", "id"}, value: "a=b"},
+		{path: []string{"empty"}, value: ""},
+	}, filters)
+
+	_, err = parseFieldFilters([]string{"=error"})
+	assert.Error(t, err)
+
+	_, err = parseFieldFilters([]string{"level"})
+	assert.Error(t, err)
+}
+
+func TestEntryView(t *testing.T) {
+	filters, err := parseFieldFilters([]string{"level=error", "req.status=500"})
+	require.NoError(t, err)
+
+	view := entryView{
+		filters: filters,
+		fields:  []string{"msg", "req.status", "missing"},
+	}
+
+	entry := logs.LogEntry{
+		Level:   "info",
+		Message: `{"level":"error","msg":"boom","req":{"status":500,"path":"/"}}`,
+	}
+
+	ok, fields := view.apply(entry)
+	require.True(t, ok)
+	assert.Equal(t, `msg=boom req.status=500`, logfmt(fields))
+
+	out, err := compactJSON(fields)
+	require.NoError(t, err)
+	assert.Equal(t, `{"msg":"boom","req.status":500}`, out)
+
+	entry.Message = `{"level":"warn","req":{"status":500}}`
+	ok, _ = view.apply(entry)
+	assert.False(t, ok)
+
+	// non JSON messages fall back to the level of their entries
+	view = entryView{filters: filters[:1], fields: []string{"msg"}}
+	entry = logs.LogEntry{Level: "error", Message: "plain text"}
+
+	ok, fields = view.apply(entry)
+	assert.True(t, ok)
+	assert.Nil(t, fields)
+}
+
+func TestLogfmtQuoting(t *testing.T) {
+	assert.Equal(t, `a="two words" b="" c=null d=true`, logfmt([]projectedField{
+		{"a", "two words"},
+		{"b", ""},
+		{"c", nil},
+		{"d", true},
+	}))
+}
@@ -0,0 +1,117 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/pkg/logs"
+)
+
+// levels ranks the levels of log entries by severity.
+var levels = map[string]int{
+	"trace":    0,
+	"debug":    1,
+	"info":     2,
+	"warn":     3,
+	"warning":  3,
+	"error":    4,
+	"err":      4,
+	"fatal":    5,
+	"critical": 5,
+}
+
+// entryFilter matches log entries by the time they were logged at, their
+// level and their message. Zero values match all entries.
+type entryFilter struct {
+	since, until time.Time
+
+	// minLevel denotes the rank of the least severe level entries may have.
+	// Entries of unknown levels always match.
+	minLevel int
+
+	grep *regexp.Regexp
+}
+
+func newEntryFilter(since, until, level, grep string, now time.Time) (f entryFilter, err error) {
+	if since != "" {
+		if f.since, err = parseTime(since, now); err != nil {
+			err = fmt.Errorf("invalid --since: %w", err)
+
+			return
+		}
+	}
+
+	if until != "" {
+		if f.until, err = parseTime(until, now); err != nil {
+			err = fmt.Errorf("invalid --until: %w", err)
+
+			return
+		}
+	}
+
+	if !f.since.IsZero() && !f.until.IsZero() && !f.until.After(f.since) {
+		err = errors.New("--until must be later than --since")
+
+		return
+	}
+
+	if level != "" {
+		var ok bool
+		if f.minLevel, ok = levels[strings.ToLower(level)]; !ok {
+			err = fmt.Errorf("invalid --level %q; expected one of trace, debug, info, warn, error or fatal", level)
+
+			return
+		}
+	}
+
+	if grep != "" {
+		if f.grep, err = regexp.Compile(grep); err != nil {
+			err = fmt.Errorf("invalid --grep: %w", err)
+		}
+	}
+
+	return
+}
+
+// parseTime parses the given time, given either as a duration before now,
+// e.g. 1h30m, as an RFC 3339 timestamp or as a date.
+func parseTime(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("duration %q is negative", s)
+		}
+
+		return now.Add(-d), nil
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%q is neither a duration, such as 30m, nor a timestamp, such as 2022-03-01T15:04:05Z", s)
+}
+
+// match reports whether the given entry passes f. Entries the timestamp of
+// which can't be parsed pass the time filters.
+func (f *entryFilter) match(entry logs.LogEntry) bool {
+	if !f.since.IsZero() || !f.until.IsZero() {
+		if t, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err == nil {
+			if t.Before(f.since) || (!f.until.IsZero() && t.After(f.until)) {
+				return false
+			}
+		}
+	}
+
+	if f.minLevel > 0 {
+		if rank, ok := levels[strings.ToLower(entry.Level)]; ok && rank < f.minLevel {
+			return false
+		}
+	}
+
+	return f.grep == nil || f.grep.MatchString(entry.Message)
+}
@@ -0,0 +1,88 @@
+package logs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/pkg/logs"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		s        string
+		expected time.Time
+	}{
+		{"90m", now.Add(-90 * time.Minute)},
+		{"2022-03-01T10:30:00Z", time.Date(2022, 3, 1, 10, 30, 0, 0, time.UTC)},
+		{"2022-03-01T10:30", time.Date(2022, 3, 1, 10, 30, 0, 0, time.UTC)},
+		{"2022-02-28", time.Date(2022, 2, 28, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		got, err := parseTime(test.s, now)
+		require.NoError(t, err, test.s)
+		assert.Equal(t, test.expected, got, test.s)
+	}
+
+	for _, s := range []string{"-1h", "yesterday"} {
+		_, err := parseTime(s, now)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestEntryFilter(t *testing.T) {
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	f, err := newEntryFilter("1h", "10m", "warn", "time(out|d out)", now)
+	require.NoError(t, err)
+
+	entry := logs.LogEntry{
+		Level:     "error",
+		Message:   "request timed out",
+		Timestamp: "2022-03-01T11:30:00.123Z",
+	}
+	assert.True(t, f.match(entry))
+
+	tooOld := entry
+	tooOld.Timestamp = "2022-03-01T10:59:59Z"
+	assert.False(t, f.match(tooOld))
+
+	tooNew := entry
+	tooNew.Timestamp = "2022-03-01T11:55:00Z"
+	assert.False(t, f.match(tooNew))
+
+	info := entry
+	info.Level = "info"
+	assert.False(t, f.match(info))
+
+	// entries of unknown levels are kept
+	unknown := entry
+	unknown.Level = "notice"
+	assert.True(t, f.match(unknown))
+
+	other := entry
+	other.Message = "request served"
+	assert.False(t, f.match(other))
+
+	var empty entryFilter
+	assert.True(t, empty.match(tooOld))
+}
+
+func TestNewEntryFilterErrors(t *testing.T) {
+	now := time.Now()
+
+	for _, args := range [][4]string{
+		{"10m", "1h", "", ""},
+		{"", "", "loud", ""},
+		{"", "", "", "("},
+		{"soon", "", "", ""},
+	} {
+		_, err := newEntryFilter(args[0], args[1], args[2], args[3], now)
+		assert.Error(t, err, args)
+	}
+}
@@ -32,6 +32,25 @@ the Fly platform.
 
 Logs can be filtered to a specific instance using the --instance/-i flag or
 to all instances running in a specific region using the --region/-r flag.
+
+Apps logging JSON objects may have their logs filtered by the value of their
+fields using the --field flag, e.g. --field level=error --field req.id=abc,
+and projected to a subset of their fields using the --fields flag, e.g.
+--fields ts,level,msg. Filters on level, region and instance also match
+entries which are not JSON objects. Use --raw to print only the messages
+without the instance, region and time they were logged at.
+
+Entries may also be filtered by the time they were logged at via --since and
+--until, which take either durations before now, e.g. 1h, or timestamps, e.g.
+2022-03-01T15:04:05Z; by their minimum level via --level, e.g. --level warn;
+and by a regular expression their message has to match via --grep. Region and
+instance filters are applied by the platform, the rest by flyctl. Logs are
+followed until interrupted unless --until is in the past, in which case the
+retained logs up to it are printed. With --json, entries are printed as JSON
+lines.
+
+To ship the logs of an organization to an external service, such as Datadog
+or S3, see 'fly logs ship'.
 `
 		short = "View app logs"
 	)
@@ -42,6 +61,10 @@ to all instances running in a specific region using the --region/-r flag.
 	)
 
 	cmd.Args = cobra.NoArgs
+	cmd.Example = `flyctl logs -a $APP
+flyctl logs --region ord -a $APP
+flyctl logs --since 30m --level error --grep 'timeout|refused' -a $APP
+flyctl logs --since 2022-03-01T15:00:00Z --until 2022-03-01T16:00:00Z --json -a $APP`
 
 	flag.Add(cmd,
 		flag.App(),
@@ -52,15 +75,59 @@ to all instances running in a specific region using the --region/-r flag.
 			Shorthand:   "i",
 			Description: "Filter by instance ID",
 		},
+		flag.StringArray{
+			Name:        "field",
+			Description: "Only show entries the JSON field of which has the given value, as name=value",
+		},
+		flag.StringSlice{
+			Name:        "fields",
+			Description: "Comma separated list of the JSON fields to show",
+		},
+		flag.Bool{
+			Name:        "raw",
+			Description: "Only show the log messages",
+		},
+		flag.String{
+			Name:        "since",
+			Description: "Only show entries logged since this duration ago (e.g. 1h) or timestamp",
+		},
+		flag.String{
+			Name:        "until",
+			Description: "Only show entries logged until this duration ago (e.g. 5m) or timestamp",
+		},
+		flag.String{
+			Name:        "level",
+			Description: "Only show entries of this level or more severe ones (trace, debug, info, warn, error, fatal)",
+		},
+		flag.String{
+			Name:        "grep",
+			Description: "Only show entries the message of which matches this regular expression",
+		},
+	)
+
+	cmd.AddCommand(
+		newExport(),
+		newShip(),
 	)
 
 	return
 }
 
-func run(ctx context.Context) error {
+func run(ctx context.Context) (err error) {
 	appName := app.NameFromContext(ctx)
 	client := client.FromContext(ctx).API()
 
+	filter, err := newEntryFilter(
+		flag.GetString(ctx, "since"),
+		flag.GetString(ctx, "until"),
+		flag.GetString(ctx, "level"),
+		flag.GetString(ctx, "grep"),
+		time.Now(),
+	)
+	if err != nil {
+		return err
+	}
+
 	app, err := client.GetApp(ctx, appName)
 	if err != nil {
 		return fmt.Errorf("failed retrieving app %s: %w", appName, err)
@@ -72,15 +139,38 @@ func run(ctx context.Context) error {
 		VMID:       flag.GetString(ctx, "instance"),
 	}
 
+	// there's nothing to follow once --until has passed
+	if until := filter.until; !until.IsZero() {
+		if until.Before(time.Now()) {
+			opts.NoTail = true
+		} else {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, until)
+			defer cancel()
+
+			defer func() {
+				if errors.Is(err, context.DeadlineExceeded) {
+					err = nil
+				}
+			}()
+		}
+	}
+
 	var eg *errgroup.Group
 	eg, ctx = errgroup.WithContext(ctx)
 
 	pollingCtx, cancelPolling := context.WithCancel(ctx)
-	pollEntries := poll(pollingCtx, eg, client, opts)
-	liveEntries := nats(ctx, eg, client, opts, cancelPolling)
+	defer cancelPolling()
+
+	streams := []<-chan logs.LogEntry{
+		poll(pollingCtx, eg, client, opts),
+	}
+	if !opts.NoTail {
+		streams = append(streams, nats(ctx, eg, client, opts, cancelPolling))
+	}
 
 	eg.Go(func() error {
-		return printStreams(ctx, pollEntries, liveEntries)
+		return printStreams(ctx, filter, streams...)
 	})
 
 	return eg.Wait()
@@ -135,25 +225,89 @@ func nats(ctx context.Context, eg *errgroup.Group, client *api.Client, opts *log
 	return c
 }
 
-func printStreams(ctx context.Context, streams ...<-chan logs.LogEntry) error {
+func printStreams(ctx context.Context, filter entryFilter, streams ...<-chan logs.LogEntry) error {
+	filters, err := parseFieldFilters(flag.GetStringArray(ctx, "field"))
+	if err != nil {
+		return err
+	}
+
+	p := &printer{
+		filter: filter,
+		view: entryView{
+			filters: filters,
+			fields:  flag.GetStringSlice(ctx, "fields"),
+		},
+		structured: config.FromContext(ctx).StructuredOutput(),
+		raw:        flag.GetBool(ctx, "raw"),
+	}
+
 	var eg *errgroup.Group
 	eg, ctx = errgroup.WithContext(ctx)
 
 	out := iostreams.FromContext(ctx).Out
-	json := config.FromContext(ctx).JSONOutput
 
 	for _, stream := range streams {
 		stream := stream
 
 		eg.Go(func() error {
-			return printStream(ctx, out, stream, json)
+			return printStream(ctx, out, stream, p)
 		})
 	}
 
 	return eg.Wait()
 }
 
-func printStream(ctx context.Context, w io.Writer, stream <-chan logs.LogEntry, json bool) error {
+// printer prints the log entries which pass its filter and view either as
+// structured data, raw messages or the default pretty log lines.
+type printer struct {
+	filter     entryFilter
+	view       entryView
+	structured bool
+	raw        bool
+}
+
+func (p *printer) print(ctx context.Context, w io.Writer, entry logs.LogEntry) (err error) {
+	if !p.filter.match(entry) {
+		return
+	}
+
+	var fields []projectedField
+	if !p.view.empty() {
+		var ok bool
+		if ok, fields = p.view.apply(entry); !ok {
+			return
+		}
+	}
+
+	if fields != nil {
+		if p.structured || p.raw {
+			entry.Message, err = compactJSON(fields)
+		} else {
+			entry.Message = logfmt(fields)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+
+	switch {
+	case p.structured:
+		err = render.Structured(ctx, w, entry)
+	case p.raw:
+		_, err = fmt.Fprintln(w, entry.Message)
+	default:
+		err = render.LogEntry(w, entry,
+			render.HideAllocID(),
+			render.RemoveNewlines(),
+			render.HideRegion(),
+		)
+	}
+
+	return
+}
+
+func printStream(ctx context.Context, w io.Writer, stream <-chan logs.LogEntry, p *printer) error {
 	for {
 		select {
 		case <-ctx.Done():
@@ -163,18 +317,7 @@ func printStream(ctx context.Context, w io.Writer, stream <-chan logs.LogEntry,
 				return nil
 			}
 
-			var err error
-			if json {
-				err = render.JSON(w, entry)
-			} else {
-				err = render.LogEntry(w, entry,
-					render.HideAllocID(),
-					render.RemoveNewlines(),
-					render.HideRegion(),
-				)
-			}
-
-			if err != nil {
+			if err := p.print(ctx, w, entry); err != nil {
 				return err
 			}
 		}
@@ -0,0 +1,465 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/logs"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/prompt"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/logger"
+)
+
+// shipperImage is the image of the app which ships the logs of an
+// organization; see https://github.com/superfly/fly-log-shipper.
+const shipperImage = "ghcr.io/superfly/fly-log-shipper:latest"
+
+// shipDestination wraps a destination logs may be shipped to, along with the
+// settings the shipper requires to ship to it.
+type shipDestination struct {
+	name     string
+	settings []shipSetting
+}
+
+type shipSetting struct {
+	name        string
+	description string
+	optional    bool
+	sensitive   bool
+}
+
+var shipDestinations = []shipDestination{
+	{"datadog", []shipSetting{
+		{name: "DATADOG_API_KEY", description: "Datadog API key", sensitive: true},
+		{name: "DATADOG_SITE", description: "Datadog site, e.g. datadoghq.eu", optional: true},
+	}},
+	{"honeycomb", []shipSetting{
+		{name: "HONEYCOMB_API_KEY", description: "Honeycomb API key", sensitive: true},
+		{name: "HONEYCOMB_DATASET", description: "Honeycomb dataset"},
+	}},
+	{"http", []shipSetting{
+		{name: "HTTP_URL", description: "URL to POST logs to"},
+		{name: "HTTP_TOKEN", description: "Bearer token to authenticate with", optional: true, sensitive: true},
+	}},
+	{"logtail", []shipSetting{
+		{name: "LOGTAIL_TOKEN", description: "Logtail source token", sensitive: true},
+	}},
+	{"loki", []shipSetting{
+		{name: "LOKI_URL", description: "Loki URL"},
+		{name: "LOKI_USERNAME", description: "Loki username"},
+		{name: "LOKI_PASSWORD", description: "Loki password", sensitive: true},
+	}},
+	{"new_relic", []shipSetting{
+		{name: "NEW_RELIC_INSERT_KEY", description: "New Relic insert key", sensitive: true},
+		{name: "NEW_RELIC_REGION", description: "New Relic region, us or eu", optional: true},
+	}},
+	{"papertrail", []shipSetting{
+		{name: "PAPERTRAIL_ENDPOINT", description: "Papertrail endpoint, as host:port"},
+	}},
+	{"s3", []shipSetting{
+		{name: "AWS_ACCESS_KEY_ID", description: "AWS access key ID"},
+		{name: "AWS_SECRET_ACCESS_KEY", description: "AWS secret access key", sensitive: true},
+		{name: "AWS_BUCKET", description: "S3 bucket"},
+		{name: "AWS_REGION", description: "AWS region of the bucket"},
+		{name: "S3_ENDPOINT", description: "Endpoint of S3 compatible storage other than AWS", optional: true},
+	}},
+}
+
+func destinationNames() []string {
+	names := make([]string, 0, len(shipDestinations))
+	for _, d := range shipDestinations {
+		names = append(names, d.name)
+	}
+
+	return names
+}
+
+func destinationByName(name string) (*shipDestination, error) {
+	for i := range shipDestinations {
+		if d := &shipDestinations[i]; d.name == name {
+			return d, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown destination %q; expected one of %s", name, strings.Join(destinationNames(), ", "))
+}
+
+// parseSettings parses the given name=value pairs, which must name settings
+// of d.
+func (d *shipDestination) parseSettings(pairs []string) (map[string]string, error) {
+	known := make(map[string]bool, len(d.settings))
+	for _, s := range d.settings {
+		known[s.name] = true
+	}
+
+	settings := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		i := strings.Index(pair, "=")
+		if i < 1 {
+			return nil, fmt.Errorf("invalid setting %q; settings must be of the form NAME=VALUE", pair)
+		}
+
+		name := pair[:i]
+		if !known[name] {
+			return nil, fmt.Errorf("%s is not a setting of %s; its settings are %s", name, d.name, strings.Join(d.settingNames(), ", "))
+		}
+
+		settings[name] = pair[i+1:]
+	}
+
+	return settings, nil
+}
+
+func (d *shipDestination) settingNames() []string {
+	names := make([]string, 0, len(d.settings))
+	for _, s := range d.settings {
+		names = append(names, s.name)
+	}
+
+	return names
+}
+
+func newShip() (cmd *cobra.Command) {
+	const (
+		intro = `Ship the logs of the apps of an organization to an external service.
+
+A log shipper app, which runs https://github.com/superfly/fly-log-shipper, is
+created in the organization and reads the logs of its apps off of the
+organization's NATS log stream. The settings destinations require are passed
+via --set NAME=VALUE and prompted for when missing; they're stored as secrets
+of the shipper app. Running the command against an existing shipper app adds
+the destination to the ones it ships to and replaces its machine.
+
+Once launched, the shipper's logs are watched until its destinations pass
+their health checks, unless --skip-verify is set.
+
+Destinations and their settings:
+`
+		short = "Ship the logs of an organization to an external service"
+	)
+
+	cmd = command.New("ship", short, intro+destinationsHelp, runShip,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.NoArgs
+	cmd.Example = `flyctl logs ship --to datadog --org acme --set DATADOG_API_KEY=$KEY
+flyctl logs ship --to s3 --org acme --subject 'logs.web.>'`
+
+	flag.Add(cmd,
+		flag.Org(),
+		flag.Region(),
+		flag.String{
+			Name:        "to",
+			Description: "Destination to ship logs to: " + strings.Join(destinationNames(), ", "),
+		},
+		flag.StringSlice{
+			Name:        "set",
+			Description: "Setting of the destination, as NAME=VALUE. Can be specified multiple times",
+		},
+		flag.String{
+			Name:        "name",
+			Description: "Name of the log shipper app. Defaults to <org>-log-shipper",
+		},
+		flag.String{
+			Name:        "subject",
+			Description: "NATS subject of the logs to ship, e.g. logs.<app>.> to only ship the logs of an app",
+		},
+		flag.String{
+			Name:        "image",
+			Description: "Image of the log shipper",
+			Default:     shipperImage,
+		},
+		flag.Bool{
+			Name:        "skip-verify",
+			Description: "Do not wait for the destination to pass its health check",
+		},
+		flag.Duration{
+			Name:        "verify-timeout",
+			Description: "How long to wait for the destination to pass its health check",
+			Default:     2 * time.Minute,
+		},
+	)
+
+	return
+}
+
+var destinationsHelp = func() string {
+	var b strings.Builder
+
+	for _, d := range shipDestinations {
+		fmt.Fprintf(&b, "\n  %s\n", d.name)
+
+		for _, s := range d.settings {
+			desc := s.description
+			if s.optional {
+				desc += " (optional)"
+			}
+
+			fmt.Fprintf(&b, "    %-22s %s\n", s.name, desc)
+		}
+	}
+
+	return b.String()
+}()
+
+func runShip(ctx context.Context) (err error) {
+	to := flag.GetString(ctx, "to")
+	if to == "" {
+		return fmt.Errorf("a destination must be specified via --to; one of %s", strings.Join(destinationNames(), ", "))
+	}
+
+	dest, err := destinationByName(to)
+	if err != nil {
+		return err
+	}
+
+	settings, err := dest.parseSettings(flag.GetStringSlice(ctx, "set"))
+	if err != nil {
+		return err
+	}
+
+	if err = promptSettings(ctx, dest, settings); err != nil {
+		return err
+	}
+
+	org, err := prompt.Org(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	appName := flag.GetString(ctx, "name")
+	if appName == "" {
+		appName = org.Slug + "-log-shipper"
+	}
+
+	secrets := map[string]string{
+		"ORG":          org.Slug,
+		"ACCESS_TOKEN": config.FromContext(ctx).AccessToken,
+	}
+	for name, value := range settings {
+		secrets[name] = value
+	}
+	if subject := flag.GetString(ctx, "subject"); subject != "" {
+		secrets["SUBJECT"] = subject
+	}
+
+	client := client.FromContext(ctx).API()
+	tb := render.NewTextBlock(ctx, fmt.Sprintf("Shipping the logs of %s to %s", org.Slug, dest.name))
+
+	app, err := client.GetAppCompact(ctx, appName)
+	switch {
+	case err == nil:
+		if app.Organization.Slug != org.Slug {
+			return fmt.Errorf("app %s belongs to %s rather than %s; pass another --name", appName, app.Organization.Slug, org.Slug)
+		}
+
+		tb.Detailf("Using the existing log shipper app %s", appName)
+	case api.IsNotFoundError(err):
+		var region *api.Region
+		if region, err = prompt.Region(ctx); err != nil {
+			return err
+		}
+
+		input := api.CreateAppInput{
+			OrganizationID:  org.ID,
+			Name:            appName,
+			PreferredRegion: &region.Code,
+			Runtime:         "FIRECRACKER",
+		}
+
+		if _, err = client.CreateApp(ctx, input); err != nil {
+			return fmt.Errorf("failed creating log shipper app %s: %w", appName, err)
+		}
+
+		tb.Detailf("Created the log shipper app %s in %s", appName, region.Code)
+	default:
+		return fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	if _, err = client.SetSecrets(ctx, appName, secrets); err != nil {
+		return fmt.Errorf("failed setting the secrets of %s: %w", appName, err)
+	}
+	tb.Detailf("Set secrets %s", strings.Join(sortedKeys(secrets), ", "))
+
+	machine, err := launchShipper(ctx, client, org, appName)
+	if err != nil {
+		return err
+	}
+	tb.Detailf("Launched log shipper machine %s", machine.ID)
+
+	if flag.GetBool(ctx, "skip-verify") {
+		tb.Donef("Log shipper launched; check its logs with 'flyctl logs -a %s'", appName)
+
+		return nil
+	}
+
+	tb.Detail("Waiting for the destination to pass its health check")
+
+	switch err = verifyShipper(ctx, client, appName, flag.GetDuration(ctx, "verify-timeout")); {
+	case err == nil:
+		tb.Donef("Logs are shipping to %s", dest.name)
+	case errors.Is(err, errShipperUnverified):
+		tb.Donef("Log shipper launched, but delivery could not be verified in time; check its logs with 'flyctl logs -a %s'", appName)
+		err = nil
+	}
+
+	return
+}
+
+func promptSettings(ctx context.Context, dest *shipDestination, settings map[string]string) error {
+	for _, s := range dest.settings {
+		if _, ok := settings[s.name]; ok || s.optional {
+			continue
+		}
+
+		var (
+			value string
+			err   error
+			msg   = fmt.Sprintf("%s (%s):", s.description, s.name)
+		)
+
+		if s.sensitive {
+			err = prompt.Password(ctx, &value, msg, true)
+		} else {
+			err = prompt.String(ctx, &value, msg, "", true)
+		}
+
+		switch {
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError(fmt.Sprintf("%s must be set via --set when not running interactively", s.name))
+		case err != nil:
+			return err
+		}
+
+		settings[s.name] = value
+	}
+
+	return nil
+}
+
+// launchShipper replaces the machines of the log shipper app with a single
+// new one, which picks up the secrets of the app.
+func launchShipper(ctx context.Context, client *api.Client, org *api.Organization, appName string) (*api.Machine, error) {
+	existing, err := client.ListMachines(ctx, appName, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed listing the machines of %s: %w", appName, err)
+	}
+
+	for _, m := range existing {
+		if m.State == "destroyed" || m.State == "destroying" {
+			continue
+		}
+
+		input := api.RemoveMachineInput{
+			AppID: appName,
+			ID:    m.ID,
+			Kill:  true,
+		}
+
+		if _, err := client.RemoveMachine(ctx, input); err != nil {
+			return nil, fmt.Errorf("failed replacing log shipper machine %s: %w", m.ID, err)
+		}
+	}
+
+	input := api.LaunchMachineInput{
+		AppID:   appName,
+		OrgSlug: org.ID,
+		Region:  config.FromContext(ctx).Region,
+		Config: &api.MachineConfig{
+			Image: flag.GetString(ctx, "image"),
+		},
+	}
+
+	machine, _, err := client.LaunchMachine(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed launching log shipper machine: %w", err)
+	}
+
+	return machine, nil
+}
+
+var errShipperUnverified = errors.New("log shipper unverified")
+
+// verifyShipper watches the logs of the log shipper app until its sinks pass
+// or fail their health checks.
+func verifyShipper(ctx context.Context, client *api.Client, appName string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	opts := &logs.LogOptions{
+		AppName: appName,
+	}
+
+	stream, err := logs.NewNatsStream(ctx, client, opts)
+	if err != nil {
+		logger.FromContext(ctx).Debugf("could not connect to wireguard tunnel, falling back to log polling: %v", err)
+
+		if stream, err = logs.NewPollingStream(ctx, client, opts); err != nil {
+			return err
+		}
+	}
+
+	for entry := range stream.Stream(ctx, opts) {
+		switch shipperHealth(entry.Message) {
+		case shipperHealthy:
+			return nil
+		case shipperUnhealthy:
+			return fmt.Errorf("the destination failed its health check: %s", strings.TrimSpace(entry.Message))
+		}
+	}
+
+	if err := stream.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed streaming the logs of %s: %w", appName, err)
+	}
+
+	return errShipperUnverified
+}
+
+type shipperHealthState int
+
+const (
+	shipperHealthUnknown shipperHealthState = iota
+	shipperHealthy
+	shipperUnhealthy
+)
+
+// shipperHealth returns the health state of its destination the given log
+// line of the log shipper tells of, if any. The shipper logs the outcome of
+// the health check of each of its sinks once they start.
+func shipperHealth(line string) shipperHealthState {
+	line = strings.ToLower(line)
+
+	switch {
+	case !strings.Contains(line, "healthcheck"):
+		return shipperHealthUnknown
+	case strings.Contains(line, "healthcheck passed"), strings.Contains(line, "healthcheck: passed"):
+		return shipperHealthy
+	case strings.Contains(line, "healthcheck failed"), strings.Contains(line, "healthcheck: failed"):
+		return shipperUnhealthy
+	default:
+		return shipperHealthUnknown
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
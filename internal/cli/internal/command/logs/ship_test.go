@@ -0,0 +1,45 @@
+package logs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShipSettings(t *testing.T) {
+	dest, err := destinationByName("s3")
+	require.NoError(t, err)
+
+	settings, err := dest.parseSettings([]string{"AWS_BUCKET=logs", "AWS_SECRET_ACCESS_KEY=a=b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"AWS_BUCKET":            "logs",
+		"AWS_SECRET_ACCESS_KEY": "a=b",
+	}, settings)
+
+	_, err = dest.parseSettings([]string{"DATADOG_API_KEY=abc"})
+	assert.Error(t, err)
+
+	_, err = dest.parseSettings([]string{"AWS_BUCKET"})
+	assert.Error(t, err)
+
+	_, err = destinationByName("syslog")
+	assert.Error(t, err)
+}
+
+func TestShipperHealth(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected shipperHealthState
+	}{
+		{"2022-03-01T12:00:00Z  INFO vector::topology::builder: Healthcheck passed.", shipperHealthy},
+		{"2022-03-01T12:00:00Z ERROR vector::topology::builder: Healthcheck failed. error=Invalid API key.", shipperUnhealthy},
+		{"2022-03-01T12:00:00Z  INFO vector::topology::builder: Healthcheck disabled.", shipperHealthUnknown},
+		{"2022-03-01T12:00:00Z  INFO vector: Vector has started.", shipperHealthUnknown},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, shipperHealth(test.line), test.line)
+	}
+}
@@ -0,0 +1,228 @@
+// Package maintenance implements the maintenance command chain.
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/format"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+// DefaultMessage denotes the message the maintenance response carries unless
+// told otherwise.
+const DefaultMessage = "This app is undergoing maintenance. Please check back soon."
+
+// New initializes and returns a new maintenance Command.
+func New() *cobra.Command {
+	const (
+		long = `Switch the edge routing of an app to and from a maintenance response.
+
+While maintenance mode is on, requests to the app are answered by the edge with
+a static maintenance response, or by a designated maintenance app, instead of
+reaching the instances of the app. The app itself is neither redeployed nor
+stopped.
+`
+		short = "Toggle the maintenance mode of an app"
+	)
+
+	cmd := command.New("maintenance", short, long, nil)
+
+	cmd.AddCommand(
+		newOn(),
+		newOff(),
+		newStatus(),
+	)
+
+	return cmd
+}
+
+func newOn() *cobra.Command {
+	const (
+		long = `Serve a maintenance response in place of the app.
+
+The response carries --message with the --status-code status, unless
+--target-app designates an app to route requests to instead.
+`
+		short = "Turn maintenance mode on"
+	)
+
+	cmd := command.New("on", short, long, runOn,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.String{
+			Name:        "message",
+			Shorthand:   "m",
+			Description: "Message of the maintenance response",
+			Default:     DefaultMessage,
+		},
+		flag.Int{
+			Name:        "status-code",
+			Description: "HTTP status code of the maintenance response",
+			Default:     http.StatusServiceUnavailable,
+		},
+		flag.String{
+			Name:        "target-app",
+			Description: "App to route requests to instead of serving the maintenance response",
+		},
+	)
+
+	return cmd
+}
+
+func newOff() *cobra.Command {
+	const (
+		long  = "Route requests to the app again."
+		short = "Turn maintenance mode off"
+	)
+
+	cmd := command.New("off", short, long, runOff,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func newStatus() *cobra.Command {
+	const (
+		long  = "Show whether maintenance mode is on, and with what response."
+		short = "Show the maintenance mode of an app"
+	)
+
+	cmd := command.New("status", short, long, runStatus,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func runOn(ctx context.Context) error {
+	appName := app.NameFromContext(ctx)
+	client := client.FromContext(ctx).API()
+
+	input := api.SetAppMaintenanceInput{
+		AppID:   appName,
+		Enabled: true,
+	}
+
+	if target := flag.GetString(ctx, "target-app"); target != "" {
+		if target == appName {
+			return errors.New("an app can't be its own maintenance app")
+		}
+
+		targetApp, err := client.GetApp(ctx, target)
+		if err != nil {
+			return fmt.Errorf("failed retrieving maintenance app %s: %w", target, err)
+		}
+
+		if !targetApp.Deployed {
+			return fmt.Errorf("maintenance app %s has not been deployed yet", target)
+		}
+
+		input.TargetAppID = api.StringPointer(targetApp.ID)
+	} else {
+		code := flag.GetInt(ctx, "status-code")
+		if code < 200 || code > 599 {
+			return fmt.Errorf("invalid status code %d; it must be between 200 and 599", code)
+		}
+
+		input.Message = api.StringPointer(flag.GetString(ctx, "message"))
+		input.StatusCode = api.IntPointer(code)
+	}
+
+	mode, err := client.SetAppMaintenance(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed turning maintenance mode of %s on: %w", appName, err)
+	}
+
+	return renderMode(ctx, appName, mode)
+}
+
+func runOff(ctx context.Context) error {
+	appName := app.NameFromContext(ctx)
+	client := client.FromContext(ctx).API()
+
+	mode, err := client.SetAppMaintenance(ctx, api.SetAppMaintenanceInput{
+		AppID: appName,
+	})
+	if err != nil {
+		return fmt.Errorf("failed turning maintenance mode of %s off: %w", appName, err)
+	}
+
+	return renderMode(ctx, appName, mode)
+}
+
+func runStatus(ctx context.Context) error {
+	appName := app.NameFromContext(ctx)
+	client := client.FromContext(ctx).API()
+
+	mode, err := client.GetAppMaintenance(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving maintenance mode of %s: %w", appName, err)
+	}
+
+	return renderMode(ctx, appName, mode)
+}
+
+func renderMode(ctx context.Context, appName string, mode *api.MaintenanceMode) error {
+	out := iostreams.FromContext(ctx).Out
+
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, out, mode)
+	}
+
+	if !mode.Enabled {
+		fmt.Fprintf(out, "Maintenance mode of %s is off; requests reach the app\n", appName)
+
+		return nil
+	}
+
+	since := "-"
+	if mode.UpdatedAt != nil {
+		since = format.RelativeTime(*mode.UpdatedAt)
+	}
+
+	var rows [][]string
+	if mode.TargetApp != nil {
+		rows = append(rows, []string{"on", "app " + mode.TargetApp.Name, since})
+	} else {
+		rows = append(rows, []string{"on", fmt.Sprintf("%d %q", mode.StatusCode, mode.Message), since})
+	}
+
+	return render.Table(out, fmt.Sprintf("Maintenance mode of %s", appName), rows, "Status", "Response", "Since")
+}
@@ -0,0 +1,179 @@
+// Package metrics implements the metrics command chain.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/internal/metrics"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+// sparkWidth is the width of the sparklines of the metrics.
+const sparkWidth = 30
+
+func New() (cmd *cobra.Command) {
+	const (
+		long = `Show the CPU, memory and HTTP response metrics of the application over the
+last --since, as queried from the Prometheus API of its organization.
+
+Each series is summarized by its current, minimum, maximum and average value;
+--sparklines adds a sparkline of its values. Custom PromQL queries may be
+given via --query instead.
+`
+		short = "Show app metrics"
+	)
+
+	cmd = command.New("metrics", short, long, run,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+	cmd.Example = `flyctl metrics -a $APP
+flyctl metrics --since 6h --sparklines -a $APP
+flyctl metrics --query 'sum(fly_app_concurrency{app="$APP"})' -a $APP`
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.StringSlice{
+			Name:        "query",
+			Description: "PromQL query to run instead of the default ones. Can be specified multiple times.",
+		},
+		flag.Duration{
+			Name:        "since",
+			Description: "How far back to query metrics from",
+			Default:     time.Hour,
+		},
+		flag.Duration{
+			Name:        "step",
+			Description: "Resolution of the queried metrics; defaults to a sixtieth of --since",
+		},
+		flag.Bool{
+			Name:        "sparklines",
+			Description: "Show a sparkline of each series",
+		},
+	)
+
+	return
+}
+
+// PanelResult wraps the series a panel queried.
+type PanelResult struct {
+	Title  string           `json:"title"`
+	Query  string           `json:"query"`
+	Unit   metrics.Unit     `json:"-"`
+	Series []metrics.Series `json:"series"`
+}
+
+func run(ctx context.Context) error {
+	since := flag.GetDuration(ctx, "since")
+	if since <= 0 {
+		return errors.New("--since must be positive")
+	}
+
+	step := flag.GetDuration(ctx, "step")
+	if step < 0 {
+		return errors.New("--step must not be negative")
+	}
+	if step == 0 {
+		step = DefaultStep(since)
+	}
+
+	appName := app.NameFromContext(ctx)
+
+	c, err := NewClient(ctx, appName)
+	if err != nil {
+		return err
+	}
+
+	panels := metrics.AppPanels(appName)
+	if queries := flag.GetStringSlice(ctx, "query"); len(queries) > 0 {
+		panels = panels[:0]
+		for _, q := range queries {
+			panels = append(panels, metrics.Panel{Title: q, Query: q})
+		}
+	}
+
+	end := time.Now()
+	start := end.Add(-since)
+
+	results := make([]PanelResult, 0, len(panels))
+	for _, p := range panels {
+		series, err := c.QueryRange(ctx, p.Query, start, end, step)
+		if err != nil {
+			return fmt.Errorf("failed querying %s: %w", p.Title, err)
+		}
+
+		results = append(results, PanelResult{
+			Title:  p.Title,
+			Query:  p.Query,
+			Unit:   p.Unit,
+			Series: series,
+		})
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, results)
+	}
+
+	width := 0
+	if flag.GetBool(ctx, "sparklines") {
+		width = sparkWidth
+	}
+
+	for i, p := range panels {
+		if len(results[i].Series) == 0 {
+			fmt.Fprintf(out, "%s\nNo data\n\n", p.Title)
+
+			continue
+		}
+
+		if err := render.MetricsPanel(out, p, results[i].Series, width); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DefaultStep returns the resolution metrics over the given range are queried
+// at by default.
+func DefaultStep(since time.Duration) time.Duration {
+	step := (since / 60).Round(time.Second)
+	if step < 15*time.Second {
+		step = 15 * time.Second
+	}
+
+	return step
+}
+
+// NewClient returns a client of the metrics of the organization of the named
+// app.
+func NewClient(ctx context.Context, appName string) (*metrics.Client, error) {
+	compact, err := client.FromContext(ctx).API().GetAppCompact(ctx, appName)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	cfg := config.FromContext(ctx)
+
+	return &metrics.Client{
+		BaseURL: cfg.APIBaseURL,
+		Token:   cfg.AccessToken,
+		Org:     compact.Organization.Slug,
+	}, nil
+}
@@ -0,0 +1,303 @@
+package orgs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/cmdutil"
+)
+
+func newBilling() *cobra.Command {
+	const (
+		long = `Commands that manage the billing of an organization.
+`
+		short = "Manage the billing of an organization"
+	)
+
+	cmd := command.New("billing", short, long, nil)
+
+	cmd.AddCommand(
+		newBillingAlerts(),
+	)
+
+	return cmd
+}
+
+func newBillingAlerts() *cobra.Command {
+	const (
+		long = `List the spend alerts of an organization along with what it has spent this
+month so far.
+
+Spend alerts are monthly spend thresholds. Once the spend of the organization
+crosses one in a billing period, the health check handlers the alert names are
+notified, so that surprise bills are caught early; see 'fly checks handlers'.
+'fly costs' shows the current spend against the thresholds.
+`
+		short = "Manage the spend alerts of an organization"
+		usage = "alerts [slug]"
+	)
+
+	cmd := command.New(usage, short, long, runBillingAlerts,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.MaximumNArgs(1)
+
+	cmd.AddCommand(
+		newBillingAlertsAdd(),
+		newBillingAlertsRemove(),
+	)
+
+	return cmd
+}
+
+func runBillingAlerts(ctx context.Context) error {
+	org, err := detailsFromFirstArgOrSelect(ctx)
+	if err != nil {
+		return err
+	}
+
+	spend, alerts, err := client.FromContext(ctx).API().GetOrganizationSpend(ctx, org.Slug)
+	if err != nil {
+		return fmt.Errorf("failed retrieving the spend alerts of %s: %w", org.Slug, err)
+	}
+
+	sortSpendAlerts(alerts)
+
+	io := iostreams.FromContext(ctx)
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, io.Out, alerts)
+	}
+
+	if len(alerts) == 0 {
+		fmt.Fprintf(io.Out, "Organization %s has no spend alerts\n", org.Slug)
+
+		return nil
+	}
+
+	rows := make([][]string, 0, len(alerts))
+	for _, alert := range alerts {
+		status := "armed"
+		if alert.TriggeredAt != nil {
+			status = "triggered " + alert.TriggeredAt.Format("2006-01-02")
+		}
+
+		rows = append(rows, []string{
+			cmdutil.FormatCents(alert.ThresholdCents),
+			strings.Join(alert.HandlerNames, ", "),
+			status,
+		})
+	}
+
+	var title string
+	if spend != nil {
+		title = fmt.Sprintf("Spend alerts of %s (spent %s this month so far)", org.Slug, cmdutil.FormatCents(spend.AmountCents))
+	}
+
+	return render.Table(io.Out, title, rows, "Threshold", "Channels", "Status")
+}
+
+func newBillingAlertsAdd() *cobra.Command {
+	const (
+		long = `Add a spend alert to an organization, which notifies the named health check
+handlers once the organization spends more than --threshold in a month. Absent
+--channel, all the handlers of the organization are notified.
+`
+		short = "Add a spend alert to an organization"
+		usage = "add [slug]"
+	)
+
+	cmd := command.New(usage, short, long, runBillingAlertsAdd,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.MaximumNArgs(1)
+
+	flag.Add(cmd,
+		flag.String{
+			Name:        "threshold",
+			Description: "Monthly spend, in US dollars, which triggers the alert, e.g. 250",
+		},
+		flag.StringSlice{
+			Name:        "channel",
+			Description: "Name of a health check handler to notify. Can be specified multiple times.",
+		},
+	)
+
+	cmd.Example = `fly orgs billing alerts add acme --threshold 250 --channel ops-slack`
+
+	return cmd
+}
+
+func runBillingAlertsAdd(ctx context.Context) error {
+	threshold, err := thresholdFromFlag(ctx)
+	if err != nil {
+		return err
+	}
+
+	org, err := detailsFromFirstArgOrSelect(ctx)
+	if err != nil {
+		return err
+	}
+
+	client := client.FromContext(ctx).API()
+
+	handlers, err := client.GetHealthCheckHandlers(ctx, org.Slug)
+	if err != nil {
+		return fmt.Errorf("failed retrieving the health check handlers of %s: %w", org.Slug, err)
+	}
+
+	channels, err := alertChannels(org.Slug, handlers, flag.GetStringSlice(ctx, "channel"))
+	if err != nil {
+		return err
+	}
+
+	alert, err := client.CreateSpendAlert(ctx, api.CreateSpendAlertInput{
+		OrganizationID: org.ID,
+		ThresholdCents: threshold,
+		HandlerNames:   channels,
+	})
+	if err != nil {
+		return fmt.Errorf("failed adding the spend alert: %w", err)
+	}
+
+	io := iostreams.FromContext(ctx)
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, io.Out, alert)
+	}
+
+	fmt.Fprintf(io.Out, "Added a spend alert to %s: once it spends more than %s in a month, %s get notified\n",
+		org.Slug, cmdutil.FormatCents(alert.ThresholdCents), strings.Join(alert.HandlerNames, ", "))
+
+	return nil
+}
+
+func newBillingAlertsRemove() *cobra.Command {
+	const (
+		long = `Remove the spend alert of the given threshold from an organization.
+`
+		short = "Remove a spend alert from an organization"
+		usage = "remove [slug]"
+	)
+
+	cmd := command.New(usage, short, long, runBillingAlertsRemove,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.Aliases = []string{"rm"}
+
+	flag.Add(cmd,
+		flag.String{
+			Name:        "threshold",
+			Description: "Monthly spend, in US dollars, of the alert to remove",
+		},
+	)
+
+	return cmd
+}
+
+func runBillingAlertsRemove(ctx context.Context) error {
+	threshold, err := thresholdFromFlag(ctx)
+	if err != nil {
+		return err
+	}
+
+	org, err := detailsFromFirstArgOrSelect(ctx)
+	if err != nil {
+		return err
+	}
+
+	client := client.FromContext(ctx).API()
+
+	_, alerts, err := client.GetOrganizationSpend(ctx, org.Slug)
+	if err != nil {
+		return fmt.Errorf("failed retrieving the spend alerts of %s: %w", org.Slug, err)
+	}
+
+	var removed int
+	for _, alert := range alerts {
+		if alert.ThresholdCents != threshold {
+			continue
+		}
+
+		input := api.DeleteSpendAlertInput{
+			OrganizationID: org.ID,
+			SpendAlertID:   alert.ID,
+		}
+
+		if err := client.DeleteSpendAlert(ctx, input); err != nil {
+			return fmt.Errorf("failed removing the spend alert: %w", err)
+		}
+		removed++
+	}
+
+	if removed == 0 {
+		return fmt.Errorf("organization %s has no spend alert of %s", org.Slug, cmdutil.FormatCents(threshold))
+	}
+
+	io := iostreams.FromContext(ctx)
+	fmt.Fprintf(io.Out, "Removed the spend alert of %s from %s\n", cmdutil.FormatCents(threshold), org.Slug)
+
+	return nil
+}
+
+func thresholdFromFlag(ctx context.Context) (int, error) {
+	s := flag.GetString(ctx, "threshold")
+	if s == "" {
+		return 0, errors.New("--threshold must be specified")
+	}
+
+	cents, err := cmdutil.ParseDollars(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --threshold %q; %w", s, err)
+	}
+
+	return cents, nil
+}
+
+// alertChannels returns the names of the given handlers of the named
+// organization which alerts notify: those named or, absent names, all of them.
+func alertChannels(slug string, handlers []api.HealthCheckHandler, names []string) ([]string, error) {
+	if len(handlers) == 0 {
+		return nil, fmt.Errorf("organization %s has no health check handlers to notify; add one with 'fly checks handlers create'", slug)
+	}
+
+	exists := make(map[string]bool, len(handlers))
+	for _, h := range handlers {
+		exists[h.Name] = true
+	}
+
+	if len(names) == 0 {
+		for _, h := range handlers {
+			names = append(names, h.Name)
+		}
+	}
+
+	for _, name := range names {
+		if !exists[name] {
+			return nil, fmt.Errorf("organization %s has no health check handler named %s", slug, name)
+		}
+	}
+
+	return names, nil
+}
+
+func sortSpendAlerts(alerts []api.SpendAlert) {
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].ThresholdCents < alerts[j].ThresholdCents
+	})
+}
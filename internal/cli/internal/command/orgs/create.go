@@ -46,8 +46,8 @@ func runCreate(ctx context.Context) error {
 		return fmt.Errorf("failed creating organization: %w", err)
 	}
 
-	if io := iostreams.FromContext(ctx); config.FromContext(ctx).JSONOutput {
-		_ = render.JSON(io.Out, org)
+	if io := iostreams.FromContext(ctx); config.FromContext(ctx).StructuredOutput() {
+		_ = render.Structured(ctx, io.Out, org)
 	} else {
 		printOrg(io.Out, org, true)
 	}
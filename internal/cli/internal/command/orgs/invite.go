@@ -59,8 +59,8 @@ func runInvite(ctx context.Context) error {
 	cfg := config.FromContext(ctx)
 	io := iostreams.FromContext(ctx)
 
-	if cfg.JSONOutput {
-		_ = render.JSON(io.Out, inv)
+	if cfg.StructuredOutput() {
+		_ = render.Structured(ctx, io.Out, inv)
 
 		return nil
 	}
@@ -36,7 +36,7 @@ func runList(ctx context.Context) error {
 
 	out := iostreams.FromContext(ctx).Out
 
-	if config.FromContext(ctx).JSONOutput {
+	if config.FromContext(ctx).StructuredOutput() {
 		orgs := map[string]string{
 			personal.Slug: personal.Name,
 		}
@@ -45,7 +45,7 @@ func runList(ctx context.Context) error {
 			orgs[other.Slug] = other.Name
 		}
 
-		_ = render.JSON(out, orgs)
+		_ = render.Structured(ctx, out, orgs)
 
 		return nil
 	}
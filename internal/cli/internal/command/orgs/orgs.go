@@ -38,6 +38,8 @@ Organization admins can also invite or remove users from Organizations.
 		newRemove(),
 		newCreate(),
 		newDelete(),
+		newReleasePolicy(),
+		newBilling(),
 	)
 
 	return orgs
@@ -0,0 +1,128 @@
+package orgs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/cmdutil"
+)
+
+func newReleasePolicy() *cobra.Command {
+	const (
+		long = `Show or set the default release retention policy of an organization, which
+'fly releases prune' applies to its apps when run without --keep or --max-age.
+`
+		short = "Manage the release retention policy of an organization"
+		usage = "release-policy [slug]"
+	)
+
+	cmd := command.New(usage, short, long, runReleasePolicy,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.MaximumNArgs(1)
+
+	flag.Add(cmd,
+		flag.Int{
+			Name:        "keep",
+			Description: "Number of the newest releases to keep",
+		},
+		flag.String{
+			Name:        "max-age",
+			Description: "Only prune releases older than this, e.g. 90d or 720h",
+		},
+		flag.Bool{
+			Name:        "clear",
+			Description: "Remove the policy",
+		},
+	)
+
+	return cmd
+}
+
+func runReleasePolicy(ctx context.Context) error {
+	org, err := detailsFromFirstArgOrSelect(ctx)
+	if err != nil {
+		return err
+	}
+
+	client := client.FromContext(ctx).API()
+
+	input, err := releasePolicyInput(ctx, org.ID)
+	if err != nil {
+		return err
+	}
+
+	var policy *api.ReleaseRetentionPolicy
+	if input != nil {
+		if policy, err = client.UpdateOrganizationReleaseRetentionPolicy(ctx, *input); err != nil {
+			return fmt.Errorf("failed updating the release retention policy of %s: %w", org.Slug, err)
+		}
+	} else if policy, err = client.GetOrganizationReleaseRetentionPolicy(ctx, org.Slug); err != nil {
+		return fmt.Errorf("failed retrieving the release retention policy of %s: %w", org.Slug, err)
+	}
+
+	if policy == nil {
+		policy = &api.ReleaseRetentionPolicy{}
+	}
+
+	io := iostreams.FromContext(ctx)
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, io.Out, policy)
+	}
+
+	switch {
+	case policy.Keep == 0 && policy.MaxAgeDays == 0:
+		fmt.Fprintf(io.Out, "Organization %s has no release retention policy\n", org.Slug)
+	case policy.MaxAgeDays == 0:
+		fmt.Fprintf(io.Out, "Apps of %s keep their newest %d releases\n", org.Slug, policy.Keep)
+	case policy.Keep == 0:
+		fmt.Fprintf(io.Out, "Apps of %s keep their releases for %d days\n", org.Slug, policy.MaxAgeDays)
+	default:
+		fmt.Fprintf(io.Out, "Apps of %s keep their newest %d releases and any newer than %d days\n", org.Slug, policy.Keep, policy.MaxAgeDays)
+	}
+
+	return nil
+}
+
+// releasePolicyInput returns the update the flags make to the policy of the
+// organization with the given ID, or nil in case they make none.
+func releasePolicyInput(ctx context.Context, orgID string) (*api.UpdateOrganizationReleaseRetentionPolicyInput, error) {
+	input := &api.UpdateOrganizationReleaseRetentionPolicyInput{
+		OrganizationID: orgID,
+		Keep:           flag.GetInt(ctx, "keep"),
+	}
+
+	if input.Keep < 0 {
+		return nil, errors.New("--keep may not be negative")
+	}
+
+	if s := flag.GetString(ctx, "max-age"); s != "" {
+		days, err := cmdutil.ParseDays(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --max-age %q; %w", s, err)
+		}
+		input.MaxAgeDays = days
+	}
+
+	set := input.Keep > 0 || input.MaxAgeDays > 0
+	switch clear := flag.GetBool(ctx, "clear"); {
+	case clear && set:
+		return nil, errors.New("--clear is mutually exclusive with --keep and --max-age")
+	case clear, set:
+		return input, nil
+	default:
+		return nil, nil
+	}
+}
@@ -40,8 +40,8 @@ func runShow(ctx context.Context) error {
 	}
 
 	io := iostreams.FromContext(ctx)
-	if config.FromContext(ctx).JSONOutput {
-		_ = render.JSON(io.Out, org)
+	if config.FromContext(ctx).StructuredOutput() {
+		_ = render.Structured(ctx, io.Out, org)
 
 		return nil
 	}
@@ -7,10 +7,13 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/pkg/iostreams"
 
+	"github.com/superfly/flyctl/internal/cli/internal/apicache"
 	"github.com/superfly/flyctl/internal/cli/internal/command"
 	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
 	"github.com/superfly/flyctl/internal/cli/internal/render"
 	"github.com/superfly/flyctl/internal/client"
 )
@@ -18,23 +21,37 @@ import (
 func newRegions() (cmd *cobra.Command) {
 	const (
 		long = `View a list of regions where Fly has edges and/or datacenters
+
+The list is cached for offline use, for FLY_CACHE_TTL or the cache_ttl setting
+of the configuration file (24h by default); pass --no-cache to fetch it anew.
 `
 		short = "List regions"
 	)
 
 	cmd = command.New("regions", short, long, runRegions,
 		command.RequireSession,
+		command.LoadAPICache,
 	)
 
 	cmd.Args = cobra.NoArgs
 
+	flag.Add(cmd,
+		flag.NoCache(),
+	)
+
 	return
 }
 
 func runRegions(ctx context.Context) error {
 	client := client.FromContext(ctx).API()
 
-	regions, _, err := client.PlatformRegions(ctx)
+	// regions seldom change, so they're served from the cache while it has them
+	var regions []api.Region
+	err := apicache.FromContext(ctx).FetchCached(ctx, "platform/regions", &regions, func(ctx context.Context) (err error) {
+		regions, _, err = client.PlatformRegions(ctx)
+
+		return
+	})
 	if err != nil {
 		return fmt.Errorf("failed retrieving regions: %w", err)
 	}
@@ -43,8 +60,8 @@ func runRegions(ctx context.Context) error {
 	})
 
 	out := iostreams.FromContext(ctx).Out
-	if config.FromContext(ctx).JSONOutput {
-		return render.JSON(out, regions)
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, out, regions)
 	}
 
 	var rows [][]string
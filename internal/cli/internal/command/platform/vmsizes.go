@@ -7,8 +7,10 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/cli/internal/apicache"
 	"github.com/superfly/flyctl/internal/cli/internal/command"
 	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
 	"github.com/superfly/flyctl/internal/cli/internal/render"
 	"github.com/superfly/flyctl/internal/client"
 	"github.com/superfly/flyctl/pkg/iostreams"
@@ -17,30 +19,44 @@ import (
 func newVMSizes() (cmd *cobra.Command) {
 	const (
 		long = `View a list of VM sizes which can be used with the FLYCTL SCALE VM command
+
+The list is cached for offline use, for FLY_CACHE_TTL or the cache_ttl setting
+of the configuration file (24h by default); pass --no-cache to fetch it anew.
 `
 		short = "List VM Sizes"
 	)
 
 	cmd = command.New("vm-sizes", short, long, runVMSizes,
 		command.RequireSession,
+		command.LoadAPICache,
 	)
 
 	cmd.Args = cobra.NoArgs
 
+	flag.Add(cmd,
+		flag.NoCache(),
+	)
+
 	return
 }
 
 func runVMSizes(ctx context.Context) error {
 	client := client.FromContext(ctx).API()
 
-	sizes, err := client.PlatformVMSizes(ctx)
+	// sizes seldom change, so they're served from the cache while it has them
+	var sizes []api.VMSize
+	err := apicache.FromContext(ctx).FetchCached(ctx, "platform/vm-sizes", &sizes, func(ctx context.Context) (err error) {
+		sizes, err = client.PlatformVMSizes(ctx)
+
+		return
+	})
 	if err != nil {
 		return fmt.Errorf("failed retrieving sizes: %w", err)
 	}
 
 	out := iostreams.FromContext(ctx).Out
-	if config.FromContext(ctx).JSONOutput {
-		return render.JSON(out, sizes)
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, out, sizes)
 	}
 
 	var rows [][]string
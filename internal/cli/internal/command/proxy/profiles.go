@@ -0,0 +1,365 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jpillora/backoff"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/cli/internal/state"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/pkg/agent"
+	"github.com/superfly/flyctl/pkg/iostreams"
+	"github.com/superfly/flyctl/pkg/proxy"
+)
+
+func newSave() *cobra.Command {
+	const (
+		long = `Save a named port forwarding profile to the configuration file.
+
+Each forward takes the form of local[:remote]. Profiles are started with
+'fly proxy up'.
+`
+		short = "Save a port forwarding profile"
+	)
+
+	cmd := command.New("save <name> <local:remote>...", short, long, runSave,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.MinimumNArgs(2)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.String{
+			Name:        "remote-host",
+			Description: "Host to forward to; defaults to the internal DNS name of the app",
+		},
+	)
+
+	return cmd
+}
+
+func runSave(ctx context.Context) error {
+	args := flag.Args(ctx)
+
+	profile := config.ProxyProfile{
+		Name:       args[0],
+		App:        app.NameFromContext(ctx),
+		RemoteHost: flag.GetString(ctx, "remote-host"),
+		Ports:      args[1:],
+	}
+
+	for _, ports := range profile.Ports {
+		if _, _, err := splitPorts(ports); err != nil {
+			return err
+		}
+	}
+
+	if err := config.SetProxyProfile(state.ConfigFile(ctx), profile); err != nil {
+		return fmt.Errorf("failed saving proxy profile: %w", err)
+	}
+
+	io := iostreams.FromContext(ctx)
+	fmt.Fprintf(io.Out, "Saved proxy profile %s\n", profile.Name)
+
+	return nil
+}
+
+func newList() *cobra.Command {
+	const (
+		long  = "List the port forwarding profiles of the configuration file."
+		short = "List port forwarding profiles"
+	)
+
+	cmd := command.New("profiles", short, long, runList)
+	cmd.Args = cobra.NoArgs
+
+	return cmd
+}
+
+func runList(ctx context.Context) error {
+	profiles, err := config.ProxyProfiles(state.ConfigFile(ctx))
+	if err != nil {
+		return fmt.Errorf("failed reading proxy profiles: %w", err)
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, out, profiles)
+	}
+
+	rows := make([][]string, 0, len(profiles))
+	for _, p := range profiles {
+		remoteHost := p.RemoteHost
+		if remoteHost == "" {
+			remoteHost = defaultRemoteHost(p.App)
+		}
+
+		rows = append(rows, []string{p.Name, p.App, remoteHost, strings.Join(p.Ports, ", ")})
+	}
+
+	return render.Table(out, "", rows, "Name", "App", "Remote Host", "Ports")
+}
+
+func newRemove() *cobra.Command {
+	const (
+		long  = "Remove a port forwarding profile from the configuration file."
+		short = "Remove a port forwarding profile"
+	)
+
+	cmd := command.New("remove <name>", short, long, runRemove)
+	cmd.Args = cobra.ExactArgs(1)
+
+	return cmd
+}
+
+func runRemove(ctx context.Context) error {
+	name := flag.FirstArg(ctx)
+
+	if err := config.RemoveProxyProfile(state.ConfigFile(ctx), name); err != nil {
+		return fmt.Errorf("failed removing proxy profile: %w", err)
+	}
+
+	io := iostreams.FromContext(ctx)
+	fmt.Fprintf(io.Out, "Removed proxy profile %s\n", name)
+
+	return nil
+}
+
+func newUp() *cobra.Command {
+	const (
+		long = `Start the forwards of the given port forwarding profiles, or of all of
+them, until interrupted.
+
+Forwards whose tunnels drop are restarted automatically.
+`
+		short = "Start port forwarding profiles"
+	)
+
+	cmd := command.New("up [name]...", short, long, runUp,
+		command.RequireSession,
+	)
+
+	return cmd
+}
+
+func runUp(ctx context.Context) error {
+	profiles, err := config.ProxyProfiles(state.ConfigFile(ctx))
+	if err != nil {
+		return fmt.Errorf("failed reading proxy profiles: %w", err)
+	}
+
+	if profiles, err = selectProfiles(profiles, flag.Args(ctx)); err != nil {
+		return err
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+
+	for _, p := range profiles {
+		p := p
+
+		for _, ports := range p.Ports {
+			ports := ports
+
+			local, remote, err := splitPorts(ports)
+			if err != nil {
+				return err
+			}
+
+			params := proxy.ConnectParams{
+				Ports:      []string{local, remote},
+				RemoteHost: p.RemoteHost,
+			}
+
+			eg.Go(func() error {
+				return supervise(ctx, fmt.Sprintf("%s: forward %s", p.Name, ports), p.App, params)
+			})
+		}
+	}
+
+	return eg.Wait()
+}
+
+// selectProfiles returns the profiles with the given names, or all of them in
+// case no names are given.
+func selectProfiles(profiles []config.ProxyProfile, names []string) ([]config.ProxyProfile, error) {
+	if len(profiles) == 0 {
+		return nil, errors.New("no proxy profiles saved; save one with 'fly proxy save'")
+	}
+
+	if len(names) == 0 {
+		return profiles, nil
+	}
+
+	byName := make(map[string]config.ProxyProfile, len(profiles))
+	for _, p := range profiles {
+		byName[p.Name] = p
+	}
+
+	selected := make([]config.ProxyProfile, 0, len(names))
+	for _, name := range names {
+		p, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("proxy profile %q does not exist", name)
+		}
+
+		selected = append(selected, p)
+	}
+
+	return selected, nil
+}
+
+// probeInterval denotes how often the tunnels of supervised forwards are
+// probed.
+const probeInterval = 15 * time.Second
+
+// setupError wraps the errors of forwards which failed before they started
+// listening for connections.
+type setupError struct {
+	err error
+}
+
+func (e setupError) Error() string {
+	return e.err.Error()
+}
+
+func (e setupError) Unwrap() error {
+	return e.err
+}
+
+// supervise runs the given forward to the given app until ctx is done,
+// restarting it whenever it fails or its tunnel drops. Forwards which fail to
+// start in the first place aren't restarted; their error is returned
+// instead.
+func supervise(ctx context.Context, name, appName string, params proxy.ConnectParams) error {
+	io := iostreams.FromContext(ctx)
+	b := &backoff.Backoff{Min: time.Second, Max: 30 * time.Second}
+
+	for attempt := 0; ; attempt++ {
+		started := time.Now()
+		err := forward(ctx, appName, params)
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if attempt == 0 && errors.As(err, &setupError{}) {
+			return err
+		}
+
+		// forwards which ran for a while count as healthy
+		if time.Since(started) > b.Max {
+			b.Reset()
+		}
+
+		wait := b.Duration()
+		fmt.Fprintf(io.ErrOut, "%s dropped (%v); restarting in %s\n", name, err, wait)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// forward runs the given forward to the given app until it fails, its tunnel
+// drops or ctx is done.
+func forward(parent context.Context, appName string, params proxy.ConnectParams) error {
+	apiClient := client.FromContext(parent).API()
+
+	app, err := apiClient.GetApp(parent, appName)
+	if err != nil {
+		return setupError{err}
+	}
+
+	agentclient, err := agent.Establish(parent, apiClient)
+	if err != nil {
+		return setupError{err}
+	}
+
+	dialer, err := agentclient.ConnectToTunnel(parent, app.Organization.Slug)
+	if err != nil {
+		return setupError{err}
+	}
+
+	params.App = app
+	params.Dialer = dialer
+	params.DisableSpinner = true
+
+	if params.RemoteHost == "" {
+		params.RemoteHost = defaultRemoteHost(app.Name)
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	srv, err := proxy.NewServer(ctx, &params)
+	if err != nil {
+		return setupError{err}
+	}
+
+	io := iostreams.FromContext(ctx)
+	fmt.Fprintf(io.Out, "Proxying local port %s to remote %s\n", srv.LocalAddr, srv.Addr)
+
+	probed := make(chan error, 1)
+	go func() {
+		probed <- probe(ctx, agentclient, app.Organization.Slug)
+		cancel()
+	}()
+
+	err = srv.ProxyServer(ctx)
+
+	cancel()
+	if probeErr := <-probed; err == nil {
+		err = probeErr
+	}
+
+	return err
+}
+
+// probe probes the tunnel to the given organization until it fails or ctx is
+// done.
+func probe(ctx context.Context, c *agent.Client, slug string) error {
+	ticker := time.NewTicker(probeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := c.Probe(ctx, slug); err != nil && ctx.Err() == nil {
+				return fmt.Errorf("tunnel probe failed: %w", err)
+			}
+		}
+	}
+}
+
+// splitPorts splits the given forward into its local and remote ports.
+func splitPorts(ports string) (local, remote string, err error) {
+	parts := strings.Split(ports, ":")
+	if len(parts) > 2 || parts[0] == "" || parts[len(parts)-1] == "" {
+		return "", "", fmt.Errorf("invalid forward %q; forwards must be of the form local[:remote]", ports)
+	}
+
+	local, remote = parts[0], parts[len(parts)-1]
+
+	return
+}
+
+func defaultRemoteHost(appName string) string {
+	return fmt.Sprintf("%s.internal", appName)
+}
@@ -0,0 +1,45 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+)
+
+func TestSplitPorts(t *testing.T) {
+	local, remote, err := splitPorts("5432")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5432", "5432"}, []string{local, remote})
+
+	local, remote, err = splitPorts("16379:6379")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"16379", "6379"}, []string{local, remote})
+
+	for _, invalid := range []string{"", ":80", "80:", "1:2:3"} {
+		_, _, err = splitPorts(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestSelectProfiles(t *testing.T) {
+	db := config.ProxyProfile{Name: "db", App: "pg"}
+	cache := config.ProxyProfile{Name: "cache", App: "redis"}
+	profiles := []config.ProxyProfile{cache, db}
+
+	selected, err := selectProfiles(profiles, nil)
+	require.NoError(t, err)
+	assert.Equal(t, profiles, selected)
+
+	selected, err = selectProfiles(profiles, []string{"db"})
+	require.NoError(t, err)
+	assert.Equal(t, []config.ProxyProfile{db}, selected)
+
+	_, err = selectProfiles(profiles, []string{"web"})
+	assert.EqualError(t, err, `proxy profile "web" does not exist`)
+
+	_, err = selectProfiles(nil, nil)
+	assert.Error(t, err)
+}
@@ -16,14 +16,41 @@ import (
 
 func New() *cobra.Command {
 	var (
-		long  = strings.Trim(`Proxies connections to a fly VM through a Wireguard tunnel The current application DNS is the default remote host`, "\n")
+		long = strings.Trim(`Proxies connections to a fly VM through a Wireguard tunnel The current application DNS is the default remote host
+
+Use --select to pick the instance to proxy to from a list, or --instance to
+name it by its address or region. The proxy reconnects automatically whenever
+its tunnel drops, unless --no-reconnect is given.
+
+With --socks5 the proxy instead runs a SOCKS5 server on the given local
+address, which routes connections into the private network of the
+organization and resolves .internal hostnames through the tunnel:
+
+  fly proxy --socks5 :1080
+
+Forwards used often may be saved as named profiles with 'fly proxy save' and
+started all at once with 'fly proxy up'.
+
+Proxies always run through the WireGuard tunnel. Unlike 'fly ssh console
+-C <command>', they have no HTTPS fallback, since the Machines API only runs
+commands and can't carry connections; on networks which block UDP the agent
+carries the tunnel over a websocket instead, see 'fly agent status'.`, "\n")
 		short = `Proxies connections to a fly VM"`
 	)
 
 	cmd := command.New("proxy <local:remote> [remote_host]", short, long, run,
-		command.RequireSession, command.RequireAppName)
+		command.RequireSession, requireAppName)
+
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		if v, _ := cmd.Flags().GetString("socks5"); v != "" {
+			return cobra.NoArgs(cmd, args)
+		}
 
-	cmd.Args = cobra.RangeArgs(1, 2)
+		return cobra.RangeArgs(1, 2)(cmd, args)
+	}
+	cmd.Example = `flyctl proxy 5432 -a $APP
+flyctl proxy 8080:80 --instance ord -a $APP
+flyctl proxy --socks5 1080`
 
 	flag.Add(cmd,
 		flag.App(),
@@ -35,48 +62,115 @@ func New() *cobra.Command {
 			Default:     false,
 			Description: "Prompt to select from available instances from the current application",
 		},
+		flag.String{
+			Name:        "instance",
+			Description: "Address or region of the instance to proxy to",
+		},
+		flag.Bool{
+			Name:        "no-reconnect",
+			Description: "Exit instead of reconnecting when the tunnel drops",
+		},
+		flag.String{
+			Name:        "socks5",
+			Description: "Run a SOCKS5 server into the organization's network on the given local address, like :1080",
+		},
+	)
+
+	cmd.AddCommand(
+		newSave(),
+		newUp(),
+		newList(),
+		newRemove(),
 	)
 
 	return cmd
 }
 
+// requireAppName requires an app name, unless the proxy is to run a SOCKS5
+// server, which serves the network of an organization rather than an app.
+func requireAppName(ctx context.Context) (context.Context, error) {
+	if flag.GetString(ctx, "socks5") != "" {
+		return command.LoadAppNameIfPresent(ctx)
+	}
+
+	return command.RequireAppName(ctx)
+}
+
 func run(ctx context.Context) (err error) {
+	if addr := flag.GetString(ctx, "socks5"); addr != "" {
+		return runSOCKS5(ctx, addr)
+	}
+
 	client := client.FromContext(ctx).API()
 	appName := app.NameFromContext(ctx)
 	args := flag.Args(ctx)
 
-	app, err := client.GetApp(ctx, appName)
-
+	local, remote, err := splitPorts(args[0])
 	if err != nil {
 		return err
 	}
 
-	agentclient, err := agent.Establish(ctx, client)
+	params := proxy.ConnectParams{
+		Ports:    []string{local, remote},
+		Instance: flag.GetString(ctx, "instance"),
+	}
 
-	if err != nil {
-		return err
+	if len(args) > 1 {
+		params.RemoteHost = args[1]
 	}
 
-	dialer, err := agentclient.ConnectToTunnel(ctx, app.Organization.Slug)
+	if flag.GetBool(ctx, "no-reconnect") {
+		return connect(ctx, appName, params)
+	}
+
+	// prompt for the instance once, so that reconnecting sticks to it
+	if params.Instance == "" && flag.GetBool(ctx, "select") {
+		app, err := client.GetApp(ctx, appName)
+		if err != nil {
+			return err
+		}
+
+		agentclient, err := agent.Establish(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		if params.Instance, err = proxy.SelectInstance(ctx, app, agentclient); err != nil {
+			return err
+		}
+	}
+
+	return supervise(ctx, fmt.Sprintf("forward %s", args[0]), appName, params)
+}
+
+// connect runs the given forward to the given app until it fails or ctx is
+// done.
+func connect(ctx context.Context, appName string, params proxy.ConnectParams) error {
+	client := client.FromContext(ctx).API()
 
+	app, err := client.GetApp(ctx, appName)
 	if err != nil {
 		return err
 	}
 
-	ports := strings.Split(args[0], ":")
+	agentclient, err := agent.Establish(ctx, client)
+	if err != nil {
+		return err
+	}
 
-	params := &proxy.ConnectParams{
-		Ports:          ports,
-		App:            app,
-		Dialer:         dialer,
-		PromptInstance: flag.GetBool(ctx, "select"),
+	dialer, err := agentclient.ConnectToTunnel(ctx, app.Organization.Slug)
+	if err != nil {
+		// there's deliberately no HTTPS fallback here; see the help
+		return fmt.Errorf("%w; proxies require a WireGuard tunnel, though commands may still run over HTTPS via 'fly ssh console -C <command>'", err)
 	}
 
-	if len(args) > 1 {
-		params.RemoteHost = args[1]
-	} else {
-		params.RemoteHost = fmt.Sprintf("%s.internal", app.Name)
+	params.App = app
+	params.Dialer = dialer
+	params.PromptInstance = params.Instance == "" && flag.GetBool(ctx, "select")
+
+	if params.RemoteHost == "" {
+		params.RemoteHost = defaultRemoteHost(app.Name)
 	}
 
-	return proxy.Connect(ctx, params)
+	return proxy.Connect(ctx, &params)
 }
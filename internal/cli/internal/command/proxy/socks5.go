@@ -0,0 +1,99 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/prompt"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/pkg/agent"
+	"github.com/superfly/flyctl/pkg/iostreams"
+	"github.com/superfly/flyctl/pkg/proxy"
+)
+
+func runSOCKS5(ctx context.Context, addr string) error {
+	listenAddr, err := socksListenAddr(addr)
+	if err != nil {
+		return err
+	}
+
+	org, err := socksOrg(ctx)
+	if err != nil {
+		return err
+	}
+
+	apiClient := client.FromContext(ctx).API()
+
+	agentclient, err := agent.Establish(ctx, apiClient)
+	if err != nil {
+		return err
+	}
+
+	dialer, err := agentclient.ConnectToTunnel(ctx, org.Slug)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return err
+	}
+
+	srv := &proxy.SOCKS5Server{
+		Listener: listener,
+		Dial:     dialer.DialContext,
+		Resolve: func(ctx context.Context, host string) (string, error) {
+			return agentclient.Resolve(ctx, org.Slug, host)
+		},
+	}
+
+	io := iostreams.FromContext(ctx)
+	fmt.Fprintf(io.Out, "SOCKS5 proxy into the network of %s listening on %s\n", org.Slug, listener.Addr())
+
+	return srv.Serve(ctx)
+}
+
+// socksOrg returns the organization the SOCKS5 server serves the network of:
+// the one of the --org flag, the one of the current app, or one the user
+// selects.
+func socksOrg(ctx context.Context) (*api.Organization, error) {
+	if appName := app.NameFromContext(ctx); appName != "" && flag.GetOrg(ctx) == "" {
+		app, err := client.FromContext(ctx).API().GetApp(ctx, appName)
+		if err != nil {
+			return nil, fmt.Errorf("failed retrieving app %s: %w", appName, err)
+		}
+
+		return &app.Organization, nil
+	}
+
+	return prompt.Org(ctx, nil)
+}
+
+// socksListenAddr normalizes the given SOCKS5 listen address. Addresses
+// which don't name a host bind to the loopback interface, so that the
+// network of the organization isn't exposed to anyone else.
+func socksListenAddr(addr string) (string, error) {
+	if _, err := strconv.Atoi(addr); err == nil {
+		addr = ":" + addr
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid SOCKS5 address %q; use [host]:port, like :1080", addr)
+	}
+
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return "", fmt.Errorf("invalid SOCKS5 port %q", port)
+	}
+
+	if host == "" {
+		host = "127.0.0.1"
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
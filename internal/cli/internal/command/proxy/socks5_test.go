@@ -0,0 +1,29 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSOCKSListenAddr(t *testing.T) {
+	cases := map[string]string{
+		":1080":         "127.0.0.1:1080",
+		"1080":          "127.0.0.1:1080",
+		"0.0.0.0:1080":  "0.0.0.0:1080",
+		"[::1]:1080":    "[::1]:1080",
+		"localhost:900": "localhost:900",
+	}
+
+	for addr, expected := range cases {
+		got, err := socksListenAddr(addr)
+		require.NoError(t, err, addr)
+		assert.Equal(t, expected, got, addr)
+	}
+
+	for _, invalid := range []string{"localhost", ":http", ":70000"} {
+		_, err := socksListenAddr(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
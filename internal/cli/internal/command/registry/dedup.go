@@ -0,0 +1,294 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/prompt"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/registry"
+)
+
+func newDedupReport() *cobra.Command {
+	const (
+		long = `Report the layers the current images of the apps of an organization share,
+along with the storage their layers take up in total and once deduplicated.
+
+Apps are grouped by their base layer, the bottom layer of their image, so that
+the base images which are in use across the organization stand out; images
+built on a common base share its layers and only store them once. Images which
+aren't hosted on the Fly registry are skipped.
+`
+		short = "Report the layers the images of an organization share"
+	)
+
+	cmd := command.New("dedup-report", short, long, runDedupReport,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.Org(),
+		flag.Int{
+			Name:        "top",
+			Description: "Number of the largest shared layers to list",
+			Default:     10,
+		},
+	)
+
+	return cmd
+}
+
+// DedupReport is the structured output of dedup-report.
+type DedupReport struct {
+	Org     string   `json:"org"`
+	Images  int      `json:"images"`
+	Skipped []string `json:"skipped"`
+
+	// TotalBytes is the size of the layers of all the images, counting
+	// shared layers once per image.
+	TotalBytes int64 `json:"total_bytes"`
+
+	// UniqueBytes is the size of the distinct layers of all the images.
+	UniqueBytes int64 `json:"unique_bytes"`
+
+	// SavedBytes is the storage layer deduplication saves.
+	SavedBytes int64 `json:"saved_bytes"`
+
+	SharedLayers []SharedLayer `json:"shared_layers"`
+	BaseLayers   []SharedLayer `json:"base_layers"`
+}
+
+// SharedLayer denotes a layer and the apps the images of which contain it.
+type SharedLayer struct {
+	Digest string   `json:"digest"`
+	Bytes  int64    `json:"bytes"`
+	Apps   []string `json:"apps"`
+}
+
+// appImage wraps the layers of the current image of an app.
+type appImage struct {
+	App    string
+	Ref    string
+	Layers []registry.Descriptor
+}
+
+func runDedupReport(ctx context.Context) error {
+	org, err := prompt.Org(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	var (
+		cfg       = config.FromContext(ctx)
+		io        = iostreams.FromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+	)
+
+	apps, err := apiClient.GetOrganizationAppImages(ctx, org.Slug)
+	if err != nil {
+		return fmt.Errorf("failed retrieving the apps of %s: %w", org.Slug, err)
+	}
+
+	reg := &registry.Client{
+		Host:  cfg.RegistryHost,
+		Token: cfg.AccessToken,
+	}
+
+	images, skipped := fetchImages(ctx, reg, apps)
+	report := buildDedupReport(org.Slug, images, skipped)
+
+	if cfg.StructuredOutput() {
+		return render.Structured(ctx, io.Out, report)
+	}
+
+	for _, app := range report.Skipped {
+		fmt.Fprintf(io.ErrOut, "Skipped %s: its current image isn't hosted on %s\n", app, reg.Host)
+	}
+
+	if report.Images == 0 {
+		fmt.Fprintf(io.Out, "No app of %s has an image on %s\n", org.Slug, reg.Host)
+
+		return nil
+	}
+
+	fmt.Fprintf(io.Out, "%d images of %s take up %s; once deduplicated, their layers take up %s (%s saved)\n\n",
+		report.Images, org.Slug,
+		humanize.Bytes(uint64(report.TotalBytes)),
+		humanize.Bytes(uint64(report.UniqueBytes)),
+		humanize.Bytes(uint64(report.SavedBytes)))
+
+	if err := render.Table(io.Out, "Base layers", layerRows(report.BaseLayers), "Layer", "Size", "Apps", "Used By"); err != nil {
+		return err
+	}
+
+	shared := report.SharedLayers
+	if top := flag.GetInt(ctx, "top"); top > 0 && len(shared) > top {
+		shared = shared[:top]
+	}
+
+	if len(shared) == 0 {
+		fmt.Fprintln(io.Out, "No layers are shared between apps")
+
+		return nil
+	}
+
+	return render.Table(io.Out, "Largest shared layers", layerRows(shared), "Layer", "Size", "Apps", "Used By")
+}
+
+// fetchImages returns the layers of the current images of the given apps,
+// along with the names of the apps the images of which aren't hosted on the
+// registry. Apps which were never deployed are left out.
+func fetchImages(ctx context.Context, reg *registry.Client, apps []api.App) (images []appImage, skipped []string) {
+	io := iostreams.FromContext(ctx)
+
+	for _, app := range apps {
+		if app.CurrentRelease == nil || app.CurrentRelease.ImageRef == "" {
+			continue
+		}
+
+		ref := app.CurrentRelease.ImageRef
+		if !reg.Owns(ref) {
+			skipped = append(skipped, app.Name)
+
+			continue
+		}
+
+		// an image failing to resolve shouldn't void the report of the rest
+		manifest, err := reg.GetManifest(ctx, ref)
+		if err != nil {
+			fmt.Fprintf(io.ErrOut, "WARNING: %v\n", err)
+
+			continue
+		}
+
+		images = append(images, appImage{
+			App:    app.Name,
+			Ref:    ref,
+			Layers: manifest.Layers,
+		})
+	}
+
+	return
+}
+
+func buildDedupReport(org string, images []appImage, skipped []string) *DedupReport {
+	report := &DedupReport{
+		Org:     org,
+		Images:  len(images),
+		Skipped: skipped,
+	}
+
+	layers := map[string]*SharedLayer{}
+	bases := map[string]*SharedLayer{}
+
+	for _, image := range images {
+		for i, desc := range image.Layers {
+			report.TotalBytes += desc.Size
+
+			layer := layerOf(layers, desc)
+			if len(layer.Apps) == 0 {
+				report.UniqueBytes += desc.Size
+			}
+			layer.Apps = appendApp(layer.Apps, image.App)
+
+			if i == 0 {
+				base := layerOf(bases, desc)
+				base.Apps = appendApp(base.Apps, image.App)
+			}
+		}
+	}
+
+	report.SavedBytes = report.TotalBytes - report.UniqueBytes
+
+	for _, layer := range layers {
+		if len(layer.Apps) > 1 {
+			report.SharedLayers = append(report.SharedLayers, *layer)
+		}
+	}
+
+	for _, base := range bases {
+		report.BaseLayers = append(report.BaseLayers, *base)
+	}
+
+	// shared layers are ranked by the storage they save, base layers by how
+	// many apps are built on them
+	sortLayers(report.SharedLayers, func(l SharedLayer) int64 {
+		return l.Bytes * int64(len(l.Apps)-1)
+	})
+	sortLayers(report.BaseLayers, func(l SharedLayer) int64 {
+		return int64(len(l.Apps))
+	})
+
+	return report
+}
+
+func layerOf(layers map[string]*SharedLayer, desc registry.Descriptor) *SharedLayer {
+	layer := layers[desc.Digest]
+	if layer == nil {
+		layer = &SharedLayer{
+			Digest: desc.Digest,
+			Bytes:  desc.Size,
+		}
+		layers[desc.Digest] = layer
+	}
+
+	return layer
+}
+
+// appendApp appends app to apps unless the same image contains the layer
+// more than once, in which case it's already the last one.
+func appendApp(apps []string, app string) []string {
+	if n := len(apps); n > 0 && apps[n-1] == app {
+		return apps
+	}
+
+	return append(apps, app)
+}
+
+func sortLayers(layers []SharedLayer, rank func(SharedLayer) int64) {
+	sort.Slice(layers, func(i, j int) bool {
+		if ri, rj := rank(layers[i]), rank(layers[j]); ri != rj {
+			return ri > rj
+		}
+
+		return layers[i].Digest < layers[j].Digest
+	})
+}
+
+func layerRows(layers []SharedLayer) [][]string {
+	rows := make([][]string, 0, len(layers))
+
+	for _, layer := range layers {
+		rows = append(rows, []string{
+			shortDigest(layer.Digest),
+			humanize.Bytes(uint64(layer.Bytes)),
+			fmt.Sprint(len(layer.Apps)),
+			strings.Join(layer.Apps, ", "),
+		})
+	}
+
+	return rows
+}
+
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) > 12 {
+		digest = digest[:12]
+	}
+
+	return digest
+}
@@ -0,0 +1,49 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/internal/registry"
+)
+
+func TestBuildDedupReport(t *testing.T) {
+	var (
+		base   = registry.Descriptor{Digest: "sha256:base", Size: 100}
+		other  = registry.Descriptor{Digest: "sha256:other", Size: 80}
+		deps   = registry.Descriptor{Digest: "sha256:deps", Size: 30}
+		web    = registry.Descriptor{Digest: "sha256:web", Size: 5}
+		worker = registry.Descriptor{Digest: "sha256:worker", Size: 7}
+	)
+
+	images := []appImage{
+		{App: "web", Layers: []registry.Descriptor{base, deps, web}},
+		{App: "worker", Layers: []registry.Descriptor{base, deps, worker, worker}},
+		{App: "legacy", Layers: []registry.Descriptor{other, web}},
+	}
+
+	report := buildDedupReport("acme", images, []string{"nginx"})
+
+	assert.Equal(t, 3, report.Images)
+	assert.Equal(t, []string{"nginx"}, report.Skipped)
+	assert.EqualValues(t, 135+144+85, report.TotalBytes)
+	assert.EqualValues(t, 222, report.UniqueBytes)
+	assert.EqualValues(t, 142, report.SavedBytes)
+
+	assert.Equal(t, []SharedLayer{
+		{Digest: "sha256:base", Bytes: 100, Apps: []string{"web", "worker"}},
+		{Digest: "sha256:deps", Bytes: 30, Apps: []string{"web", "worker"}},
+		{Digest: "sha256:web", Bytes: 5, Apps: []string{"web", "legacy"}},
+	}, report.SharedLayers)
+
+	assert.Equal(t, []SharedLayer{
+		{Digest: "sha256:base", Bytes: 100, Apps: []string{"web", "worker"}},
+		{Digest: "sha256:other", Bytes: 80, Apps: []string{"legacy"}},
+	}, report.BaseLayers)
+}
+
+func TestShortDigest(t *testing.T) {
+	assert.Equal(t, "0123456789ab", shortDigest("sha256:0123456789abcdef"))
+	assert.Equal(t, "abc", shortDigest("sha256:abc"))
+}
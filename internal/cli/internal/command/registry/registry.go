@@ -0,0 +1,26 @@
+// Package registry implements the registry command chain.
+package registry
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+)
+
+// New initializes and returns a new registry Command.
+func New() *cobra.Command {
+	const (
+		long = `Commands that report on the images the apps of an organization push to the
+Fly registry.
+`
+		short = "Report on registry images"
+	)
+
+	cmd := command.New("registry", short, long, nil)
+
+	cmd.AddCommand(
+		newDedupReport(),
+	)
+
+	return cmd
+}
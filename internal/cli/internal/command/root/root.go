@@ -12,32 +12,52 @@ import (
 	"github.com/superfly/flyctl/internal/cli/internal/command/agent"
 	"github.com/superfly/flyctl/internal/cli/internal/command/apps"
 	"github.com/superfly/flyctl/internal/cli/internal/command/auth"
+	"github.com/superfly/flyctl/internal/cli/internal/command/builders"
 	"github.com/superfly/flyctl/internal/cli/internal/command/builds"
+	"github.com/superfly/flyctl/internal/cli/internal/command/ci"
+	"github.com/superfly/flyctl/internal/cli/internal/command/contexts"
+	"github.com/superfly/flyctl/internal/cli/internal/command/costs"
 	"github.com/superfly/flyctl/internal/cli/internal/command/create"
+	"github.com/superfly/flyctl/internal/cli/internal/command/cron"
 	"github.com/superfly/flyctl/internal/cli/internal/command/curl"
 	"github.com/superfly/flyctl/internal/cli/internal/command/deploy"
+	"github.com/superfly/flyctl/internal/cli/internal/command/deploys"
 	"github.com/superfly/flyctl/internal/cli/internal/command/destroy"
 	"github.com/superfly/flyctl/internal/cli/internal/command/dig"
 	"github.com/superfly/flyctl/internal/cli/internal/command/docs"
 	"github.com/superfly/flyctl/internal/cli/internal/command/doctor"
+	"github.com/superfly/flyctl/internal/cli/internal/command/dr"
+	"github.com/superfly/flyctl/internal/cli/internal/command/help"
 	"github.com/superfly/flyctl/internal/cli/internal/command/history"
+	"github.com/superfly/flyctl/internal/cli/internal/command/hostnames"
 	"github.com/superfly/flyctl/internal/cli/internal/command/image"
+	"github.com/superfly/flyctl/internal/cli/internal/command/load"
 	"github.com/superfly/flyctl/internal/cli/internal/command/logs"
+	"github.com/superfly/flyctl/internal/cli/internal/command/maintenance"
+	"github.com/superfly/flyctl/internal/cli/internal/command/metrics"
 	"github.com/superfly/flyctl/internal/cli/internal/command/move"
 	"github.com/superfly/flyctl/internal/cli/internal/command/open"
 	"github.com/superfly/flyctl/internal/cli/internal/command/orgs"
 	"github.com/superfly/flyctl/internal/cli/internal/command/ping"
 	"github.com/superfly/flyctl/internal/cli/internal/command/platform"
 	"github.com/superfly/flyctl/internal/cli/internal/command/proxy"
+	"github.com/superfly/flyctl/internal/cli/internal/command/registry"
 	"github.com/superfly/flyctl/internal/cli/internal/command/releases"
 	"github.com/superfly/flyctl/internal/cli/internal/command/restart"
 	"github.com/superfly/flyctl/internal/cli/internal/command/resume"
+	"github.com/superfly/flyctl/internal/cli/internal/command/rules"
+	"github.com/superfly/flyctl/internal/cli/internal/command/sandbox"
 	"github.com/superfly/flyctl/internal/cli/internal/command/services"
+	"github.com/superfly/flyctl/internal/cli/internal/command/settings"
 	"github.com/superfly/flyctl/internal/cli/internal/command/status"
 	"github.com/superfly/flyctl/internal/cli/internal/command/suspend"
+	"github.com/superfly/flyctl/internal/cli/internal/command/tokens"
+	"github.com/superfly/flyctl/internal/cli/internal/command/trace"
 	"github.com/superfly/flyctl/internal/cli/internal/command/version"
 	"github.com/superfly/flyctl/internal/cli/internal/command/volumes"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
 	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/flyerr"
 )
 
 // New initializes and returns a reference to a new root command.
@@ -124,22 +144,38 @@ func New() *cobra.Command {
 		orgs.New(),
 		auth.New(),
 		builds.New(),
+		builders.New(),
+		costs.New(),
 		open.New(), // TODO: deprecate
 		curl.New(),
 		platform.New(),
 		docs.New(),
 		releases.New(),
 		deploy.New(),
+		deploys.New(),
+		load.New(),
+		cron.New(),
 		history.New(),
 		status.New(),
 		logs.New(),
+		metrics.New(),
 		doctor.New(),
 		dig.New(),
+		hostnames.New(),
 		volumes.New(),
 		agent.New(),
 		image.New(),
+		registry.New(),
 		ping.New(),
 		proxy.New(),
+		dr.New(),
+		ci.New(),
+		maintenance.New(),
+		rules.New(),
+		sandbox.New(),
+		settings.New(),
+		tokens.New(),
+		trace.New(),
 	}
 
 	if os.Getenv("DEV") != "" {
@@ -154,7 +190,8 @@ func New() *cobra.Command {
 
 	// instead of root being constructed like in the commented out snippet, we
 	// rebuild it the old way.
-	root := cmd.NewRootCmd(client.New())
+	legacyClient := client.New()
+	root := cmd.NewRootCmd(legacyClient)
 
 	// gather the slice of commands which must be replaced with their new
 	// iterations
@@ -170,18 +207,74 @@ func New() *cobra.Command {
 
 	// make sure the remaining old commands run the preparers
 	// TODO: remove when migration is done
-	wrapRunE(root)
+	wrapRunE(root, legacyClient)
 
 	// and finally, add the new commands
 	root.AddCommand(newCommands...)
 
+	registerAppNameCompletion(root)
+
+	root.PersistentFlags().Bool(flag.ErrorJSONName, false, "Print errors as JSON objects on stderr, for wrappers to react on")
+	root.PersistentFlags().Bool(flag.ExamplesName, false, "Print examples of the command, for the current app")
+	root.PersistentFlags().Duration(flag.TimeoutName, 0, "Abort the command once it runs for longer than this, e.g. 10m")
+	root.PersistentFlags().String(flag.ContextName, "", "Name of the context to use instead of the current one; see 'fly config contexts'")
+	root.PersistentFlags().Bool(flag.DebugHTTPName, false, "Trace the operation, variables, status and duration of requests to the API on stderr, like LOG_LEVEL=trace")
+	root.PersistentFlags().String(flag.DebugHTTPFileName, "", "Append the traces of --debug-http to this file instead of stderr")
+
+	// contexts are managed by a subcommand of config, which is yet to be
+	// migrated
+	for _, c := range root.Commands() {
+		if c.Name() == "config" {
+			c.AddCommand(contexts.New())
+		}
+	}
+
+	root.SetHelpCommand(help.New())
+
+	// invalid invocations exit with the validation exit code
+	root.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &flyerr.ValidationError{Err: err}
+	})
+	wrapArgs(root)
+
 	return root
 }
 
-func wrapRunE(cmd *cobra.Command) {
+func wrapArgs(cmd *cobra.Command) {
+	for _, c := range cmd.Commands() {
+		wrapArgs(c)
+	}
+
+	if cmd.Args == nil {
+		return
+	}
+
+	args := cmd.Args
+	cmd.Args = func(cmd *cobra.Command, a []string) error {
+		if err := args(cmd, a); err != nil {
+			return &flyerr.ValidationError{Err: err}
+		}
+
+		return nil
+	}
+}
+
+// registerAppNameCompletion has the app flags of cmd and its subcommands
+// complete the names of the apps of the current account.
+func registerAppNameCompletion(cmd *cobra.Command) {
+	for _, c := range cmd.Commands() {
+		registerAppNameCompletion(c)
+	}
+
+	if cmd.LocalFlags().Lookup(flag.AppName) != nil {
+		_ = cmd.RegisterFlagCompletionFunc(flag.AppName, command.CompleteAppNames)
+	}
+}
+
+func wrapRunE(cmd *cobra.Command, legacyClient *client.Client) {
 	if cmd.HasAvailableSubCommands() {
 		for _, c := range cmd.Commands() {
-			wrapRunE(c)
+			wrapRunE(c, legacyClient)
 		}
 	}
 
@@ -193,5 +286,11 @@ func wrapRunE(cmd *cobra.Command) {
 		panic(cmd.Name())
 	}
 
-	cmd.RunE = command.WrapRunE(cmd.RunE)
+	run := cmd.RunE
+	cmd.RunE = command.WrapRunE(func(cmd *cobra.Command, args []string) error {
+		// the preparers may have selected a context of another account
+		legacyClient.InitApi()
+
+		return run(cmd, args)
+	})
 }
@@ -0,0 +1,42 @@
+package root
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+)
+
+func TestVerboseShorthand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	root := New()
+
+	// merging the global flags into those of each command panics in case a
+	// command defines a shorthand the global flags do, such as -v
+	var walk func(*cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		assert.NotPanics(t, func() { _ = cmd.InheritedFlags() }, cmd.CommandPath())
+
+		for _, c := range cmd.Commands() {
+			walk(c)
+		}
+	}
+	walk(root)
+
+	for _, args := range [][]string{{"status"}, {"machine", "run"}} {
+		cmd, _, err := root.Find(args)
+		require.NoError(t, err, args)
+
+		require.NoError(t, cmd.ParseFlags([]string{"-v"}), args)
+
+		cfg := config.New()
+		cfg.ApplyFlags(cmd.Flags())
+		assert.Equal(t, iostreams.VerbosityVerbose, cfg.Verbosity(), args)
+	}
+}
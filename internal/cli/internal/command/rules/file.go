@@ -0,0 +1,76 @@
+package rules
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// file is the layout of the TOML files rules are imported from and exported
+// to.
+type file struct {
+	Rules []fileRule `toml:"rule"`
+}
+
+type fileRule struct {
+	Kind      string   `toml:"kind"`
+	Path      string   `toml:"path"`
+	RPS       int      `toml:"rps,omitempty"`
+	Burst     int      `toml:"burst,omitempty"`
+	IPs       []string `toml:"ips,omitempty"`
+	Countries []string `toml:"countries,omitempty"`
+}
+
+func writeFile(w io.Writer, rules []api.EdgeRule) error {
+	var f file
+
+	for _, rule := range rules {
+		f.Rules = append(f.Rules, fileRule{
+			Kind:      kindName(rule.Kind),
+			Path:      rule.Path,
+			RPS:       rule.RPS,
+			Burst:     rule.Burst,
+			IPs:       rule.IPs,
+			Countries: rule.Countries,
+		})
+	}
+
+	return toml.NewEncoder(w).Encode(f)
+}
+
+// readFile reads and validates the rules of the given TOML file.
+func readFile(r io.Reader) ([]api.EdgeRuleInput, error) {
+	var f file
+	if _, err := toml.DecodeReader(r, &f); err != nil {
+		return nil, err
+	}
+
+	inputs := make([]api.EdgeRuleInput, 0, len(f.Rules))
+
+	for i, rule := range f.Rules {
+		kind, err := parseKind(rule.Kind)
+		if err != nil {
+			return nil, fmt.Errorf("rule #%d: %w", i+1, err)
+		}
+
+		in := api.EdgeRuleInput{
+			Kind:      kind,
+			Path:      rule.Path,
+			RPS:       rule.RPS,
+			Burst:     rule.Burst,
+			IPs:       rule.IPs,
+			Countries: rule.Countries,
+		}
+
+		if err := validate(&in); err != nil {
+			return nil, fmt.Errorf("rule #%d: %w", i+1, err)
+		}
+
+		inputs = append(inputs, in)
+	}
+
+	return inputs, nil
+}
@@ -0,0 +1,242 @@
+package rules
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// kinds maps the names the CLI uses for the kinds of edge rules to the ones
+// of the API.
+var kinds = map[string]string{
+	"rate-limit":      api.EdgeRuleRateLimit,
+	"allow":           api.EdgeRuleAllow,
+	"deny":            api.EdgeRuleDeny,
+	"block-countries": api.EdgeRuleBlockCountries,
+}
+
+func kindNames() string {
+	names := make([]string, 0, len(kinds))
+	for name := range kinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+func parseKind(name string) (string, error) {
+	if kind, ok := kinds[name]; ok {
+		return kind, nil
+	}
+
+	return "", fmt.Errorf("unknown rule kind %q; use one of %s", name, kindNames())
+}
+
+func kindName(kind string) string {
+	for name, k := range kinds {
+		if k == kind {
+			return name
+		}
+	}
+
+	return strings.ToLower(kind)
+}
+
+// validate normalizes and validates the given rule input.
+func validate(in *api.EdgeRuleInput) (err error) {
+	if in.Path == "" {
+		in.Path = "/"
+	}
+
+	if !strings.HasPrefix(in.Path, "/") {
+		return fmt.Errorf("invalid path %q; paths must start with /", in.Path)
+	}
+
+	switch in.Kind {
+	case api.EdgeRuleRateLimit:
+		if in.RPS < 1 {
+			return fmt.Errorf("invalid rate limit of %d requests per second; it must be at least 1", in.RPS)
+		}
+
+		if in.Burst < 0 {
+			return fmt.Errorf("invalid burst of %d requests; it can't be negative", in.Burst)
+		}
+	case api.EdgeRuleAllow, api.EdgeRuleDeny:
+		if len(in.IPs) == 0 {
+			return fmt.Errorf("%s rules require at least one IP address or range", kindName(in.Kind))
+		}
+
+		in.IPs, err = parseNetworks(in.IPs)
+	case api.EdgeRuleBlockCountries:
+		if len(in.Countries) == 0 {
+			return fmt.Errorf("%s rules require at least one country", kindName(in.Kind))
+		}
+
+		in.Countries, err = parseCountries(in.Countries)
+	default:
+		err = fmt.Errorf("unknown rule kind %q; use one of %s", in.Kind, kindNames())
+	}
+
+	return
+}
+
+// parseNetworks normalizes the given IP addresses and CIDR ranges to CIDR
+// ranges.
+func parseNetworks(values []string) ([]string, error) {
+	networks := make([]string, 0, len(values))
+
+	for _, v := range values {
+		if !strings.Contains(v, "/") {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", v)
+			}
+
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+
+			networks = append(networks, (&net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}).String())
+
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP range %q", v)
+		}
+
+		networks = append(networks, network.String())
+	}
+
+	return networks, nil
+}
+
+func parseCountries(values []string) ([]string, error) {
+	countries := make([]string, 0, len(values))
+
+	for _, v := range values {
+		code := strings.ToUpper(strings.TrimSpace(v))
+
+		if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+			return nil, fmt.Errorf("invalid country %q; use ISO 3166-1 alpha-2 codes, like US", v)
+		}
+
+		countries = append(countries, code)
+	}
+
+	return countries, nil
+}
+
+// matchesPath reports whether requests to path fall under the rules of
+// rulePath. Rule paths match themselves and the paths below them.
+func matchesPath(rulePath, path string) bool {
+	switch {
+	case rulePath == "" || rulePath == "/":
+		return true
+	case strings.HasSuffix(rulePath, "/"):
+		return strings.HasPrefix(path, rulePath)
+	default:
+		return path == rulePath || strings.HasPrefix(path, rulePath+"/")
+	}
+}
+
+func containsIP(networks []string, ip net.IP) bool {
+	for _, v := range networks {
+		if _, network, err := net.ParseCIDR(v); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsCountry(countries []string, country string) bool {
+	for _, c := range countries {
+		if strings.EqualFold(c, country) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verdict is the outcome of a request against the edge rules of an app.
+type verdict struct {
+	Allowed    bool
+	Reason     string
+	Rule       *api.EdgeRule `json:",omitempty"`
+	RateLimits []api.EdgeRule
+}
+
+// evaluate determines how the edge treats a request to path, from ip and
+// country, according to rules.
+//
+// Allow lists take precedence: an address an allow rule lists bypasses deny
+// and country rules, while any other address is denied. Rate limits apply
+// to all requests which aren't denied.
+func evaluate(rules []api.EdgeRule, path string, ip net.IP, country string) (v verdict) {
+	var (
+		allowListed bool
+		allowRule   *api.EdgeRule
+		denyRule    *api.EdgeRule
+	)
+
+	for i := range rules {
+		rule := &rules[i]
+
+		if !matchesPath(rule.Path, path) {
+			continue
+		}
+
+		switch rule.Kind {
+		case api.EdgeRuleAllow:
+			if allowRule == nil {
+				allowRule = rule
+			}
+
+			if containsIP(rule.IPs, ip) {
+				allowListed = true
+				allowRule = rule
+			}
+		case api.EdgeRuleDeny:
+			if denyRule == nil && containsIP(rule.IPs, ip) {
+				denyRule = rule
+				v.Reason = fmt.Sprintf("%s is denied", ip)
+			}
+		case api.EdgeRuleBlockCountries:
+			if denyRule == nil && country != "" && containsCountry(rule.Countries, country) {
+				denyRule = rule
+				v.Reason = fmt.Sprintf("requests from %s are blocked", strings.ToUpper(country))
+			}
+		case api.EdgeRuleRateLimit:
+			v.RateLimits = append(v.RateLimits, *rule)
+		}
+	}
+
+	switch {
+	case allowListed:
+		v.Allowed = true
+		v.Rule = allowRule
+		v.Reason = fmt.Sprintf("%s is allowed", ip)
+	case allowRule != nil:
+		v.Rule = allowRule
+		v.Reason = fmt.Sprintf("%s is not on the allow list", ip)
+	case denyRule != nil:
+		v.Rule = denyRule
+	default:
+		v.Allowed = true
+		v.Reason = "no rule denies the request"
+	}
+
+	if !v.Allowed {
+		v.RateLimits = nil
+	}
+
+	return
+}
@@ -0,0 +1,108 @@
+package rules
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestValidate(t *testing.T) {
+	in := api.EdgeRuleInput{Kind: api.EdgeRuleDeny, IPs: []string{"10.0.0.1", "192.168.0.7/24", "fdaa::1"}}
+	require.NoError(t, validate(&in))
+	assert.Equal(t, "/", in.Path)
+	assert.Equal(t, []string{"10.0.0.1/32", "192.168.0.0/24", "fdaa::1/128"}, in.IPs)
+
+	in = api.EdgeRuleInput{Kind: api.EdgeRuleBlockCountries, Path: "/admin", Countries: []string{"us", " de"}}
+	require.NoError(t, validate(&in))
+	assert.Equal(t, []string{"US", "DE"}, in.Countries)
+
+	for _, in := range []api.EdgeRuleInput{
+		{Kind: api.EdgeRuleRateLimit, Path: "/login"},
+		{Kind: api.EdgeRuleRateLimit, Path: "login", RPS: 5},
+		{Kind: api.EdgeRuleAllow},
+		{Kind: api.EdgeRuleDeny, IPs: []string{"10.0.0.300"}},
+		{Kind: api.EdgeRuleBlockCountries, Countries: []string{"USA"}},
+		{Kind: "REDIRECT"},
+	} {
+		in := in
+		assert.Error(t, validate(&in), "%+v", in)
+	}
+}
+
+func TestMatchesPath(t *testing.T) {
+	cases := []struct {
+		rule, path string
+		match      bool
+	}{
+		{"/", "/anything", true},
+		{"/login", "/login", true},
+		{"/login", "/login/2fa", true},
+		{"/login", "/logins", false},
+		{"/api/", "/api/v1", true},
+		{"/api/", "/api", false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.match, matchesPath(c.rule, c.path), "%s %s", c.rule, c.path)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	rules := []api.EdgeRule{
+		{ID: "limit", Kind: api.EdgeRuleRateLimit, Path: "/login", RPS: 5},
+		{ID: "deny", Kind: api.EdgeRuleDeny, Path: "/", IPs: []string{"10.0.0.0/8"}},
+		{ID: "countries", Kind: api.EdgeRuleBlockCountries, Path: "/", Countries: []string{"XX"}},
+		{ID: "allow", Kind: api.EdgeRuleAllow, Path: "/admin", IPs: []string{"10.1.0.0/16"}},
+	}
+
+	v := evaluate(rules, "/login", net.ParseIP("1.2.3.4"), "US")
+	assert.True(t, v.Allowed)
+	assert.Nil(t, v.Rule)
+	require.Len(t, v.RateLimits, 1)
+	assert.Equal(t, "limit", v.RateLimits[0].ID)
+
+	v = evaluate(rules, "/login", net.ParseIP("10.2.3.4"), "")
+	assert.False(t, v.Allowed)
+	assert.Equal(t, "deny", v.Rule.ID)
+	assert.Empty(t, v.RateLimits)
+
+	v = evaluate(rules, "/", net.ParseIP("1.2.3.4"), "xx")
+	assert.False(t, v.Allowed)
+	assert.Equal(t, "countries", v.Rule.ID)
+
+	v = evaluate(rules, "/admin/users", net.ParseIP("10.1.2.3"), "")
+	assert.True(t, v.Allowed)
+	assert.Equal(t, "allow", v.Rule.ID)
+
+	v = evaluate(rules, "/admin", net.ParseIP("1.2.3.4"), "")
+	assert.False(t, v.Allowed)
+	assert.Equal(t, "allow", v.Rule.ID)
+}
+
+func TestFileRoundTrip(t *testing.T) {
+	rules := []api.EdgeRule{
+		{ID: "a", Kind: api.EdgeRuleRateLimit, Path: "/login", RPS: 5, Burst: 10},
+		{ID: "b", Kind: api.EdgeRuleDeny, Path: "/", IPs: []string{"10.0.0.0/8"}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeFile(&buf, rules))
+	assert.Contains(t, buf.String(), `kind = "rate-limit"`)
+
+	inputs, err := readFile(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, []api.EdgeRuleInput{
+		{Kind: api.EdgeRuleRateLimit, Path: "/login", RPS: 5, Burst: 10},
+		{Kind: api.EdgeRuleDeny, Path: "/", IPs: []string{"10.0.0.0/8"}},
+	}, inputs)
+
+	_, err = readFile(strings.NewReader("[[rule]]\nkind = \"redirect\"\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rule #1")
+}
@@ -0,0 +1,468 @@
+// Package rules implements the rules command chain.
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/format"
+	"github.com/superfly/flyctl/internal/cli/internal/prompt"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+// New initializes and returns a new rules Command.
+func New() *cobra.Command {
+	const (
+		long = `Manage the edge rules of an app.
+
+Edge rules apply to requests before they reach the instances of an app. They
+rate limit requests, allow or deny IP addresses and ranges, and block
+countries, for the requests whose path falls under the path of the rule.
+`
+		short = "Manage the edge rules of an app"
+	)
+
+	cmd := command.New("rules", short, long, nil)
+
+	cmd.AddCommand(
+		newAdd(),
+		newList(),
+		newDelete(),
+		newTest(),
+		newImport(),
+		newExport(),
+	)
+
+	return cmd
+}
+
+func newAdd() *cobra.Command {
+	const (
+		long = `Add an edge rule to an app.
+
+Each rule does one of: rate limit requests (--rps), allow listed addresses
+(--allow), deny listed addresses (--deny) or block countries
+(--block-country). Once an allow rule covers a path, addresses it doesn't list
+are denied.
+`
+		short = "Add an edge rule"
+	)
+
+	cmd := command.New("add", short, long, runAdd,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.String{
+			Name:        "path",
+			Description: "Path the rule applies to, along with the paths below it",
+			Default:     "/",
+		},
+		flag.Int{
+			Name:        "rps",
+			Description: "Requests per second, per client address, to limit requests to",
+		},
+		flag.Int{
+			Name:        "burst",
+			Description: "Requests over the rate limit to tolerate in bursts",
+		},
+		flag.StringSlice{
+			Name:        "allow",
+			Description: "IP addresses or CIDR ranges to allow",
+		},
+		flag.StringSlice{
+			Name:        "deny",
+			Description: "IP addresses or CIDR ranges to deny",
+		},
+		flag.StringSlice{
+			Name:        "block-country",
+			Description: "Countries, as ISO 3166-1 alpha-2 codes, to block",
+		},
+	)
+
+	return cmd
+}
+
+func newList() *cobra.Command {
+	const (
+		long  = "List the edge rules of an app."
+		short = "List edge rules"
+	)
+
+	cmd := command.New("list", short, long, runList,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Aliases = []string{"ls"}
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func newDelete() *cobra.Command {
+	const (
+		long  = "Delete the edge rule with the given ID. Rule IDs are shown by the list command."
+		short = "Delete an edge rule"
+	)
+
+	cmd := command.New("delete <id>", short, long, runDelete,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Aliases = []string{"rm"}
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+func newTest() *cobra.Command {
+	const (
+		long = `Show how the edge rules of an app treat a request to the given path, from
+the given address and country, without sending one.
+`
+		short = "Test a request against the edge rules"
+	)
+
+	cmd := command.New("test <path>", short, long, runTest,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.String{
+			Name:        "ip",
+			Description: "IP address the request comes from",
+		},
+		flag.String{
+			Name:        "country",
+			Description: "Country, as an ISO 3166-1 alpha-2 code, the request comes from",
+		},
+	)
+
+	_ = cmd.MarkFlagRequired("ip")
+
+	return cmd
+}
+
+func newImport() *cobra.Command {
+	const (
+		long = `Replace the edge rules of an app with the ones of the given TOML file, in
+the format the export command writes.
+`
+		short = "Import edge rules from a file"
+	)
+
+	cmd := command.New("import <path>", short, long, runImport,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Yes(),
+	)
+
+	return cmd
+}
+
+func newExport() *cobra.Command {
+	const (
+		long = `Write the edge rules of an app to the given TOML file, or to stdout when no
+file is given.
+`
+		short = "Export edge rules to a file"
+	)
+
+	cmd := command.New("export [path]", short, long, runExport,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.MaximumNArgs(1)
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+	)
+
+	return cmd
+}
+
+var errOneKind = errors.New("specify exactly one of --rps, --allow, --deny or --block-country")
+
+func runAdd(ctx context.Context) error {
+	appName := app.NameFromContext(ctx)
+
+	in := api.EdgeRuleInput{
+		Path: flag.GetString(ctx, "path"),
+	}
+
+	var set int
+	if rps := flag.GetInt(ctx, "rps"); rps != 0 {
+		in.Kind, in.RPS, in.Burst = api.EdgeRuleRateLimit, rps, flag.GetInt(ctx, "burst")
+		set++
+	}
+	if ips := flag.GetStringSlice(ctx, "allow"); len(ips) > 0 {
+		in.Kind, in.IPs = api.EdgeRuleAllow, ips
+		set++
+	}
+	if ips := flag.GetStringSlice(ctx, "deny"); len(ips) > 0 {
+		in.Kind, in.IPs = api.EdgeRuleDeny, ips
+		set++
+	}
+	if countries := flag.GetStringSlice(ctx, "block-country"); len(countries) > 0 {
+		in.Kind, in.Countries = api.EdgeRuleBlockCountries, countries
+		set++
+	}
+
+	if set != 1 {
+		return errOneKind
+	}
+
+	if err := validate(&in); err != nil {
+		return err
+	}
+
+	rule, err := client.FromContext(ctx).API().AddEdgeRule(ctx, api.AddEdgeRuleInput{
+		AppID:         appName,
+		EdgeRuleInput: in,
+	})
+	if err != nil {
+		return fmt.Errorf("failed adding edge rule to %s: %w", appName, err)
+	}
+
+	return renderRules(ctx, fmt.Sprintf("Added edge rule to %s", appName), []api.EdgeRule{*rule})
+}
+
+func runList(ctx context.Context) error {
+	appName := app.NameFromContext(ctx)
+
+	rules, err := client.FromContext(ctx).API().GetEdgeRules(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving edge rules of %s: %w", appName, err)
+	}
+
+	return renderRules(ctx, fmt.Sprintf("Edge rules of %s", appName), rules)
+}
+
+func runDelete(ctx context.Context) error {
+	var (
+		appName = app.NameFromContext(ctx)
+		id      = flag.FirstArg(ctx)
+	)
+
+	if err := client.FromContext(ctx).API().DeleteEdgeRule(ctx, api.DeleteEdgeRuleInput{
+		AppID:  appName,
+		RuleID: id,
+	}); err != nil {
+		return fmt.Errorf("failed deleting edge rule %s of %s: %w", id, appName, err)
+	}
+
+	fmt.Fprintf(iostreams.FromContext(ctx).Out, "Deleted edge rule %s of %s\n", id, appName)
+
+	return nil
+}
+
+func runTest(ctx context.Context) error {
+	var (
+		appName = app.NameFromContext(ctx)
+		path    = flag.FirstArg(ctx)
+		country = flag.GetString(ctx, "country")
+	)
+
+	ip := net.ParseIP(flag.GetString(ctx, "ip"))
+	if ip == nil {
+		return fmt.Errorf("invalid IP address %q", flag.GetString(ctx, "ip"))
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	rules, err := client.FromContext(ctx).API().GetEdgeRules(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving edge rules of %s: %w", appName, err)
+	}
+
+	v := evaluate(rules, path, ip, country)
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, out, v)
+	}
+
+	outcome := "allowed"
+	if !v.Allowed {
+		outcome = "denied"
+	}
+
+	fmt.Fprintf(out, "A request to %s from %s would be %s: %s\n", path, ip, outcome, v.Reason)
+
+	if v.Rule != nil {
+		fmt.Fprintf(out, "Decided by rule %s (%s %s)\n", v.Rule.ID, kindName(v.Rule.Kind), v.Rule.Path)
+	}
+
+	for _, rule := range v.RateLimits {
+		fmt.Fprintf(out, "Rate limited by rule %s to %s\n", rule.ID, describe(rule))
+	}
+
+	return nil
+}
+
+func runImport(ctx context.Context) error {
+	var (
+		appName = app.NameFromContext(ctx)
+		path    = flag.FirstArg(ctx)
+	)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed opening rules file: %w", err)
+	}
+	defer f.Close()
+
+	inputs, err := readFile(f)
+	if err != nil {
+		return fmt.Errorf("failed reading rules file %s: %w", path, err)
+	}
+
+	if !flag.GetYes(ctx) {
+		msg := fmt.Sprintf("Replace the edge rules of %s with the %d rules of %s?", appName, len(inputs), path)
+
+		switch confirmed, err := prompt.Confirm(ctx, msg); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	rules, err := client.FromContext(ctx).API().ReplaceEdgeRules(ctx, api.ReplaceEdgeRulesInput{
+		AppID: appName,
+		Rules: inputs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed importing edge rules of %s: %w", appName, err)
+	}
+
+	return renderRules(ctx, fmt.Sprintf("Edge rules of %s", appName), rules)
+}
+
+func runExport(ctx context.Context) (err error) {
+	appName := app.NameFromContext(ctx)
+
+	rules, err := client.FromContext(ctx).API().GetEdgeRules(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving edge rules of %s: %w", appName, err)
+	}
+
+	path := flag.FirstArg(ctx)
+	if path == "" {
+		return writeFile(iostreams.FromContext(ctx).Out, rules)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed creating rules file: %w", err)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	if err = writeFile(f, rules); err != nil {
+		return fmt.Errorf("failed writing rules file: %w", err)
+	}
+
+	fmt.Fprintf(iostreams.FromContext(ctx).ErrOut, "Wrote %d edge rules of %s to %s\n", len(rules), appName, path)
+
+	return
+}
+
+func describe(rule api.EdgeRule) string {
+	switch rule.Kind {
+	case api.EdgeRuleRateLimit:
+		s := strconv.Itoa(rule.RPS) + " rps"
+		if rule.Burst > 0 {
+			s += fmt.Sprintf(" (burst %d)", rule.Burst)
+		}
+
+		return s
+	case api.EdgeRuleAllow, api.EdgeRuleDeny:
+		return strings.Join(rule.IPs, ", ")
+	case api.EdgeRuleBlockCountries:
+		return strings.Join(rule.Countries, ", ")
+	default:
+		return "-"
+	}
+}
+
+func renderRules(ctx context.Context, title string, rules []api.EdgeRule) error {
+	out := iostreams.FromContext(ctx).Out
+
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, out, rules)
+	}
+
+	rows := make([][]string, 0, len(rules))
+	for _, rule := range rules {
+		created := "-"
+		if rule.CreatedAt != nil {
+			created = format.RelativeTime(*rule.CreatedAt)
+		}
+
+		rows = append(rows, []string{
+			rule.ID,
+			kindName(rule.Kind),
+			rule.Path,
+			describe(rule),
+			created,
+		})
+	}
+
+	return render.Table(out, title, rows, "ID", "Kind", "Path", "Rule", "Created")
+}
@@ -0,0 +1,298 @@
+// Package sandbox implements the sandbox command chain.
+package sandbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/format"
+	"github.com/superfly/flyctl/internal/cli/internal/prompt"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/cli/internal/state"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/flyerr"
+	"github.com/superfly/flyctl/internal/sandbox"
+)
+
+const (
+	namePrefix = "sandbox-"
+
+	defaultTTL = time.Hour
+	maxTTL     = 7 * 24 * time.Hour
+)
+
+// New initializes and returns a new sandbox Command.
+func New() (cmd *cobra.Command) {
+	const (
+		long = `Commands that manage sandboxes: organizations for tutorials, demos and trying
+out risky operations, which are destroyed along with everything created in
+them once their time to live runs out.
+
+Sandboxes are recorded in the configuration directory of the machine they were
+created from. They're destroyed there by a background flyctl process, as well
+as by the next command which runs once they've expired.
+`
+		short = "Manage disposable sandbox organizations"
+	)
+
+	cmd = command.New("sandbox", short, long, nil)
+
+	cmd.AddCommand(
+		newCreate(),
+		newList(),
+		newDestroy(),
+		newReap(),
+	)
+
+	return
+}
+
+func newCreate() (cmd *cobra.Command) {
+	const (
+		long = `Create a sandbox organization which, along with every app created in it, is
+destroyed after --ttl. Resources are created in it via --org <slug>.
+`
+		short = "Create a sandbox"
+	)
+
+	cmd = command.New("create [name]", short, long, runCreate,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.Example = `flyctl sandbox create
+flyctl sandbox create demo --ttl 3h`
+
+	flag.Add(cmd,
+		flag.Duration{
+			Name:        "ttl",
+			Description: "How long the sandbox lives for before it's destroyed",
+			Default:     defaultTTL,
+		},
+	)
+
+	return
+}
+
+func runCreate(ctx context.Context) error {
+	ttl := flag.GetDuration(ctx, "ttl")
+	if ttl <= 0 || ttl > maxTTL {
+		return fmt.Errorf("--ttl must be in the (0, %s] range", maxTTL)
+	}
+
+	name, err := sandboxName(flag.FirstArg(ctx))
+	if err != nil {
+		return err
+	}
+
+	var (
+		io        = iostreams.FromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+		store     = sandbox.NewStore(state.ConfigDirectory(ctx))
+	)
+
+	org, err := apiClient.CreateOrganization(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed creating organization: %w", err)
+	}
+
+	now := time.Now().UTC()
+	sb := &sandbox.Sandbox{
+		Slug:      org.Slug,
+		OrgID:     org.ID,
+		Name:      org.Name,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	if err := store.Add(ctx, sb); err != nil {
+		fmt.Fprintf(io.ErrOut, "Warning: %v; run 'fly sandbox destroy %s' once you're done with it\n", err, sb.Slug)
+	}
+
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(io.Out, sb)
+	}
+
+	fmt.Fprintf(io.Out, "Created sandbox %s, which will be destroyed at %s (in %s)\n",
+		sb.Slug, sb.ExpiresAt.Local().Format(time.RFC3339), ttl)
+	fmt.Fprintf(io.Out, "Create resources in it via --org %s, e.g. 'fly launch --org %s'\n", sb.Slug, sb.Slug)
+
+	return nil
+}
+
+// sandboxName returns the organization name of the sandbox of the given name,
+// generating one in case it's empty.
+func sandboxName(name string) (string, error) {
+	if name == "" {
+		var b [3]byte
+		if _, err := rand.Read(b[:]); err != nil {
+			return "", err
+		}
+		name = hex.EncodeToString(b[:])
+	}
+
+	if !strings.HasPrefix(name, namePrefix) {
+		name = namePrefix + name
+	}
+
+	return name, nil
+}
+
+func newList() (cmd *cobra.Command) {
+	const (
+		long = `List the sandboxes created from this machine. Expired ones are destroyed
+before listing.
+`
+		short = "List sandboxes"
+	)
+
+	cmd = command.New("list", short, long, runList,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.NoArgs
+	cmd.Aliases = []string{"ls"}
+
+	return
+}
+
+func runList(ctx context.Context) error {
+	store := sandbox.NewStore(state.ConfigDirectory(ctx))
+
+	sandboxes, err := store.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).JSONOutput {
+		return render.JSON(out, sandboxes)
+	}
+
+	rows := make([][]string, 0, len(sandboxes))
+	for _, sb := range sandboxes {
+		remaining := "expired"
+		if !sb.Expired() {
+			remaining = time.Until(sb.ExpiresAt).Round(time.Minute).String()
+		}
+
+		rows = append(rows, []string{
+			sb.Slug,
+			format.RelativeTime(sb.CreatedAt),
+			sb.ExpiresAt.Local().Format(time.RFC3339),
+			remaining,
+		})
+	}
+
+	return render.Table(out, "", rows, "Slug", "Created", "Expires", "Remaining")
+}
+
+func newDestroy() (cmd *cobra.Command) {
+	const (
+		long = `Destroy a sandbox ahead of its expiry, along with every app created in it.
+`
+		short = "Destroy a sandbox"
+	)
+
+	cmd = command.New("destroy <slug>", short, long, runDestroy,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.Yes(),
+	)
+
+	return
+}
+
+func runDestroy(ctx context.Context) error {
+	var (
+		io    = iostreams.FromContext(ctx)
+		slug  = flag.FirstArg(ctx)
+		store = sandbox.NewStore(state.ConfigDirectory(ctx))
+	)
+
+	sb, err := store.Get(ctx, slug)
+	if errors.Is(err, sandbox.ErrNotFound) {
+		return &flyerr.NotFoundError{
+			Err: fmt.Errorf("no sandbox %s was created from this machine; see 'fly sandbox list'", slug),
+		}
+	} else if err != nil {
+		return err
+	}
+
+	if !flag.GetYes(ctx) {
+		switch confirmed, err := prompt.Confirmf(ctx, "Destroy sandbox %s and all of its apps?", sb.Slug); {
+		case err == nil:
+			if !confirmed {
+				return nil
+			}
+		case prompt.IsNonInteractive(err):
+			return prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+		default:
+			return err
+		}
+	}
+
+	destroyed, err := store.Destroy(ctx, client.FromContext(ctx).API(), sb)
+	for _, app := range destroyed {
+		fmt.Fprintf(io.Out, "Destroyed app %s\n", app)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(io.Out, "Destroyed sandbox %s\n", sb.Slug)
+
+	return nil
+}
+
+func newReap() (cmd *cobra.Command) {
+	const (
+		short = "Destroy expired sandboxes"
+		long  = `Destroy the sandboxes which have expired or, given a slug, wait for that
+sandbox to expire and destroy it.
+`
+	)
+
+	cmd = command.New("reap [slug]", short, long, runReap,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.Hidden = true
+
+	return
+}
+
+func runReap(ctx context.Context) error {
+	var (
+		apiClient = client.FromContext(ctx).API()
+		store     = sandbox.NewStore(state.ConfigDirectory(ctx))
+	)
+
+	if slug := flag.FirstArg(ctx); slug != "" {
+		return store.Wait(ctx, apiClient, slug)
+	}
+
+	reaped, err := store.Reap(ctx, apiClient)
+	for _, sb := range reaped {
+		fmt.Fprintf(iostreams.FromContext(ctx).Out, "Destroyed expired sandbox %s\n", sb.Slug)
+	}
+
+	return err
+}
@@ -88,8 +88,8 @@ func runLaunch(ctx context.Context) (err error) {
 		return
 	}
 
-	if io := iostreams.FromContext(ctx); config.FromContext(ctx).JSONOutput {
-		_ = render.JSON(io.Out, struct {
+	if io := iostreams.FromContext(ctx); config.FromContext(ctx).StructuredOutput() {
+		_ = render.Structured(ctx, io.Out, struct {
 			ID   string `json:"id"`
 			Name string `json:"name"`
 		}{
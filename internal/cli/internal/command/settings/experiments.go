@@ -0,0 +1,148 @@
+package settings
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/cli/internal/state"
+)
+
+func newExperiments() *cobra.Command {
+	const (
+		long = `Manage the experiments enabled for the current user.
+
+Experiments are behaviors of flyctl which ship disabled while they mature.
+Besides enabling them here, experiments may be enabled for a single run via the
+FLY_EXPERIMENTS environment variable, as a comma separated list, or via the
+--experiments flag of the commands which support them, such as deploy.
+`
+		short = "Manage experiments"
+	)
+
+	cmd := command.New("experiments", short, long, nil)
+
+	cmd.AddCommand(
+		newExperimentsList(),
+		newExperimentsEnable(),
+		newExperimentsDisable(),
+	)
+
+	return cmd
+}
+
+func newExperimentsList() *cobra.Command {
+	const (
+		long  = "List the known experiments and whether they're enabled."
+		short = "List experiments"
+	)
+
+	cmd := command.New("list", short, long, runExperimentsList)
+
+	cmd.Aliases = []string{"ls"}
+	cmd.Args = cobra.NoArgs
+
+	return cmd
+}
+
+func newExperimentsEnable() *cobra.Command {
+	const (
+		long  = "Enable the named experiments for the current user."
+		short = "Enable experiments"
+	)
+
+	cmd := command.New("enable <name>...", short, long, runExperimentsEnable)
+
+	cmd.Args = cobra.MinimumNArgs(1)
+
+	return cmd
+}
+
+func newExperimentsDisable() *cobra.Command {
+	const (
+		long  = "Disable the named experiments for the current user."
+		short = "Disable experiments"
+	)
+
+	cmd := command.New("disable <name>...", short, long, runExperimentsDisable)
+
+	cmd.Args = cobra.MinimumNArgs(1)
+
+	return cmd
+}
+
+type experimentStatus struct {
+	Name        string
+	Description string
+	Enabled     bool
+}
+
+func runExperimentsList(ctx context.Context) error {
+	statuses := make([]experimentStatus, 0, len(command.Experiments))
+	for _, e := range command.Experiments {
+		statuses = append(statuses, experimentStatus{
+			Name:        e.Name,
+			Description: e.Description,
+			Enabled:     command.ExperimentEnabled(ctx, e.Name),
+		})
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, out, statuses)
+	}
+
+	rows := make([][]string, 0, len(statuses))
+	for _, s := range statuses {
+		enabled := "no"
+		if s.Enabled {
+			enabled = "yes"
+		}
+
+		rows = append(rows, []string{s.Name, enabled, s.Description})
+	}
+
+	return render.Table(out, "", rows, "Name", "Enabled", "Description")
+}
+
+func runExperimentsEnable(ctx context.Context) error {
+	names := flag.Args(ctx)
+	for _, name := range names {
+		if _, err := command.LookupExperiment(name); err != nil {
+			return err
+		}
+	}
+
+	if err := config.EnableExperiments(state.ConfigFile(ctx), names...); err != nil {
+		return fmt.Errorf("failed enabling experiments: %w", err)
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	for _, name := range names {
+		fmt.Fprintf(out, "Enabled experiment %s\n", name)
+	}
+
+	return nil
+}
+
+func runExperimentsDisable(ctx context.Context) error {
+	names := flag.Args(ctx)
+
+	if err := config.DisableExperiments(state.ConfigFile(ctx), names...); err != nil {
+		return fmt.Errorf("failed disabling experiments: %w", err)
+	}
+
+	out := iostreams.FromContext(ctx).Out
+	for _, name := range names {
+		fmt.Fprintf(out, "Disabled experiment %s\n", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package settings
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/state"
+)
+
+func newNotifications() *cobra.Command {
+	const (
+		long = `Show, enable or disable desktop notifications for the current user.
+
+Once enabled, deployments send a desktop notification when their build
+finishes and once they succeed or fail, as the --notify flag of deploy does
+for a single run. Notifications are sent via osascript on macOS, notify-send
+on Linux and toast notifications on Windows.
+`
+		short = "Manage desktop notifications"
+		usage = "notifications [on|off]"
+	)
+
+	cmd := command.New(usage, short, long, runNotifications)
+
+	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.ValidArgs = []string{"on", "off"}
+
+	return cmd
+}
+
+func runNotifications(ctx context.Context) error {
+	out := iostreams.FromContext(ctx).Out
+
+	var enabled bool
+	switch arg := flag.FirstArg(ctx); arg {
+	case "":
+		status := "disabled"
+		if config.FromContext(ctx).DesktopNotifications {
+			status = "enabled"
+		}
+		fmt.Fprintf(out, "Desktop notifications are %s\n", status)
+
+		return nil
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		return fmt.Errorf("invalid argument %q; use on or off", arg)
+	}
+
+	if err := config.SetDesktopNotifications(state.ConfigFile(ctx), enabled); err != nil {
+		return fmt.Errorf("failed updating desktop notifications: %w", err)
+	}
+
+	if enabled {
+		fmt.Fprintln(out, "Enabled desktop notifications")
+	} else {
+		fmt.Fprintln(out, "Disabled desktop notifications")
+	}
+
+	return nil
+}
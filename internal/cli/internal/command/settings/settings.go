@@ -0,0 +1,26 @@
+// Package settings implements the settings command chain.
+package settings
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+)
+
+// New initializes and returns a new settings Command.
+func New() *cobra.Command {
+	const (
+		long = `Manage the settings of flyctl, which it stores in its configuration file.
+`
+		short = "Manage flyctl settings"
+	)
+
+	cmd := command.New("settings", short, long, nil)
+
+	cmd.AddCommand(
+		newExperiments(),
+		newNotifications(),
+	)
+
+	return cmd
+}
@@ -0,0 +1,427 @@
+package status
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/metrics"
+	"github.com/superfly/flyctl/pkg/iostreams"
+	"github.com/superfly/flyctl/pkg/logs"
+
+	metricscmd "github.com/superfly/flyctl/internal/cli/internal/command/metrics"
+	"github.com/superfly/flyctl/internal/cli/internal/format"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+)
+
+// The views of the dashboard.
+const (
+	viewOverview = iota
+	viewLogs
+	viewChecks
+	viewVM
+)
+
+// dashboardLogLimit is the number of recent log lines the logs view of an
+// instance shows.
+const dashboardLogLimit = 40
+
+const (
+	// dashboardMetricsRange is the range the metrics panel of the dashboard
+	// covers, as its title states.
+	dashboardMetricsRange = 15 * time.Minute
+
+	dashboardSparkWidth = 20
+)
+
+// dashboard is the state of the live status dashboard of an app.
+type dashboard struct {
+	client  *api.Client
+	appName string
+	all     bool
+
+	view int
+
+	// selectedID denotes the instance views other than the overview are of.
+	// It's tracked by ID, so that the selection survives refreshes which
+	// reorder instances.
+	selectedID string
+
+	status        *api.AppStatus
+	backupRegions []api.Region
+	release       *api.Release
+
+	// instance denotes the details of the selected instance, as fetched by
+	// views other than the overview.
+	instance *api.AllocationStatus
+
+	// metricsClient is the client the metrics panel is queried with; the panel is
+	// hidden when nil.
+	metricsClient *metrics.Client
+	panels        []metricscmd.PanelResult
+	metricsErr    error
+
+	refreshedAt time.Time
+	err         error
+}
+
+// refresh fetches the status of the app, along with the details of the
+// selected instance when a view of it is shown.
+func (d *dashboard) refresh(ctx context.Context) (err error) {
+	defer func() {
+		if d.err = err; err == nil {
+			d.refreshedAt = time.Now()
+		}
+	}()
+
+	status, err := d.client.GetAppStatus(ctx, d.appName, d.all)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app %s: %w", d.appName, err)
+	}
+	d.status = status
+
+	if status.Deployed {
+		if _, d.backupRegions, err = d.client.ListAppRegions(ctx, d.appName); err != nil {
+			return fmt.Errorf("failed retrieving backup regions for %s: %w", d.appName, err)
+		}
+	}
+
+	releases, err := d.client.GetAppReleases(ctx, d.appName, 1)
+	if err != nil {
+		return fmt.Errorf("failed retrieving releases of %s: %w", d.appName, err)
+	}
+
+	d.release = nil
+	if len(releases) > 0 {
+		d.release = &releases[0]
+	}
+
+	d.clampSelection()
+
+	if d.metricsClient != nil && d.view == viewOverview {
+		d.refreshMetrics(ctx)
+	}
+
+	if d.view != viewOverview {
+		return d.refreshInstance(ctx)
+	}
+
+	return nil
+}
+
+// refreshMetrics queries the metrics panel. Failing to do so is reported on
+// the panel rather than failing the refresh, as metrics may lag behind.
+func (d *dashboard) refreshMetrics(ctx context.Context) {
+	end := time.Now()
+	start := end.Add(-dashboardMetricsRange)
+	step := metricscmd.DefaultStep(dashboardMetricsRange)
+
+	var (
+		panels = metrics.AppPanels(d.appName)
+		rs     = make([]metricscmd.PanelResult, 0, len(panels))
+	)
+
+	for _, p := range panels {
+		series, err := d.metricsClient.QueryRange(ctx, p.Query, start, end, step)
+		if err != nil {
+			d.metricsErr = fmt.Errorf("failed querying %s: %w", p.Title, err)
+
+			return
+		}
+
+		rs = append(rs, metricscmd.PanelResult{
+			Title:  p.Title,
+			Query:  p.Query,
+			Unit:   p.Unit,
+			Series: series,
+		})
+	}
+
+	d.panels, d.metricsErr = rs, nil
+}
+
+func (d *dashboard) refreshInstance(ctx context.Context) (err error) {
+	if d.selectedID == "" {
+		d.view, d.instance = viewOverview, nil
+
+		return nil
+	}
+
+	if d.instance, err = d.client.GetAllocationStatus(ctx, d.appName, d.selectedID, dashboardLogLimit); err != nil {
+		return fmt.Errorf("failed retrieving allocation status for %s: %w", d.selectedID, err)
+	}
+
+	return nil
+}
+
+// selected returns the index of the selected instance, or -1 in case there's
+// none to select.
+func (d *dashboard) selected() int {
+	if d.status == nil {
+		return -1
+	}
+
+	for i, alloc := range d.status.Allocations {
+		if alloc.ID == d.selectedID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// clampSelection selects the first instance in case the selected one went
+// away.
+func (d *dashboard) clampSelection() {
+	if d.selected() >= 0 {
+		return
+	}
+
+	d.selectedID = ""
+	if allocs := d.status.Allocations; len(allocs) > 0 {
+		d.selectedID = allocs[0].ID
+	}
+}
+
+func (d *dashboard) move(delta int) {
+	i := d.selected()
+	if i < 0 {
+		return
+	}
+
+	allocs := d.status.Allocations
+	if i += delta; i >= 0 && i < len(allocs) {
+		d.selectedID = allocs[i].ID
+	}
+}
+
+// handle handles the given keystroke. It reports whether the dashboard
+// should quit.
+func (d *dashboard) handle(ctx context.Context, key render.Key) (quit bool) {
+	switch key {
+	case 'q', render.KeyInterrupt:
+		return true
+	case render.KeyUp, 'k':
+		if d.view == viewOverview {
+			d.move(-1)
+		}
+	case render.KeyDown, 'j':
+		if d.view == viewOverview {
+			d.move(1)
+		}
+	case render.KeyEscape, render.KeyBackspace, render.KeyLeft, 'o':
+		d.view, d.instance = viewOverview, nil
+	case 'r':
+		_ = d.refresh(ctx)
+	case render.KeyEnter, 'l':
+		d.open(ctx, viewLogs)
+	case 'c':
+		d.open(ctx, viewChecks)
+	case 'v':
+		d.open(ctx, viewVM)
+	}
+
+	return false
+}
+
+func (d *dashboard) open(ctx context.Context, view int) {
+	if d.selectedID == "" {
+		return
+	}
+
+	wasOverview := d.view == viewOverview
+	d.view = view
+
+	if wasOverview || d.instance == nil {
+		d.err = d.refreshInstance(ctx)
+	}
+}
+
+// frame renders the dashboard.
+func (d *dashboard) frame(colorize *iostreams.ColorScheme, rate time.Duration) string {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "%s at: %s (refreshing every %s)\n\n",
+		colorize.Bold(d.appName),
+		colorize.Bold(d.refreshedAt.UTC().Format("15:04:05")),
+		rate)
+
+	if d.status != nil {
+		switch {
+		case d.view == viewOverview:
+			d.renderOverview(&buf)
+		case d.instance != nil:
+			d.renderInstance(&buf)
+		}
+	}
+
+	if d.err != nil {
+		fmt.Fprintln(&buf, colorize.Red(d.err.Error()))
+		fmt.Fprintln(&buf)
+	}
+
+	hints := "↑/↓ select  enter logs  c checks  v vm  r refresh  q quit"
+	if d.view != viewOverview {
+		hints = "esc back  enter logs  c checks  v vm  r refresh  q quit"
+	}
+	fmt.Fprint(&buf, colorize.Gray(hints))
+
+	return buf.String()
+}
+
+func (d *dashboard) renderOverview(w io.Writer) {
+	app := d.status
+
+	_ = renderApp(w, app)
+
+	if r := d.release; r != nil {
+		_ = render.VerticalTable(w, "Latest Release", [][]string{
+			{
+				fmt.Sprintf("v%d", r.Version),
+				r.Status,
+				r.Description,
+				r.User.Email,
+				format.RelativeTime(r.CreatedAt),
+			},
+		}, "Version", "Status", "Description", "User", "Created")
+	}
+
+	if !app.Deployed {
+		fmt.Fprintln(w, "App has not been deployed yet.")
+		fmt.Fprintln(w)
+
+		return
+	}
+
+	if ds := app.DeploymentStatus; ds != nil && ds.InProgress {
+		_ = renderDeploymentStatus(w, ds)
+	}
+
+	_ = render.Table(w, "Regions", regionRows(app.Allocations), "Region", "Instances", "Healthy", "Restarts")
+
+	if d.metricsClient != nil {
+		d.renderMetrics(w)
+	}
+
+	_ = render.SelectedAllocationStatuses(w, "Instances", d.backupRegions, d.selected(), app.Allocations...)
+}
+
+// renderMetrics renders the metrics panel, with a row per series.
+func (d *dashboard) renderMetrics(w io.Writer) {
+	if d.metricsErr != nil {
+		fmt.Fprintf(w, "Metrics\n%s\n\n", d.metricsErr)
+
+		return
+	}
+
+	var rows [][]string
+	for _, p := range d.panels {
+		for j := range p.Series {
+			s := &p.Series[j]
+
+			name := s.Name()
+			if name == "" {
+				name = "total"
+			}
+
+			rows = append(rows, []string{
+				p.Title,
+				name,
+				p.Unit.Format(s.Last()),
+				render.Sparkline(s.Values(), dashboardSparkWidth),
+			})
+		}
+	}
+
+	_ = render.Table(w, "Metrics (last 15m)", rows, "Metric", "Series", "Current", "Trend")
+}
+
+// regionRows summarizes the health of the given instances by region.
+func regionRows(allocs []*api.AllocationStatus) (rows [][]string) {
+	type summary struct {
+		instances, healthy, restarts int
+	}
+
+	byRegion := map[string]*summary{}
+	for _, alloc := range allocs {
+		s := byRegion[alloc.Region]
+		if s == nil {
+			s = &summary{}
+			byRegion[alloc.Region] = s
+		}
+
+		s.instances++
+		s.restarts += alloc.Restarts
+
+		if alloc.Healthy && alloc.CriticalCheckCount == 0 {
+			s.healthy++
+		}
+	}
+
+	regions := make([]string, 0, len(byRegion))
+	for region := range byRegion {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	for _, region := range regions {
+		s := byRegion[region]
+
+		rows = append(rows, []string{
+			region,
+			strconv.Itoa(s.instances),
+			strconv.Itoa(s.healthy),
+			strconv.Itoa(s.restarts),
+		})
+	}
+
+	return
+}
+
+func (d *dashboard) renderInstance(w io.Writer) {
+	alloc := d.instance
+
+	_ = renderAllocationStatus(w, alloc)
+
+	switch d.view {
+	case viewLogs:
+		fmt.Fprintln(w, "Recent Logs")
+
+		for _, e := range alloc.RecentLogs {
+			_ = render.LogEntry(w, logs.LogEntry{
+				Instance:  e.Instance,
+				Level:     e.Level,
+				Message:   e.Message,
+				Region:    e.Region,
+				Timestamp: e.Timestamp,
+				Meta:      e.Meta,
+			}, render.RemoveNewlines(), render.HideRegion(), render.HideAllocID())
+		}
+
+		fmt.Fprintln(w)
+	case viewChecks:
+		_ = renderChecks(w, alloc.Checks)
+	case viewVM:
+		var volumes []string
+		for _, v := range alloc.AttachedVolumes.Nodes {
+			volumes = append(volumes, v.Name)
+		}
+
+		_ = render.VerticalTable(w, "VM", [][]string{
+			{
+				alloc.ID,
+				alloc.PrivateIP,
+				format.RelativeTime(alloc.UpdatedAt),
+				strings.Join(volumes, ", "),
+			},
+		}, "ID", "Private IP", "Updated", "Volumes")
+
+		_ = render.AllocationEvents(w, "Recent Events", alloc.Events...)
+	}
+}
@@ -63,9 +63,9 @@ func runInstance(ctx context.Context) (err error) {
 	}
 
 	out := iostreams.FromContext(ctx).Out
-	if config.FromContext(ctx).JSONOutput {
+	if config.FromContext(ctx).StructuredOutput() {
 		// TODO: checks & recent events are being outputted twice
-		err = render.JSON(out,
+		err = render.Structured(ctx, out,
 			map[string]interface{}{
 				"Instance":      alloc,
 				"Recent Events": alloc.Events,
@@ -2,17 +2,13 @@
 package status
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"strconv"
-	"strings"
 	"time"
 
-	"github.com/azazeal/pause"
-	"github.com/inancgumus/screen"
 	"github.com/spf13/cobra"
 
 	"github.com/superfly/flyctl/pkg/iostreams"
@@ -20,10 +16,13 @@ import (
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/internal/cli/internal/app"
 	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/command/image"
+	metricscmd "github.com/superfly/flyctl/internal/cli/internal/command/metrics"
 	"github.com/superfly/flyctl/internal/cli/internal/config"
 	"github.com/superfly/flyctl/internal/cli/internal/flag"
 	"github.com/superfly/flyctl/internal/cli/internal/render"
 	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/logger"
 )
 
 func New() (cmd *cobra.Command) {
@@ -31,6 +30,19 @@ func New() (cmd *cobra.Command) {
 		long = `Show the application's current status including application
 details, tasks, most recent deployment details and in which regions it is
 currently allocated.
+
+With --watch, a dashboard of the health of the app's instances, by region,
+and of its latest release is shown instead, refreshing every --rate seconds.
+--metrics adds a panel of the app's metrics over the last 15 minutes to it.
+Its keys are:
+
+  up/down, k/j  select an instance
+  enter, l      show the recent logs of the selected instance
+  c             show the health checks of the selected instance
+  v             show the VM and recent events of the selected instance
+  esc           go back to the overview
+  r             refresh right away
+  q, ctrl+c     quit
 `
 		short = "Show app status"
 	)
@@ -41,6 +53,10 @@ currently allocated.
 	)
 
 	cmd.Args = cobra.NoArgs
+	cmd.Example = `flyctl status -a $APP
+flyctl status --all -a $APP
+flyctl status --watch --rate 2 -a $APP
+flyctl status --watch --metrics -a $APP`
 
 	flag.Add(cmd,
 		flag.App(),
@@ -55,13 +71,25 @@ currently allocated.
 		},
 		flag.Bool{
 			Name:        "watch",
-			Description: "Refresh details",
+			Description: "Show a live dashboard of the app which refreshes its details",
 		},
 		flag.Int{
 			Name:        "rate",
 			Description: "Refresh Rate for --watch",
 			Default:     5,
 		},
+		flag.Bool{
+			Name:        "metrics",
+			Description: "Show a panel of the app's CPU, memory and HTTP response metrics on the --watch dashboard",
+		},
+		flag.Bool{
+			Name:        "timeline",
+			Description: "Show a timeline of the most recent releases",
+		},
+		flag.Bool{
+			Name:        "all-releases",
+			Description: "Include all releases in the --timeline instead of the most recent ones",
+		},
 	)
 
 	cmd.AddCommand(
@@ -73,8 +101,20 @@ currently allocated.
 
 func run(ctx context.Context) error {
 	watch := flag.GetBool(ctx, "watch")
-	if watch && config.FromContext(ctx).JSONOutput {
-		return errors.New("--watch and --json are not supported together")
+	if watch && config.FromContext(ctx).StructuredOutput() {
+		return errors.New("--watch is not supported together with --json or --format")
+	}
+
+	if flag.GetBool(ctx, "timeline") {
+		if watch {
+			return errors.New("--watch and --timeline are not supported together")
+		}
+
+		return runTimeline(ctx)
+	}
+
+	if flag.GetBool(ctx, "metrics") && !watch {
+		return errors.New("--metrics is only supported together with --watch")
 	}
 
 	if !watch {
@@ -93,7 +133,7 @@ func once(ctx context.Context, out io.Writer) (err error) {
 		appName    = app.NameFromContext(ctx)
 		all        = flag.GetBool(ctx, "all")
 		client     = client.FromContext(ctx).API()
-		jsonOutput = config.FromContext(ctx).JSONOutput
+		jsonOutput = config.FromContext(ctx).StructuredOutput()
 	)
 
 	var app *api.AppStatus
@@ -111,25 +151,17 @@ func once(ctx context.Context, out io.Writer) (err error) {
 	}
 
 	if jsonOutput {
-		err = render.JSON(out, app)
+		err = render.Structured(ctx, out, app)
 
 		return
 	}
 
-	obj := [][]string{
-		{
-			app.Name,
-			app.Organization.Slug,
-			strconv.Itoa(app.Version),
-			app.Status,
-			app.Hostname,
-		},
-	}
-
-	if err = render.VerticalTable(out, "App", obj, "Name", "Owner", "Version", "Status", "Hostname"); err != nil {
+	if err = renderApp(out, app); err != nil {
 		return
 	}
 
+	renderImageUpdate(ctx, out, appName)
+
 	if !app.Deployed {
 		_, err = fmt.Fprintln(out, "App has not been deployed yet.")
 
@@ -145,11 +177,87 @@ func once(ctx context.Context, out io.Writer) (err error) {
 		}
 	}
 
-	err = render.AllocationStatuses(out, "Instances", backupRegions, app.Allocations...)
+	// a lone process group is sized like the app, so only list several
+	if len(app.ProcessGroups) < 2 {
+		err = render.AllocationStatuses(out, "Instances", backupRegions, app.Allocations...)
+
+		return
+	}
+
+	if err = renderProcessGroups(out, app); err != nil {
+		return
+	}
+
+	byGroup := allocationsByGroup(app.Allocations)
+	for _, pg := range app.ProcessGroups {
+		if len(byGroup[pg.Name]) == 0 {
+			continue
+		}
+
+		title := fmt.Sprintf("Instances (%s)", pg.Name)
+		if err = render.AllocationStatuses(out, title, backupRegions, byGroup[pg.Name]...); err != nil {
+			return
+		}
+		delete(byGroup, pg.Name)
+	}
+
+	// instances of groups the app no longer runs, e.g. while they're stopped
+	var rest []*api.AllocationStatus
+	for _, alloc := range app.Allocations {
+		if _, ok := byGroup[alloc.TaskName]; ok {
+			rest = append(rest, alloc)
+		}
+	}
+
+	if len(rest) > 0 {
+		err = render.AllocationStatuses(out, "Instances (other)", backupRegions, rest...)
+	}
 
 	return
 }
 
+// allocationsByGroup maps the names of process groups to their allocations.
+func allocationsByGroup(allocs []*api.AllocationStatus) map[string][]*api.AllocationStatus {
+	byGroup := map[string][]*api.AllocationStatus{}
+	for _, alloc := range allocs {
+		byGroup[alloc.TaskName] = append(byGroup[alloc.TaskName], alloc)
+	}
+
+	return byGroup
+}
+
+func renderApp(w io.Writer, app *api.AppStatus) error {
+	obj := [][]string{
+		{
+			app.Name,
+			app.Organization.Slug,
+			strconv.Itoa(app.Version),
+			app.Status,
+			app.Hostname,
+		},
+	}
+
+	return render.VerticalTable(w, "App", obj, "Name", "Owner", "Version", "Status", "Hostname")
+}
+
+// renderImageUpdate tells when the app runs an outdated version of an image
+// the platform tracks the versions of, such as the ones Fly provides for
+// Postgres and Redis. Failing to tell isn't worth failing the command over.
+func renderImageUpdate(ctx context.Context, w io.Writer, appName string) {
+	info, err := client.FromContext(ctx).API().GetImageInfo(ctx, appName)
+	if err != nil {
+		logger.FromContext(ctx).Debugf("failed retrieving image info of %s: %v", appName, err)
+
+		return
+	}
+
+	if message := image.UpdateNotice(info, appName); message != "" {
+		colorize := iostreams.FromContext(ctx).ColorScheme()
+
+		fmt.Fprintln(w, colorize.Yellow(message))
+	}
+}
+
 func renderDeploymentStatus(w io.Writer, ds *api.DeploymentStatus) error {
 	obj := [][]string{
 		{
@@ -171,6 +279,52 @@ func renderDeploymentStatus(w io.Writer, ds *api.DeploymentStatus) error {
 	)
 }
 
+func renderProcessGroups(w io.Writer, app *api.AppStatus) error {
+	desired := make(map[string]int, len(app.TaskGroupCounts))
+	for _, tg := range app.TaskGroupCounts {
+		desired[tg.Name] = tg.Count
+	}
+
+	running := map[string]int{}
+	for _, alloc := range app.Allocations {
+		if alloc.Status == "running" {
+			running[alloc.TaskName]++
+		}
+	}
+
+	rows := make([][]string, 0, len(app.ProcessGroups))
+
+	for _, pg := range app.ProcessGroups {
+		row := []string{pg.Name, strconv.Itoa(desired[pg.Name]), strconv.Itoa(running[pg.Name]), "", "", ""}
+
+		if size := pg.VMSize; size != nil {
+			row[3] = size.Name
+			row[4] = formatCores(size.CPUCores)
+			row[5] = formatMemory(size.MemoryMB)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return render.Table(w, "Process Groups", rows, "Name", "Count", "Running", "VM Size", "CPU Cores", "Memory")
+}
+
+func formatCores(cores float32) string {
+	if cores < 1.0 {
+		return fmt.Sprintf("%.2f", cores)
+	}
+
+	return strconv.Itoa(int(cores))
+}
+
+func formatMemory(mb int) string {
+	if mb < 1024 {
+		return fmt.Sprintf("%d MB", mb)
+	}
+
+	return fmt.Sprintf("%d GB", mb/1024)
+}
+
 func runWatch(ctx context.Context) (err error) {
 	streams := iostreams.FromContext(ctx)
 	if !streams.IsInteractive() {
@@ -178,7 +332,6 @@ func runWatch(ctx context.Context) (err error) {
 
 		return
 	}
-	colorize := streams.ColorScheme()
 
 	sleep := flag.GetInt(ctx, "rate")
 	if sleep < 1 || sleep > 3600 {
@@ -186,30 +339,49 @@ func runWatch(ctx context.Context) (err error) {
 
 		return
 	}
+	rate := time.Duration(sleep) * time.Second
 
-	appName := app.NameFromContext(ctx)
-
-	var buf bytes.Buffer
-
-	for err == nil {
-		buf.Reset()
+	d := &dashboard{
+		client:  client.FromContext(ctx).API(),
+		appName: app.NameFromContext(ctx),
+		all:     flag.GetBool(ctx, "all"),
+	}
 
-		if err = once(ctx, &buf); err != nil {
-			break
+	if flag.GetBool(ctx, "metrics") {
+		if d.metricsClient, err = metricscmd.NewClient(ctx, d.appName); err != nil {
+			return
 		}
+	}
+
+	// failing to fetch the status to begin with is fatal; failing to refresh
+	// it is reported on the dashboard
+	if err = d.refresh(ctx); err != nil {
+		return
+	}
 
-		header := fmt.Sprintf("%s %s %s\n\n", colorize.Bold(appName), "at:", colorize.Bold(time.Now().UTC().Format("15:04:05")))
+	screen, err := render.NewScreen(ctx)
+	if err != nil {
+		return
+	}
+	defer screen.Close()
 
-		screen.Clear()
-		screen.MoveTopLeft()
+	ticker := time.NewTicker(rate)
+	defer ticker.Stop()
 
-		io.Copy(streams.Out, io.MultiReader(
-			strings.NewReader(header),
-			&buf,
-		))
+	colorize := streams.ColorScheme()
 
-		pause.For(ctx, time.Duration(sleep)*time.Second)
+	for {
+		screen.Draw(d.frame(colorize, rate))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			_ = d.refresh(ctx)
+		case key := <-screen.Keys():
+			if d.handle(ctx, key) {
+				return nil
+			}
+		}
 	}
-
-	return
 }
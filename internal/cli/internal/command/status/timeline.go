@@ -0,0 +1,222 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+const (
+	recentReleasesLimit = 25
+
+	timelineBarWidth = 40
+)
+
+type timelineEntry struct {
+	Version     int           `json:"version"`
+	Status      string        `json:"status"`
+	Reason      string        `json:"reason"`
+	Description string        `json:"description"`
+	User        string        `json:"user"`
+	StartedAt   time.Time     `json:"started_at"`
+	DeployedAt  *time.Time    `json:"deployed_at,omitempty"`
+	EndedAt     time.Time     `json:"ended_at"`
+	Duration    time.Duration `json:"duration_ns"`
+	Current     bool          `json:"current"`
+	Incident    string        `json:"incident,omitempty"`
+}
+
+func runTimeline(ctx context.Context) error {
+	var (
+		appName = app.NameFromContext(ctx)
+		client  = client.FromContext(ctx).API()
+		out     = iostreams.FromContext(ctx).Out
+	)
+
+	var (
+		releases []api.Release
+		err      error
+	)
+	if flag.GetBool(ctx, "all-releases") {
+		releases, err = client.GetAllAppReleases(ctx, appName)
+	} else {
+		releases, err = client.GetAppReleases(ctx, appName, recentReleasesLimit)
+	}
+	if err != nil {
+		return fmt.Errorf("failed retrieving releases of %s: %w", appName, err)
+	}
+
+	status, err := client.GetAppStatus(ctx, appName, false)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	entries := buildTimeline(releases, status.DeploymentStatus, time.Now())
+
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, out, entries)
+	}
+
+	if len(entries) == 0 {
+		_, err = fmt.Fprintln(out, "App has no releases yet.")
+
+		return err
+	}
+
+	return renderTimeline(out, iostreams.FromContext(ctx).ColorScheme(), entries)
+}
+
+// buildTimeline returns the timeline of the given releases ordered from oldest
+// to newest. The duration of each release is the time its deployment took,
+// from its creation until it completed or, for releases still in progress,
+// until now. Each release is considered live until the one following it got
+// created; the latest release is considered live until now.
+func buildTimeline(releases []api.Release, ds *api.DeploymentStatus, now time.Time) []timelineEntry {
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].Version < releases[j].Version
+	})
+
+	entries := make([]timelineEntry, 0, len(releases))
+	for i, release := range releases {
+		end := now
+		if i < len(releases)-1 {
+			end = releases[i+1].CreatedAt
+		}
+
+		user := release.User.Email
+		if user == "" {
+			user = release.User.Name
+		}
+
+		deployed := deployedAt(release, now)
+
+		var duration time.Duration
+		if deployed != nil {
+			duration = deployed.Sub(release.CreatedAt)
+		}
+
+		entry := timelineEntry{
+			Version:     release.Version,
+			Status:      release.Status,
+			Reason:      release.Reason,
+			Description: release.Description,
+			User:        user,
+			StartedAt:   release.CreatedAt,
+			DeployedAt:  deployed,
+			EndedAt:     end,
+			Duration:    duration,
+			Current:     i == len(releases)-1,
+			Incident:    releaseIncident(release, ds),
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// deployedAt returns the time the deployment of release completed at, now for
+// releases still in progress or nil in case it's unknown.
+func deployedAt(release api.Release, now time.Time) *time.Time {
+	switch {
+	case release.InProgress:
+		return &now
+	case release.UpdatedAt.IsZero(), release.UpdatedAt.Before(release.CreatedAt):
+		return nil
+	default:
+		return &release.UpdatedAt
+	}
+}
+
+// releaseIncident returns a short description of what went wrong with the
+// given release or an empty string in case nothing did.
+func releaseIncident(release api.Release, ds *api.DeploymentStatus) string {
+	switch release.Status {
+	case "failed", "cancelled", "interrupted":
+		return "deployment " + release.Status
+	}
+
+	if ds != nil && ds.Version == release.Version && ds.UnhealthyCount > 0 {
+		return fmt.Sprintf("%d unhealthy instances", ds.UnhealthyCount)
+	}
+
+	return ""
+}
+
+func renderTimeline(w io.Writer, cs *iostreams.ColorScheme, entries []timelineEntry) error {
+	start := entries[0].StartedAt
+	span := entries[len(entries)-1].EndedAt.Sub(start)
+	if span <= 0 {
+		span = time.Second
+	}
+
+	rows := make([][]string, 0, len(entries))
+	for _, e := range entries {
+		offset := int(int64(timelineBarWidth) * int64(e.StartedAt.Sub(start)) / int64(span))
+		width := int(int64(timelineBarWidth) * int64(e.EndedAt.Sub(e.StartedAt)) / int64(span))
+		if width < 1 {
+			width = 1
+		}
+		if offset+width > timelineBarWidth {
+			offset = timelineBarWidth - width
+		}
+
+		bar := strings.Repeat("█", width)
+		switch {
+		case e.Incident != "":
+			bar = cs.Red(bar)
+		case e.Current:
+			bar = cs.Green(bar)
+		}
+
+		version := fmt.Sprintf("v%d", e.Version)
+		if e.Current {
+			version += "*"
+		}
+
+		rows = append(rows, []string{
+			version,
+			e.StartedAt.Format(time.RFC3339),
+			formatDuration(e.Duration),
+			e.User,
+			e.Status,
+			e.Incident,
+			"|" + strings.Repeat(" ", offset) + bar + strings.Repeat(" ", timelineBarWidth-offset-width) + "|",
+		})
+	}
+
+	return render.Table(w, "Releases Timeline", rows,
+		"Version",
+		"Started",
+		"Duration",
+		"User",
+		"Status",
+		"Incident",
+		"Timeline",
+	)
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d < time.Minute:
+		return d.Round(time.Second).String()
+	case d < 24*time.Hour:
+		return d.Round(time.Minute).String()
+	default:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
@@ -0,0 +1,64 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	at := func(min int) time.Time {
+		return time.Date(2022, 3, 1, 12, min, 0, 0, time.UTC)
+	}
+
+	releases := []api.Release{
+		{Version: 3, Status: "running", InProgress: true, CreatedAt: at(30), User: api.User{Name: "bob"}},
+		{Version: 1, Status: "succeeded", CreatedAt: at(0), UpdatedAt: at(2), User: api.User{Email: "a@example.com", Name: "alice"}},
+		{Version: 2, Status: "failed", CreatedAt: at(10), UpdatedAt: at(15)},
+	}
+	ds := &api.DeploymentStatus{Version: 3, UnhealthyCount: 1}
+
+	entries := buildTimeline(releases, ds, at(40))
+	require.Len(t, entries, 3)
+
+	// durations span from creation to deployment completion
+	first := entries[0]
+	assert.Equal(t, 1, first.Version)
+	assert.Equal(t, "a@example.com", first.User)
+	require.NotNil(t, first.DeployedAt)
+	assert.Equal(t, at(2), *first.DeployedAt)
+	assert.Equal(t, 2*time.Minute, first.Duration)
+	assert.Equal(t, at(10), first.EndedAt)
+	assert.False(t, first.Current)
+	assert.Empty(t, first.Incident)
+
+	failed := entries[1]
+	assert.Equal(t, 5*time.Minute, failed.Duration)
+	assert.Equal(t, at(30), failed.EndedAt)
+	assert.Equal(t, "deployment failed", failed.Incident)
+
+	// releases in progress are still deploying as of now
+	current := entries[2]
+	assert.Equal(t, "bob", current.User)
+	assert.Equal(t, 10*time.Minute, current.Duration)
+	assert.Equal(t, at(40), current.EndedAt)
+	assert.True(t, current.Current)
+	assert.Equal(t, "1 unhealthy instances", current.Incident)
+}
+
+func TestBuildTimelineUnknownCompletion(t *testing.T) {
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	entries := buildTimeline([]api.Release{
+		{Version: 1, Status: "succeeded", CreatedAt: now.Add(-time.Hour)},
+	}, nil, now)
+	require.Len(t, entries, 1)
+
+	assert.Nil(t, entries[0].DeployedAt)
+	assert.Zero(t, entries[0].Duration)
+	assert.Equal(t, "-", formatDuration(entries[0].Duration))
+}
@@ -0,0 +1,170 @@
+// Package tokens implements the tokens command chain.
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/cmdutil"
+)
+
+// DeployScope denotes the scope of tokens which may only build, push and
+// deploy the images of one app.
+const DeployScope = "deploy"
+
+// New initializes and returns a new tokens Command.
+func New() *cobra.Command {
+	const (
+		long = `Commands that manage access tokens which are restricted to a scope, as opposed
+to personal access tokens, which may do anything their user may.
+`
+		short = "Manage scoped access tokens"
+	)
+
+	cmd := command.New("tokens", short, long, nil)
+
+	cmd.AddCommand(
+		newCreate(),
+	)
+
+	return cmd
+}
+
+func newCreate() *cobra.Command {
+	const (
+		long = `Create an access token restricted to the given scope and print it.
+
+Tokens of the deploy scope may only build, push and deploy the images of the
+given app, which is what CI pipelines need, and expire after --expiry. Deploys
+use them as FLY_API_TOKEN, for the API as well as for the registry:
+
+  FLY_API_TOKEN="$(fly tokens create --scope deploy --app myapp)" fly deploy
+
+Scoped tokens carry their FlyV1 authorization scheme, which is part of the
+token and has to be kept along with it.
+`
+		short = "Create a scoped access token"
+	)
+
+	cmd := command.New("create", short, long, runCreate,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.NoArgs
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.String{
+			Name:        "scope",
+			Description: "Scope of the token; only deploy is supported",
+			Default:     DeployScope,
+		},
+		flag.String{
+			Name:        "expiry",
+			Description: "How long the token is valid for, e.g. 90d or 720h",
+			Default:     "90d",
+		},
+		flag.String{
+			Name:        "name",
+			Description: "Name of the token; defaults to one denoting its scope and app",
+		},
+	)
+
+	cmd.Example = `fly tokens create --scope deploy --app myapp --expiry 90d`
+
+	return cmd
+}
+
+// createdToken is the structured output of create.
+type createdToken struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Scope     string    `json:"scope"`
+	App       string    `json:"app"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Token     string    `json:"token"`
+}
+
+func runCreate(ctx context.Context) error {
+	if scope := flag.GetString(ctx, "scope"); scope != DeployScope {
+		return fmt.Errorf("unsupported scope %q; only %s is supported", scope, DeployScope)
+	}
+
+	expiry, err := parseExpiry(flag.GetString(ctx, "expiry"))
+	if err != nil {
+		return err
+	}
+
+	var (
+		appName   = app.NameFromContext(ctx)
+		apiClient = client.FromContext(ctx).API()
+	)
+
+	appCompact, err := apiClient.GetAppCompact(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app %s: %w", appName, err)
+	}
+
+	name := flag.GetString(ctx, "name")
+	if name == "" {
+		name = fmt.Sprintf("flyctl %s token for %s", DeployScope, appName)
+	}
+
+	token, err := apiClient.CreateLimitedAccessToken(ctx, api.CreateLimitedAccessTokenInput{
+		Name:           name,
+		OrganizationID: appCompact.Organization.ID,
+		Profile:        DeployScope,
+		ProfileParams: map[string]interface{}{
+			"app_id": appCompact.ID,
+		},
+		Expiry: fmt.Sprintf("%dh", int(expiry.Hours())),
+	})
+	if err != nil {
+		return fmt.Errorf("failed creating token: %w", err)
+	}
+
+	io := iostreams.FromContext(ctx)
+
+	if config.FromContext(ctx).StructuredOutput() {
+		return render.Structured(ctx, io.Out, createdToken{
+			ID:        token.ID,
+			Name:      token.Name,
+			Scope:     DeployScope,
+			App:       appName,
+			ExpiresAt: token.ExpiresAt,
+			Token:     token.TokenHeader,
+		})
+	}
+
+	// only the token goes to stdout, so that it may be captured
+	fmt.Fprintf(io.ErrOut, "Created %s token %q for %s, valid until %s\n",
+		DeployScope, token.Name, appName, token.ExpiresAt.Format(time.RFC3339))
+	fmt.Fprintln(io.Out, token.TokenHeader)
+
+	return nil
+}
+
+// parseExpiry parses the given number of days or duration, which the platform
+// takes in hours.
+func parseExpiry(s string) (time.Duration, error) {
+	days, err := cmdutil.ParseDays(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --expiry %q; %w", s, err)
+	}
+
+	return time.Duration(days) * 24 * time.Hour, nil
+}
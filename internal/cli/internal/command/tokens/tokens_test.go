@@ -0,0 +1,22 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpiry(t *testing.T) {
+	d, err := parseExpiry("90d")
+	require.NoError(t, err)
+	assert.Equal(t, 90*24*time.Hour, d)
+
+	d, err = parseExpiry("36h")
+	require.NoError(t, err)
+	assert.Equal(t, 2*24*time.Hour, d, "durations round up to days")
+
+	_, err = parseExpiry("soon")
+	assert.EqualError(t, err, `invalid --expiry "soon"; use a number of days or a duration, e.g. 90d or 720h`)
+}
@@ -0,0 +1,121 @@
+package trace
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/pkg/logs"
+)
+
+// Event is a log entry which concerns the traced request.
+type Event struct {
+	Time     time.Time `json:"time"`
+	Region   string    `json:"region"`
+	Instance string    `json:"instance,omitempty"`
+	// Source is where the entry originated: "edge" for the proxy which
+	// accepted the request, "proxy" for the decisions the proxy made about
+	// specific instances and "app" for the entries of the app itself.
+	Source  string `json:"source"`
+	Message string `json:"message"`
+	Status  int    `json:"status,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Timeline is the events of a request, in the order they happened.
+type Timeline struct {
+	RequestID string   `json:"request_id"`
+	Events    []Event  `json:"events"`
+	Regions   []string `json:"regions"`
+	Statuses  []int    `json:"statuses"`
+	Errors    []string `json:"errors"`
+}
+
+// matches reports whether entry concerns the request identified by id;
+// either because the proxy tagged it with the id or because the app logged
+// it.
+func matches(entry logs.LogEntry, id string) bool {
+	return entry.Meta.HTTP.Request.ID == id || strings.Contains(entry.Message, id)
+}
+
+func newEvent(entry logs.LogEntry) Event {
+	ev := Event{
+		Region:   firstNonEmpty(entry.Region, entry.Meta.Region),
+		Instance: firstNonEmpty(entry.Instance, entry.Meta.Instance),
+		Message:  entry.Message,
+		Status:   entry.Meta.HTTP.Response.StatusCode,
+		Error:    entry.Meta.Error.Message,
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err == nil {
+		ev.Time = t
+	}
+
+	switch provider := entry.Meta.Event.Provider; {
+	case provider == "proxy" && ev.Instance == "":
+		ev.Source = "edge"
+	case provider == "":
+		ev.Source = "app"
+	default:
+		ev.Source = provider
+	}
+
+	return ev
+}
+
+// buildTimeline correlates the entries which concern the request identified
+// by id.
+func buildTimeline(id string, entries []logs.LogEntry) *Timeline {
+	tl := &Timeline{
+		RequestID: id,
+		Events:    []Event{},
+		Regions:   []string{},
+		Statuses:  []int{},
+		Errors:    []string{},
+	}
+
+	for _, entry := range entries {
+		if matches(entry, id) {
+			tl.Events = append(tl.Events, newEvent(entry))
+		}
+	}
+
+	sort.SliceStable(tl.Events, func(i, j int) bool {
+		return tl.Events[i].Time.Before(tl.Events[j].Time)
+	})
+
+	var (
+		regions  = map[string]bool{}
+		statuses = map[int]bool{}
+		errors   = map[string]bool{}
+	)
+
+	for _, ev := range tl.Events {
+		if ev.Region != "" && !regions[ev.Region] {
+			regions[ev.Region] = true
+			tl.Regions = append(tl.Regions, ev.Region)
+		}
+
+		if ev.Status != 0 && !statuses[ev.Status] {
+			statuses[ev.Status] = true
+			tl.Statuses = append(tl.Statuses, ev.Status)
+		}
+
+		if ev.Error != "" && !errors[ev.Error] {
+			errors[ev.Error] = true
+			tl.Errors = append(tl.Errors, ev.Error)
+		}
+	}
+
+	return tl
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
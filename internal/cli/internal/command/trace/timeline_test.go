@@ -0,0 +1,68 @@
+package trace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/pkg/logs"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	const id = "01GB2Q5ETQ9F8K7ZJ3X4RGW0AB-ord"
+
+	edge := logs.LogEntry{
+		Region:    "ord",
+		Timestamp: "2022-08-01T10:00:00.100Z",
+		Message:   "request received",
+	}
+	edge.Meta.Event.Provider = "proxy"
+	edge.Meta.HTTP.Request.ID = id
+	edge.Meta.HTTP.Response.StatusCode = 502
+
+	decision := logs.LogEntry{
+		Region:    "iad",
+		Instance:  "abcd1234",
+		Timestamp: "2022-08-01T10:00:00.050Z",
+		Message:   "could not proxy request",
+	}
+	decision.Meta.Event.Provider = "proxy"
+	decision.Meta.HTTP.Request.ID = id
+	decision.Meta.Error.Message = "connection refused"
+
+	appEntry := logs.LogEntry{
+		Region:    "iad",
+		Instance:  "abcd1234",
+		Timestamp: "2022-08-01T10:00:00.010Z",
+		Message:   "panic handling " + id,
+	}
+	appEntry.Meta.Event.Provider = "app"
+
+	other := logs.LogEntry{
+		Region:    "iad",
+		Timestamp: "2022-08-01T10:00:00.020Z",
+		Message:   "unrelated",
+	}
+
+	tl := buildTimeline(id, []logs.LogEntry{edge, decision, other, appEntry})
+
+	require.Len(t, tl.Events, 3)
+	assert.Equal(t, "app", tl.Events[0].Source)
+	assert.Equal(t, "proxy", tl.Events[1].Source)
+	assert.Equal(t, "edge", tl.Events[2].Source)
+
+	assert.Equal(t, time.Date(2022, 8, 1, 10, 0, 0, 10e6, time.UTC), tl.Events[0].Time)
+
+	assert.Equal(t, []string{"iad", "ord"}, tl.Regions)
+	assert.Equal(t, []int{502}, tl.Statuses)
+	assert.Equal(t, []string{"connection refused"}, tl.Errors)
+}
+
+func TestBuildTimelineEmpty(t *testing.T) {
+	tl := buildTimeline("nope", []logs.LogEntry{{Message: "hello"}})
+
+	assert.Empty(t, tl.Events)
+	assert.NotNil(t, tl.Events, "renders as an empty JSON array")
+}
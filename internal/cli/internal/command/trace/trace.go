@@ -0,0 +1,185 @@
+// Package trace implements the trace command.
+package trace
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azazeal/pause"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+	"github.com/superfly/flyctl/pkg/logs"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+// New initializes and returns a new trace Command.
+func New() (cmd *cobra.Command) {
+	const (
+		long = `Trace a request through the Fly platform by its fly-request-id. The logs
+of the edge proxy which accepted the request, the decisions the proxy made
+about the instances to route it to and the entries the app logged for it are
+correlated across regions and shown as a single timeline.
+
+The request ID is in the fly-request-id header of every response, including
+the ones for 502s. Entries are looked up in the recent logs of the app; use
+--wait to keep watching for entries of requests which are still in flight.
+`
+		short = "Trace a request through the edge, the proxy and the app"
+		usage = "trace <request-id>"
+	)
+
+	cmd = command.New(usage, short, long, run,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+	cmd.Example = `flyctl trace 01GB2Q5ETQ9F8K7ZJ3X4RGW0AB-ord -a $APP
+flyctl trace 01GB2Q5ETQ9F8K7ZJ3X4RGW0AB-ord --wait 30 -a $APP`
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Region(),
+		flag.Int{
+			Name:        "wait",
+			Description: "Seconds to keep watching the logs for further entries of the request",
+		},
+	)
+
+	return
+}
+
+func run(ctx context.Context) error {
+	var (
+		id      = flag.FirstArg(ctx)
+		appName = app.NameFromContext(ctx)
+		client  = client.FromContext(ctx).API()
+		cfg     = config.FromContext(ctx)
+		out     = iostreams.FromContext(ctx).Out
+	)
+
+	opts := &logs.LogOptions{
+		AppName:    appName,
+		RegionCode: cfg.Region,
+	}
+
+	entries, err := collect(ctx, client, opts, time.Duration(flag.GetInt(ctx, "wait"))*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed retrieving logs of %s: %w", appName, err)
+	}
+
+	tl := buildTimeline(id, entries)
+
+	if cfg.JSONOutput {
+		return render.JSON(out, tl)
+	}
+
+	if len(tl.Events) == 0 {
+		fmt.Fprintf(out, "No log entries found for request %s in the recent logs of %s.\n", id, appName)
+
+		return nil
+	}
+
+	rows := make([][]string, 0, len(tl.Events))
+	for _, ev := range tl.Events {
+		message := ev.Message
+		if ev.Error != "" && !strings.Contains(message, ev.Error) {
+			message += " (" + ev.Error + ")"
+		}
+
+		var status string
+		if ev.Status != 0 {
+			status = strconv.Itoa(ev.Status)
+		}
+
+		rows = append(rows, []string{
+			ev.Time.Format("15:04:05.000"),
+			ev.Region,
+			ev.Source,
+			ev.Instance,
+			status,
+			message,
+		})
+	}
+
+	if err := render.Table(out, "Request "+id, rows, "Time", "Region", "Source", "Instance", "Status", "Message"); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Regions: %s\n", strings.Join(tl.Regions, " -> "))
+
+	if len(tl.Statuses) > 0 {
+		statuses := make([]string, 0, len(tl.Statuses))
+		for _, s := range tl.Statuses {
+			statuses = append(statuses, strconv.Itoa(s))
+		}
+		fmt.Fprintf(out, "Responses: %s\n", strings.Join(statuses, ", "))
+	}
+
+	for _, e := range tl.Errors {
+		fmt.Fprintf(out, "Error: %s\n", e)
+	}
+
+	return nil
+}
+
+// collect pages through the recent logs the options denote and, for wait,
+// keeps polling for new entries.
+func collect(ctx context.Context, client *api.Client, opts *logs.LogOptions, wait time.Duration) (entries []logs.LogEntry, err error) {
+	const (
+		pollInterval = 2 * time.Second
+		// maxPages bounds the backlog of busy apps
+		maxPages = 50
+	)
+
+	deadline := time.Now().Add(wait)
+
+	var token string
+	for pages := 1; ; pages++ {
+		page, next, err := client.GetAppLogs(ctx, opts.AppName, token, opts.RegionCode, opts.VMID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range page {
+			entries = append(entries, logs.LogEntry{
+				Instance:  e.Instance,
+				Level:     e.Level,
+				Message:   e.Message,
+				Region:    e.Region,
+				Timestamp: e.Timestamp,
+				Meta:      e.Meta,
+			})
+		}
+
+		if next != "" {
+			token = next
+		}
+
+		// keep paging while there's a backlog to catch up with
+		if len(page) > 0 && next != "" && pages < maxPages {
+			continue
+		}
+
+		if !time.Now().Before(deadline) {
+			return entries, nil
+		}
+
+		pause.For(ctx, pollInterval)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+}
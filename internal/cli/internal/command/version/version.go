@@ -15,6 +15,7 @@ import (
 	"github.com/superfly/flyctl/internal/cli/internal/command"
 	"github.com/superfly/flyctl/internal/cli/internal/config"
 	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
 )
 
 const saveInstallName = "saveinstall"
@@ -64,9 +65,12 @@ func run(ctx context.Context) (err error) {
 		out  = iostreams.FromContext(ctx).Out
 	)
 
-	if cfg.JSONOutput {
+	switch {
+	case cfg.Format != "":
+		err = render.Template(out, cfg.Format, info)
+	case cfg.JSONOutput:
 		err = json.NewEncoder(out).Encode(info)
-	} else {
+	default:
 		_, err = fmt.Fprintln(out, info)
 	}
 
@@ -11,6 +11,7 @@ import (
 
 	"github.com/superfly/flyctl/internal/cli/internal/app"
 	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/command/volumes/snapshots"
 	"github.com/superfly/flyctl/internal/cli/internal/config"
 	"github.com/superfly/flyctl/internal/cli/internal/flag"
 	"github.com/superfly/flyctl/internal/cli/internal/prompt"
@@ -35,6 +36,7 @@ sets the size as the number of gigabytes the volume will consume.`
 	)
 
 	cmd.Args = cobra.ExactArgs(1)
+	cmd.Example = `flyctl volumes create data --region ord --size 10 -a $APP`
 
 	flag.Add(cmd,
 		flag.App(),
@@ -56,6 +58,7 @@ sets the size as the number of gigabytes the volume will consume.`
 			Description: "Require volume to be placed in separate hardware zone from existing volumes",
 			Default:     true,
 		},
+		snapshots.RetentionFlag(),
 	)
 
 	return cmd
@@ -70,6 +73,11 @@ func runCreate(ctx context.Context) error {
 		appName    = app.NameFromContext(ctx)
 	)
 
+	retention, err := snapshots.Retention(ctx)
+	if err != nil {
+		return err
+	}
+
 	app, err := client.GetApp(ctx, appName)
 	if err != nil {
 		return err
@@ -88,6 +96,7 @@ func runCreate(ctx context.Context) error {
 		SizeGb:            flag.GetInt(ctx, "size"),
 		Encrypted:         flag.GetBool(ctx, "encrypted"),
 		RequireUniqueZone: flag.GetBool(ctx, "require-unique-zone"),
+		SnapshotRetention: retention,
 	}
 
 	volume, err := client.CreateVolume(ctx, input)
@@ -97,8 +106,8 @@ func runCreate(ctx context.Context) error {
 
 	out := iostreams.FromContext(ctx).Out
 
-	if cfg.JSONOutput {
-		return render.JSON(out, volume)
+	if cfg.StructuredOutput() {
+		return render.Structured(ctx, out, volume)
 	}
 
 	return printVolume(out, volume)
@@ -51,8 +51,8 @@ func runList(ctx context.Context) error {
 
 	out := iostreams.FromContext(ctx).Out
 
-	if cfg.JSONOutput {
-		return render.JSON(out, volumes)
+	if cfg.StructuredOutput() {
+		return render.Structured(ctx, out, volumes)
 	}
 
 	rows := make([][]string, 0, len(volumes))
@@ -0,0 +1,612 @@
+package volumes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/prompt"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/cli/internal/state"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+const (
+	migrationPollInterval   = 5 * time.Second
+	snapshotTimeout         = 30 * time.Minute
+	machineStartTimeout     = 5 * time.Minute
+	migrationDrainTimeout   = 30
+	migrationsDirectoryName = "volume-migrations"
+)
+
+func newMigrate() *cobra.Command {
+	const (
+		long = `Migrate the data of a volume to a new volume, which may be bigger or in
+another region, and move the machine using it over.
+
+The migration stops the machine the volume is attached to, so that the snapshot
+holds all of its writes, snapshots the volume, restores the snapshot into the
+new volume and launches a replacement of the machine with the new volume
+mounted. The machine is unavailable from the moment it stops until its
+replacement starts; you are asked to confirm before it stops.
+
+For apps which don't run on machines, the migration moves the placement of the
+app over instead: the target region joins the region pool of the app and, in
+case no other volume of the same name remains in the old region, the old
+region leaves it, so that the allocation is rescheduled onto the new volume.
+The allocation keeps running while the volume is snapshotted, so writes it
+makes after the snapshot are lost; you are asked to confirm before it moves.
+
+Progress is checkpointed, so that an interrupted migration resumes where it
+left off when the command runs again with the same volume. The old volume and
+machine are kept so that the migration may be rolled back; delete them once
+the new ones prove healthy.
+`
+		short = "Migrate a volume to a new volume"
+	)
+
+	cmd := command.New("migrate <id>", short, long, runMigrate,
+		command.RequireSession,
+		command.RequireAppName,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+	cmd.Example = `flyctl volumes migrate vol_1234 --to-region fra
+flyctl volumes migrate vol_1234 --size 20`
+
+	flag.Add(cmd,
+		flag.App(),
+		flag.AppConfig(),
+		flag.Yes(),
+		flag.String{
+			Name:        "to-region",
+			Description: "The region to migrate the volume to. Defaults to the region of the volume",
+		},
+		flag.String{
+			Name:        "region",
+			Shorthand:   "r",
+			Description: "Alias of --to-region",
+			Hidden:      true,
+		},
+		flag.Int{
+			Name:        "size",
+			Shorthand:   "s",
+			Description: "Size of the new volume in gigabytes. Defaults to the size of the volume",
+		},
+		flag.String{
+			Name:        "snapshot",
+			Description: "ID of an existing snapshot of the volume to restore, instead of taking a new one",
+		},
+	)
+
+	return cmd
+}
+
+// migration tracks the progress of a volume migration. It's checkpointed to
+// disk after every step.
+type migration struct {
+	App        string `json:"app"`
+	Volume     string `json:"volume"`
+	Region     string `json:"region"`
+	SizeGb     int    `json:"size_gb"`
+	Snapshot   string `json:"snapshot,omitempty"`
+	NewVolume  string `json:"new_volume,omitempty"`
+	OldMachine string `json:"old_machine,omitempty"`
+	NewMachine string `json:"new_machine,omitempty"`
+	Stopped    bool   `json:"stopped,omitempty"`
+	Started    bool   `json:"started,omitempty"`
+	Placed     bool   `json:"placed,omitempty"`
+
+	path string // the checkpoint file
+}
+
+func migrationPath(ctx context.Context, volID string) string {
+	return filepath.Join(state.ConfigDirectory(ctx), migrationsDirectoryName, volID+".json")
+}
+
+func loadMigration(path string) (*migration, error) {
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var m migration
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed parsing migration checkpoint %s: %w", path, err)
+	}
+
+	return &m, nil
+}
+
+// checkpoint saves m to the file it was loaded from or planned for.
+func (m *migration) checkpoint() error {
+	if err := m.save(m.path); err != nil {
+		return fmt.Errorf("failed checkpointing migration: %w", err)
+	}
+
+	return nil
+}
+
+func (m *migration) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+func runMigrate(ctx context.Context) (err error) {
+	var (
+		io      = iostreams.FromContext(ctx)
+		client  = client.FromContext(ctx).API()
+		appName = app.NameFromContext(ctx)
+		volID   = flag.FirstArg(ctx)
+		path    = migrationPath(ctx, volID)
+	)
+
+	vol, err := client.GetVolume(ctx, volID)
+	if err != nil {
+		return fmt.Errorf("failed retrieving volume %s: %w", volID, err)
+	}
+
+	if vol.App.Name != appName {
+		return fmt.Errorf("volume %s does not belong to %s", volID, appName)
+	}
+
+	m, err := loadMigration(path)
+	if err != nil {
+		return err
+	}
+
+	if m != nil {
+		fmt.Fprintf(io.ErrOut, "Resuming the migration of %s to %s (%dGB)\n", volID, m.Region, m.SizeGb)
+	} else {
+		if m, err = planMigration(ctx, vol); err != nil {
+			return err
+		}
+
+		if err = confirmMigration(ctx, fmt.Sprintf("Migrate volume %s (%s, %dGB) to a new %dGB volume in %s?",
+			vol.ID, vol.Region, vol.SizeGb, m.SizeGb, m.Region)); err != nil {
+			return err
+		}
+	}
+
+	m.path = path
+
+	steps := []func(context.Context, *api.Volume, *migration) error{
+		stopAttachedMachine,
+		takeSnapshot,
+		restoreSnapshot,
+		movePlacement,
+		launchReplacement,
+		waitForReplacement,
+	}
+
+	for _, step := range steps {
+		if err = step(ctx, vol, m); err != nil {
+			return err
+		}
+
+		if err = m.checkpoint(); err != nil {
+			return err
+		}
+	}
+
+	if err = os.Remove(path); err != nil {
+		return fmt.Errorf("failed removing migration checkpoint: %w", err)
+	}
+
+	tb := render.NewTextBlock(ctx)
+	tb.Donef("Migrated volume %s to %s", vol.ID, m.NewVolume)
+
+	switch {
+	case m.OldMachine != "":
+		tb.Detailf("Machine %s replaced %s, which is stopped", m.NewMachine, m.OldMachine)
+		tb.Detailf("Once %s proves healthy, remove the old machine with 'fly machine remove %s'", m.NewMachine, m.OldMachine)
+	case m.Placed:
+		tb.Detailf("Allocations of %s using %s are placed in %s; see 'fly status' for their progress", m.App, vol.Name, m.Region)
+	default:
+		tb.Detailf("No machine was attached to %s; the next deployment may attach either volume", vol.ID)
+	}
+	tb.Detailf("and the old volume with 'fly volumes delete %s'", vol.ID)
+
+	return nil
+}
+
+func planMigration(ctx context.Context, vol *api.Volume) (*migration, error) {
+	m := &migration{
+		App:      vol.App.Name,
+		Volume:   vol.ID,
+		Region:   flag.GetString(ctx, "to-region"),
+		SizeGb:   flag.GetInt(ctx, "size"),
+		Snapshot: flag.GetString(ctx, "snapshot"),
+	}
+
+	if m.Region == "" {
+		m.Region = flag.GetString(ctx, "region")
+	}
+	if m.Region == "" {
+		m.Region = vol.Region
+	}
+
+	switch {
+	case m.SizeGb == 0:
+		m.SizeGb = vol.SizeGb
+	case m.SizeGb < vol.SizeGb:
+		return nil, fmt.Errorf("volumes can't shrink; the new volume must be at least %dGB", vol.SizeGb)
+	}
+
+	if m.Region == vol.Region && m.SizeGb == vol.SizeGb {
+		return nil, errors.New("the new volume would match the old one; pass a different --to-region or --size")
+	}
+
+	return m, nil
+}
+
+func confirmMigration(ctx context.Context, msg string) error {
+	if flag.GetYes(ctx) {
+		return nil
+	}
+
+	switch confirmed, err := prompt.Confirm(ctx, msg); {
+	case err == nil:
+		if !confirmed {
+			return errMigrationAborted
+		}
+
+		return nil
+	case prompt.IsNonInteractive(err):
+		return prompt.NonInteractiveError("yes flag must be specified when not running interactively")
+	default:
+		return err
+	}
+}
+
+var errMigrationAborted = errors.New("migration aborted; run the command again to resume it")
+
+// stopAttachedMachine stops the machine the volume is attached to, so that
+// nothing writes to the volume after it's snapshotted.
+func stopAttachedMachine(ctx context.Context, vol *api.Volume, m *migration) error {
+	if m.Stopped {
+		return nil
+	}
+
+	client := client.FromContext(ctx).API()
+
+	machines, err := client.ListMachines(ctx, m.App, "")
+	if err != nil {
+		return fmt.Errorf("failed listing machines of %s: %w", m.App, err)
+	}
+
+	old := attachedMachine(machines, vol.ID)
+	if old == nil {
+		return nil // nothing writes to the volume, or it doesn't run on machines
+	}
+	m.OldMachine = old.ID
+
+	if old.State != "stopped" {
+		msg := fmt.Sprintf("Stop machine %s so that the snapshot of %s holds all of its writes? It's unavailable until its replacement starts.", old.ID, vol.ID)
+		if m.Snapshot != "" {
+			msg = fmt.Sprintf("Stop machine %s? Writes to %s since snapshot %s was taken are lost, and the machine is unavailable until its replacement starts.", old.ID, vol.ID, m.Snapshot)
+		}
+
+		if err := confirmMigration(ctx, msg); err != nil {
+			return err
+		}
+
+		tb := render.NewTextBlock(ctx, "Stopping machine ", old.ID)
+
+		if _, err := client.StopMachine(ctx, api.StopMachineInput{
+			AppID:            m.App,
+			ID:               old.ID,
+			DrainTimeoutSecs: migrationDrainTimeout,
+		}); err != nil {
+			return fmt.Errorf("failed stopping machine %s: %w", old.ID, err)
+		}
+
+		tb.Donef("Stopped machine %s", old.ID)
+	}
+
+	m.Stopped = true
+
+	return nil
+}
+
+func takeSnapshot(ctx context.Context, vol *api.Volume, m *migration) error {
+	if m.Snapshot != "" {
+		return nil
+	}
+
+	client := client.FromContext(ctx).API()
+	tb := render.NewTextBlock(ctx, "Snapshotting volume ", vol.ID)
+
+	requested := time.Now().Add(-time.Minute) // allow for clock skew
+
+	if err := client.CreateVolumeSnapshot(ctx, vol.ID); err != nil {
+		return fmt.Errorf("failed snapshotting volume %s: %w", vol.ID, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, snapshotTimeout)
+	defer cancel()
+
+	for {
+		snapshots, err := client.GetVolumeSnapshots(ctx, vol.ID)
+		if err != nil {
+			return fmt.Errorf("failed retrieving snapshots of %s: %w", vol.ID, err)
+		}
+
+		if snapshot := latestSnapshot(snapshots, requested); snapshot != nil {
+			m.Snapshot = snapshot.ID
+			tb.Donef("Created snapshot %s", snapshot.ID)
+
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for the snapshot of %s", vol.ID)
+		case <-time.After(migrationPollInterval):
+		}
+	}
+}
+
+// snapshotComplete is the status of the snapshots which may be restored.
+const snapshotComplete = "complete"
+
+// latestSnapshot returns the latest of the given complete snapshots taken after
+// the given time, or nil in case there's none.
+func latestSnapshot(snapshots []api.Snapshot, after time.Time) (latest *api.Snapshot) {
+	for i := range snapshots {
+		s := &snapshots[i]
+
+		if s.Status != snapshotComplete || !s.CreatedAt.After(after) {
+			continue
+		}
+
+		if latest == nil || s.CreatedAt.After(latest.CreatedAt) {
+			latest = s
+		}
+	}
+
+	return
+}
+
+func restoreSnapshot(ctx context.Context, vol *api.Volume, m *migration) error {
+	if m.NewVolume != "" {
+		return nil
+	}
+
+	client := client.FromContext(ctx).API()
+	tb := render.NewTextBlock(ctx, "Restoring snapshot ", m.Snapshot, " into a new volume")
+
+	app, err := client.GetApp(ctx, m.App)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app %s: %w", m.App, err)
+	}
+
+	newVol, err := client.CreateVolume(ctx, api.CreateVolumeInput{
+		AppID:      app.ID,
+		Name:       vol.Name,
+		Region:     m.Region,
+		SizeGb:     m.SizeGb,
+		Encrypted:  vol.Encrypted,
+		SnapshotID: api.StringPointer(m.Snapshot),
+	})
+	if err != nil {
+		return fmt.Errorf("failed restoring snapshot %s: %w", m.Snapshot, err)
+	}
+
+	m.NewVolume = newVol.ID
+	tb.Donef("Created volume %s in %s", newVol.ID, newVol.Region)
+
+	return nil
+}
+
+// movePlacement moves the allocations of apps which don't run on machines to
+// the region of the new volume by updating the region pool of the app.
+func movePlacement(ctx context.Context, vol *api.Volume, m *migration) error {
+	if m.Placed || m.Region == vol.Region {
+		return nil
+	}
+
+	client := client.FromContext(ctx).API()
+
+	app, err := client.GetAppCompact(ctx, m.App)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app %s: %w", m.App, err)
+	} else if app.PlatformVersion == "machines" {
+		return nil // replacing the machine moves it over
+	}
+
+	pool, _, err := client.ListAppRegions(ctx, m.App)
+	if err != nil {
+		return fmt.Errorf("failed retrieving regions of %s: %w", m.App, err)
+	}
+
+	volumes, err := client.GetVolumes(ctx, m.App)
+	if err != nil {
+		return fmt.Errorf("failed listing volumes of %s: %w", m.App, err)
+	}
+
+	allow, deny := placementChange(pool, volumes, vol, m.Region)
+
+	if len(deny) > 0 {
+		if err := confirmMigration(ctx, fmt.Sprintf("Volume %s is ready. Remove %s from the regions of %s to move its allocation over? Writes to %s since snapshot %s was taken are lost.", m.NewVolume, vol.Region, m.App, vol.ID, m.Snapshot)); err != nil {
+			return err
+		}
+	}
+
+	if len(allow) > 0 || len(deny) > 0 {
+		tb := render.NewTextBlock(ctx, "Moving the placement of ", m.App, " to ", m.Region)
+
+		if _, _, err := client.ConfigureRegions(ctx, api.ConfigureRegionsInput{
+			AppID:        m.App,
+			AllowRegions: allow,
+			DenyRegions:  deny,
+		}); err != nil {
+			return fmt.Errorf("failed updating the regions of %s: %w", m.App, err)
+		}
+
+		tb.Donef("Updated the regions of %s", m.App)
+	}
+
+	m.Placed = true
+
+	return nil
+}
+
+// placementChange returns the regions which should join and leave the given
+// region pool for allocations using volumes named after vol to be placed on
+// the new volume in target rather than on vol.
+func placementChange(pool []api.Region, volumes []api.Volume, vol *api.Volume, target string) (allow, deny []string) {
+	inPool := map[string]bool{}
+	for _, r := range pool {
+		inPool[r.Code] = true
+	}
+
+	if !inPool[target] {
+		allow = append(allow, target)
+	}
+
+	if !inPool[vol.Region] {
+		return
+	}
+
+	for _, other := range volumes {
+		if other.ID != vol.ID && other.Name == vol.Name && other.Region == vol.Region {
+			return // the region keeps serving allocations on other volumes
+		}
+	}
+
+	deny = append(deny, vol.Region)
+
+	return
+}
+
+// attachedMachine returns the machine which mounts the given volume, or nil
+// in case none does.
+func attachedMachine(machines []*api.Machine, volID string) *api.Machine {
+	for _, machine := range machines {
+		if machine.State == "destroyed" {
+			continue
+		}
+
+		for _, mount := range machine.Config.Mounts {
+			if mount.Volume == volID {
+				return machine
+			}
+		}
+	}
+
+	return nil
+}
+
+func launchReplacement(ctx context.Context, vol *api.Volume, m *migration) error {
+	if m.NewMachine != "" {
+		return nil
+	}
+
+	client := client.FromContext(ctx).API()
+
+	machines, err := client.ListMachines(ctx, m.App, "")
+	if err != nil {
+		return fmt.Errorf("failed listing machines of %s: %w", m.App, err)
+	}
+
+	old := attachedMachine(machines, vol.ID)
+	if old == nil {
+		return nil // nothing to move over
+	}
+	m.OldMachine = old.ID
+
+	tb := render.NewTextBlock(ctx, "Launching a replacement of machine ", old.ID)
+
+	cfg := old.Config
+	cfg.Mounts = make([]api.MachineMount, len(old.Config.Mounts))
+	for i, mount := range old.Config.Mounts {
+		if mount.Volume == vol.ID {
+			mount.Volume = m.NewVolume
+			mount.SizeGb = m.SizeGb
+		}
+
+		cfg.Mounts[i] = mount
+	}
+
+	machine, _, err := client.LaunchMachine(ctx, api.LaunchMachineInput{
+		AppID:  m.App,
+		Region: m.Region,
+		Config: &cfg,
+	})
+	if err != nil {
+		return fmt.Errorf("failed launching replacement machine: %w", err)
+	}
+
+	// checkpoint at once so that a failure past this point never leads to a
+	// resumed migration launching a second replacement
+	m.NewMachine = machine.ID
+	if err := m.checkpoint(); err != nil {
+		return err
+	}
+	tb.Donef("Launched machine %s", machine.ID)
+
+	return nil
+}
+
+func waitForReplacement(ctx context.Context, _ *api.Volume, m *migration) error {
+	if m.NewMachine == "" || m.Started {
+		return nil
+	}
+
+	client := client.FromContext(ctx).API()
+	tb := render.NewTextBlock(ctx, "Waiting for machine ", m.NewMachine, " to start")
+
+	ctx, cancel := context.WithTimeout(ctx, machineStartTimeout)
+	defer cancel()
+
+	for {
+		machine, err := client.GetMachine(ctx, m.App, m.NewMachine)
+		if err != nil && ctx.Err() == nil {
+			return fmt.Errorf("failed retrieving machine %s: %w", m.NewMachine, err)
+		}
+
+		if machine != nil {
+			switch machine.State {
+			case "started":
+				m.Started = true
+				tb.Donef("Machine %s started", m.NewMachine)
+
+				return nil
+			case "failed", "destroyed":
+				return fmt.Errorf("replacement machine %s %s; roll back with 'fly machine start %s'", m.NewMachine, machine.State, m.OldMachine)
+			}
+
+			tb.Detailf("Machine %s is %s", m.NewMachine, machine.State)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for machine %s to start; roll back with 'fly machine start %s'", m.NewMachine, m.OldMachine)
+		case <-time.After(migrationPollInterval):
+		}
+	}
+}
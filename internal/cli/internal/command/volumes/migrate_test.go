@@ -0,0 +1,92 @@
+package volumes
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestLatestSnapshot(t *testing.T) {
+	now := time.Now()
+
+	snapshots := []api.Snapshot{
+		{ID: "old", CreatedAt: now.Add(-time.Hour), Status: snapshotComplete},
+		{ID: "new", CreatedAt: now.Add(2 * time.Minute), Status: snapshotComplete},
+		{ID: "newer", CreatedAt: now.Add(3 * time.Minute), Status: snapshotComplete},
+		{ID: "pending", CreatedAt: now.Add(4 * time.Minute), Status: "created"},
+	}
+
+	assert.Equal(t, "newer", latestSnapshot(snapshots, now).ID)
+	assert.Nil(t, latestSnapshot(snapshots, now.Add(time.Hour)))
+	assert.Nil(t, latestSnapshot(snapshots[3:], now))
+}
+
+func TestAttachedMachine(t *testing.T) {
+	machine := func(id, state string, volumes ...string) *api.Machine {
+		m := &api.Machine{ID: id, State: state}
+		for _, v := range volumes {
+			m.Config.Mounts = append(m.Config.Mounts, api.MachineMount{Volume: v})
+		}
+
+		return m
+	}
+
+	machines := []*api.Machine{
+		machine("gone", "destroyed", "vol_1"),
+		machine("other", "started", "vol_2"),
+		machine("db", "started", "vol_3", "vol_1"),
+	}
+
+	assert.Equal(t, "db", attachedMachine(machines, "vol_1").ID)
+	assert.Nil(t, attachedMachine(machines, "vol_4"))
+}
+
+func TestMigrationCheckpoints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), migrationsDirectoryName, "vol_1.json")
+
+	m, err := loadMigration(path)
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	saved := &migration{App: "app", Volume: "vol_1", Region: "ams", SizeGb: 20, Snapshot: "vs_1"}
+	require.NoError(t, saved.save(path))
+
+	m, err = loadMigration(path)
+	require.NoError(t, err)
+	assert.Equal(t, saved, m)
+
+	m.path = path
+	m.NewMachine = "m_1"
+	require.NoError(t, m.checkpoint())
+
+	resumed, err := loadMigration(path)
+	require.NoError(t, err)
+	assert.Equal(t, "m_1", resumed.NewMachine)
+}
+
+func TestPlacementChange(t *testing.T) {
+	vol := &api.Volume{ID: "vol_1", Name: "data", Region: "ord"}
+	pool := []api.Region{{Code: "ord"}, {Code: "iad"}}
+
+	allow, deny := placementChange(pool, []api.Volume{*vol}, vol, "fra")
+	assert.Equal(t, []string{"fra"}, allow)
+	assert.Equal(t, []string{"ord"}, deny)
+
+	allow, deny = placementChange(pool, []api.Volume{*vol}, vol, "iad")
+	assert.Empty(t, allow)
+	assert.Equal(t, []string{"ord"}, deny)
+
+	volumes := []api.Volume{*vol, {ID: "vol_2", Name: "data", Region: "ord"}}
+	allow, deny = placementChange(pool, volumes, vol, "fra")
+	assert.Equal(t, []string{"fra"}, allow)
+	assert.Empty(t, deny)
+
+	volumes = []api.Volume{*vol, {ID: "vol_3", Name: "other", Region: "ord"}}
+	_, deny = placementChange(pool, volumes, vol, "fra")
+	assert.Equal(t, []string{"ord"}, deny)
+}
@@ -44,8 +44,8 @@ func runShow(ctx context.Context) error {
 
 	out := iostreams.FromContext(ctx).Out
 
-	if cfg.JSONOutput {
-		return render.JSON(out, volume)
+	if cfg.StructuredOutput() {
+		return render.Structured(ctx, out, volume)
 	}
 
 	return printVolume(out, volume)
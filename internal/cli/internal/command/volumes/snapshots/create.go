@@ -0,0 +1,49 @@
+package snapshots
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+func newCreate() *cobra.Command {
+	const (
+		long = `Snapshot the specified volume, on top of the daily snapshots taken of it.
+Snapshots are taken asynchronously; they are listed once they're complete.
+`
+		short = "Snapshot a volume"
+
+		usage = "create <volume-id>"
+	)
+
+	cmd := command.New(usage, short, long, runCreate,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	return cmd
+}
+
+func runCreate(ctx context.Context) error {
+	var (
+		io     = iostreams.FromContext(ctx)
+		client = client.FromContext(ctx).API()
+		volID  = flag.FirstArg(ctx)
+	)
+
+	if err := client.CreateVolumeSnapshot(ctx, volID); err != nil {
+		return fmt.Errorf("failed snapshotting volume %s: %w", volID, err)
+	}
+
+	fmt.Fprintf(io.Out, "Snapshotting volume %s; see 'fly volumes snapshots list %s' for its progress\n", volID, volID)
+
+	return nil
+}
@@ -48,8 +48,8 @@ func runList(ctx context.Context) error {
 		return fmt.Errorf("failed retrieving snapshots: %w", err)
 	}
 
-	if cfg.JSONOutput {
-		return render.JSON(io.Out, snapshots)
+	if cfg.StructuredOutput() {
+		return render.Structured(ctx, io.Out, snapshots)
 	}
 
 	if len(snapshots) == 0 {
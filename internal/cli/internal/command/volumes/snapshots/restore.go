@@ -0,0 +1,189 @@
+package snapshots
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/flyerr"
+)
+
+func newRestore() *cobra.Command {
+	const (
+		long = `Restore a snapshot of the specified volume into a new volume of the same app,
+by default the latest snapshot. The new volume is created in the region of the
+snapshotted volume, unless --region says otherwise, and with its name and size,
+unless --name and --size say otherwise.
+
+The snapshotted volume is left untouched; machines and deployments start
+using the new volume once they mount it.
+`
+		short = "Restore a snapshot into a new volume"
+
+		usage = "restore <volume-id> [snapshot-id]"
+	)
+
+	cmd := command.New(usage, short, long, runRestore,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.RangeArgs(1, 2)
+	cmd.Example = `flyctl volumes snapshots restore vol_1234 --region ams
+flyctl volumes snapshots restore vol_1234 vs_5678 --size 20`
+
+	flag.Add(cmd,
+		flag.String{
+			Name:        "region",
+			Shorthand:   "r",
+			Description: "The region to create the new volume in. Defaults to the region of the volume",
+		},
+		flag.String{
+			Name:        "name",
+			Description: "Name of the new volume. Defaults to the name of the volume",
+		},
+		flag.Int{
+			Name:        "size",
+			Shorthand:   "s",
+			Description: "Size of the new volume in gigabytes. Defaults to the size of the volume",
+		},
+		flag.Bool{
+			Name:        "require-unique-zone",
+			Description: "Require the new volume to be placed in separate hardware zone from existing volumes",
+			Default:     true,
+		},
+		RetentionFlag(),
+	)
+
+	return cmd
+}
+
+func runRestore(ctx context.Context) error {
+	var (
+		io     = iostreams.FromContext(ctx)
+		cfg    = config.FromContext(ctx)
+		client = client.FromContext(ctx).API()
+
+		args  = flag.Args(ctx)
+		volID = args[0]
+	)
+
+	retention, err := Retention(ctx)
+	if err != nil {
+		return err
+	}
+
+	vol, err := client.GetVolume(ctx, volID)
+	if err != nil {
+		return fmt.Errorf("failed retrieving volume %s: %w", volID, err)
+	}
+
+	snapshots, err := client.GetVolumeSnapshots(ctx, volID)
+	if err != nil {
+		return fmt.Errorf("failed retrieving snapshots of %s: %w", volID, err)
+	}
+
+	var snapshotID string
+	if len(args) > 1 {
+		snapshotID = args[1]
+	}
+
+	snapshot, err := selectSnapshot(snapshots, snapshotID)
+	if err != nil {
+		return &flyerr.NotFoundError{
+			Err: fmt.Errorf("%w of volume %s; see 'fly volumes snapshots list %s'", err, volID, volID),
+		}
+	}
+
+	input, err := restoreInput(ctx, vol)
+	if err != nil {
+		return err
+	}
+	input.SnapshotID = api.StringPointer(snapshot.ID)
+	input.SnapshotRetention = retention
+
+	app, err := client.GetApp(ctx, vol.App.Name)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app %s: %w", vol.App.Name, err)
+	}
+	input.AppID = app.ID
+
+	restored, err := client.CreateVolume(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed restoring snapshot %s: %w", snapshot.ID, err)
+	}
+
+	if cfg.StructuredOutput() {
+		return render.Structured(ctx, io.Out, restored)
+	}
+
+	fmt.Fprintf(io.Out, "Restored snapshot %s (taken %s) into volume %s (%s, %dGB) in %s\n",
+		snapshot.ID, snapshot.CreatedAt.Format(time.RFC822), restored.ID, restored.Name, restored.SizeGb, restored.Region)
+
+	return nil
+}
+
+// selectSnapshot returns the snapshot of the given ID or, in case id is empty,
+// the latest snapshot.
+func selectSnapshot(snapshots []api.Snapshot, id string) (*api.Snapshot, error) {
+	var latest *api.Snapshot
+
+	for i := range snapshots {
+		s := &snapshots[i]
+
+		switch {
+		case id != "" && s.ID == id:
+			return s, nil
+		case id == "" && (latest == nil || s.CreatedAt.After(latest.CreatedAt)):
+			latest = s
+		}
+	}
+
+	switch {
+	case id != "":
+		return nil, fmt.Errorf("no snapshot %s", id)
+	case latest == nil:
+		return nil, errors.New("no snapshots")
+	default:
+		return latest, nil
+	}
+}
+
+// restoreInput returns the input which creates the volume a snapshot of vol
+// restores into.
+func restoreInput(ctx context.Context, vol *api.Volume) (input api.CreateVolumeInput, err error) {
+	input = api.CreateVolumeInput{
+		Name:              flag.GetString(ctx, "name"),
+		Region:            flag.GetString(ctx, "region"),
+		SizeGb:            flag.GetInt(ctx, "size"),
+		Encrypted:         vol.Encrypted,
+		RequireUniqueZone: flag.GetBool(ctx, "require-unique-zone"),
+	}
+
+	if input.Name == "" {
+		input.Name = vol.Name
+	}
+
+	if input.Region == "" {
+		input.Region = vol.Region
+	}
+
+	switch {
+	case input.SizeGb == 0:
+		input.SizeGb = vol.SizeGb
+	case input.SizeGb < vol.SizeGb:
+		err = fmt.Errorf("volumes can't shrink; the new volume must be at least %dGB", vol.SizeGb)
+	}
+
+	return
+}
@@ -0,0 +1,35 @@
+package snapshots
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestSelectSnapshot(t *testing.T) {
+	now := time.Now()
+
+	snapshots := []api.Snapshot{
+		{ID: "old", CreatedAt: now.Add(-time.Hour)},
+		{ID: "newest", CreatedAt: now},
+		{ID: "new", CreatedAt: now.Add(-time.Minute)},
+	}
+
+	s, err := selectSnapshot(snapshots, "")
+	require.NoError(t, err)
+	assert.Equal(t, "newest", s.ID)
+
+	s, err = selectSnapshot(snapshots, "old")
+	require.NoError(t, err)
+	assert.Equal(t, "old", s.ID)
+
+	_, err = selectSnapshot(snapshots, "missing")
+	assert.EqualError(t, err, "no snapshot missing")
+
+	_, err = selectSnapshot(nil, "")
+	assert.EqualError(t, err, "no snapshots")
+}
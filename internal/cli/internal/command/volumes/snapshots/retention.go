@@ -0,0 +1,40 @@
+package snapshots
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/superfly/flyctl/api"
+
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+)
+
+const (
+	retentionFlagName = "snapshot-retention"
+
+	// maxRetention is the maximum number of days scheduled snapshots may be kept
+	// for.
+	maxRetention = 60
+)
+
+// RetentionFlag returns the flag which sets the number of days the scheduled
+// snapshots of the volumes a command creates or updates are kept for.
+func RetentionFlag() flag.Int {
+	return flag.Int{
+		Name:        retentionFlagName,
+		Description: fmt.Sprintf("Number of days (1-%d) to keep the scheduled snapshots of the volume for. Defaults to the platform's retention", maxRetention),
+	}
+}
+
+// Retention returns the value of the flag RetentionFlag returns, or nil in
+// case it wasn't set.
+func Retention(ctx context.Context) (*int, error) {
+	switch days := flag.GetInt(ctx, retentionFlagName); {
+	case days == 0:
+		return nil, nil
+	case days < 0 || days > maxRetention:
+		return nil, fmt.Errorf("--%s must be between 1 and %d days", retentionFlagName, maxRetention)
+	default:
+		return api.IntPointer(days), nil
+	}
+}
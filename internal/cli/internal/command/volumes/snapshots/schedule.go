@@ -0,0 +1,245 @@
+package snapshots
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/command"
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/internal/cli/internal/render"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+// frequencyOff denotes the schedule of volumes which aren't snapshotted on a
+// schedule.
+const frequencyOff = "off"
+
+// frequencies maps the frequencies volumes may be snapshotted at to the
+// interval between their snapshots.
+var frequencies = map[string]time.Duration{
+	"daily":  24 * time.Hour,
+	"weekly": 7 * 24 * time.Hour,
+}
+
+// upcomingCount is the number of upcoming snapshots schedule lists.
+const upcomingCount = 3
+
+func newSchedule() *cobra.Command {
+	const (
+		long = `Show or change how often the specified volume is snapshotted and for how
+many days those snapshots are kept, along with its upcoming and past scheduled
+snapshots. Snapshots taken on demand aren't affected by the schedule.
+`
+		short = "Manage the snapshot schedule of a volume"
+
+		usage = "schedule <volume-id>"
+	)
+
+	cmd := command.New(usage, short, long, runSchedule,
+		command.RequireSession,
+	)
+
+	cmd.Args = cobra.ExactArgs(1)
+
+	flag.Add(cmd,
+		flag.String{
+			Name:        "frequency",
+			Description: "How often to snapshot the volume; one of daily, weekly or off",
+		},
+		RetentionFlag(),
+	)
+
+	cmd.Example = `fly volumes snapshots schedule vol_2n0l3vl60qpv635d --frequency daily --snapshot-retention 14`
+
+	return cmd
+}
+
+// scheduledSnapshot denotes a snapshot the schedule of a volume took.
+type scheduledSnapshot struct {
+	ID        string
+	Size      string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type scheduleStatus struct {
+	VolumeID string
+	api.VolumeSnapshotSchedule
+	Upcoming []time.Time
+	Past     []scheduledSnapshot
+}
+
+func runSchedule(ctx context.Context) error {
+	var (
+		io     = iostreams.FromContext(ctx)
+		cfg    = config.FromContext(ctx)
+		client = client.FromContext(ctx).API()
+		volID  = flag.FirstArg(ctx)
+	)
+
+	vol, err := client.GetVolumeSnapshotSchedule(ctx, volID)
+	if err != nil {
+		return fmt.Errorf("failed retrieving volume %s: %w", volID, err)
+	}
+
+	input, err := scheduleInput(ctx, vol)
+	if err != nil {
+		return err
+	}
+
+	if input != nil {
+		schedule, err := client.UpdateVolumeSnapshotSchedule(ctx, *input)
+		if err != nil {
+			return fmt.Errorf("failed updating the snapshot schedule of volume %s: %w", volID, err)
+		}
+		vol.SnapshotSchedule = schedule
+
+		fmt.Fprintf(io.ErrOut, "Updated the snapshot schedule of volume %s\n", volID)
+	}
+
+	status := newScheduleStatus(vol, time.Now())
+
+	if cfg.StructuredOutput() {
+		return render.Structured(ctx, io.Out, status)
+	}
+
+	policy := "not snapshotted on a schedule"
+	if status.Frequency != frequencyOff {
+		policy = fmt.Sprintf("snapshotted %s, snapshots kept for %d days", status.Frequency, status.RetentionDays)
+	}
+	fmt.Fprintf(io.Out, "Volume %s is %s\n\n", volID, policy)
+
+	if len(status.Upcoming) > 0 {
+		rows := make([][]string, 0, len(status.Upcoming))
+		for _, at := range status.Upcoming {
+			rows = append(rows, []string{at.UTC().Format(time.RFC3339), humanize.Time(at)})
+		}
+
+		if err := render.Table(io.Out, "Upcoming", rows, "At", "In"); err != nil {
+			return err
+		}
+	}
+
+	rows := make([][]string, 0, len(status.Past))
+	for _, s := range status.Past {
+		rows = append(rows, []string{s.ID, s.Size, humanize.Time(s.CreatedAt), humanize.Time(s.ExpiresAt)})
+	}
+
+	return render.Table(io.Out, "Past", rows, "ID", "Size", "Created At", "Expires")
+}
+
+// scheduleInput returns the update the flags make to the schedule of vol, or
+// nil in case they make none.
+func scheduleInput(ctx context.Context, vol *api.Volume) (*api.UpdateVolumeSnapshotScheduleInput, error) {
+	retention, err := Retention(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	frequency := strings.ToLower(flag.GetString(ctx, "frequency"))
+	if frequency == "" && retention == nil {
+		return nil, nil
+	}
+
+	if frequency != "" && frequency != frequencyOff {
+		if _, ok := frequencies[frequency]; !ok {
+			return nil, fmt.Errorf("invalid --frequency %q; use one of daily, weekly or off", frequency)
+		}
+	}
+
+	input := &api.UpdateVolumeSnapshotScheduleInput{
+		VolumeID:      vol.ID,
+		RetentionDays: retention,
+	}
+	if frequency != "" {
+		input.Frequency = api.StringPointer(frequency)
+	}
+
+	if err := validateSchedule(vol.SnapshotSchedule, frequency, retention); err != nil {
+		return nil, err
+	}
+
+	return input, nil
+}
+
+// validateSchedule checks that the snapshots of the schedule resulting from
+// changing current to the given frequency and retention outlive the interval
+// between them, since the volume would otherwise have none most of the time.
+func validateSchedule(current *api.VolumeSnapshotSchedule, frequency string, retention *int) error {
+	var days int
+	if current != nil {
+		days = current.RetentionDays
+		if frequency == "" {
+			frequency = current.Frequency
+		}
+	}
+	if retention != nil {
+		days = *retention
+	}
+
+	interval, ok := frequencies[frequency]
+	if !ok || days == 0 {
+		return nil
+	}
+
+	if minDays := int(interval / (24 * time.Hour)); days < minDays {
+		return fmt.Errorf("%s snapshots must be kept for at least %d days; raise --%s", frequency, minDays, retentionFlagName)
+	}
+
+	return nil
+}
+
+// newScheduleStatus returns the status of the schedule of vol as of now.
+func newScheduleStatus(vol *api.Volume, now time.Time) *scheduleStatus {
+	status := &scheduleStatus{
+		VolumeID: vol.ID,
+		VolumeSnapshotSchedule: api.VolumeSnapshotSchedule{
+			Frequency: frequencyOff,
+		},
+	}
+	if vol.SnapshotSchedule != nil {
+		status.VolumeSnapshotSchedule = *vol.SnapshotSchedule
+	}
+
+	interval, ok := frequencies[status.Frequency]
+	if ok && status.NextSnapshotAt != nil {
+		at := *status.NextSnapshotAt
+		for len(status.Upcoming) < upcomingCount {
+			if !at.Before(now) {
+				status.Upcoming = append(status.Upcoming, at)
+			}
+			at = at.Add(interval)
+		}
+	}
+
+	retention := time.Duration(status.RetentionDays) * 24 * time.Hour
+	for _, s := range vol.Snapshots.Nodes {
+		if !s.Scheduled {
+			continue
+		}
+
+		status.Past = append(status.Past, scheduledSnapshot{
+			ID:        s.ID,
+			Size:      s.Size,
+			CreatedAt: s.CreatedAt,
+			ExpiresAt: s.CreatedAt.Add(retention),
+		})
+	}
+
+	// sort snapshots from newest to oldest
+	sort.Slice(status.Past, func(i, j int) bool {
+		return status.Past[i].CreatedAt.After(status.Past[j].CreatedAt)
+	})
+
+	return status
+}
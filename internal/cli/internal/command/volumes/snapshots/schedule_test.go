@@ -0,0 +1,55 @@
+package snapshots
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestValidateSchedule(t *testing.T) {
+	daily := &api.VolumeSnapshotSchedule{Frequency: "daily", RetentionDays: 5}
+
+	assert.NoError(t, validateSchedule(daily, "", api.IntPointer(1)))
+	assert.NoError(t, validateSchedule(daily, "off", nil))
+	assert.NoError(t, validateSchedule(nil, "weekly", nil))
+
+	assert.EqualError(t, validateSchedule(daily, "weekly", nil),
+		"weekly snapshots must be kept for at least 7 days; raise --snapshot-retention")
+	assert.NoError(t, validateSchedule(daily, "weekly", api.IntPointer(14)))
+}
+
+func TestNewScheduleStatus(t *testing.T) {
+	now := time.Date(2021, 6, 10, 12, 0, 0, 0, time.UTC)
+	next := now.Add(-time.Hour)
+
+	vol := &api.Volume{
+		ID: "vol",
+		SnapshotSchedule: &api.VolumeSnapshotSchedule{
+			Frequency:      "daily",
+			RetentionDays:  14,
+			NextSnapshotAt: &next,
+		},
+	}
+	vol.Snapshots.Nodes = []api.Snapshot{
+		{ID: "old", CreatedAt: now.Add(-48 * time.Hour), Scheduled: true},
+		{ID: "manual", CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: "new", CreatedAt: now.Add(-24 * time.Hour), Scheduled: true},
+	}
+
+	status := newScheduleStatus(vol, now)
+
+	assert.Equal(t, []time.Time{next.Add(24 * time.Hour), next.Add(48 * time.Hour), next.Add(72 * time.Hour)}, status.Upcoming)
+
+	require.Len(t, status.Past, 2)
+	assert.Equal(t, "new", status.Past[0].ID)
+	assert.Equal(t, now.Add(13*24*time.Hour), status.Past[0].ExpiresAt)
+	assert.Equal(t, "old", status.Past[1].ID)
+
+	off := newScheduleStatus(&api.Volume{ID: "vol"}, now)
+	assert.Equal(t, "off", off.Frequency)
+	assert.Empty(t, off.Upcoming)
+}
@@ -22,6 +22,9 @@ func New() *cobra.Command {
 
 	snapshots.AddCommand(
 		newList(),
+		newCreate(),
+		newRestore(),
+		newSchedule(),
 	)
 
 	return snapshots
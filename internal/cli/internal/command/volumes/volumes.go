@@ -32,6 +32,7 @@ func New() *cobra.Command {
 		newList(),
 		newDelete(),
 		newShow(),
+		newMigrate(),
 		snapshots.New(),
 	)
 
@@ -1,12 +1,18 @@
 package config
 
 import (
+	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/spf13/pflag"
 
+	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/internal/cli/internal/flag"
 	"github.com/superfly/flyctl/internal/env"
+	"github.com/superfly/flyctl/pkg/iostreams"
 )
 
 const (
@@ -24,12 +30,25 @@ const (
 	organizationEnvKey    = envKeyPrefix + "ORGANIZATION"
 	regionEnvKey          = envKeyPrefix + "REGION"
 	verboseOutputEnvKey   = envKeyPrefix + "VERBOSE"
+	quietOutputEnvKey     = envKeyPrefix + "QUIET"
+	debugOutputEnvKey     = envKeyPrefix + "DEBUG"
 	jsonOutputEnvKey      = envKeyPrefix + "JSON"
+	formatEnvKey          = envKeyPrefix + "FORMAT"
+	themeEnvKey           = envKeyPrefix + "THEME"
+	ThemeFileKey          = "theme"
+	experimentsEnvKey     = envKeyPrefix + "EXPERIMENTS"
+	ExperimentsFileKey    = "experiments"
 	logGQLEnvKey          = envKeyPrefix + "LOG_GQL_ERRORS"
 	localOnlyEnvKey       = envKeyPrefix + "LOCAL_ONLY"
+	apiMaxRetriesEnvKey   = envKeyPrefix + "API_MAX_RETRIES"
+	cacheTTLEnvKey        = envKeyPrefix + "CACHE_TTL"
+
+	DesktopNotificationsFileKey = "desktop_notifications"
+	CacheTTLFileKey             = "cache_ttl"
 
 	defaultAPIBaseURL   = "https://api.fly.io"
 	defaultRegistryHost = "registry.fly.io"
+	defaultCacheTTL     = 24 * time.Hour
 )
 
 // Config wraps the functionality of the configuration file.
@@ -47,12 +66,37 @@ type Config struct {
 	// VerboseOutput denotes whether the user wants the output to be verbose.
 	VerboseOutput bool
 
+	// QuietOutput denotes whether the user wants the output to be terse.
+	QuietOutput bool
+
+	// DebugOutput denotes whether the user wants the output to include
+	// debugging information.
+	DebugOutput bool
+
 	// JSONOutput denotes whether the user wants the output to be JSON.
 	JSONOutput bool
 
+	// Format denotes the Go template the user wants structured output to be
+	// rendered through.
+	Format string
+
+	// Theme denotes the name of the theme the user wants output to be colored
+	// with.
+	Theme string
+
+	// Experiments denotes the names of the experiments the user has enabled.
+	Experiments []string
+
 	// LogGQLErrors denotes whether the user wants the log GraphQL errors.
 	LogGQLErrors bool
 
+	// DebugHTTP denotes whether the user wants requests to the API traced.
+	DebugHTTP bool
+
+	// DebugHTTPFile denotes the path of the file the user wants requests to
+	// the API traced to, rather than to stderr.
+	DebugHTTPFile string
+
 	// Organization denotes the organizational slug the user has selected.
 	Organization string
 
@@ -62,15 +106,38 @@ type Config struct {
 	// LocalOnly denotes whether the user wants only local operations.
 	LocalOnly bool
 
+	// APIMaxRetries denotes how many times API requests which fail
+	// transiently are retried.
+	APIMaxRetries int
+
+	// CacheTTL denotes for how long API responses are cached for offline
+	// use; 0 disables the cache. The environment overrides the configuration
+	// file.
+	CacheTTL time.Duration
+
 	// AccessToken denotes the user's access token.
 	AccessToken string
+
+	// Context denotes the name of the context in use, if any.
+	Context string
+
+	// OIDC denotes the OIDC session the access token belongs to, if any. It's
+	// unset when the access token doesn't come from the configuration file.
+	OIDC *OIDCSession
+
+	// DesktopNotifications denotes whether the user wants long running
+	// operations, like deployments, to send desktop notifications once they
+	// finish.
+	DesktopNotifications bool
 }
 
 // New returns a new instance of Config populated with default values.
 func New() *Config {
 	return &Config{
-		APIBaseURL:   defaultAPIBaseURL,
-		RegistryHost: defaultRegistryHost,
+		APIBaseURL:    defaultAPIBaseURL,
+		RegistryHost:  defaultRegistryHost,
+		APIMaxRetries: api.DefaultRetryPolicy.MaxRetries,
+		CacheTTL:      defaultCacheTTL,
 	}
 }
 
@@ -82,10 +149,20 @@ func (cfg *Config) ApplyEnv() {
 	cfg.mu.Lock()
 	defer cfg.mu.Unlock()
 
-	cfg.AccessToken = env.FirstOrDefault(cfg.AccessToken,
-		AccessTokenEnvKey, APITokenEnvKey)
+	// variables which are exported but empty don't override the token of the
+	// file nor end its session
+	for _, key := range []string{AccessTokenEnvKey, APITokenEnvKey} {
+		if token := os.Getenv(key); token != "" {
+			cfg.AccessToken = token
+			cfg.OIDC = nil
+
+			break
+		}
+	}
 
 	cfg.VerboseOutput = env.IsTruthy(verboseOutputEnvKey) || cfg.VerboseOutput
+	cfg.QuietOutput = env.IsTruthy(quietOutputEnvKey) || cfg.QuietOutput
+	cfg.DebugOutput = env.IsTruthy(debugOutputEnvKey) || cfg.DebugOutput
 	cfg.JSONOutput = env.IsTruthy(jsonOutputEnvKey) || cfg.JSONOutput
 	cfg.LogGQLErrors = env.IsTruthy(logGQLEnvKey) || cfg.LogGQLErrors
 	cfg.LocalOnly = env.IsTruthy(localOnlyEnvKey) || cfg.LocalOnly
@@ -93,8 +170,24 @@ func (cfg *Config) ApplyEnv() {
 	cfg.Organization = env.FirstOrDefault(cfg.Organization,
 		orgEnvKey, organizationEnvKey)
 	cfg.Region = env.FirstOrDefault(cfg.Region, regionEnvKey)
+	cfg.Format = env.FirstOrDefault(cfg.Format, formatEnvKey)
+	cfg.Theme = env.FirstOrDefault(cfg.Theme, themeEnvKey)
 	cfg.RegistryHost = env.FirstOrDefault(cfg.RegistryHost, registryHostEnvKey)
 	cfg.APIBaseURL = env.FirstOrDefault(cfg.APIBaseURL, apiBaseURLEnvKey)
+
+	if n, err := strconv.Atoi(env.First(apiMaxRetriesEnvKey)); err == nil && n >= 0 {
+		cfg.APIMaxRetries = n
+	}
+
+	if d, err := time.ParseDuration(env.First(cacheTTLEnvKey)); err == nil && d >= 0 {
+		cfg.CacheTTL = d
+	}
+
+	for _, name := range strings.Split(os.Getenv(experimentsEnvKey), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			cfg.Experiments = append(cfg.Experiments, name)
+		}
+	}
 }
 
 // ApplyFile sets the properties of cfg which may be set via configuration file
@@ -104,11 +197,24 @@ func (cfg *Config) ApplyFile(path string) (err error) {
 	defer cfg.mu.Unlock()
 
 	var w struct {
-		AccessToken string `yaml:"access_token"`
+		AccessToken          string       `yaml:"access_token"`
+		Theme                string       `yaml:"theme"`
+		Experiments          []string     `yaml:"experiments"`
+		DesktopNotifications bool         `yaml:"desktop_notifications"`
+		OIDC                 *OIDCSession `yaml:"oidc"`
+		CacheTTL             string       `yaml:"cache_ttl"`
 	}
 
 	if err = unmarshal(path, &w); err == nil {
 		cfg.AccessToken = w.AccessToken
+		cfg.Theme = w.Theme
+		cfg.Experiments = append(w.Experiments, cfg.Experiments...)
+		cfg.DesktopNotifications = w.DesktopNotifications
+		cfg.OIDC = w.OIDC
+
+		if d, perr := time.ParseDuration(w.CacheTTL); perr == nil && d >= 0 {
+			cfg.CacheTTL = d
+		}
 	}
 
 	return
@@ -120,17 +226,61 @@ func (cfg *Config) ApplyFlags(fs *pflag.FlagSet) {
 	cfg.mu.Lock()
 	defer cfg.mu.Unlock()
 
+	if fs.Changed(flag.AccessTokenName) {
+		cfg.OIDC = nil
+	}
+
 	applyStringFlags(fs, map[string]*string{
-		flag.AccessTokenName: &cfg.AccessToken,
-		flag.OrgName:         &cfg.Organization,
-		flag.RegionName:      &cfg.Region,
+		flag.AccessTokenName:   &cfg.AccessToken,
+		flag.OrgName:           &cfg.Organization,
+		flag.RegionName:        &cfg.Region,
+		flag.FormatName:        &cfg.Format,
+		flag.DebugHTTPFileName: &cfg.DebugHTTPFile,
 	})
 
 	applyBoolFlags(fs, map[string]*bool{
 		flag.VerboseName:    &cfg.VerboseOutput,
+		flag.QuietName:      &cfg.QuietOutput,
+		flag.DebugName:      &cfg.DebugOutput,
 		flag.JSONOutputName: &cfg.JSONOutput,
 		flag.LocalOnlyName:  &cfg.LocalOnly,
+		flag.DebugHTTPName:  &cfg.DebugHTTP,
 	})
+
+	if fs.Changed(flag.ExperimentsName) {
+		if v, err := fs.GetStringSlice(flag.ExperimentsName); err != nil {
+			panic(err)
+		} else {
+			cfg.Experiments = append(cfg.Experiments, v...)
+		}
+	}
+}
+
+// Verbosity returns the output verbosity cfg denotes. Asking for more output
+// takes precedence over asking for less.
+func (cfg *Config) Verbosity() iostreams.Verbosity {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
+	switch {
+	case cfg.DebugOutput:
+		return iostreams.VerbosityDebug
+	case cfg.VerboseOutput:
+		return iostreams.VerbosityVerbose
+	case cfg.QuietOutput:
+		return iostreams.VerbosityQuiet
+	default:
+		return iostreams.VerbosityNormal
+	}
+}
+
+// StructuredOutput returns whether the user wants the output to be structured
+// data, either JSON or rendered through a format template.
+func (cfg *Config) StructuredOutput() bool {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
+	return cfg.JSONOutput || cfg.Format != ""
 }
 
 func applyStringFlags(fs *pflag.FlagSet, flags map[string]*string) {
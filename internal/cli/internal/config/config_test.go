@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+	"github.com/superfly/flyctl/pkg/iostreams"
+)
+
+func TestVerbosity(t *testing.T) {
+	cases := map[string]struct {
+		args []string
+		exp  iostreams.Verbosity
+	}{
+		"default":       {nil, iostreams.VerbosityNormal},
+		"quiet":         {[]string{"-q"}, iostreams.VerbosityQuiet},
+		"verbose":       {[]string{"-v"}, iostreams.VerbosityVerbose},
+		"debug":         {[]string{"--debug"}, iostreams.VerbosityDebug},
+		"quiet+verbose": {[]string{"-q", "-v"}, iostreams.VerbosityVerbose},
+		"verbose+debug": {[]string{"-v", "--debug"}, iostreams.VerbosityDebug},
+	}
+
+	for name, c := range cases {
+		fs := pflag.NewFlagSet(name, pflag.ContinueOnError)
+		fs.BoolP(flag.QuietName, "q", false, "")
+		fs.BoolP(flag.VerboseName, "v", false, "")
+		fs.Bool(flag.DebugName, false, "")
+		require.NoError(t, fs.Parse(c.args))
+
+		cfg := New()
+		cfg.ApplyFlags(fs)
+
+		assert.Equal(t, c.exp, cfg.Verbosity(), name)
+	}
+}
+
+func TestAPIMaxRetries(t *testing.T) {
+	cfg := New()
+	cfg.ApplyEnv()
+	assert.Equal(t, api.DefaultRetryPolicy.MaxRetries, cfg.APIMaxRetries)
+
+	t.Setenv(apiMaxRetriesEnvKey, "0")
+	cfg.ApplyEnv()
+	assert.Equal(t, 0, cfg.APIMaxRetries, "0 disables retries")
+
+	t.Setenv(apiMaxRetriesEnvKey, "many")
+	cfg = New()
+	cfg.ApplyEnv()
+	assert.Equal(t, api.DefaultRetryPolicy.MaxRetries, cfg.APIMaxRetries, "invalid values are ignored")
+}
+
+func TestDebugHTTP(t *testing.T) {
+	fs := pflag.NewFlagSet(t.Name(), pflag.ContinueOnError)
+	fs.Bool(flag.DebugHTTPName, false, "")
+	fs.String(flag.DebugHTTPFileName, "", "")
+	require.NoError(t, fs.Parse([]string{"--debug-http", "--debug-http-file", "trace.log"}))
+
+	cfg := New()
+	cfg.ApplyFlags(fs)
+
+	assert.True(t, cfg.DebugHTTP)
+	assert.Equal(t, "trace.log", cfg.DebugHTTPFile)
+}
+
+func TestCacheTTL(t *testing.T) {
+	cfg := New()
+	cfg.ApplyEnv()
+	assert.Equal(t, defaultCacheTTL, cfg.CacheTTL)
+
+	t.Setenv(cacheTTLEnvKey, "1h30m")
+	cfg.ApplyEnv()
+	assert.Equal(t, 90*time.Minute, cfg.CacheTTL)
+
+	t.Setenv(cacheTTLEnvKey, "0")
+	cfg.ApplyEnv()
+	assert.Equal(t, time.Duration(0), cfg.CacheTTL, "0 disables the cache")
+}
+
+func TestCacheTTLFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte(CacheTTLFileKey+": 2h\n"), 0600))
+
+	cfg := New()
+	require.NoError(t, cfg.ApplyFile(path))
+	assert.Equal(t, 2*time.Hour, cfg.CacheTTL)
+
+	t.Setenv(cacheTTLEnvKey, "5m")
+	cfg.ApplyEnv()
+	assert.Equal(t, 5*time.Minute, cfg.CacheTTL, "the environment overrides the file")
+}
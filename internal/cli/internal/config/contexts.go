@@ -0,0 +1,227 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/spf13/pflag"
+
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+)
+
+const (
+	// ContextsFileKey denotes the key of the configuration file under which
+	// named contexts are stored.
+	ContextsFileKey = "contexts"
+
+	// CurrentContextFileKey denotes the key of the configuration file which
+	// names the context in use.
+	CurrentContextFileKey = "current_context"
+
+	contextEnvKey = envKeyPrefix + "CONTEXT"
+)
+
+var contextNameRE = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Context wraps a named set of credentials and defaults, which lets users
+// switch between accounts without logging in again.
+type Context struct {
+	// Name denotes the name of the context.
+	Name string `yaml:"-"`
+
+	// AccessToken denotes the access token of the context.
+	AccessToken string `yaml:"access_token,omitempty"`
+
+	// Org denotes the slug of the organization commands default to.
+	Org string `yaml:"org,omitempty"`
+
+	// Region denotes the code of the region commands default to.
+	Region string `yaml:"region,omitempty"`
+
+	// APIBaseURL denotes the base URL of the API, if not the default one.
+	APIBaseURL string `yaml:"api_base_url,omitempty"`
+}
+
+// ValidateContextName returns an error in case name isn't fit for a context.
+func ValidateContextName(name string) error {
+	if !contextNameRE.MatchString(name) {
+		return fmt.Errorf("invalid context name %q: names may only contain letters, digits, dots, dashes and underscores", name)
+	}
+
+	return nil
+}
+
+type contextsFile struct {
+	Current  string             `yaml:"current_context"`
+	Contexts map[string]Context `yaml:"contexts"`
+}
+
+func readContexts(path string) (*contextsFile, error) {
+	var w contextsFile
+	if err := unmarshal(path, &w); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// Contexts returns the contexts of the configuration file found at path,
+// sorted by name, along with the name of the one in use, if any.
+func Contexts(path string) (contexts []Context, current string, err error) {
+	w, err := readContexts(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	contexts = make([]Context, 0, len(w.Contexts))
+	for name, c := range w.Contexts {
+		c.Name = name
+		contexts = append(contexts, c)
+	}
+
+	sort.Slice(contexts, func(i, j int) bool {
+		return contexts[i].Name < contexts[j].Name
+	})
+
+	return contexts, w.Current, nil
+}
+
+// SetContext stores the given context at the configuration file found at
+// path, replacing any other context of the same name.
+func SetContext(path string, c Context) error {
+	if err := ValidateContextName(c.Name); err != nil {
+		return err
+	}
+
+	return updateContexts(path, func(w *contextsFile) error {
+		w.Contexts[c.Name] = c
+
+		return nil
+	})
+}
+
+// SetContextAccessToken sets the access token of the named context of the
+// configuration file found at path.
+func SetContextAccessToken(path, name, token string) error {
+	return updateContexts(path, func(w *contextsFile) error {
+		c, ok := w.Contexts[name]
+		if !ok {
+			return fmt.Errorf("context %q doesn't exist", name)
+		}
+
+		c.AccessToken = token
+		w.Contexts[name] = c
+
+		return nil
+	})
+}
+
+// UseContext sets the named context as the one in use at the configuration
+// file found at path. An empty name stops using contexts.
+func UseContext(path, name string) error {
+	return updateContexts(path, func(w *contextsFile) error {
+		if _, ok := w.Contexts[name]; !ok && name != "" {
+			return fmt.Errorf("context %q doesn't exist", name)
+		}
+
+		w.Current = name
+
+		return nil
+	})
+}
+
+// RemoveContext removes the named context from the configuration file found
+// at path, which stops using it in case it's in use.
+func RemoveContext(path, name string) error {
+	return updateContexts(path, func(w *contextsFile) error {
+		if _, ok := w.Contexts[name]; !ok {
+			return fmt.Errorf("context %q doesn't exist", name)
+		}
+
+		delete(w.Contexts, name)
+		if w.Current == name {
+			w.Current = ""
+		}
+
+		return nil
+	})
+}
+
+func updateContexts(path string, fn func(*contextsFile) error) error {
+	w, err := readContexts(path)
+	if err != nil {
+		return err
+	}
+
+	if w.Contexts == nil {
+		w.Contexts = map[string]Context{}
+	}
+
+	if err := fn(w); err != nil {
+		return err
+	}
+
+	return set(path, map[string]interface{}{
+		ContextsFileKey:       w.Contexts,
+		CurrentContextFileKey: w.Current,
+	})
+}
+
+// ContextName returns the name of the context the given FlagSet or the
+// environment select, if any.
+func ContextName(fs *pflag.FlagSet) string {
+	if fs.Changed(flag.ContextName) {
+		if v, err := fs.GetString(flag.ContextName); err != nil {
+			panic(err)
+		} else {
+			return v
+		}
+	}
+
+	return os.Getenv(contextEnvKey)
+}
+
+// ApplyContext sets the properties of cfg the named context of the
+// configuration file found at path sets, overriding those of the file. An
+// empty name denotes the context in use, if any.
+//
+// ApplyContext should be called after ApplyFile, so that the environment and
+// the command line flags still take precedence.
+func (cfg *Config) ApplyContext(path, name string) error {
+	w, err := readContexts(path)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		if name = w.Current; name == "" {
+			return nil
+		}
+	}
+
+	c, ok := w.Contexts[name]
+	if !ok {
+		return fmt.Errorf("context %q doesn't exist; see 'fly config contexts list'", name)
+	}
+
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
+	cfg.Context = name
+	cfg.AccessToken = c.AccessToken
+	cfg.OIDC = nil
+
+	if c.Org != "" {
+		cfg.Organization = c.Org
+	}
+	if c.Region != "" {
+		cfg.Region = c.Region
+	}
+	if c.APIBaseURL != "" {
+		cfg.APIBaseURL = c.APIBaseURL
+	}
+
+	return nil
+}
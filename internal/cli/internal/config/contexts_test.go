@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+)
+
+func TestContexts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte("access_token: abc\n"), 0600))
+
+	contexts, current, err := Contexts(path)
+	require.NoError(t, err)
+	assert.Empty(t, contexts)
+	assert.Empty(t, current)
+
+	acme := Context{Name: "acme", AccessToken: "acme-token", Org: "acme", Region: "iad"}
+	staging := Context{Name: "staging", AccessToken: "staging-token", APIBaseURL: "https://api.staging.example.com"}
+	require.NoError(t, SetContext(path, staging))
+	require.NoError(t, SetContext(path, acme))
+	assert.Error(t, SetContext(path, Context{Name: "not valid"}))
+
+	assert.Error(t, UseContext(path, "personal"))
+	require.NoError(t, UseContext(path, "acme"))
+
+	contexts, current, err = Contexts(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Context{acme, staging}, contexts)
+	assert.Equal(t, "acme", current)
+
+	require.NoError(t, SetContextAccessToken(path, "acme", "new-token"))
+	assert.Error(t, SetContextAccessToken(path, "personal", "token"))
+
+	require.NoError(t, RemoveContext(path, "acme"))
+	assert.Error(t, RemoveContext(path, "acme"))
+
+	contexts, current, err = Contexts(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Context{staging}, contexts)
+	assert.Empty(t, current, "removing the context in use must stop using it")
+
+	cfg := New()
+	require.NoError(t, cfg.ApplyFile(path))
+	assert.Equal(t, "abc", cfg.AccessToken, "other keys must be kept")
+}
+
+func TestApplyContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte("access_token: abc\n"), 0600))
+
+	cfg := New()
+	require.NoError(t, cfg.ApplyFile(path))
+	require.NoError(t, cfg.ApplyContext(path, ""))
+	assert.Equal(t, "abc", cfg.AccessToken, "the file applies absent a context in use")
+	assert.Empty(t, cfg.Context)
+
+	require.NoError(t, SetContext(path, Context{Name: "acme", AccessToken: "acme-token", Org: "acme", Region: "iad"}))
+	require.NoError(t, SetContext(path, Context{Name: "staging", AccessToken: "staging-token", APIBaseURL: "https://api.staging.example.com"}))
+	require.NoError(t, UseContext(path, "acme"))
+
+	cfg = New()
+	require.NoError(t, cfg.ApplyFile(path))
+	require.NoError(t, cfg.ApplyContext(path, ""))
+	assert.Equal(t, "acme", cfg.Context)
+	assert.Equal(t, "acme-token", cfg.AccessToken)
+	assert.Equal(t, "acme", cfg.Organization)
+	assert.Equal(t, "iad", cfg.Region)
+	assert.Equal(t, defaultAPIBaseURL, cfg.APIBaseURL)
+
+	cfg = New()
+	require.NoError(t, cfg.ApplyContext(path, "staging"))
+	assert.Equal(t, "staging-token", cfg.AccessToken)
+	assert.Equal(t, "https://api.staging.example.com", cfg.APIBaseURL)
+
+	assert.EqualError(t, New().ApplyContext(path, "personal"),
+		`context "personal" doesn't exist; see 'fly config contexts list'`)
+}
+
+func TestContextName(t *testing.T) {
+	t.Setenv(contextEnvKey, "acme")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String(flag.ContextName, "", "")
+	assert.Equal(t, "acme", ContextName(fs))
+
+	require.NoError(t, fs.Parse([]string{"--context", "staging"}))
+	assert.Equal(t, "staging", ContextName(fs))
+}
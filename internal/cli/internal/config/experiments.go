@@ -0,0 +1,66 @@
+package config
+
+import (
+	"os"
+	"sort"
+)
+
+// FileExperiments returns the sorted names of the experiments enabled at the
+// configuration file found at path.
+func FileExperiments(path string) ([]string, error) {
+	var w struct {
+		Experiments []string `yaml:"experiments"`
+	}
+
+	if err := unmarshal(path, &w); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	sort.Strings(w.Experiments)
+
+	return w.Experiments, nil
+}
+
+// EnableExperiments enables the named experiments at the configuration file
+// found at path.
+func EnableExperiments(path string, names ...string) error {
+	return updateExperiments(path, func(enabled map[string]bool) {
+		for _, name := range names {
+			enabled[name] = true
+		}
+	})
+}
+
+// DisableExperiments disables the named experiments at the configuration file
+// found at path.
+func DisableExperiments(path string, names ...string) error {
+	return updateExperiments(path, func(enabled map[string]bool) {
+		for _, name := range names {
+			delete(enabled, name)
+		}
+	})
+}
+
+func updateExperiments(path string, fn func(map[string]bool)) error {
+	current, err := FileExperiments(path)
+	if err != nil {
+		return err
+	}
+
+	enabled := make(map[string]bool, len(current))
+	for _, name := range current {
+		enabled[name] = true
+	}
+
+	fn(enabled)
+
+	names := make([]string, 0, len(enabled))
+	for name := range enabled {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return set(path, map[string]interface{}{
+		ExperimentsFileKey: names,
+	})
+}
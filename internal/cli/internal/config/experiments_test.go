@@ -0,0 +1,37 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExperiments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte("access_token: abc\n"), 0600))
+
+	names, err := FileExperiments(path)
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	require.NoError(t, EnableExperiments(path, "watch-ui", "machines-rollout"))
+	require.NoError(t, EnableExperiments(path, "watch-ui"))
+
+	names, err = FileExperiments(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"machines-rollout", "watch-ui"}, names)
+
+	require.NoError(t, DisableExperiments(path, "watch-ui", "never-enabled"))
+
+	cfg := New()
+	require.NoError(t, cfg.ApplyFile(path))
+	assert.Equal(t, "abc", cfg.AccessToken)
+	assert.Equal(t, []string{"machines-rollout"}, cfg.Experiments)
+
+	t.Setenv(experimentsEnvKey, "incremental-upload, watch-ui,")
+	cfg.ApplyEnv()
+	assert.Equal(t, []string{"machines-rollout", "incremental-upload", "watch-ui"}, cfg.Experiments)
+}
@@ -12,10 +12,19 @@ import (
 )
 
 // SetAccessToken sets the value of the access token at the configuration file
-// found at path.
+// found at path. Tokens which are set this way belong to no OIDC session.
 func SetAccessToken(path, token string) error {
 	return set(path, map[string]interface{}{
 		AccessTokenFileKey: token,
+		OIDCFileKey:        nil,
+	})
+}
+
+// SetDesktopNotifications enables or disables desktop notifications at the
+// configuration file found at path.
+func SetDesktopNotifications(path string, enabled bool) error {
+	return set(path, map[string]interface{}{
+		DesktopNotificationsFileKey: enabled,
 	})
 }
 
@@ -24,6 +33,7 @@ func SetAccessToken(path, token string) error {
 func Clear(path string) (err error) {
 	return set(path, map[string]interface{}{
 		AccessTokenFileKey:    "",
+		OIDCFileKey:           nil,
 		WireGuardStateFileKey: map[string]interface{}{},
 	})
 }
@@ -0,0 +1,30 @@
+package config
+
+import "time"
+
+// OIDCFileKey is the key of the configuration file under which the OIDC
+// session, if any, the access token belongs to is kept.
+const OIDCFileKey = "oidc"
+
+// OIDCSession denotes the organization the access token a CI job exchanged
+// its OIDC ID token for grants access to, and when the token expires, so that
+// it may be exchanged again once it does.
+type OIDCSession struct {
+	Org       string    `yaml:"org"`
+	ExpiresAt time.Time `yaml:"expires_at"`
+}
+
+// Expired reports whether the access token of the session expires within
+// margin of now.
+func (s *OIDCSession) Expired(now time.Time, margin time.Duration) bool {
+	return !now.Add(margin).Before(s.ExpiresAt)
+}
+
+// SetOIDCAccessToken sets the access token at the configuration file found at
+// path, along with the OIDC session it belongs to.
+func SetOIDCAccessToken(path, token string, session OIDCSession) error {
+	return set(path, map[string]interface{}{
+		AccessTokenFileKey: token,
+		OIDCFileKey:        session,
+	})
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCAccessToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte("theme: dark\n"), 0600))
+
+	expiresAt := time.Date(2022, 3, 10, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, SetOIDCAccessToken(path, "short-lived", OIDCSession{Org: "acme", ExpiresAt: expiresAt}))
+
+	cfg := New()
+	require.NoError(t, cfg.ApplyFile(path))
+	assert.Equal(t, "short-lived", cfg.AccessToken)
+	assert.Equal(t, "dark", cfg.Theme)
+	require.NotNil(t, cfg.OIDC)
+	assert.Equal(t, "acme", cfg.OIDC.Org)
+	assert.True(t, expiresAt.Equal(cfg.OIDC.ExpiresAt))
+
+	t.Setenv(AccessTokenEnvKey, "")
+	cfg.ApplyEnv()
+	assert.Equal(t, "short-lived", cfg.AccessToken, "empty variables must not override the file")
+	require.NotNil(t, cfg.OIDC, "empty variables must not end the session")
+
+	t.Setenv(APITokenEnvKey, "from-env")
+	cfg.ApplyEnv()
+	assert.Equal(t, "from-env", cfg.AccessToken, "empty variables must not shadow the others")
+
+	t.Setenv(APITokenEnvKey, "")
+	t.Setenv(AccessTokenEnvKey, "from-env")
+	cfg.ApplyEnv()
+	assert.Equal(t, "from-env", cfg.AccessToken)
+	assert.Nil(t, cfg.OIDC, "tokens of the environment belong to no session")
+
+	require.NoError(t, SetAccessToken(path, "long-lived"))
+
+	cfg = New()
+	require.NoError(t, cfg.ApplyFile(path))
+	assert.Nil(t, cfg.OIDC, "setting a token must end the session")
+}
+
+func TestOIDCSessionExpired(t *testing.T) {
+	now := time.Now()
+	s := &OIDCSession{ExpiresAt: now.Add(10 * time.Minute)}
+
+	assert.False(t, s.Expired(now, time.Minute))
+	assert.True(t, s.Expired(now, 10*time.Minute))
+	assert.True(t, s.Expired(now.Add(time.Hour), 0))
+}
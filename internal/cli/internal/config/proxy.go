@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ProxyProfilesFileKey denotes the key of the configuration file under which
+// port forwarding profiles are stored.
+const ProxyProfilesFileKey = "proxy_profiles"
+
+// ProxyProfile wraps a named set of port forwards to the instances of an app.
+type ProxyProfile struct {
+	// Name denotes the name of the profile.
+	Name string `yaml:"-"`
+
+	// App denotes the name of the app the profile forwards to.
+	App string `yaml:"app"`
+
+	// RemoteHost denotes the host the profile forwards to, if other than the
+	// app's internal DNS name.
+	RemoteHost string `yaml:"remote_host,omitempty"`
+
+	// Ports denotes the forwards of the profile, in the form of
+	// local[:remote].
+	Ports []string `yaml:"ports"`
+}
+
+// ProxyProfiles returns the port forwarding profiles of the configuration file
+// found at path, sorted by name.
+func ProxyProfiles(path string) ([]ProxyProfile, error) {
+	var w struct {
+		Profiles map[string]ProxyProfile `yaml:"proxy_profiles"`
+	}
+
+	if err := unmarshal(path, &w); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	profiles := make([]ProxyProfile, 0, len(w.Profiles))
+	for name, p := range w.Profiles {
+		p.Name = name
+		profiles = append(profiles, p)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+
+	return profiles, nil
+}
+
+// SetProxyProfile stores the given port forwarding profile at the
+// configuration file found at path, replacing any profile of the same name.
+func SetProxyProfile(path string, profile ProxyProfile) error {
+	return updateProxyProfiles(path, func(profiles map[string]ProxyProfile) error {
+		profiles[profile.Name] = profile
+
+		return nil
+	})
+}
+
+// RemoveProxyProfile removes the port forwarding profile with the given name
+// from the configuration file found at path.
+func RemoveProxyProfile(path, name string) error {
+	return updateProxyProfiles(path, func(profiles map[string]ProxyProfile) error {
+		if _, ok := profiles[name]; !ok {
+			return fmt.Errorf("proxy profile %q does not exist", name)
+		}
+
+		delete(profiles, name)
+
+		return nil
+	})
+}
+
+func updateProxyProfiles(path string, fn func(map[string]ProxyProfile) error) error {
+	current, err := ProxyProfiles(path)
+	if err != nil {
+		return err
+	}
+
+	profiles := make(map[string]ProxyProfile, len(current))
+	for _, p := range current {
+		profiles[p.Name] = p
+	}
+
+	if err := fn(profiles); err != nil {
+		return err
+	}
+
+	return set(path, map[string]interface{}{
+		ProxyProfilesFileKey: profiles,
+	})
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte("access_token: abc\n"), 0600))
+
+	profiles, err := ProxyProfiles(path)
+	require.NoError(t, err)
+	assert.Empty(t, profiles)
+
+	db := ProxyProfile{Name: "db", App: "pg", Ports: []string{"5432"}}
+	cache := ProxyProfile{Name: "cache", App: "redis", RemoteHost: "fdaa::3", Ports: []string{"16379:6379"}}
+	require.NoError(t, SetProxyProfile(path, db))
+	require.NoError(t, SetProxyProfile(path, cache))
+
+	profiles, err = ProxyProfiles(path)
+	require.NoError(t, err)
+	assert.Equal(t, []ProxyProfile{cache, db}, profiles)
+
+	require.NoError(t, RemoveProxyProfile(path, "cache"))
+	assert.Error(t, RemoveProxyProfile(path, "cache"))
+
+	profiles, err = ProxyProfiles(path)
+	require.NoError(t, err)
+	assert.Equal(t, []ProxyProfile{db}, profiles)
+
+	cfg := New()
+	require.NoError(t, cfg.ApplyFile(path))
+	assert.Equal(t, "abc", cfg.AccessToken, "other keys must be kept")
+}
@@ -2,6 +2,7 @@ package flag
 
 import (
 	"context"
+	"time"
 
 	"github.com/spf13/pflag"
 )
@@ -54,6 +55,17 @@ func GetInt(ctx context.Context, name string) int {
 	}
 }
 
+// GetDuration returns the value of the named duration flag ctx carries. It
+// panics in case ctx carries no flags or in case the named flag isn't a
+// duration one.
+func GetDuration(ctx context.Context, name string) time.Duration {
+	if v, err := FromContext(ctx).GetDuration(name); err != nil {
+		panic(err)
+	} else {
+		return v
+	}
+}
+
 // GetString returns the value of the named string flag ctx carries. It panics
 // in case ctx carries no flags or in case the named flag isn't a string one.
 func GetStringSlice(ctx context.Context, name string) []string {
@@ -64,6 +76,17 @@ func GetStringSlice(ctx context.Context, name string) []string {
 	}
 }
 
+// GetStringArray returns the values of the named string array flag ctx
+// carries. It panics in case ctx carries no flags or in case the named flag
+// isn't a string array one.
+func GetStringArray(ctx context.Context, name string) []string {
+	if v, err := FromContext(ctx).GetStringArray(name); err != nil {
+		panic(err)
+	} else {
+		return v
+	}
+}
+
 // GetBool returns the value of the named boolean flag ctx carries. It panics
 // in case ctx carries no flags or in case the named flag isn't a boolean one.
 func GetBool(ctx context.Context, name string) bool {
@@ -98,3 +121,35 @@ func GetApp(ctx context.Context) string {
 func GetAppConfigFilePath(ctx context.Context) string {
 	return GetString(ctx, AppConfigFilePathName)
 }
+
+// IsDefined reports whether the named flag is defined in the FlagSet ctx
+// carries.
+func IsDefined(ctx context.Context, name string) bool {
+	return FromContext(ctx).Lookup(name) != nil
+}
+
+// GetEnvironment returns the value of the environment flag ctx carries or an
+// empty string in case the flag isn't defined for the command.
+func GetEnvironment(ctx context.Context) string {
+	if !IsDefined(ctx, EnvironmentName) {
+		return ""
+	}
+
+	return GetString(ctx, EnvironmentName)
+}
+
+// GetVars returns the values of the var flag ctx carries or nil in case the
+// flag isn't defined for the command.
+func GetVars(ctx context.Context) []string {
+	if !IsDefined(ctx, VarName) {
+		return nil
+	}
+
+	return GetStringArray(ctx, VarName)
+}
+
+// GetStrictVars returns the value of the strict vars flag ctx carries or false
+// in case the flag isn't defined for the command.
+func GetStrictVars(ctx context.Context) bool {
+	return IsDefined(ctx, StrictVarsName) && GetBool(ctx, StrictVarsName)
+}
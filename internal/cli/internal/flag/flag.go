@@ -3,6 +3,7 @@ package flag
 
 import (
 	"context"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -14,9 +15,18 @@ const (
 	// VerboseName denotes the name of the verbose flag.
 	VerboseName = "verbose"
 
+	// QuietName denotes the name of the quiet flag.
+	QuietName = "quiet"
+
+	// DebugName denotes the name of the debug flag.
+	DebugName = "debug"
+
 	// JSONOutputName denotes the name of the json output flag.
 	JSONOutputName = "json"
 
+	// FormatName denotes the name of the format flag.
+	FormatName = "format"
+
 	// LocalOnlyName denotes the name of the local-only flag.
 	LocalOnlyName = "local-only"
 
@@ -35,6 +45,15 @@ const (
 	// AppConfigFilePathName denotes the name of the app config file path flag.
 	AppConfigFilePathName = "config"
 
+	// EnvironmentName denotes the name of the environment flag.
+	EnvironmentName = "env-name"
+
+	// VarName denotes the name of the var flag.
+	VarName = "var"
+
+	// StrictVarsName denotes the name of the strict vars flag.
+	StrictVarsName = "strict-vars"
+
 	// ImageName denotes the name of the image flag.
 	ImageName = "image"
 
@@ -49,6 +68,27 @@ const (
 
 	// DetachName denotes the name of the detach flag.
 	DetachName = "detach"
+
+	// ExperimentsName denotes the name of the experiments flag.
+	ExperimentsName = "experiments"
+
+	// ErrorJSONName denotes the name of the error json flag.
+	ErrorJSONName = "error-json"
+
+	// ExamplesName denotes the name of the examples flag.
+	ExamplesName = "examples"
+
+	// TimeoutName denotes the name of the timeout flag.
+	TimeoutName = "timeout"
+
+	// ContextName denotes the name of the context flag.
+	ContextName = "context"
+
+	// DebugHTTPName denotes the name of the debug http flag.
+	DebugHTTPName = "debug-http"
+
+	// DebugHTTPFileName denotes the name of the debug http file flag.
+	DebugHTTPFileName = "debug-http-file"
 )
 
 // Flag wraps the set of flags.
@@ -131,6 +171,28 @@ func (i Int) addTo(cmd *cobra.Command) {
 	f.Hidden = i.Hidden
 }
 
+// Duration wraps the set of duration flags.
+type Duration struct {
+	Name        string
+	Shorthand   string
+	Description string
+	Default     time.Duration
+	Hidden      bool
+}
+
+func (d Duration) addTo(cmd *cobra.Command) {
+	flags := cmd.Flags()
+
+	if d.Shorthand != "" {
+		_ = flags.DurationP(d.Name, d.Shorthand, d.Default, d.Description)
+	} else {
+		_ = flags.Duration(d.Name, d.Default, d.Description)
+	}
+
+	f := flags.Lookup(d.Name)
+	f.Hidden = d.Hidden
+}
+
 // StringSlice wraps the set of string slice flags.
 type StringSlice struct {
 	Name        string
@@ -151,6 +213,36 @@ func (ss StringSlice) addTo(cmd *cobra.Command) {
 	}
 }
 
+// StringArray wraps the set of string array flags. Unlike the values of
+// string slice flags, theirs aren't split on commas, so each occurrence of the
+// flag denotes exactly one value.
+type StringArray struct {
+	Name        string
+	Shorthand   string
+	Description string
+	Default     []string
+	ConfName    string
+	EnvName     string
+}
+
+func (sa StringArray) addTo(cmd *cobra.Command) {
+	flags := cmd.Flags()
+
+	if sa.Shorthand != "" {
+		_ = flags.StringArrayP(sa.Name, sa.Shorthand, sa.Default, sa.Description)
+	} else {
+		_ = flags.StringArray(sa.Name, sa.Default, sa.Description)
+	}
+}
+
+// Experiments returns an experiments string slice flag.
+func Experiments() StringSlice {
+	return StringSlice{
+		Name:        ExperimentsName,
+		Description: "Experiments to enable for this run, in addition to the ones enabled via settings",
+	}
+}
+
 // Org returns an org string flag.
 func Org() String {
 	return String{
@@ -196,6 +288,31 @@ func AppConfig() String {
 	}
 }
 
+// Environment returns an environment string flag.
+func Environment() String {
+	return String{
+		Name:        EnvironmentName,
+		Description: "Name of the environment whose overrides to apply to the application configuration. Defaults to FLY_ENV_NAME",
+	}
+}
+
+// Var returns a string array flag for the variables to interpolate into the
+// application configuration. Values may contain commas.
+func Var() StringArray {
+	return StringArray{
+		Name:        VarName,
+		Description: "Set of variables to interpolate into the application configuration in the form of NAME=VALUE pairs. Can be specified multiple times.",
+	}
+}
+
+// StrictVars returns a boolean flag for erroring on undefined variables.
+func StrictVars() Bool {
+	return Bool{
+		Name:        StrictVarsName,
+		Description: "Fail in case the application configuration references undefined variables",
+	}
+}
+
 // Image returns a Docker image config string flag.
 func Image() String {
 	return String{
@@ -266,6 +383,21 @@ func GetDetach(ctx context.Context) bool {
 	return GetBool(ctx, detachName)
 }
 
+const noCacheName = "no-cache"
+
+// NoCache returns a boolean flag for fetching from the API rather than
+// serving the offline cache of its responses
+func NoCache() Bool {
+	return Bool{
+		Name:        noCacheName,
+		Description: "Fetch from the API instead of serving cached responses",
+	}
+}
+
+func GetNoCache(ctx context.Context) bool {
+	return GetBool(ctx, noCacheName)
+}
+
 const buildOnlyName = "build-only"
 
 // BuildOnly returns a boolean flag for building without a deployment
@@ -16,6 +16,7 @@ import (
 	"github.com/superfly/flyctl/internal/cli/internal/config"
 	"github.com/superfly/flyctl/internal/cli/internal/sort"
 	"github.com/superfly/flyctl/internal/client"
+	"github.com/superfly/flyctl/internal/flyerr"
 )
 
 func String(ctx context.Context, dst *string, msg, def string, required bool) error {
@@ -105,6 +106,10 @@ func (e NonInteractiveError) Error() string { return string(e) }
 
 func (NonInteractiveError) Unwrap() error { return errNonInteractive }
 
+// ExitCode implements flyerr.ExitCoder; non-interactive runs lack input the
+// command required.
+func (NonInteractiveError) ExitCode() int { return flyerr.ExitCodeValidation }
+
 func newSurveyIO(ctx context.Context) (survey.AskOpt, error) {
 	io := iostreams.FromContext(ctx)
 	if !io.IsInteractive() {
@@ -12,10 +12,25 @@ import (
 )
 
 func AllocationStatuses(w io.Writer, title string, backupRegions []api.Region, statuses ...*api.AllocationStatus) error {
+	return SelectedAllocationStatuses(w, title, backupRegions, -1, statuses...)
+}
+
+// SelectedAllocationStatuses is like AllocationStatuses, but marks the status
+// at the given index as selected, as interactive views do.
+func SelectedAllocationStatuses(w io.Writer, title string, backupRegions []api.Region, selected int, statuses ...*api.AllocationStatus) error {
 	multipleVersions := hasMultipleVersions(statuses)
 
 	var rows [][]string
-	for _, alloc := range statuses {
+	for i, alloc := range statuses {
+		id := alloc.IDShort
+		if selected >= 0 {
+			if i == selected {
+				id = aurora.Bold("> " + id).String()
+			} else {
+				id = "  " + id
+			}
+		}
+
 		version := strconv.Itoa(alloc.Version)
 		if multipleVersions && alloc.LatestVersion {
 			version = version + " " + aurora.Green("⇡").String()
@@ -32,7 +47,7 @@ func AllocationStatuses(w io.Writer, title string, backupRegions []api.Region, s
 		}
 
 		rows = append(rows, []string{
-			alloc.IDShort,                        // ID,
+			id,                                   // ID,
 			alloc.TaskName,                       // Process
 			version,                              // Version
 			region,                               // Region
@@ -0,0 +1,129 @@
+package render
+
+import (
+	"io"
+	"math"
+	"strings"
+
+	"github.com/superfly/flyctl/internal/metrics"
+)
+
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as a line of block characters at most width wide,
+// averaging neighbouring values in case there are more of them than that.
+// Missing (NaN) values are rendered as spaces.
+func Sparkline(values []float64, width int) string {
+	values = downsample(values, width)
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		switch {
+		case math.IsNaN(v):
+			sb.WriteRune(' ')
+		case hi == lo:
+			sb.WriteRune(sparkRunes[0])
+		default:
+			i := int((v - lo) / (hi - lo) * float64(len(sparkRunes)-1))
+			sb.WriteRune(sparkRunes[i])
+		}
+	}
+
+	return sb.String()
+}
+
+func downsample(values []float64, width int) []float64 {
+	if width <= 0 || len(values) <= width {
+		return values
+	}
+
+	out := make([]float64, width)
+	for i := range out {
+		from, to := i*len(values)/width, (i+1)*len(values)/width
+
+		var sum float64
+		var n int
+		for _, v := range values[from:to] {
+			if !math.IsNaN(v) {
+				sum += v
+				n++
+			}
+		}
+
+		out[i] = math.NaN()
+		if n > 0 {
+			out[i] = sum / float64(n)
+		}
+	}
+
+	return out
+}
+
+// MetricsPanel renders the series of the given panel as a table of their
+// current, minimum, maximum and average values, along with a sparkline of
+// each in case sparkWidth is positive.
+func MetricsPanel(w io.Writer, panel metrics.Panel, series []metrics.Series, sparkWidth int) error {
+	cols := []string{"Series", "Current", "Min", "Max", "Avg"}
+	if sparkWidth > 0 {
+		cols = append(cols, "Trend")
+	}
+
+	rows := make([][]string, 0, len(series))
+	for i := range series {
+		s := &series[i]
+
+		lo, hi, avg := summarize(s.Values())
+
+		name := s.Name()
+		if name == "" {
+			name = "total"
+		}
+
+		row := []string{
+			name,
+			panel.Unit.Format(s.Last()),
+			panel.Unit.Format(lo),
+			panel.Unit.Format(hi),
+			panel.Unit.Format(avg),
+		}
+		if sparkWidth > 0 {
+			row = append(row, Sparkline(s.Values(), sparkWidth))
+		}
+
+		rows = append(rows, row)
+	}
+
+	return Table(w, panel.Title, rows, cols...)
+}
+
+// summarize returns the minimum, maximum and average of the values which are
+// not NaN; all three are NaN in case there are none.
+func summarize(values []float64) (lo, hi, avg float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+
+	var sum float64
+	var n int
+	for _, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+		sum += v
+		n++
+	}
+
+	if n == 0 {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+
+	return lo, hi, sum / float64(n)
+}
@@ -0,0 +1,18 @@
+package render
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "▁▄█", Sparkline([]float64{0, 5, 10}, 10))
+	assert.Equal(t, "▁▁", Sparkline([]float64{3, 3}, 10))
+	assert.Equal(t, "▁ █", Sparkline([]float64{1, math.NaN(), 2}, 10))
+
+	// neighbouring values are averaged down to the width
+	assert.Equal(t, "▁█", Sparkline([]float64{0, 2, 8, 10}, 2))
+	assert.Equal(t, "", Sparkline(nil, 10))
+}
@@ -81,9 +81,15 @@ func VerticalTable(w io.Writer, title string, objects [][]string, cols ...string
 	return nil
 }
 
+// NewTextBlock returns a text block which writes to the error stream ctx
+// carries, honoring its verbosity. In quiet mode, only the outcomes blocks
+// report via Done and Donef are written.
 func NewTextBlock(ctx context.Context, v ...interface{}) (tb *TextBlock) {
+	io := iostreams.FromContext(ctx)
+
 	tb = &TextBlock{
-		out: iostreams.FromContext(ctx).ErrOut,
+		out:       io.ErrOut,
+		verbosity: io.Verbosity(),
 	}
 
 	if len(v) > 0 {
@@ -94,19 +100,26 @@ func NewTextBlock(ctx context.Context, v ...interface{}) (tb *TextBlock) {
 }
 
 type TextBlock struct {
-	out io.Writer
+	out       io.Writer
+	verbosity iostreams.Verbosity
 }
 
 func (tb *TextBlock) Print(v ...interface{}) {
-	fmt.Fprint(tb.out, v...)
+	if tb.verbosity > iostreams.VerbosityQuiet {
+		fmt.Fprint(tb.out, v...)
+	}
 }
 
 func (tb *TextBlock) Println(v ...interface{}) {
-	fmt.Fprintln(tb.out, v...)
+	if tb.verbosity > iostreams.VerbosityQuiet {
+		fmt.Fprintln(tb.out, v...)
+	}
 }
 
 func (tb *TextBlock) Printf(format string, v ...interface{}) {
-	fmt.Fprintf(tb.out, format, v...)
+	if tb.verbosity > iostreams.VerbosityQuiet {
+		fmt.Fprintf(tb.out, format, v...)
+	}
 }
 
 // Detail prints to the output ctx carries. It behaves similarly to log.Print.
@@ -119,12 +132,24 @@ func (tb *TextBlock) Detailf(format string, v ...interface{}) {
 	tb.Detail(fmt.Sprintf(format, v...))
 }
 
+// Verbose is like Detail, but only prints in verbose mode.
+func (tb *TextBlock) Verbose(v ...interface{}) {
+	if tb.verbosity >= iostreams.VerbosityVerbose {
+		tb.Detail(v...)
+	}
+}
+
+// Verbosef is like Detailf, but only prints in verbose mode.
+func (tb *TextBlock) Verbosef(format string, v ...interface{}) {
+	tb.Verbose(fmt.Sprintf(format, v...))
+}
+
 func (tb *TextBlock) Overwrite() {
 	tb.Print(aec.Up(1), aec.EraseLine(aec.EraseModes.All))
 }
 
 func (tb *TextBlock) Done(v ...interface{}) {
-	tb.Println(aurora.Gray(20, "--> "+fmt.Sprint(v...)))
+	fmt.Fprintln(tb.out, aurora.Gray(20, "--> "+fmt.Sprint(v...)))
 }
 
 func (tb *TextBlock) Donef(format string, v ...interface{}) {
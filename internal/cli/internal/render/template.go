@@ -0,0 +1,33 @@
+package render
+
+import (
+	"context"
+	"io"
+	"text/template"
+
+	"github.com/superfly/flyctl/internal/cli/internal/config"
+	"github.com/superfly/flyctl/internal/outputtemplate"
+)
+
+// ParseTemplate parses the given output template, like the ones the --format
+// flag accepts.
+func ParseTemplate(text string) (*template.Template, error) {
+	return outputtemplate.Parse(text)
+}
+
+// Template renders v into w through the given Go template. Output which does
+// not end in a newline is terminated with one.
+func Template(w io.Writer, text string, v interface{}) error {
+	return outputtemplate.Execute(w, text, v)
+}
+
+// Structured renders v into w through the format template the config ctx
+// carries denotes, or as JSON in case there's none. Commands call it for the
+// structured data they output when the config's StructuredOutput is set.
+func Structured(ctx context.Context, w io.Writer, v interface{}) error {
+	if format := config.FromContext(ctx).Format; format != "" {
+		return Template(w, format, v)
+	}
+
+	return JSON(w, v)
+}
@@ -0,0 +1,39 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate(t *testing.T) {
+	type release struct {
+		Version int
+		Status  string
+		Tags    []string
+	}
+
+	cases := []struct {
+		format string
+		v      interface{}
+		output string
+	}{
+		{"{{.Version}}", release{Version: 3}, "3\n"},
+		{"{{.Version}} {{upper .Status}}\n", release{Version: 2, Status: "succeeded"}, "2 SUCCEEDED\n"},
+		{`{{join .Tags ","}}`, release{Tags: []string{"a", "b"}}, "a,b\n"},
+		{"{{range .}}{{.Version}}\n{{end}}", []release{{Version: 1}, {Version: 2}}, "1\n2\n"},
+		{"{{json .}}", map[string]int{"version": 1}, "{\"version\":1}\n"},
+		{"{{range .}}{{end}}", []release{}, ""},
+	}
+
+	for _, c := range cases {
+		var buf bytes.Buffer
+		require.NoError(t, Template(&buf, c.format, c.v), c.format)
+		assert.Equal(t, c.output, buf.String(), c.format)
+	}
+
+	assert.Error(t, Template(&bytes.Buffer{}, "{{.Version", release{}))
+	assert.Error(t, Template(&bytes.Buffer{}, "{{.Missing}}", release{}))
+}
@@ -0,0 +1,203 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/morikuni/aec"
+	"golang.org/x/term"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+)
+
+// Key denotes a keystroke a Screen reads. Printable keys are denoted by their
+// rune; the rest by the negative constants below.
+type Key rune
+
+const (
+	KeyUp Key = -(iota + 1)
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyEnter
+	KeyBackspace
+	KeyEscape
+	KeyInterrupt
+)
+
+// parseKeys returns the keystrokes the given input of a terminal in raw mode
+// denotes.
+func parseKeys(b []byte) (keys []Key) {
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b && len(b) >= 3 && (b[1] == '[' || b[1] == 'O'):
+			switch b[2] {
+			case 'A':
+				keys = append(keys, KeyUp)
+			case 'B':
+				keys = append(keys, KeyDown)
+			case 'C':
+				keys = append(keys, KeyRight)
+			case 'D':
+				keys = append(keys, KeyLeft)
+			}
+			b = b[3:]
+
+			continue
+		case c == 0x1b:
+			keys = append(keys, KeyEscape)
+		case c == '\r' || c == '\n':
+			keys = append(keys, KeyEnter)
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, KeyBackspace)
+		case c == 0x03:
+			keys = append(keys, KeyInterrupt)
+		case c < utf8.RuneSelf:
+			keys = append(keys, Key(c))
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, Key(r))
+			b = b[size:]
+
+			continue
+		}
+
+		b = b[1:]
+	}
+
+	return
+}
+
+// Screen is a minimal full screen terminal UI. It redraws whole frames on the
+// alternate screen of the terminal and, when stdin is a terminal too, reads
+// keystrokes by putting it in raw mode.
+type Screen struct {
+	out     io.Writer
+	outFd   int
+	raw     bool
+	keys    chan Key
+	restore func()
+}
+
+// NewScreen initializes and returns a Screen which draws on the output ctx
+// carries. Callers must Close the Screen to restore the terminal.
+func NewScreen(ctx context.Context) (*Screen, error) {
+	io := iostreams.FromContext(ctx)
+	if !io.IsStdoutTTY() {
+		return nil, errors.New("output is not a terminal")
+	}
+
+	s := &Screen{
+		out:     io.Out,
+		outFd:   int(io.StdoutFd()),
+		keys:    make(chan Key, 16),
+		restore: func() {},
+	}
+
+	if in, ok := io.In.(*os.File); ok && term.IsTerminal(int(in.Fd())) {
+		fd := int(in.Fd())
+
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return nil, fmt.Errorf("failed putting the terminal in raw mode: %w", err)
+		}
+
+		s.raw = true
+		s.restore = func() { _ = term.Restore(fd, state) }
+
+		go s.read(in)
+	}
+
+	// switch to the alternate screen, so that the terminal gets its contents
+	// back once the screen is closed
+	fmt.Fprint(s.out, "\x1b[?1049h", aec.Hide)
+
+	return s, nil
+}
+
+func (s *Screen) read(in io.Reader) {
+	buf := make([]byte, 64)
+
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for _, k := range parseKeys(buf[:n]) {
+			select {
+			case s.keys <- k:
+			default: // keys the UI can't keep up with are dropped
+			}
+		}
+	}
+}
+
+// Keys returns the channel keystrokes are delivered on. The channel never
+// delivers in case stdin isn't a terminal.
+//
+// Since the terminal is in raw mode, Ctrl+C is delivered as KeyInterrupt
+// instead of interrupting the process.
+func (s *Screen) Keys() <-chan Key {
+	return s.keys
+}
+
+// Draw replaces the contents of the screen with the given frame, the lines of
+// which don't fit the terminal being cut off.
+func (s *Screen) Draw(frame string) {
+	lines := strings.Split(strings.TrimSuffix(frame, "\n"), "\n")
+	if _, height, err := term.GetSize(s.outFd); err == nil && height > 0 && len(lines) > height {
+		lines = lines[:height]
+	}
+
+	// raw mode disables the translation of line feeds to carriage returns
+	sep := "\n"
+	if s.raw {
+		sep = "\r\n"
+	}
+
+	fmt.Fprint(s.out, aec.Position(1, 1).With(aec.EraseDisplay(aec.EraseModes.All)), strings.Join(lines, sep))
+}
+
+// Close restores the terminal to the state it was in before the screen was
+// initialized.
+func (s *Screen) Close() {
+	fmt.Fprint(s.out, aec.Show, "\x1b[?1049l")
+
+	s.restore()
+}
+
+// LiveRegion redraws a block of lines of a TextBlock in place, below what the
+// block wrote before it, as deployment monitoring does with its summaries.
+type LiveRegion struct {
+	tb    *TextBlock
+	lines int
+}
+
+// Live returns a LiveRegion which writes to tb.
+func (tb *TextBlock) Live() *LiveRegion {
+	return &LiveRegion{tb: tb}
+}
+
+// Draw replaces what the region last drew with v.
+func (r *LiveRegion) Draw(v ...interface{}) {
+	for ; r.lines > 0; r.lines-- {
+		r.tb.Print(aec.Up(1).With(aec.EraseLine(aec.EraseModes.All)))
+	}
+
+	s := strings.TrimSuffix(fmt.Sprint(v...), "\n")
+
+	r.tb.Println(s)
+	r.lines = strings.Count(s, "\n") + 1
+}
+
+// Release keeps what the region last drew, so that the next Draw writes below
+// it and whatever was written since.
+func (r *LiveRegion) Release() {
+	r.lines = 0
+}
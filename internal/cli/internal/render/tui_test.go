@@ -0,0 +1,45 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/morikuni/aec"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+)
+
+func TestParseKeys(t *testing.T) {
+	cases := map[string][]Key{
+		"q":            {'q'},
+		"\x1b[A\x1b[B": {KeyUp, KeyDown},
+		"\x1bOC\x1b[D": {KeyRight, KeyLeft},
+		"\x1b":         {KeyEscape},
+		"\r\x7f\x03":   {KeyEnter, KeyBackspace, KeyInterrupt},
+		"jé":           {'j', 'é'},
+		"\x1b[Zk":      {'k'},
+		"":             nil,
+	}
+
+	for input, keys := range cases {
+		assert.Equal(t, keys, parseKeys([]byte(input)), "%q", input)
+	}
+}
+
+func TestLiveRegion(t *testing.T) {
+	var buf bytes.Buffer
+	tb := &TextBlock{out: &buf, verbosity: iostreams.VerbosityNormal}
+
+	live := tb.Live()
+	live.Draw("a\nb")
+	live.Draw("c")
+
+	erase := aec.Up(1).String() + aec.EraseLine(aec.EraseModes.All).String()
+	assert.Equal(t, "a\nb\n"+erase+erase+"c\n", buf.String())
+
+	buf.Reset()
+	live.Release()
+	live.Draw("d")
+	assert.Equal(t, "d\n", buf.String())
+}
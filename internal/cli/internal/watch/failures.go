@@ -0,0 +1,148 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/pkg/iostreams"
+
+	"github.com/superfly/flyctl/internal/cli/internal/app"
+	"github.com/superfly/flyctl/internal/client"
+)
+
+// failureLogLimit is the number of recent log lines rendered for each
+// allocation which fails.
+const failureLogLimit = 30
+
+// failureReporter renders the recent logs and exit code of the allocations of
+// a deployment as they crash or start failing their health checks, so that
+// failures may be diagnosed without tailing the logs of the app.
+type failureReporter struct {
+	seen map[string]allocFailure
+}
+
+func newFailureReporter() *failureReporter {
+	return &failureReporter{
+		seen: map[string]allocFailure{},
+	}
+}
+
+// allocFailure wraps the ways an allocation fails.
+type allocFailure struct {
+	restarts int
+	failed   bool
+	critical bool
+}
+
+func failureOf(alloc *api.AllocationStatus) allocFailure {
+	return allocFailure{
+		restarts: alloc.Restarts,
+		failed:   alloc.Failed || alloc.Status == "failed",
+		critical: alloc.CriticalCheckCount > 0,
+	}
+}
+
+// worseThan reports whether f denotes a failure prev doesn't.
+func (f allocFailure) worseThan(prev allocFailure) bool {
+	return f.restarts > prev.restarts ||
+		(f.failed && !prev.failed) ||
+		(f.critical && !prev.critical)
+}
+
+func (f allocFailure) String() string {
+	switch {
+	case f.failed:
+		return "failed"
+	case f.restarts > 0 && f.critical:
+		return fmt.Sprintf("crashed (%d restarts) and is failing health checks", f.restarts)
+	case f.restarts > 0:
+		return fmt.Sprintf("crashed (%d restarts)", f.restarts)
+	default:
+		return "is failing health checks"
+	}
+}
+
+// failing returns the given allocations which failed in a way they hadn't
+// when last seen, along with their failures.
+func (r *failureReporter) failing(allocs []*api.AllocationStatus) (failing []*api.AllocationStatus, failures []allocFailure) {
+	for _, alloc := range allocs {
+		f := failureOf(alloc)
+
+		if prev := r.seen[alloc.ID]; f.worseThan(prev) {
+			failing = append(failing, alloc)
+			failures = append(failures, f)
+		}
+
+		r.seen[alloc.ID] = f
+	}
+
+	return
+}
+
+// report renders the failures of the given allocations which failed since
+// last seen. It returns whether it rendered anything.
+func (r *failureReporter) report(ctx context.Context, allocs []*api.AllocationStatus) (reported bool) {
+	failing, failures := r.failing(allocs)
+
+	var (
+		io      = iostreams.FromContext(ctx)
+		appName = app.NameFromContext(ctx)
+		client  = client.FromContext(ctx).API()
+	)
+
+	for i, a := range failing {
+		reported = true
+
+		fmt.Fprintf(io.ErrOut, "\nInstance %s %s\n", a.IDShort, failures[i])
+
+		alloc, err := client.GetAllocationStatus(ctx, appName, a.ID, failureLogLimit)
+		if err != nil {
+			fmt.Fprintf(io.ErrOut, "failed fetching alloc %s: %s\n", a.ID, err)
+
+			continue
+		}
+
+		if code, ok := exitCodeOf(alloc.Events); ok {
+			fmt.Fprintf(io.ErrOut, "Exit code: %d\n", code)
+		}
+
+		for _, check := range alloc.Checks {
+			if check.Status == "critical" {
+				fmt.Fprintf(io.ErrOut, "Check %s failed: %s\n", check.Name, check.Output)
+			}
+		}
+
+		if len(alloc.RecentLogs) > 0 {
+			fmt.Fprintln(io.ErrOut, "Recent logs:")
+			RenderLogs(ctx, alloc)
+		}
+
+		fmt.Fprintln(io.ErrOut)
+	}
+
+	return
+}
+
+var exitCodePattern = regexp.MustCompile(`(?i)exit code:?\s*(-?\d+)`)
+
+// exitCodeOf returns the exit code the latest of the given events which
+// tells of one does.
+func exitCodeOf(events []api.AllocationEvent) (code int, ok bool) {
+	var latest api.AllocationEvent
+
+	for _, e := range events {
+		m := exitCodePattern.FindStringSubmatch(e.Message)
+		if m == nil || (ok && e.Timestamp.Before(latest.Timestamp)) {
+			continue
+		}
+
+		if c, err := strconv.Atoi(m[1]); err == nil {
+			code, ok, latest = c, true, e
+		}
+	}
+
+	return
+}
@@ -0,0 +1,54 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestFailureReporterFailing(t *testing.T) {
+	r := newFailureReporter()
+
+	healthy := &api.AllocationStatus{ID: "a", Status: "running"}
+	failing, _ := r.failing([]*api.AllocationStatus{healthy})
+	assert.Empty(t, failing)
+
+	crashed := &api.AllocationStatus{ID: "a", Status: "running", Restarts: 1}
+	failing, failures := r.failing([]*api.AllocationStatus{crashed})
+	assert.Equal(t, []*api.AllocationStatus{crashed}, failing)
+	assert.Equal(t, "crashed (1 restarts)", failures[0].String())
+
+	// the same crash is only reported once
+	failing, _ = r.failing([]*api.AllocationStatus{crashed})
+	assert.Empty(t, failing)
+
+	critical := &api.AllocationStatus{ID: "a", Status: "running", Restarts: 1, CriticalCheckCount: 1}
+	failing, failures = r.failing([]*api.AllocationStatus{critical})
+	assert.Len(t, failing, 1)
+	assert.Equal(t, "crashed (1 restarts) and is failing health checks", failures[0].String())
+
+	failed := &api.AllocationStatus{ID: "b", Status: "failed"}
+	failing, failures = r.failing([]*api.AllocationStatus{critical, failed})
+	assert.Equal(t, []*api.AllocationStatus{failed}, failing)
+	assert.Equal(t, "failed", failures[0].String())
+}
+
+func TestExitCodeOf(t *testing.T) {
+	now := time.Now()
+
+	_, ok := exitCodeOf([]api.AllocationEvent{
+		{Timestamp: now, Type: "Started", Message: "Task started by client"},
+	})
+	assert.False(t, ok)
+
+	code, ok := exitCodeOf([]api.AllocationEvent{
+		{Timestamp: now.Add(-time.Minute), Type: "Terminated", Message: "Exit Code: 137"},
+		{Timestamp: now, Type: "Terminated", Message: "Exit Code: 1, Exit Message: \"\""},
+		{Timestamp: now.Add(time.Second), Type: "Restarting", Message: "Task restarting in 1s"},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, 1, code)
+}
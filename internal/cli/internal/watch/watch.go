@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/azazeal/pause"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/superfly/flyctl/api"
@@ -25,8 +26,30 @@ import (
 	"github.com/superfly/flyctl/internal/spinner"
 )
 
-func Deployment(ctx context.Context, evaluationID string) error {
+// RollbackError is returned when a deployment failed and the platform rolls
+// the app back to its last stable release.
+type RollbackError struct {
+	// Version denotes the version of the failed release.
+	Version int
+}
+
+func (e *RollbackError) Error() string {
+	return fmt.Sprintf("v%d failed and is being rolled back", e.Version)
+}
+
+func (*RollbackError) Unwrap() error {
+	return flyerr.ErrAbort
+}
+
+// Deployment monitors the deployment the given evaluation started. When given
+// process groups, only their instances are reported.
+func Deployment(ctx context.Context, evaluationID string, groups ...string) error {
 	tb := render.NewTextBlock(ctx, "Monitoring deployment")
+	if len(groups) > 0 {
+		tb.Detailf("Watching the %s process groups", strings.Join(groups, ", "))
+	}
+	ofGroups := groupFilter(groups)
+	var rollback *RollbackError
 
 	io := iostreams.FromContext(ctx)
 	appName := app.NameFromContext(ctx)
@@ -35,27 +58,43 @@ func Deployment(ctx context.Context, evaluationID string) error {
 
 	monitor := deployment.NewDeploymentMonitor(client, appName, evaluationID)
 
+	// the live summary of the deployment replaces its opening summary
+	live := tb.Live()
+
 	monitor.DeploymentStarted = func(idx int, d *api.DeploymentStatus) error {
 		if idx > 0 {
+			live.Release()
 			tb.Println()
 		}
-		tb.Println(format.DeploymentSummary(d))
+		live.Draw(format.DeploymentSummary(d))
 
 		return nil
 	}
 
+	failures := newFailureReporter()
+
 	// TODO check we aren't asking for JSON
 	monitor.DeploymentUpdated = func(d *api.DeploymentStatus, updatedAllocs []*api.AllocationStatus) error {
-		if io.IsInteractive() {
-			tb.Overwrite()
+		updatedAllocs = ofGroups(updatedAllocs)
 
-			tb.Println(format.DeploymentAllocSummary(d))
+		// verbose output keeps a line per update instead of a live summary
+		interactive := io.IsInteractive() && !io.IsVerbose()
+
+		if interactive {
+			live.Draw(format.DeploymentAllocSummary(d))
 		} else {
 			for _, alloc := range updatedAllocs {
 				tb.Println(format.AllocSummary(alloc))
 			}
 		}
 
+		// instances which crash or fail their checks are reported as they do,
+		// after which the live summary is printed anew below the report
+		if failures.report(ctx, updatedAllocs) && interactive {
+			live.Release()
+			live.Draw(format.DeploymentAllocSummary(d))
+		}
+
 		return nil
 	}
 
@@ -67,11 +106,13 @@ func Deployment(ctx context.Context, evaluationID string) error {
 				endmessage = fmt.Sprintf("v%d %s - %s\n", d.Version, d.Status, d.Description)
 			} else {
 				endmessage = fmt.Sprintf("v%d %s - %s and deploying as v%d \n", d.Version, d.Status, d.Description, d.Version+1)
+				rollback = &RollbackError{Version: d.Version}
 			}
 		}
 
-		if len(failedAllocs) > 0 {
-			tb.Println("Failed Instances")
+		if failedAllocs = ofGroups(failedAllocs); len(failedAllocs) > 0 {
+			// failures are reported regardless of verbosity
+			fmt.Fprintln(io.ErrOut, "Failed Instances")
 
 			x := make(chan *api.AllocationStatus)
 			var wg sync.WaitGroup
@@ -83,7 +124,7 @@ func Deployment(ctx context.Context, evaluationID string) error {
 					defer wg.Done()
 					alloc, err := client.GetAllocationStatus(ctx, appName, a.ID, 30)
 					if err != nil {
-						tb.Printf("failed fetching alloc %s: %s", a.ID, err)
+						fmt.Fprintf(io.ErrOut, "failed fetching alloc %s: %s", a.ID, err)
 
 						return
 					}
@@ -100,9 +141,7 @@ func Deployment(ctx context.Context, evaluationID string) error {
 			for alloc := range x {
 				count++
 
-				tb.Println()
-				tb.Printf("Failure #%d\n", count)
-				tb.Println()
+				fmt.Fprintf(io.ErrOut, "\nFailure #%d\n\n", count)
 
 				if err := render.AllocationStatuses(io.Out, "Instance", []api.Region{}, alloc); err != nil {
 					return fmt.Errorf("failed rendering alloc status: %w", err)
@@ -112,7 +151,7 @@ func Deployment(ctx context.Context, evaluationID string) error {
 					return fmt.Errorf("failed rendering recent events: %w", err)
 				}
 
-				renderLogs(ctx, alloc)
+				RenderLogs(ctx, alloc)
 			}
 		}
 
@@ -137,12 +176,39 @@ func Deployment(ctx context.Context, evaluationID string) error {
 
 	if !monitor.Success() {
 		tb.Done("Troubleshooting guide at https://fly.io/docs/getting-started/troubleshooting/")
-		return flyerr.ErrAbort
+		if rollback != nil {
+			return &flyerr.HealthCheckError{Err: rollback}
+		}
+
+		return &flyerr.HealthCheckError{Err: flyerr.ErrAbort}
 	}
 
 	return nil
 }
 
+// groupFilter returns a function which filters allocations down to the ones of
+// the given process groups, or keeps them all in case there are none.
+func groupFilter(groups []string) func([]*api.AllocationStatus) []*api.AllocationStatus {
+	if len(groups) == 0 {
+		return func(allocs []*api.AllocationStatus) []*api.AllocationStatus { return allocs }
+	}
+
+	selected := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		selected[group] = true
+	}
+
+	return func(allocs []*api.AllocationStatus) (ret []*api.AllocationStatus) {
+		for _, alloc := range allocs {
+			if selected[alloc.TaskName] {
+				ret = append(ret, alloc)
+			}
+		}
+
+		return
+	}
+}
+
 func ReleaseCommand(ctx context.Context, id string) error {
 	g, ctx := errgroup.WithContext(ctx)
 	io := iostreams.FromContext(ctx)
@@ -207,6 +273,10 @@ func ReleaseCommand(ctx context.Context, id string) error {
 				return rc, err
 			}()
 			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+
 				if err == context.DeadlineExceeded {
 					// don't increment error count if this is a timeout
 					continue
@@ -227,7 +297,7 @@ func ReleaseCommand(ctx context.Context, id string) error {
 				break
 			}
 
-			time.Sleep(500 * time.Millisecond)
+			pause.For(ctx, 500*time.Millisecond)
 		}
 
 		return nil
@@ -252,7 +322,9 @@ func ReleaseCommand(ctx context.Context, id string) error {
 				if rc.Succeeded && interactive {
 					s.StopWithMessage("Running release task... Done.")
 				} else if rc.Failed {
-					return fmt.Errorf("release command failed, deployment aborted")
+					return &flyerr.ReleaseCommandError{
+						Err: errors.New("release command failed, deployment aborted"),
+					}
 				}
 			}
 		}
@@ -263,7 +335,8 @@ func ReleaseCommand(ctx context.Context, id string) error {
 	return g.Wait()
 }
 
-func renderLogs(ctx context.Context, alloc *api.AllocationStatus) {
+// RenderLogs renders the recent logs of the given allocation.
+func RenderLogs(ctx context.Context, alloc *api.AllocationStatus) {
 	out := iostreams.FromContext(ctx).Out
 	cfg := config.FromContext(ctx)
 
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/superfly/flyctl/internal/flyerr"
+
+	"github.com/superfly/flyctl/internal/cli/internal/flag"
+)
+
+const (
+	// timeoutEnvKey is the environment variable which, when set, has commands
+	// time out like --timeout does.
+	timeoutEnvKey = "FLY_TIMEOUT"
+
+	// timeoutGrace is how long commands which timed out are given to abort
+	// and clean up before they're abandoned.
+	timeoutGrace = 5 * time.Second
+)
+
+// commandTimeout returns the --timeout args give the command they invoke, or
+// 0 in case they give none. Commands which define a timeout flag of their own
+// get none.
+//
+// The flag is looked for before cobra parses it, since the context commands
+// run with may not change afterwards.
+func commandTimeout(root *cobra.Command, args []string) (time.Duration, error) {
+	if c, _, err := root.Find(args); err == nil {
+		if f := c.Flags().Lookup(flag.TimeoutName); f != nil && f != root.PersistentFlags().Lookup(flag.TimeoutName) {
+			return 0, nil
+		}
+	}
+
+	value := os.Getenv(timeoutEnvKey)
+
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		if arg == "--"+flag.TimeoutName && i+1 < len(args) {
+			value = args[i+1]
+		} else if v := strings.TrimPrefix(arg, "--"+flag.TimeoutName+"="); v != arg {
+			value = v
+		}
+	}
+
+	if value == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return 0, &flyerr.ValidationError{
+			Err: fmt.Errorf("invalid --%s %q; use a positive duration, e.g. 10m", flag.TimeoutName, value),
+		}
+	}
+
+	return timeout, nil
+}
+
+// execute executes root with ctx, timing it out after timeout unless it's 0.
+// Commands which time out are given timeoutGrace to return before they're
+// abandoned, so that commands stuck on operations which don't honor their
+// context still abort.
+func execute(ctx context.Context, root *cobra.Command, timeout time.Duration) (*cobra.Command, error) {
+	if timeout == 0 {
+		return root.ExecuteContextC(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		cmd *cobra.Command
+		err error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		cmd, err := root.ExecuteContextC(ctx)
+		done <- result{cmd, err}
+	}()
+
+	var res result
+
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		if ctx.Err() != context.DeadlineExceeded {
+			res = <-done // interrupted; commands abort on their own
+
+			break
+		}
+
+		select {
+		case res = <-done:
+		case <-time.After(timeoutGrace):
+			return root, &flyerr.TimeoutError{Timeout: timeout}
+		}
+	}
+
+	if res.err != nil && ctx.Err() == context.DeadlineExceeded {
+		res.err = &flyerr.TimeoutError{Timeout: timeout, Err: res.err}
+	}
+
+	return res.cmd, res.err
+}
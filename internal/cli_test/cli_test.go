@@ -42,3 +42,21 @@ func capture(ctx context.Context, t *testing.T, args ...string) (stdout, stderr
 
 	return
 }
+
+func TestInvalidTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, stderr, code := capture(ctx, t, "version", "--timeout", "soon")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `invalid --timeout "soon"`)
+}
+
+func TestCommandTimeoutFlag(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// machine exec takes a --timeout of its own, in seconds
+	_, stderr, _ := capture(ctx, t, "machine", "exec", "--timeout", "30", "--help")
+	assert.NotContains(t, stderr, "invalid --timeout")
+}
@@ -6,11 +6,14 @@ import (
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/flyctl"
 	"github.com/superfly/flyctl/internal/buildinfo"
+	"github.com/superfly/flyctl/internal/flyerr"
 	"github.com/superfly/flyctl/pkg/iostreams"
 	"github.com/superfly/flyctl/terminal"
 )
 
-var ErrNoAuthToken = errors.New("No access token available. Please login with 'flyctl auth login'")
+var ErrNoAuthToken error = &flyerr.AuthError{
+	Err: errors.New("No access token available. Please login with 'flyctl auth login'"),
+}
 
 func New() *Client {
 	client := &Client{
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/env"
+)
+
+// OIDCAudience is the audience the OIDC ID tokens of CI jobs have to be
+// issued for.
+const OIDCAudience = "https://fly.io"
+
+const (
+	// IDTokenEnvKey names the variable which carries the ID token of the job,
+	// as GitLab CI sets it when the pipeline declares it under id_tokens.
+	IDTokenEnvKey = "FLY_ID_TOKEN"
+
+	githubActionsEnvKey     = "GITHUB_ACTIONS"
+	githubRequestURLEnvKey  = "ACTIONS_ID_TOKEN_REQUEST_URL"
+	githubRequestAuthEnvKey = "ACTIONS_ID_TOKEN_REQUEST_TOKEN"
+	gitlabCIEnvKey          = "GITLAB_CI"
+)
+
+// ErrNoOIDCProvider is returned when the ID token of the CI job may not be
+// retrieved.
+var ErrNoOIDCProvider = errors.New("no OIDC ID token is available; run in GitHub Actions with the id-token: write permission, or in GitLab CI with FLY_ID_TOKEN declared under id_tokens")
+
+// OIDCAvailable reports whether the environment is a CI job the ID token of
+// which may be retrieved.
+func OIDCAvailable() bool {
+	return env.First(IDTokenEnvKey) != "" || env.First(githubRequestURLEnvKey) != ""
+}
+
+// ExchangeOIDCToken retrieves the OIDC ID token of the CI job and exchanges it
+// for a short-lived access token to the named organization.
+func ExchangeOIDCToken(ctx context.Context, orgSlug string) (*api.OIDCToken, error) {
+	idToken, err := FetchIDToken(ctx, http.DefaultClient)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := api.ExchangeOIDCToken(ctx, idToken, orgSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed exchanging the OIDC ID token for an access token to %s: %w", orgSlug, err)
+	}
+
+	return token, nil
+}
+
+// FetchIDToken returns the OIDC ID token of the CI job, issued for
+// OIDCAudience. FLY_ID_TOKEN takes precedence; GitHub Actions tokens are
+// requested from the runner.
+func FetchIDToken(ctx context.Context, httpClient *http.Client) (string, error) {
+	if token := env.First(IDTokenEnvKey); token != "" {
+		return token, nil
+	}
+
+	requestURL := env.First(githubRequestURLEnvKey)
+	switch {
+	case requestURL != "":
+		return fetchGitHubIDToken(ctx, httpClient, requestURL, env.First(githubRequestAuthEnvKey))
+	case env.IsTruthy(githubActionsEnvKey):
+		return "", errors.New("GitHub Actions issues no OIDC ID token to jobs without the id-token: write permission")
+	case env.IsTruthy(gitlabCIEnvKey):
+		return "", fmt.Errorf("GitLab CI issues no OIDC ID token unless the job declares %s under id_tokens, with aud %s", IDTokenEnvKey, OIDCAudience)
+	default:
+		return "", ErrNoOIDCProvider
+	}
+}
+
+func fetchGitHubIDToken(ctx context.Context, httpClient *http.Client, requestURL, requestToken string) (string, error) {
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", githubRequestURLEnvKey, err)
+	}
+
+	q := u.Query()
+	q.Set("audience", OIDCAudience)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "bearer "+requestToken)
+	req.Header.Set("Accept", "application/json")
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed requesting the OIDC ID token of the job: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+
+		return "", fmt.Errorf("failed requesting the OIDC ID token of the job: %s %s", res.Status, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("failed decoding the OIDC ID token of the job: %w", err)
+	} else if payload.Value == "" {
+		return "", errors.New("the runner returned an empty OIDC ID token")
+	}
+
+	return payload.Value, nil
+}
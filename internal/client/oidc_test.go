@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clearOIDCEnv(t *testing.T) {
+	for _, key := range []string{IDTokenEnvKey, githubActionsEnvKey, githubRequestURLEnvKey, githubRequestAuthEnvKey, gitlabCIEnvKey} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestFetchIDTokenFromGitHub(t *testing.T) {
+	clearOIDCEnv(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bearer request-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "1", r.URL.Query().Get("api-version"))
+		assert.Equal(t, OIDCAudience, r.URL.Query().Get("audience"))
+
+		w.Write([]byte(`{"value":"id-token"}`))
+	}))
+	defer srv.Close()
+
+	t.Setenv(githubActionsEnvKey, "true")
+	t.Setenv(githubRequestURLEnvKey, srv.URL+"/token?api-version=1")
+	t.Setenv(githubRequestAuthEnvKey, "request-token")
+	assert.True(t, OIDCAvailable())
+
+	token, err := FetchIDToken(context.Background(), srv.Client())
+	require.NoError(t, err)
+	assert.Equal(t, "id-token", token)
+}
+
+func TestFetchIDTokenFromEnv(t *testing.T) {
+	clearOIDCEnv(t)
+	t.Setenv(gitlabCIEnvKey, "true")
+
+	_, err := FetchIDToken(context.Background(), http.DefaultClient)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "id_tokens")
+	assert.False(t, OIDCAvailable())
+
+	t.Setenv(IDTokenEnvKey, "gitlab-token")
+
+	token, err := FetchIDToken(context.Background(), http.DefaultClient)
+	require.NoError(t, err)
+	assert.Equal(t, "gitlab-token", token)
+}
+
+func TestFetchIDTokenWithoutProvider(t *testing.T) {
+	clearOIDCEnv(t)
+
+	_, err := FetchIDToken(context.Background(), http.DefaultClient)
+	assert.ErrorIs(t, err, ErrNoOIDCProvider)
+}
@@ -0,0 +1,27 @@
+package cmdutil
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseDays parses a positive number of days, given either in days, e.g. 90d,
+// or as a duration, e.g. 720h, which is rounded up to whole days.
+func ParseDays(s string) (int, error) {
+	if v := strings.TrimSuffix(s, "d"); v != s {
+		if days, err := strconv.Atoi(v); err == nil && days > 0 {
+			return days, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, errors.New("use a number of days or a duration, e.g. 90d or 720h")
+	}
+
+	const day = 24 * time.Hour
+
+	return int((d + day - 1) / day), nil
+}
@@ -0,0 +1,28 @@
+package cmdutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDays(t *testing.T) {
+	cases := map[string]int{
+		"90d":  90,
+		"720h": 30,
+		"25h":  2,
+		"1m":   1,
+	}
+
+	for s, expected := range cases {
+		days, err := ParseDays(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected, days, s)
+		}
+	}
+
+	for _, s := range []string{"", "0d", "-1h", "ninety"} {
+		_, err := ParseDays(s)
+		assert.Error(t, err, s)
+	}
+}
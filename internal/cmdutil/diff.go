@@ -0,0 +1,85 @@
+package cmdutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+)
+
+// DiffDefinitions returns the unified diff between the normalized TOML
+// representations of the from and to app definitions. It returns an empty
+// string in case the definitions are equivalent.
+func DiffDefinitions(fromName string, from map[string]interface{}, toName string, to map[string]interface{}) (string, error) {
+	a, err := normalizeDefinition(from)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := normalizeDefinition(to)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(a),
+		B:        difflib.SplitLines(b),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  3,
+	})
+}
+
+// normalizeDefinition renders def as TOML with its keys sorted and its numbers
+// formatted consistently, regardless of whether def was decoded from TOML or
+// JSON.
+func normalizeDefinition(def map[string]interface{}) (string, error) {
+	if len(def) == 0 {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(def); err != nil {
+		return "", err
+	}
+
+	var normalized map[string]interface{}
+
+	dec := json.NewDecoder(&buf)
+	dec.UseNumber()
+	if err := dec.Decode(&normalized); err != nil {
+		return "", err
+	}
+
+	buf.Reset()
+	if err := toml.NewEncoder(&buf).Encode(normalized); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// ColorizeDiff colors the added and removed lines of the given unified diff
+// according to cs.
+func ColorizeDiff(diff string, cs *iostreams.ColorScheme) string {
+	lines := strings.SplitAfter(diff, "\n")
+
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			lines[i] = cs.Bold(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = cs.Green(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = cs.Red(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = cs.Cyan(line)
+		}
+	}
+
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,50 @@
+package cmdutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/pkg/iostreams"
+)
+
+func TestDiffDefinitions(t *testing.T) {
+	// numbers decoded from JSON and TOML compare equal
+	from := map[string]interface{}{
+		"app":      "web",
+		"env":      map[string]interface{}{"PORT": "8080"},
+		"services": []interface{}{map[string]interface{}{"internal_port": float64(8080)}},
+	}
+	to := map[string]interface{}{
+		"services": []interface{}{map[string]interface{}{"internal_port": int64(8080)}},
+		"env":      map[string]interface{}{"PORT": "8080"},
+		"app":      "web",
+	}
+
+	diff, err := DiffDefinitions("deployed", from, "fly.toml", to)
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+
+	to["env"] = map[string]interface{}{"PORT": "9090"}
+
+	diff, err = DiffDefinitions("deployed", from, "fly.toml", to)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "--- deployed\n+++ fly.toml\n")
+	assert.Contains(t, diff, "\n-  PORT = \"8080\"\n+  PORT = \"9090\"\n")
+
+	diff, err = DiffDefinitions("deployed", nil, "fly.toml", map[string]interface{}{"app": "web"})
+	require.NoError(t, err)
+	assert.Contains(t, diff, "+app = \"web\"\n")
+}
+
+func TestColorizeDiff(t *testing.T) {
+	const diff = "--- a\n+++ b\n@@ -1 +1 @@\n-old\n+new\n same\n"
+
+	assert.Equal(t, diff, ColorizeDiff(diff, iostreams.NewColorScheme(false, false)))
+
+	cs := iostreams.NewColorScheme(true, false)
+	assert.Equal(t,
+		cs.Bold("--- a\n")+cs.Bold("+++ b\n")+cs.Cyan("@@ -1 +1 @@\n")+cs.Red("-old\n")+cs.Green("+new\n")+" same\n",
+		ColorizeDiff(diff, cs))
+}
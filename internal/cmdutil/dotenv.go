@@ -0,0 +1,117 @@
+package cmdutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseDotenv parses the NAME=VALUE pairs r contains in dotenv format.
+//
+// Blank lines and lines starting with # are skipped, as is an optional export
+// prefix. Values may be wrapped in single quotes, taken literally, or double
+// quotes, in which case \n, \t, \" and \\ are unescaped. Values wrapped in
+// triple double quotes may span multiple lines.
+//
+// ParseDotenv errors out on malformed lines, invalid names and names which
+// are defined more than once.
+func ParseDotenv(r io.Reader) (map[string]string, error) {
+	var (
+		env     = map[string]string{}
+		lines   = map[string]int{}
+		scanner = bufio.NewScanner(r)
+		lineNo  int
+	)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		lineNo++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("line %d: expected NAME=VALUE", lineNo)
+		}
+
+		name := strings.TrimSpace(parts[0])
+		if !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("line %d: invalid name %q", lineNo, name)
+		}
+
+		if prev, ok := lines[name]; ok {
+			return nil, fmt.Errorf("line %d: %s is already defined on line %d", lineNo, name, prev)
+		}
+		lines[name] = lineNo
+
+		value := strings.TrimSpace(parts[1])
+
+		switch {
+		case strings.HasPrefix(value, `"""`):
+			start := lineNo
+
+			var b strings.Builder
+			b.WriteString(strings.TrimPrefix(value, `"""`))
+
+			closed := strings.HasSuffix(value, `"""`) && len(value) >= 6
+			for !closed && scanner.Scan() {
+				lineNo++
+
+				b.WriteString("\n")
+				b.WriteString(scanner.Text())
+
+				closed = strings.HasSuffix(scanner.Text(), `"""`)
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: unterminated multiline value of %s", start, name)
+			}
+
+			value = strings.TrimSuffix(b.String(), `"""`)
+		case strings.HasPrefix(value, `"`):
+			if len(value) < 2 || !strings.HasSuffix(value, `"`) {
+				return nil, fmt.Errorf("line %d: unterminated quoted value of %s", lineNo, name)
+			}
+
+			value = unescapeDotenvValue(value[1 : len(value)-1])
+		case strings.HasPrefix(value, "'"):
+			if len(value) < 2 || !strings.HasSuffix(value, "'") {
+				return nil, fmt.Errorf("line %d: unterminated quoted value of %s", lineNo, name)
+			}
+
+			value = value[1 : len(value)-1]
+		default:
+			// strip trailing comments off of unquoted values
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+
+		env[name] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+var dotenvUnescaper = strings.NewReplacer(
+	`\n`, "\n",
+	`\t`, "\t",
+	`\"`, `"`,
+	`\\`, `\`,
+)
+
+func unescapeDotenvValue(s string) string {
+	return dotenvUnescaper.Replace(s)
+}
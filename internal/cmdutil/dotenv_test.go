@@ -0,0 +1,52 @@
+package cmdutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const dotenv = `# a comment
+PLAIN=value
+export EXPORTED=exported
+SPACED = spaced # trailing comment
+DOUBLE="line\nbreak \"quoted\""
+SINGLE='literal\n # kept'
+EMPTY=
+EQUALS=a=b
+
+MULTI="""first
+second
+"""
+`
+
+func TestParseDotenv(t *testing.T) {
+	env, err := ParseDotenv(strings.NewReader(dotenv))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"PLAIN":    "value",
+		"EXPORTED": "exported",
+		"SPACED":   "spaced",
+		"DOUBLE":   "line\nbreak \"quoted\"",
+		"SINGLE":   `literal\n # kept`,
+		"EMPTY":    "",
+		"EQUALS":   "a=b",
+		"MULTI":    "first\nsecond\n",
+	}, env)
+}
+
+func TestParseDotenvErrors(t *testing.T) {
+	cases := map[string]string{
+		"NOVALUE":         "line 1: expected NAME=VALUE",
+		"1NAME=x":         `line 1: invalid name "1NAME"`,
+		"A=1\nA=2":        "line 2: A is already defined on line 1",
+		`A="open`:         "line 1: unterminated quoted value of A",
+		"A=\"\"\"open\nx": "line 1: unterminated multiline value of A",
+	}
+
+	for input, expected := range cases {
+		_, err := ParseDotenv(strings.NewReader(input))
+		assert.EqualError(t, err, expected, input)
+	}
+}
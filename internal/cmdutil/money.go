@@ -0,0 +1,53 @@
+package cmdutil
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseDollars parses a positive amount of US dollars, e.g. 250, $250 or
+// 99.50, into cents.
+func ParseDollars(s string) (int, error) {
+	v := strings.TrimPrefix(strings.TrimSpace(s), "$")
+
+	errInvalid := errors.New("use an amount of US dollars, e.g. 250 or 99.50")
+
+	whole, frac := v, ""
+	if i := strings.IndexByte(v, '.'); i >= 0 {
+		whole, frac = v[:i], v[i+1:]
+	}
+
+	if len(frac) > 2 {
+		return 0, errInvalid
+	}
+
+	dollars, err := strconv.Atoi(whole)
+	if err != nil || dollars < 0 {
+		return 0, errInvalid
+	}
+
+	var cents int
+	if frac != "" {
+		if cents, err = strconv.Atoi(frac + strings.Repeat("0", 2-len(frac))); err != nil || cents < 0 {
+			return 0, errInvalid
+		}
+	}
+
+	if total := dollars*100 + cents; total > 0 {
+		return total, nil
+	}
+
+	return 0, errInvalid
+}
+
+// FormatCents formats the given amount of cents as US dollars, e.g. $99.50.
+func FormatCents(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+
+	return fmt.Sprintf("%s$%d.%02d", sign, cents/100, cents%100)
+}
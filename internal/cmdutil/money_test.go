@@ -0,0 +1,35 @@
+package cmdutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDollars(t *testing.T) {
+	cases := map[string]int{
+		"250":    25000,
+		"$250":   25000,
+		"99.5":   9950,
+		"99.50":  9950,
+		" 0.01 ": 1,
+	}
+
+	for s, expected := range cases {
+		cents, err := ParseDollars(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected, cents, s)
+		}
+	}
+
+	for _, s := range []string{"", "0", "-5", "1.234", "1.-5", "ten"} {
+		_, err := ParseDollars(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestFormatCents(t *testing.T) {
+	assert.Equal(t, "$0.00", FormatCents(0))
+	assert.Equal(t, "$99.50", FormatCents(9950))
+	assert.Equal(t, "-$1.05", FormatCents(-105))
+}
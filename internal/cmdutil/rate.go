@@ -0,0 +1,21 @@
+package cmdutil
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+)
+
+// ParseByteRate parses a positive rate in bytes per second, e.g. 5MB/s or
+// 500KiB/s. The /s suffix is optional.
+func ParseByteRate(s string) (int64, error) {
+	v := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "/s"))
+
+	n, err := humanize.ParseBytes(v)
+	if err != nil || n == 0 {
+		return 0, errors.New("use a number of bytes per second, e.g. 5MB/s or 500KB/s")
+	}
+
+	return int64(n), nil
+}
@@ -0,0 +1,29 @@
+package cmdutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseByteRate(t *testing.T) {
+	cases := map[string]int64{
+		"5MB/s":    5000000,
+		"500KB/s":  500000,
+		"1MiB/s":   1048576,
+		"2mb":      2000000,
+		" 10 KB/s": 10000,
+	}
+
+	for s, expected := range cases {
+		rate, err := ParseByteRate(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected, rate, s)
+		}
+	}
+
+	for _, s := range []string{"", "0MB/s", "-1MB/s", "fast"} {
+		_, err := ParseByteRate(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -0,0 +1,87 @@
+package cmdutil
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// killSignals denotes the signals machines and VMs may be stopped with.
+var killSignals = map[string]struct{}{
+	"SIGINT":  {},
+	"SIGTERM": {},
+	"SIGQUIT": {},
+	"SIGUSR1": {},
+	"SIGUSR2": {},
+	"SIGKILL": {},
+	"SIGSTOP": {},
+}
+
+// NormalizeSignal returns the canonical name of the given stop signal,
+// accepting names in any case, with or without the SIG prefix. It errors out
+// on signals which can't be used to stop a machine.
+func NormalizeSignal(name string) (string, error) {
+	signal := strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(signal, "SIG") {
+		signal = "SIG" + signal
+	}
+
+	if _, ok := killSignals[signal]; !ok {
+		names := make([]string, 0, len(killSignals))
+		for name := range killSignals {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return "", fmt.Errorf("unsupported signal %q; use one of %s", name, strings.Join(names, ", "))
+	}
+
+	return signal, nil
+}
+
+// ValidateKillSettings returns the problems with the kill_signal and
+// kill_timeout settings of the given app config definition.
+func ValidateKillSettings(definition map[string]interface{}) (problems []string) {
+	if v, ok := definition["kill_signal"]; ok {
+		if s, isString := v.(string); !isString {
+			problems = append(problems, "kill_signal must be a string")
+		} else if _, err := NormalizeSignal(s); err != nil {
+			problems = append(problems, "kill_signal: "+err.Error())
+		}
+	}
+
+	if v, ok := definition["kill_timeout"]; ok {
+		if _, err := KillTimeout(v); err != nil {
+			problems = append(problems, "kill_timeout: "+err.Error())
+		}
+	}
+
+	return
+}
+
+// KillTimeout returns the number of seconds the given kill_timeout value
+// denotes.
+func KillTimeout(v interface{}) (int, error) {
+	var secs float64
+
+	switch v := v.(type) {
+	case int:
+		secs = float64(v)
+	case int64:
+		secs = float64(v)
+	case float64:
+		secs = v
+	default:
+		return 0, fmt.Errorf("expected a number of seconds, got %v", v)
+	}
+
+	switch {
+	case secs != math.Trunc(secs):
+		return 0, fmt.Errorf("expected a whole number of seconds, got %v", secs)
+	case secs < 0:
+		return 0, fmt.Errorf("must not be negative, got %v", secs)
+	}
+
+	return int(secs), nil
+}
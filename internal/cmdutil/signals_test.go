@@ -0,0 +1,49 @@
+package cmdutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeSignal(t *testing.T) {
+	for in, exp := range map[string]string{
+		"SIGTERM": "SIGTERM",
+		"sigint":  "SIGINT",
+		"usr1":    "SIGUSR1",
+		" QUIT ":  "SIGQUIT",
+	} {
+		got, err := NormalizeSignal(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, exp, got, in)
+	}
+
+	_, err := NormalizeSignal("SIGHUP")
+	assert.Error(t, err)
+}
+
+func TestValidateKillSettings(t *testing.T) {
+	assert.Empty(t, ValidateKillSettings(map[string]interface{}{
+		"kill_signal":  "SIGTERM",
+		"kill_timeout": int64(30),
+	}))
+	assert.Empty(t, ValidateKillSettings(map[string]interface{}{
+		"kill_timeout": float64(5),
+	}))
+
+	problems := ValidateKillSettings(map[string]interface{}{
+		"kill_signal":  "SIGHUP",
+		"kill_timeout": int64(-1),
+	})
+	assert.Len(t, problems, 2)
+
+	problems = ValidateKillSettings(map[string]interface{}{
+		"kill_signal":  5,
+		"kill_timeout": 2.5,
+	})
+	assert.Equal(t, []string{
+		"kill_signal must be a string",
+		"kill_timeout: expected a whole number of seconds, got 2.5",
+	}, problems)
+}
@@ -2,6 +2,7 @@ package cmdutil
 
 import (
 	"regexp"
+	"strings"
 )
 
 const ansi = "[\u001B\u009B][[\\]()#;?]*(?:(?:(?:[a-zA-Z\\d]*(?:;[a-zA-Z\\d]*)*)?\u0007)|(?:(?:\\d{1,4}(?:;\\d{0,4})*)?[\\dA-PRZcf-ntqry=><~]))"
@@ -11,3 +12,12 @@ var re = regexp.MustCompile(ansi)
 func StripANSI(str string) string {
 	return re.ReplaceAllString(str, "")
 }
+
+// Cut is strings.Cut, which Go 1.18 introduced.
+func Cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
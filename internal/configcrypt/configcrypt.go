@@ -0,0 +1,509 @@
+// Package configcrypt implements encrypting the values of fields of app
+// configs in place, as sops does, so that settings which are sensitive but not
+// secret may be committed along with the rest of the config.
+//
+// Values are encrypted with AES-256-GCM using a data key, which is in turn
+// encrypted for each of the age or AWS KMS recipients the encryption section
+// of the config names, and stored along with it:
+//
+//	[encryption]
+//	  age = ["age1..."]
+//	  kms = ["arn:aws:kms:us-east-1:111122223333:key/..."]
+//	  fields = ["env.API_HOST"]
+//
+//	  [[encryption.keys]]
+//	    recipient = "age1..."
+//	    key = "-----BEGIN AGE ENCRYPTED FILE-----..."
+//
+// Encrypted values are strings of the form ENC[AES256_GCM,<data>]. Each is
+// bound to the dotted path of its field, so that values can't be swapped
+// between fields.
+package configcrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Section is the name of the section of app configs the encryption settings
+// are found in.
+const Section = "encryption"
+
+const (
+	valuePrefix = "ENC[AES256_GCM,"
+	valueSuffix = "]"
+
+	dataKeySize = 32
+)
+
+// IsEncrypted reports whether v is an encrypted value.
+func IsEncrypted(v string) bool {
+	return strings.HasPrefix(v, valuePrefix) && strings.HasSuffix(v, valueSuffix)
+}
+
+// settings wraps the contents of the encryption section.
+type settings struct {
+	age    []string
+	kms    []string
+	fields []string
+
+	// keys maps recipients to the data key, as encrypted for them.
+	keys map[string]string
+}
+
+func settingsOf(def map[string]interface{}) (s *settings, err error) {
+	s = &settings{
+		keys: map[string]string{},
+	}
+
+	raw, ok := def[Section]
+	if !ok {
+		return
+	}
+
+	section, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("[%s] must be a section", Section)
+	}
+
+	if s.age, err = stringsOf(section, "age"); err != nil {
+		return nil, err
+	}
+	if s.kms, err = stringsOf(section, "kms"); err != nil {
+		return nil, err
+	}
+	if s.fields, err = stringsOf(section, "fields"); err != nil {
+		return nil, err
+	}
+
+	var keys []map[string]interface{}
+	switch v := section["keys"].(type) {
+	case nil:
+	case []map[string]interface{}:
+		keys = v
+	case []interface{}:
+		for _, k := range v {
+			m, ok := k.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s.keys must be an array of tables", Section)
+			}
+			keys = append(keys, m)
+		}
+	default:
+		return nil, fmt.Errorf("%s.keys must be an array of tables", Section)
+	}
+
+	for _, k := range keys {
+		recipient, _ := k["recipient"].(string)
+		key, _ := k["key"].(string)
+
+		if recipient == "" || key == "" {
+			return nil, fmt.Errorf("each of %s.keys must have a recipient and a key", Section)
+		}
+
+		s.keys[recipient] = key
+	}
+
+	return
+}
+
+func stringsOf(section map[string]interface{}, name string) (values []string, err error) {
+	switch v := section[name].(type) {
+	case nil:
+	case string:
+		values = append(values, v)
+	case []string:
+		values = append(values, v...)
+	case []interface{}:
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s.%s must be an array of strings", Section, name)
+			}
+			values = append(values, s)
+		}
+	default:
+		return nil, fmt.Errorf("%s.%s must be an array of strings", Section, name)
+	}
+
+	return
+}
+
+func (s *settings) recipients() []string {
+	return append(append([]string{}, s.age...), s.kms...)
+}
+
+func (s *settings) toMap() map[string]interface{} {
+	m := map[string]interface{}{}
+
+	if len(s.age) > 0 {
+		m["age"] = s.age
+	}
+	if len(s.kms) > 0 {
+		m["kms"] = s.kms
+	}
+	if len(s.fields) > 0 {
+		m["fields"] = s.fields
+	}
+
+	if len(s.keys) > 0 {
+		recipients := make([]string, 0, len(s.keys))
+		for r := range s.keys {
+			recipients = append(recipients, r)
+		}
+		sort.Strings(recipients)
+
+		keys := make([]map[string]interface{}, 0, len(recipients))
+		for _, r := range recipients {
+			keys = append(keys, map[string]interface{}{
+				"recipient": r,
+				"key":       s.keys[r],
+			})
+		}
+		m["keys"] = keys
+	}
+
+	return m
+}
+
+// dataKey returns the data key of the config, decrypting it with the first
+// identity available for any of the recipients it's encrypted for.
+func (s *settings) dataKey(ctx context.Context) ([]byte, error) {
+	if len(s.keys) == 0 {
+		return nil, fmt.Errorf("the [%s] section holds no data key", Section)
+	}
+
+	var errs []string
+	for _, recipient := range sortedKeys(s.keys) {
+		key, err := unwrap(ctx, recipient, s.keys[recipient])
+		if err == nil {
+			if len(key) != dataKeySize {
+				err = errors.New("data key is malformed")
+			} else {
+				return key, nil
+			}
+		}
+
+		errs = append(errs, fmt.Sprintf("%s: %v", recipient, err))
+	}
+
+	return nil, fmt.Errorf("failed decrypting the data key for any recipient (%s)", strings.Join(errs, "; "))
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Lock adds the given fields to the ones the encryption section of def lists
+// and encrypts the values of all of them in place. Fields are denoted by their
+// dotted paths, e.g. env.API_HOST. It returns the number of values it
+// encrypted.
+//
+// The data key is generated on first use and encrypted for recipients which
+// have been added to the section since. Once recipients are removed from the
+// section, since they may have kept the data key, it's replaced with a new
+// one and all encrypted values are encrypted anew.
+func Lock(ctx context.Context, def map[string]interface{}, fields ...string) (n int, err error) {
+	s, err := settingsOf(def)
+	if err != nil {
+		return 0, err
+	}
+
+	recipients := s.recipients()
+	if len(recipients) == 0 {
+		return 0, fmt.Errorf("the [%s] section names no age or kms recipients", Section)
+	}
+
+	for _, f := range fields {
+		if !contains(s.fields, f) {
+			s.fields = append(s.fields, f)
+		}
+	}
+
+	if len(s.fields) == 0 {
+		return 0, errors.New("no fields to encrypt were given")
+	}
+
+	rotate := len(s.keys) == 0
+	for r := range s.keys {
+		if !contains(recipients, r) {
+			rotate = true
+		}
+	}
+
+	targets := append([]string{}, s.fields...)
+
+	var key []byte
+	if len(s.keys) > 0 || len(encryptedPaths(def)) > 0 {
+		if key, err = s.dataKey(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	if rotate {
+		// values encrypted with the old key are decrypted to be encrypted
+		// anew, whether or not their fields are still listed
+		for _, path := range encryptedPaths(def) {
+			if !contains(targets, path) {
+				targets = append(targets, path)
+			}
+		}
+
+		if _, err = decryptAll(def, key); err != nil {
+			return 0, err
+		}
+
+		key = make([]byte, dataKeySize)
+		if _, err = io.ReadFull(rand.Reader, key); err != nil {
+			return 0, fmt.Errorf("failed generating data key: %w", err)
+		}
+
+		s.keys = map[string]string{}
+	}
+
+	for _, r := range recipients {
+		if _, ok := s.keys[r]; ok {
+			continue
+		}
+
+		if s.keys[r], err = wrap(ctx, r, key); err != nil {
+			return 0, fmt.Errorf("failed encrypting the data key for %s: %w", r, err)
+		}
+	}
+
+	for _, f := range targets {
+		v, ok := lookup(def, f)
+		if !ok {
+			return 0, fmt.Errorf("field %s does not exist", f)
+		}
+
+		str, ok := v.(string)
+		switch {
+		case !ok:
+			return 0, fmt.Errorf("field %s is not a string; only strings may be encrypted", f)
+		case IsEncrypted(str):
+			continue
+		}
+
+		var enc string
+		if enc, err = encrypt(key, f, str); err != nil {
+			return 0, fmt.Errorf("failed encrypting %s: %w", f, err)
+		}
+
+		set(def, f, enc)
+		n++
+	}
+
+	def[Section] = s.toMap()
+
+	return n, nil
+}
+
+// Unlock decrypts the encrypted values of def in place, leaving the encryption
+// section as is so that Lock may encrypt them anew. It returns the number of
+// values it decrypted; configs without encrypted values need no identity.
+func Unlock(ctx context.Context, def map[string]interface{}) (n int, err error) {
+	if len(encryptedPaths(def)) == 0 {
+		return 0, nil
+	}
+
+	s, err := settingsOf(def)
+	if err != nil {
+		return 0, err
+	}
+
+	key, err := s.dataKey(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return decryptAll(def, key)
+}
+
+// encryptedPaths returns the dotted paths of the encrypted values of def.
+func encryptedPaths(def map[string]interface{}) (paths []string) {
+	walk(def, "", func(path, v string) {
+		if IsEncrypted(v) {
+			paths = append(paths, path)
+		}
+	})
+
+	sort.Strings(paths)
+
+	return
+}
+
+// decryptAll decrypts the encrypted values of def in place with key.
+func decryptAll(def map[string]interface{}, key []byte) (n int, err error) {
+	for _, path := range encryptedPaths(def) {
+		v, _ := lookup(def, path)
+
+		var dec string
+		if dec, err = decrypt(key, path, v.(string)); err != nil {
+			return 0, fmt.Errorf("failed decrypting %s: %w", path, err)
+		}
+
+		set(def, path, dec)
+		n++
+	}
+
+	return n, nil
+}
+
+// encrypt encrypts the plaintext of the field at path with key, binding the
+// ciphertext to path.
+func encrypt(key []byte, path, plaintext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), []byte(path))
+
+	return valuePrefix + base64.StdEncoding.EncodeToString(sealed) + valueSuffix, nil
+}
+
+func decrypt(key []byte, path, value string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(value, valuePrefix), valueSuffix))
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", errors.New("value is malformed")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte(path))
+	if err != nil {
+		return "", errors.New("value does not match the data key or was moved from another field")
+	}
+
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// walk calls fn with the dotted path and value of each string of v, skipping
+// the encryption section.
+func walk(v interface{}, path string, fn func(path, v string)) {
+	switch v := v.(type) {
+	case string:
+		fn(path, v)
+	case map[string]interface{}:
+		for k, e := range v {
+			if path == "" && k == Section {
+				continue
+			}
+			walk(e, join(path, k), fn)
+		}
+	case []interface{}:
+		for i, e := range v {
+			walk(e, join(path, strconv.Itoa(i)), fn)
+		}
+	case []map[string]interface{}:
+		for i, e := range v {
+			walk(e, join(path, strconv.Itoa(i)), fn)
+		}
+	}
+}
+
+func join(path, elem string) string {
+	if path == "" {
+		return elem
+	}
+
+	return path + "." + elem
+}
+
+// lookup returns the value the given dotted path of def denotes.
+func lookup(def map[string]interface{}, path string) (v interface{}, ok bool) {
+	v = def
+
+	for _, elem := range strings.Split(path, ".") {
+		if v, ok = child(v, elem); !ok {
+			return nil, false
+		}
+	}
+
+	return v, true
+}
+
+func child(v interface{}, elem string) (interface{}, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		e, ok := v[elem]
+
+		return e, ok
+	case []interface{}:
+		if i, err := strconv.Atoi(elem); err == nil && i >= 0 && i < len(v) {
+			return v[i], true
+		}
+	case []map[string]interface{}:
+		if i, err := strconv.Atoi(elem); err == nil && i >= 0 && i < len(v) {
+			return v[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// set sets the value the given dotted path of def denotes, which must exist.
+func set(def map[string]interface{}, path string, value string) {
+	i := strings.LastIndex(path, ".")
+	if i < 0 {
+		def[path] = value
+
+		return
+	}
+
+	parent, _ := lookup(def, path[:i])
+	elem := path[i+1:]
+
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[elem] = value
+	case []interface{}:
+		idx, _ := strconv.Atoi(elem)
+		p[idx] = value
+	}
+}
+
+func contains(values []string, v string) bool {
+	for _, e := range values {
+		if e == v {
+			return true
+		}
+	}
+
+	return false
+}
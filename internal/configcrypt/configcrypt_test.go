@@ -0,0 +1,208 @@
+package configcrypt
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRun stubs the age CLI with one which wraps keys in base64 and counts
+// its invocations.
+func stubRun(t *testing.T) *int {
+	t.Helper()
+
+	t.Setenv("FLY_AGE_KEY_FILE", "keys.txt")
+
+	var calls int
+
+	prev := run
+	run = func(_ context.Context, stdin []byte, name string, args ...string) ([]byte, error) {
+		calls++
+
+		switch {
+		case name != "age":
+			return nil, errors.New("unexpected command " + name)
+		case args[0] == "--encrypt":
+			return []byte(base64.StdEncoding.EncodeToString(stdin)), nil
+		default:
+			return base64.StdEncoding.DecodeString(string(stdin))
+		}
+	}
+	t.Cleanup(func() { run = prev })
+
+	return &calls
+}
+
+func testDefinition() map[string]interface{} {
+	return map[string]interface{}{
+		"env": map[string]interface{}{
+			"API_HOST": "internal.example.com",
+			"PORT":     "8080",
+		},
+		"services": []interface{}{
+			map[string]interface{}{"internal_port": 8080},
+		},
+		Section: map[string]interface{}{
+			"age":    []interface{}{"age1alice"},
+			"fields": []interface{}{"env.API_HOST"},
+		},
+	}
+}
+
+func TestLockUnlock(t *testing.T) {
+	calls := stubRun(t)
+	ctx := context.Background()
+	def := testDefinition()
+
+	n, err := Lock(ctx, def)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	env := def["env"].(map[string]interface{})
+	assert.True(t, IsEncrypted(env["API_HOST"].(string)))
+	assert.Equal(t, "8080", env["PORT"])
+
+	section := def[Section].(map[string]interface{})
+	assert.Len(t, section["keys"], 1)
+	assert.Equal(t, 1, *calls)
+
+	// locking again re-encrypts nothing
+	n, err = Lock(ctx, def)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	n, err = Unlock(ctx, def)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "internal.example.com", env["API_HOST"])
+	assert.Equal(t, []string{"env.API_HOST"}, def[Section].(map[string]interface{})["fields"])
+}
+
+func TestLockAddsRecipientsAndFields(t *testing.T) {
+	stubRun(t)
+	ctx := context.Background()
+	def := testDefinition()
+
+	_, err := Lock(ctx, def)
+	require.NoError(t, err)
+
+	env := def["env"].(map[string]interface{})
+	encrypted := env["API_HOST"]
+
+	section := def[Section].(map[string]interface{})
+	section["age"] = []interface{}{"age1alice", "age1bob"}
+
+	// adding recipients keeps the data key
+	n, err := Lock(ctx, def, "env.PORT")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, encrypted, env["API_HOST"])
+
+	s, err := settingsOf(def)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"env.API_HOST", "env.PORT"}, s.fields)
+	assert.Equal(t, []string{"age1alice", "age1bob"}, sortedKeys(s.keys))
+
+	n, err = Unlock(ctx, def)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestLockRotatesDataKeyOnRemovedRecipients(t *testing.T) {
+	stubRun(t)
+	ctx := context.Background()
+	def := testDefinition()
+
+	_, err := Lock(ctx, def, "env.PORT")
+	require.NoError(t, err)
+
+	s, err := settingsOf(def)
+	require.NoError(t, err)
+	oldKey, err := s.dataKey(ctx)
+	require.NoError(t, err)
+
+	env := def["env"].(map[string]interface{})
+	encrypted := env["API_HOST"]
+
+	// removing a recipient encrypts all values anew with a new data key,
+	// including ones the section no longer lists
+	def[Section].(map[string]interface{})["age"] = []interface{}{"age1bob"}
+	def[Section].(map[string]interface{})["fields"] = []interface{}{"env.API_HOST"}
+
+	n, err := Lock(ctx, def)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.NotEqual(t, encrypted, env["API_HOST"])
+
+	s, err = settingsOf(def)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"age1bob"}, sortedKeys(s.keys))
+
+	newKey, err := s.dataKey(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, oldKey, newKey)
+
+	_, err = decrypt(oldKey, "env.API_HOST", env["API_HOST"].(string))
+	assert.Error(t, err)
+
+	n, err = Unlock(ctx, def)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, "internal.example.com", env["API_HOST"])
+	assert.Equal(t, "8080", env["PORT"])
+}
+
+func TestUnlockRejectsMovedValues(t *testing.T) {
+	stubRun(t)
+	ctx := context.Background()
+	def := testDefinition()
+
+	_, err := Lock(ctx, def, "env.PORT")
+	require.NoError(t, err)
+
+	env := def["env"].(map[string]interface{})
+	env["API_HOST"], env["PORT"] = env["PORT"], env["API_HOST"]
+
+	_, err = Unlock(ctx, def)
+	assert.EqualError(t, err, "failed decrypting env.API_HOST: value does not match the data key or was moved from another field")
+}
+
+func TestLockErrors(t *testing.T) {
+	stubRun(t)
+	ctx := context.Background()
+
+	def := testDefinition()
+	_, err := Lock(ctx, def, "services.0.internal_port")
+	assert.EqualError(t, err, "field services.0.internal_port is not a string; only strings may be encrypted")
+
+	def = testDefinition()
+	_, err = Lock(ctx, def, "env.MISSING")
+	assert.EqualError(t, err, "field env.MISSING does not exist")
+
+	def = testDefinition()
+	delete(def, Section)
+	_, err = Lock(ctx, def, "env.PORT")
+	assert.EqualError(t, err, "the [encryption] section names no age or kms recipients")
+}
+
+func TestUnlockWithoutEncryptedValues(t *testing.T) {
+	calls := stubRun(t)
+
+	n, err := Unlock(context.Background(), testDefinition())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 0, *calls)
+}
+
+func TestKMSRegion(t *testing.T) {
+	region, err := kmsRegion("arn:aws:kms:eu-west-1:111122223333:key/1234abcd")
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", region)
+
+	_, err = kmsRegion("arn:aws:s3:::bucket")
+	assert.Error(t, err)
+}
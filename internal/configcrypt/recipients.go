@@ -0,0 +1,136 @@
+package configcrypt
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// run runs the named command with the given standard input and returns its
+// standard output. It's a variable so that tests may stub it.
+var run = func(ctx context.Context, stdin []byte, name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("the %s CLI is required but could not be found in PATH", name)
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(msg)
+		}
+
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func isKMS(recipient string) bool {
+	return strings.HasPrefix(recipient, "arn:")
+}
+
+// wrap encrypts the data key for the given recipient: an age public key,
+// through the age CLI, or the ARN of an AWS KMS key, through the aws CLI and
+// thus the credentials it's configured with.
+func wrap(ctx context.Context, recipient string, key []byte) (string, error) {
+	if !isKMS(recipient) {
+		out, err := run(ctx, key, "age", "--encrypt", "--armor", "--recipient", recipient)
+
+		return string(out), err
+	}
+
+	region, err := kmsRegion(recipient)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := run(ctx, key, "aws", "kms", "encrypt",
+		"--key-id", recipient,
+		"--region", region,
+		"--plaintext", "fileb:///dev/stdin",
+		"--query", "CiphertextBlob",
+		"--output", "text",
+	)
+
+	return strings.TrimSpace(string(out)), err
+}
+
+// unwrap decrypts the data key, as wrap encrypted it for the given recipient.
+// age keys are decrypted with the identity file FLY_AGE_KEY_FILE or
+// SOPS_AGE_KEY_FILE denote, defaulting to the one sops uses.
+func unwrap(ctx context.Context, recipient, wrapped string) ([]byte, error) {
+	if !isKMS(recipient) {
+		identity, err := ageIdentityFile()
+		if err != nil {
+			return nil, err
+		}
+
+		return run(ctx, []byte(wrapped), "age", "--decrypt", "--identity", identity)
+	}
+
+	region, err := kmsRegion(recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, errors.New("key is malformed")
+	}
+
+	out, err := run(ctx, blob, "aws", "kms", "decrypt",
+		"--key-id", recipient,
+		"--region", region,
+		"--ciphertext-blob", "fileb:///dev/stdin",
+		"--query", "Plaintext",
+		"--output", "text",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(string(out)))
+}
+
+// kmsRegion returns the region of the KMS key the given ARN denotes, as in
+// arn:aws:kms:<region>:<account>:key/<id>.
+func kmsRegion(arn string) (string, error) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 || parts[2] != "kms" || parts[3] == "" {
+		return "", fmt.Errorf("%q is not the ARN of a KMS key", arn)
+	}
+
+	return parts[3], nil
+}
+
+func ageIdentityFile() (string, error) {
+	for _, env := range []string{"FLY_AGE_KEY_FILE", "SOPS_AGE_KEY_FILE"} {
+		if path := os.Getenv(env); path != "" {
+			return path, nil
+		}
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "sops", "age", "keys.txt")
+	if _, err := os.Stat(path); err != nil {
+		return "", errors.New("no age identity found; set FLY_AGE_KEY_FILE to the path of one")
+	}
+
+	return path, nil
+}
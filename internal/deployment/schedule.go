@@ -0,0 +1,287 @@
+package deployment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/superfly/flyctl/internal/filemu"
+)
+
+// ErrScheduledNotFound is returned for IDs which match no scheduled
+// deployment.
+var ErrScheduledNotFound = errors.New("no such scheduled deployment")
+
+// Scheduled is a deployment of an already built image which a background
+// flyctl process runs at a later time.
+type Scheduled struct {
+	ID        string    `json:"id"`
+	AppName   string    `json:"app"`
+	Image     string    `json:"image"`
+	At        time.Time `json:"at"`
+	CreatedAt time.Time `json:"created_at"`
+	// Args are the arguments flyctl is run with to deploy.
+	Args []string `json:"args"`
+	// PID identifies the process waiting to deploy.
+	PID     int    `json:"pid,omitempty"`
+	LogFile string `json:"log_file,omitempty"`
+}
+
+// Schedule stores scheduled deployments in a directory, by default the
+// flyctl config directory.
+type Schedule struct {
+	dir string
+}
+
+// NewSchedule returns the Schedule stored in dir.
+func NewSchedule(dir string) *Schedule {
+	return &Schedule{dir: dir}
+}
+
+func (s *Schedule) path() string {
+	return filepath.Join(s.dir, "scheduled_deploys.json")
+}
+
+// List returns the scheduled deployments, in the order they are due.
+func (s *Schedule) List(ctx context.Context) (scheduled []*Scheduled, err error) {
+	err = s.update(ctx, func(all []*Scheduled) ([]*Scheduled, error) {
+		scheduled = all
+
+		return all, nil
+	})
+
+	return
+}
+
+// Get returns the scheduled deployment of the given ID.
+func (s *Schedule) Get(ctx context.Context, id string) (*Scheduled, error) {
+	all, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range all {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+
+	return nil, ErrScheduledNotFound
+}
+
+// Add schedules the given deployment, assigning it an ID, and starts the
+// background process which runs it once it's due.
+func (s *Schedule) Add(ctx context.Context, d *Scheduled) error {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return err
+	}
+	d.ID = hex.EncodeToString(b[:])
+	d.CreatedAt = time.Now().UTC()
+
+	logDir := filepath.Join(s.dir, "deploy-logs")
+	if err := os.MkdirAll(logDir, 0700); err != nil {
+		return fmt.Errorf("failed creating deploy log directory: %w", err)
+	}
+	d.LogFile = filepath.Join(logDir, d.ID+".log")
+
+	if err := s.update(ctx, func(all []*Scheduled) ([]*Scheduled, error) {
+		return append(all, d), nil
+	}); err != nil {
+		return err
+	}
+
+	pid, err := StartDetached("deploys", "scheduled", "run", d.ID)
+	if err != nil {
+		_, _ = s.Remove(ctx, d.ID)
+
+		return fmt.Errorf("failed starting scheduler process: %w", err)
+	}
+	d.PID = pid
+
+	return s.update(ctx, func(all []*Scheduled) ([]*Scheduled, error) {
+		for _, other := range all {
+			if other.ID == d.ID {
+				other.PID = d.PID
+			}
+		}
+
+		return all, nil
+	})
+}
+
+// StartDetached starts flyctl with the given arguments in the background,
+// detached from the current process so that it outlives it, and returns the
+// PID of the process it started.
+func StartDetached(args ...string) (pid int, err error) {
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "FLY_NO_UPDATE_CHECK=1")
+	setSysProcAttributes(cmd)
+
+	if err = cmd.Start(); err != nil {
+		return
+	}
+	pid = cmd.Process.Pid
+	_ = cmd.Process.Release()
+
+	return
+}
+
+// Remove unschedules the deployment of the given ID and returns it.
+func (s *Schedule) Remove(ctx context.Context, id string) (removed *Scheduled, err error) {
+	err = s.update(ctx, func(all []*Scheduled) ([]*Scheduled, error) {
+		kept := all[:0]
+		for _, d := range all {
+			if d.ID == id {
+				removed = d
+			} else {
+				kept = append(kept, d)
+			}
+		}
+
+		if removed == nil {
+			return nil, ErrScheduledNotFound
+		}
+
+		return kept, nil
+	})
+
+	return
+}
+
+// Cancel unschedules the deployment of the given ID. The process waiting to
+// run it exits once it finds the deployment gone; it isn't killed, since its
+// PID may have been reused by another process by now.
+func (s *Schedule) Cancel(ctx context.Context, id string) (*Scheduled, error) {
+	return s.Remove(ctx, id)
+}
+
+func (s *Schedule) waiterLockPath(id string) string {
+	return filepath.Join(s.dir, "deploy-waiters", id+".lock")
+}
+
+// WaiterRunning reports whether the process waiting to run the given
+// deployment is still running; waiters hold a lock for as long as they run,
+// which is released once they exit, crash or the machine restarts.
+func (s *Schedule) WaiterRunning(d *Scheduled) (bool, error) {
+	return filemu.Held(s.waiterLockPath(d.ID))
+}
+
+func (s *Schedule) update(ctx context.Context, fn func([]*Scheduled) ([]*Scheduled, error)) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+
+	unlock, err := filemu.Lock(ctx, s.path()+".lock")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = unlock() }()
+
+	var all []*Scheduled
+
+	switch data, err := os.ReadFile(s.path()); {
+	case errors.Is(err, os.ErrNotExist):
+		break
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(data, &all); err != nil {
+			return fmt.Errorf("failed parsing %s: %w", s.path(), err)
+		}
+	}
+
+	updated, err := fn(all)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(updated, func(i, j int) bool {
+		return updated[i].At.Before(updated[j].At)
+	})
+
+	data, err := json.MarshalIndent(updated, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path(), data, 0600)
+}
+
+// Run waits for the given scheduled deployment to be due and runs it, writing
+// its output to its log file. Deployments which are unscheduled in the
+// meantime are not run.
+func (s *Schedule) Run(ctx context.Context, id string) error {
+	lockPath := s.waiterLockPath(id)
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0700); err != nil {
+		return err
+	}
+
+	unlock, err := filemu.Lock(ctx, lockPath)
+	if err != nil {
+		return fmt.Errorf("failed locking %s; is another process waiting to run it? %w", id, err)
+	}
+	defer func() {
+		_ = unlock()
+		_ = os.Remove(lockPath)
+	}()
+
+	d, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// poll the wall clock rather than sleeping for the duration, since timers
+	// don't account for the time the machine spends suspended
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for time.Now().Round(0).Before(d.At) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		// cancelled deployments are gone from the schedule; other errors,
+		// such as contention on it, are retried on the next tick
+		if _, err := s.Get(ctx, id); errors.Is(err, ErrScheduledNotFound) {
+			return nil
+		}
+	}
+
+	if d, err = s.Remove(ctx, id); err != nil {
+		return err
+	}
+
+	log, err := os.OpenFile(d.LogFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer log.Close()
+
+	fmt.Fprintf(log, "%s deploying %s to %s\n", time.Now().UTC().Format(time.RFC3339), d.Image, d.AppName)
+
+	cmd := exec.CommandContext(ctx, os.Args[0], d.Args...)
+	cmd.Env = append(os.Environ(), "FLY_NO_UPDATE_CHECK=1")
+	cmd.Stdout = log
+	cmd.Stderr = log
+
+	err = cmd.Run()
+
+	status := "succeeded"
+	if err != nil {
+		status = fmt.Sprintf("failed: %v", err)
+	}
+	fmt.Fprintf(log, "%s deployment %s\n", time.Now().UTC().Format(time.RFC3339), status)
+
+	return err
+}
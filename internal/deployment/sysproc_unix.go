@@ -0,0 +1,16 @@
+//go:build !windows
+// +build !windows
+
+package deployment
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+func setSysProcAttributes(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+		Pgid:    0,
+	}
+}
@@ -0,0 +1,35 @@
+// Package desktop implements sending native desktop notifications, via
+// osascript on macOS, notify-send on Linux and toast notifications on Windows.
+package desktop
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnsupported is returned by Notify on platforms it doesn't support.
+var ErrUnsupported = errors.New("desktop notifications aren't supported on this platform")
+
+// Notify sends a desktop notification with the given title and message.
+func Notify(ctx context.Context, title, message string) error {
+	cmd, err := command(ctx, title, message)
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("failed sending desktop notification: %w: %s", err, msg)
+		}
+
+		return fmt.Errorf("failed sending desktop notification: %w", err)
+	}
+
+	return nil
+}
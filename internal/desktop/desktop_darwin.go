@@ -0,0 +1,19 @@
+//go:build darwin
+// +build darwin
+
+package desktop
+
+import (
+	"context"
+	"os/exec"
+)
+
+func command(ctx context.Context, title, message string) (*exec.Cmd, error) {
+	// passing the title and message as arguments spares quoting them
+	return exec.CommandContext(ctx, "osascript",
+		"-e", "on run argv",
+		"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+		"-e", "end run",
+		title, message,
+	), nil
+}
@@ -0,0 +1,19 @@
+//go:build linux
+// +build linux
+
+package desktop
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+func command(ctx context.Context, title, message string) (*exec.Cmd, error) {
+	path, err := exec.LookPath("notify-send")
+	if err != nil {
+		return nil, fmt.Errorf("%w: notify-send not found, install libnotify", ErrUnsupported)
+	}
+
+	return exec.CommandContext(ctx, path, "--app-name", "flyctl", title, message), nil
+}
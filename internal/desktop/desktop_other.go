@@ -0,0 +1,13 @@
+//go:build !darwin && !linux && !windows
+// +build !darwin,!linux,!windows
+
+package desktop
+
+import (
+	"context"
+	"os/exec"
+)
+
+func command(context.Context, string, string) (*exec.Cmd, error) {
+	return nil, ErrUnsupported
+}
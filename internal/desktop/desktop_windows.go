@@ -0,0 +1,32 @@
+//go:build windows
+// +build windows
+
+package desktop
+
+import (
+	"context"
+	"os"
+	"os/exec"
+)
+
+// toastScript shows the title and message its environment holds as a toast
+// notification, which spares quoting them.
+const toastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $template.GetElementsByTagName('text')
+$text.Item(0).AppendChild($template.CreateTextNode($env:FLYCTL_NOTIFY_TITLE)) > $null
+$text.Item(1).AppendChild($template.CreateTextNode($env:FLYCTL_NOTIFY_MESSAGE)) > $null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($template)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('flyctl').Show($toast)
+`
+
+func command(ctx context.Context, title, message string) (*exec.Cmd, error) {
+	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", toastScript)
+	cmd.Env = append(os.Environ(),
+		"FLYCTL_NOTIFY_TITLE="+title,
+		"FLYCTL_NOTIFY_MESSAGE="+message,
+	)
+
+	return cmd, nil
+}
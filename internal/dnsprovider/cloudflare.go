@@ -0,0 +1,200 @@
+package dnsprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/superfly/flyctl/internal/env"
+)
+
+// CloudflareTokenEnv is the environment variable the Cloudflare API token is
+// read from. The token requires the Zone:Read and DNS:Edit permissions.
+const CloudflareTokenEnv = "CLOUDFLARE_API_TOKEN"
+
+const cloudflareBaseURL = "https://api.cloudflare.com/client/v4"
+
+// Cloudflare manages the records of zones hosted with Cloudflare.
+type Cloudflare struct {
+	Token string
+
+	// BaseURL defaults to the URL of the Cloudflare API.
+	BaseURL string
+
+	HTTPClient *http.Client
+
+	zones map[string]string
+}
+
+// NewCloudflare returns the Cloudflare provider authenticated with the token
+// of the environment.
+func NewCloudflare() (*Cloudflare, error) {
+	token := env.First(CloudflareTokenEnv, "CF_API_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("the Cloudflare API token must be set via %s", CloudflareTokenEnv)
+	}
+
+	return &Cloudflare{Token: token}, nil
+}
+
+type cloudflareRecord struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	TTL     int    `json:"ttl,omitempty"`
+	Proxied bool   `json:"proxied"`
+}
+
+// Ensure implements Provider. Records are created unproxied, since proxying
+// them would keep certificates from being validated.
+func (c *Cloudflare) Ensure(ctx context.Context, record Record) (bool, error) {
+	zone, err := c.zoneOf(ctx, record.Name)
+	if err != nil {
+		return false, err
+	}
+
+	var records []cloudflareRecord
+
+	path := fmt.Sprintf("/zones/%s/dns_records?name=%s", zone, url.QueryEscape(record.Name))
+	if err := c.do(ctx, http.MethodGet, path, nil, &records); err != nil {
+		return false, fmt.Errorf("failed listing the records of %s: %w", record.Name, err)
+	}
+
+	existing := make([]Record, 0, len(records))
+	for _, r := range records {
+		existing = append(existing, Record{Type: r.Type, Name: r.Name, Content: r.Content})
+	}
+
+	found, conflicting := conflicts(record, existing)
+	switch {
+	case found:
+		return false, nil
+	case len(conflicting) > 0:
+		return false, &ConflictError{Record: record, Existing: conflicting}
+	}
+
+	// a TTL of 1 is automatic
+	body := cloudflareRecord{
+		Type:    record.Type,
+		Name:    record.Name,
+		Content: record.Content,
+		TTL:     1,
+	}
+
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/zones/%s/dns_records", zone), body, nil); err != nil {
+		return false, fmt.Errorf("failed creating %s: %w", record, err)
+	}
+
+	return true, nil
+}
+
+// zoneOf returns the ID of the zone of the account the token grants access to
+// which name belongs to, the most specific one in case there are several.
+func (c *Cloudflare) zoneOf(ctx context.Context, name string) (string, error) {
+	name = strings.ToLower(strings.TrimPrefix(name, "*."))
+
+	apex, err := publicsuffix.EffectiveTLDPlusOne(name)
+	if err != nil {
+		return "", fmt.Errorf("failed determining the domain of %s: %w", name, err)
+	}
+
+	for candidate := name; ; {
+		if id, ok := c.zones[candidate]; ok {
+			return id, nil
+		}
+
+		var zones []struct {
+			ID string `json:"id"`
+		}
+
+		path := "/zones?name=" + url.QueryEscape(candidate)
+		if err := c.do(ctx, http.MethodGet, path, nil, &zones); err != nil {
+			return "", fmt.Errorf("failed looking up zone %s: %w", candidate, err)
+		}
+
+		if len(zones) > 0 {
+			if c.zones == nil {
+				c.zones = map[string]string{}
+			}
+			c.zones[candidate] = zones[0].ID
+
+			return zones[0].ID, nil
+		}
+
+		if candidate == apex {
+			return "", fmt.Errorf("no zone of the Cloudflare account holds %s", name)
+		}
+		candidate = candidate[strings.Index(candidate, ".")+1:]
+	}
+}
+
+func (c *Cloudflare) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body bytes.Buffer
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return err
+		}
+	}
+
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = cloudflareBaseURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+path, &body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	var envelope struct {
+		Success bool `json:"success"`
+		Errors  []struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+		Result json.RawMessage `json:"result"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("failed decoding the response of the Cloudflare API (%s): %w", res.Status, err)
+	}
+
+	if !envelope.Success {
+		messages := make([]string, 0, len(envelope.Errors))
+		for _, e := range envelope.Errors {
+			messages = append(messages, fmt.Sprintf("%s (%d)", e.Message, e.Code))
+		}
+		if len(messages) == 0 {
+			return errors.New(res.Status)
+		}
+
+		return errors.New(strings.Join(messages, "; "))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.Unmarshal(envelope.Result, out)
+}
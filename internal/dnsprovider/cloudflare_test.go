@@ -0,0 +1,99 @@
+package dnsprovider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudflareEnsure(t *testing.T) {
+	var created []cloudflareRecord
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+
+		var result interface{} = []interface{}{}
+
+		switch {
+		case r.URL.Path == "/zones" && r.URL.Query().Get("name") == "example.com":
+			result = []map[string]string{{"id": "zone1"}}
+		case r.URL.Path == "/zones":
+		case r.URL.Path == "/zones/zone1/dns_records" && r.Method == http.MethodGet:
+			switch r.URL.Query().Get("name") {
+			case "www.example.com":
+				result = []Record{{Type: "CNAME", Name: "www.example.com", Content: "my-app.fly.dev"}}
+			case "api.example.com":
+				result = []Record{{Type: "A", Name: "api.example.com", Content: "1.2.3.4"}}
+			}
+		case r.URL.Path == "/zones/zone1/dns_records" && r.Method == http.MethodPost:
+			var record cloudflareRecord
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&record))
+			created = append(created, record)
+			result = record
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"errors":  []map[string]interface{}{{"code": 7003, "message": "Could not route"}},
+			})
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "result": result})
+	}))
+	defer srv.Close()
+
+	c := &Cloudflare{Token: "tok", BaseURL: srv.URL}
+	ctx := context.Background()
+
+	ok, err := c.Ensure(ctx, Record{Type: "CNAME", Name: "www.example.com", Content: "my-app.fly.dev"})
+	require.NoError(t, err)
+	assert.False(t, ok, "existing records aren't created again")
+
+	ok, err = c.Ensure(ctx, Record{Type: "CNAME", Name: "_acme-challenge.shop.example.com", Content: "shop.example.com.x.flydns.net"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []cloudflareRecord{
+		{Type: "CNAME", Name: "_acme-challenge.shop.example.com", Content: "shop.example.com.x.flydns.net", TTL: 1},
+	}, created)
+
+	_, err = c.Ensure(ctx, Record{Type: "A", Name: "api.example.com", Content: "5.6.7.8"})
+	assert.EqualError(t, err, "can't create A api.example.com 5.6.7.8, since it conflicts with A api.example.com 1.2.3.4; remove or update them first")
+
+	_, err = c.Ensure(ctx, Record{Type: "A", Name: "example.org", Content: "5.6.7.8"})
+	assert.EqualError(t, err, "no zone of the Cloudflare account holds example.org")
+}
+
+func TestConflicts(t *testing.T) {
+	existing := []Record{
+		{Type: "A", Name: "example.com", Content: "1.2.3.4"},
+		{Type: "MX", Name: "example.com", Content: "mail.example.com"},
+	}
+
+	found, conflicting := conflicts(Record{Type: "AAAA", Name: "example.com", Content: "::1"}, existing)
+	assert.False(t, found)
+	assert.Empty(t, conflicting)
+
+	_, conflicting = conflicts(Record{Type: "CNAME", Name: "example.com", Content: "my-app.fly.dev"}, existing)
+	assert.Equal(t, existing, conflicting)
+
+	found, _ = conflicts(Record{Type: "a", Name: "example.com", Content: "1.2.3.4"}, existing)
+	assert.True(t, found)
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv(CloudflareTokenEnv, "tok")
+
+	p, err := New("Cloudflare")
+	require.NoError(t, err)
+	assert.Equal(t, "tok", p.(*Cloudflare).Token)
+
+	_, err = New("route53")
+	assert.EqualError(t, err, `unsupported DNS provider "route53"; supported are: cloudflare`)
+}
@@ -0,0 +1,77 @@
+// Package dnsprovider manages DNS records via the APIs of the DNS providers
+// flyctl integrates with, so the records certificates require don't have to
+// be created by hand.
+package dnsprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Names are the names of the supported providers.
+var Names = []string{"cloudflare"}
+
+// Record wraps a DNS record. Names are fully qualified, without the trailing
+// dot.
+type Record struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+func (r Record) String() string {
+	return fmt.Sprintf("%s %s %s", r.Type, r.Name, r.Content)
+}
+
+// Provider manages the records of the zones hosted with a DNS provider.
+type Provider interface {
+	// Ensure creates record in the zone its name belongs to, unless the zone
+	// has it already, and reports whether it did. Records the zone has which
+	// conflict with record fail with a ConflictError.
+	Ensure(ctx context.Context, record Record) (created bool, err error)
+}
+
+// New returns the named provider, configured from the environment.
+func New(name string) (Provider, error) {
+	switch strings.ToLower(name) {
+	case "cloudflare":
+		return NewCloudflare()
+	default:
+		return nil, fmt.Errorf("unsupported DNS provider %q; supported are: %s", name, strings.Join(Names, ", "))
+	}
+}
+
+// ConflictError is returned for records zones have other records in place of.
+type ConflictError struct {
+	Record   Record
+	Existing []Record
+}
+
+func (err *ConflictError) Error() string {
+	existing := make([]string, 0, len(err.Existing))
+	for _, r := range err.Existing {
+		existing = append(existing, r.String())
+	}
+
+	return fmt.Sprintf("can't create %s, since it conflicts with %s; remove or update them first",
+		err.Record, strings.Join(existing, ", "))
+}
+
+// conflicts returns the records of existing which conflict with record, or
+// reports that existing has it already. CNAME records may not coexist with
+// any other record of the same name.
+func conflicts(record Record, existing []Record) (found bool, conflicting []Record) {
+	for _, r := range existing {
+		switch {
+		case strings.EqualFold(r.Type, record.Type) && strings.EqualFold(r.Content, record.Content):
+			return true, nil
+		case strings.EqualFold(r.Type, record.Type),
+			strings.EqualFold(r.Type, "CNAME"),
+			strings.EqualFold(record.Type, "CNAME"):
+			conflicting = append(conflicting, r)
+		}
+	}
+
+	return false, conflicting
+}
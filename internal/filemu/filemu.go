@@ -4,6 +4,7 @@ package filemu
 import (
 	"context"
 	"errors"
+	"os"
 	"time"
 
 	"github.com/gofrs/flock"
@@ -27,6 +28,26 @@ func RLock(ctx context.Context, path string) (UnlockFunc, error) {
 	return try(ctx, path, (*flock.Flock).TryRLockContext)
 }
 
+// Held reports whether a lock on the named file is held, by this process or
+// another. Files which don't exist aren't locked.
+func Held(path string) (bool, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	mu := flock.New(path)
+
+	locked, err := mu.TryLock()
+	switch {
+	case err != nil:
+		return false, err
+	case !locked:
+		return true, nil
+	default:
+		return false, mu.Unlock()
+	}
+}
+
 var errFailed = errors.New("failed acquiring lock")
 
 type lockFunc func(*flock.Flock, context.Context, time.Duration) (bool, error)
@@ -0,0 +1,43 @@
+package flyerr
+
+// Envelope is the machine-readable form of the errors commands fail with,
+// which the CLI prints on stderr when asked to via --error-json.
+type Envelope struct {
+	Error EnvelopeError `json:"error"`
+}
+
+// EnvelopeError wraps the details of an error an Envelope carries.
+type EnvelopeError struct {
+	// Message denotes the message of the error.
+	Message string `json:"message"`
+
+	// Kind denotes the kind of the error, per its exit code.
+	Kind string `json:"kind"`
+
+	// ExitCode denotes the code the CLI exits with.
+	ExitCode int `json:"exit_code"`
+
+	// Reason denotes the specific reason of the failure, if known; e.g. the
+	// phase a deployment failed at.
+	Reason string `json:"reason,omitempty"`
+
+	// Description denotes the description of the error, if any.
+	Description string `json:"description,omitempty"`
+
+	// Suggestion denotes a suggestion on how to address the error, if any.
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// NewEnvelope returns the Envelope of err, which the CLI exits with code on.
+func NewEnvelope(err error, code int) Envelope {
+	return Envelope{
+		Error: EnvelopeError{
+			Message:     err.Error(),
+			Kind:        Kind(code),
+			ExitCode:    code,
+			Reason:      GetReason(err),
+			Description: GetErrorDescription(err),
+			Suggestion:  GetErrorSuggestion(err),
+		},
+	}
+}
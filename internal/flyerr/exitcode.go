@@ -0,0 +1,297 @@
+package flyerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// The exit codes the CLI exits with. Wrappers, such as CI pipelines, may
+// branch on them to tell why a command failed without parsing its output.
+const (
+	// ExitCodeGeneric denotes a failure none of the other codes describe.
+	ExitCodeGeneric = 1
+
+	// ExitCodeValidation denotes the command was invoked with invalid
+	// arguments, flags or input.
+	ExitCodeValidation = 2
+
+	// ExitCodeAuth denotes the user is not authenticated, or not authorized
+	// to perform the operation.
+	ExitCodeAuth = 3
+
+	// ExitCodeNotFound denotes a resource the command refers to does not
+	// exist.
+	ExitCodeNotFound = 4
+
+	// ExitCodeTimeout denotes the CLI gave up waiting on an operation.
+	ExitCodeTimeout = 5
+
+	// ExitCodeDeployFailed denotes a deployment failed; the error envelope
+	// tells the phase it failed at.
+	ExitCodeDeployFailed = 6
+
+	// ExitCodeCancelled denotes the user cancelled the operation, e.g. by
+	// interrupting the CLI.
+	ExitCodeCancelled = 127
+)
+
+// kinds maps exit codes to the kinds of errors the error envelope reports.
+var kinds = map[int]string{
+	ExitCodeGeneric:      "generic",
+	ExitCodeValidation:   "validation",
+	ExitCodeAuth:         "auth",
+	ExitCodeNotFound:     "not_found",
+	ExitCodeTimeout:      "timeout",
+	ExitCodeDeployFailed: "deploy_failed",
+	ExitCodeCancelled:    "cancelled",
+}
+
+// Kind returns the name of the kind of errors the given exit code denotes.
+func Kind(code int) string {
+	if kind, ok := kinds[code]; ok {
+		return kind
+	}
+
+	return kinds[ExitCodeGeneric]
+}
+
+// ExitCoder is an error which denotes the code the CLI should exit with.
+type ExitCoder interface {
+	error
+	ExitCode() int
+}
+
+// GetExitCode returns the exit code the first ExitCoder in the chain of err
+// denotes, or 0 in case there's none.
+func GetExitCode(err error) int {
+	var ec ExitCoder
+	if errors.As(err, &ec) {
+		return ec.ExitCode()
+	}
+
+	return 0
+}
+
+// ExitCode returns the code the CLI should exit with on err; 0 for nil.
+//
+// Errors which carry no exit code are classified by their cause: timeouts, and
+// the API's authentication and not found errors. Anything else is generic.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	if code := GetExitCode(err); code != 0 {
+		return code
+	}
+
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ExitCodeCancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ExitCodeTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ExitCodeTimeout
+	case isAuthError(err):
+		return ExitCodeAuth
+	case isNotFoundError(err):
+		return ExitCodeNotFound
+	default:
+		return ExitCodeGeneric
+	}
+}
+
+func isAuthError(err error) bool {
+	var apiErr *api.ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr.Status == 401 || apiErr.Status == 403
+	}
+
+	// the API reports GraphQL errors by message only
+	msg := strings.ToLower(err.Error())
+
+	return strings.Contains(msg, "must be authenticated") ||
+		strings.Contains(msg, "not authorized") ||
+		strings.Contains(msg, "unauthorized")
+}
+
+func isNotFoundError(err error) bool {
+	var apiErr *api.ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr.Status == 404
+	}
+
+	if errors.Is(err, api.ErrNotFound) {
+		return true
+	}
+
+	msg := err.Error()
+
+	return strings.HasPrefix(msg, "Could not resolve") ||
+		strings.HasPrefix(msg, "Could not find")
+}
+
+// Reasoner is an error which tells the reason of a failure, in addition to its
+// kind.
+type Reasoner interface {
+	error
+	Reason() string
+}
+
+// GetReason returns the reason the first Reasoner in the chain of err tells,
+// or an empty string in case there's none.
+func GetReason(err error) string {
+	var r Reasoner
+	if errors.As(err, &r) {
+		return r.Reason()
+	}
+
+	return ""
+}
+
+// ValidationError wraps the errors of commands invoked with invalid
+// arguments, flags or input.
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string { return e.Err.Error() }
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+func (*ValidationError) ExitCode() int { return ExitCodeValidation }
+
+// AuthError wraps the errors which occur because the user is not authenticated
+// or not authorized.
+type AuthError struct {
+	Err error
+}
+
+func (e *AuthError) Error() string { return e.Err.Error() }
+
+func (e *AuthError) Unwrap() error { return e.Err }
+
+func (*AuthError) ExitCode() int { return ExitCodeAuth }
+
+// NotFoundError wraps the errors which occur because a resource does not
+// exist.
+type NotFoundError struct {
+	Err error
+}
+
+func (e *NotFoundError) Error() string { return e.Err.Error() }
+
+func (e *NotFoundError) Unwrap() error { return e.Err }
+
+func (*NotFoundError) ExitCode() int { return ExitCodeNotFound }
+
+// TimeoutError wraps the errors of commands which ran for longer than the
+// --timeout they were given. Err is nil for commands which failed to abort in
+// time.
+type TimeoutError struct {
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Err == nil || errors.Is(e.Err, context.DeadlineExceeded) {
+		return fmt.Sprintf("command timed out after %s", e.Timeout)
+	}
+
+	return fmt.Sprintf("command timed out after %s: %v", e.Timeout, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+func (*TimeoutError) ExitCode() int { return ExitCodeTimeout }
+
+// BuildError wraps the errors which occur while building or resolving the
+// image of a deployment.
+type BuildError struct {
+	Err error
+}
+
+func (e *BuildError) Error() string { return e.Err.Error() }
+
+func (e *BuildError) Unwrap() error { return e.Err }
+
+func (*BuildError) ExitCode() int { return ExitCodeDeployFailed }
+
+func (*BuildError) Reason() string { return "build_failed" }
+
+// ReleaseCommandError wraps the failures of release commands.
+type ReleaseCommandError struct {
+	Err error
+}
+
+func (e *ReleaseCommandError) Error() string { return e.Err.Error() }
+
+func (e *ReleaseCommandError) Unwrap() error { return e.Err }
+
+func (*ReleaseCommandError) ExitCode() int { return ExitCodeDeployFailed }
+
+func (*ReleaseCommandError) Reason() string { return "release_command_failed" }
+
+// HealthCheckError wraps the failures of deployments the instances of which
+// did not become healthy.
+type HealthCheckError struct {
+	Err error
+}
+
+func (e *HealthCheckError) Error() string { return e.Err.Error() }
+
+func (e *HealthCheckError) Unwrap() error { return e.Err }
+
+func (*HealthCheckError) ExitCode() int { return ExitCodeDeployFailed }
+
+func (*HealthCheckError) Reason() string { return "health_checks_failed" }
+
+// LoadTestError wraps the failures of the load tests deployments are gated
+// on.
+type LoadTestError struct {
+	Err error
+}
+
+func (e *LoadTestError) Error() string { return e.Err.Error() }
+
+func (e *LoadTestError) Unwrap() error { return e.Err }
+
+func (*LoadTestError) ExitCode() int { return ExitCodeDeployFailed }
+
+func (*LoadTestError) Reason() string { return "load_test_failed" }
+
+// RequirementError wraps the failures of the requirements the app config
+// declares deployments are gated on.
+type RequirementError struct {
+	Err error
+}
+
+func (e *RequirementError) Error() string { return e.Err.Error() }
+
+func (e *RequirementError) Unwrap() error { return e.Err }
+
+func (*RequirementError) ExitCode() int { return ExitCodeDeployFailed }
+
+func (*RequirementError) Reason() string { return "requirements_unmet" }
+
+// RuntimeAssertionError wraps the failures of the runtime assertions the new
+// instances of deployments are verified against.
+type RuntimeAssertionError struct {
+	Err error
+}
+
+func (e *RuntimeAssertionError) Error() string { return e.Err.Error() }
+
+func (e *RuntimeAssertionError) Unwrap() error { return e.Err }
+
+func (*RuntimeAssertionError) ExitCode() int { return ExitCodeDeployFailed }
+
+func (*RuntimeAssertionError) Reason() string { return "runtime_assertions_failed" }
@@ -0,0 +1,97 @@
+package flyerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestGetExitCode(t *testing.T) {
+	cause := errors.New("cause")
+
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{cause, 0},
+		{&BuildError{Err: cause}, ExitCodeDeployFailed},
+		{fmt.Errorf("deploying: %w", &ReleaseCommandError{Err: cause}), ExitCodeDeployFailed},
+		{&HealthCheckError{Err: ErrAbort}, ExitCodeDeployFailed},
+		{&LoadTestError{Err: cause}, ExitCodeDeployFailed},
+		{&RequirementError{Err: cause}, ExitCodeDeployFailed},
+		{&RuntimeAssertionError{Err: cause}, ExitCodeDeployFailed},
+		{&ValidationError{Err: cause}, ExitCodeValidation},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.code, GetExitCode(c.err), c.err.Error())
+	}
+
+	err := &BuildError{Err: fmt.Errorf("building: %w", context.Canceled)}
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, "building: context canceled", err.Error())
+}
+
+func TestExitCode(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{nil, 0},
+		{errors.New("boom"), ExitCodeGeneric},
+		{fmt.Errorf("failed: %w", &ValidationError{Err: errors.New("bad flag")}), ExitCodeValidation},
+		{&AuthError{Err: errors.New("no token")}, ExitCodeAuth},
+		{&api.ApiError{Message: "401 Unauthorized", Status: 401}, ExitCodeAuth},
+		{errors.New("You must be authenticated to view this."), ExitCodeAuth},
+		{&NotFoundError{Err: errors.New("gone")}, ExitCodeNotFound},
+		{fmt.Errorf("failed retrieving app: %w", api.ErrNotFound), ExitCodeNotFound},
+		{errors.New("Could not resolve App"), ExitCodeNotFound},
+		{fmt.Errorf("waiting: %w", context.DeadlineExceeded), ExitCodeTimeout},
+		{&net.OpError{Op: "dial", Err: timeoutError{}}, ExitCodeTimeout},
+		{&HealthCheckError{Err: errors.New("unhealthy")}, ExitCodeDeployFailed},
+		{fmt.Errorf("deploying: %w", context.Canceled), ExitCodeCancelled},
+		{&TimeoutError{Timeout: time.Minute, Err: net.ErrClosed}, ExitCodeTimeout},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.code, ExitCode(c.err), "%v", c.err)
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	err := &TimeoutError{Timeout: time.Minute}
+	assert.Equal(t, "command timed out after 1m0s", err.Error())
+
+	err = &TimeoutError{Timeout: time.Minute, Err: fmt.Errorf("waiting: %w", context.DeadlineExceeded)}
+	assert.Equal(t, "command timed out after 1m0s", err.Error())
+
+	err = &TimeoutError{Timeout: time.Minute, Err: net.ErrClosed}
+	assert.Equal(t, "command timed out after 1m0s: "+net.ErrClosed.Error(), err.Error())
+	assert.True(t, errors.Is(err, net.ErrClosed))
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestNewEnvelope(t *testing.T) {
+	err := fmt.Errorf("deploying: %w", &ReleaseCommandError{Err: errors.New("exit status 1")})
+
+	assert.Equal(t, Envelope{
+		Error: EnvelopeError{
+			Message:  "deploying: exit status 1",
+			Kind:     "deploy_failed",
+			ExitCode: ExitCodeDeployFailed,
+			Reason:   "release_command_failed",
+		},
+	}, NewEnvelope(err, ExitCode(err)))
+}
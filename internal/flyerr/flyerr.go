@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/logrusorgru/aurora"
+	"github.com/superfly/flyctl/pkg/iostreams"
 )
 
 // ErrAbort is an error for when the CLI aborts
@@ -49,7 +49,7 @@ func PrintCLIOutput(err error) {
 	}
 
 	fmt.Println()
-	fmt.Println(aurora.Red("Error"), err)
+	fmt.Println(iostreams.Aurora().Red("Error"), err)
 
 	description := GetErrorDescription(err)
 	suggestion := GetErrorSuggestion(err)
@@ -0,0 +1,251 @@
+// Package github implements the subset of the GitHub REST API flyctl uses to
+// report deployments.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultAPIURL = "https://api.github.com"
+
+// The states of deployment statuses.
+const (
+	StateQueued     = "queued"
+	StateInProgress = "in_progress"
+	StateSuccess    = "success"
+	StateFailure    = "failure"
+	StateError      = "error"
+	StateInactive   = "inactive"
+)
+
+// States denotes the valid states of deployment statuses.
+var States = []string{StateQueued, StateInProgress, StateSuccess, StateFailure, StateError, StateInactive}
+
+// ErrNoToken is returned by FromEnv when neither GITHUB_TOKEN nor GH_TOKEN is
+// set.
+var ErrNoToken = errors.New("GITHUB_TOKEN must be set; in GitHub Actions, pass it via env: GITHUB_TOKEN: ${{ secrets.GITHUB_TOKEN }}")
+
+// Client wraps the GitHub API of a repository.
+type Client struct {
+	// APIURL denotes the base URL of the API.
+	APIURL string
+
+	// Repository denotes the owner/name of the repository.
+	Repository string
+
+	// Token denotes the token requests authenticate with.
+	Token string
+
+	HTTPClient *http.Client
+}
+
+// FromEnv returns a Client for the repository and token the environment of
+// GitHub Actions denotes. The given repository, if any, overrides the one
+// GITHUB_REPOSITORY denotes.
+func FromEnv(repository string) (*Client, error) {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	if token == "" {
+		return nil, ErrNoToken
+	}
+
+	if repository == "" {
+		repository = os.Getenv("GITHUB_REPOSITORY")
+	}
+	if strings.Count(repository, "/") != 1 {
+		return nil, fmt.Errorf("invalid repository %q; set --repo or GITHUB_REPOSITORY to <owner>/<name>", repository)
+	}
+
+	apiURL := os.Getenv("GITHUB_API_URL")
+	if apiURL == "" {
+		apiURL = defaultAPIURL
+	}
+
+	return &Client{
+		APIURL:     strings.TrimSuffix(apiURL, "/"),
+		Repository: repository,
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// RunURL returns the URL of the GitHub Actions run the environment denotes,
+// if any.
+func RunURL() string {
+	server, repo, run := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID")
+	if server == "" || repo == "" || run == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/%s/actions/runs/%s", server, repo, run)
+}
+
+// Deployment wraps a GitHub deployment.
+type Deployment struct {
+	ID          int64  `json:"id"`
+	Ref         string `json:"ref"`
+	SHA         string `json:"sha"`
+	Environment string `json:"environment"`
+	Description string `json:"description"`
+}
+
+// DeploymentInput wraps the properties of deployments to create.
+type DeploymentInput struct {
+	Ref         string `json:"ref"`
+	Environment string `json:"environment"`
+	Description string `json:"description,omitempty"`
+}
+
+// DeploymentStatus wraps the properties of deployment statuses to create.
+type DeploymentStatus struct {
+	State          string `json:"state"`
+	Description    string `json:"description,omitempty"`
+	LogURL         string `json:"log_url,omitempty"`
+	EnvironmentURL string `json:"environment_url,omitempty"`
+	Environment    string `json:"environment,omitempty"`
+}
+
+// FindDeployment returns the latest deployment of ref to the given
+// environment, or nil in case there's none.
+func (c *Client) FindDeployment(ctx context.Context, ref, environment string) (*Deployment, error) {
+	q := url.Values{}
+	q.Set("environment", environment)
+	q.Set("per_page", "1")
+	if isSHA(ref) {
+		q.Set("sha", ref)
+	} else {
+		q.Set("ref", ref)
+	}
+
+	var deployments []*Deployment
+	if err := c.do(ctx, http.MethodGet, "/deployments?"+q.Encode(), nil, &deployments); err != nil {
+		return nil, fmt.Errorf("failed listing deployments: %w", err)
+	}
+
+	if len(deployments) == 0 {
+		return nil, nil
+	}
+
+	return deployments[0], nil
+}
+
+// CreateDeployment creates a deployment. Commit status checks are not
+// required to pass, since deployments from CI run before they complete.
+func (c *Client) CreateDeployment(ctx context.Context, in DeploymentInput) (*Deployment, error) {
+	body := struct {
+		DeploymentInput
+		AutoMerge        bool     `json:"auto_merge"`
+		RequiredContexts []string `json:"required_contexts"`
+	}{
+		DeploymentInput:  in,
+		RequiredContexts: []string{},
+	}
+
+	var d Deployment
+	if err := c.do(ctx, http.MethodPost, "/deployments", body, &d); err != nil {
+		return nil, fmt.Errorf("failed creating deployment: %w", err)
+	}
+
+	return &d, nil
+}
+
+// EnsureDeployment returns the latest deployment of the given ref to the
+// given environment, creating one in case there's none.
+func (c *Client) EnsureDeployment(ctx context.Context, in DeploymentInput) (*Deployment, error) {
+	d, err := c.FindDeployment(ctx, in.Ref, in.Environment)
+	if err != nil || d != nil {
+		return d, err
+	}
+
+	return c.CreateDeployment(ctx, in)
+}
+
+// CreateDeploymentStatus adds the given status to the deployment with the
+// given ID.
+func (c *Client) CreateDeploymentStatus(ctx context.Context, id int64, status DeploymentStatus) error {
+	path := fmt.Sprintf("/deployments/%d/statuses", id)
+	if err := c.do(ctx, http.MethodPost, path, status, nil); err != nil {
+		return fmt.Errorf("failed creating deployment status: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	u := fmt.Sprintf("%s/repos/%s%s", c.APIURL, c.Repository, path)
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(res.Body).Decode(&apiErr)
+
+		if apiErr.Message != "" {
+			return fmt.Errorf("github responded with %s: %s", res.Status, apiErr.Message)
+		}
+
+		return fmt.Errorf("github responded with %s", res.Status)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+func isSHA(ref string) bool {
+	if len(ref) != 40 {
+		return false
+	}
+
+	for _, r := range ref {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+
+	return true
+}
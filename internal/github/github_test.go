@@ -0,0 +1,89 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsureDeployment(t *testing.T) {
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+
+	var (
+		created  bool
+		statuses []DeploymentStatus
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/app/deployments":
+			assert.Equal(t, sha, r.URL.Query().Get("sha"))
+			assert.Equal(t, "staging", r.URL.Query().Get("environment"))
+
+			if created {
+				_, _ = w.Write([]byte(`[{"id":7,"sha":"` + sha + `","environment":"staging"}]`))
+			} else {
+				_, _ = w.Write([]byte(`[]`))
+			}
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/app/deployments":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, sha, body["ref"])
+			assert.Equal(t, []interface{}{}, body["required_contexts"])
+
+			created = true
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":7}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/app/deployments/7/statuses":
+			var status DeploymentStatus
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&status))
+			statuses = append(statuses, status)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := &Client{APIURL: srv.URL, Repository: "acme/app", Token: "token"}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		d, err := c.EnsureDeployment(ctx, DeploymentInput{Ref: sha, Environment: "staging"})
+		require.NoError(t, err)
+		assert.EqualValues(t, 7, d.ID)
+	}
+
+	require.NoError(t, c.CreateDeploymentStatus(ctx, 7, DeploymentStatus{State: StateSuccess}))
+	assert.Equal(t, []DeploymentStatus{{State: StateSuccess}}, statuses)
+
+	err := c.CreateDeploymentStatus(ctx, 8, DeploymentStatus{State: StateSuccess})
+	assert.EqualError(t, err, "failed creating deployment status: github responded with 404 Not Found: Not Found")
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_TOKEN", "")
+
+	_, err := FromEnv("acme/app")
+	assert.ErrorIs(t, err, ErrNoToken)
+
+	t.Setenv("GITHUB_TOKEN", "token")
+	t.Setenv("GITHUB_REPOSITORY", "acme/app")
+	t.Setenv("GITHUB_API_URL", "https://github.example.com/api/v3/")
+
+	c, err := FromEnv("")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.example.com/api/v3", c.APIURL)
+	assert.Equal(t, "acme/app", c.Repository)
+
+	_, err = FromEnv("app")
+	assert.Error(t, err)
+}
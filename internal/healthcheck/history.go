@@ -0,0 +1,210 @@
+// Package healthcheck implements the local history of the health checks of
+// apps: the transitions between states flyctl observes each time it lists
+// them, kept so that flapping checks may be diagnosed after the fact.
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/flyctl"
+	"github.com/superfly/flyctl/internal/filemu"
+)
+
+const (
+	// retention is how long transitions are kept for.
+	retention = 30 * 24 * time.Hour
+
+	// maxTransitions caps the number of transitions kept per app.
+	maxTransitions = 10000
+)
+
+// Transition denotes a change in the status of a check of an allocation. The
+// first observation of each check is recorded as a transition from no status.
+type Transition struct {
+	Check      string    `json:"check"`
+	Allocation string    `json:"allocation"`
+	Region     string    `json:"region"`
+	From       string    `json:"from,omitempty"`
+	To         string    `json:"to"`
+	At         time.Time `json:"at"`
+	Output     string    `json:"output,omitempty"`
+}
+
+// IsFirstSeen reports whether t is the first observation of its check rather
+// than a change in its status.
+func (t *Transition) IsFirstSeen() bool {
+	return t.From == ""
+}
+
+// observed denotes the last observed state of a check of an allocation.
+type observed struct {
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// History wraps the check history of an app.
+type History struct {
+	// Last holds the last observed state of each check, keyed by allocation
+	// and check name.
+	Last map[string]observed `json:"last"`
+
+	// Transitions holds the observed transitions, oldest first.
+	Transitions []Transition `json:"transitions"`
+}
+
+func historyPath(appName string) string {
+	return filepath.Join(flyctl.ConfigDir(), "check-history", appName+".json")
+}
+
+// Record records the given current states of the checks of the named app and
+// returns the transitions they denote.
+func Record(ctx context.Context, appName string, checks []api.CheckState) (transitions []Transition, err error) {
+	err = update(ctx, historyPath(appName), func(h *History) {
+		transitions = h.observe(checks, time.Now().UTC())
+	})
+
+	return
+}
+
+// Load returns the check history of the named app. It returns an empty
+// History in case nothing has been recorded for it.
+func Load(ctx context.Context, appName string) (*History, error) {
+	path := historyPath(appName)
+
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return read(path)
+	}
+
+	unlock, err := filemu.RLock(ctx, path+".lock")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = unlock() }()
+
+	return read(path)
+}
+
+// Since returns the transitions of h which happened at or after the given time,
+// of the named check only unless check is empty.
+func (h *History) Since(check string, since time.Time) (transitions []Transition) {
+	for _, t := range h.Transitions {
+		if (check == "" || t.Check == check) && !t.At.Before(since) {
+			transitions = append(transitions, t)
+		}
+	}
+
+	return
+}
+
+func (h *History) observe(checks []api.CheckState, now time.Time) (transitions []Transition) {
+	for _, c := range checks {
+		var alloc, region string
+		if c.Allocation != nil {
+			alloc, region = c.Allocation.IDShort, c.Allocation.Region
+		}
+
+		key := alloc + "/" + c.Name
+		last, seen := h.Last[key]
+
+		if seen && last.Status == c.Status {
+			continue
+		}
+
+		at := c.UpdatedAt
+		if at.IsZero() || (seen && !at.After(last.UpdatedAt)) {
+			at = now
+		}
+
+		transitions = append(transitions, Transition{
+			Check:      c.Name,
+			Allocation: alloc,
+			Region:     region,
+			From:       last.Status,
+			To:         c.Status,
+			At:         at.UTC(),
+			Output:     c.Output,
+		})
+
+		h.Last[key] = observed{
+			Status:    c.Status,
+			UpdatedAt: at.UTC(),
+		}
+	}
+
+	h.Transitions = append(h.Transitions, transitions...)
+	h.prune(now)
+
+	return
+}
+
+func (h *History) prune(now time.Time) {
+	sort.SliceStable(h.Transitions, func(i, j int) bool {
+		return h.Transitions[i].At.Before(h.Transitions[j].At)
+	})
+
+	cutoff := now.Add(-retention)
+
+	i := sort.Search(len(h.Transitions), func(i int) bool {
+		return !h.Transitions[i].At.Before(cutoff)
+	})
+	if n := len(h.Transitions) - maxTransitions; n > i {
+		i = n
+	}
+
+	h.Transitions = h.Transitions[i:]
+}
+
+func update(ctx context.Context, path string, fn func(*History)) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	unlock, err := filemu.Lock(ctx, path+".lock")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = unlock() }()
+
+	h, err := read(path)
+	if err != nil {
+		return err
+	}
+
+	fn(h)
+
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0600)
+}
+
+func read(path string) (*History, error) {
+	h := &History{}
+
+	switch data, err := os.ReadFile(path); {
+	case errors.Is(err, fs.ErrNotExist):
+		break
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, h); err != nil {
+			return nil, err
+		}
+	}
+
+	if h.Last == nil {
+		h.Last = map[string]observed{}
+	}
+
+	return h, nil
+}
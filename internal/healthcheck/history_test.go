@@ -0,0 +1,65 @@
+package healthcheck
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestObserve(t *testing.T) {
+	var (
+		now   = time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+		alloc = &api.AllocationStatus{IDShort: "abcd1234", Region: "iad"}
+		h     = &History{Last: map[string]observed{}}
+	)
+
+	check := func(status string, updatedAt time.Time) []api.CheckState {
+		return []api.CheckState{
+			{Name: "http", Status: status, Allocation: alloc, UpdatedAt: updatedAt},
+		}
+	}
+
+	got := h.observe(check("passing", now.Add(-time.Hour)), now)
+	if assert.Len(t, got, 1) {
+		assert.True(t, got[0].IsFirstSeen())
+		assert.Equal(t, now.Add(-time.Hour), got[0].At)
+	}
+
+	// unchanged states are no transitions
+	assert.Empty(t, h.observe(check("passing", now.Add(-time.Hour)), now))
+
+	got = h.observe(check("critical", now.Add(time.Minute)), now.Add(2*time.Minute))
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, "passing", got[0].From)
+		assert.Equal(t, "critical", got[0].To)
+		assert.Equal(t, now.Add(time.Minute), got[0].At)
+		assert.Equal(t, "iad", got[0].Region)
+	}
+
+	// a stale updatedAt falls back to the time of the observation
+	got = h.observe(check("passing", now), now.Add(3*time.Minute))
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, now.Add(3*time.Minute), got[0].At)
+	}
+
+	assert.Len(t, h.Since("http", now), 2)
+	assert.Len(t, h.Since("", now.Add(-2*time.Hour)), 3)
+	assert.Empty(t, h.Since("tcp", now.Add(-2*time.Hour)))
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	h := &History{
+		Transitions: []Transition{
+			{Check: "new", At: now.Add(-time.Hour)},
+			{Check: "old", At: now.Add(-retention - time.Hour)},
+		},
+	}
+
+	h.prune(now)
+	assert.Equal(t, []Transition{{Check: "new", At: now.Add(-time.Hour)}}, h.Transitions)
+}
@@ -0,0 +1,224 @@
+package healthcheck
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// FlapThreshold is the number of status changes over the window of a
+	// report a check has to go through to be considered flapping.
+	FlapThreshold = 3
+
+	// flapPenalty is the number of points each flapping check costs the
+	// stability score.
+	flapPenalty = 5
+)
+
+// CheckReport wraps the stability of a check of an allocation over the
+// window of a report.
+type CheckReport struct {
+	Check      string `json:"check"`
+	Allocation string `json:"allocation"`
+	Region     string `json:"region"`
+	Status     string `json:"status"`
+
+	// Changes is the number of status changes over the window.
+	Changes int `json:"changes"`
+
+	// ChangesPerDay is Changes, over the days of the window.
+	ChangesPerDay float64 `json:"changes_per_day"`
+
+	// Passing is the share of the observed time of the check it spent
+	// passing, from 0 to 1.
+	Passing float64 `json:"passing"`
+
+	Flapping bool `json:"flapping"`
+
+	// Timeline charts the status of the check over the window, by the worst
+	// status of each slice of it: ▁ passing, ▄ warning, █ critical and
+	// blanks for slices in which the check was not observed.
+	Timeline string `json:"timeline"`
+}
+
+// Report wraps the stability of the checks of an app over a window.
+type Report struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+
+	// Score is the share of the observed time the checks spent passing, as a
+	// percentage, less a few points per flapping check.
+	Score float64 `json:"score"`
+
+	// Checks holds the checks, the least stable first.
+	Checks []CheckReport `json:"checks"`
+}
+
+// Flapping returns the reports of the checks which are flapping.
+func (r *Report) Flapping() (flapping []CheckReport) {
+	for _, c := range r.Checks {
+		if c.Flapping {
+			flapping = append(flapping, c)
+		}
+	}
+
+	return
+}
+
+// Report analyzes the transitions of the checks of h over the [since, until]
+// window, of the named check only unless check is empty. Timelines are width
+// runes wide.
+func (h *History) Report(check string, since, until time.Time, width int) *Report {
+	type key struct{ alloc, check string }
+
+	byCheck := map[key][]Transition{}
+	for _, t := range h.Transitions {
+		if (check != "" && t.Check != check) || t.At.After(until) {
+			continue
+		}
+
+		k := key{t.Allocation, t.Check}
+		byCheck[k] = append(byCheck[k], t)
+	}
+
+	r := &Report{
+		Since: since,
+		Until: until,
+		Score: 100,
+	}
+
+	days := until.Sub(since).Hours() / 24
+
+	// checks which changed last before the window, and thus held their status
+	// through it, count as well
+	var passing float64
+	for _, transitions := range byCheck {
+		c := analyze(transitions, since, until, width)
+
+		if days > 0 {
+			c.ChangesPerDay = float64(c.Changes) / days
+		}
+		c.Flapping = c.Changes >= FlapThreshold
+
+		passing += c.Passing
+		r.Checks = append(r.Checks, c)
+	}
+
+	if n := len(r.Checks); n > 0 {
+		r.Score = 100*passing/float64(n) - float64(flapPenalty*len(r.Flapping()))
+		r.Score = math.Max(0, math.Round(r.Score*10)/10)
+	}
+
+	sort.Slice(r.Checks, func(i, j int) bool {
+		a, b := r.Checks[i], r.Checks[j]
+
+		if a.Changes != b.Changes {
+			return a.Changes > b.Changes
+		} else if a.Passing != b.Passing {
+			return a.Passing < b.Passing
+		} else if a.Check != b.Check {
+			return a.Check < b.Check
+		}
+
+		return a.Allocation < b.Allocation
+	})
+
+	return r
+}
+
+// analyze analyzes the transitions of a check of an allocation, oldest first,
+// over the [since, until] window.
+func analyze(transitions []Transition, since, until time.Time, width int) (c CheckReport) {
+	last := transitions[len(transitions)-1]
+
+	c.Check = last.Check
+	c.Allocation = last.Allocation
+	c.Region = last.Region
+	c.Status = last.To
+
+	var (
+		window   = until.Sub(since)
+		observed time.Duration
+		passing  time.Duration
+		slices   = make([]int, width)
+	)
+
+	for i := range slices {
+		slices[i] = -1
+	}
+
+	for i, t := range transitions {
+		if !t.IsFirstSeen() && !t.At.Before(since) {
+			c.Changes++
+		}
+
+		// the check held the status of t until the next transition
+		from, to := t.At, until
+		if i+1 < len(transitions) {
+			to = transitions[i+1].At
+		}
+
+		if from.Before(since) {
+			from = since
+		}
+		if !to.After(from) {
+			continue
+		}
+
+		observed += to.Sub(from)
+		if t.To == "passing" {
+			passing += to.Sub(from)
+		}
+
+		if width == 0 || window <= 0 {
+			continue
+		}
+
+		sev := severity(t.To)
+
+		first := int(float64(from.Sub(since)) / float64(window) * float64(width))
+		lastSlice := int(math.Ceil(float64(to.Sub(since))/float64(window)*float64(width))) - 1
+		for s := first; s <= lastSlice && s < width; s++ {
+			if s >= 0 && sev > slices[s] {
+				slices[s] = sev
+			}
+		}
+	}
+
+	if observed > 0 {
+		c.Passing = float64(passing) / float64(observed)
+	}
+
+	c.Timeline = timeline(slices)
+
+	return
+}
+
+var timelineRunes = []rune("▁▄█")
+
+func timeline(slices []int) string {
+	var sb strings.Builder
+
+	for _, sev := range slices {
+		if sev < 0 {
+			sb.WriteRune(' ')
+		} else {
+			sb.WriteRune(timelineRunes[sev])
+		}
+	}
+
+	return sb.String()
+}
+
+func severity(status string) int {
+	switch status {
+	case "passing":
+		return 0
+	case "warning":
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,77 @@
+package healthcheck
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport(t *testing.T) {
+	var (
+		since = time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+		until = since.Add(10 * time.Hour)
+	)
+
+	at := func(h int) time.Time {
+		return since.Add(time.Duration(h) * time.Hour)
+	}
+
+	h := &History{
+		Transitions: []Transition{
+			// steady since before the window
+			{Check: "tcp", Allocation: "a", To: "passing", At: at(-5)},
+
+			// flapping through the second half of the window
+			{Check: "http", Allocation: "b", To: "passing", At: at(0)},
+			{Check: "http", Allocation: "b", From: "passing", To: "critical", At: at(5)},
+			{Check: "http", Allocation: "b", From: "critical", To: "passing", At: at(6)},
+			{Check: "http", Allocation: "b", From: "passing", To: "warning", At: at(8)},
+			{Check: "http", Allocation: "b", From: "warning", To: "passing", At: at(9)},
+		},
+	}
+
+	r := h.Report("", since, until, 10)
+	if !assert.Len(t, r.Checks, 2) {
+		return
+	}
+
+	flapping := r.Checks[0]
+	assert.Equal(t, "http", flapping.Check)
+	assert.Equal(t, "passing", flapping.Status)
+	assert.Equal(t, 4, flapping.Changes)
+	assert.True(t, flapping.Flapping)
+	assert.InDelta(t, 0.8, flapping.Passing, 0.001)
+	assert.InDelta(t, 9.6, flapping.ChangesPerDay, 0.001)
+	assert.Equal(t, "▁▁▁▁▁█▁▁▄▁", flapping.Timeline)
+
+	steady := r.Checks[1]
+	assert.Equal(t, "tcp", steady.Check)
+	assert.Equal(t, 0, steady.Changes)
+	assert.False(t, steady.Flapping)
+	assert.Equal(t, 1.0, steady.Passing)
+
+	// (100% + 80%) / 2, less 5 points for the flapping one
+	assert.Equal(t, 85.0, r.Score)
+	assert.Len(t, r.Flapping(), 1)
+
+	assert.Len(t, h.Report("tcp", since, until, 10).Checks, 1)
+}
+
+func TestReportPartialTimeline(t *testing.T) {
+	since := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	h := &History{
+		Transitions: []Transition{
+			{Check: "http", Allocation: "a", To: "critical", At: since.Add(5 * time.Hour)},
+		},
+	}
+
+	r := h.Report("", since, since.Add(10*time.Hour), 10)
+	if assert.Len(t, r.Checks, 1) {
+		assert.Equal(t, "     █████", r.Checks[0].Timeline)
+		assert.Equal(t, 0.0, r.Checks[0].Passing)
+	}
+
+	assert.Equal(t, 100.0, (&History{}).Report("", since, since.Add(time.Hour), 10).Score)
+}
@@ -0,0 +1,105 @@
+package healthcheck
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Tuning wraps the parameters of health checks which may be updated without
+// redeploying the image of the app. Zero parameters are left as they are.
+type Tuning struct {
+	Interval    time.Duration
+	Timeout     time.Duration
+	GracePeriod time.Duration
+}
+
+// IsZero reports whether t leaves all parameters as they are.
+func (t Tuning) IsZero() bool {
+	return t.Interval == 0 && t.Timeout == 0 && t.GracePeriod == 0
+}
+
+func (t Tuning) apply(check map[string]interface{}) {
+	if t.Interval > 0 {
+		check["interval"] = t.Interval.String()
+	}
+	if t.Timeout > 0 {
+		check["timeout"] = t.Timeout.String()
+	}
+	if t.GracePeriod > 0 {
+		check["grace_period"] = t.GracePeriod.String()
+	}
+}
+
+// TuneDefinition applies t to the checks of the given app config definition
+// name selects, which are all of them in case name is empty, and returns the
+// names of those it updated. Checks of the checks section are named by their
+// key; those of services by their name setting, if any, or their position.
+func TuneDefinition(def map[string]interface{}, name string, t Tuning) (tuned []string) {
+	if checks, ok := def["checks"].(map[string]interface{}); ok {
+		keys := make([]string, 0, len(checks))
+		for key := range checks {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if check, ok := checks[key].(map[string]interface{}); ok && (name == "" || name == key) {
+				t.apply(check)
+				tuned = append(tuned, key)
+			}
+		}
+	}
+
+	for i, service := range tables(def["services"]) {
+		for _, kind := range []string{"tcp_checks", "http_checks"} {
+			prefix := fmt.Sprintf("services[%d].%s", i, kind)
+			tuned = append(tuned, tuneChecks(tables(service[kind]), prefix, name, t)...)
+		}
+	}
+
+	return
+}
+
+// TuneMachineServices applies t to the checks of the given services of a
+// machine config name selects, as TuneDefinition does.
+func TuneMachineServices(services []interface{}, name string, t Tuning) (tuned []string) {
+	for i, service := range tables(services) {
+		prefix := fmt.Sprintf("services[%d].checks", i)
+		tuned = append(tuned, tuneChecks(tables(service["checks"]), prefix, name, t)...)
+	}
+
+	return
+}
+
+func tuneChecks(checks []map[string]interface{}, prefix, name string, t Tuning) (tuned []string) {
+	for i, check := range checks {
+		checkName, _ := check["name"].(string)
+		if checkName == "" {
+			checkName = fmt.Sprintf("%s[%d]", prefix, i)
+		}
+
+		if name == "" || name == checkName {
+			t.apply(check)
+			tuned = append(tuned, checkName)
+		}
+	}
+
+	return
+}
+
+// tables returns the tables of v, which is a list of them.
+func tables(v interface{}) (tables []map[string]interface{}) {
+	switch v := v.(type) {
+	case []map[string]interface{}:
+		return v
+	case []interface{}:
+		for _, item := range v {
+			if table, ok := item.(map[string]interface{}); ok {
+				tables = append(tables, table)
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,60 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tuneDefinition = `{
+	"checks": {
+		"alive": {"type": "tcp", "port": 8080, "interval": "10s"},
+		"db": {"type": "http", "port": 8080, "path": "/db"}
+	},
+	"services": [{
+		"internal_port": 8080,
+		"tcp_checks": [{"interval": 10000, "timeout": 2000}],
+		"http_checks": [{"name": "health", "path": "/health"}]
+	}]
+}`
+
+func TestTuneDefinition(t *testing.T) {
+	var def map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(tuneDefinition), &def))
+
+	tuned := TuneDefinition(def, "", Tuning{Interval: 15 * time.Second})
+	assert.Equal(t, []string{"alive", "db", "services[0].tcp_checks[0]", "health"}, tuned)
+
+	service := def["services"].([]interface{})[0].(map[string]interface{})
+	tcp := service["tcp_checks"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "15s", tcp["interval"])
+	assert.Equal(t, 2000.0, tcp["timeout"], "parameters the tuning leaves are kept")
+
+	tuned = TuneDefinition(def, "health", Tuning{GracePeriod: time.Minute})
+	assert.Equal(t, []string{"health"}, tuned)
+
+	health := service["http_checks"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "1m0s", health["grace_period"])
+	assert.Equal(t, "15s", health["interval"])
+
+	assert.Empty(t, TuneDefinition(def, "missing", Tuning{Timeout: time.Second}))
+}
+
+func TestTuneMachineServices(t *testing.T) {
+	services := []interface{}{
+		map[string]interface{}{
+			"checks": []interface{}{
+				map[string]interface{}{"type": "tcp", "interval": "5s"},
+			},
+		},
+	}
+
+	tuned := TuneMachineServices(services, "", Tuning{Interval: 30 * time.Second, Timeout: 3 * time.Second})
+	assert.Equal(t, []string{"services[0].checks[0]"}, tuned)
+
+	check := services[0].(map[string]interface{})["checks"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "tcp", "interval": "30s", "timeout": "3s"}, check)
+}
@@ -0,0 +1,110 @@
+package loadtest
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// bucketGrowth is the factor by which the bounds of consecutive histogram
+// buckets grow, which bounds the relative error of the quantiles a Histogram
+// reports to 5%.
+const bucketGrowth = 1.05
+
+var logBucketGrowth = math.Log(bucketGrowth)
+
+// Histogram records a distribution of latencies in exponentially growing
+// buckets. Histograms recorded in different places may be merged.
+type Histogram struct {
+	// Buckets maps the indexes of the buckets of the histogram to the number
+	// of latencies they hold. Empty buckets are omitted.
+	Buckets map[int]int   `json:"buckets"`
+	Count   int           `json:"count"`
+	Sum     time.Duration `json:"sum"`
+	Max     time.Duration `json:"max"`
+}
+
+func bucketOf(d time.Duration) int {
+	us := float64(d / time.Microsecond)
+	if us < 1 {
+		return 0
+	}
+
+	return int(math.Log(us) / logBucketGrowth)
+}
+
+// upperBound returns the largest latency the bucket of the given index holds.
+func upperBound(bucket int) time.Duration {
+	return time.Duration(math.Exp(float64(bucket+1)*logBucketGrowth)) * time.Microsecond
+}
+
+// Record adds d to h.
+func (h *Histogram) Record(d time.Duration) {
+	if h.Buckets == nil {
+		h.Buckets = make(map[int]int)
+	}
+
+	h.Buckets[bucketOf(d)]++
+	h.Count++
+	h.Sum += d
+
+	if d > h.Max {
+		h.Max = d
+	}
+}
+
+// Merge adds the latencies other holds to h.
+func (h *Histogram) Merge(other Histogram) {
+	if h.Buckets == nil {
+		h.Buckets = make(map[int]int, len(other.Buckets))
+	}
+
+	for bucket, n := range other.Buckets {
+		h.Buckets[bucket] += n
+	}
+
+	h.Count += other.Count
+	h.Sum += other.Sum
+
+	if other.Max > h.Max {
+		h.Max = other.Max
+	}
+}
+
+// Mean returns the mean of the latencies h holds.
+func (h *Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Quantile returns an estimate of the latency below which the given ratio,
+// between 0 and 1, of the latencies h holds are.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+
+	buckets := make([]int, 0, len(h.Buckets))
+	for bucket := range h.Buckets {
+		buckets = append(buckets, bucket)
+	}
+	sort.Ints(buckets)
+
+	rank := int(math.Ceil(q * float64(h.Count)))
+
+	var seen int
+	for _, bucket := range buckets {
+		if seen += h.Buckets[bucket]; seen >= rank {
+			if ub := upperBound(bucket); ub < h.Max {
+				return ub
+			}
+
+			break
+		}
+	}
+
+	return h.Max
+}
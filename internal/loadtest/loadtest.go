@@ -0,0 +1,347 @@
+// Package loadtest implements an open-loop HTTP load generator and the
+// reports it produces.
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxRPS is the highest rate of requests per second a single generator
+// sends.
+const MaxRPS = 5000
+
+// Options wraps the set of options Run accepts.
+type Options struct {
+	// URL denotes the URL requests are sent to.
+	URL string
+
+	// RPS denotes the rate of requests per second to send.
+	RPS int
+
+	// Duration denotes for how long to send requests for.
+	Duration time.Duration
+
+	// Timeout denotes how long requests may take before they're counted as
+	// errors. Defaults to 10 seconds.
+	Timeout time.Duration
+
+	// Client denotes the client requests are sent with. Defaults to a client
+	// which doesn't follow redirects.
+	Client *http.Client
+}
+
+// Validate returns an error in case opts are invalid.
+func (opts *Options) Validate() error {
+	switch {
+	case opts.URL == "":
+		return errors.New("a URL to load test is required")
+	case opts.RPS <= 0:
+		return errors.New("the rate of requests must be positive")
+	case opts.RPS > MaxRPS:
+		return fmt.Errorf("the rate of requests may not exceed %d per second per generator", MaxRPS)
+	case opts.Duration <= 0:
+		return errors.New("the duration must be positive")
+	default:
+		return nil
+	}
+}
+
+// Run sends requests to the URL opts define at a constant rate, regardless of
+// how quickly they're responded to, and reports on the responses. Requests
+// which are due while too many others are in flight are dropped and counted
+// as errors.
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+
+	var (
+		report = &Report{Statuses: map[int]int{}}
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		// allow for as many requests in flight as may time out at once
+		inFlight = make(chan struct{}, opts.RPS*int(opts.Timeout/time.Second+1))
+	)
+
+	record := func(status int, latency time.Duration, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		report.Requests++
+
+		switch {
+		case err != nil:
+			report.Errors++
+			report.Failures++
+		case status >= 500:
+			report.Errors++
+			fallthrough
+		default:
+			report.Statuses[status]++
+			report.Latency.Record(latency)
+		}
+	}
+
+	ticker := time.NewTicker(time.Second / time.Duration(opts.RPS))
+	defer ticker.Stop()
+
+	started := time.Now()
+	deadline := time.NewTimer(opts.Duration)
+	defer deadline.Stop()
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-deadline.C:
+			break loop
+		case <-ticker.C:
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			record(0, 0, errDropped)
+
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-inFlight
+				wg.Done()
+			}()
+
+			status, latency, err := send(ctx, client, opts)
+			if ctx.Err() != nil {
+				return // interrupted requests say nothing about the target
+			}
+
+			record(status, latency, err)
+		}()
+	}
+
+	wg.Wait()
+	report.Duration = time.Since(started)
+
+	return report, ctx.Err()
+}
+
+var errDropped = errors.New("request dropped")
+
+func send(ctx context.Context, client *http.Client, opts Options) (status int, latency time.Duration, err error) {
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, opts.URL, nil); err != nil {
+		return
+	}
+	req.Header.Set("User-Agent", "flyctl-load")
+
+	started := time.Now()
+
+	var res *http.Response
+	if res, err = client.Do(req); err != nil {
+		return
+	}
+	defer res.Body.Close()
+
+	if _, err = io.Copy(ioutil.Discard, res.Body); err != nil {
+		return
+	}
+
+	return res.StatusCode, time.Since(started), nil
+}
+
+// Report wraps the outcome of a load test.
+type Report struct {
+	// Region denotes the region the requests were sent from, if known.
+	Region string `json:"region,omitempty"`
+
+	Duration time.Duration `json:"duration"`
+	Requests int           `json:"requests"`
+
+	// Errors denotes the number of requests which failed; the ones which
+	// got no response and the ones responded to with a 5xx status.
+	Errors int `json:"errors"`
+
+	// Failures denotes the number of requests which got no response, since
+	// they failed, timed out or were dropped.
+	Failures int `json:"failures"`
+
+	// Statuses maps the status codes of responses to their number.
+	Statuses map[int]int `json:"statuses"`
+
+	// Latency denotes the distribution of the latencies of the requests
+	// which got a response.
+	Latency Histogram `json:"latency"`
+}
+
+// Merge returns the report of all the given reports combined.
+func Merge(reports ...*Report) *Report {
+	merged := &Report{Statuses: map[int]int{}}
+
+	for _, r := range reports {
+		if r.Duration > merged.Duration {
+			merged.Duration = r.Duration
+		}
+
+		merged.Requests += r.Requests
+		merged.Errors += r.Errors
+		merged.Failures += r.Failures
+
+		for status, n := range r.Statuses {
+			merged.Statuses[status] += n
+		}
+
+		merged.Latency.Merge(r.Latency)
+	}
+
+	return merged
+}
+
+// ErrorRate returns the ratio, between 0 and 1, of the requests which failed.
+func (r *Report) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+
+	return float64(r.Errors) / float64(r.Requests)
+}
+
+// RPS returns the rate of requests per second which were sent.
+func (r *Report) RPS() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+
+	return float64(r.Requests) / r.Duration.Seconds()
+}
+
+// StatusSummary returns the number of responses per status code, e.g.
+// "200: 1180, 503: 20".
+func (r *Report) StatusSummary() string {
+	statuses := make([]int, 0, len(r.Statuses))
+	for status := range r.Statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+
+	parts := make([]string, 0, len(statuses)+1)
+	for _, status := range statuses {
+		parts = append(parts, fmt.Sprintf("%d: %d", status, r.Statuses[status]))
+	}
+
+	if r.Failures > 0 {
+		parts = append(parts, fmt.Sprintf("no response: %d", r.Failures))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// reportLinePrefix prefixes the lines remote generators print their reports
+// on, so that reports can be told apart from other log lines.
+const reportLinePrefix = "flyctl-load-report: "
+
+// MarshalLine returns the single line r is printed on by remote generators.
+func (r *Report) MarshalLine() (string, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+
+	return reportLinePrefix + string(data), nil
+}
+
+// ParseLine returns the report the given line, as MarshalLine returned it,
+// carries. It returns nil for lines which carry no report.
+func ParseLine(line string) (*Report, error) {
+	i := strings.Index(line, reportLinePrefix)
+	if i < 0 {
+		return nil, nil
+	}
+
+	var r Report
+	if err := json.Unmarshal([]byte(line[i+len(reportLinePrefix):]), &r); err != nil {
+		return nil, fmt.Errorf("failed decoding load test report: %w", err)
+	}
+
+	return &r, nil
+}
+
+// Thresholds wraps the limits load test reports are checked against. Zero
+// values disable the respective check.
+type Thresholds struct {
+	MaxP99       time.Duration
+	MaxErrorRate float64
+}
+
+// Check returns an error describing the thresholds r exceeds, if any.
+func (t Thresholds) Check(r *Report) error {
+	var problems []string
+
+	if p99 := r.Latency.Quantile(.99); t.MaxP99 > 0 && p99 > t.MaxP99 {
+		problems = append(problems, fmt.Sprintf("p99 latency of %s exceeds %s", p99.Round(time.Millisecond), t.MaxP99))
+	}
+
+	if rate := r.ErrorRate(); t.MaxErrorRate > 0 && rate > t.MaxErrorRate {
+		problems = append(problems, fmt.Sprintf("error rate of %s exceeds %s", FormatRate(rate), FormatRate(t.MaxErrorRate)))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("load test failed: %s", strings.Join(problems, "; "))
+}
+
+// ParseRate parses a ratio given either as a percentage, e.g. 0.5%, or as a
+// fraction, e.g. 0.005.
+func ParseRate(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q: expected a percentage such as 1%% or a fraction such as 0.01", s)
+	}
+
+	if strings.HasSuffix(s, "%") {
+		v /= 100
+	}
+
+	if v < 0 || v > 1 {
+		return 0, fmt.Errorf("invalid rate %q: must be between 0%% and 100%%", s)
+	}
+
+	return v, nil
+}
+
+// FormatRate formats the given ratio as a percentage.
+func FormatRate(v float64) string {
+	return strconv.FormatFloat(v*100, 'g', 4, 64) + "%"
+}
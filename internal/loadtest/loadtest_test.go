@@ -0,0 +1,130 @@
+package loadtest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramQuantile(t *testing.T) {
+	var h Histogram
+	assert.Equal(t, time.Duration(0), h.Quantile(.5))
+
+	for i := 1; i <= 1000; i++ {
+		h.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	for q, exp := range map[float64]time.Duration{
+		.5:  500 * time.Millisecond,
+		.9:  900 * time.Millisecond,
+		.99: 990 * time.Millisecond,
+	} {
+		got := h.Quantile(q)
+		assert.InEpsilon(t, exp, got, .05, "q%v", q)
+		assert.GreaterOrEqual(t, got, exp, "q%v", q)
+	}
+
+	assert.Equal(t, time.Second, h.Quantile(1))
+	assert.Equal(t, 500500*time.Microsecond, h.Mean())
+}
+
+func TestMerge(t *testing.T) {
+	a := &Report{Requests: 10, Errors: 1, Failures: 1, Statuses: map[int]int{200: 9}, Duration: time.Second}
+	a.Latency.Record(10 * time.Millisecond)
+
+	b := &Report{Requests: 10, Errors: 2, Statuses: map[int]int{200: 8, 503: 2}, Duration: 2 * time.Second}
+	b.Latency.Record(30 * time.Millisecond)
+
+	m := Merge(a, b)
+	assert.Equal(t, 20, m.Requests)
+	assert.Equal(t, 3, m.Errors)
+	assert.Equal(t, 1, m.Failures)
+	assert.Equal(t, map[int]int{200: 17, 503: 2}, m.Statuses)
+	assert.Equal(t, 2, m.Latency.Count)
+	assert.Equal(t, 30*time.Millisecond, m.Latency.Max)
+	assert.Equal(t, 2*time.Second, m.Duration)
+	assert.Equal(t, .15, m.ErrorRate())
+	assert.Equal(t, "200: 17, 503: 2, no response: 1", m.StatusSummary())
+}
+
+func TestLineRoundtrip(t *testing.T) {
+	exp := &Report{Region: "ams", Requests: 2, Statuses: map[int]int{200: 2}, Duration: time.Second}
+	exp.Latency.Record(time.Millisecond)
+	exp.Latency.Record(time.Second)
+
+	line, err := exp.MarshalLine()
+	require.NoError(t, err)
+
+	got, err := ParseLine("2022-01-01T00:00:00Z app[abc] ams [info]" + line)
+	require.NoError(t, err)
+	assert.Equal(t, exp, got)
+
+	got, err = ParseLine("listening on 0.0.0.0:8080")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestParseRate(t *testing.T) {
+	for s, exp := range map[string]float64{"1%": .01, "0.5%": .005, "0.02": .02, "0": 0} {
+		got, err := ParseRate(s)
+		require.NoError(t, err, s)
+		assert.InDelta(t, exp, got, 1e-9, s)
+	}
+
+	for _, s := range []string{"", "abc", "150%", "-1"} {
+		_, err := ParseRate(s)
+		assert.Error(t, err, s)
+	}
+
+	assert.Equal(t, "1%", FormatRate(.01))
+	assert.Equal(t, "7%", FormatRate(.07))
+}
+
+func TestThresholds(t *testing.T) {
+	r := &Report{Requests: 100, Errors: 5}
+	for i := 0; i < 100; i++ {
+		r.Latency.Record(100 * time.Millisecond)
+	}
+
+	assert.NoError(t, Thresholds{}.Check(r))
+	assert.NoError(t, Thresholds{MaxP99: time.Second, MaxErrorRate: .1}.Check(r))
+	assert.Error(t, Thresholds{MaxP99: 50 * time.Millisecond}.Check(r))
+	assert.Error(t, Thresholds{MaxErrorRate: .01}.Check(r))
+}
+
+func TestRun(t *testing.T) {
+	var n int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&n, 1)%4 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	r, err := Run(context.Background(), Options{
+		URL:      srv.URL,
+		RPS:      200,
+		Duration: 500 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	assert.Greater(t, r.Requests, 50)
+	assert.Equal(t, r.Requests, r.Latency.Count)
+	assert.Equal(t, r.Statuses[http.StatusServiceUnavailable], r.Errors)
+	assert.Zero(t, r.Failures)
+	assert.InDelta(t, .25, r.ErrorRate(), .05)
+}
+
+func TestRunValidates(t *testing.T) {
+	_, err := Run(context.Background(), Options{URL: "http://localhost", Duration: time.Second})
+	assert.Error(t, err)
+
+	_, err = Run(context.Background(), Options{URL: "http://localhost", RPS: MaxRPS + 1, Duration: time.Second})
+	assert.Error(t, err)
+}
@@ -13,7 +13,8 @@ import (
 type Level int
 
 const (
-	Debug Level = iota
+	Trace Level = iota
+	Debug
 	Info
 	Warn
 	Error
@@ -24,6 +25,20 @@ type Logger struct {
 	level Level
 }
 
+// New returns a logger which writes the entries of the given level and above
+// to out.
+func New(out io.Writer, level Level) *Logger {
+	return &Logger{
+		out:   out,
+		level: level,
+	}
+}
+
+// Level returns the level of the least severe entries l writes.
+func (l *Logger) Level() Level {
+	return l.level
+}
+
 func FromEnv(out io.Writer) *Logger {
 	return &Logger{
 		out:   out,
@@ -42,6 +57,8 @@ func levelFromEnv() Level {
 	switch lit {
 	default:
 		return Info
+	case "trace":
+		return Trace
 	case "debug":
 		return Debug
 	case "warn":
@@ -51,6 +68,26 @@ func levelFromEnv() Level {
 	}
 }
 
+func (l *Logger) trace(v ...interface{}) {
+	fmt.Fprintln(
+		l.out,
+		aurora.Faint("TRACE"),
+		fmt.Sprint(v...),
+	)
+}
+
+func (l *Logger) Trace(v ...interface{}) {
+	if l.level <= Trace {
+		l.trace(v...)
+	}
+}
+
+func (l *Logger) Tracef(format string, v ...interface{}) {
+	if l.level <= Trace {
+		l.trace(fmt.Sprintf(format, v...))
+	}
+}
+
 func (l *Logger) debug(v ...interface{}) {
 	fmt.Fprintln(
 		l.out,
@@ -0,0 +1,228 @@
+// Package metrics implements querying the metrics of apps through the
+// Prometheus compatible API Fly exposes for each organization.
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// Client queries the metrics of the apps of an organization.
+type Client struct {
+	// BaseURL is the base URL of the API, e.g. https://api.fly.io.
+	BaseURL string
+
+	// Token is the access token requests are authorized with.
+	Token string
+
+	// Org is the slug of the organization queries are made against.
+	Org string
+
+	// HTTPClient is the client requests are made with; http.DefaultClient
+	// when nil.
+	HTTPClient *http.Client
+}
+
+// Sample denotes the value of a series at a point in time.
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// MarshalJSON implements json.Marshaler. Values which JSON can't represent,
+// such as the NaN ones of gaps, are encoded as null.
+func (s Sample) MarshalJSON() ([]byte, error) {
+	var value interface{}
+	if !math.IsNaN(s.Value) && !math.IsInf(s.Value, 0) {
+		value = s.Value
+	}
+
+	return json.Marshal(struct {
+		Time  time.Time   `json:"time"`
+		Value interface{} `json:"value"`
+	}{s.Time, value})
+}
+
+// Series denotes the samples of a series, along with its labels.
+type Series struct {
+	Labels  map[string]string `json:"labels"`
+	Samples []Sample          `json:"samples"`
+}
+
+// Name returns the labels of the series, in the order of their names, e.g.
+// region=iad, status=200. It returns an empty string in case the series has
+// no labels.
+func (s *Series) Name() string {
+	names := make([]string, 0, len(s.Labels))
+	for name := range s.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+"="+s.Labels[name])
+	}
+
+	return strings.Join(pairs, ", ")
+}
+
+// Values returns the values of the samples of the series.
+func (s *Series) Values() []float64 {
+	values := make([]float64, 0, len(s.Samples))
+	for _, sample := range s.Samples {
+		values = append(values, sample.Value)
+	}
+
+	return values
+}
+
+// Last returns the value of the most recent sample of the series, or NaN in
+// case it has none.
+func (s *Series) Last() float64 {
+	if len(s.Samples) == 0 {
+		return math.NaN()
+	}
+
+	return s.Samples[len(s.Samples)-1].Value
+}
+
+// QueryRange evaluates the given PromQL query over the [start, end] range, at
+// step intervals.
+func (c *Client) QueryRange(ctx context.Context, query string, start, end time.Time, step time.Duration) ([]Series, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", formatTime(start))
+	params.Set("end", formatTime(end))
+	params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+
+	u := fmt.Sprintf("%s/prometheus/%s/api/v1/query_range?%s",
+		strings.TrimSuffix(c.BaseURL, "/"), url.PathEscape(c.Org), params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", api.AuthorizationHeader(c.Token))
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying metrics: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading metrics: %w", err)
+	}
+
+	var resp response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed querying metrics: %s", res.Status)
+		}
+
+		return nil, fmt.Errorf("failed decoding metrics: %w", err)
+	}
+
+	if resp.Status != "success" {
+		if resp.Error == "" {
+			resp.Error = res.Status
+		}
+
+		return nil, fmt.Errorf("failed querying metrics: %s", resp.Error)
+	}
+
+	if resp.Data.ResultType != "matrix" {
+		return nil, fmt.Errorf("expected a range vector but got a %s", resp.Data.ResultType)
+	}
+
+	return resp.series()
+}
+
+type response struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string    `json:"metric"`
+			Values [][2]json.RawMessage `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+func (r *response) series() ([]Series, error) {
+	series := make([]Series, 0, len(r.Data.Result))
+
+	for _, result := range r.Data.Result {
+		s := Series{
+			Labels:  result.Metric,
+			Samples: make([]Sample, 0, len(result.Values)),
+		}
+		if s.Labels == nil {
+			s.Labels = map[string]string{}
+		}
+
+		for _, pair := range result.Values {
+			sample, err := parseSample(pair)
+			if err != nil {
+				return nil, err
+			}
+
+			s.Samples = append(s.Samples, sample)
+		}
+
+		series = append(series, s)
+	}
+
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Name() < series[j].Name()
+	})
+
+	return series, nil
+}
+
+// parseSample parses a [<unix time>, "<value>"] pair.
+func parseSample(pair [2]json.RawMessage) (sample Sample, err error) {
+	var ts float64
+	if err = json.Unmarshal(pair[0], &ts); err != nil {
+		return sample, errors.New("failed decoding metrics: malformed sample time")
+	}
+
+	var value string
+	if err = json.Unmarshal(pair[1], &value); err != nil {
+		return sample, errors.New("failed decoding metrics: malformed sample value")
+	}
+
+	sec, frac := math.Modf(ts)
+	sample.Time = time.Unix(int64(sec), int64(frac*1e9)).UTC()
+
+	if sample.Value, err = strconv.ParseFloat(value, 64); err != nil {
+		return sample, fmt.Errorf("failed decoding metrics: malformed sample value %q", value)
+	}
+
+	return sample, nil
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', 3, 64)
+}
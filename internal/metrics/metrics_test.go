@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryRange(t *testing.T) {
+	var got *http.Request
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+
+		_, _ = w.Write([]byte(`{
+			"status": "success",
+			"data": {
+				"resultType": "matrix",
+				"result": [
+					{"metric": {"region": "lhr"}, "values": [[1600000000, "3"], [1600000015.5, "NaN"]]},
+					{"metric": {"region": "iad"}, "values": [[1600000000, "1.5"]]}
+				]
+			}
+		}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL + "/", Token: "tok", Org: "personal"}
+
+	start := time.Unix(1600000000, 0)
+	series, err := c.QueryRange(context.Background(), "up", start, start.Add(time.Minute), 15*time.Second)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/prometheus/personal/api/v1/query_range", got.URL.Path)
+	assert.Equal(t, "Bearer tok", got.Header.Get("Authorization"))
+	assert.Equal(t, "up", got.URL.Query().Get("query"))
+	assert.Equal(t, "1600000000.000", got.URL.Query().Get("start"))
+	assert.Equal(t, "15", got.URL.Query().Get("step"))
+
+	require.Len(t, series, 2)
+	assert.Equal(t, "region=iad", series[0].Name())
+	assert.Equal(t, []float64{1.5}, series[0].Values())
+
+	assert.Equal(t, "region=lhr", series[1].Name())
+	assert.Equal(t, time.Unix(1600000015, 5e8).UTC(), series[1].Samples[1].Time)
+	assert.True(t, math.IsNaN(series[1].Last()))
+}
+
+func TestQueryRangeError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status": "error", "errorType": "bad_data", "error": "parse error"}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, Org: "personal"}
+
+	_, err := c.QueryRange(context.Background(), "up{", time.Now(), time.Now(), time.Minute)
+	assert.EqualError(t, err, "failed querying metrics: parse error")
+}
+
+func TestUnitFormat(t *testing.T) {
+	cases := []struct {
+		unit Unit
+		v    float64
+		want string
+	}{
+		{UnitPercent, 12.34, "12.3%"},
+		{UnitBytes, 2 * 1000 * 1000, "2.0 MB"},
+		{UnitRate, 0.5, "0.50/s"},
+		{UnitSeconds, 0.123, "123ms"},
+		{UnitSeconds, 1.5, "1.50s"},
+		{UnitNone, 2.5, "2.5"},
+		{UnitNone, 3, "3"},
+		{UnitNone, math.NaN(), "-"},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.want, c.unit.Format(c.v))
+	}
+}
+
+func TestSampleMarshalJSON(t *testing.T) {
+	samples := []Sample{
+		{Time: time.Unix(0, 0).UTC(), Value: 1.5},
+		{Time: time.Unix(0, 0).UTC(), Value: math.NaN()},
+	}
+
+	b, err := json.Marshal(samples)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"time":"1970-01-01T00:00:00Z","value":1.5},{"time":"1970-01-01T00:00:00Z","value":null}]`, string(b))
+}
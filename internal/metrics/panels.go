@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+)
+
+// Unit denotes the unit of the values of a panel.
+type Unit int
+
+const (
+	UnitNone Unit = iota
+	UnitPercent
+	UnitBytes
+	UnitRate
+	UnitSeconds
+)
+
+// Panel denotes a query the values of which are shown together.
+type Panel struct {
+	Title string
+	Query string
+	Unit  Unit
+}
+
+// AppPanels returns the panels of the CPU, memory and HTTP response metrics of
+// the given app.
+func AppPanels(appName string) []Panel {
+	sel := fmt.Sprintf("app=%q", appName)
+
+	return []Panel{
+		{
+			Title: "CPU",
+			Query: fmt.Sprintf(`100 * sum by (region) (rate(fly_instance_cpu{%[1]s,mode!="idle"}[1m])) / sum by (region) (rate(fly_instance_cpu{%[1]s}[1m]))`, sel),
+			Unit:  UnitPercent,
+		},
+		{
+			Title: "Memory",
+			Query: fmt.Sprintf(`sum by (region) (fly_instance_memory_mem_total{%[1]s} - fly_instance_memory_mem_available{%[1]s})`, sel),
+			Unit:  UnitBytes,
+		},
+		{
+			Title: "HTTP Responses",
+			Query: fmt.Sprintf(`sum by (status) (rate(fly_edge_http_responses_count{%s}[1m]))`, sel),
+			Unit:  UnitRate,
+		},
+		{
+			Title: "HTTP Response Time (p95)",
+			Query: fmt.Sprintf(`histogram_quantile(0.95, sum by (le) (rate(fly_edge_http_response_time_seconds_bucket{%s}[1m])))`, sel),
+			Unit:  UnitSeconds,
+		},
+	}
+}
+
+// Format formats v according to the unit.
+func (u Unit) Format(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "-"
+	}
+
+	switch u {
+	case UnitPercent:
+		return strconv.FormatFloat(v, 'f', 1, 64) + "%"
+	case UnitBytes:
+		if v < 0 {
+			return "-" + humanize.Bytes(uint64(-v))
+		}
+		return humanize.Bytes(uint64(v))
+	case UnitRate:
+		return strconv.FormatFloat(v, 'f', 2, 64) + "/s"
+	case UnitSeconds:
+		if v < 1 {
+			return strconv.FormatFloat(v*1000, 'f', 0, 64) + "ms"
+		}
+		return strconv.FormatFloat(v, 'f', 2, 64) + "s"
+	default:
+		s := strconv.FormatFloat(v, 'f', 3, 64)
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+		if s == "" || s == "-" {
+			s = "0"
+		}
+		return s
+	}
+}
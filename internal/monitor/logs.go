@@ -5,6 +5,7 @@ import (
 	"io"
 	"time"
 
+	"github.com/azazeal/pause"
 	"github.com/jpillora/backoff"
 	"github.com/superfly/flyctl/api"
 	"github.com/superfly/flyctl/cmdctx"
@@ -41,6 +42,10 @@ func WatchLogs(cc *cmdctx.CmdContext, w io.Writer, opts LogOptions) error {
 	// logPresenter := presenters.LogPresenter{}
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		entries, token, err := cc.Client.API().GetAppLogs(ctx, opts.AppName, nextToken, opts.RegionCode, opts.VMID)
 
 		if err != nil {
@@ -54,13 +59,13 @@ func WatchLogs(cc *cmdctx.CmdContext, w io.Writer, opts LogOptions) error {
 				if errorCount > 10 {
 					return err
 				}
-				time.Sleep(b.Duration())
+				pause.For(ctx, b.Duration())
 			}
 		}
 		errorCount = 0
 
 		if len(entries) == 0 {
-			time.Sleep(b.Duration())
+			pause.For(ctx, b.Duration())
 		} else {
 			b.Reset()
 
@@ -0,0 +1,192 @@
+// Package netproxy implements the HTTP and SOCKS proxy support of the network
+// paths flyctl takes, per the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// environment variables (or their lowercase forms).
+//
+// HTTP traffic, like the one of the API client, honors these variables via
+// http.DefaultTransport. The package covers the paths which don't go through
+// it: raw TCP connections and the builds of local Docker daemons.
+package netproxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+// ForURL returns the URL of the proxy requests to u go through, or nil in
+// case they go direct.
+func ForURL(u *url.URL) (*url.URL, error) {
+	return httpproxy.FromEnvironment().ProxyFunc()(u)
+}
+
+// Describe returns a description of the proxy requests to the given URL go
+// through, with any password redacted, or an empty string in case they go
+// direct.
+func Describe(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	p, err := ForURL(u)
+	if err != nil || p == nil {
+		return "", err
+	}
+
+	desc := p.Redacted()
+	if noProxy := httpproxy.FromEnvironment().NoProxy; noProxy != "" {
+		desc += fmt.Sprintf(" (NO_PROXY=%s)", noProxy)
+	}
+
+	return desc, nil
+}
+
+// DialContext connects to addr via the proxy TLS connections to it go through,
+// if any: SOCKS5 proxies are dialed as such and HTTP(S) proxies are asked to
+// CONNECT.
+func DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	var d net.Dialer
+
+	p, err := ForURL(&url.URL{Scheme: "https", Host: addr})
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("invalid proxy: %w", err)
+	case p == nil:
+		return d.DialContext(ctx, network, addr)
+	}
+
+	switch p.Scheme {
+	case "socks5", "socks5h":
+		dialer, err := proxy.FromURL(p, &d)
+		if err != nil {
+			return nil, err
+		}
+
+		if cd, ok := dialer.(proxy.ContextDialer); ok {
+			return cd.DialContext(ctx, network, addr)
+		}
+
+		return dialer.Dial(network, addr)
+	case "http", "https":
+		return dialConnect(ctx, p, addr)
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", p.Scheme)
+	}
+}
+
+func dialConnect(ctx context.Context, p *url.URL, addr string) (_ net.Conn, err error) {
+	proxyAddr := p.Host
+	if p.Port() == "" {
+		port := "80"
+		if p.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(p.Hostname(), port)
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed dialing proxy %s: %w", proxyAddr, err)
+	}
+	defer func() {
+		if err != nil {
+			conn.Close()
+		}
+	}()
+
+	if p.Scheme == "https" {
+		tc := tls.Client(conn, &tls.Config{ServerName: p.Hostname()})
+		if err = tc.HandshakeContext(ctx); err != nil {
+			return nil, fmt.Errorf("failed handshaking with proxy %s: %w", proxyAddr, err)
+		}
+		conn = tc
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if user := p.User; user != nil {
+		password, _ := user.Password()
+		creds := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+creds)
+	}
+
+	if err = req.Write(conn); err != nil {
+		return nil, fmt.Errorf("failed writing CONNECT request: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading CONNECT response: %w", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("proxy %s refused to connect to %s: %s", proxyAddr, addr, res.Status)
+	}
+
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+
+	return conn, nil
+}
+
+// bufferedConn is a net.Conn which reads what was buffered off of it first.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// proxyEnvKeys denotes the environment variables Docker passes to builds as
+// build arguments by default.
+var proxyEnvKeys = []string{
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "FTP_PROXY", "ALL_PROXY",
+}
+
+// BuildArgs returns the proxy settings of the environment as the build
+// arguments the Docker CLI passes to builds, both in upper and lower case.
+// They only make sense for builds which run on the local network.
+func BuildArgs() map[string]string {
+	args := map[string]string{}
+
+	for _, key := range proxyEnvKeys {
+		v := os.Getenv(key)
+		if v == "" {
+			v = os.Getenv(strings.ToLower(key))
+		}
+
+		if v != "" {
+			args[key] = v
+			args[strings.ToLower(key)] = v
+		}
+	}
+
+	return args
+}
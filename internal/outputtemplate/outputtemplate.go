@@ -0,0 +1,58 @@
+// Package outputtemplate implements rendering structured output through the
+// Go templates the --format flag accepts, for the commands of both the legacy
+// and the current command trees.
+package outputtemplate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// funcs denotes the functions available to output templates in addition to
+// the builtin ones.
+var funcs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+
+		return string(data), err
+	},
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// Parse parses the given output template.
+func Parse(text string) (*template.Template, error) {
+	tmpl, err := template.New("format").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// Execute renders v into w through the given Go template. Output which does
+// not end in a newline is terminated with one.
+func Execute(w io.Writer, text string, v interface{}) error {
+	tmpl, err := Parse(text)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, v); err != nil {
+		return fmt.Errorf("failed executing format template: %w", err)
+	}
+
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+
+	_, err = buf.WriteTo(w)
+
+	return err
+}
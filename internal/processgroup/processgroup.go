@@ -0,0 +1,274 @@
+// Package processgroup implements the processes section of app configs, which
+// runs the app as several process groups, each with its own command, count
+// and VM size:
+//
+//	[processes]
+//	  web = "bin/rails server"
+//	  worker = { command = "bundle exec sidekiq", count = 2, vm_size = "shared-cpu-2x" }
+//	  cron = { command = "supercronic /app/crontab", count = 1 }
+//
+//	[[services]]
+//	  processes = ["web"]
+//
+// The platform only knows the commands of groups; their counts and VM sizes are
+// applied through the scaling API once the release is created.
+package processgroup
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Section is the name of the section of app configs process groups are
+// declared in.
+const Section = "processes"
+
+// DefaultGroup is the name of the group of apps which declare none.
+const DefaultGroup = "app"
+
+var nameRE = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Group wraps the settings of a process group.
+type Group struct {
+	Name    string
+	Command string
+
+	// Count is the number of instances the group runs, or nil in case the
+	// config leaves it as is.
+	Count *int
+
+	// VMSize and MemoryMB denote the VM size of the group, if any.
+	VMSize   string
+	MemoryMB int
+}
+
+// FromDefinition returns the process groups of the given definition sorted by
+// name, or nil in case it declares none. The services of the definition may
+// only name declared groups.
+func FromDefinition(def map[string]interface{}) ([]Group, error) {
+	raw, ok := def[Section]
+	if !ok {
+		return nil, nil
+	}
+
+	section, err := toMap(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a table", Section)
+	}
+
+	groups := make([]Group, 0, len(section))
+	for name, v := range section {
+		if !nameRE.MatchString(name) {
+			return nil, fmt.Errorf("%s.%s: names of process groups may only contain lowercase letters, digits, dashes and underscores", Section, name)
+		}
+
+		group, err := parseGroup(name, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", Section, name, err)
+		}
+
+		groups = append(groups, group)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+
+	if err := validateServices(def, groups); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// Normalize returns the process groups of def, as FromDefinition does, and
+// replaces their settings in def with their commands, which is all the
+// platform knows of them.
+func Normalize(def map[string]interface{}) ([]Group, error) {
+	groups, err := FromDefinition(def)
+	if err != nil || groups == nil {
+		return groups, err
+	}
+
+	commands := make(map[string]interface{}, len(groups))
+	for _, group := range groups {
+		commands[group.Name] = group.Command
+	}
+	def[Section] = commands
+
+	return groups, nil
+}
+
+// Names returns the names of the given groups.
+func Names(groups []Group) []string {
+	names := make([]string, 0, len(groups))
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+
+	return names
+}
+
+// Counts maps the names of the given groups which set their count to it.
+func Counts(groups []Group) map[string]int {
+	counts := map[string]int{}
+	for _, group := range groups {
+		if group.Count != nil {
+			counts[group.Name] = *group.Count
+		}
+	}
+
+	return counts
+}
+
+// Sized returns the given groups which set their VM size.
+func Sized(groups []Group) (sized []Group) {
+	for _, group := range groups {
+		if group.VMSize != "" {
+			sized = append(sized, group)
+		}
+	}
+
+	return
+}
+
+func parseGroup(name string, v interface{}) (Group, error) {
+	group := Group{
+		Name: name,
+	}
+
+	if command, ok := v.(string); ok {
+		group.Command = command
+	} else {
+		settings, err := toMap(v)
+		if err != nil {
+			return group, fmt.Errorf("must be a command or a table")
+		}
+
+		for k, v := range settings {
+			switch k {
+			case "command":
+				command, ok := v.(string)
+				if !ok {
+					err = fmt.Errorf("must be a string")
+				}
+				group.Command = command
+			case "count":
+				var count int
+				if count, err = toInt(v); err == nil {
+					group.Count = &count
+				}
+			case "vm_size":
+				size, ok := v.(string)
+				if !ok {
+					err = fmt.Errorf("must be a string")
+				}
+				group.VMSize = size
+			case "memory_mb":
+				group.MemoryMB, err = toInt(v)
+			default:
+				err = fmt.Errorf("unknown setting")
+			}
+
+			if err != nil {
+				return group, fmt.Errorf("%s: %w", k, err)
+			}
+		}
+	}
+
+	if strings.TrimSpace(group.Command) == "" {
+		return group, fmt.Errorf("command must be set")
+	} else if group.MemoryMB > 0 && group.VMSize == "" {
+		return group, fmt.Errorf("memory_mb requires vm_size")
+	}
+
+	return group, nil
+}
+
+func validateServices(def map[string]interface{}, groups []Group) error {
+	declared := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		declared[group.Name] = true
+	}
+
+	services, _ := def["services"].([]interface{})
+	if typed, ok := def["services"].([]map[string]interface{}); ok {
+		for _, service := range typed {
+			services = append(services, service)
+		}
+	}
+
+	for i, raw := range services {
+		service, err := toMap(raw)
+		if err != nil {
+			continue
+		}
+
+		var names []string
+		switch v := service["processes"].(type) {
+		case []string:
+			names = v
+		case []interface{}:
+			for _, name := range v {
+				names = append(names, fmt.Sprint(name))
+			}
+		}
+
+		for _, name := range names {
+			if !declared[name] {
+				return fmt.Errorf("services[%d].processes: process group %q isn't declared in the %s section", i, name, Section)
+			}
+		}
+	}
+
+	return nil
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return v, nil
+	case map[string]string:
+		m := make(map[string]interface{}, len(v))
+		for k, s := range v {
+			m[k] = s
+		}
+
+		return m, nil
+	default:
+		return nil, fmt.Errorf("must be a table")
+	}
+}
+
+func toInt(v interface{}) (int, error) {
+	var n int
+
+	switch v := v.(type) {
+	case int:
+		n = v
+	case int64:
+		n = int(v)
+	case float64:
+		if v != float64(int(v)) {
+			return 0, fmt.Errorf("must be a whole number")
+		}
+		n = int(v)
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			return 0, fmt.Errorf("must be a whole number")
+		}
+		n = int(i)
+	default:
+		return 0, fmt.Errorf("must be a number")
+	}
+
+	if n < 0 {
+		return 0, fmt.Errorf("may not be negative")
+	}
+
+	return n, nil
+}
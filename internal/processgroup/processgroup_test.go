@@ -0,0 +1,84 @@
+package processgroup
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sample = `
+app = "app"
+
+[processes]
+  web = "bin/rails server"
+  worker = { command = "bundle exec sidekiq", count = 2, vm_size = "shared-cpu-2x", memory_mb = 1024 }
+
+[[services]]
+  internal_port = 3000
+  processes = ["web"]
+`
+
+func decode(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+
+	def := map[string]interface{}{}
+	_, err := toml.Decode(data, &def)
+	require.NoError(t, err)
+
+	return def
+}
+
+func TestFromDefinition(t *testing.T) {
+	groups, err := FromDefinition(decode(t, sample))
+	require.NoError(t, err)
+
+	two := 2
+	assert.Equal(t, []Group{
+		{Name: "web", Command: "bin/rails server"},
+		{Name: "worker", Command: "bundle exec sidekiq", Count: &two, VMSize: "shared-cpu-2x", MemoryMB: 1024},
+	}, groups)
+
+	assert.Equal(t, []string{"web", "worker"}, Names(groups))
+	assert.Equal(t, map[string]int{"worker": 2}, Counts(groups))
+	assert.Equal(t, groups[1:], Sized(groups))
+
+	groups, err = FromDefinition(map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Nil(t, groups)
+}
+
+func TestFromDefinitionInvalid(t *testing.T) {
+	cases := map[string]string{
+		`Web = "x"`:                           "processes.Web: names of process groups may only contain lowercase letters, digits, dashes and underscores",
+		`web = 1`:                             "processes.web: must be a command or a table",
+		`web = { count = 1 }`:                 "processes.web: command must be set",
+		`web = { command = "x", count = -1 }`: "processes.web: count: may not be negative",
+		`web = { command = "x", memory_mb = 512 }`:         "processes.web: memory_mb requires vm_size",
+		`web = { command = "x", replicas = 2 }`:            "processes.web: replicas: unknown setting",
+		"web = \"x\"\n[[services]]\nprocesses = [\"api\"]": `services[0].processes: process group "api" isn't declared in the processes section`,
+	}
+
+	for section, expected := range cases {
+		_, err := FromDefinition(decode(t, "[processes]\n"+section))
+		assert.EqualError(t, err, expected, section)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	def := decode(t, sample)
+
+	groups, err := Normalize(def)
+	require.NoError(t, err)
+	assert.Len(t, groups, 2)
+
+	assert.Equal(t, map[string]interface{}{
+		"web":    "bin/rails server",
+		"worker": "bundle exec sidekiq",
+	}, def[Section])
+
+	again, err := Normalize(def)
+	require.NoError(t, err)
+	assert.Equal(t, Names(groups), Names(again))
+}
@@ -0,0 +1,244 @@
+// Package registry implements the parts of the Docker registry HTTP API
+// flyctl uses to manage the images it pushes.
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// manifestTypes are the media types of the manifests images are pushed with.
+var manifestTypes = []string{
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+}
+
+// Client manages the images of a registry.
+type Client struct {
+	// Host is the host of the registry, e.g. registry.fly.io.
+	Host string
+
+	// Token authenticates against the registry, as docker login does.
+	Token string
+
+	// Scheme defaults to https.
+	Scheme string
+
+	HTTPClient *http.Client
+}
+
+// Owns reports whether ref refers to an image of the registry.
+func (c *Client) Owns(ref string) bool {
+	return strings.HasPrefix(ref, c.Host+"/")
+}
+
+// InRepository reports whether ref refers to an image of the named repository
+// of the registry, e.g. registry.fly.io/myapp:v1 to one of myapp. Images of
+// other repositories, such as the ones of other apps, don't count.
+func (c *Client) InRepository(ref, repo string) bool {
+	got, _, err := c.parseRef(ref)
+
+	return err == nil && got == repo
+}
+
+// parseRef splits ref into the repository and the tag or digest it refers to.
+func (c *Client) parseRef(ref string) (repo, reference string, err error) {
+	if !c.Owns(ref) {
+		return "", "", fmt.Errorf("image %s is not hosted on %s", ref, c.Host)
+	}
+
+	rest := strings.TrimPrefix(ref, c.Host+"/")
+
+	switch i, j := strings.Index(rest, "@"), strings.LastIndex(rest, ":"); {
+	case i >= 0:
+		repo, reference = rest[:i], rest[i+1:]
+	case j >= 0:
+		repo, reference = rest[:j], rest[j+1:]
+	default:
+		repo, reference = rest, "latest"
+	}
+
+	if repo == "" || reference == "" {
+		return "", "", fmt.Errorf("invalid image reference %s", ref)
+	}
+
+	return repo, reference, nil
+}
+
+// ResolveDigest returns the digest of the manifest ref refers to, by tag or by
+// digest, or an empty string in case the registry has no such manifest.
+func (c *Client) ResolveDigest(ctx context.Context, ref string) (string, error) {
+	repo, reference, err := c.parseRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.HasPrefix(reference, "sha256:") {
+		return reference, nil
+	}
+
+	res, err := c.do(ctx, http.MethodHead, repo, reference)
+	if err != nil {
+		return "", err
+	}
+	res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusNotFound:
+		return "", nil
+	case http.StatusOK:
+		digest := res.Header.Get("Docker-Content-Digest")
+		if digest == "" {
+			return "", fmt.Errorf("failed resolving %s: no digest", ref)
+		}
+
+		return digest, nil
+	default:
+		return "", fmt.Errorf("failed resolving %s: %s", ref, res.Status)
+	}
+}
+
+// DigestRef returns the reference to the given digest in the repository ref
+// refers to, e.g. registry.fly.io/myapp@sha256:... for registry.fly.io/myapp:v1.
+func (c *Client) DigestRef(ref, digest string) (string, error) {
+	repo, _, err := c.parseRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	return c.Host + "/" + repo + "@" + digest, nil
+}
+
+// DeleteImage deletes the manifest ref refers to, by tag or by digest, from
+// the registry, along with every tag of it. Images which aren't found are
+// considered deleted.
+func (c *Client) DeleteImage(ctx context.Context, ref string) error {
+	repo, _, err := c.parseRef(ref)
+	if err != nil {
+		return err
+	}
+
+	digest, err := c.ResolveDigest(ctx, ref)
+	if err != nil || digest == "" {
+		return err
+	}
+
+	res, err := c.do(ctx, http.MethodDelete, repo, digest)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusAccepted, http.StatusOK, http.StatusNotFound:
+		return nil
+	default:
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+
+		return fmt.Errorf("failed deleting %s: %s %s", ref, res.Status, strings.TrimSpace(string(body)))
+	}
+}
+
+// Descriptor denotes content of the registry, such as a layer or a manifest.
+type Descriptor struct {
+	MediaType string    `json:"mediaType"`
+	Digest    string    `json:"digest"`
+	Size      int64     `json:"size"`
+	Platform  *Platform `json:"platform,omitempty"`
+}
+
+// Platform denotes the platform the manifests of a manifest list run on.
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+}
+
+// Manifest wraps an image manifest or, for multi-platform images, a manifest
+// list.
+type Manifest struct {
+	MediaType string       `json:"mediaType"`
+	Config    Descriptor   `json:"config"`
+	Layers    []Descriptor `json:"layers"`
+	Manifests []Descriptor `json:"manifests"`
+}
+
+// GetManifest returns the manifest ref refers to. Manifest lists resolve to
+// the manifest of their linux/amd64 image, which is what machines run.
+func (c *Client) GetManifest(ctx context.Context, ref string) (*Manifest, error) {
+	repo, reference, err := c.parseRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := c.getManifest(ctx, repo, reference)
+	if err != nil {
+		return nil, fmt.Errorf("failed retrieving the manifest of %s: %w", ref, err)
+	}
+
+	if len(manifest.Manifests) == 0 {
+		return manifest, nil
+	}
+
+	for _, m := range manifest.Manifests {
+		if m.Platform == nil || (m.Platform.OS == "linux" && m.Platform.Architecture == "amd64") {
+			if manifest, err = c.getManifest(ctx, repo, m.Digest); err != nil {
+				return nil, fmt.Errorf("failed retrieving the manifest of %s: %w", ref, err)
+			}
+
+			return manifest, nil
+		}
+	}
+
+	return nil, fmt.Errorf("image %s has no linux/amd64 manifest", ref)
+}
+
+func (c *Client) getManifest(ctx context.Context, repo, reference string) (*Manifest, error) {
+	res, err := c.do(ctx, http.MethodGet, repo, reference)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+
+		return nil, fmt.Errorf("%s %s", res.Status, strings.TrimSpace(string(body)))
+	}
+
+	var manifest Manifest
+	if err := json.NewDecoder(res.Body).Decode(&manifest); err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
+
+func (c *Client) do(ctx context.Context, method, repo, reference string) (*http.Response, error) {
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+
+	url := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", scheme, c.Host, repo, reference)
+
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.SetBasicAuth("x", c.Token)
+	req.Header.Set("Accept", strings.Join(manifestTypes, ", "))
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return httpClient.Do(req)
+}
@@ -0,0 +1,123 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRef(t *testing.T) {
+	c := &Client{Host: "registry.fly.io"}
+
+	cases := map[string][2]string{
+		"registry.fly.io/app:deployment-1":  {"app", "deployment-1"},
+		"registry.fly.io/app@sha256:abc":    {"app", "sha256:abc"},
+		"registry.fly.io/org/app":           {"org/app", "latest"},
+		"registry.fly.io/app:v1@sha256:abc": {"app:v1", "sha256:abc"},
+	}
+
+	for ref, expected := range cases {
+		repo, reference, err := c.parseRef(ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, expected, [2]string{repo, reference}, ref)
+	}
+
+	_, _, err := c.parseRef("docker.io/library/nginx:latest")
+	assert.EqualError(t, err, "image docker.io/library/nginx:latest is not hosted on registry.fly.io")
+}
+
+func TestInRepository(t *testing.T) {
+	c := &Client{Host: "registry.fly.io"}
+
+	assert.True(t, c.InRepository("registry.fly.io/app:deployment-1", "app"))
+	assert.True(t, c.InRepository("registry.fly.io/app@sha256:abc", "app"))
+
+	// images of other apps, as releases deploy after forks or restores
+	assert.False(t, c.InRepository("registry.fly.io/other:deployment-1", "app"))
+	assert.False(t, c.InRepository("registry.fly.io/app-staging:deployment-1", "app"))
+	assert.False(t, c.InRepository("registry.fly.io/app/nested:v1", "app"))
+	assert.False(t, c.InRepository("docker.io/library/app:latest", "app"))
+}
+
+func TestDeleteImage(t *testing.T) {
+	var requests []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "x", user)
+		assert.Equal(t, "tok", pass)
+
+		switch {
+		case r.Method == http.MethodHead && strings.HasSuffix(r.URL.Path, "/gone"):
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodHead:
+			w.Header().Set("Docker-Content-Digest", "sha256:abc")
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	c := &Client{Host: host, Token: "tok", Scheme: "http"}
+
+	require.NoError(t, c.DeleteImage(context.Background(), host+"/app:deployment-1"))
+	require.NoError(t, c.DeleteImage(context.Background(), host+"/app:gone"))
+
+	digest, err := c.ResolveDigest(context.Background(), host+"/app:deployment-1")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:abc", digest)
+
+	ref, err := c.DigestRef(host+"/app:deployment-1", digest)
+	require.NoError(t, err)
+	assert.Equal(t, host+"/app@sha256:abc", ref)
+
+	assert.Equal(t, []string{
+		"HEAD /v2/app/manifests/deployment-1",
+		"DELETE /v2/app/manifests/sha256:abc",
+		"HEAD /v2/app/manifests/gone",
+		"HEAD /v2/app/manifests/deployment-1",
+	}, requests)
+}
+
+func TestGetManifest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/multi/manifests/latest":
+			w.Write([]byte(`{"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[
+				{"digest":"sha256:arm","platform":{"architecture":"arm64","os":"linux"}},
+				{"digest":"sha256:amd","platform":{"architecture":"amd64","os":"linux"}}]}`))
+		case "/v2/multi/manifests/sha256:amd", "/v2/app/manifests/deployment-1":
+			w.Write([]byte(`{"mediaType":"application/vnd.docker.distribution.manifest.v2+json",
+				"config":{"digest":"sha256:cfg","size":10},
+				"layers":[{"digest":"sha256:base","size":100},{"digest":"sha256:app","size":20}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	c := &Client{Host: host, Scheme: "http"}
+
+	expected := []Descriptor{
+		{Digest: "sha256:base", Size: 100},
+		{Digest: "sha256:app", Size: 20},
+	}
+
+	for _, ref := range []string{host + "/app:deployment-1", host + "/multi"} {
+		manifest, err := c.GetManifest(context.Background(), ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, expected, manifest.Layers, ref)
+	}
+
+	_, err := c.GetManifest(context.Background(), host+"/app:gone")
+	assert.Error(t, err)
+}
@@ -0,0 +1,262 @@
+// Package sandbox implements sandboxes: organizations created for experiments
+// which are destroyed, along with every app created in them, once their time
+// to live runs out.
+//
+// The platform knows nothing of sandboxes; flyctl keeps track of them in its
+// config directory and destroys them from a background process it starts for
+// each, as well as from the next command which runs once they've expired.
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+	"github.com/superfly/flyctl/internal/deployment"
+	"github.com/superfly/flyctl/internal/filemu"
+)
+
+// ErrNotFound is returned for slugs which match no sandbox.
+var ErrNotFound = errors.New("no such sandbox")
+
+// Sandbox denotes an organization which is destroyed once it expires.
+type Sandbox struct {
+	Slug      string    `json:"slug"`
+	OrgID     string    `json:"org_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// PID identifies the process waiting to destroy the sandbox.
+	PID int `json:"pid,omitempty"`
+}
+
+// Expired reports whether the sandbox has outlived its time to live.
+func (sb *Sandbox) Expired() bool {
+	return !time.Now().Round(0).Before(sb.ExpiresAt)
+}
+
+// Store stores sandboxes in a directory, by default the flyctl config
+// directory.
+type Store struct {
+	dir string
+}
+
+// NewStore returns the Store stored in dir.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func (s *Store) path() string {
+	return filepath.Join(s.dir, "sandboxes.json")
+}
+
+// List returns the sandboxes, in the order they expire.
+func (s *Store) List(ctx context.Context) ([]*Sandbox, error) {
+	if _, err := os.Stat(s.path()); errors.Is(err, os.ErrNotExist) {
+		return nil, nil // spare the lock in case no sandbox was ever created
+	}
+
+	unlock, err := filemu.Lock(ctx, s.path()+".lock")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = unlock() }()
+
+	return s.read()
+}
+
+// Get returns the sandbox of the given slug.
+func (s *Store) Get(ctx context.Context, slug string) (*Sandbox, error) {
+	all, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sb := range all {
+		if sb.Slug == slug {
+			return sb, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// Add stores the given sandbox and starts the background process which
+// destroys it once it expires.
+func (s *Store) Add(ctx context.Context, sb *Sandbox) error {
+	if err := s.update(ctx, func(all []*Sandbox) ([]*Sandbox, error) {
+		return append(all, sb), nil
+	}); err != nil {
+		return err
+	}
+
+	pid, err := deployment.StartDetached("sandbox", "reap", sb.Slug)
+	if err != nil {
+		// the sandbox is still destroyed the next time it's found expired
+		return fmt.Errorf("failed starting reaper process: %w", err)
+	}
+
+	return s.update(ctx, func(all []*Sandbox) ([]*Sandbox, error) {
+		for _, other := range all {
+			if other.Slug == sb.Slug {
+				other.PID = pid
+			}
+		}
+
+		return all, nil
+	})
+}
+
+// Remove forgets the sandbox of the given slug and returns it.
+func (s *Store) Remove(ctx context.Context, slug string) (removed *Sandbox, err error) {
+	err = s.update(ctx, func(all []*Sandbox) ([]*Sandbox, error) {
+		kept := all[:0]
+		for _, sb := range all {
+			if sb.Slug == slug {
+				removed = sb
+			} else {
+				kept = append(kept, sb)
+			}
+		}
+
+		if removed == nil {
+			return nil, ErrNotFound
+		}
+
+		return kept, nil
+	})
+
+	return
+}
+
+// Destroy destroys the apps of the given sandbox and then its organization,
+// and forgets it. It returns the names of the apps it destroyed.
+func (s *Store) Destroy(ctx context.Context, client *api.Client, sb *Sandbox) (destroyed []string, err error) {
+	apps, err := client.GetOrganizationApps(ctx, sb.Slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed listing apps of %s: %w", sb.Slug, err)
+	}
+
+	for _, app := range apps {
+		if err = client.DeleteApp(ctx, app.Name); err != nil {
+			return destroyed, fmt.Errorf("failed destroying app %s: %w", app.Name, err)
+		}
+
+		destroyed = append(destroyed, app.Name)
+	}
+
+	if _, err = client.DeleteOrganization(ctx, sb.OrgID); err != nil {
+		return destroyed, fmt.Errorf("failed deleting organization %s: %w", sb.Slug, err)
+	}
+
+	if _, err = s.Remove(ctx, sb.Slug); errors.Is(err, ErrNotFound) {
+		// another process destroyed it in the meantime
+		err = nil
+	}
+
+	return destroyed, err
+}
+
+// Reap destroys the sandboxes which have expired and returns them.
+func (s *Store) Reap(ctx context.Context, client *api.Client) (reaped []*Sandbox, err error) {
+	all, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sb := range all {
+		if !sb.Expired() {
+			continue
+		}
+
+		if _, err = s.Destroy(ctx, client, sb); err != nil {
+			return reaped, err
+		}
+
+		reaped = append(reaped, sb)
+	}
+
+	return reaped, nil
+}
+
+// Wait waits for the sandbox of the given slug to expire and destroys it.
+// Sandboxes which are destroyed in the meantime are left alone.
+func (s *Store) Wait(ctx context.Context, client *api.Client, slug string) error {
+	// poll the wall clock rather than sleeping for the duration, since timers
+	// don't account for the time the machine spends suspended
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		sb, err := s.Get(ctx, slug)
+		if err != nil {
+			return err
+		}
+
+		if sb.Expired() {
+			_, err = s.Destroy(ctx, client, sb)
+
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Store) update(ctx context.Context, fn func([]*Sandbox) ([]*Sandbox, error)) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+
+	unlock, err := filemu.Lock(ctx, s.path()+".lock")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = unlock() }()
+
+	all, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	updated, err := fn(all)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(updated, func(i, j int) bool {
+		return updated[i].ExpiresAt.Before(updated[j].ExpiresAt)
+	})
+
+	data, err := json.MarshalIndent(updated, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path(), data, 0600)
+}
+
+func (s *Store) read() (all []*Sandbox, err error) {
+	switch data, err := os.ReadFile(s.path()); {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, fmt.Errorf("failed parsing %s: %w", s.path(), err)
+		}
+	}
+
+	return all, nil
+}
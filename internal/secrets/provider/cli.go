@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+func init() {
+	Register("aws", &AWSSecretsManager{})
+	Register("gcp", &GCPSecretManager{})
+}
+
+// execOutput runs the given command and returns its standard output. It's a
+// variable so that tests may stub it.
+var execOutput = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("the %s CLI is required but could not be found in PATH", name)
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(msg)
+		}
+
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// AWSSecretsManager fetches secrets from AWS Secrets Manager through the aws
+// CLI and thus the credentials and profile it's configured with. Paths are
+// secret names or ARNs, and the region and version_stage params are passed
+// along.
+type AWSSecretsManager struct{}
+
+func (*AWSSecretsManager) Fetch(ctx context.Context, path string, params url.Values) (map[string]string, error) {
+	args := []string{
+		"secretsmanager", "get-secret-value",
+		"--secret-id", path,
+		"--query", "SecretString",
+		"--output", "text",
+	}
+	if region := params.Get("region"); region != "" {
+		args = append(args, "--region", region)
+	}
+	if stage := params.Get("version_stage"); stage != "" {
+		args = append(args, "--version-stage", stage)
+	}
+
+	out, err := execOutput(ctx, "aws", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// the aws CLI terminates text output with a newline
+	return decodeValue(bytes.TrimSuffix(out, []byte("\n")), params)
+}
+
+// GCPSecretManager fetches secrets from GCP Secret Manager through the gcloud
+// CLI and thus the account it's logged in with. Paths are of the form
+// <project>/<secret>; the version param selects a version other than the
+// latest one.
+type GCPSecretManager struct{}
+
+func (*GCPSecretManager) Fetch(ctx context.Context, path string, params url.Values) (map[string]string, error) {
+	i := strings.Index(path, "/")
+	if i < 0 {
+		return nil, fmt.Errorf("gcp path %q must be of the form <project>/<secret>", path)
+	}
+	project, secret := path[:i], path[i+1:]
+
+	version := params.Get("version")
+	if version == "" {
+		version = "latest"
+	}
+
+	out, err := execOutput(ctx, "gcloud", "secrets", "versions", "access", version,
+		"--secret", secret,
+		"--project", project,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeValue(out, params)
+}
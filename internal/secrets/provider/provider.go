@@ -0,0 +1,170 @@
+// Package provider implements fetching app secrets from external secrets
+// managers such as HashiCorp Vault, AWS Secrets Manager and GCP Secret
+// Manager.
+//
+// Sources are denoted by URLs of the form <scheme>://<path>[?<params>], the
+// scheme of which selects the Provider handling them.
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Provider is the interface secrets manager backends implement.
+type Provider interface {
+	// Fetch returns the secrets path denotes, keyed by name.
+	Fetch(ctx context.Context, path string, params url.Values) (map[string]string, error)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{}
+)
+
+// Register makes the given provider handle URLs of the given scheme. It panics
+// in case scheme is already registered.
+func Register(scheme string, p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	if _, dup := providers[scheme]; dup {
+		panic("provider: " + scheme + " is already registered")
+	}
+	providers[scheme] = p
+}
+
+// Schemes returns the sorted schemes of the registered providers.
+func Schemes() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	schemes := make([]string, 0, len(providers))
+	for scheme := range providers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+
+	return schemes
+}
+
+// Fetch returns the secrets the given source URL denotes, keyed by name.
+func Fetch(ctx context.Context, source string) (map[string]string, error) {
+	scheme, path, params, err := parseSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	providersMu.RLock()
+	p, ok := providers[scheme]
+	providersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported secrets source %q; use one of %s", scheme+"://", strings.Join(Schemes(), ", "))
+	}
+
+	secrets, err := p.Fetch(ctx, path, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching secrets from %s://%s: %w", scheme, path, err)
+	}
+
+	return secrets, nil
+}
+
+// FetchAll returns the secrets the given sources denote. Sources are fetched
+// in order, so that secrets of later sources override the ones of earlier ones.
+func FetchAll(ctx context.Context, sources []string) (map[string]string, error) {
+	all := map[string]string{}
+
+	for _, source := range sources {
+		secrets, err := Fetch(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+
+		for k, v := range secrets {
+			all[k] = v
+		}
+	}
+
+	return all, nil
+}
+
+// parseSource splits the given source URL. It doesn't use url.Parse since
+// paths, like ARNs, may contain characters URLs don't allow in their host.
+func parseSource(source string) (scheme, path string, params url.Values, err error) {
+	i := strings.Index(source, "://")
+	if i < 1 {
+		err = fmt.Errorf("invalid secrets source %q; expected <scheme>://<path>", source)
+
+		return
+	}
+	scheme, path = strings.ToLower(source[:i]), source[i+3:]
+
+	var query string
+	if i := strings.Index(path, "?"); i >= 0 {
+		path, query = path[:i], path[i+1:]
+	}
+
+	if path = strings.Trim(path, "/"); path == "" {
+		err = fmt.Errorf("invalid secrets source %q: missing path", source)
+
+		return
+	}
+
+	if params, err = url.ParseQuery(query); err != nil {
+		err = fmt.Errorf("invalid secrets source %q: %w", source, err)
+	}
+
+	return
+}
+
+// decodeValue returns the secrets the given secret value holds. In case params
+// name a key, the value is returned as the secret of that name. Otherwise, it
+// must be a JSON object of scalar values.
+func decodeValue(value []byte, params url.Values) (map[string]string, error) {
+	if key := params.Get("key"); key != "" {
+		return map[string]string{key: string(value)}, nil
+	}
+
+	var fields map[string]interface{}
+	if err := unmarshalJSON(value, &fields); err != nil {
+		return nil, errors.New("secret is not a JSON object; use ?key=NAME to import it as a single secret")
+	}
+
+	return stringifyFields(fields)
+}
+
+func stringifyFields(fields map[string]interface{}) (map[string]string, error) {
+	secrets := make(map[string]string, len(fields))
+
+	for k, v := range fields {
+		switch v := v.(type) {
+		case string:
+			secrets[k] = v
+		case json.Number:
+			secrets[k] = v.String()
+		case bool:
+			secrets[k] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("field %s must be a scalar value", k)
+		}
+	}
+
+	return secrets, nil
+}
+
+// unmarshalJSON is like json.Unmarshal but retains numbers as they're spelled.
+func unmarshalJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	return dec.Decode(v)
+}
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSource(t *testing.T) {
+	scheme, path, params, err := parseSource("AWS://arn:aws:secretsmanager:us-east-1:123:secret:app?region=us-east-1")
+	require.NoError(t, err)
+	assert.Equal(t, "aws", scheme)
+	assert.Equal(t, "arn:aws:secretsmanager:us-east-1:123:secret:app", path)
+	assert.Equal(t, "us-east-1", params.Get("region"))
+
+	for _, source := range []string{"vault", "://path", "vault://", "vault:///?key=x"} {
+		_, _, _, err := parseSource(source)
+		assert.Error(t, err, source)
+	}
+}
+
+func TestFetchUnsupportedScheme(t *testing.T) {
+	_, err := Fetch(context.Background(), "s3://bucket/key")
+	assert.EqualError(t, err, `unsupported secrets source "s3://"; use one of aws, gcp, vault`)
+}
+
+func TestDecodeValue(t *testing.T) {
+	secrets, err := decodeValue([]byte(`{"A":"a","PORT":8080,"DEBUG":true}`), nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"A": "a", "PORT": "8080", "DEBUG": "true"}, secrets)
+
+	secrets, err = decodeValue([]byte("plain"), url.Values{"key": {"TOKEN"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"TOKEN": "plain"}, secrets)
+
+	_, err = decodeValue([]byte("plain"), nil)
+	assert.Error(t, err)
+
+	_, err = decodeValue([]byte(`{"A":{"nested":true}}`), nil)
+	assert.EqualError(t, err, "field A must be a scalar value")
+}
+
+func TestVault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/app":
+			assert.Equal(t, "3", r.URL.Query().Get("version"))
+			_, _ = w.Write([]byte(`{"data":{"data":{"DATABASE_URL":"postgres://"}}}`))
+		case "/v1/kv/legacy":
+			_, _ = w.Write([]byte(`{"data":{"API_KEY":"key"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "token")
+
+	ctx := context.Background()
+
+	secrets, err := Fetch(ctx, "vault://secret/app?version=3")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DATABASE_URL": "postgres://"}, secrets)
+
+	secrets, err = Fetch(ctx, "vault://kv/legacy")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"API_KEY": "key"}, secrets)
+
+	_, err = Fetch(ctx, "vault://secret/missing")
+	assert.Error(t, err)
+
+	t.Setenv("VAULT_TOKEN", "wrong")
+	_, err = Fetch(ctx, "vault://secret/app")
+	assert.EqualError(t, err, "failed fetching secrets from vault://secret/app: vault responded with 403 Forbidden")
+}
+
+func TestCLIProviders(t *testing.T) {
+	var calls [][]string
+
+	prev := execOutput
+	defer func() { execOutput = prev }()
+
+	execOutput = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		calls = append(calls, append([]string{name}, args...))
+
+		return []byte(`{"TOKEN":"t"}` + "\n"), nil
+	}
+
+	ctx := context.Background()
+
+	secrets, err := Fetch(ctx, "aws://prod/app?region=eu-west-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"TOKEN": "t"}, secrets)
+
+	secrets, err = Fetch(ctx, "gcp://my-project/app?version=2")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"TOKEN": "t"}, secrets)
+
+	assert.Equal(t, [][]string{
+		{"aws", "secretsmanager", "get-secret-value", "--secret-id", "prod/app", "--query", "SecretString", "--output", "text", "--region", "eu-west-1"},
+		{"gcloud", "secrets", "versions", "access", "2", "--secret", "app", "--project", "my-project"},
+	}, calls)
+}
@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	Register("vault", &Vault{})
+}
+
+// Vault fetches secrets from the key/value secrets engines of HashiCorp Vault.
+// Paths are of the form <mount>/<path>; both version 2 of the engine, which is
+// tried first, and version 1 are supported. The version param selects a
+// version other than the latest one of a version 2 secret.
+//
+// The server address, token and namespace are read from VAULT_ADDR,
+// VAULT_TOKEN (or ~/.vault-token) and VAULT_NAMESPACE, like the vault CLI does.
+type Vault struct {
+	// Client is the client requests are made with; http.DefaultClient when nil.
+	Client *http.Client
+}
+
+const defaultVaultAddr = "https://127.0.0.1:8200"
+
+func (v *Vault) Fetch(ctx context.Context, path string, params url.Values) (map[string]string, error) {
+	mount, p := path, ""
+	if i := strings.Index(path, "/"); i >= 0 {
+		mount, p = path[:i], path[i+1:]
+	}
+	if p == "" {
+		return nil, fmt.Errorf("vault path %q must be of the form <mount>/<path>", path)
+	}
+
+	token, err := vaultToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// KV version 2 nests secrets under data/ and their fields under data.data
+	var v2 struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+
+	query := url.Values{}
+	if version := params.Get("version"); version != "" {
+		query.Set("version", version)
+	}
+
+	switch err := v.get(ctx, token, mount+"/data/"+p, query, &v2); {
+	case err == nil:
+		return stringifyFields(v2.Data.Data)
+	case !errors.Is(err, errVaultNotFound):
+		return nil, err
+	}
+
+	var v1 struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := v.get(ctx, token, path, nil, &v1); err != nil {
+		return nil, err
+	}
+
+	return stringifyFields(v1.Data)
+}
+
+var errVaultNotFound = errors.New("secret not found")
+
+func (v *Vault) get(ctx context.Context, token, path string, query url.Values, into interface{}) error {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		addr = defaultVaultAddr
+	}
+
+	u := strings.TrimSuffix(addr, "/") + "/v1/" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return errVaultNotFound
+	case res.StatusCode != http.StatusOK:
+		return fmt.Errorf("vault responded with %s", res.Status)
+	}
+
+	return unmarshalJSON(body, into)
+}
+
+func vaultToken() (string, error) {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(home, ".vault-token"))
+	if err != nil {
+		return "", errors.New("no vault token found; set VAULT_TOKEN or run vault login")
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
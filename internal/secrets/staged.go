@@ -0,0 +1,143 @@
+// Package secrets implements the local staging area of app secrets; secrets
+// which are recorded locally and applied by the next deploy of the app.
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/superfly/flyctl/flyctl"
+)
+
+// Staged wraps the secrets staged for an app.
+type Staged struct {
+	// Set holds the values of the secrets to set, keyed by name.
+	Set map[string]string `json:"set"`
+
+	// Unset holds the names of the secrets to unset.
+	Unset []string `json:"unset,omitempty"`
+}
+
+// IsEmpty reports whether s stages nothing.
+func (s *Staged) IsEmpty() bool {
+	return s == nil || (len(s.Set) == 0 && len(s.Unset) == 0)
+}
+
+// Names returns the sorted names of the secrets s sets.
+func (s *Staged) Names() []string {
+	names := make([]string, 0, len(s.Set))
+	for name := range s.Set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (s *Staged) set(secrets map[string]string) {
+	for k, v := range secrets {
+		s.Set[k] = v
+	}
+	s.Unset = without(s.Unset, secrets)
+}
+
+func (s *Staged) unset(keys []string) {
+	removed := make(map[string]string, len(keys))
+	for _, key := range keys {
+		delete(s.Set, key)
+		removed[key] = ""
+	}
+
+	s.Unset = append(without(s.Unset, removed), keys...)
+	sort.Strings(s.Unset)
+}
+
+func without(keys []string, m map[string]string) (ret []string) {
+	for _, key := range keys {
+		if _, ok := m[key]; !ok {
+			ret = append(ret, key)
+		}
+	}
+
+	return
+}
+
+func stagedPath(appName string) string {
+	return filepath.Join(flyctl.ConfigDir(), "staged-secrets", appName+".json")
+}
+
+// LoadStaged returns the secrets staged for the named app. It returns an empty
+// Staged in case nothing is staged.
+func LoadStaged(appName string) (*Staged, error) {
+	staged := &Staged{
+		Set: map[string]string{},
+	}
+
+	data, err := os.ReadFile(stagedPath(appName))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return staged, nil
+	case err != nil:
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, staged); err != nil {
+		return nil, err
+	}
+
+	return staged, nil
+}
+
+// Stage merges the given secrets onto the ones already staged for the named
+// app. Staging a secret cancels any staged removal of it.
+func Stage(appName string, secrets map[string]string) error {
+	staged, err := LoadStaged(appName)
+	if err != nil {
+		return err
+	}
+
+	staged.set(secrets)
+
+	return writeStaged(appName, staged)
+}
+
+// StageUnset stages the removal of the named secrets of the named app.
+// Staging a removal discards any staged value of the secret.
+func StageUnset(appName string, keys []string) error {
+	staged, err := LoadStaged(appName)
+	if err != nil {
+		return err
+	}
+
+	staged.unset(keys)
+
+	return writeStaged(appName, staged)
+}
+
+func writeStaged(appName string, staged *Staged) error {
+	path := stagedPath(appName)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(staged)
+	if err != nil {
+		return err
+	}
+
+	// staged values are sensitive; keep them private to the user
+	return os.WriteFile(path, data, 0600)
+}
+
+// ClearStaged discards the secrets staged for the named app.
+func ClearStaged(appName string) error {
+	if err := os.Remove(stagedPath(appName)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
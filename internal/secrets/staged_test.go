@@ -0,0 +1,28 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStagedSetAndUnset(t *testing.T) {
+	s := &Staged{
+		Set: map[string]string{},
+	}
+
+	s.set(map[string]string{"A": "1", "B": "2"})
+	s.unset([]string{"B", "C"})
+	assert.Equal(t, map[string]string{"A": "1"}, s.Set)
+	assert.Equal(t, []string{"B", "C"}, s.Unset)
+
+	s.set(map[string]string{"C": "3"})
+	assert.Equal(t, []string{"A", "C"}, s.Names())
+	assert.Equal(t, []string{"B"}, s.Unset)
+	assert.False(t, s.IsEmpty())
+
+	s.unset([]string{"A", "C"})
+	s.set(map[string]string{})
+	assert.Equal(t, []string{"A", "B", "C"}, s.Unset)
+	assert.True(t, (&Staged{}).IsEmpty())
+}
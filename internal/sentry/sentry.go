@@ -9,9 +9,9 @@ import (
 	"time"
 
 	"github.com/getsentry/sentry-go"
-	"github.com/logrusorgru/aurora"
 
 	"github.com/superfly/flyctl/internal/buildinfo"
+	"github.com/superfly/flyctl/pkg/iostreams"
 )
 
 var initError error // set during init
@@ -106,7 +106,7 @@ func Recover(v interface{}) {
 func printError(v interface{}) {
 	var buf bytes.Buffer
 
-	fmt.Fprintln(&buf, aurora.Red("Oops, something went wrong! Could you try that again?"))
+	fmt.Fprintln(&buf, iostreams.Aurora().Red("Oops, something went wrong! Could you try that again?"))
 
 	if buildinfo.IsDev() {
 		fmt.Fprintln(&buf)
@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/superfly/flyctl/internal/cmdutil"
+)
+
+const defaultOTLPEndpoint = "http://localhost:4318"
+
+// httpClient implements otlptrace.Client for the OTLP/HTTP protocol, in its
+// binary protobuf encoding. It's configured via the standard OTEL_EXPORTER_OTLP
+// environment variables.
+type httpClient struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+}
+
+func newHTTPClient() *httpClient {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		if base == "" {
+			base = defaultOTLPEndpoint
+		}
+
+		endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+	}
+
+	headers := parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	for k, v := range parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS")) {
+		headers[k] = v
+	}
+
+	return &httpClient{
+		endpoint: endpoint,
+		headers:  headers,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// parseHeaders parses headers in the key1=value1,key2=value2 format of the
+// OTEL_EXPORTER_OTLP_HEADERS environment variable. Values are URL encoded.
+func parseHeaders(s string) map[string]string {
+	headers := map[string]string{}
+
+	for _, pair := range strings.Split(s, ",") {
+		k, v, ok := cmdutil.Cut(pair, "=")
+		if k = strings.TrimSpace(k); !ok || k == "" {
+			continue
+		}
+
+		if unescaped, err := url.QueryUnescape(strings.TrimSpace(v)); err == nil {
+			headers[k] = unescaped
+		}
+	}
+
+	return headers
+}
+
+func (*httpClient) Start(context.Context) error {
+	return nil
+}
+
+func (c *httpClient) Stop(context.Context) error {
+	c.client.CloseIdleConnections()
+
+	return nil
+}
+
+func (c *httpClient) UploadTraces(ctx context.Context, spans []*tracepb.ResourceSpans) error {
+	body, err := proto.Marshal(&coltracepb.ExportTraceServiceRequest{ResourceSpans: spans})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("%s responded with %s", c.endpoint, res.Status)
+	}
+
+	return nil
+}
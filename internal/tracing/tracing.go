@@ -0,0 +1,100 @@
+// Package tracing implements the OpenTelemetry tracing of CLI operations.
+//
+// Tracing is disabled unless the FLY_OTEL_EXPORTER environment variable is
+// set, in which case spans are exported either via OTLP over HTTP (otlp) or
+// as JSON lines written to the standard error stream (stderr).
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/superfly/flyctl/internal/buildinfo"
+)
+
+// ExporterEnvKey denotes the environment variable which selects the exporter
+// spans are exported with.
+const ExporterEnvKey = "FLY_OTEL_EXPORTER"
+
+// The exporters ExporterEnvKey may select.
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStderr = "stderr"
+)
+
+// shutdownTimeout denotes the time pending spans are given to be exported
+// before the CLI exits.
+const shutdownTimeout = 5 * time.Second
+
+const instrumentationName = "github.com/superfly/flyctl"
+
+// Init installs the tracer provider the environment denotes and returns the
+// function which flushes and stops it. In case tracing is disabled, Init
+// installs nothing and the returned function is a no-op.
+func Init(ctx context.Context) (shutdown func(), err error) {
+	shutdown = func() {}
+
+	var exporter sdktrace.SpanExporter
+	switch name := os.Getenv(ExporterEnvKey); name {
+	case "":
+		return
+	case ExporterOTLP:
+		exporter, err = otlptrace.New(ctx, newHTTPClient())
+	case ExporterStderr:
+		exporter = newWriterExporter(os.Stderr)
+	default:
+		err = fmt.Errorf("unsupported %s %q; use one of %s or %s", ExporterEnvKey, name, ExporterOTLP, ExporterStderr)
+	}
+
+	if err != nil {
+		return
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(sdkresource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String("flyctl"),
+			semconv.ServiceVersionKey.String(buildinfo.Version().String()),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	shutdown = func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := provider.Shutdown(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "failed exporting traces: %v\n", err)
+		}
+	}
+
+	return
+}
+
+// Start starts and returns a span with the given name and attributes, along
+// with a copy of ctx which carries it. Spans started off of the returned
+// context are children of the returned span.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends the given span, marking it as failed in case err is set.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestParseHeaders(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"authorization": "Bearer abc=",
+		"x-team":        "fly io",
+	}, parseHeaders("authorization=Bearer%20abc%3D, x-team = fly%20io,invalid,=empty"))
+
+	assert.Empty(t, parseHeaders(""))
+}
+
+func TestWriterExporter(t *testing.T) {
+	var buf bytes.Buffer
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(newWriterExporter(&buf)))
+	tracer := provider.Tracer(instrumentationName)
+
+	ctx, parent := tracer.Start(context.Background(), "deploy")
+	_, child := tracer.Start(ctx, "deploy.build")
+	child.SetAttributes(attribute.String("builder.app", "fly-builder-x"))
+	End(child, errors.New("boom"))
+	End(parent, nil)
+
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	dec := json.NewDecoder(&buf)
+
+	var first, second writtenSpan
+	require.NoError(t, dec.Decode(&first))
+	require.NoError(t, dec.Decode(&second))
+
+	assert.Equal(t, "deploy.build", first.Name)
+	assert.Equal(t, "Error", first.Status)
+	assert.Equal(t, "boom", first.Error)
+	assert.Equal(t, "fly-builder-x", first.Attributes["builder.app"])
+	assert.Equal(t, second.SpanID, first.ParentID)
+	assert.Equal(t, second.TraceID, first.TraceID)
+
+	assert.Equal(t, "deploy", second.Name)
+	assert.Empty(t, second.ParentID)
+	assert.Empty(t, second.Status)
+}
@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// writerExporter exports spans as JSON lines written to a writer.
+type writerExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newWriterExporter(w io.Writer) *writerExporter {
+	return &writerExporter{w: w}
+}
+
+type writtenSpan struct {
+	Name       string                 `json:"name"`
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Start      time.Time              `json:"start"`
+	DurationMS float64                `json:"duration_ms"`
+	Status     string                 `json:"status,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+func (e *writerExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	enc := json.NewEncoder(e.w)
+
+	for _, s := range spans {
+		ws := writtenSpan{
+			Name:       s.Name(),
+			TraceID:    s.SpanContext().TraceID().String(),
+			SpanID:     s.SpanContext().SpanID().String(),
+			Start:      s.StartTime(),
+			DurationMS: float64(s.EndTime().Sub(s.StartTime())) / float64(time.Millisecond),
+		}
+
+		if parent := s.Parent(); parent.IsValid() {
+			ws.ParentID = parent.SpanID().String()
+		}
+
+		if status := s.Status(); status.Code != 0 {
+			ws.Status = status.Code.String()
+			ws.Error = status.Description
+		}
+
+		if attrs := s.Attributes(); len(attrs) > 0 {
+			ws.Attributes = make(map[string]interface{}, len(attrs))
+			for _, kv := range attrs {
+				ws.Attributes[string(kv.Key)] = kv.Value.AsInterface()
+			}
+		}
+
+		if err := enc.Encode(ws); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (*writerExporter) Shutdown(context.Context) error {
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 
 	badrand "math/rand"
@@ -25,11 +26,16 @@ var (
 )
 
 func generatePeerName(ctx context.Context, apiClient *api.Client) (string, error) {
-	user, err := apiClient.GetCurrentUser(ctx)
-	if err != nil {
+	var emailSlug string
+
+	// scoped tokens, such as deploy tokens, belong to no user
+	if user, err := apiClient.GetCurrentUser(ctx); err == nil {
+		emailSlug = cleanDNSPattern.ReplaceAllString(user.Email, "-")
+	} else if api.IsScopedToken(flyctl.GetAPIToken()) {
+		emailSlug = "token"
+	} else {
 		return "", err
 	}
-	emailSlug := cleanDNSPattern.ReplaceAllString(user.Email, "-")
 
 	host, err := os.Hostname()
 	if err != nil {
@@ -209,3 +215,93 @@ func PruneInvalidPeers(ctx context.Context, apiClient *api.Client) error {
 
 	return setWireGuardState(state)
 }
+
+// LocalState returns the state flyctl keeps of the peer named name of the
+// organization orgSlug, or nil in case flyctl keeps none; flyctl only keeps
+// the state of the peers it creates for itself.
+func LocalState(orgSlug, name string) (*wg.WireGuardState, error) {
+	state, err := getWireGuardStateForOrg(orgSlug)
+	if err != nil || state == nil || state.Name != name {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// Rotate replaces the keys of the peer named name of org with a new peer in
+// the same region, named after the old one as rotatedPeerName names it. The
+// new peer is handed to write, and the old one is removed only once write
+// succeeds; in case it fails, the new peer is removed instead. The local state
+// of peers flyctl created for itself is updated to the new peer.
+func Rotate(ctx context.Context, apiClient *api.Client, org *api.Organization, name string, write func(*wg.WireGuardState) error) (*wg.WireGuardState, error) {
+	peers, err := apiClient.GetWireGuardPeers(ctx, org.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	var region string
+	taken := map[string]bool{}
+	for _, peer := range peers {
+		taken[peer.Name] = true
+
+		if peer.Name == name {
+			region = peer.Region
+		}
+	}
+
+	if region == "" {
+		return nil, fmt.Errorf("organization %s has no WireGuard peer named %q", org.Slug, name)
+	}
+
+	local, err := LocalState(org.Slug, name)
+	if err != nil {
+		return nil, err
+	}
+
+	newName := rotatedPeerName(name)
+	for taken[newName] {
+		newName = rotatedPeerName(newName)
+	}
+
+	state, err := Create(apiClient, org, region, newName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed creating the replacement of peer %s", name)
+	}
+
+	if err := write(state); err != nil {
+		if rerr := apiClient.RemoveWireGuardPeer(ctx, org, newName); rerr != nil {
+			terminal.Debugf("failed removing peer %s: %v\n", newName, rerr)
+		}
+
+		return nil, err
+	}
+
+	if local != nil {
+		if err := setWireGuardStateForOrg(org.Slug, state); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := apiClient.RemoveWireGuardPeer(ctx, org, name); err != nil {
+		return nil, errors.Wrapf(err, "failed removing peer %s after replacing it with %s", name, newName)
+	}
+
+	return state, nil
+}
+
+// rotatedPeerRx matches the names rotatedPeerName returns.
+var rotatedPeerRx = regexp.MustCompile(`^(.+)-r(\d+)$`)
+
+// rotatedPeerName returns the name of the peer replacing the peer named name:
+// name suffixed with -r1, or with the next number in case name already has
+// such a suffix. Peer names are unique, so the replacement can't take the
+// name of the peer it replaces while that one exists.
+func rotatedPeerName(name string) string {
+	if m := rotatedPeerRx.FindStringSubmatch(name); m != nil {
+		n, _ := strconv.Atoi(m[2])
+
+		return fmt.Sprintf("%s-r%d", m[1], n+1)
+	}
+
+	return name + "-r1"
+}
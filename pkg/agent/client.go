@@ -150,6 +150,9 @@ func (c *Client) do(parent context.Context, fn func(net.Conn) error) (err error)
 
 	if err = eg.Wait(); errors.Is(err, errDone) {
 		err = nil
+	} else if parent.Err() != nil {
+		// report why the connection was closed rather than that it was
+		err = parent.Err()
 	}
 
 	return
@@ -196,6 +199,67 @@ func (c *Client) Ping(ctx context.Context) (res PingResponse, err error) {
 	return
 }
 
+// TunnelStatus describes one of the tunnels the agent maintains.
+type TunnelStatus struct {
+	Org           string
+	Peer          string
+	Endpoint      string
+	Transport     string
+	LastHandshake *time.Time
+}
+
+// PeerStatus describes one of the WireGuard peers of the configuration the
+// agent tunnels with.
+type PeerStatus struct {
+	Org    string
+	Name   string
+	Region string
+	IP     string
+}
+
+// RecentError is one of the errors the agent recently replied with.
+type RecentError struct {
+	At      time.Time
+	Message string
+}
+
+type StatusResponse struct {
+	PID        int
+	Version    semver.Version
+	Background bool
+	StartedAt  time.Time
+	LogFile    string
+	Tunnels    []TunnelStatus
+	Peers      []PeerStatus
+	Errors     []RecentError
+}
+
+func (c *Client) Status(ctx context.Context) (res StatusResponse, err error) {
+	err = c.do(ctx, func(conn net.Conn) (err error) {
+		if err = proto.Write(conn, "status"); err != nil {
+			return
+		}
+
+		var data []byte
+		if data, err = proto.Read(conn); err != nil {
+			return
+		}
+
+		switch {
+		case isOK(data):
+			err = unmarshal(&res, data)
+		case isError(data):
+			err = extractError(data)
+		default:
+			err = errInvalidResponse(data)
+		}
+
+		return
+	})
+
+	return
+}
+
 const okPrefix = "ok "
 
 func isOK(data []byte) bool {
@@ -7,6 +7,7 @@ import (
 	"log"
 	"net"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -29,6 +30,7 @@ type Options struct {
 	Client     *api.Client
 	Background bool
 	ConfigFile string
+	LogFile    string
 }
 
 func Run(ctx context.Context, opt Options) (err error) {
@@ -54,6 +56,7 @@ func Run(ctx context.Context, opt Options) (err error) {
 		listener:      l,
 		currentChange: latestChangeAt,
 		tunnels:       make(map[string]*wg.Tunnel),
+		startedAt:     time.Now(),
 	}).serve(ctx, l)
 
 	return
@@ -103,6 +106,36 @@ type server struct {
 	mu            sync.Mutex
 	currentChange time.Time
 	tunnels       map[string]*wg.Tunnel
+
+	startedAt time.Time
+
+	errorsMu sync.Mutex
+	errors   []agent.RecentError // the most recent last
+}
+
+// maxRecentErrors is the number of errors the server keeps around to report
+// with its status.
+const maxRecentErrors = 20
+
+func (s *server) recordError(err error) {
+	s.errorsMu.Lock()
+	defer s.errorsMu.Unlock()
+
+	s.errors = append(s.errors, agent.RecentError{
+		At:      time.Now(),
+		Message: err.Error(),
+	})
+
+	if over := len(s.errors) - maxRecentErrors; over > 0 {
+		s.errors = append(s.errors[:0], s.errors[over:]...)
+	}
+}
+
+func (s *server) recentErrors() []agent.RecentError {
+	s.errorsMu.Lock()
+	defer s.errorsMu.Unlock()
+
+	return append([]agent.RecentError(nil), s.errors...)
 }
 
 type terminateError struct{ error }
@@ -215,15 +248,88 @@ func (s *server) buildTunnel(org *api.Organization) (tunnel *wg.Tunnel, err erro
 		return
 	}
 
-	if tunnel, err = wg.Connect(state); err != nil {
+	if transport := wg.EnvTransport(); transport != "" {
+		tunnel, err = wg.ConnectTransport(state, transport)
+	} else {
+		tunnel, err = s.connectWithFallback(org.Slug, state)
+	}
+
+	if err != nil {
 		return
 	}
 
+	s.printf("tunnel for %q established over %s.", org.Slug, tunnel.Transport)
+
 	s.tunnels[org.Slug] = tunnel
 
+	if closed := tunnel.RelayClosed(); closed != nil {
+		go s.dropOnRelayClose(org.Slug, tunnel, closed)
+	}
+
 	return
 }
 
+// dropOnRelayClose drops the tunnel once its websocket relay dies so that the
+// next establish builds a fresh one instead of handing out a dead tunnel.
+func (s *server) dropOnRelayClose(slug string, tunnel *wg.Tunnel, closed <-chan struct{}) {
+	<-closed
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tunnels[slug] != tunnel {
+		return // already dropped or replaced
+	}
+	delete(s.tunnels, slug)
+
+	s.printf("websocket relay for %q closed - closing tunnel ...", slug)
+
+	if err := tunnel.Close(); err != nil {
+		s.printf("failed closing tunnel: %v", err)
+	}
+}
+
+// udpProbeTimeout bounds the time a fresh UDP tunnel has to answer before
+// the agent falls back to the websocket transport.
+const udpProbeTimeout = 5 * time.Second
+
+// connectWithFallback connects over UDP and, should the tunnel not respond
+// (as is the case on networks which block UDP), reconnects over a websocket.
+// Without a websocket relay configured there's nothing to fall back to, so the
+// tunnel isn't probed.
+func (s *server) connectWithFallback(slug string, state *wg.WireGuardState) (*wg.Tunnel, error) {
+	if _, err := wg.WebSocketURL(state.TunnelConfig()); err != nil {
+		return wg.ConnectTransport(state, wg.TransportUDP)
+	}
+
+	tunnel, err := wg.ConnectTransport(state, wg.TransportUDP)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), udpProbeTimeout)
+	defer cancel()
+
+	if _, err = tunnel.LookupTXT(ctx, "_apps.internal"); err == nil {
+		return tunnel, nil
+	}
+
+	s.printf("udp tunnel for %q unresponsive (%v); falling back to %s ...", slug, err, wg.TransportWebSocket)
+
+	fallback, err := wg.ConnectTransport(state, wg.TransportWebSocket)
+	if err != nil {
+		s.printf("failed falling back to %s: %v", wg.TransportWebSocket, err)
+
+		return tunnel, nil
+	}
+
+	if err := tunnel.Close(); err != nil {
+		s.printf("failed closing udp tunnel: %v", err)
+	}
+
+	return fallback, nil
+}
+
 func (s *server) fetchInstances(ctx context.Context, tunnel *wg.Tunnel, app string) (*agent.Instances, error) {
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
@@ -275,6 +381,58 @@ func (s *server) tunnelFor(slug string) *wg.Tunnel {
 	return s.tunnels[slug]
 }
 
+func (s *server) tunnelStatuses() []agent.TunnelStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]agent.TunnelStatus, 0, len(s.tunnels))
+	for slug, tunnel := range s.tunnels {
+		status := agent.TunnelStatus{
+			Org:       slug,
+			Peer:      tunnel.State.Name,
+			Endpoint:  tunnel.Config.Endpoint,
+			Transport: tunnel.Transport,
+		}
+
+		if at, err := tunnel.LastHandshake(); err != nil {
+			s.printf("failed querying handshake of %q: %v", slug, err)
+		} else if !at.IsZero() {
+			status.LastHandshake = &at
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Org < statuses[j].Org
+	})
+
+	return statuses
+}
+
+func (s *server) peerStatuses() ([]agent.PeerStatus, error) {
+	states, err := wireguard.GetWireGuardState()
+	if err != nil {
+		return nil, err
+	}
+
+	peers := make([]agent.PeerStatus, 0, len(states))
+	for slug, state := range states {
+		peers = append(peers, agent.PeerStatus{
+			Org:    slug,
+			Name:   state.Name,
+			Region: state.Region,
+			IP:     state.Peer.Peerip,
+		})
+	}
+
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].Org < peers[j].Org
+	})
+
+	return peers, nil
+}
+
 func (s *server) probeTunnel(ctx context.Context, slug string) (err error) {
 	tunnel := s.tunnelFor(slug)
 	if tunnel == nil {
@@ -115,6 +115,7 @@ var handlers = map[string]handlerFunc{
 	"instances": (*session).instances,
 	"resolve":   (*session).resolve,
 	"ping6":     (*session).ping6,
+	"status":    (*session).status,
 }
 
 var errMalformedKill = errors.New("malformed kill command")
@@ -143,6 +144,32 @@ func (s *session) ping(_ context.Context, args ...string) {
 	})
 }
 
+var errMalformedStatus = errors.New("malformed status command")
+
+func (s *session) status(_ context.Context, args ...string) {
+	if !s.noArgs(args, errMalformedStatus) {
+		return
+	}
+
+	peers, err := s.srv.peerStatuses()
+	if err != nil {
+		s.error(err)
+
+		return
+	}
+
+	_ = s.marshal(agent.StatusResponse{
+		Version:    buildinfo.Version(),
+		PID:        os.Getpid(),
+		Background: s.srv.Options.Background,
+		StartedAt:  s.srv.startedAt,
+		LogFile:    s.srv.Options.LogFile,
+		Tunnels:    s.srv.tunnelStatuses(),
+		Peers:      peers,
+		Errors:     s.srv.recentErrors(),
+	})
+}
+
 var (
 	errMalformedEstablish = errors.New("malformed establish command")
 )
@@ -493,6 +520,8 @@ func (s *session) ping6(ctx context.Context, args ...string) {
 }
 
 func (s *session) error(err error) bool {
+	s.srv.recordError(err)
+
 	return s.reply("err", err.Error())
 }
 
@@ -169,8 +169,43 @@ func createLogFile() (path string, err error) {
 	return
 }
 
+func logDirectory() string {
+	return filepath.Join(flyctl.ConfigDir(), "agent-logs")
+}
+
+// LatestLogFile returns the path to the log file of the agent which started
+// in the background most recently.
+func LatestLogFile() (path string, err error) {
+	dir := logDirectory()
+
+	var entries []fs.DirEntry
+	if entries, err = os.ReadDir(dir); err != nil {
+		err = fmt.Errorf("failed reading agent log directory entries: %w", err)
+
+		return
+	}
+
+	var latest time.Time
+	for _, entry := range entries {
+		inf, e := entry.Info()
+		if e != nil || !inf.Mode().IsRegular() || filepath.Ext(inf.Name()) != ".log" {
+			continue
+		}
+
+		if at := inf.ModTime(); at.After(latest) {
+			latest, path = at, filepath.Join(dir, inf.Name())
+		}
+	}
+
+	if path == "" {
+		err = fmt.Errorf("no agent log files in %s", dir)
+	}
+
+	return
+}
+
 func setupLogDirectory() (dir string, err error) {
-	dir = filepath.Join(flyctl.ConfigDir(), "agent-logs")
+	dir = logDirectory()
 
 	if err = os.MkdirAll(dir, 0700); err != nil {
 		err = fmt.Errorf("failed creating agent log directory at %s: %w", dir, err)
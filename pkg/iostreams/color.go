@@ -1,31 +1,19 @@
 package iostreams
 
 import (
-	"fmt"
 	"os"
 	"strings"
 
+	"github.com/logrusorgru/aurora"
 	"github.com/mgutz/ansi"
 )
 
-var (
-	magenta  = ansi.ColorFunc("magenta")
-	cyan     = ansi.ColorFunc("cyan")
-	red      = ansi.ColorFunc("red")
-	yellow   = ansi.ColorFunc("yellow")
-	blue     = ansi.ColorFunc("blue")
-	green    = ansi.ColorFunc("green")
-	gray     = ansi.ColorFunc("black+h")
-	bold     = ansi.ColorFunc("default+b")
-	cyanBold = ansi.ColorFunc("cyan+b")
-
-	gray256 = func(t string) string {
-		return fmt.Sprintf("\x1b[%d;5;%dm%s\x1b[m", 38, 242, t)
-	}
-)
-
+// EnvColorDisabled reports whether the environment disables color, either
+// per the NO_COLOR and CLICOLOR conventions or by selecting the no-color theme.
 func EnvColorDisabled() bool {
-	return os.Getenv("NO_COLOR") != "" || os.Getenv("CLICOLOR") == "0"
+	return os.Getenv("NO_COLOR") != "" ||
+		os.Getenv("CLICOLOR") == "0" ||
+		strings.EqualFold(os.Getenv(ThemeEnvKey), ThemeNoColor)
 }
 
 func EnvColorForced() bool {
@@ -45,81 +33,72 @@ func Is256ColorSupported() bool {
 }
 
 func NewColorScheme(enabled, is256enabled bool) *ColorScheme {
+	return NewThemedColorScheme(enabled, is256enabled, DefaultTheme())
+}
+
+// NewThemedColorScheme returns a ColorScheme which colors output with the
+// given theme.
+func NewThemedColorScheme(enabled, is256enabled bool, theme *Theme) *ColorScheme {
 	return &ColorScheme{
-		enabled:      enabled,
+		enabled:      enabled && !theme.NoColor,
 		is256enabled: is256enabled,
+		theme:        theme,
 	}
 }
 
 type ColorScheme struct {
 	enabled      bool
 	is256enabled bool
+	theme        *Theme
 }
 
-func (c *ColorScheme) Bold(t string) string {
+// Theme returns the theme c colors output with.
+func (c *ColorScheme) Theme() *Theme {
+	return c.theme
+}
+
+func (c *ColorScheme) paint(color, t string) string {
 	if !c.enabled {
 		return t
 	}
-	return bold(t)
+
+	return ansi.Color(t, c.theme.style(color, c.is256enabled))
+}
+
+func (c *ColorScheme) Bold(t string) string {
+	return c.paint("bold", t)
 }
 
 func (c *ColorScheme) Red(t string) string {
-	if !c.enabled {
-		return t
-	}
-	return red(t)
+	return c.paint("red", t)
 }
 
 func (c *ColorScheme) Yellow(t string) string {
-	if !c.enabled {
-		return t
-	}
-	return yellow(t)
+	return c.paint("yellow", t)
 }
 
 func (c *ColorScheme) Green(t string) string {
-	if !c.enabled {
-		return t
-	}
-	return green(t)
+	return c.paint("green", t)
 }
 
 func (c *ColorScheme) Gray(t string) string {
-	if !c.enabled {
-		return t
-	}
-	if c.is256enabled {
-		return gray256(t)
-	}
-	return gray(t)
+	return c.paint("gray", t)
 }
 
 func (c *ColorScheme) Magenta(t string) string {
-	if !c.enabled {
-		return t
-	}
-	return magenta(t)
+	return c.paint("magenta", t)
 }
 
 func (c *ColorScheme) Cyan(t string) string {
-	if !c.enabled {
-		return t
-	}
-	return cyan(t)
+	return c.paint("cyan", t)
 }
 
 func (c *ColorScheme) CyanBold(t string) string {
-	if !c.enabled {
-		return t
-	}
-	return cyanBold(t)
+	return c.paint("cyanBold", t)
 }
 
 func (c *ColorScheme) Blue(t string) string {
-	if !c.enabled {
-		return t
-	}
-	return blue(t)
+	return c.paint("blue", t)
 }
 
 func (c *ColorScheme) SuccessIcon() string {
@@ -166,3 +145,9 @@ func (c *ColorScheme) ColorFromString(s string) func(string) string {
 
 	return fn
 }
+
+// Aurora returns the aurora instance output which bypasses IOStreams should be
+// colored with. It honors the environment the same way System does.
+func Aurora() aurora.Aurora {
+	return aurora.NewAurora(EnvColorForced() || !EnvColorDisabled())
+}
@@ -27,11 +27,13 @@ type IOStreams struct {
 	Out    io.Writer
 	ErrOut io.Writer
 
-	// the original (non-colorable) output stream
-	originalOut   io.Writer
-	colorEnabled  bool
-	is256enabled  bool
-	terminalTheme string
+	// the original (non-colorable) output streams
+	originalOut    io.Writer
+	originalErrOut io.Writer
+	colorEnabled   bool
+	is256enabled   bool
+	terminalTheme  string
+	theme          *Theme
 
 	progressIndicatorEnabled bool
 	progressIndicator        *spinner.Spinner
@@ -48,6 +50,8 @@ type IOStreams struct {
 
 	neverPrompt bool
 
+	verbosity Verbosity
+
 	TempFileOverride *os.File
 }
 
@@ -59,6 +63,38 @@ func (s *IOStreams) ColorSupport256() bool {
 	return s.is256enabled
 }
 
+// Theme returns the theme output is colored with.
+func (s *IOStreams) Theme() *Theme {
+	if s.theme == nil {
+		return DefaultTheme()
+	}
+
+	return s.theme
+}
+
+// SetTheme sets the theme output is colored with. The no-color theme disables
+// color and strips the escape sequences off of the output written to the
+// standard streams, including the output of the code which colors it
+// unconditionally.
+func (s *IOStreams) SetTheme(t *Theme) {
+	s.theme = t
+
+	if t.NoColor {
+		s.colorEnabled = false
+		s.stripColor()
+	}
+}
+
+func (s *IOStreams) stripColor() {
+	if f, ok := s.originalOut.(*os.File); ok {
+		s.Out = newUncoloredWriter(f)
+	}
+
+	if f, ok := s.originalErrOut.(*os.File); ok {
+		s.ErrOut = newUncoloredWriter(f)
+	}
+}
+
 func (s *IOStreams) DetectTerminalTheme() string {
 	if !s.ColorEnabled() {
 		s.terminalTheme = "none"
@@ -290,7 +326,7 @@ func (s *IOStreams) TerminalWidth() int {
 }
 
 func (s *IOStreams) ColorScheme() *ColorScheme {
-	return NewColorScheme(s.ColorEnabled(), s.ColorSupport256())
+	return NewThemedColorScheme(s.ColorEnabled(), s.ColorSupport256(), s.Theme())
 }
 
 func (s *IOStreams) ReadUserFile(fn string) ([]byte, error) {
@@ -322,13 +358,21 @@ func System() *IOStreams {
 	pagerCommand := os.Getenv("PAGER")
 
 	io := &IOStreams{
-		In:           os.Stdin,
-		originalOut:  os.Stdout,
-		Out:          colorable.NewColorable(os.Stdout),
-		ErrOut:       colorable.NewColorable(os.Stderr),
-		colorEnabled: EnvColorForced() || (!EnvColorDisabled() && stdoutIsTTY),
-		is256enabled: Is256ColorSupported(),
-		pagerCommand: pagerCommand,
+		In:             os.Stdin,
+		originalOut:    os.Stdout,
+		originalErrOut: os.Stderr,
+		Out:            colorable.NewColorable(os.Stdout),
+		ErrOut:         colorable.NewColorable(os.Stderr),
+		colorEnabled:   EnvColorForced() || (!EnvColorDisabled() && stdoutIsTTY),
+		is256enabled:   Is256ColorSupported(),
+		pagerCommand:   pagerCommand,
+		theme:          EnvTheme(),
+	}
+
+	// honor the NO_COLOR and CLICOLOR conventions for output which is colored
+	// unconditionally as well
+	if EnvColorDisabled() && !EnvColorForced() {
+		io.stripColor()
 	}
 
 	if stdoutIsTTY && stderrIsTTY {
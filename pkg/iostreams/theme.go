@@ -0,0 +1,217 @@
+package iostreams
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ThemeEnvKey denotes the environment variable which selects the theme output
+// is colored with.
+const ThemeEnvKey = "FLY_THEME"
+
+// The names of the themes output may be colored with.
+const (
+	ThemeDefault      = "default"
+	ThemeDark         = "dark"
+	ThemeLight        = "light"
+	ThemeNoColor      = "no-color"
+	ThemeHighContrast = "high-contrast"
+)
+
+// ThemeNames denotes the names of the available themes.
+var ThemeNames = []string{ThemeDefault, ThemeDark, ThemeLight, ThemeNoColor, ThemeHighContrast}
+
+// Theme maps the colors of a ColorScheme to the ANSI styles they're rendered
+// with.
+type Theme struct {
+	// Name denotes the name of the theme.
+	Name string
+
+	// NoColor denotes whether the theme disables color altogether.
+	NoColor bool
+
+	// styles maps the names of colors to the ansi package styles they're
+	// rendered with.
+	styles map[string]string
+
+	// styles256 overrides styles on terminals which support 256 colors.
+	styles256 map[string]string
+}
+
+var themes = map[string]*Theme{
+	ThemeDefault: {
+		Name: ThemeDefault,
+		styles: map[string]string{
+			"magenta":  "magenta",
+			"cyan":     "cyan",
+			"red":      "red",
+			"yellow":   "yellow",
+			"blue":     "blue",
+			"green":    "green",
+			"gray":     "black+h",
+			"bold":     "default+b",
+			"cyanBold": "cyan+b",
+		},
+		styles256: map[string]string{
+			"gray": "242",
+		},
+	},
+	ThemeDark: {
+		Name: ThemeDark,
+		styles: map[string]string{
+			"magenta":  "magenta+h",
+			"cyan":     "cyan+h",
+			"red":      "red+h",
+			"yellow":   "yellow+h",
+			"blue":     "blue+h",
+			"green":    "green+h",
+			"gray":     "white",
+			"bold":     "default+b",
+			"cyanBold": "cyan+bh",
+		},
+		styles256: map[string]string{
+			"gray": "246",
+		},
+	},
+	ThemeLight: {
+		Name: ThemeLight,
+		styles: map[string]string{
+			"magenta":  "magenta",
+			"cyan":     "cyan",
+			"red":      "red",
+			"yellow":   "yellow+b",
+			"blue":     "blue",
+			"green":    "green",
+			"gray":     "black+h",
+			"bold":     "default+b",
+			"cyanBold": "cyan+b",
+		},
+		styles256: map[string]string{
+			"cyan":   "30",
+			"yellow": "136",
+			"green":  "28",
+			"gray":   "240",
+		},
+	},
+	ThemeNoColor: {
+		Name:    ThemeNoColor,
+		NoColor: true,
+	},
+	ThemeHighContrast: {
+		Name: ThemeHighContrast,
+		styles: map[string]string{
+			"magenta":  "magenta+bh",
+			"cyan":     "cyan+bh",
+			"red":      "red+bh",
+			"yellow":   "yellow+bh",
+			"blue":     "blue+bh",
+			"green":    "green+bh",
+			"gray":     "white+h",
+			"bold":     "default+bu",
+			"cyanBold": "cyan+bh",
+		},
+	},
+}
+
+// DefaultTheme returns the theme output is colored with unless told otherwise.
+func DefaultTheme() *Theme {
+	return themes[ThemeDefault]
+}
+
+// ParseTheme returns the theme with the given name.
+func ParseTheme(name string) (*Theme, error) {
+	if t, ok := themes[strings.ToLower(name)]; ok {
+		return t, nil
+	}
+
+	return nil, fmt.Errorf("unknown theme %q; use one of %s", name, strings.Join(ThemeNames, ", "))
+}
+
+// EnvTheme returns the theme the environment selects, falling back to the
+// default theme in case it selects none or an unknown one.
+func EnvTheme() *Theme {
+	if t, err := ParseTheme(os.Getenv(ThemeEnvKey)); err == nil {
+		return t
+	}
+
+	return DefaultTheme()
+}
+
+// style returns the style the theme renders the named color with.
+func (t *Theme) style(color string, is256enabled bool) string {
+	if is256enabled {
+		if s, ok := t.styles256[color]; ok {
+			return s
+		}
+	}
+
+	return t.styles[color]
+}
+
+// uncoloredWriter strips the SGR escape sequences, which color and style text,
+// off of the output written to a file. Other escape sequences, such as the
+// cursor movements and erasures of prompts, pass through. The writer exposes
+// the file's descriptor so that prompts keep working.
+type uncoloredWriter struct {
+	io.Writer
+	fd uintptr
+}
+
+func newUncoloredWriter(f *os.File) *uncoloredWriter {
+	return &uncoloredWriter{
+		Writer: &sgrStripper{w: f},
+		fd:     f.Fd(),
+	}
+}
+
+// Fd implements terminal.FileWriter for w.
+func (w *uncoloredWriter) Fd() uintptr {
+	return w.fd
+}
+
+// sgrStripper drops the SGR sequences (ESC [ params m) written to it. Sequences
+// may be split across writes; the part of one seen so far is held back until
+// it's known whether it's an SGR one.
+type sgrStripper struct {
+	w       io.Writer
+	pending []byte
+}
+
+func (s *sgrStripper) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p))
+
+	for _, b := range p {
+		if len(s.pending) == 0 {
+			if b == 0x1b {
+				s.pending = append(s.pending, b)
+			} else {
+				out = append(out, b)
+			}
+
+			continue
+		}
+
+		s.pending = append(s.pending, b)
+
+		switch {
+		case len(s.pending) == 2 && b != '[':
+			// not a CSI sequence
+			out = append(out, s.pending...)
+			s.pending = s.pending[:0]
+		case len(s.pending) > 2 && b >= 0x40 && b <= 0x7e:
+			// the final byte of a CSI sequence
+			if b != 'm' {
+				out = append(out, s.pending...)
+			}
+			s.pending = s.pending[:0]
+		}
+	}
+
+	if _, err := s.w.Write(out); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
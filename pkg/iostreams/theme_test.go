@@ -0,0 +1,87 @@
+package iostreams
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTheme(t *testing.T) {
+	for _, name := range ThemeNames {
+		theme, err := ParseTheme(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, name, theme.Name)
+	}
+
+	theme, err := ParseTheme("High-Contrast")
+	require.NoError(t, err)
+	assert.Equal(t, ThemeHighContrast, theme.Name)
+
+	_, err = ParseTheme("solarized")
+	assert.EqualError(t, err, `unknown theme "solarized"; use one of default, dark, light, no-color, high-contrast`)
+}
+
+func TestEnvTheme(t *testing.T) {
+	t.Setenv(ThemeEnvKey, "")
+	assert.Equal(t, DefaultTheme(), EnvTheme())
+
+	t.Setenv(ThemeEnvKey, "bogus")
+	assert.Equal(t, DefaultTheme(), EnvTheme())
+
+	t.Setenv(ThemeEnvKey, ThemeLight)
+	assert.Equal(t, ThemeLight, EnvTheme().Name)
+}
+
+func TestThemedColorScheme(t *testing.T) {
+	cases := map[string]string{
+		ThemeDefault:      "\x1b[0;31mx\x1b[0m",
+		ThemeDark:         "\x1b[0;91mx\x1b[0m",
+		ThemeHighContrast: "\x1b[0;1;91mx\x1b[0m",
+		ThemeNoColor:      "x",
+	}
+
+	for name, exp := range cases {
+		theme, err := ParseTheme(name)
+		require.NoError(t, err)
+
+		assert.Equal(t, exp, NewThemedColorScheme(true, false, theme).Red("x"), name)
+	}
+
+	light, err := ParseTheme(ThemeLight)
+	require.NoError(t, err)
+	assert.Equal(t, "\x1b[0;38;5;240mx\x1b[0m", NewThemedColorScheme(true, true, light).Gray("x"))
+	assert.Equal(t, "\x1b[0;90mx\x1b[0m", NewThemedColorScheme(true, false, light).Gray("x"))
+
+	assert.Equal(t, "x", NewThemedColorScheme(false, true, light).Gray("x"), "disabled schemes must not color")
+}
+
+func TestSetThemeNoColor(t *testing.T) {
+	io, _, _, _ := Test()
+	io.colorEnabled = true
+
+	io.SetTheme(DefaultTheme())
+	assert.True(t, io.ColorEnabled())
+
+	noColor, err := ParseTheme(ThemeNoColor)
+	require.NoError(t, err)
+
+	io.SetTheme(noColor)
+	assert.False(t, io.ColorEnabled())
+	assert.Equal(t, "x", io.ColorScheme().Green("x"))
+}
+
+func TestSGRStripper(t *testing.T) {
+	var buf bytes.Buffer
+	w := &sgrStripper{w: &buf}
+
+	// colors go, cursor movements and erasures stay, even when split
+	for _, chunk := range []string{"\x1b[1;32mok\x1b[0m\n", "\x1b[1A\x1b[2K", "\x1b[", "31mred\x1b", "[0m done\x1b7"} {
+		n, err := w.Write([]byte(chunk))
+		require.NoError(t, err)
+		assert.Equal(t, len(chunk), n)
+	}
+
+	assert.Equal(t, "ok\n\x1b[1A\x1b[2Kred done\x1b7", buf.String())
+}
@@ -0,0 +1,38 @@
+package iostreams
+
+// Verbosity denotes how much output commands write to the streams.
+type Verbosity int
+
+const (
+	// VerbosityQuiet limits output to results, outcomes and errors.
+	VerbosityQuiet Verbosity = iota - 1
+
+	// VerbosityNormal is the default verbosity.
+	VerbosityNormal
+
+	// VerbosityVerbose adds details, such as the full build log, to the output.
+	VerbosityVerbose
+
+	// VerbosityDebug adds debugging information on top of VerbosityVerbose.
+	VerbosityDebug
+)
+
+// Verbosity returns the verbosity of s.
+func (s *IOStreams) Verbosity() Verbosity {
+	return s.verbosity
+}
+
+// SetVerbosity sets the verbosity of s.
+func (s *IOStreams) SetVerbosity(v Verbosity) {
+	s.verbosity = v
+}
+
+// IsQuiet reports whether s is set to VerbosityQuiet.
+func (s *IOStreams) IsQuiet() bool {
+	return s.verbosity <= VerbosityQuiet
+}
+
+// IsVerbose reports whether s is set to VerbosityVerbose or above.
+func (s *IOStreams) IsVerbose() bool {
+	return s.verbosity >= VerbosityVerbose
+}
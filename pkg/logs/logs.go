@@ -14,6 +14,10 @@ type LogOptions struct {
 	AppName    string
 	VMID       string
 	RegionCode string
+
+	// NoTail instructs Poll to return once it has caught up with the logs,
+	// instead of waiting for new ones.
+	NoTail bool
 }
 
 func (opts *LogOptions) toNatsSubject() (subject string) {
@@ -74,6 +74,10 @@ func Poll(ctx context.Context, out chan<- LogEntry, client *api.Client, opts *Lo
 
 		errorCount = 0
 		if len(entries) == 0 {
+			if opts.NoTail {
+				return nil
+			}
+
 			waitFor = backoff(minWait, maxWait)
 
 			continue
@@ -0,0 +1,162 @@
+package machines
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+)
+
+const (
+	// ExecURLEnvKey is the environment variable which overrides the URL of
+	// the Machines API, which runs commands over HTTPS when no WireGuard
+	// tunnel may be established.
+	ExecURLEnvKey = "FLY_MACHINES_API_URL"
+
+	defaultExecURL = "https://api.machines.dev"
+
+	// DefaultExecTimeout is how long commands run over HTTPS may run for.
+	DefaultExecTimeout = 30 * time.Second
+)
+
+// ExecClient runs commands on machines over HTTPS, through the exec endpoint
+// of the Machines API. Unlike SSH it has no terminal: commands run to
+// completion without input and their output is returned once they exit.
+type ExecClient struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// NewExecClient returns an ExecClient which authenticates with the given
+// token against the Machines API, or the URL ExecURLEnvKey names.
+func NewExecClient(token string) *ExecClient {
+	baseURL := os.Getenv(ExecURLEnvKey)
+	if baseURL == "" {
+		baseURL = defaultExecURL
+	}
+
+	return &ExecClient{
+		BaseURL: baseURL,
+		Token:   token,
+	}
+}
+
+// ExecResult wraps the outcome of a command run over HTTPS.
+type ExecResult struct {
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+}
+
+type execRequest struct {
+	Cmd     []string `json:"cmd"`
+	Timeout int      `json:"timeout,omitempty"`
+}
+
+// Exec runs the given command on the given machine of the named app and
+// waits for it to exit, for up to timeout.
+func (c *ExecClient) Exec(ctx context.Context, appName, machineID string, cmd []string, timeout time.Duration) (*ExecResult, error) {
+	body, err := json.Marshal(execRequest{
+		Cmd:     cmd,
+		Timeout: int(timeout.Seconds()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("%s/v1/apps/%s/machines/%s/exec",
+		strings.TrimSuffix(c.BaseURL, "/"), url.PathEscape(appName), url.PathEscape(machineID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", api.AuthorizationHeader(c.Token))
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed running command over HTTPS: %w", err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &e) != nil || e.Error == "" {
+			e.Error = strings.TrimSpace(string(data))
+		}
+
+		return nil, fmt.Errorf("failed running command over HTTPS: %s: %s", res.Status, e.Error)
+	}
+
+	var result ExecResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed decoding command output: %w", err)
+	}
+
+	return &result, nil
+}
+
+// Console reads commands off in, one per line, and runs them on the given
+// machine through a shell until in runs out or ctx is done. Each command
+// runs in a shell of its own, so state such as the working directory doesn't
+// carry over between them.
+func (c *ExecClient) Console(ctx context.Context, appName, machineID string, in io.Reader, out, errOut io.Writer) error {
+	scanner := bufio.NewScanner(in)
+
+	for {
+		fmt.Fprintf(errOut, "%s$ ", machineID)
+
+		if !scanner.Scan() {
+			fmt.Fprintln(errOut)
+
+			return scanner.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		switch line {
+		case "":
+			continue
+		case "exit", "logout":
+			return nil
+		}
+
+		res, err := c.Exec(ctx, appName, machineID, []string{"sh", "-c", line}, DefaultExecTimeout)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		} else if err != nil {
+			fmt.Fprintln(errOut, err)
+
+			continue
+		}
+
+		_, _ = io.WriteString(out, res.Stdout)
+		_, _ = io.WriteString(errOut, res.Stderr)
+
+		if res.ExitCode != 0 {
+			fmt.Fprintf(errOut, "exit status %d\n", res.ExitCode)
+		}
+	}
+}
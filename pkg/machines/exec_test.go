@@ -0,0 +1,74 @@
+package machines
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecClientExec(t *testing.T) {
+	var got execRequest
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/apps/app/machines/m1/exec", r.URL.Path)
+		assert.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+
+		_, _ = w.Write([]byte(`{"exit_code":3,"stdout":"out\n","stderr":"err\n"}`))
+	}))
+	defer srv.Close()
+
+	c := &ExecClient{BaseURL: srv.URL, Token: "tok"}
+
+	res, err := c.Exec(context.Background(), "app", "m1", []string{"uptime"}, 10*time.Second)
+	require.NoError(t, err)
+
+	assert.Equal(t, execRequest{Cmd: []string{"uptime"}, Timeout: 10}, got)
+	assert.Equal(t, &ExecResult{ExitCode: 3, Stdout: "out\n", Stderr: "err\n"}, res)
+}
+
+func TestExecClientExecError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"machine not found"}`))
+	}))
+	defer srv.Close()
+
+	c := &ExecClient{BaseURL: srv.URL}
+
+	_, err := c.Exec(context.Background(), "app", "m1", []string{"uptime"}, time.Second)
+	assert.EqualError(t, err, "failed running command over HTTPS: 404 Not Found: machine not found")
+}
+
+func TestExecClientConsole(t *testing.T) {
+	var cmds []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req execRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		cmds = append(cmds, req.Cmd[len(req.Cmd)-1])
+
+		_, _ = w.Write([]byte(`{"exit_code":1,"stdout":"hi\n"}`))
+	}))
+	defer srv.Close()
+
+	c := &ExecClient{BaseURL: srv.URL}
+
+	var out, errOut bytes.Buffer
+	in := strings.NewReader("echo hi\n\nexit\nuptime\n")
+
+	require.NoError(t, c.Console(context.Background(), "app", "m1", in, &out, &errOut))
+
+	assert.Equal(t, []string{"echo hi"}, cmds)
+	assert.Equal(t, "hi\n", out.String())
+	assert.Contains(t, errOut.String(), "exit status 1\n")
+}
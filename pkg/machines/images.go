@@ -0,0 +1,89 @@
+package machines
+
+import (
+	"context"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// BaseImage is an image Fly provides for running infrastructure apps.
+type BaseImage struct {
+	Name        string `json:"name"`
+	Repository  string `json:"repository"`
+	Description string `json:"description"`
+
+	// Changelog denotes the URL the changes of each version are listed at.
+	Changelog string `json:"changelog"`
+}
+
+// BaseImages is the catalog of the images Fly provides.
+var BaseImages = []BaseImage{
+	{
+		Name:        "postgres",
+		Repository:  "flyio/postgres",
+		Description: "Highly available Postgres cluster, as run by 'fly postgres create'",
+		Changelog:   "https://github.com/fly-apps/postgres-ha/releases",
+	},
+	{
+		Name:        "postgres-standalone",
+		Repository:  "flyio/postgres-standalone",
+		Description: "Single node Postgres",
+		Changelog:   "https://github.com/fly-apps/postgres-standalone/releases",
+	},
+	{
+		Name:        "redis",
+		Repository:  "flyio/redis",
+		Description: "Redis, as run by 'fly redis create'",
+		Changelog:   "https://github.com/fly-apps/redis/releases",
+	},
+	{
+		Name:        "litefs",
+		Repository:  "flyio/litefs",
+		Description: "Distributed file system which replicates SQLite databases",
+		Changelog:   "https://github.com/superfly/litefs/releases",
+	},
+}
+
+// BaseImageOf returns the base image the given repository is of, or nil in
+// case it's not one Fly provides.
+func BaseImageOf(repository string) *BaseImage {
+	repository = strings.TrimPrefix(repository, "registry-1.docker.io/")
+
+	for i := range BaseImages {
+		if BaseImages[i].Repository == repository {
+			return &BaseImages[i]
+		}
+	}
+
+	return nil
+}
+
+// LatestBaseImage wraps a base image along with the reference of its latest
+// version.
+type LatestBaseImage struct {
+	BaseImage
+
+	// Latest denotes the reference of the latest version of the image, or is
+	// empty in case it couldn't be determined.
+	Latest string `json:"latest"`
+}
+
+// LatestBaseImages returns the catalog along with the latest version of each
+// image. Images the latest version of which can't be determined are returned
+// without one, along with the first error encountered.
+func LatestBaseImages(ctx context.Context, client *api.Client) (images []LatestBaseImage, err error) {
+	for _, image := range BaseImages {
+		latest, latestErr := client.GetLatestImageTag(ctx, image.Repository)
+		if latestErr != nil && err == nil {
+			err = latestErr
+		}
+
+		images = append(images, LatestBaseImage{
+			BaseImage: image,
+			Latest:    latest,
+		})
+	}
+
+	return
+}
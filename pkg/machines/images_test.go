@@ -0,0 +1,21 @@
+package machines
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseImageOf(t *testing.T) {
+	image := BaseImageOf("flyio/postgres")
+	if assert.NotNil(t, image) {
+		assert.Equal(t, "postgres", image.Name)
+	}
+
+	image = BaseImageOf("registry-1.docker.io/flyio/redis")
+	if assert.NotNil(t, image) {
+		assert.Equal(t, "redis", image.Name)
+	}
+
+	assert.Nil(t, BaseImageOf("library/nginx"))
+}
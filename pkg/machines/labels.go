@@ -0,0 +1,177 @@
+package machines
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// ReservedLabelPrefix prefixes the keys of the metadata the platform sets on
+// machines, which labels may not use.
+const ReservedLabelPrefix = "fly_"
+
+var labelKeyRE = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9_./-]{0,62})$`)
+
+// Labels returns the labels of m, which are kept in the metadata of its
+// config.
+func Labels(m *api.Machine) map[string]string {
+	return m.Config.Metadata
+}
+
+// ParseLabels parses the given key=value pairs into labels.
+func ParseLabels(pairs []string) (map[string]string, error) {
+	labels := make(map[string]string, len(pairs))
+
+	for _, pair := range pairs {
+		i := strings.Index(pair, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("label %q must be given as key=value", pair)
+		}
+
+		key, value := pair[:i], pair[i+1:]
+		if err := validateLabelKey(key); err != nil {
+			return nil, err
+		}
+		labels[key] = value
+	}
+
+	return labels, nil
+}
+
+// SetLabels sets the given labels on conf, after removing the labels the
+// given keys name.
+func SetLabels(conf *api.MachineConfig, labels map[string]string, remove []string) error {
+	for _, key := range remove {
+		if err := validateLabelKey(key); err != nil {
+			return err
+		}
+		delete(conf.Metadata, key)
+	}
+
+	if len(labels) == 0 {
+		return nil
+	}
+
+	if conf.Metadata == nil {
+		conf.Metadata = make(map[string]string, len(labels))
+	}
+	for k, v := range labels {
+		conf.Metadata[k] = v
+	}
+
+	return nil
+}
+
+// FormatLabels formats labels as the sorted, comma separated key=value pairs
+// selectors take.
+func FormatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+func validateLabelKey(key string) error {
+	switch {
+	case !labelKeyRE.MatchString(key):
+		return fmt.Errorf("invalid label key %q; keys are up to 63 letters, digits, dashes, underscores, dots and slashes", key)
+	case strings.HasPrefix(key, ReservedLabelPrefix):
+		return fmt.Errorf("label key %q is reserved; keys starting with %s are set by the platform", key, ReservedLabelPrefix)
+	}
+
+	return nil
+}
+
+// Selector selects machines by their labels. Selectors are comma separated
+// requirements, all of which labels must meet: key=value, key!=value, key
+// (the label is set) and !key (the label isn't set).
+type Selector struct {
+	expr         string
+	requirements []requirement
+}
+
+type requirement struct {
+	key    string
+	value  string
+	negate bool
+	exists bool
+}
+
+// ParseSelector parses the selector expr denotes.
+func ParseSelector(expr string) (*Selector, error) {
+	s := &Selector{expr: expr}
+
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var r requirement
+		switch i := strings.Index(part, "="); {
+		case i > 0 && part[i-1] == '!':
+			r = requirement{key: part[:i-1], value: part[i+1:], negate: true}
+		case i >= 0:
+			r = requirement{key: part[:i], value: part[i+1:]}
+		case strings.HasPrefix(part, "!"):
+			r = requirement{key: part[1:], exists: true, negate: true}
+		default:
+			r = requirement{key: part, exists: true}
+		}
+
+		r.key = strings.TrimSpace(r.key)
+		r.value = strings.TrimSpace(r.value)
+		if !labelKeyRE.MatchString(r.key) {
+			return nil, fmt.Errorf("invalid selector %q: %q is not a label key", expr, r.key)
+		}
+
+		s.requirements = append(s.requirements, r)
+	}
+
+	if len(s.requirements) == 0 {
+		return nil, fmt.Errorf("selector %q has no requirements", expr)
+	}
+
+	return s, nil
+}
+
+// Matches reports whether labels meet the requirements of s.
+func (s *Selector) Matches(labels map[string]string) bool {
+	for _, r := range s.requirements {
+		v, ok := labels[r.key]
+
+		var met bool
+		if r.exists {
+			met = ok
+		} else {
+			met = ok && v == r.value
+		}
+
+		if met == r.negate {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Select returns the given machines whose labels s matches.
+func (s *Selector) Select(machines []*api.Machine) (selected []*api.Machine) {
+	for _, m := range machines {
+		if s.Matches(Labels(m)) {
+			selected = append(selected, m)
+		}
+	}
+
+	return
+}
+
+func (s *Selector) String() string {
+	return s.expr
+}
@@ -0,0 +1,74 @@
+package machines
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestParseLabels(t *testing.T) {
+	labels, err := ParseLabels([]string{"env=staging", "tier=worker", "note="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "staging", "tier": "worker", "note": ""}, labels)
+
+	_, err = ParseLabels([]string{"env"})
+	assert.EqualError(t, err, `label "env" must be given as key=value`)
+
+	_, err = ParseLabels([]string{"fly_process_group=web"})
+	assert.EqualError(t, err, `label key "fly_process_group" is reserved; keys starting with fly_ are set by the platform`)
+
+	_, err = ParseLabels([]string{"my key=x"})
+	assert.Error(t, err)
+}
+
+func TestSetLabels(t *testing.T) {
+	conf := &api.MachineConfig{Metadata: map[string]string{"env": "staging", "fly_platform_version": "v2"}}
+
+	require.NoError(t, SetLabels(conf, map[string]string{"tier": "worker"}, []string{"env"}))
+	assert.Equal(t, map[string]string{"tier": "worker", "fly_platform_version": "v2"}, conf.Metadata)
+
+	conf = &api.MachineConfig{}
+	require.NoError(t, SetLabels(conf, map[string]string{"env": "prod"}, nil))
+	assert.Equal(t, "env=prod", FormatLabels(conf.Metadata))
+}
+
+func TestSelector(t *testing.T) {
+	labels := map[string]string{"env": "staging", "tier": "worker"}
+
+	cases := map[string]bool{
+		"env=staging":             true,
+		"env=staging,tier=worker": true,
+		"env=staging, tier=web":   false,
+		"env!=prod":               true,
+		"env!=staging":            false,
+		"tier":                    true,
+		"!tier":                   false,
+		"!canary":                 true,
+		"canary":                  false,
+		"team=":                   false,
+	}
+
+	for expr, expected := range cases {
+		s, err := ParseSelector(expr)
+		require.NoError(t, err, expr)
+		assert.Equal(t, expected, s.Matches(labels), expr)
+	}
+
+	for _, expr := range []string{"", ",", "=x", "bad key=x"} {
+		_, err := ParseSelector(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestSelectorSelect(t *testing.T) {
+	staging := &api.Machine{ID: "a", Config: api.MachineConfig{Metadata: map[string]string{"env": "staging"}}}
+	prod := &api.Machine{ID: "b", Config: api.MachineConfig{Metadata: map[string]string{"env": "prod"}}}
+	bare := &api.Machine{ID: "c"}
+
+	s, err := ParseSelector("env!=prod")
+	require.NoError(t, err)
+	assert.Equal(t, []*api.Machine{staging, bare}, s.Select([]*api.Machine{staging, prod, bare}))
+}
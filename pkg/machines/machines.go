@@ -1,7 +1,10 @@
 package machines
 
 import (
+	"context"
+	"fmt"
 	"net"
+	"time"
 
 	"github.com/superfly/flyctl/api"
 )
@@ -11,3 +14,28 @@ func IpAddress(machine *api.Machine) string {
 	peerIP := net.ParseIP(ip)
 	return peerIP.String()
 }
+
+// WaitForState polls the given machine until it reaches the given state or the
+// timeout elapses.
+func WaitForState(ctx context.Context, client *api.Client, appName, id, state string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		machine, err := client.GetMachine(ctx, appName, id)
+		switch {
+		case err == nil && machine.State == state:
+			return nil
+		case ctx.Err() != nil:
+			return fmt.Errorf("timed out waiting for machine %s to be %s", id, state)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
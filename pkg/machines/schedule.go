@@ -0,0 +1,218 @@
+package machines
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// scheduleAliases maps the shorthands schedules may be given as to the cron
+// expressions they denote.
+var scheduleAliases = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+	"@yearly":  "0 0 1 1 *",
+}
+
+// Schedule is a parsed cron expression, which denotes the times a scheduled
+// machine is booted at.
+type Schedule struct {
+	expr string
+
+	minutes, hours, days, months, weekdays []bool
+
+	// anyDay and anyWeekday tell whether the day of month and day of week
+	// fields are unrestricted, which decides how they combine.
+	anyDay, anyWeekday bool
+}
+
+// ParseSchedule parses the given cron expression; five space separated
+// fields for the minute, hour, day of month, month and day of week, or one of
+// @hourly, @daily, @weekly, @monthly and @yearly. Fields may be *, values,
+// ranges such as 1-5, lists such as 1,15 and steps such as */10. Times are UTC.
+func ParseSchedule(expr string) (*Schedule, error) {
+	s := &Schedule{expr: strings.TrimSpace(expr)}
+
+	spec := s.expr
+	if alias, ok := scheduleAliases[spec]; ok {
+		spec = alias
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields (minute hour day month weekday) or an alias such as @daily", expr)
+	}
+
+	parsers := []struct {
+		name     string
+		min, max int
+		dst      *[]bool
+	}{
+		{"minute", 0, 59, &s.minutes},
+		{"hour", 0, 23, &s.hours},
+		{"day of month", 1, 31, &s.days},
+		{"month", 1, 12, &s.months},
+		{"day of week", 0, 7, &s.weekdays},
+	}
+
+	for i, p := range parsers {
+		set, err := parseScheduleField(fields[i], p.min, p.max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s field of schedule %q: %w", p.name, expr, err)
+		}
+
+		*p.dst = set
+	}
+
+	// both 0 and 7 denote sunday
+	s.weekdays[0] = s.weekdays[0] || s.weekdays[7]
+
+	s.anyDay = fields[2] == "*"
+	s.anyWeekday = fields[4] == "*"
+
+	return s, nil
+}
+
+func parseScheduleField(field string, min, max int) ([]bool, error) {
+	set := make([]bool, max+1)
+
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+			rng = part[:i]
+		}
+
+		lo, hi := min, max
+
+		switch i := strings.Index(rng, "-"); {
+		case rng == "*":
+			break
+		case i >= 0:
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(rng[:i])
+			hi, err2 = strconv.Atoi(rng[i+1:])
+			if err1 != nil || err2 != nil || lo > hi {
+				return nil, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			v, err := strconv.Atoi(rng)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", rng)
+			}
+
+			lo = v
+			if step == 1 {
+				hi = v
+			}
+		}
+
+		if lo < min || hi > max {
+			return nil, fmt.Errorf("%q is out of the range %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			set[v] = true
+		}
+	}
+
+	return set, nil
+}
+
+// String returns the expression s was parsed from.
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	day, weekday := s.days[t.Day()], s.weekdays[t.Weekday()]
+
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+// Next returns the first time after t the schedule denotes, or the zero time
+// in case there's none within 5 years, such as for February 30th.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case !s.months[t.Month()]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !s.hours[t.Hour()]:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case !s.minutes[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// LastRun wraps the outcome of the latest run of a scheduled machine.
+type LastRun struct {
+	At       time.Time `json:"at"`
+	ExitCode int       `json:"exit_code"`
+}
+
+// Succeeded reports whether the run exited with code 0.
+func (r *LastRun) Succeeded() bool {
+	return r.ExitCode == 0
+}
+
+// LastRunOf returns the latest run of the given machine the exit events of the
+// machine tell of, or nil in case the machine hasn't exited yet.
+func LastRunOf(machine *api.Machine) (last *LastRun) {
+	for _, event := range machine.Events.Nodes {
+		if event == nil || event.Kind != "exit" {
+			continue
+		}
+
+		if last != nil && !event.Timestamp.After(last.At) {
+			continue
+		}
+
+		last = &LastRun{
+			At:       event.Timestamp,
+			ExitCode: exitCode(event.Body),
+		}
+	}
+
+	return
+}
+
+// exitCode returns the exit code the body of an exit event carries, or -1 in
+// case it carries none.
+func exitCode(body interface{}) int {
+	b, _ := body.(map[string]interface{})
+	exit, _ := b["exit_event"].(map[string]interface{})
+
+	if code, ok := exit["exit_code"].(float64); ok {
+		return int(code)
+	}
+
+	return -1
+}
@@ -0,0 +1,92 @@
+package machines
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestScheduleNext(t *testing.T) {
+	from := time.Date(2022, 6, 15, 10, 30, 0, 0, time.UTC) // a wednesday
+
+	cases := []struct {
+		expr string
+		exp  time.Time
+	}{
+		{"0 3 * * *", time.Date(2022, 6, 16, 3, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2022, 6, 15, 10, 45, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2022, 6, 16, 10, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2022, 6, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2022, 6, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1,20 * *", time.Date(2022, 6, 20, 12, 0, 0, 0, time.UTC)},
+		// restricted days of month and of week match either
+		{"0 0 1 * 4", time.Date(2022, 6, 16, 0, 0, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2022, 6, 15, 10, 45, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2022, 6, 15, 11, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, c := range cases {
+		s, err := ParseSchedule(c.expr)
+		require.NoError(t, err, c.expr)
+
+		assert.Equal(t, c.exp, s.Next(from), c.expr)
+		assert.Equal(t, c.expr, s.String())
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@often",
+	} {
+		_, err := ParseSchedule(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestLastRunOf(t *testing.T) {
+	var m api.Machine
+	assert.Nil(t, LastRunOf(&m))
+
+	at := time.Date(2022, 6, 15, 3, 0, 0, 0, time.UTC)
+	exit := func(at time.Time, code float64) *api.MachineEvent {
+		return &api.MachineEvent{
+			Kind:      "exit",
+			Timestamp: at,
+			Body: map[string]interface{}{
+				"exit_event": map[string]interface{}{"exit_code": code},
+			},
+		}
+	}
+
+	m.Events.Nodes = []*api.MachineEvent{
+		exit(at.Add(-24*time.Hour), 0),
+		{Kind: "start", Timestamp: at.Add(time.Minute)},
+		exit(at, 1),
+	}
+
+	last := LastRunOf(&m)
+	require.NotNil(t, last)
+	assert.Equal(t, at, last.At)
+	assert.Equal(t, 1, last.ExitCode)
+	assert.False(t, last.Succeeded())
+
+	m.Events.Nodes = []*api.MachineEvent{{Kind: "exit", Timestamp: at}}
+	assert.Equal(t, -1, LastRunOf(&m).ExitCode)
+}
@@ -0,0 +1,159 @@
+package machines
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/superfly/flyctl/api"
+)
+
+// MaxOpenFilesLimit denotes the highest open file limit guests support, which
+// matches the default fs.nr_open of the Linux kernel.
+const MaxOpenFilesLimit = 1 << 20
+
+// permittedKernelArgs denotes the kernel command line parameters guests may be
+// booted with. Parameters which affect how the guest boots, like init= or
+// root=, are reserved for the platform.
+var permittedKernelArgs = []string{
+	"transparent_hugepage=",
+	"hugepages=",
+	"hugepagesz=",
+	"default_hugepagesz=",
+	"numa_balancing=",
+	"sysctl.",
+	"cgroup_enable=",
+	"cgroup_disable=",
+	"swapaccount=",
+	"systemd.unified_cgroup_hierarchy=",
+}
+
+// Tunables wraps the guest tunables of an app; the swap_size_mb setting and
+// the kernel_args and max_open_files settings of the [guest] section of its
+// config.
+type Tunables struct {
+	SwapSizeMB   int
+	KernelArgs   []string
+	MaxOpenFiles int
+}
+
+// TunablesFromDefinition returns the tunables the given app config definition
+// sets along with the problems with them.
+func TunablesFromDefinition(definition map[string]interface{}) (t Tunables, problems []string) {
+	if v, ok := definition["swap_size_mb"]; ok {
+		var isInt bool
+		if t.SwapSizeMB, isInt = intValue(v); !isInt {
+			problems = append(problems, "swap_size_mb must be a whole number of megabytes")
+		}
+	}
+
+	var guest map[string]interface{}
+	switch v := definition["guest"].(type) {
+	case nil:
+	case map[string]interface{}:
+		guest = v
+	default:
+		problems = append(problems, "guest must be a table")
+	}
+
+	if v, ok := guest["kernel_args"]; ok {
+		args, isList := v.([]interface{})
+		if !isList {
+			problems = append(problems, "guest.kernel_args must be a list of strings")
+		}
+
+		for _, arg := range args {
+			s, isString := arg.(string)
+			if !isString {
+				problems = append(problems, "guest.kernel_args must be a list of strings")
+
+				break
+			}
+			t.KernelArgs = append(t.KernelArgs, s)
+		}
+	}
+
+	if v, ok := guest["max_open_files"]; ok {
+		var isInt bool
+		if t.MaxOpenFiles, isInt = intValue(v); !isInt {
+			problems = append(problems, "guest.max_open_files must be a whole number")
+		}
+	}
+
+	problems = append(problems, t.Validate()...)
+
+	return
+}
+
+// ValidateTunables returns the problems with the tunables the given app config
+// definition sets.
+func ValidateTunables(definition map[string]interface{}) []string {
+	_, problems := TunablesFromDefinition(definition)
+
+	return problems
+}
+
+// Validate returns the problems with t.
+func (t Tunables) Validate() (problems []string) {
+	if t.SwapSizeMB < 0 {
+		problems = append(problems, fmt.Sprintf("swap_size_mb must not be negative, got %d", t.SwapSizeMB))
+	}
+
+	if t.MaxOpenFiles < 0 || t.MaxOpenFiles > MaxOpenFilesLimit {
+		problems = append(problems, fmt.Sprintf("max_open_files must be between 0 and %d, got %d", MaxOpenFilesLimit, t.MaxOpenFiles))
+	}
+
+	for _, arg := range t.KernelArgs {
+		if !isPermittedKernelArg(arg) {
+			problems = append(problems, fmt.Sprintf("kernel arg %q is not permitted", arg))
+		}
+	}
+
+	return
+}
+
+// Apply sets the non-zero tunables of t on the given machine config.
+func (t Tunables) Apply(cfg *api.MachineConfig) {
+	if t.SwapSizeMB > 0 {
+		cfg.Init.SwapSizeMB = t.SwapSizeMB
+	}
+
+	if t.MaxOpenFiles > 0 {
+		cfg.Init.MaxOpenFiles = t.MaxOpenFiles
+	}
+
+	if len(t.KernelArgs) > 0 {
+		// guests may point to shared presets
+		var guest api.MachineGuest
+		if cfg.Guest != nil {
+			guest = *cfg.Guest
+		}
+		guest.KernelArgs = t.KernelArgs
+
+		cfg.Guest = &guest
+	}
+}
+
+func isPermittedKernelArg(arg string) bool {
+	for _, prefix := range permittedKernelArgs {
+		if strings.HasPrefix(arg, prefix) && len(arg) > len(prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func intValue(v interface{}) (int, bool) {
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		if v == float64(int(v)) {
+			return int(v), true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,60 @@
+package machines
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/superfly/flyctl/api"
+)
+
+func TestTunablesFromDefinition(t *testing.T) {
+	tunables, problems := TunablesFromDefinition(map[string]interface{}{
+		"swap_size_mb": int64(512),
+		"guest": map[string]interface{}{
+			"kernel_args":    []interface{}{"transparent_hugepage=never", "sysctl.vm.swappiness=10"},
+			"max_open_files": float64(65536),
+		},
+	})
+	assert.Empty(t, problems)
+	assert.Equal(t, Tunables{
+		SwapSizeMB:   512,
+		KernelArgs:   []string{"transparent_hugepage=never", "sysctl.vm.swappiness=10"},
+		MaxOpenFiles: 65536,
+	}, tunables)
+
+	_, problems = TunablesFromDefinition(map[string]interface{}{
+		"swap_size_mb": int64(-1),
+		"guest": map[string]interface{}{
+			"kernel_args":    []interface{}{"init=/bin/sh"},
+			"max_open_files": int64(MaxOpenFilesLimit + 1),
+		},
+	})
+	assert.Equal(t, []string{
+		"swap_size_mb must not be negative, got -1",
+		"max_open_files must be between 0 and 1048576, got 1048577",
+		`kernel arg "init=/bin/sh" is not permitted`,
+	}, problems)
+
+	_, problems = TunablesFromDefinition(map[string]interface{}{
+		"swap_size_mb": "1G",
+		"guest":        "big",
+	})
+	assert.Equal(t, []string{
+		"swap_size_mb must be a whole number of megabytes",
+		"guest must be a table",
+	}, problems)
+}
+
+func TestTunablesApply(t *testing.T) {
+	preset := &api.MachineGuest{CPUKind: "shared", CPUs: 1, MemoryMB: 256}
+	cfg := &api.MachineConfig{Guest: preset}
+
+	Tunables{SwapSizeMB: 256, KernelArgs: []string{"numa_balancing=disable"}}.Apply(cfg)
+
+	assert.Equal(t, 256, cfg.Init.SwapSizeMB)
+	assert.Zero(t, cfg.Init.MaxOpenFiles)
+	assert.Equal(t, []string{"numa_balancing=disable"}, cfg.Guest.KernelArgs)
+	assert.Empty(t, preset.KernelArgs, "presets must not be modified")
+	assert.Equal(t, 256, cfg.Guest.MemoryMB)
+}
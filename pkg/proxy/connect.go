@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/superfly/flyctl/api"
@@ -20,14 +21,29 @@ type ConnectParams struct {
 	RemoteHost     string
 	PromptInstance bool
 	DisableSpinner bool
+
+	// Instance, when set, selects the instance to proxy to by its address,
+	// its label or its region. It takes precedence over RemoteHost.
+	Instance string
 }
 
 func Connect(ctx context.Context, p *ConnectParams) (err error) {
+	srv, err := NewServer(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	io := iostreams.FromContext(ctx)
+	fmt.Fprintf(io.Out, "Proxying local port %s to remote %s\n", srv.LocalAddr, srv.Addr)
+
+	return srv.ProxyServer(ctx)
+}
+
+// NewServer resolves the remote address of the given params and binds their
+// local port, returning a Server ready to proxy connections.
+func NewServer(ctx context.Context, p *ConnectParams) (*Server, error) {
+	client := client.FromContext(ctx).API()
 
-	var (
-		io     = iostreams.FromContext(ctx)
-		client = client.FromContext(ctx).API()
-	)
 	var localPort, remotePort, remoteAddr string
 
 	localPort = p.Ports[0]
@@ -41,15 +57,28 @@ func Connect(ctx context.Context, p *ConnectParams) (err error) {
 	agentclient, err := agent.Establish(ctx, client)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Prompt for a specific instance and set it as the remote target
-	if p.PromptInstance {
-		instance, err := selectInstance(ctx, p.App, agentclient)
+	switch {
+	case p.Instance != "":
+		instances, err := agentclient.Instances(ctx, &p.App.Organization, p.App.Name)
+		if err != nil {
+			return nil, fmt.Errorf("look up %s: %w", p.App.Name, err)
+		}
 
+		instance, err := MatchInstance(instances, p.Instance)
 		if err != nil {
-			return err
+			return nil, err
+		}
+
+		remoteAddr = fmt.Sprintf("[%s]:%s", instance, remotePort)
+	case p.PromptInstance:
+		// Prompt for a specific instance and set it as the remote target
+		instance, err := SelectInstance(ctx, p.App, agentclient)
+
+		if err != nil {
+			return nil, err
 		}
 
 		remoteAddr = fmt.Sprintf("[%s]:%s", instance, remotePort)
@@ -61,7 +90,7 @@ func Connect(ctx context.Context, p *ConnectParams) (err error) {
 		// entry to resolve
 		if !ip.IsV6(p.RemoteHost) {
 			if err := agentclient.WaitForDNS(ctx, p.Dialer, p.App.Organization.Slug, p.RemoteHost); err != nil {
-				return fmt.Errorf("%s: %w", p.RemoteHost, err)
+				return nil, fmt.Errorf("%s: %w", p.RemoteHost, err)
 			}
 		}
 
@@ -70,26 +99,25 @@ func Connect(ctx context.Context, p *ConnectParams) (err error) {
 
 	addr, err := net.ResolveTCPAddr("tcp", fmt.Sprintf("127.0.0.1:%s", localPort))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	listener, err := net.ListenTCP("tcp", addr)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	fmt.Fprintf(io.Out, "Proxying local port %s to remote %s\n", localPort, remoteAddr)
-
-	proxy := Server{
-		Addr:     remoteAddr,
-		Listener: listener,
-		Dial:     p.Dialer.DialContext,
-	}
-
-	return proxy.ProxyServer(ctx)
+	return &Server{
+		LocalAddr: localPort,
+		Addr:      remoteAddr,
+		Listener:  listener,
+		Dial:      p.Dialer.DialContext,
+	}, nil
 }
 
-func selectInstance(ctx context.Context, app *api.App, c *agent.Client) (instance string, err error) {
+// SelectInstance prompts for one of the running instances of the given app
+// and returns its address.
+func SelectInstance(ctx context.Context, app *api.App, c *agent.Client) (instance string, err error) {
 	instances, err := c.Instances(ctx, &app.Organization, app.Name)
 	if err != nil {
 		return "", fmt.Errorf("look up %s: %w", app.Name, err)
@@ -108,3 +136,30 @@ func selectInstance(ctx context.Context, app *api.App, c *agent.Client) (instanc
 
 	return instances.Addresses[selected], nil
 }
+
+// MatchInstance returns the address of the instance the given query refers
+// to. Queries match instances by address, by label or by region; in the
+// latter case the region must have a single instance.
+func MatchInstance(instances agent.Instances, query string) (string, error) {
+	var matches []string
+
+	for i, addr := range instances.Addresses {
+		label := instances.Labels[i]
+
+		switch {
+		case addr == query, label == query:
+			return addr, nil
+		case strings.HasPrefix(label, query+"."), strings.HasPrefix(label, query+" ("):
+			matches = append(matches, addr)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no running instance matches %q", query)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%d instances match %q; select one by address", len(matches), query)
+	}
+}
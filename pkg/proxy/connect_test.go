@@ -0,0 +1,38 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/superfly/flyctl/pkg/agent"
+)
+
+func TestMatchInstance(t *testing.T) {
+	instances := agent.Instances{
+		Labels:    []string{"ams.app.internal", "iad (fdaa::2)", "iad (fdaa::3)"},
+		Addresses: []string{"fdaa::1", "fdaa::2", "fdaa::3"},
+	}
+
+	cases := map[string]string{
+		"fdaa::3":          "fdaa::3",
+		"ams.app.internal": "fdaa::1",
+		"ams":              "fdaa::1",
+		"iad (fdaa::2)":    "fdaa::2",
+	}
+
+	for query, expected := range cases {
+		addr, err := MatchInstance(instances, query)
+		require.NoError(t, err, query)
+		assert.Equal(t, expected, addr, query)
+	}
+
+	_, err := MatchInstance(instances, "iad")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 instances match")
+
+	_, err = MatchInstance(instances, "syd")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no running instance")
+}
@@ -2,6 +2,7 @@ package proxy
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net"
 	"os"
@@ -31,6 +32,10 @@ func (srv *Server) ProxyServer(ctx context.Context) error {
 		select {
 
 		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return ctx.Err()
+			}
+
 			return nil
 		default:
 			if err := ls.SetDeadline(time.Now().Add(time.Second)); err != nil {
@@ -43,12 +48,15 @@ func (srv *Server) ProxyServer(ctx context.Context) error {
 					continue
 				}
 				terminal.Debug("Error accepting connection: ", err)
+
+				continue
 			}
-			defer source.Close()
 
 			terminal.Debug("accepted new connection from: ", source.RemoteAddr())
 
 			go func() {
+				defer source.Close()
+
 				target, err := srv.Dial(ctx, "tcp", srv.Addr)
 				if err != nil {
 					terminal.Debug("failed to connect to target: ", err)
@@ -56,6 +64,19 @@ func (srv *Server) ProxyServer(ctx context.Context) error {
 				}
 				defer target.Close()
 
+				// tear the connection down along with the proxy
+				stop := make(chan struct{})
+				defer close(stop)
+
+				go func() {
+					select {
+					case <-ctx.Done():
+						_ = source.Close()
+						_ = target.Close()
+					case <-stop:
+					}
+				}()
+
 				wg := &sync.WaitGroup{}
 
 				wg.Add(2)
@@ -0,0 +1,242 @@
+package proxy
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/superfly/flyctl/terminal"
+)
+
+// SOCKS5 protocol constants, as per RFC 1928.
+const (
+	socks5Version = 0x05
+
+	socks5NoAuth       = 0x00
+	socks5NoAcceptable = 0xff
+
+	socks5Connect = 0x01
+
+	socks5IPv4   = 0x01
+	socks5Domain = 0x03
+	socks5IPv6   = 0x04
+
+	socks5Succeeded          = 0x00
+	socks5HostUnreachable    = 0x04
+	socks5ConnectionRefused  = 0x05
+	socks5CommandUnsupported = 0x07
+	socks5AddressUnsupported = 0x08
+)
+
+// socks5HandshakeTimeout bounds the time clients may take to send their
+// requests.
+const socks5HandshakeTimeout = 10 * time.Second
+
+// SOCKS5Server is a SOCKS5 server which dials the destinations its clients
+// CONNECT to via Dial. Only unauthenticated CONNECT requests are supported.
+type SOCKS5Server struct {
+	Listener net.Listener
+	Dial     func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// Resolve, when set, resolves the hostnames clients connect to.
+	// Otherwise hostnames are handed to Dial as they are.
+	Resolve func(ctx context.Context, host string) (string, error)
+}
+
+// Serve accepts and serves connections until ctx is done or the listener
+// fails.
+func (srv *SOCKS5Server) Serve(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		_ = srv.Listener.Close()
+	}()
+
+	for {
+		conn, err := srv.Listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		go srv.serve(ctx, conn)
+	}
+}
+
+func (srv *SOCKS5Server) serve(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(socks5HandshakeTimeout))
+
+	addr, err := srv.handshake(conn)
+	if err != nil {
+		terminal.Debug("socks5 handshake failed: ", err)
+		return
+	}
+
+	if addr, err = srv.resolve(ctx, addr); err != nil {
+		terminal.Debug("socks5 failed resolving ", addr, ": ", err)
+		_ = writeSOCKS5Reply(conn, socks5HostUnreachable)
+		return
+	}
+
+	target, err := srv.Dial(ctx, "tcp", addr)
+	if err != nil {
+		terminal.Debug("socks5 failed dialing ", addr, ": ", err)
+		_ = writeSOCKS5Reply(conn, socks5ConnectionRefused)
+		return
+	}
+	defer target.Close()
+
+	if err := writeSOCKS5Reply(conn, socks5Succeeded); err != nil {
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	terminal.Debug("socks5 proxying to ", addr)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	copyFunc := func(dst net.Conn, src net.Conn) {
+		defer wg.Done()
+		_, _ = io.Copy(dst, src)
+
+		// close the write half if it exports a CloseWrite() method
+		if conn, ok := dst.(ClosableWrite); ok {
+			_ = conn.CloseWrite()
+		}
+	}
+
+	go copyFunc(target, conn)
+	go copyFunc(conn, target)
+
+	wg.Wait()
+}
+
+// handshake negotiates the authentication method and reads the CONNECT
+// request of the client, returning its destination address.
+func (srv *SOCKS5Server) handshake(conn net.Conn) (string, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", err
+	}
+
+	if header[0] != socks5Version {
+		return "", fmt.Errorf("unsupported socks version %d", header[0])
+	}
+
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return "", err
+	}
+
+	if !containsByte(methods, socks5NoAuth) {
+		_, _ = conn.Write([]byte{socks5Version, socks5NoAcceptable})
+
+		return "", errors.New("client does not support unauthenticated access")
+	}
+
+	if _, err := conn.Write([]byte{socks5Version, socks5NoAuth}); err != nil {
+		return "", err
+	}
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return "", err
+	}
+
+	if request[0] != socks5Version {
+		return "", fmt.Errorf("unsupported socks version %d", request[0])
+	}
+
+	if request[1] != socks5Connect {
+		_ = writeSOCKS5Reply(conn, socks5CommandUnsupported)
+
+		return "", fmt.Errorf("unsupported socks command %d", request[1])
+	}
+
+	var host string
+
+	switch request[3] {
+	case socks5IPv4, socks5IPv6:
+		ip := make(net.IP, net.IPv4len)
+		if request[3] == socks5IPv6 {
+			ip = make(net.IP, net.IPv6len)
+		}
+
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return "", err
+		}
+
+		host = ip.String()
+	case socks5Domain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return "", err
+		}
+
+		domain := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, domain); err != nil {
+			return "", err
+		}
+
+		host = string(domain)
+	default:
+		_ = writeSOCKS5Reply(conn, socks5AddressUnsupported)
+
+		return "", fmt.Errorf("unsupported socks address type %d", request[3])
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), nil
+}
+
+func (srv *SOCKS5Server) resolve(ctx context.Context, addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr, err
+	}
+
+	if srv.Resolve == nil || net.ParseIP(host) != nil {
+		return addr, nil
+	}
+
+	ip, err := srv.Resolve(ctx, host)
+	if err != nil {
+		return addr, err
+	}
+
+	return net.JoinHostPort(ip, port), nil
+}
+
+// writeSOCKS5Reply writes a reply of the given status. Since clients may
+// not rely on the bound address of CONNECT replies, it's always reported as
+// 0.0.0.0:0.
+func writeSOCKS5Reply(w io.Writer, status byte) error {
+	_, err := w.Write([]byte{socks5Version, status, 0x00, socks5IPv4, 0, 0, 0, 0, 0, 0})
+
+	return err
+}
+
+func containsByte(s []byte, b byte) bool {
+	for _, v := range s {
+		if v == b {
+			return true
+		}
+	}
+
+	return false
+}
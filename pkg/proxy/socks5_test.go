@@ -0,0 +1,77 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	xproxy "golang.org/x/net/proxy"
+)
+
+func TestSOCKS5Server(t *testing.T) {
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer echo.Close()
+
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var dialer net.Dialer
+	srv := &SOCKS5Server{
+		Listener: listener,
+		Dial:     dialer.DialContext,
+		Resolve: func(_ context.Context, host string) (string, error) {
+			if host == "echo.internal" {
+				return "127.0.0.1", nil
+			}
+
+			return "", errors.New("no such host")
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go srv.Serve(ctx)
+
+	client, err := xproxy.SOCKS5("tcp", listener.Addr().String(), nil, xproxy.Direct)
+	require.NoError(t, err)
+
+	_, echoPort, _ := net.SplitHostPort(echo.Addr().String())
+
+	for _, host := range []string{"echo.internal", "127.0.0.1"} {
+		conn, err := client.Dial("tcp", net.JoinHostPort(host, echoPort))
+		require.NoError(t, err, host)
+
+		_, err = conn.Write([]byte("ping"))
+		require.NoError(t, err)
+
+		buf := make([]byte, 4)
+		_, err = io.ReadFull(conn, buf)
+		require.NoError(t, err)
+		assert.Equal(t, "ping", string(buf))
+
+		conn.Close()
+	}
+
+	_, err = client.Dial("tcp", net.JoinHostPort("missing.internal", echoPort))
+	assert.Error(t, err)
+}
@@ -3,9 +3,12 @@ package ssh
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net"
 
+	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -73,7 +76,7 @@ func (c *Client) Connect(ctx context.Context) error {
 		HostKeyAlgorithms: []string{ssh.KeyAlgoED25519},
 	}
 
-	respCh := make(chan connResp)
+	respCh := make(chan connResp, 1)
 
 	// ssh.NewClientConn doesn't take a context, so we need to handle cancelation on our end
 	go func() {
@@ -88,18 +91,21 @@ func (c *Client) Connect(ctx context.Context) error {
 		respCh <- connResp{nil, conn, client}
 	}()
 
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case resp := <-respCh:
-			if resp.err != nil {
-				return resp.err
-			}
-			c.conn = resp.conn
-			c.client = resp.client
-			return nil
+	select {
+	case <-ctx.Done():
+		// unblock the handshake, which may be stuck on the connection
+		_ = tcpConn.Close()
+
+		return ctx.Err()
+	case resp := <-respCh:
+		if resp.err != nil {
+			_ = tcpConn.Close()
+
+			return resp.err
 		}
+		c.conn = resp.conn
+		c.client = resp.client
+		return nil
 	}
 }
 
@@ -118,3 +124,53 @@ func (c *Client) Shell(ctx context.Context, term *Terminal, cmd string) error {
 
 	return term.attach(ctx, sess, cmd)
 }
+
+// Run runs cmd without a terminal, writing its output to stdout and stderr. A
+// command which exits with a non-zero status reports an *ssh.ExitError.
+func (c *Client) Run(ctx context.Context, cmd string, stdout, stderr io.Writer) error {
+	if c.client == nil {
+		if err := c.Connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	sess, err := c.client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer sess.Close()
+
+	sess.Stdout = stdout
+	sess.Stderr = stderr
+
+	done := make(chan error, 1)
+	go func() {
+		done <- sess.Run(cmd)
+	}()
+
+	select {
+	case <-ctx.Done():
+		_ = sess.Signal(ssh.SIGTERM)
+
+		return ctx.Err()
+	case err := <-done:
+		return err
+	}
+}
+
+// SFTP starts an sftp subsystem session and returns a client of it. Closing
+// the client ends the session.
+func (c *Client) SFTP(ctx context.Context) (*sftp.Client, error) {
+	if c.client == nil {
+		if err := c.Connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := sftp.NewClient(c.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed starting sftp subsystem: %w", err)
+	}
+
+	return client, nil
+}
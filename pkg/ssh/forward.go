@@ -0,0 +1,185 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Forward describes a port forward: connections to Listen are relayed to
+// Connect.
+type Forward struct {
+	Listen  string
+	Connect string
+}
+
+func (f Forward) String() string {
+	return f.Listen + " -> " + f.Connect
+}
+
+// ParseForward parses spec, which follows ssh's -L and -R syntax of
+// [bind_address:]port:host:hostport. The listening side binds to localhost
+// unless spec names an address. IPv6 addresses go in brackets.
+func ParseForward(spec string) (Forward, error) {
+	parts, err := splitForward(spec)
+	if err != nil {
+		return Forward{}, err
+	}
+
+	bind := "localhost"
+	switch len(parts) {
+	case 3:
+	case 4:
+		bind, parts = parts[0], parts[1:]
+	default:
+		return Forward{}, fmt.Errorf("invalid forward %q: expected [bind_address:]port:host:hostport", spec)
+	}
+
+	for _, port := range []string{parts[0], parts[2]} {
+		if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+			return Forward{}, fmt.Errorf("invalid forward %q: invalid port %q", spec, port)
+		}
+	}
+
+	if parts[1] == "" {
+		return Forward{}, fmt.Errorf("invalid forward %q: missing host", spec)
+	}
+
+	return Forward{
+		Listen:  net.JoinHostPort(bind, parts[0]),
+		Connect: net.JoinHostPort(parts[1], parts[2]),
+	}, nil
+}
+
+// splitForward splits spec on the colons outside of brackets.
+func splitForward(spec string) (parts []string, err error) {
+	for spec != "" {
+		var part string
+
+		if strings.HasPrefix(spec, "[") {
+			end := strings.Index(spec, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid forward %q: unterminated bracket", spec)
+			}
+
+			part, spec = spec[1:end], spec[end+1:]
+			if spec != "" && !strings.HasPrefix(spec, ":") {
+				return nil, fmt.Errorf("invalid forward %q: expected a colon after %q", spec, part)
+			}
+			spec = strings.TrimPrefix(spec, ":")
+		} else if i := strings.Index(spec, ":"); i >= 0 {
+			part, spec = spec[:i], spec[i+1:]
+		} else {
+			part, spec = spec, ""
+		}
+
+		parts = append(parts, part)
+	}
+
+	return
+}
+
+// ForwardLocal listens on f.Listen locally and relays the connections it
+// accepts to f.Connect, as dialed from the remote end of the connection. It
+// returns once ctx is done or listening fails.
+func (c *Client) ForwardLocal(ctx context.Context, f Forward) error {
+	if c.client == nil {
+		return errors.New("ssh: not connected")
+	}
+
+	var lc net.ListenConfig
+	l, err := lc.Listen(ctx, "tcp", f.Listen)
+	if err != nil {
+		return err
+	}
+
+	return serveForward(ctx, l, func(context.Context) (net.Conn, error) {
+		return c.client.Dial("tcp", f.Connect)
+	})
+}
+
+// ForwardRemote has the remote end of the connection listen on f.Listen and
+// relays the connections it accepts to f.Connect, as dialed locally. It
+// returns once ctx is done or listening fails.
+func (c *Client) ForwardRemote(ctx context.Context, f Forward) error {
+	if c.client == nil {
+		return errors.New("ssh: not connected")
+	}
+
+	l, err := c.client.Listen("tcp", f.Listen)
+	if err != nil {
+		return fmt.Errorf("remote listen on %s: %w", f.Listen, err)
+	}
+
+	var d net.Dialer
+
+	return serveForward(ctx, l, func(ctx context.Context) (net.Conn, error) {
+		return d.DialContext(ctx, "tcp", f.Connect)
+	})
+}
+
+func serveForward(ctx context.Context, l net.Listener, dial func(context.Context) (net.Conn, error)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+
+			upstream, err := dial(ctx)
+			if err != nil {
+				return
+			}
+			defer upstream.Close()
+
+			relay(ctx, conn, upstream)
+		}()
+	}
+}
+
+// relay copies between a and b until either side is done or ctx is.
+func relay(ctx context.Context, a, b io.ReadWriteCloser) {
+	done := make(chan struct{}, 2)
+
+	cp := func(dst io.Writer, src io.Reader) {
+		_, _ = io.Copy(dst, src)
+		done <- struct{}{}
+	}
+
+	go cp(a, b)
+	go cp(b, a)
+
+	select {
+	case <-ctx.Done():
+	case <-done:
+	}
+
+	// unblock the other copy
+	_ = a.Close()
+	_ = b.Close()
+	<-done
+}
@@ -0,0 +1,38 @@
+package ssh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseForward(t *testing.T) {
+	cases := map[string]Forward{
+		"5432:localhost:5432":      {Listen: "localhost:5432", Connect: "localhost:5432"},
+		"0.0.0.0:8080:10.0.0.1:80": {Listen: "0.0.0.0:8080", Connect: "10.0.0.1:80"},
+		"8080:[fdaa::3]:80":        {Listen: "localhost:8080", Connect: "[fdaa::3]:80"},
+		"[::1]:8080:[fdaa::3]:80":  {Listen: "[::1]:8080", Connect: "[fdaa::3]:80"},
+		"*:9000:app.internal:9000": {Listen: "*:9000", Connect: "app.internal:9000"},
+	}
+
+	for spec, expected := range cases {
+		f, err := ParseForward(spec)
+		require.NoError(t, err, spec)
+		assert.Equal(t, expected, f, spec)
+	}
+
+	for _, spec := range []string{
+		"",
+		"5432",
+		"5432:localhost",
+		"port:localhost:5432",
+		"5432:localhost:70000",
+		"5432::5432",
+		"[::1:8080:localhost:80",
+		"a:b:c:d:e",
+	} {
+		_, err := ParseForward(spec)
+		assert.Error(t, err, spec)
+	}
+}
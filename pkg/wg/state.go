@@ -15,6 +15,11 @@ type WireGuardState struct {
 	LocalPrivate string                   `json:"localpublic"`
 	DNS          string                   `json:"dns"`
 	Peer         api.CreatedWireGuardPeer `json:"peer"`
+
+	// WebSocketURL is the URL of the WebSocket relay which fronts the peer's
+	// gateway, for networks which block UDP. The API doesn't hand it out, so
+	// it's set by hand in the peer's entry of the config file.
+	WebSocketURL string `json:"websocketurl,omitempty"`
 }
 
 // BUG(tqbf): Obviously all this needs to go, and I should just
@@ -62,6 +67,7 @@ func (s *WireGuardState) TunnelConfig() *Config {
 		RemotePublicKey: pkey,
 		RemoteNetwork:   &wgr,
 		Endpoint:        s.Peer.Endpointip + ":51820",
+		WebSocketURL:    s.WebSocketURL,
 		DNS:             dns,
 		// LogLevel:        9999999,
 	}
@@ -7,12 +7,16 @@ import (
 	"fmt"
 	"math/rand"
 	"net"
+	"strconv"
+	"time"
 
 	"github.com/miekg/dns"
 	"golang.zx2c4.com/go118/netip"
 	"golang.zx2c4.com/wireguard/device"
 	"golang.zx2c4.com/wireguard/tun"
 	"golang.zx2c4.com/wireguard/tun/netstack"
+
+	"github.com/superfly/flyctl/internal/cmdutil"
 )
 
 type Tunnel struct {
@@ -23,10 +27,29 @@ type Tunnel struct {
 	State  *WireGuardState
 	Config *Config
 
+	// Transport is the transport the tunnel carries WireGuard over; one of
+	// TransportUDP or TransportWebSocket.
+	Transport string
+
 	resolv *net.Resolver
+	relay  *wsRelay
 }
 
 func Connect(state *WireGuardState) (*Tunnel, error) {
+	return ConnectTransport(state, TransportUDP)
+}
+
+// relayDialTimeout bounds the time it takes to set up a websocket relay.
+const relayDialTimeout = 15 * time.Second
+
+// ConnectTransport connects the tunnel state describes over the given
+// transport.
+func ConnectTransport(state *WireGuardState, transport string) (*Tunnel, error) {
+	if transport != TransportUDP && transport != TransportWebSocket {
+		return nil, fmt.Errorf("unknown wireguard transport %q; use one of %s or %s",
+			transport, TransportUDP, TransportWebSocket)
+	}
+
 	cfg := state.TunnelConfig()
 	fmt.Println("wg connect", cfg.DNS, cfg.Endpoint, cfg.LocalNetwork.IP, cfg.RemoteNetwork.IP)
 	localIPs := []netip.Addr{netip.AddrFromSlice(cfg.LocalNetwork.IP)}
@@ -42,19 +65,24 @@ func Connect(state *WireGuardState) (*Tunnel, error) {
 		return nil, err
 	}
 
-	endpointHost, endpointPort, err := net.SplitHostPort(cfg.Endpoint)
-	if err != nil {
-		return nil, err
-	}
+	var (
+		endpointAddr string
+		relay        *wsRelay
+	)
+
+	if transport == TransportWebSocket {
+		if relay, err = connectRelay(cfg); err != nil {
+			_ = tunDev.Close()
+
+			return nil, err
+		}
+		endpointAddr = relay.Addr()
+	} else if endpointAddr, err = resolveEndpoint(cfg.Endpoint); err != nil {
+		_ = tunDev.Close()
 
-	endpointIPs, err := net.LookupIP(endpointHost)
-	if err != nil {
 		return nil, err
 	}
 
-	endpointIP := endpointIPs[rand.Intn(len(endpointIPs))]
-	endpointAddr := net.JoinHostPort(endpointIP.String(), endpointPort)
-
 	wgDev := device.NewDevice(tunDev, device.NewLogger(cfg.LogLevel, "(fly-ssh) "))
 
 	wgConf := bytes.NewBuffer(nil)
@@ -65,6 +93,11 @@ func Connect(state *WireGuardState) (*Tunnel, error) {
 	fmt.Fprintf(wgConf, "persistent_keepalive_interval=%d\n", cfg.KeepAlive)
 
 	if err := wgDev.IpcSetOperation(bufio.NewReader(wgConf)); err != nil {
+		wgDev.Close()
+		if relay != nil {
+			_ = relay.Close()
+		}
+
 		return nil, err
 	}
 	wgDev.Up()
@@ -77,6 +110,9 @@ func Connect(state *WireGuardState) (*Tunnel, error) {
 		Config: cfg,
 		State:  state,
 
+		Transport: transport,
+		relay:     relay,
+
 		resolv: &net.Resolver{
 			PreferGo: true,
 			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
@@ -87,15 +123,99 @@ func Connect(state *WireGuardState) (*Tunnel, error) {
 	}, nil
 }
 
+func resolveEndpoint(endpoint string) (string, error) {
+	endpointHost, endpointPort, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	endpointIPs, err := net.LookupIP(endpointHost)
+	if err != nil {
+		return "", err
+	}
+
+	endpointIP := endpointIPs[rand.Intn(len(endpointIPs))]
+
+	return net.JoinHostPort(endpointIP.String(), endpointPort), nil
+}
+
+func connectRelay(cfg *Config) (*wsRelay, error) {
+	rawURL, err := WebSocketURL(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), relayDialTimeout)
+	defer cancel()
+
+	return dialRelay(ctx, rawURL)
+}
+
 func (t *Tunnel) Close() error {
 	if t.dev != nil {
 		t.dev.Close()
 	}
 
-	t.dev, t.net, t.tun = nil, nil, nil
+	if t.relay != nil {
+		_ = t.relay.Close()
+	}
+
+	t.dev, t.net, t.tun, t.relay = nil, nil, nil, nil
 	return nil
 }
 
+// RelayClosed returns a channel which is closed once the websocket relay of
+// the tunnel dies, e.g. because the relay dropped the connection. It returns
+// nil for tunnels which don't carry WireGuard over a websocket.
+func (t *Tunnel) RelayClosed() <-chan struct{} {
+	if t.relay == nil {
+		return nil
+	}
+
+	return t.relay.done
+}
+
+// LastHandshake returns the time of the latest handshake of the tunnel with
+// its peer, or the zero time in case there hasn't been one.
+func (t *Tunnel) LastHandshake() (at time.Time, err error) {
+	if t.dev == nil {
+		return
+	}
+
+	var buf bytes.Buffer
+
+	w := bufio.NewWriter(&buf)
+	if err = t.dev.IpcGetOperation(w); err != nil {
+		return
+	}
+	if err = w.Flush(); err != nil {
+		return
+	}
+
+	var sec, nsec int64
+
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		key, value, ok := cmdutil.Cut(sc.Text(), "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "last_handshake_time_sec":
+			sec, _ = strconv.ParseInt(value, 10, 64)
+		case "last_handshake_time_nsec":
+			nsec, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+
+	if sec != 0 || nsec != 0 {
+		at = time.Unix(sec, nsec)
+	}
+
+	return
+}
+
 func (t *Tunnel) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
 	return t.net.DialContext(ctx, network, addr)
 }
@@ -0,0 +1,220 @@
+package wg
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/superfly/flyctl/internal/netproxy"
+)
+
+const (
+	// TransportUDP denotes tunnels which carry WireGuard over plain UDP.
+	TransportUDP = "udp"
+
+	// TransportWebSocket denotes tunnels which carry WireGuard datagrams as
+	// binary WebSocket messages, for networks which block UDP.
+	TransportWebSocket = "websocket"
+
+	// TransportEnvKey is the environment variable which, when set, forces
+	// the transport of the agent's tunnels.
+	TransportEnvKey = "FLY_WG_TRANSPORT"
+
+	// WebSocketURLEnvKey is the environment variable which overrides the URL
+	// of the WebSocket relay.
+	WebSocketURLEnvKey = "FLY_WG_WEBSOCKET_URL"
+)
+
+// EnvTransport returns the transport forced via the environment, or an empty
+// string in case none is.
+func EnvTransport() string {
+	return os.Getenv(TransportEnvKey)
+}
+
+// ErrNoWebSocketURL is returned by WebSocketURL when no relay is configured.
+var ErrNoWebSocketURL = errors.New("no websocket relay configured; set " + WebSocketURLEnvKey +
+	" or the websocketurl of the peer in the wire_guard_state section of the config file")
+
+// WebSocketURL returns the URL of the WebSocket relay the tunnel cfg describes
+// should use. The environment takes precedence over the peer's config; the
+// relay isn't derived from the WireGuard endpoint since gateways don't serve
+// one there.
+func WebSocketURL(cfg *Config) (string, error) {
+	rawURL := os.Getenv(WebSocketURLEnvKey)
+	if rawURL == "" {
+		rawURL = cfg.WebSocketURL
+	}
+
+	if rawURL == "" {
+		return "", ErrNoWebSocketURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid websocket relay url: %w", err)
+	}
+
+	if u.Scheme != "wss" && u.Scheme != "ws" {
+		return "", fmt.Errorf("invalid websocket relay url %s: scheme must be wss or ws", rawURL)
+	}
+
+	return rawURL, nil
+}
+
+// wsRelay relays the datagrams the WireGuard device sends to its local UDP
+// socket over a WebSocket connection, and the other way round.
+type wsRelay struct {
+	conn *net.UDPConn
+	ws   *websocket.Conn
+
+	mu   sync.Mutex
+	peer *net.UDPAddr // the address of the WireGuard device's socket
+
+	closeOnce sync.Once
+	done      chan struct{} // closed once the relay is
+}
+
+func dialRelay(ctx context.Context, rawURL string) (*wsRelay, error) {
+	cfg, err := websocket.NewConfig(rawURL, "https://fly.io")
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err := dialWebSocket(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed dialing websocket relay %s: %w", rawURL, err)
+	}
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		_ = ws.Close()
+
+		return nil, err
+	}
+
+	r := &wsRelay{
+		conn: conn,
+		ws:   ws,
+		done: make(chan struct{}),
+	}
+
+	go r.uplink()
+	go r.downlink()
+
+	return r, nil
+}
+
+func dialWebSocket(ctx context.Context, cfg *websocket.Config) (*websocket.Conn, error) {
+	addr := cfg.Location.Host
+	if cfg.Location.Port() == "" {
+		port := "443"
+		if cfg.Location.Scheme == "ws" {
+			port = "80"
+		}
+		addr = net.JoinHostPort(cfg.Location.Hostname(), port)
+	}
+
+	conn, err := netproxy.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Location.Scheme == "wss" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: cfg.Location.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	ws, err := websocket.NewClient(cfg, conn)
+	if err != nil {
+		_ = conn.Close()
+
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	ws.PayloadType = websocket.BinaryFrame
+
+	return ws, nil
+}
+
+// Addr returns the address of the relay's local UDP socket, which the
+// WireGuard device should use as its endpoint.
+func (r *wsRelay) Addr() string {
+	return r.conn.LocalAddr().String()
+}
+
+func (r *wsRelay) uplink() {
+	defer r.Close()
+
+	buf := make([]byte, 1<<16)
+
+	for {
+		n, addr, err := r.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+
+		r.mu.Lock()
+		r.peer = addr
+		r.mu.Unlock()
+
+		if err := websocket.Message.Send(r.ws, buf[:n]); err != nil {
+			return
+		}
+	}
+}
+
+func (r *wsRelay) downlink() {
+	defer r.Close()
+
+	for {
+		var msg []byte
+		if err := websocket.Message.Receive(r.ws, &msg); err != nil {
+			return
+		}
+
+		r.mu.Lock()
+		peer := r.peer
+		r.mu.Unlock()
+
+		if peer == nil {
+			continue // nothing sent yet; nothing to answer to
+		}
+
+		if _, err := r.conn.WriteToUDP(msg, peer); err != nil && errors.Is(err, net.ErrClosed) {
+			return
+		}
+	}
+}
+
+// Close closes both ends of the relay.
+func (r *wsRelay) Close() (err error) {
+	r.closeOnce.Do(func() {
+		err = r.ws.Close()
+
+		if cerr := r.conn.Close(); err == nil {
+			err = cerr
+		}
+
+		close(r.done)
+	})
+
+	return
+}
@@ -0,0 +1,74 @@
+package wg
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func TestWebSocketURL(t *testing.T) {
+	t.Setenv(WebSocketURLEnvKey, "")
+
+	_, err := WebSocketURL(&Config{Endpoint: "ord1.gateway.6pn.dev:51820"})
+	assert.ErrorIs(t, err, ErrNoWebSocketURL)
+
+	u, err := WebSocketURL(&Config{WebSocketURL: "wss://peer.example.com/wg"})
+	require.NoError(t, err)
+	assert.Equal(t, "wss://peer.example.com/wg", u)
+
+	_, err = WebSocketURL(&Config{WebSocketURL: "https://peer.example.com/wg"})
+	assert.Error(t, err)
+
+	t.Setenv(WebSocketURLEnvKey, "wss://relay.example.com/tunnel")
+
+	u, err = WebSocketURL(&Config{WebSocketURL: "wss://peer.example.com/wg"})
+	require.NoError(t, err)
+	assert.Equal(t, "wss://relay.example.com/tunnel", u)
+}
+
+func TestRelay(t *testing.T) {
+	// the relay answers once and then drops the connection
+	srv := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		var msg []byte
+		if err := websocket.Message.Receive(ws, &msg); err != nil {
+			return
+		}
+
+		_ = websocket.Message.Send(ws, append([]byte("echo:"), msg...))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	r, err := dialRelay(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"))
+	require.NoError(t, err)
+	defer r.Close()
+
+	conn, err := net.Dial("udp", r.Addr())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	_, err = conn.Write([]byte("handshake"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "echo:handshake", string(buf[:n]))
+
+	select {
+	case <-r.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("relay not closed after the websocket dropped")
+	}
+}
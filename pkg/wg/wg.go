@@ -15,11 +15,12 @@ type Config struct {
 	RemotePublicKey PublicKey `toml:"remote_public_key"`
 	RemoteNetwork   *IPNet    `toml:"remote_network"`
 
-	Endpoint  string `toml:"endpoint"`
-	DNS       net.IP `toml:"dns"`
-	KeepAlive int    `toml:"keepalive"`
-	MTU       int    `toml:"mtu"`
-	LogLevel  int    `toml:"log_level"`
+	Endpoint     string `toml:"endpoint"`
+	WebSocketURL string `toml:"websocket_url"`
+	DNS          net.IP `toml:"dns"`
+	KeepAlive    int    `toml:"keepalive"`
+	MTU          int    `toml:"mtu"`
+	LogLevel     int    `toml:"log_level"`
 }
 
 type IPNet net.IPNet
@@ -5,7 +5,7 @@ import (
 	"os"
 	"strings"
 
-	"github.com/logrusorgru/aurora"
+	"github.com/superfly/flyctl/pkg/iostreams"
 )
 
 type LogLevel int
@@ -17,6 +17,9 @@ const (
 	LevelError
 )
 
+// au colors log output unless the environment disables color
+var au = iostreams.Aurora()
+
 var DefaultLogger = &Logger{level: LevelInfo}
 
 type Logger struct {
@@ -52,8 +55,8 @@ func (l *Logger) Debug(v ...interface{}) {
 	}
 
 	fmt.Println(
-		aurora.Sprintf(
-			aurora.Faint("DEBUG %s"),
+		au.Sprintf(
+			au.Faint("DEBUG %s"),
 			fmt.Sprint(v...),
 		),
 	)
@@ -69,8 +72,8 @@ func (l *Logger) Debugf(format string, v ...interface{}) {
 	}
 
 	fmt.Printf(
-		aurora.Sprintf(
-			aurora.Faint(fmt.Sprintf("DEBUG %s", format)),
+		au.Sprintf(
+			au.Faint(fmt.Sprintf("DEBUG %s", format)),
 			v...,
 		),
 	)
@@ -108,7 +111,7 @@ func (l *Logger) Warn(v ...interface{}) {
 	if l.level > LevelWarn {
 		return
 	}
-	fmt.Print(aurora.Yellow("WARN "))
+	fmt.Print(au.Yellow("WARN "))
 	fmt.Println(v...)
 }
 
@@ -120,7 +123,7 @@ func (l *Logger) Warnf(format string, v ...interface{}) {
 	if l.level > LevelWarn {
 		return
 	}
-	fmt.Print(aurora.Yellow("WARN "))
+	fmt.Print(au.Yellow("WARN "))
 	fmt.Printf(format, v...)
 }
 
@@ -132,7 +135,7 @@ func (l *Logger) Error(v ...interface{}) {
 	if l.level > LevelError {
 		return
 	}
-	fmt.Print(aurora.Red("ERROR "))
+	fmt.Print(au.Red("ERROR "))
 	fmt.Println(v...)
 }
 
@@ -144,6 +147,6 @@ func (l *Logger) Errorf(format string, v ...interface{}) {
 	if l.level > LevelError {
 		return
 	}
-	fmt.Print(aurora.Red("ERROR "))
+	fmt.Print(au.Red("ERROR "))
 	fmt.Printf(format, v...)
 }